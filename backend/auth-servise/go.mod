@@ -5,9 +5,9 @@ go 1.24.0
 require (
 	backend.com/forum/proto v0.0.0-00010101000000-000000000000
 	github.com/DATA-DOG/go-sqlmock v1.5.2
-	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/gin-contrib/cors v1.7.5
 	github.com/gin-gonic/gin v1.10.0
+	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/golang-migrate/migrate/v4 v4.16.0
 	github.com/jmoiron/sqlx v1.3.5
 	github.com/lib/pq v1.10.7
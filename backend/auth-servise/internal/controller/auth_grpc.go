@@ -3,6 +3,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 
 	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/internal/entity"
@@ -61,11 +62,91 @@ func (c *AuthController) Login(
 	}
 
 	return &pb.LoginResponse{
-		Token:    ucResp.Token,
-		Username: ucResp.Username,
+		Token:        ucResp.Token,
+		RefreshToken: ucResp.RefreshToken,
+		Username:     ucResp.Username,
 	}, nil
 }
 
+func (c *AuthController) Refresh(
+	ctx context.Context,
+	req *pb.RefreshRequest,
+) (*pb.RefreshResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	ucResp, err := c.uc.Refresh(ctx, &usecase.RefreshRequest{RefreshToken: req.RefreshToken})
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.RefreshResponse{
+		Token:        ucResp.Token,
+		RefreshToken: ucResp.RefreshToken,
+	}, nil
+}
+
+func (c *AuthController) Logout(
+	ctx context.Context,
+	req *pb.LogoutRequest,
+) (*pb.LogoutResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	if _, err := c.uc.Logout(ctx, &usecase.LogoutRequest{Token: req.Token}); err != nil {
+		return nil, sessionStatusError(err)
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+func (c *AuthController) LogoutAll(
+	ctx context.Context,
+	req *pb.LogoutRequest,
+) (*pb.LogoutResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	if _, err := c.uc.LogoutAll(ctx, &usecase.LogoutRequest{Token: req.Token}); err != nil {
+		return nil, sessionStatusError(err)
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+func (c *AuthController) RevokeUserSessions(
+	ctx context.Context,
+	req *pb.RevokeUserSessionsRequest,
+) (*pb.LogoutResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	_, err := c.uc.RevokeUserSessions(ctx, &usecase.RevokeUserSessionsRequest{
+		Token:  req.Token,
+		UserID: req.UserId,
+	})
+	if err != nil {
+		return nil, sessionStatusError(err)
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+func sessionStatusError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, usecase.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
 func (c *AuthController) GetUser(
 	ctx context.Context,
 	req *pb.GetUserRequest,
@@ -86,6 +167,51 @@ func (c *AuthController) GetUser(
 	}, nil
 }
 
+func (c *AuthController) GetUsers(
+	ctx context.Context,
+	req *pb.GetUsersRequest,
+) (*pb.GetUsersResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	ucResp, err := c.uc.GetUsers(ctx, &usecase.GetUsersRequest{UserIDs: req.Ids})
+	if err != nil {
+		if errors.Is(err, usecase.ErrTooManyUsers) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return convertUsersToProto(ucResp.Users), nil
+}
+
+func (c *AuthController) GetUsersByUsernames(
+	ctx context.Context,
+	req *pb.GetUsersByUsernamesRequest,
+) (*pb.GetUsersResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	ucResp, err := c.uc.GetUsersByUsernames(ctx, &usecase.GetUsersByUsernamesRequest{Usernames: req.Usernames})
+	if err != nil {
+		if errors.Is(err, usecase.ErrTooManyUsers) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return convertUsersToProto(ucResp.Users), nil
+}
+
+func convertUsersToProto(users []*entity.User) *pb.GetUsersResponse {
+	resp := &pb.GetUsersResponse{Users: make([]*pb.User, 0, len(users))}
+	for _, user := range users {
+		resp.Users = append(resp.Users, convertUserToProto(user))
+	}
+	return resp
+}
+
 // auth_grpc.go
 func convertUserToProto(user *entity.User) *pb.User {
 	if user == nil {
@@ -125,8 +251,9 @@ func (c *AuthController) ValidateToken(
 	}
 
 	return &pb.ValidateTokenResponse{
-		Valid:  ucResp.Valid,
-		UserId: ucResp.UserID,
-		Role:   ucResp.Role,
+		Valid:    ucResp.Valid,
+		UserId:   ucResp.UserID,
+		Username: ucResp.Username,
+		Role:     ucResp.Role,
 	}, nil
 }
@@ -145,13 +145,15 @@ func TestAuthController_Login(t *testing.T) {
 						Password: "testpass",
 					},
 				).Return(&usecase.LoginResponse{
-					Token:    "test_token",
-					Username: "testuser",
+					Token:        "test_token",
+					RefreshToken: "test_refresh",
+					Username:     "testuser",
 				}, nil)
 			},
 			want: &pb.LoginResponse{
-				Token:    "test_token",
-				Username: "testuser",
+				Token:        "test_token",
+				RefreshToken: "test_refresh",
+				Username:     "testuser",
 			},
 		},
 		{
@@ -223,6 +225,140 @@ func TestAuthController_Login(t *testing.T) {
 	}
 }
 
+func TestAuthController_Refresh(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *pb.RefreshRequest
+		mockSetup func(*MockAuthUsecase)
+		want      *pb.RefreshResponse
+		wantCode  codes.Code
+	}{
+		{
+			name: "successful refresh",
+			req:  &pb.RefreshRequest{RefreshToken: "old"},
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().Refresh(gomock.Any(), &usecase.RefreshRequest{RefreshToken: "old"}).
+					Return(&usecase.RefreshResponse{Token: "access", RefreshToken: "new"}, nil)
+			},
+			want:     &pb.RefreshResponse{Token: "access", RefreshToken: "new"},
+			wantCode: codes.OK,
+		},
+		{
+			name: "reused token",
+			req:  &pb.RefreshRequest{RefreshToken: "old"},
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().Refresh(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrRefreshTokenReused)
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "internal error",
+			req:  &pb.RefreshRequest{RefreshToken: "old"},
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().Refresh(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+			},
+			wantCode: codes.Internal,
+		},
+		{
+			name:      "nil request",
+			mockSetup: func(m *MockAuthUsecase) {},
+			wantCode:  codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUC := NewMockAuthUsecase(ctrl)
+			tt.mockSetup(mockUC)
+
+			resp, err := NewAuthController(mockUC).Refresh(context.Background(), tt.req)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestAuthController_RevokeUserSessions(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *pb.RevokeUserSessionsRequest
+		mockSetup func(*MockAuthUsecase)
+		want      *pb.LogoutResponse
+		wantCode  codes.Code
+	}{
+		{
+			name: "success",
+			req:  &pb.RevokeUserSessionsRequest{Token: "admin", UserId: 7},
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().RevokeUserSessions(gomock.Any(), &usecase.RevokeUserSessionsRequest{Token: "admin", UserID: 7}).
+					Return(&usecase.LogoutResponse{}, nil)
+			},
+			want:     &pb.LogoutResponse{},
+			wantCode: codes.OK,
+		},
+		{
+			name: "invalid token",
+			req:  &pb.RevokeUserSessionsRequest{Token: "bad", UserId: 7},
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().RevokeUserSessions(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrInvalidToken)
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "not admin",
+			req:  &pb.RevokeUserSessionsRequest{Token: "user", UserId: 7},
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().RevokeUserSessions(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrPermissionDenied)
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:      "nil request",
+			mockSetup: func(m *MockAuthUsecase) {},
+			wantCode:  codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUC := NewMockAuthUsecase(ctrl)
+			tt.mockSetup(mockUC)
+
+			resp, err := NewAuthController(mockUC).RevokeUserSessions(context.Background(), tt.req)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestAuthController_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := NewMockAuthUsecase(ctrl)
+	mockUC.EXPECT().Logout(gomock.Any(), &usecase.LogoutRequest{Token: "access"}).Return(&usecase.LogoutResponse{}, nil)
+	mockUC.EXPECT().LogoutAll(gomock.Any(), &usecase.LogoutRequest{Token: "access"}).Return(nil, errors.New("db down"))
+	controller := NewAuthController(mockUC)
+
+	resp, err := controller.Logout(context.Background(), &pb.LogoutRequest{Token: "access"})
+	assert.NoError(t, err)
+	assert.Equal(t, &pb.LogoutResponse{}, resp)
+
+	_, err = controller.LogoutAll(context.Background(), &pb.LogoutRequest{Token: "access"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = controller.Logout(context.Background(), nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestAuthController_GetUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -307,6 +443,77 @@ func TestAuthController_GetUser(t *testing.T) {
 	}
 }
 
+func TestAuthController_GetUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := NewMockAuthUsecase(ctrl)
+	controller := NewAuthController(mockUC)
+
+	testTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUC.EXPECT().GetUsers(
+		gomock.Any(),
+		&usecase.GetUsersRequest{UserIDs: []int64{1, 2}},
+	).Return(&usecase.GetUsersResponse{
+		Users: []*entity.User{
+			{ID: 1, Username: "alice", Role: entity.RoleUser, CreatedAt: testTime},
+		},
+	}, nil)
+
+	resp, err := controller.GetUsers(context.Background(), &pb.GetUsersRequest{Ids: []int64{1, 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.User{
+		{Id: 1, Username: "alice", Role: "user", CreatedAt: timestamppb.New(testTime)},
+	}, resp.Users)
+
+	mockUC.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrTooManyUsers)
+
+	_, err = controller.GetUsers(context.Background(), &pb.GetUsersRequest{Ids: []int64{1}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = controller.GetUsers(context.Background(), nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAuthController_GetUsersByUsernames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := NewMockAuthUsecase(ctrl)
+	controller := NewAuthController(mockUC)
+
+	testTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockUC.EXPECT().GetUsersByUsernames(
+		gomock.Any(),
+		&usecase.GetUsersByUsernamesRequest{Usernames: []string{"alice", "ghost"}},
+	).Return(&usecase.GetUsersResponse{
+		Users: []*entity.User{
+			{ID: 1, Username: "alice", Role: entity.RoleUser, CreatedAt: testTime},
+		},
+	}, nil)
+
+	resp, err := controller.GetUsersByUsernames(context.Background(), &pb.GetUsersByUsernamesRequest{Usernames: []string{"alice", "ghost"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.User{
+		{Id: 1, Username: "alice", Role: "user", CreatedAt: timestamppb.New(testTime)},
+	}, resp.Users)
+
+	mockUC.EXPECT().GetUsersByUsernames(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrTooManyUsers)
+
+	_, err = controller.GetUsersByUsernames(context.Background(), &pb.GetUsersByUsernamesRequest{Usernames: []string{"a"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockUC.EXPECT().GetUsersByUsernames(gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+
+	_, err = controller.GetUsersByUsernames(context.Background(), &pb.GetUsersByUsernamesRequest{Usernames: []string{"a"}})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = controller.GetUsersByUsernames(context.Background(), nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestAuthController_ValidateToken(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -2,8 +2,10 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/Mandarinka0707/newRepoGOODarhit/internal/usecase"
 	"github.com/gin-gonic/gin"
@@ -27,6 +29,10 @@ type HTTPLoginRequest struct {
 	Password string `json:"password"`
 }
 
+type HTTPRefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // Register регистрирует пользователя через HTTP
 // @Summary Регистрация пользователя
 // @Description Регистрирует нового пользователя
@@ -91,9 +97,155 @@ func (ctrl *HTTPAuthController) Login(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token":    ucResp.Token,
-		"username": ucResp.Username,
+		"token":         ucResp.Token,
+		"refresh_token": ucResp.RefreshToken,
+		"username":      ucResp.Username,
+	})
+}
+
+// Refresh обновляет пару токенов
+// @Summary Обновление токенов
+// @Description Обменивает refresh-токен на новый access-токен и новый refresh-токен. Старый refresh-токен становится недействительным, а его повторное использование отзывает всю сессию
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body HTTPRefreshRequest true "Refresh-токен"
+// @Success 200 {object} map[string]interface{} "token, refresh_token"
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/auth/refresh [post]
+func (ctrl *HTTPAuthController) Refresh(c *gin.Context) {
+	var req HTTPRefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	ucResp, err := ctrl.uc.Refresh(c.Request.Context(), &usecase.RefreshRequest{
+		RefreshToken: req.RefreshToken,
 	})
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         ucResp.Token,
+		"refresh_token": ucResp.RefreshToken,
+	})
+}
+
+// Logout завершает текущую сессию
+// @Summary Выход
+// @Description Отзывает сессию, к которой привязан access-токен, вместе с её refresh-токенами
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer токен"
+// @Success 200 {object} map[string]interface{} "message"
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/auth/logout [post]
+func (ctrl *HTTPAuthController) Logout(c *gin.Context) {
+	token, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	if _, err := ctrl.uc.Logout(c.Request.Context(), &usecase.LogoutRequest{Token: token}); err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAll завершает все сессии пользователя
+// @Summary Выход на всех устройствах
+// @Description Отзывает все сессии владельца access-токена
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer токен"
+// @Success 200 {object} map[string]interface{} "message"
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/auth/logout-all [post]
+func (ctrl *HTTPAuthController) LogoutAll(c *gin.Context) {
+	token, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	if _, err := ctrl.uc.LogoutAll(c.Request.Context(), &usecase.LogoutRequest{Token: token}); err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out from all devices"})
+}
+
+// RevokeUserSessions завершает все сессии указанного пользователя
+// @Summary Отзыв сессий пользователя
+// @Description Администратор отзывает все сессии пользователя
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer токен администратора"
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} map[string]interface{} "message"
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/auth/admin/users/{id}/sessions [delete]
+func (ctrl *HTTPAuthController) RevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	token, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	_, err = ctrl.uc.RevokeUserSessions(c.Request.Context(), &usecase.RevokeUserSessionsRequest{
+		Token:  token,
+		UserID: userID,
+	})
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User sessions revoked"})
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return "", false
+	}
+	return strings.TrimPrefix(authHeader, "Bearer "), true
+}
+
+func respondSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
 
 // GetUser получает информацию о пользователе
@@ -2,6 +2,7 @@
 package controller
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/Mandarinka0707/newRepoGOODarhit/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/pkg/auth"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -94,12 +96,13 @@ func TestHTTPAuthController_Login(t *testing.T) {
 					Username: "testuser",
 					Password: "testpass",
 				}).Return(&usecase.LoginResponse{
-					Token:    "testtoken",
-					Username: "testuser",
+					Token:        "testtoken",
+					RefreshToken: "testrefresh",
+					Username:     "testuser",
 				}, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"token":"testtoken","username":"testuser"}`,
+			expectedBody:   `{"token":"testtoken","refresh_token":"testrefresh","username":"testuser"}`,
 		},
 		{
 			name:           "invalid request body",
@@ -144,6 +147,175 @@ func TestHTTPAuthController_Login(t *testing.T) {
 	}
 }
 
+func TestHTTPAuthController_Refresh(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockAuthUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "successful refresh",
+			requestBody: `{"refresh_token": "old"}`,
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().Refresh(gomock.Any(), &usecase.RefreshRequest{RefreshToken: "old"}).
+					Return(&usecase.RefreshResponse{Token: "access", RefreshToken: "new"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"token":"access","refresh_token":"new"}`,
+		},
+		{
+			name:           "missing refresh token",
+			requestBody:    `{}`,
+			mockSetup:      func(m *MockAuthUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"Invalid request"}`,
+		},
+		{
+			name:        "invalid refresh token",
+			requestBody: `{"refresh_token": "old"}`,
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().Refresh(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrInvalidRefreshToken)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid refresh token"}`,
+		},
+		{
+			name:        "reused refresh token",
+			requestBody: `{"refresh_token": "old"}`,
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().Refresh(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrRefreshTokenReused)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"refresh token reuse detected"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := NewMockAuthUsecase(ctrl)
+			tt.mockSetup(mockUsecase)
+
+			router := gin.Default()
+			authController := NewHTTPAuthController(mockUsecase)
+			router.POST("/refresh", authController.Refresh)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/refresh", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.JSONEq(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestHTTPAuthController_Logout(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		authHeader     string
+		mockSetup      func(*MockAuthUsecase)
+		expectedStatus int
+	}{
+		{
+			name:       "logout",
+			path:       "/logout",
+			authHeader: "Bearer access",
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().Logout(gomock.Any(), &usecase.LogoutRequest{Token: "access"}).Return(&usecase.LogoutResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing header",
+			path:           "/logout",
+			mockSetup:      func(m *MockAuthUsecase) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "revoked token",
+			path:       "/logout",
+			authHeader: "Bearer access",
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().Logout(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrInvalidToken)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "logout all",
+			path:       "/logout-all",
+			authHeader: "Bearer access",
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().LogoutAll(gomock.Any(), &usecase.LogoutRequest{Token: "access"}).Return(&usecase.LogoutResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "admin revoke",
+			path:       "/admin/users/7/sessions",
+			authHeader: "Bearer admin",
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().RevokeUserSessions(gomock.Any(), &usecase.RevokeUserSessionsRequest{Token: "admin", UserID: 7}).
+					Return(&usecase.LogoutResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "admin revoke by user",
+			path:       "/admin/users/7/sessions",
+			authHeader: "Bearer user",
+			mockSetup: func(m *MockAuthUsecase) {
+				m.EXPECT().RevokeUserSessions(gomock.Any(), gomock.Any()).Return(nil, usecase.ErrPermissionDenied)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "admin revoke invalid id",
+			path:           "/admin/users/abc/sessions",
+			authHeader:     "Bearer admin",
+			mockSetup:      func(m *MockAuthUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUsecase := NewMockAuthUsecase(ctrl)
+			tt.mockSetup(mockUsecase)
+
+			router := gin.Default()
+			authController := NewHTTPAuthController(mockUsecase)
+			router.POST("/logout", authController.Logout)
+			router.POST("/logout-all", authController.LogoutAll)
+			router.DELETE("/admin/users/:id/sessions", authController.RevokeUserSessions)
+
+			method := http.MethodPost
+			if strings.HasPrefix(tt.path, "/admin") {
+				method = http.MethodDelete
+			}
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(method, tt.path, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
 func TestHTTPAuthController_GetUser(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -221,3 +393,25 @@ func TestHTTPAuthController_GetUser(t *testing.T) {
 		})
 	}
 }
+
+func TestJWKSController_JWKS(t *testing.T) {
+	signing, err := auth.GenerateSigningKey()
+	assert.NoError(t, err)
+	keys, err := auth.NewKeyring(signing)
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.GET("/.well-known/jwks.json", NewJWKSController(keys).JWKS)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	router.ServeHTTP(w, req)
+
+	var set auth.JWKSet
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &set))
+	assert.Len(t, set.Keys, 1)
+	assert.Equal(t, signing.ID, set.Keys[0].Kid)
+	assert.Equal(t, "EdDSA", set.Keys[0].Alg)
+	assert.NotEmpty(t, w.Header().Get("Cache-Control"))
+}
@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// jwksMaxAge — сколько клиенты могут кэшировать JWKS. Новый ключ нужно
+// опубликовать хотя бы за это время до того, как им начнут подписывать.
+const jwksMaxAge = "max-age=300"
+
+type JWKSController struct {
+	keys *auth.Keyring
+}
+
+func NewJWKSController(keys *auth.Keyring) *JWKSController {
+	return &JWKSController{keys: keys}
+}
+
+// JWKS отдаёт открытые ключи для проверки access-токенов
+// @Summary Открытые ключи
+// @Description Возвращает JWKS со всеми ключами, которыми можно проверить access-токен
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (ctrl *JWKSController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", jwksMaxAge)
+	c.JSON(http.StatusOK, ctrl.keys.JWKS())
+}
@@ -53,6 +53,20 @@ func (m *MockAuthUsecase) GetUser(ctx context.Context, req *usecase.GetUserReque
 	return ret0, ret1
 }
 
+func (m *MockAuthUsecase) GetUsers(ctx context.Context, req *usecase.GetUsersRequest) (*usecase.GetUsersResponse, error) {
+	ret := m.ctrl.Call(m, "GetUsers", ctx, req)
+	ret0, _ := ret[0].(*usecase.GetUsersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (m *MockAuthUsecase) GetUsersByUsernames(ctx context.Context, req *usecase.GetUsersByUsernamesRequest) (*usecase.GetUsersResponse, error) {
+	ret := m.ctrl.Call(m, "GetUsersByUsernames", ctx, req)
+	ret0, _ := ret[0].(*usecase.GetUsersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 func (m *MockAuthUsecase) ValidateToken(ctx context.Context, req *usecase.ValidateTokenRequest) (*usecase.ValidateTokenResponse, error) {
 	ret := m.ctrl.Call(m, "ValidateToken", ctx, req)
 	ret0, _ := ret[0].(*usecase.ValidateTokenResponse)
@@ -60,6 +74,34 @@ func (m *MockAuthUsecase) ValidateToken(ctx context.Context, req *usecase.Valida
 	return ret0, ret1
 }
 
+func (m *MockAuthUsecase) Refresh(ctx context.Context, req *usecase.RefreshRequest) (*usecase.RefreshResponse, error) {
+	ret := m.ctrl.Call(m, "Refresh", ctx, req)
+	ret0, _ := ret[0].(*usecase.RefreshResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (m *MockAuthUsecase) Logout(ctx context.Context, req *usecase.LogoutRequest) (*usecase.LogoutResponse, error) {
+	ret := m.ctrl.Call(m, "Logout", ctx, req)
+	ret0, _ := ret[0].(*usecase.LogoutResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (m *MockAuthUsecase) LogoutAll(ctx context.Context, req *usecase.LogoutRequest) (*usecase.LogoutResponse, error) {
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, req)
+	ret0, _ := ret[0].(*usecase.LogoutResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (m *MockAuthUsecase) RevokeUserSessions(ctx context.Context, req *usecase.RevokeUserSessionsRequest) (*usecase.LogoutResponse, error) {
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, req)
+	ret0, _ := ret[0].(*usecase.LogoutResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 func (m *MockAuthUsecase) GetUserByID(ctx context.Context, userID int64) (*entity.User, error) {
 	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
 	ret0, _ := ret[0].(*entity.User)
@@ -97,6 +139,26 @@ func (mr *MockAuthUsecaseRecorder) GetUser(ctx, req interface{}) *gomock.Call {
 	)
 }
 
+func (mr *MockAuthUsecaseRecorder) GetUsers(ctx, req interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(
+		mr.mock,
+		"GetUsers",
+		reflect.TypeOf((*MockAuthUsecase)(nil).GetUsers),
+		ctx,
+		req,
+	)
+}
+
+func (mr *MockAuthUsecaseRecorder) GetUsersByUsernames(ctx, req interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(
+		mr.mock,
+		"GetUsersByUsernames",
+		reflect.TypeOf((*MockAuthUsecase)(nil).GetUsersByUsernames),
+		ctx,
+		req,
+	)
+}
+
 func (mr *MockAuthUsecaseRecorder) ValidateToken(ctx, req interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(
 		mr.mock,
@@ -107,6 +169,46 @@ func (mr *MockAuthUsecaseRecorder) ValidateToken(ctx, req interface{}) *gomock.C
 	)
 }
 
+func (mr *MockAuthUsecaseRecorder) Refresh(ctx, req interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(
+		mr.mock,
+		"Refresh",
+		reflect.TypeOf((*MockAuthUsecase)(nil).Refresh),
+		ctx,
+		req,
+	)
+}
+
+func (mr *MockAuthUsecaseRecorder) Logout(ctx, req interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(
+		mr.mock,
+		"Logout",
+		reflect.TypeOf((*MockAuthUsecase)(nil).Logout),
+		ctx,
+		req,
+	)
+}
+
+func (mr *MockAuthUsecaseRecorder) LogoutAll(ctx, req interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(
+		mr.mock,
+		"LogoutAll",
+		reflect.TypeOf((*MockAuthUsecase)(nil).LogoutAll),
+		ctx,
+		req,
+	)
+}
+
+func (mr *MockAuthUsecaseRecorder) RevokeUserSessions(ctx, req interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(
+		mr.mock,
+		"RevokeUserSessions",
+		reflect.TypeOf((*MockAuthUsecase)(nil).RevokeUserSessions),
+		ctx,
+		req,
+	)
+}
+
 func (mr *MockAuthUsecaseRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(
 		mr.mock,
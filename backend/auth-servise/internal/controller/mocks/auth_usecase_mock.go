@@ -51,6 +51,36 @@ func (mr *MockAuthUsecaseInterfaceMockRecorder) GetUser(ctx, req interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).GetUser), ctx, req)
 }
 
+// GetUsers mocks base method.
+func (m *MockAuthUsecaseInterface) GetUsers(ctx context.Context, req *usecase.GetUsersRequest) (*usecase.GetUsersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx, req)
+	ret0, _ := ret[0].(*usecase.GetUsersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockAuthUsecaseInterfaceMockRecorder) GetUsers(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).GetUsers), ctx, req)
+}
+
+// GetUsersByUsernames mocks base method.
+func (m *MockAuthUsecaseInterface) GetUsersByUsernames(ctx context.Context, req *usecase.GetUsersByUsernamesRequest) (*usecase.GetUsersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByUsernames", ctx, req)
+	ret0, _ := ret[0].(*usecase.GetUsersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByUsernames indicates an expected call of GetUsersByUsernames.
+func (mr *MockAuthUsecaseInterfaceMockRecorder) GetUsersByUsernames(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByUsernames", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).GetUsersByUsernames), ctx, req)
+}
+
 // GetUserByID mocks base method.
 func (m *MockAuthUsecaseInterface) GetUserByID(ctx context.Context, userID string) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +111,51 @@ func (mr *MockAuthUsecaseInterfaceMockRecorder) Login(ctx, req interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).Login), ctx, req)
 }
 
+// Logout mocks base method.
+func (m *MockAuthUsecaseInterface) Logout(ctx context.Context, req *usecase.LogoutRequest) (*usecase.LogoutResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, req)
+	ret0, _ := ret[0].(*usecase.LogoutResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthUsecaseInterfaceMockRecorder) Logout(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).Logout), ctx, req)
+}
+
+// LogoutAll mocks base method.
+func (m *MockAuthUsecaseInterface) LogoutAll(ctx context.Context, req *usecase.LogoutRequest) (*usecase.LogoutResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, req)
+	ret0, _ := ret[0].(*usecase.LogoutResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockAuthUsecaseInterfaceMockRecorder) LogoutAll(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).LogoutAll), ctx, req)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthUsecaseInterface) Refresh(ctx context.Context, req *usecase.RefreshRequest) (*usecase.RefreshResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, req)
+	ret0, _ := ret[0].(*usecase.RefreshResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthUsecaseInterfaceMockRecorder) Refresh(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).Refresh), ctx, req)
+}
+
 // Register mocks base method.
 func (m *MockAuthUsecaseInterface) Register(ctx context.Context, req *usecase.RegisterRequest) (*usecase.RegisterResponse, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +171,21 @@ func (mr *MockAuthUsecaseInterfaceMockRecorder) Register(ctx, req interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).Register), ctx, req)
 }
 
+// RevokeUserSessions mocks base method.
+func (m *MockAuthUsecaseInterface) RevokeUserSessions(ctx context.Context, req *usecase.RevokeUserSessionsRequest) (*usecase.LogoutResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSessions", ctx, req)
+	ret0, _ := ret[0].(*usecase.LogoutResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeUserSessions indicates an expected call of RevokeUserSessions.
+func (mr *MockAuthUsecaseInterfaceMockRecorder) RevokeUserSessions(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSessions", reflect.TypeOf((*MockAuthUsecaseInterface)(nil).RevokeUserSessions), ctx, req)
+}
+
 // ValidateToken mocks base method.
 func (m *MockAuthUsecaseInterface) ValidateToken(ctx context.Context, req *usecase.ValidateTokenRequest) (*usecase.ValidateTokenResponse, error) {
 	m.ctrl.T.Helper()
@@ -4,8 +4,15 @@ import (
 	"time"
 )
 
+// Session — один выданный refresh-токен. Токены, полученные друг из друга
+// при обновлении, объединены общим FamilyID.
 type Session struct {
-	UserID    int64     `db:"user_id"`
-	Token     string    `db:"token"`
-	ExpiresAt time.Time `db:"expires_at"`
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	FamilyID  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
 }
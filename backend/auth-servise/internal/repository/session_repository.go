@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	domain "github.com/Mandarinka0707/newRepoGOODarhit/internal/entity"
 	"github.com/jmoiron/sqlx"
@@ -9,7 +10,15 @@ import (
 
 type SessionRepository interface {
 	CreateSession(ctx context.Context, session *domain.Session) error
-	GetSessionByToken(ctx context.Context, token string) (*domain.Session, error)
+	GetSessionByToken(ctx context.Context, tokenHash string) (*domain.Session, error)
+	// MarkSessionUsed помечает refresh-токен использованным. Возвращает false,
+	// если токен уже был использован раньше.
+	MarkSessionUsed(ctx context.Context, id int64, usedAt time.Time) (bool, error)
+	RevokeSessionFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+	RevokeUserSessions(ctx context.Context, userID int64, revokedAt time.Time) error
+	// IsSessionActive сообщает, есть ли в семействе не отозванный и не
+	// истёкший refresh-токен.
+	IsSessionActive(ctx context.Context, familyID string, now time.Time) (bool, error)
 }
 
 type sessionRepository struct {
@@ -21,14 +30,51 @@ func NewSessionRepository(db *sqlx.DB) SessionRepository {
 }
 
 func (r *sessionRepository) CreateSession(ctx context.Context, session *domain.Session) error {
-	query := `INSERT INTO sessions (user_id, token, expires_at) VALUES ($1, $2, $3)`
-	_, err := r.db.ExecContext(ctx, query, session.UserID, session.Token, session.ExpiresAt)
-	return err
+	query := `INSERT INTO sessions (user_id, family_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	return r.db.QueryRowContext(ctx, query,
+		session.UserID,
+		session.FamilyID,
+		session.TokenHash,
+		session.ExpiresAt,
+		session.CreatedAt,
+	).Scan(&session.ID)
 }
 
-func (r *sessionRepository) GetSessionByToken(ctx context.Context, token string) (*domain.Session, error) {
-	query := `SELECT user_id, token, expires_at FROM sessions WHERE token = $1`
+func (r *sessionRepository) GetSessionByToken(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, created_at, used_at, revoked_at FROM sessions WHERE token_hash = $1`
 	session := &domain.Session{}
-	err := r.db.GetContext(ctx, session, query, token)
+	err := r.db.GetContext(ctx, session, query, tokenHash)
 	return session, err
 }
+
+func (r *sessionRepository) MarkSessionUsed(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+	query := `UPDATE sessions SET used_at = $1 WHERE id = $2 AND used_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, usedAt, id)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (r *sessionRepository) RevokeSessionFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	query := `UPDATE sessions SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, revokedAt, familyID)
+	return err
+}
+
+func (r *sessionRepository) RevokeUserSessions(ctx context.Context, userID int64, revokedAt time.Time) error {
+	query := `UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, revokedAt, userID)
+	return err
+}
+
+func (r *sessionRepository) IsSessionActive(ctx context.Context, familyID string, now time.Time) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM sessions WHERE family_id = $1 AND revoked_at IS NULL AND expires_at > $2)`
+	var active bool
+	err := r.db.GetContext(ctx, &active, query, familyID, now)
+	return active, err
+}
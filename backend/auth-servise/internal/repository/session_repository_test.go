@@ -27,32 +27,37 @@ func TestCreateSession(t *testing.T) {
 		name        string
 		session     *entity.Session
 		mock        func()
+		expectedID  int64
 		expectedErr error
 	}{
 		{
 			name: "Success",
 			session: &entity.Session{
 				UserID:    1,
-				Token:     "test-token",
+				FamilyID:  "family",
+				TokenHash: "test-hash",
 				ExpiresAt: time.Now().Add(24 * time.Hour),
+				CreatedAt: time.Now(),
 			},
 			mock: func() {
-				mock.ExpectExec(`INSERT INTO sessions \(user_id, token, expires_at\) VALUES \(\$1, \$2, \$3\)`).
-					WithArgs(1, "test-token", sqlmock.AnyArg()).
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery(`INSERT INTO sessions \(user_id, family_id, token_hash, expires_at, created_at\) VALUES \(\$1, \$2, \$3, \$4, \$5\) RETURNING id`).
+					WithArgs(1, "family", "test-hash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
 			},
+			expectedID:  7,
 			expectedErr: nil,
 		},
 		{
 			name: "Empty Token",
 			session: &entity.Session{
 				UserID:    1,
-				Token:     "",
+				FamilyID:  "family",
+				TokenHash: "",
 				ExpiresAt: time.Now().Add(24 * time.Hour),
 			},
 			mock: func() {
-				mock.ExpectExec(`INSERT INTO sessions \(user_id, token, expires_at\) VALUES \(\$1, \$2, \$3\)`).
-					WithArgs(1, "", sqlmock.AnyArg()).
+				mock.ExpectQuery(`INSERT INTO sessions`).
+					WithArgs(1, "family", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnError(errors.New("empty token"))
 			},
 			expectedErr: errors.New("empty token"),
@@ -64,6 +69,7 @@ func TestCreateSession(t *testing.T) {
 			tt.mock()
 			err := r.CreateSession(context.Background(), tt.session)
 			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedID, tt.session.ID)
 		})
 	}
 }
@@ -79,6 +85,8 @@ func TestGetSessionByToken(t *testing.T) {
 	r := &sessionRepository{db: sqlxDB}
 
 	expiresAt := time.Now().Add(24 * time.Hour)
+	usedAt := time.Now()
+	columns := []string{"id", "user_id", "family_id", "token_hash", "expires_at", "created_at", "used_at", "revoked_at"}
 
 	tests := []struct {
 		name        string
@@ -89,27 +97,30 @@ func TestGetSessionByToken(t *testing.T) {
 	}{
 		{
 			name:  "Success",
-			token: "valid-token",
+			token: "valid-hash",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"user_id", "token", "expires_at"}).
-					AddRow(1, "valid-token", expiresAt)
-				mock.ExpectQuery(`SELECT user_id, token, expires_at FROM sessions WHERE token = \$1`).
-					WithArgs("valid-token").
+				rows := sqlmock.NewRows(columns).
+					AddRow(3, 1, "family", "valid-hash", expiresAt, usedAt, usedAt, nil)
+				mock.ExpectQuery(`SELECT (.+) FROM sessions WHERE token_hash = \$1`).
+					WithArgs("valid-hash").
 					WillReturnRows(rows)
 			},
 			expected: &entity.Session{
+				ID:        3,
 				UserID:    1,
-				Token:     "valid-token",
+				FamilyID:  "family",
+				TokenHash: "valid-hash",
 				ExpiresAt: expiresAt,
+				UsedAt:    &usedAt,
 			},
 			expectedErr: nil,
 		},
 		{
 			name:  "Not Found",
-			token: "invalid-token",
+			token: "invalid-hash",
 			mock: func() {
-				mock.ExpectQuery(`SELECT user_id, token, expires_at FROM sessions WHERE token = \$1`).
-					WithArgs("invalid-token").
+				mock.ExpectQuery(`SELECT (.+) FROM sessions WHERE token_hash = \$1`).
+					WithArgs("invalid-hash").
 					WillReturnError(sql.ErrNoRows)
 			},
 			expected:    nil,
@@ -126,10 +137,137 @@ func TestGetSessionByToken(t *testing.T) {
 				assert.Equal(t, tt.expectedErr, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.expected.ID, result.ID)
 				assert.Equal(t, tt.expected.UserID, result.UserID)
-				assert.Equal(t, tt.expected.Token, result.Token)
+				assert.Equal(t, tt.expected.FamilyID, result.FamilyID)
+				assert.Equal(t, tt.expected.TokenHash, result.TokenHash)
 				assert.WithinDuration(t, tt.expected.ExpiresAt, result.ExpiresAt, time.Second)
+				assert.NotNil(t, result.UsedAt)
+				assert.Nil(t, result.RevokedAt)
 			}
 		})
 	}
 }
+
+func TestMarkSessionUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &sessionRepository{db: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE sessions SET used_at = \$1 WHERE id = \$2 AND used_at IS NULL`).
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	marked, err := r.MarkSessionUsed(context.Background(), 3, now)
+	assert.NoError(t, err)
+	assert.True(t, marked)
+
+	mock.ExpectExec(`UPDATE sessions SET used_at`).
+		WithArgs(now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	marked, err = r.MarkSessionUsed(context.Background(), 3, now)
+	assert.NoError(t, err)
+	assert.False(t, marked)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeSessionFamily(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &sessionRepository{db: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE sessions SET revoked_at = \$1 WHERE family_id = \$2 AND revoked_at IS NULL`).
+		WithArgs(now, "family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	assert.NoError(t, r.RevokeSessionFamily(context.Background(), "family", now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &sessionRepository{db: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE sessions SET revoked_at = \$1 WHERE user_id = \$2 AND revoked_at IS NULL`).
+		WithArgs(now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, r.RevokeUserSessions(context.Background(), 1, now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsSessionActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := &sessionRepository{db: sqlx.NewDb(db, "sqlmock")}
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		mock        func()
+		expected    bool
+		expectedErr error
+	}{
+		{
+			name: "Active",
+			mock: func() {
+				mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM sessions WHERE family_id = \$1 AND revoked_at IS NULL AND expires_at > \$2\)`).
+					WithArgs("family", now).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			},
+			expected: true,
+		},
+		{
+			name: "Revoked",
+			mock: func() {
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs("family", now).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expected: false,
+		},
+		{
+			name: "Database error",
+			mock: func() {
+				mock.ExpectQuery(`SELECT EXISTS`).
+					WithArgs("family", now).
+					WillReturnError(errors.New("database error"))
+			},
+			expectedErr: errors.New("database error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			active, err := r.IsSessionActive(context.Background(), "family", now)
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, active)
+		})
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
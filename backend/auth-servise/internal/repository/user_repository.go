@@ -7,12 +7,15 @@ import (
 
 	domain "github.com/Mandarinka0707/newRepoGOODarhit/internal/entity"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) (int64, error)
 	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
 	GetUserByID(ctx context.Context, id int64) (*domain.User, error) // Добавьте этот метод
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]*domain.User, error)
+	GetUsersByUsernames(ctx context.Context, usernames []string) ([]*domain.User, error)
 }
 
 type userRepository struct {
@@ -57,3 +60,32 @@ func (r *userRepository) GetUserByID(ctx context.Context, id int64) (*domain.Use
 	}
 	return user, nil
 }
+
+// GetUsersByIDs возвращает найденных пользователей одним запросом.
+// Несуществующие id просто отсутствуют в результате, порядок не гарантируется.
+func (r *userRepository) GetUsersByIDs(ctx context.Context, ids []int64) ([]*domain.User, error) {
+	users := []*domain.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	query := `SELECT id, username, password, role, created_at FROM users WHERE id = ANY($1)`
+	if err := r.db.SelectContext(ctx, &users, query, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUsersByUsernames — то же для имен; имена сравниваются точно.
+func (r *userRepository) GetUsersByUsernames(ctx context.Context, usernames []string) ([]*domain.User, error) {
+	users := []*domain.User{}
+	if len(usernames) == 0 {
+		return users, nil
+	}
+
+	query := `SELECT id, username, password, role, created_at FROM users WHERE username = ANY($1)`
+	if err := r.db.SelectContext(ctx, &users, query, pq.Array(usernames)); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
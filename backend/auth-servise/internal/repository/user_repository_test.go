@@ -10,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	domain "github.com/Mandarinka0707/newRepoGOODarhit/internal/entity"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -214,3 +215,68 @@ func TestGetUserByID_Error(t *testing.T) {
 	assert.Nil(t, user)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetUsersByIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT id, username, password, role, created_at FROM users WHERE id = ANY\(\$1\)`).
+		WithArgs(pq.Array([]int64{1, 2, 3})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "role", "created_at"}).
+			AddRow(1, "alice", "hash", "user", now).
+			AddRow(3, "carol", "hash", "admin", now))
+
+	users, err := repo.GetUsersByIDs(context.Background(), []int64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "alice", users[0].Username)
+	assert.Equal(t, "carol", users[1].Username)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUsersByIDs_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+
+	users, err := repo.GetUsersByIDs(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUsersByUsernames(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT id, username, password, role, created_at FROM users WHERE username = ANY\(\$1\)`).
+		WithArgs(pq.Array([]string{"alice", "ghost"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "role", "created_at"}).
+			AddRow(1, "alice", "hash", "user", now))
+
+	users, err := repo.GetUsersByUsernames(context.Background(), []string{"alice", "ghost"})
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, int64(1), users[0].ID)
+
+	users, err = repo.GetUsersByUsernames(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,22 +3,36 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/Mandarinka0707/newRepoGOODarhit/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/pkg/auth"
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrInvalidToken        = errors.New("invalid token")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrTooManyUsers        = errors.New("too many user ids in one request")
+)
+
+// MaxUsersBatch ограничивает GetUsers, чтобы один вызов не выгружал всю таблицу.
+const MaxUsersBatch = 500
+
 type AuthUsecase struct {
 	userRepo    repository.UserRepository
 	sessionRepo repository.SessionRepository
 	cfg         *auth.Config
 	logger      *zap.Logger
+	sessions    *sessionCache
 }
 
 type AuthUsecaseInterface interface {
@@ -27,6 +41,12 @@ type AuthUsecaseInterface interface {
 	GetUserByID(ctx context.Context, userID int64) (*entity.User, error)
 	ValidateToken(ctx context.Context, req *ValidateTokenRequest) (*ValidateTokenResponse, error)
 	GetUser(ctx context.Context, req *GetUserRequest) (*GetUserResponse, error)
+	GetUsers(ctx context.Context, req *GetUsersRequest) (*GetUsersResponse, error)
+	GetUsersByUsernames(ctx context.Context, req *GetUsersByUsernamesRequest) (*GetUsersResponse, error)
+	Refresh(ctx context.Context, req *RefreshRequest) (*RefreshResponse, error)
+	Logout(ctx context.Context, req *LogoutRequest) (*LogoutResponse, error)
+	LogoutAll(ctx context.Context, req *LogoutRequest) (*LogoutResponse, error)
+	RevokeUserSessions(ctx context.Context, req *RevokeUserSessionsRequest) (*LogoutResponse, error)
 }
 
 func NewAuthUsecase(
@@ -40,6 +60,7 @@ func NewAuthUsecase(
 		sessionRepo: sessionRepo,
 		cfg:         cfg,
 		logger:      logger,
+		sessions:    newSessionCache(cfg.SessionCacheTTL),
 	}
 }
 
@@ -83,33 +104,133 @@ func (uc *AuthUsecase) Login(
 		return nil, fmt.Errorf("invalid username or password")
 	}
 
+	sessionID, err := auth.NewSessionID()
+	if err != nil {
+		uc.logger.Error("failed to generate session id", zap.Error(err))
+		return nil, fmt.Errorf("internal server error")
+	}
+
+	token, refreshToken, err := uc.issueTokens(ctx, user, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		Username:     user.Username,
+	}, nil
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов. Каждый
+// refresh-токен одноразовый: повторное предъявление уже использованного
+// токена считается кражей, и всё семейство сессии отзывается.
+func (uc *AuthUsecase) Refresh(
+	ctx context.Context,
+	req *RefreshRequest,
+) (*RefreshResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := uc.sessionRepo.GetSessionByToken(ctx, auth.HashRefreshToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidRefreshToken
+		}
+		uc.logger.Error("failed to get session", zap.Error(err))
+		return nil, fmt.Errorf("internal server error")
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if session.UsedAt != nil {
+		return nil, uc.revokeReusedFamily(ctx, session)
+	}
+
+	marked, err := uc.sessionRepo.MarkSessionUsed(ctx, session.ID, now)
+	if err != nil {
+		uc.logger.Error("failed to mark session used", zap.Error(err))
+		return nil, fmt.Errorf("internal server error")
+	}
+	if !marked {
+		// Тот же токен параллельно обменяли в другом запросе.
+		return nil, uc.revokeReusedFamily(ctx, session)
+	}
+
+	user, err := uc.userRepo.GetUserByID(ctx, session.UserID)
+	if err != nil || user == nil {
+		uc.logger.Warn("session user not found", zap.Int64("user_id", session.UserID), zap.Error(err))
+		return nil, ErrInvalidRefreshToken
+	}
+
+	token, refreshToken, err := uc.issueTokens(ctx, user, session.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RefreshResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (uc *AuthUsecase) revokeReusedFamily(ctx context.Context, session *entity.Session) error {
+	uc.logger.Warn("refresh token reuse detected",
+		zap.Int64("user_id", session.UserID),
+		zap.String("session_id", session.FamilyID),
+	)
+	if err := uc.sessionRepo.RevokeSessionFamily(ctx, session.FamilyID, time.Now()); err != nil {
+		uc.logger.Error("failed to revoke session family", zap.Error(err))
+	}
+	// Access-токены семейства должны перестать проходить ValidateToken
+	// сразу, а не после истечения кэша.
+	uc.sessions.revoke(session.FamilyID)
+	return ErrRefreshTokenReused
+}
+
+// issueTokens выпускает access-токен и новый refresh-токен в семействе sessionID.
+func (uc *AuthUsecase) issueTokens(
+	ctx context.Context,
+	user *entity.User,
+	sessionID string,
+) (string, string, error) {
 	token, err := auth.GenerateToken(
+		uc.cfg.Keys,
 		user.ID,
 		user.Role,
 		user.Username,
-		uc.cfg.TokenSecret,
+		sessionID,
 		uc.cfg.TokenExpiration,
 	)
 	if err != nil {
 		uc.logger.Error("failed to generate token", zap.Error(err))
-		return nil, fmt.Errorf("internal server error")
+		return "", "", fmt.Errorf("internal server error")
 	}
 
+	refreshToken, err := auth.GenerateRefreshToken()
+	if err != nil {
+		uc.logger.Error("failed to generate refresh token", zap.Error(err))
+		return "", "", fmt.Errorf("internal server error")
+	}
+
+	now := time.Now()
 	session := &entity.Session{
 		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(uc.cfg.TokenExpiration),
+		FamilyID:  sessionID,
+		TokenHash: auth.HashRefreshToken(refreshToken),
+		ExpiresAt: now.Add(uc.cfg.RefreshTokenExpiration),
+		CreatedAt: now,
 	}
 
 	if err := uc.sessionRepo.CreateSession(ctx, session); err != nil {
 		uc.logger.Error("failed to create session", zap.Error(err))
-		return nil, fmt.Errorf("internal server error")
+		return "", "", fmt.Errorf("internal server error")
 	}
 
-	return &LoginResponse{
-		Token:    token,
-		Username: user.Username,
-	}, nil
+	return token, refreshToken, nil
 }
 
 func (uc *AuthUsecase) GetUserByID(
@@ -125,35 +246,177 @@ func (uc *AuthUsecase) ValidateToken(
 ) (*ValidateTokenResponse, error) {
 	uc.logger.Info("Token validation request")
 
-	token, err := auth.ParseToken(req.Token, uc.cfg.TokenSecret)
+	claims, ok := uc.parseAccessToken(req.Token)
+	if !ok {
+		return &ValidateTokenResponse{Valid: false}, nil
+	}
+
+	active, err := uc.isSessionActive(ctx, claims)
+	if err != nil {
+		uc.logger.Error("failed to check session", zap.Error(err))
+		return nil, fmt.Errorf("internal server error")
+	}
+	if !active {
+		uc.logger.Warn("Session revoked or expired", zap.String("session_id", claims.SessionID))
+		return &ValidateTokenResponse{Valid: false}, nil
+	}
+
+	return &ValidateTokenResponse{
+		Valid:    true,
+		UserID:   claims.UserID,
+		Username: claims.Username,
+		Role:     claims.Role,
+	}, nil
+}
+
+// Logout отзывает сессию, к которой привязан токен.
+func (uc *AuthUsecase) Logout(
+	ctx context.Context,
+	req *LogoutRequest,
+) (*LogoutResponse, error) {
+	claims, err := uc.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.sessionRepo.RevokeSessionFamily(ctx, claims.SessionID, time.Now()); err != nil {
+		uc.logger.Error("failed to revoke session", zap.Error(err))
+		return nil, fmt.Errorf("internal server error")
+	}
+	uc.sessions.revoke(claims.SessionID)
+
+	return &LogoutResponse{}, nil
+}
+
+// LogoutAll отзывает все сессии владельца токена на всех устройствах.
+func (uc *AuthUsecase) LogoutAll(
+	ctx context.Context,
+	req *LogoutRequest,
+) (*LogoutResponse, error) {
+	claims, err := uc.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.revokeUserSessions(ctx, claims.UserID); err != nil {
+		return nil, err
+	}
+	return &LogoutResponse{}, nil
+}
+
+// RevokeUserSessions позволяет администратору завершить все сессии пользователя.
+func (uc *AuthUsecase) RevokeUserSessions(
+	ctx context.Context,
+	req *RevokeUserSessionsRequest,
+) (*LogoutResponse, error) {
+	claims, err := uc.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Role != entity.RoleAdmin {
+		return nil, ErrPermissionDenied
+	}
+
+	uc.logger.Info("Revoking user sessions",
+		zap.Int64("admin_id", claims.UserID),
+		zap.Int64("user_id", req.UserID),
+	)
+	if err := uc.revokeUserSessions(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	return &LogoutResponse{}, nil
+}
+
+func (uc *AuthUsecase) revokeUserSessions(ctx context.Context, userID int64) error {
+	if err := uc.sessionRepo.RevokeUserSessions(ctx, userID, time.Now()); err != nil {
+		uc.logger.Error("failed to revoke user sessions", zap.Error(err))
+		return fmt.Errorf("internal server error")
+	}
+	uc.sessions.revokeUser(userID)
+	return nil
+}
+
+// tokenClaims — проверенное содержимое access-токена.
+type tokenClaims struct {
+	UserID    int64
+	Username  string
+	Role      string
+	SessionID string
+}
+
+// authenticate проверяет подпись токена и то, что его сессия не отозвана.
+func (uc *AuthUsecase) authenticate(ctx context.Context, tokenString string) (*tokenClaims, error) {
+	claims, ok := uc.parseAccessToken(tokenString)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	active, err := uc.isSessionActive(ctx, claims)
+	if err != nil {
+		uc.logger.Error("failed to check session", zap.Error(err))
+		return nil, fmt.Errorf("internal server error")
+	}
+	if !active {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (uc *AuthUsecase) parseAccessToken(tokenString string) (*tokenClaims, bool) {
+	token, err := auth.ParseToken(tokenString, uc.cfg.Keys)
 	if err != nil || !token.Valid {
 		uc.logger.Warn("Invalid token", zap.Error(err))
-		return &ValidateTokenResponse{Valid: false}, nil
+		return nil, false
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
 		uc.logger.Warn("Invalid token claims")
-		return &ValidateTokenResponse{Valid: false}, nil
+		return nil, false
 	}
 
 	userID, ok := claims["user_id"].(float64)
 	if !ok {
 		uc.logger.Warn("Invalid user_id in token")
-		return &ValidateTokenResponse{Valid: false}, nil
+		return nil, false
 	}
 
 	role, ok := claims["role"].(string)
 	if !ok {
 		uc.logger.Warn("Invalid role in token")
-		return &ValidateTokenResponse{Valid: false}, nil
+		return nil, false
 	}
 
-	return &ValidateTokenResponse{
-		Valid:  true,
-		UserID: int64(userID),
-		Role:   role,
-	}, nil
+	// Токены без сессии выпускались до появления refresh-токенов
+	// и отозвать их нельзя, поэтому они не принимаются.
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		uc.logger.Warn("Invalid sid in token")
+		return nil, false
+	}
+
+	username, _ := claims["username"].(string)
+
+	return &tokenClaims{
+		UserID:    int64(userID),
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
+	}, true
+}
+
+func (uc *AuthUsecase) isSessionActive(ctx context.Context, claims *tokenClaims) (bool, error) {
+	now := time.Now()
+	if active, ok := uc.sessions.get(claims.SessionID, now); ok {
+		return active, nil
+	}
+
+	active, err := uc.sessionRepo.IsSessionActive(ctx, claims.SessionID, now)
+	if err != nil {
+		return false, err
+	}
+	uc.sessions.set(claims.SessionID, claims.UserID, active, now)
+	return active, nil
 }
 
 func (uc *AuthUsecase) GetUser(
@@ -170,3 +433,58 @@ func (uc *AuthUsecase) GetUser(
 
 	return &GetUserResponse{User: user}, nil
 }
+
+// GetUsers отдает профили пачкой; повторяющиеся id запрашиваются один раз.
+func (uc *AuthUsecase) GetUsers(
+	ctx context.Context,
+	req *GetUsersRequest,
+) (*GetUsersResponse, error) {
+	ids := make([]int64, 0, len(req.UserIDs))
+	seen := make(map[int64]struct{}, len(req.UserIDs))
+	for _, id := range req.UserIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) > MaxUsersBatch {
+		return nil, ErrTooManyUsers
+	}
+
+	users, err := uc.userRepo.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		uc.logger.Error("Failed to get users", zap.Error(err))
+		return nil, err
+	}
+
+	return &GetUsersResponse{Users: users}, nil
+}
+
+// GetUsersByUsernames находит пользователей по именам, например упомянутых
+// через @username. Пустые и повторяющиеся имена отбрасываются.
+func (uc *AuthUsecase) GetUsersByUsernames(
+	ctx context.Context,
+	req *GetUsersByUsernamesRequest,
+) (*GetUsersResponse, error) {
+	usernames := make([]string, 0, len(req.Usernames))
+	seen := make(map[string]struct{}, len(req.Usernames))
+	for _, username := range req.Usernames {
+		if _, ok := seen[username]; ok || username == "" {
+			continue
+		}
+		seen[username] = struct{}{}
+		usernames = append(usernames, username)
+	}
+	if len(usernames) > MaxUsersBatch {
+		return nil, ErrTooManyUsers
+	}
+
+	users, err := uc.userRepo.GetUsersByUsernames(ctx, usernames)
+	if err != nil {
+		uc.logger.Error("Failed to get users by usernames", zap.Error(err))
+		return nil, err
+	}
+
+	return &GetUsersResponse{Users: users}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -42,6 +43,22 @@ func (m *MockUserRepo) GetUserByID(ctx context.Context, id int64) (*entity.User,
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (m *MockUserRepo) GetUsersByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepo) GetUsersByUsernames(ctx context.Context, usernames []string) ([]*entity.User, error) {
+	args := m.Called(ctx, usernames)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.User), args.Error(1)
+}
+
 type MockSessionRepo struct {
 	mock.Mock
 }
@@ -59,12 +76,46 @@ func (m *MockSessionRepo) GetSessionByToken(ctx context.Context, token string) (
 	return args.Get(0).(*entity.Session), args.Error(1)
 }
 
+func (m *MockSessionRepo) MarkSessionUsed(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, usedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSessionRepo) RevokeSessionFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	args := m.Called(ctx, familyID, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepo) RevokeUserSessions(ctx context.Context, userID int64, revokedAt time.Time) error {
+	args := m.Called(ctx, userID, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepo) IsSessionActive(ctx context.Context, familyID string, now time.Time) (bool, error) {
+	args := m.Called(ctx, familyID, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func newTestKeyring(t *testing.T) *auth.Keyring {
+	signing, err := auth.GenerateSigningKey()
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+	keys, err := auth.NewKeyring(signing)
+	if err != nil {
+		t.Fatalf("failed to build keyring: %v", err)
+	}
+	return keys
+}
+
 func setupTest(t *testing.T) (*AuthUsecase, *MockUserRepo, *MockSessionRepo) {
 	userRepo := new(MockUserRepo)
 	sessionRepo := new(MockSessionRepo)
 	cfg := &auth.Config{
-		TokenSecret:     "test-secret",
-		TokenExpiration: time.Hour,
+		Keys:                   newTestKeyring(t),
+		TokenExpiration:        time.Hour,
+		RefreshTokenExpiration: 24 * time.Hour,
+		SessionCacheTTL:        time.Minute,
 	}
 
 	logger := zaptest.NewLogger(t)
@@ -167,7 +218,12 @@ func TestLogin_Success(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, resp.Token)
+	assert.NotEmpty(t, resp.RefreshToken)
 	assert.Equal(t, "testuser", resp.Username)
+
+	session := sessionRepo.Calls[0].Arguments.Get(1).(*entity.Session)
+	assert.Equal(t, auth.HashRefreshToken(resp.RefreshToken), session.TokenHash)
+	assert.NotEmpty(t, session.FamilyID)
 	userRepo.AssertExpectations(t)
 	sessionRepo.AssertExpectations(t)
 }
@@ -297,6 +353,79 @@ func TestGetUser_DBError(t *testing.T) {
 	userRepo.AssertExpectations(t)
 }
 
+func TestGetUsers_DeduplicatesIDs(t *testing.T) {
+	uc, userRepo, _ := setupTest(t)
+	ctx := context.Background()
+
+	users := []*entity.User{{ID: 1, Username: "alice"}, {ID: 2, Username: "bob"}}
+	userRepo.On("GetUsersByIDs", ctx, []int64{1, 2, 3}).Return(users, nil)
+
+	resp, err := uc.GetUsers(ctx, &GetUsersRequest{UserIDs: []int64{1, 2, 1, 3, 2}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, users, resp.Users)
+	userRepo.AssertExpectations(t)
+}
+
+func TestGetUsers_TooMany(t *testing.T) {
+	uc, userRepo, _ := setupTest(t)
+	ctx := context.Background()
+
+	ids := make([]int64, MaxUsersBatch+1)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+
+	resp, err := uc.GetUsers(ctx, &GetUsersRequest{UserIDs: ids})
+
+	assert.ErrorIs(t, err, ErrTooManyUsers)
+	assert.Nil(t, resp)
+	userRepo.AssertNotCalled(t, "GetUsersByIDs", mock.Anything, mock.Anything)
+}
+
+func TestGetUsers_DBError(t *testing.T) {
+	uc, userRepo, _ := setupTest(t)
+	ctx := context.Background()
+
+	userRepo.On("GetUsersByIDs", ctx, []int64{1}).Return(nil, errors.New("db error"))
+
+	resp, err := uc.GetUsers(ctx, &GetUsersRequest{UserIDs: []int64{1}})
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	userRepo.AssertExpectations(t)
+}
+
+func TestGetUsersByUsernames_Deduplicates(t *testing.T) {
+	uc, userRepo, _ := setupTest(t)
+	ctx := context.Background()
+
+	users := []*entity.User{{ID: 1, Username: "alice"}}
+	userRepo.On("GetUsersByUsernames", ctx, []string{"alice", "Alice"}).Return(users, nil)
+
+	resp, err := uc.GetUsersByUsernames(ctx, &GetUsersByUsernamesRequest{Usernames: []string{"alice", "", "Alice", "alice"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, users, resp.Users)
+	userRepo.AssertExpectations(t)
+}
+
+func TestGetUsersByUsernames_TooMany(t *testing.T) {
+	uc, userRepo, _ := setupTest(t)
+	ctx := context.Background()
+
+	usernames := make([]string, MaxUsersBatch+1)
+	for i := range usernames {
+		usernames[i] = fmt.Sprintf("user%d", i)
+	}
+
+	resp, err := uc.GetUsersByUsernames(ctx, &GetUsersByUsernamesRequest{Usernames: usernames})
+
+	assert.ErrorIs(t, err, ErrTooManyUsers)
+	assert.Nil(t, resp)
+	userRepo.AssertNotCalled(t, "GetUsersByUsernames", mock.Anything, mock.Anything)
+}
+
 func TestGetUser_Logging(t *testing.T) {
 	t.Run("Success logs info message", func(t *testing.T) {
 		uc, userRepo, _ := setupTest(t)
@@ -356,8 +485,9 @@ func setupTestWithLogObserver(t *testing.T) (*AuthUsecase, *MockUserRepo, *MockS
 	userRepo := new(MockUserRepo)
 	sessionRepo := new(MockSessionRepo)
 	cfg := &auth.Config{
-		TokenSecret:     "test-secret",
-		TokenExpiration: time.Hour,
+		Keys:                   newTestKeyring(t),
+		TokenExpiration:        time.Hour,
+		RefreshTokenExpiration: 24 * time.Hour,
 	}
 
 	core, recorded := observer.New(zap.InfoLevel)
@@ -445,3 +575,306 @@ func TestGetUserByID_NotFound(t *testing.T) {
 	assert.Nil(t, user)
 	userRepo.AssertExpectations(t)
 }
+
+func TestRefresh_Success(t *testing.T) {
+	uc, userRepo, sessionRepo := setupTest(t)
+	ctx := context.Background()
+
+	session := &entity.Session{
+		ID:        5,
+		UserID:    1,
+		FamilyID:  "family",
+		TokenHash: auth.HashRefreshToken("old-refresh"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	user := &entity.User{ID: 1, Username: "testuser", Role: "user"}
+
+	sessionRepo.On("GetSessionByToken", ctx, auth.HashRefreshToken("old-refresh")).Return(session, nil)
+	sessionRepo.On("MarkSessionUsed", ctx, int64(5), mock.AnythingOfType("time.Time")).Return(true, nil)
+	userRepo.On("GetUserByID", ctx, int64(1)).Return(user, nil)
+	sessionRepo.On("CreateSession", ctx, mock.MatchedBy(func(s *entity.Session) bool {
+		return s.FamilyID == "family" && s.UserID == 1
+	})).Return(nil)
+	sessionRepo.On("IsSessionActive", ctx, "family", mock.AnythingOfType("time.Time")).Return(true, nil)
+
+	resp, err := uc.Refresh(ctx, &RefreshRequest{RefreshToken: "old-refresh"})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	assert.NotEqual(t, "old-refresh", resp.RefreshToken)
+
+	validated, err := uc.ValidateToken(ctx, &ValidateTokenRequest{Token: resp.Token})
+	assert.NoError(t, err)
+	assert.True(t, validated.Valid)
+	assert.Equal(t, int64(1), validated.UserID)
+	assert.Equal(t, "testuser", validated.Username)
+	userRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	ctx := context.Background()
+
+	usedAt := time.Now().Add(-time.Minute)
+	session := &entity.Session{
+		ID:        5,
+		UserID:    1,
+		FamilyID:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+		UsedAt:    &usedAt,
+	}
+
+	sessionRepo.On("GetSessionByToken", ctx, auth.HashRefreshToken("old-refresh")).Return(session, nil)
+	sessionRepo.On("RevokeSessionFamily", ctx, "family", mock.AnythingOfType("time.Time")).Return(nil)
+
+	resp, err := uc.Refresh(ctx, &RefreshRequest{RefreshToken: "old-refresh"})
+
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Nil(t, resp)
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestRefresh_ReuseInvalidatesCachedSession(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	ctx := context.Background()
+	token := newAccessToken(t, uc, 1, "user", "family")
+
+	usedAt := time.Now().Add(-time.Minute)
+	session := &entity.Session{ID: 5, UserID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}
+
+	sessionRepo.On("IsSessionActive", ctx, "family", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	sessionRepo.On("GetSessionByToken", ctx, auth.HashRefreshToken("old-refresh")).Return(session, nil)
+	sessionRepo.On("RevokeSessionFamily", ctx, "family", mock.AnythingOfType("time.Time")).Return(nil)
+
+	resp, err := uc.ValidateToken(ctx, &ValidateTokenRequest{Token: token})
+	assert.NoError(t, err)
+	assert.True(t, resp.Valid)
+
+	_, err = uc.Refresh(ctx, &RefreshRequest{RefreshToken: "old-refresh"})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	resp, err = uc.ValidateToken(ctx, &ValidateTokenRequest{Token: token})
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	sessionRepo.AssertNumberOfCalls(t, "IsSessionActive", 1)
+}
+
+func TestRefresh_ConcurrentUseRevokesFamily(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	ctx := context.Background()
+
+	session := &entity.Session{ID: 5, UserID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+
+	sessionRepo.On("GetSessionByToken", ctx, auth.HashRefreshToken("old-refresh")).Return(session, nil)
+	sessionRepo.On("MarkSessionUsed", ctx, int64(5), mock.AnythingOfType("time.Time")).Return(false, nil)
+	sessionRepo.On("RevokeSessionFamily", ctx, "family", mock.AnythingOfType("time.Time")).Return(nil)
+
+	_, err := uc.Refresh(ctx, &RefreshRequest{RefreshToken: "old-refresh"})
+
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestRefresh_InvalidToken(t *testing.T) {
+	revokedAt := time.Now()
+
+	tests := []struct {
+		name    string
+		token   string
+		session *entity.Session
+		err     error
+	}{
+		{name: "Empty token", token: ""},
+		{name: "Unknown token", token: "unknown", err: sql.ErrNoRows},
+		{
+			name:    "Expired",
+			token:   "expired",
+			session: &entity.Session{ID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(-time.Minute)},
+		},
+		{
+			name:    "Revoked",
+			token:   "revoked",
+			session: &entity.Session{ID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _, sessionRepo := setupTest(t)
+			ctx := context.Background()
+
+			if tt.token != "" {
+				if tt.session != nil {
+					sessionRepo.On("GetSessionByToken", ctx, auth.HashRefreshToken(tt.token)).Return(tt.session, nil)
+				} else {
+					sessionRepo.On("GetSessionByToken", ctx, auth.HashRefreshToken(tt.token)).Return(nil, tt.err)
+				}
+			}
+
+			resp, err := uc.Refresh(ctx, &RefreshRequest{RefreshToken: tt.token})
+
+			assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+			assert.Nil(t, resp)
+			sessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func newAccessToken(t *testing.T, uc *AuthUsecase, userID int64, role, sessionID string) string {
+	token, err := auth.GenerateToken(uc.cfg.Keys, userID, role, "testuser", sessionID, time.Hour)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestValidateToken_RevokedSession(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	ctx := context.Background()
+	token := newAccessToken(t, uc, 1, "user", "family")
+
+	sessionRepo.On("IsSessionActive", ctx, "family", mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	resp, err := uc.ValidateToken(ctx, &ValidateTokenRequest{Token: token})
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestValidateToken_WithoutSessionID(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	token := newAccessToken(t, uc, 1, "user", "")
+
+	resp, err := uc.ValidateToken(context.Background(), &ValidateTokenRequest{Token: token})
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	sessionRepo.AssertNotCalled(t, "IsSessionActive", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestValidateToken_CachesSessionCheck(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	ctx := context.Background()
+	token := newAccessToken(t, uc, 1, "user", "family")
+
+	sessionRepo.On("IsSessionActive", ctx, "family", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+
+	for i := 0; i < 3; i++ {
+		resp, err := uc.ValidateToken(ctx, &ValidateTokenRequest{Token: token})
+		assert.NoError(t, err)
+		assert.True(t, resp.Valid)
+	}
+	sessionRepo.AssertNumberOfCalls(t, "IsSessionActive", 1)
+}
+
+func TestValidateToken_SessionCheckError(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	ctx := context.Background()
+	token := newAccessToken(t, uc, 1, "user", "family")
+
+	sessionRepo.On("IsSessionActive", ctx, "family", mock.AnythingOfType("time.Time")).
+		Return(false, errors.New("db error"))
+
+	resp, err := uc.ValidateToken(ctx, &ValidateTokenRequest{Token: token})
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestLogout_RevokesSession(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	ctx := context.Background()
+	token := newAccessToken(t, uc, 1, "user", "family")
+
+	sessionRepo.On("IsSessionActive", ctx, "family", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	sessionRepo.On("RevokeSessionFamily", ctx, "family", mock.AnythingOfType("time.Time")).Return(nil)
+
+	_, err := uc.Logout(ctx, &LogoutRequest{Token: token})
+	assert.NoError(t, err)
+
+	// Кэш сбрасывается сразу, повторного запроса в базу не нужно.
+	resp, err := uc.ValidateToken(ctx, &ValidateTokenRequest{Token: token})
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	sessionRepo.AssertExpectations(t)
+	sessionRepo.AssertNumberOfCalls(t, "IsSessionActive", 1)
+}
+
+func TestLogout_InvalidToken(t *testing.T) {
+	uc, _, _ := setupTest(t)
+
+	resp, err := uc.Logout(context.Background(), &LogoutRequest{Token: "invalid"})
+
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.Nil(t, resp)
+}
+
+func TestLogoutAll_RevokesUserSessions(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	ctx := context.Background()
+	phone := newAccessToken(t, uc, 1, "user", "phone")
+	laptop := newAccessToken(t, uc, 1, "user", "laptop")
+
+	sessionRepo.On("IsSessionActive", ctx, "phone", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	sessionRepo.On("IsSessionActive", ctx, "laptop", mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	sessionRepo.On("RevokeUserSessions", ctx, int64(1), mock.AnythingOfType("time.Time")).Return(nil)
+
+	resp, err := uc.ValidateToken(ctx, &ValidateTokenRequest{Token: laptop})
+	assert.NoError(t, err)
+	assert.True(t, resp.Valid)
+
+	_, err = uc.LogoutAll(ctx, &LogoutRequest{Token: phone})
+	assert.NoError(t, err)
+
+	resp, err = uc.ValidateToken(ctx, &ValidateTokenRequest{Token: laptop})
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		wantErr error
+	}{
+		{name: "Admin", role: entity.RoleAdmin},
+		{name: "Not admin", role: "user", wantErr: ErrPermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _, sessionRepo := setupTest(t)
+			ctx := context.Background()
+			token := newAccessToken(t, uc, 1, tt.role, "family")
+
+			sessionRepo.On("IsSessionActive", ctx, "family", mock.AnythingOfType("time.Time")).Return(true, nil)
+			if tt.wantErr == nil {
+				sessionRepo.On("RevokeUserSessions", ctx, int64(7), mock.AnythingOfType("time.Time")).Return(nil)
+			}
+
+			_, err := uc.RevokeUserSessions(ctx, &RevokeUserSessionsRequest{Token: token, UserID: 7})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				sessionRepo.AssertNotCalled(t, "RevokeUserSessions", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			sessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestValidateToken_UnknownKey(t *testing.T) {
+	uc, _, sessionRepo := setupTest(t)
+	// Токен подписан ключом, которого нет в связке сервиса.
+	token, err := auth.GenerateToken(newTestKeyring(t), 1, "user", "testuser", "family", time.Hour)
+	assert.NoError(t, err)
+
+	resp, err := uc.ValidateToken(context.Background(), &ValidateTokenRequest{Token: token})
+
+	assert.NoError(t, err)
+	assert.False(t, resp.Valid)
+	sessionRepo.AssertNotCalled(t, "IsSessionActive", mock.Anything, mock.Anything, mock.Anything)
+}
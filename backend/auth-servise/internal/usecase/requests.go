@@ -16,9 +16,30 @@ type ValidateTokenRequest struct {
 	Token string
 }
 
+type RefreshRequest struct {
+	RefreshToken string
+}
+
+type LogoutRequest struct {
+	Token string
+}
+
+type RevokeUserSessionsRequest struct {
+	Token  string
+	UserID int64
+}
+
 type GetUserRequest struct {
 	UserID int64
 }
+
+type GetUsersRequest struct {
+	UserIDs []int64
+}
+
+type GetUsersByUsernamesRequest struct {
+	Usernames []string
+}
 type Config struct {
 	TokenSecret     string
 	TokenExpiration time.Duration
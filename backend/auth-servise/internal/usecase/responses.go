@@ -7,16 +7,29 @@ type RegisterResponse struct {
 }
 
 type LoginResponse struct {
-	Token    string
-	Username string
+	Token        string
+	RefreshToken string
+	Username     string
+}
+
+type RefreshResponse struct {
+	Token        string
+	RefreshToken string
 }
 
+type LogoutResponse struct{}
+
 type ValidateTokenResponse struct {
-	UserID int64
-	Role   string
-	Valid  bool
+	UserID   int64
+	Username string
+	Role     string
+	Valid    bool
 }
 
 type GetUserResponse struct {
 	User *entity.User
 }
+
+type GetUsersResponse struct {
+	Users []*entity.User
+}
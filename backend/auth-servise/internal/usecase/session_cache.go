@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+const sessionCachePruneThreshold = 10000
+
+// sessionCache запоминает результат проверки сессии, чтобы ValidateToken,
+// который форум вызывает на каждый запрос, не ходил в базу каждый раз.
+// Отзыв через этот же процесс сбрасывает запись сразу, отзыв из другого
+// экземпляра сервиса становится виден не позже чем через ttl.
+type sessionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]sessionCacheEntry
+}
+
+type sessionCacheEntry struct {
+	userID    int64
+	active    bool
+	checkedAt time.Time
+}
+
+func newSessionCache(ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		ttl:     ttl,
+		entries: make(map[string]sessionCacheEntry),
+	}
+}
+
+func (c *sessionCache) get(sessionID string, now time.Time) (active bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[sessionID]
+	if !ok || now.Sub(entry.checkedAt) >= c.ttl {
+		return false, false
+	}
+	return entry.active, true
+}
+
+func (c *sessionCache) set(sessionID string, userID int64, active bool, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Устаревшие записи вычищаем по ходу, чтобы кэш не рос бесконечно.
+	if len(c.entries) >= sessionCachePruneThreshold {
+		for id, entry := range c.entries {
+			if now.Sub(entry.checkedAt) >= c.ttl {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[sessionID] = sessionCacheEntry{userID: userID, active: active, checkedAt: now}
+}
+
+func (c *sessionCache) revoke(sessionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[sessionID]; ok {
+		entry.active = false
+		c.entries[sessionID] = entry
+	}
+}
+
+func (c *sessionCache) revokeUser(userID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, entry := range c.entries {
+		if entry.userID == userID {
+			entry.active = false
+			c.entries[id] = entry
+		}
+	}
+}
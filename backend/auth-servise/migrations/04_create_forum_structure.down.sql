@@ -0,0 +1,3 @@
+DROP TABLE messages;
+DROP TABLE topics;
+DROP TABLE categories;
@@ -0,0 +1,4 @@
+DROP INDEX idx_posts_topic_id;
+ALTER TABLE posts DROP COLUMN topic_id;
+ALTER TABLE topics DROP COLUMN updated_at;
+ALTER TABLE categories DROP COLUMN updated_at;
@@ -0,0 +1,6 @@
+ALTER TABLE categories ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE topics ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+-- Пост может принадлежать теме; при удалении темы посты остаются без неё
+ALTER TABLE posts ADD COLUMN topic_id INT REFERENCES topics(id) ON DELETE SET NULL;
+CREATE INDEX idx_posts_topic_id ON posts(topic_id);
@@ -0,0 +1,12 @@
+DELETE FROM sessions;
+
+DROP INDEX idx_sessions_user_id;
+DROP INDEX idx_sessions_family_id;
+
+ALTER TABLE sessions
+    DROP COLUMN revoked_at,
+    DROP COLUMN used_at,
+    DROP COLUMN created_at,
+    DROP COLUMN family_id,
+    DROP COLUMN id;
+ALTER TABLE sessions RENAME COLUMN token_hash TO token;
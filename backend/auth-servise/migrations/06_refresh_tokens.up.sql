@@ -0,0 +1,15 @@
+-- Сессия теперь хранит refresh-токен (только его SHA-256), а не сам JWT.
+-- Старые записи с JWT для обновления не пригодны.
+DELETE FROM sessions;
+
+ALTER TABLE sessions RENAME COLUMN token TO token_hash;
+ALTER TABLE sessions
+    ADD COLUMN id BIGSERIAL PRIMARY KEY,
+    -- Все токены, выпущенные по цепочке обновлений от одного входа
+    ADD COLUMN family_id VARCHAR(64) NOT NULL,
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN used_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN revoked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_sessions_family_id ON sessions(family_id);
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
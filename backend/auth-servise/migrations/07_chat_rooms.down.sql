@@ -0,0 +1,5 @@
+DROP INDEX idx_chat_messages_room_id;
+ALTER TABLE chat_messages DROP COLUMN room_id;
+
+DROP TABLE chat_room_members;
+DROP TABLE chat_rooms;
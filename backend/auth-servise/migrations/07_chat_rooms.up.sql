@@ -0,0 +1,30 @@
+-- Комнаты чата. Публичные видны и доступны всем, в закрытые попадают
+-- только по приглашению.
+CREATE TABLE chat_rooms (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    is_private BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Комната обсуждения темы форума, не больше одной на тему
+    topic_id INT UNIQUE,
+    created_by BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE CASCADE
+);
+
+CREATE TABLE chat_room_members (
+    room_id INT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (room_id, user_id),
+    FOREIGN KEY (room_id) REFERENCES chat_rooms(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_chat_room_members_user_id ON chat_room_members(user_id);
+
+-- Общая комната: в неё попадают все существующие сообщения
+INSERT INTO chat_rooms (id, name) VALUES (1, 'general');
+SELECT setval('chat_rooms_id_seq', (SELECT MAX(id) FROM chat_rooms));
+
+ALTER TABLE chat_messages
+    ADD COLUMN room_id INT NOT NULL DEFAULT 1 REFERENCES chat_rooms(id) ON DELETE CASCADE;
+CREATE INDEX idx_chat_messages_room_id ON chat_messages(room_id, timestamp);
@@ -0,0 +1,4 @@
+DROP INDEX idx_chat_messages_room_timestamp_id;
+CREATE INDEX idx_chat_messages_room_id ON chat_messages(room_id, timestamp);
+
+ALTER TABLE chat_messages ALTER COLUMN timestamp DROP NOT NULL;
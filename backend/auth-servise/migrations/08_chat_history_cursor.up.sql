@@ -0,0 +1,7 @@
+-- История чата листается курсором (timestamp, id), поэтому время сообщения
+-- обязательно, а индекс покрывает оба поля курсора.
+UPDATE chat_messages SET timestamp = CURRENT_TIMESTAMP WHERE timestamp IS NULL;
+ALTER TABLE chat_messages ALTER COLUMN timestamp SET NOT NULL;
+
+DROP INDEX idx_chat_messages_room_id;
+CREATE INDEX idx_chat_messages_room_timestamp_id ON chat_messages(room_id, timestamp, id);
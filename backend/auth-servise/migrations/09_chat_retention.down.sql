@@ -0,0 +1,20 @@
+DROP TABLE chat_messages_archive;
+
+ALTER TABLE chat_rooms
+    DROP COLUMN retention_archive,
+    DROP COLUMN retention_value,
+    DROP COLUMN retention_mode;
+
+CREATE OR REPLACE FUNCTION delete_old_messages()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM chat_messages
+    WHERE timestamp < NOW() - INTERVAL '10 minutes';
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER cleanup_old_messages
+AFTER INSERT ON chat_messages
+FOR EACH ROW
+EXECUTE FUNCTION delete_old_messages();
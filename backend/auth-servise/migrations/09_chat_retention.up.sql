@@ -0,0 +1,26 @@
+-- Триггер из 03 при каждой вставке удалял все сообщения старше 10 минут.
+-- Теперь срок хранения задаётся для комнаты, а соблюдает его фоновая задача
+-- chat-servise.
+DROP TRIGGER IF EXISTS cleanup_old_messages ON chat_messages;
+DROP FUNCTION IF EXISTS delete_old_messages();
+
+-- forever — хранить всегда, days — retention_value дней,
+-- count — последние retention_value сообщений
+ALTER TABLE chat_rooms
+    ADD COLUMN retention_mode VARCHAR(10) NOT NULL DEFAULT 'forever'
+        CHECK (retention_mode IN ('forever', 'days', 'count')),
+    ADD COLUMN retention_value INT NOT NULL DEFAULT 0 CHECK (retention_value >= 0),
+    -- Устаревшие сообщения переносятся в chat_messages_archive, а не удаляются
+    ADD COLUMN retention_archive BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Холодное хранилище. Без внешнего ключа на комнату: архив переживает её удаление.
+CREATE TABLE chat_messages_archive (
+    id INT PRIMARY KEY,
+    room_id INT NOT NULL,
+    user_id BIGINT NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_chat_messages_archive_room ON chat_messages_archive(room_id, timestamp, id);
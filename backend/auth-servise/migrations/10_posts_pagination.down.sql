@@ -0,0 +1,3 @@
+DROP INDEX idx_posts_created_at_id;
+
+ALTER TABLE posts ALTER COLUMN created_at DROP NOT NULL;
@@ -0,0 +1,6 @@
+-- Лента постов листается курсором (created_at, id), поэтому время создания
+-- обязательно, а индекс покрывает оба поля курсора в порядке выдачи.
+UPDATE posts SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+ALTER TABLE posts ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX idx_posts_created_at_id ON posts(created_at DESC, id DESC);
@@ -0,0 +1,7 @@
+DROP INDEX idx_comments_search_vector;
+DROP INDEX idx_posts_search_vector;
+
+ALTER TABLE comments DROP COLUMN search_vector;
+ALTER TABLE posts DROP COLUMN search_vector;
+
+ALTER TABLE comments DROP COLUMN created_at;
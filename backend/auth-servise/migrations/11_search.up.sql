@@ -0,0 +1,16 @@
+-- Полнотекстовый поиск по постам и комментариям. Конфигурация 'simple':
+-- на форуме пишут и по-русски, и по-английски, а стемминг одного языка
+-- портил бы слова другого.
+ALTER TABLE comments ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE posts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+) STORED;
+
+ALTER TABLE comments ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(content, ''))
+) STORED;
+
+CREATE INDEX idx_posts_search_vector ON posts USING GIN (search_vector);
+CREATE INDEX idx_comments_search_vector ON comments USING GIN (search_vector);
@@ -0,0 +1,5 @@
+DROP INDEX idx_comments_parent_id;
+DROP INDEX idx_comments_post_roots;
+
+ALTER TABLE comments DROP COLUMN depth;
+ALTER TABLE comments DROP COLUMN parent_id;
@@ -0,0 +1,7 @@
+-- Ответы на комментарии. depth хранится, чтобы не считать глубину
+-- рекурсивно при каждой вставке; у корневых комментариев она 0.
+ALTER TABLE comments ADD COLUMN parent_id INT REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN depth INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_comments_post_roots ON comments(post_id, id) WHERE parent_id IS NULL;
+CREATE INDEX idx_comments_parent_id ON comments(parent_id, id);
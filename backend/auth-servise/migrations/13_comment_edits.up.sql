@@ -0,0 +1,2 @@
+-- Время последней правки комментария; NULL — комментарий не редактировался.
+ALTER TABLE comments ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE;
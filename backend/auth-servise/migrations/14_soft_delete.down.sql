@@ -0,0 +1,14 @@
+DROP INDEX idx_comments_deleted_at;
+DROP INDEX idx_posts_deleted_at;
+
+DROP INDEX idx_posts_created_at_id;
+CREATE INDEX idx_posts_created_at_id ON posts(created_at DESC, id DESC);
+
+-- Без мягкого удаления помеченные строки снова стали бы видны.
+DELETE FROM comments WHERE deleted_at IS NOT NULL;
+DELETE FROM posts WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE comments DROP COLUMN deleted_by;
+ALTER TABLE comments DROP COLUMN deleted_at;
+ALTER TABLE posts DROP COLUMN deleted_by;
+ALTER TABLE posts DROP COLUMN deleted_at;
@@ -0,0 +1,14 @@
+-- Мягкое удаление: пост или комментарий помечается удаленным и уходит
+-- в корзину, откуда админ может его восстановить. Окончательно строки
+-- удаляет фоновая очистка после истечения срока хранения.
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE posts ADD COLUMN deleted_by INT;
+ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE comments ADD COLUMN deleted_by INT;
+
+-- Лента читает только живые посты.
+DROP INDEX idx_posts_created_at_id;
+CREATE INDEX idx_posts_created_at_id ON posts(created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_posts_deleted_at ON posts(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_comments_deleted_at ON comments(deleted_at) WHERE deleted_at IS NOT NULL;
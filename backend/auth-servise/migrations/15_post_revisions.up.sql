@@ -0,0 +1,18 @@
+-- История правок постов. Ревизия 1 — исходный текст, каждая правка
+-- или откат добавляет следующую. posts.revision — номер текущей ревизии:
+-- его инкремент в UPDATE сериализует параллельные правки одного поста.
+ALTER TABLE posts ADD COLUMN revision INT NOT NULL DEFAULT 1;
+
+CREATE TABLE post_revisions (
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    editor_id INT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, revision)
+);
+
+-- У существующих постов история начинается с текущего текста.
+INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at)
+SELECT id, 1, title, content, author_id, COALESCE(created_at, CURRENT_TIMESTAMP) FROM posts;
@@ -0,0 +1,7 @@
+DROP INDEX idx_posts_top;
+DROP INDEX idx_posts_hot;
+DROP FUNCTION hot_rank(INT, TIMESTAMP WITH TIME ZONE);
+DROP TABLE comment_votes;
+DROP TABLE post_votes;
+ALTER TABLE comments DROP COLUMN score;
+ALTER TABLE posts DROP COLUMN score;
@@ -0,0 +1,33 @@
+-- Голоса за посты и комментарии: один голос (+1 или -1) от пользователя
+-- на запись, его можно поменять или снять. score — денормализованная сумма
+-- голосов, меняется в одной транзакции с таблицей голосов.
+ALTER TABLE posts ADD COLUMN score INT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN score INT NOT NULL DEFAULT 0;
+
+CREATE TABLE post_votes (
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id)
+);
+
+CREATE TABLE comment_votes (
+    comment_id INT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+-- Рейтинг для сортировки hot, как у Reddit: порядок величины счета плюс
+-- время создания, где каждые 12.5 часов весят как десятикратный счет.
+-- Эпоха timestamptz от часового пояса не зависит, поэтому функция
+-- объявлена IMMUTABLE и по ней можно строить индекс.
+CREATE FUNCTION hot_rank(score INT, created_at TIMESTAMP WITH TIME ZONE)
+RETURNS DOUBLE PRECISION AS $$
+    SELECT sign(score) * log(greatest(abs(score), 1)) + extract(epoch FROM created_at) / 45000
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE INDEX idx_posts_hot ON posts (hot_rank(score, created_at) DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_posts_top ON posts (score DESC, id DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE chat_message_reactions;
+DROP TABLE comment_reactions;
+DROP TABLE post_reactions;
@@ -0,0 +1,28 @@
+-- Реакции эмодзи на посты, комментарии и сообщения чата. У пользователя
+-- одна реакция каждого вида на запись: повторная реакция ее снимает.
+-- Набор допустимых эмодзи задается в настройках сервисов, а не в схеме.
+CREATE TABLE post_reactions (
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, user_id, emoji)
+);
+
+CREATE TABLE comment_reactions (
+    comment_id INT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id INT NOT NULL,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comment_id, user_id, emoji)
+);
+
+-- Реакции удаляются вместе с сообщением, в том числе при очистке по сроку
+-- хранения: в архив они не переносятся.
+CREATE TABLE chat_message_reactions (
+    message_id INT NOT NULL REFERENCES chat_messages(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
@@ -0,0 +1,19 @@
+-- Теги постов. Имя хранится уже нормализованным (нижний регистр, без
+-- пробелов), поэтому уникальность проверяется по нему напрямую.
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Автодополнение ищет по префиксу имени.
+CREATE INDEX tags_name_prefix_idx ON tags (name text_pattern_ops);
+
+CREATE TABLE post_tags (
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+-- Фильтр ленты по тегу и подсчет постов тега.
+CREATE INDEX post_tags_tag_idx ON post_tags (tag_id, post_id);
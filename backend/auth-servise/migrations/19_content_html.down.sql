@@ -0,0 +1,2 @@
+ALTER TABLE comments DROP COLUMN content_html;
+ALTER TABLE posts DROP COLUMN content_html;
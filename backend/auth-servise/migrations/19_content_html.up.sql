@@ -0,0 +1,5 @@
+-- Текст постов и комментариев хранится в markdown (content) и рядом —
+-- готовым очищенным HTML (content_html), который отдается клиентам.
+-- Записи, созданные раньше, forum-servise дорисовывает при старте.
+ALTER TABLE posts ADD COLUMN content_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE comments ADD COLUMN content_html TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,18 @@
+-- Вложения постов. Сам файл лежит в хранилище forum-servise (локальный
+-- диск или S3) под storage_key, здесь — только его описание. Тип
+-- определяется по содержимому файла, а не по заголовку клиента.
+CREATE TABLE attachments (
+    id SERIAL PRIMARY KEY,
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    uploader_id INT NOT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL UNIQUE,
+    -- Пусто, если превью нет (не картинка или формат не поддерживается).
+    thumbnail_key TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Вложения постов ленты и подсчет вложений поста.
+CREATE INDEX attachments_post_idx ON attachments (post_id, id);
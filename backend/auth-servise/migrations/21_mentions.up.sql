@@ -0,0 +1,50 @@
+-- Упоминания @username в постах, комментариях и сообщениях чата. Запись
+-- сохраняется при первом упоминании пользователя в тексте, поэтому после
+-- правки текста уведомление о том же упоминании не повторяется.
+CREATE TABLE mentions (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username VARCHAR(255) NOT NULL,
+    author_id INT NOT NULL,
+    -- У комментария заполнены post_id и comment_id, у сообщения чата —
+    -- room_id и message_id.
+    post_id INT REFERENCES posts(id) ON DELETE CASCADE,
+    comment_id INT REFERENCES comments(id) ON DELETE CASCADE,
+    room_id INT REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    message_id INT REFERENCES chat_messages(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX mentions_post_uniq ON mentions (post_id, user_id)
+    WHERE post_id IS NOT NULL AND comment_id IS NULL;
+CREATE UNIQUE INDEX mentions_comment_uniq ON mentions (comment_id, user_id)
+    WHERE comment_id IS NOT NULL;
+CREATE UNIQUE INDEX mentions_message_uniq ON mentions (message_id, user_id)
+    WHERE message_id IS NOT NULL;
+
+-- Уведомления пользователей. Уведомление о сообщении чата переживает
+-- очистку истории комнаты: ссылка на сообщение просто обнуляется.
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    actor_id INT NOT NULL,
+    actor_name VARCHAR(255) NOT NULL,
+    post_id INT REFERENCES posts(id) ON DELETE CASCADE,
+    comment_id INT REFERENCES comments(id) ON DELETE CASCADE,
+    room_id INT REFERENCES chat_rooms(id) ON DELETE CASCADE,
+    message_id INT REFERENCES chat_messages(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX notifications_user_idx ON notifications (user_id, id);
+
+-- Настройки уведомлений: строка есть, только если пользователь менял
+-- настройку типа. По умолчанию все типы включены.
+CREATE TABLE notification_preferences (
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
@@ -0,0 +1,24 @@
+-- Действие модератора в уведомлении типа moderation: post_deleted,
+-- post_restored, comment_deleted или comment_restored.
+ALTER TABLE notifications ADD COLUMN action VARCHAR(32);
+
+CREATE INDEX notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+
+-- Новое уведомление отправляется в канал notifications вместе с числом
+-- непрочитанных, chat-servise доставляет его по WebSocket.
+CREATE OR REPLACE FUNCTION notify_notification()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('notifications', json_build_object(
+        'user_id', NEW.user_id,
+        'unread_count', (SELECT COUNT(*) FROM notifications WHERE user_id = NEW.user_id AND read_at IS NULL),
+        'notification', row_to_json(NEW)
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notifications_notify
+AFTER INSERT ON notifications
+FOR EACH ROW
+EXECUTE FUNCTION notify_notification();
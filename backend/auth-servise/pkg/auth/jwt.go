@@ -1,37 +1,42 @@
 package auth
 
 import (
-	"errors"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Config struct {
-	TokenSecret     string
+	// Keys — ключи подписи и проверки access-токенов.
+	Keys            *Keyring
 	TokenExpiration time.Duration
+	// RefreshTokenExpiration — срок жизни refresh-токена (и всей цепочки
+	// обновлений без повторного входа).
+	RefreshTokenExpiration time.Duration
+	// SessionCacheTTL — сколько ValidateToken доверяет последней проверке
+	// сессии в базе.
+	SessionCacheTTL time.Duration
 }
 
-func GenerateToken(userID int64, role string, username string, secret string, expiration time.Duration) (string, error) {
+// GenerateToken выпускает access-токен. sessionID — семейство refresh-токенов,
+// к которому привязан access-токен.
+func GenerateToken(keys *Keyring, userID int64, role string, username string, sessionID string, expiration time.Duration) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id":  userID,
 		"role":     role,
 		"username": username,
+		"sid":      sessionID,
 		"exp":      time.Now().Add(expiration).Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.Sign(claims)
 }
 
-func ParseToken(tokenString string, secret string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return []byte(secret), nil
-	})
-	return token, err
+func ParseToken(tokenString string, keys *Keyring) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, keys.Keyfunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
 }
 func HashPassword(password string) (string, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnsupportedKey = errors.New("unsupported key type")
+	ErrUnknownKeyID   = errors.New("unknown key id")
+)
+
+// SigningKey — закрытый ключ, которым подписываются access-токены.
+// ID попадает в заголовок kid и совпадает с kid ключа в JWKS.
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	Key    crypto.Signer
+}
+
+// JWK — открытый ключ в формате RFC 7517. Поддерживаются RSA и Ed25519 (OKP).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+	jwk    JWK
+}
+
+// Keyring подписывает токены текущим ключом и проверяет их любым из
+// опубликованных ключей. Для ротации новый ключ сначала добавляют в
+// проверочные (чтобы он попал в JWKS), затем делают подписывающим, а старый
+// оставляют проверочным, пока не истекут выпущенные им токены.
+type Keyring struct {
+	signing *SigningKey
+	keys    map[string]verificationKey
+	order   []string
+}
+
+func NewSigningKey(key crypto.Signer) (*SigningKey, error) {
+	method, err := signingMethodFor(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := publicJWK(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{ID: jwk.Kid, Method: method, Key: key}, nil
+}
+
+// GenerateSigningKey создаёт временный Ed25519-ключ. Токены, подписанные им,
+// перестают проверяться после перезапуска сервиса.
+func GenerateSigningKey() (*SigningKey, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKey(private)
+}
+
+// LoadSigningKey читает закрытый RSA- или Ed25519-ключ из PEM-файла.
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return NewSigningKey(key)
+	}
+	if key, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, ErrUnsupportedKey
+		}
+		return NewSigningKey(signer)
+	}
+	return nil, fmt.Errorf("%s: %w", path, ErrUnsupportedKey)
+}
+
+// LoadVerificationKey читает открытый RSA- или Ed25519-ключ из PEM-файла.
+func LoadVerificationKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%s: %w", path, ErrUnsupportedKey)
+}
+
+// NewKeyring собирает связку из подписывающего ключа и дополнительных
+// открытых ключей. Открытая часть подписывающего ключа добавляется сама.
+func NewKeyring(signing *SigningKey, verification ...crypto.PublicKey) (*Keyring, error) {
+	k := &Keyring{
+		signing: signing,
+		keys:    make(map[string]verificationKey),
+	}
+	for _, key := range append([]crypto.PublicKey{signing.Key.Public()}, verification...) {
+		if err := k.add(key); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+func (k *Keyring) add(key crypto.PublicKey) error {
+	method, err := signingMethodFor(key)
+	if err != nil {
+		return err
+	}
+	jwk, err := publicJWK(key)
+	if err != nil {
+		return err
+	}
+	if _, ok := k.keys[jwk.Kid]; ok {
+		return nil
+	}
+	k.keys[jwk.Kid] = verificationKey{method: method, key: key, jwk: jwk}
+	k.order = append(k.order, jwk.Kid)
+	return nil
+}
+
+// Sign подписывает claims текущим ключом и проставляет kid.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.Method, claims)
+	token.Header["kid"] = k.signing.ID
+	return token.SignedString(k.signing.Key)
+}
+
+// Keyfunc выбирает открытый ключ по kid и проверяет, что алгоритм токена
+// соответствует типу ключа.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.key, nil
+}
+
+// JWKS возвращает все ключи, которыми сейчас можно проверить токен.
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(k.order))}
+	for _, kid := range k.order {
+		set.Keys = append(set.Keys, k.keys[kid].jwk)
+	}
+	return set
+}
+
+func signingMethodFor(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+// publicJWK строит JWK для открытого ключа. kid — отпечаток ключа по
+// RFC 7638, поэтому он не зависит от имени файла и одинаков на всех
+// экземплярах сервиса.
+func publicJWK(key crypto.PublicKey) (JWK, error) {
+	var (
+		jwk       JWK
+		canonical interface{}
+	)
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+		jwk = JWK{Kty: "RSA", Alg: jwt.SigningMethodRS256.Alg(), N: n, E: e}
+		canonical = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{e, "RSA", n}
+	case ed25519.PublicKey:
+		x := base64.RawURLEncoding.EncodeToString(key)
+		jwk = JWK{Kty: "OKP", Alg: jwt.SigningMethodEdDSA.Alg(), Crv: "Ed25519", X: x}
+		canonical = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{"Ed25519", "OKP", x}
+	default:
+		return JWK{}, ErrUnsupportedKey
+	}
+
+	data, err := json.Marshal(canonical)
+	if err != nil {
+		return JWK{}, err
+	}
+	sum := sha256.Sum256(data)
+	jwk.Kid = base64.RawURLEncoding.EncodeToString(sum[:])
+	jwk.Use = "sig"
+	return jwk, nil
+}
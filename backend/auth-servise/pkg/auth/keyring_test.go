@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaDER := x509.MarshalPKCS1PrivateKey(rsaKey)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		path      string
+		wantAlg   string
+		wantError bool
+	}{
+		{name: "RSA", path: writePEM(t, "RSA PRIVATE KEY", rsaDER), wantAlg: "RS256"},
+		{name: "Ed25519", path: writePEM(t, "PRIVATE KEY", edDER), wantAlg: "EdDSA"},
+		{name: "Garbage", path: writePEM(t, "PRIVATE KEY", []byte("garbage")), wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := LoadSigningKey(tt.path)
+			if tt.wantError {
+				assert.ErrorIs(t, err, ErrUnsupportedKey)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAlg, key.Method.Alg())
+			assert.NotEmpty(t, key.ID)
+		})
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	oldKey, err := GenerateSigningKey()
+	require.NoError(t, err)
+	oldKeys, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+
+	oldToken, err := GenerateToken(oldKeys, 1, "user", "alice", "family", time.Hour)
+	require.NoError(t, err)
+
+	// Новый ключ подписывает, старый остаётся только для проверки.
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := NewSigningKey(rsaKey)
+	require.NoError(t, err)
+	keys, err := NewKeyring(newKey, oldKey.Key.Public())
+	require.NoError(t, err)
+
+	newToken, err := GenerateToken(keys, 1, "user", "alice", "family", time.Hour)
+	require.NoError(t, err)
+
+	for _, tokenString := range []string{oldToken, newToken} {
+		token, err := ParseToken(tokenString, keys)
+		require.NoError(t, err)
+		assert.True(t, token.Valid)
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, newKey.ID, parsed.Header["kid"])
+	assert.Equal(t, "RS256", parsed.Header["alg"])
+
+	// Без старого ключа его токены больше не принимаются.
+	_, err = ParseToken(oldToken, mustKeyring(t, newKey))
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, newKey.ID, jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, oldKey.ID, jwks.Keys[1].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+}
+
+func TestParseToken_RejectsWrongAlgorithm(t *testing.T) {
+	signing, err := GenerateSigningKey()
+	require.NoError(t, err)
+	keys := mustKeyring(t, signing)
+
+	// HS256 с открытым ключом в роли секрета — классическая подмена алгоритма.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = signing.ID
+	tokenString, err := token.SignedString([]byte(signing.Key.Public().(ed25519.PublicKey)))
+	require.NoError(t, err)
+
+	_, err = ParseToken(tokenString, keys)
+	assert.Error(t, err)
+}
+
+func TestParseToken_RequiresExpiration(t *testing.T) {
+	signing, err := GenerateSigningKey()
+	require.NoError(t, err)
+	keys := mustKeyring(t, signing)
+
+	tokenString, err := keys.Sign(jwt.MapClaims{"user_id": 1})
+	require.NoError(t, err)
+
+	_, err = ParseToken(tokenString, keys)
+	assert.Error(t, err)
+}
+
+func mustKeyring(t *testing.T, signing *SigningKey) *Keyring {
+	keys, err := NewKeyring(signing)
+	require.NoError(t, err)
+	return keys
+}
@@ -0,0 +1,115 @@
+package authmw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "backend.com/forum/proto"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrMissingToken = errors.New("authorization token is required")
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrAuthUnavailable — токен не удалось проверить ни локально, ни через
+	// auth-servise.
+	ErrAuthUnavailable = errors.New("authentication service unavailable")
+)
+
+// Authenticator проверяет access-токены. Подпись, срок действия и claims
+// проверяются локально по ключам из KeySet, и поддельный или просроченный
+// токен отклоняется без обращения к auth-servise. Принятый локально токен
+// затем проверяется через ValidateToken: только auth-servise знает, не
+// отозвана ли сессия (logout, logout-all, отзыв администратором). Если ключа
+// нет (JWKS недоступен или kid ещё не опубликован), токен целиком проверяет
+// auth-servise.
+type Authenticator struct {
+	keys   *KeySet
+	client pb.AuthServiceClient
+}
+
+// NewAuthenticator создаёт Authenticator. Если keys == nil, каждый токен
+// проверяется через auth-servise.
+func NewAuthenticator(keys *KeySet, client pb.AuthServiceClient) *Authenticator {
+	return &Authenticator{keys: keys, client: client}
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+
+	if a.keys != nil {
+		principal, err := a.authenticateLocally(token)
+		if !errors.Is(err, ErrKeyUnavailable) {
+			if err != nil {
+				return nil, err
+			}
+			if _, err := a.authenticateRemotely(ctx, token); err != nil {
+				return nil, err
+			}
+			return principal, nil
+		}
+	}
+
+	return a.authenticateRemotely(ctx, token)
+}
+
+func (a *Authenticator) authenticateLocally(tokenString string) (*Principal, error) {
+	token, err := jwt.Parse(tokenString, a.keys.Keyfunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, ErrKeyUnavailable) {
+			return nil, ErrKeyUnavailable
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	role, ok := claims["role"].(string)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	// auth-servise не принимает токены без сессии, здесь правило то же.
+	if sid, _ := claims["sid"].(string); sid == "" {
+		return nil, ErrInvalidToken
+	}
+	username, _ := claims["username"].(string)
+
+	return &Principal{
+		UserID:   int64(userID),
+		Username: username,
+		Role:     role,
+	}, nil
+}
+
+func (a *Authenticator) authenticateRemotely(ctx context.Context, token string) (*Principal, error) {
+	if a.client == nil {
+		return nil, ErrAuthUnavailable
+	}
+
+	resp, err := a.client.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAuthUnavailable, err)
+	}
+	if resp == nil || !resp.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{
+		UserID:   resp.UserId,
+		Username: resp.Username,
+		Role:     resp.Role,
+	}, nil
+}
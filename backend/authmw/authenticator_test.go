@@ -0,0 +1,241 @@
+package authmw
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "backend.com/forum/proto"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type fakeAuthClient struct {
+	pb.AuthServiceClient
+	calls    int
+	validate func(token string) (*pb.ValidateTokenResponse, error)
+}
+
+func (f *fakeAuthClient) ValidateToken(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
+	f.calls++
+	return f.validate(in.Token)
+}
+
+type testIssuer struct {
+	kid     string
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+func newTestIssuer(t *testing.T, kid string) *testIssuer {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &testIssuer{kid: kid, private: private, public: public}
+}
+
+func (i *testIssuer) token(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = i.kid
+	signed, err := token.SignedString(i.private)
+	require.NoError(t, err)
+	return signed
+}
+
+func (i *testIssuer) jwk() jwk {
+	return jwk{
+		Kty: "OKP",
+		Kid: i.kid,
+		Use: "sig",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(i.public),
+	}
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"user_id":  float64(7),
+		"username": "alice",
+		"role":     "user",
+		"sid":      "family",
+		"exp":      time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// newJWKSServer отдаёт ключи издателей и считает запросы.
+func newJWKSServer(t *testing.T, issuers ...*testIssuer) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		set := jwkSet{}
+		for _, issuer := range issuers {
+			set.Keys = append(set.Keys, issuer.jwk())
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+// activeSession — ответ auth-servise для неотозванной сессии.
+func activeSession(token string) (*pb.ValidateTokenResponse, error) {
+	return &pb.ValidateTokenResponse{Valid: true, UserId: 7, Username: "alice", Role: "user"}, nil
+}
+
+func TestAuthenticator_LocalValidation(t *testing.T) {
+	issuer := newTestIssuer(t, "k1")
+	server, hits := newJWKSServer(t, issuer)
+	client := &fakeAuthClient{validate: activeSession}
+	a := NewAuthenticator(NewKeySet(server.URL, time.Hour), client)
+
+	for i := 0; i < 3; i++ {
+		principal, err := a.Authenticate(context.Background(), issuer.token(t, validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, &Principal{UserID: 7, Username: "alice", Role: "user"}, principal)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+	assert.Equal(t, 3, client.calls)
+}
+
+func TestAuthenticator_ChecksSessionWithAuthService(t *testing.T) {
+	issuer := newTestIssuer(t, "k1")
+	server, _ := newJWKSServer(t, issuer)
+
+	tests := []struct {
+		name    string
+		resp    *pb.ValidateTokenResponse
+		err     error
+		wantErr error
+	}{
+		{name: "Revoked", resp: &pb.ValidateTokenResponse{Valid: false}, wantErr: ErrInvalidToken},
+		{name: "Unavailable", err: errors.New("connection refused"), wantErr: ErrAuthUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeAuthClient{
+				validate: func(token string) (*pb.ValidateTokenResponse, error) { return tt.resp, tt.err },
+			}
+			a := NewAuthenticator(NewKeySet(server.URL, time.Hour), client)
+
+			principal, err := a.Authenticate(context.Background(), issuer.token(t, validClaims()))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, principal)
+			assert.Equal(t, 1, client.calls)
+		})
+	}
+}
+
+func TestAuthenticator_RejectsInvalidTokens(t *testing.T) {
+	issuer := newTestIssuer(t, "k1")
+	server, _ := newJWKSServer(t, issuer)
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	withoutSession := validClaims()
+	delete(withoutSession, "sid")
+
+	// Чужой ключ с тем же kid — подпись не сойдётся.
+	forger := newTestIssuer(t, "k1")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Expired", token: issuer.token(t, expired)},
+		{name: "Without session", token: issuer.token(t, withoutSession)},
+		{name: "Forged signature", token: forger.token(t, validClaims())},
+		{name: "Garbage", token: "not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeAuthClient{}
+			a := NewAuthenticator(NewKeySet(server.URL, time.Hour), client)
+
+			_, err := a.Authenticate(context.Background(), tt.token)
+
+			assert.ErrorIs(t, err, ErrInvalidToken)
+			assert.Zero(t, client.calls)
+		})
+	}
+}
+
+func TestAuthenticator_FallsBackToAuthService(t *testing.T) {
+	issuer := newTestIssuer(t, "new-key")
+	// JWKS ещё не знает новый ключ.
+	server, _ := newJWKSServer(t, newTestIssuer(t, "old-key"))
+	client := &fakeAuthClient{
+		validate: func(token string) (*pb.ValidateTokenResponse, error) {
+			return &pb.ValidateTokenResponse{Valid: true, UserId: 7, Username: "alice", Role: "admin"}, nil
+		},
+	}
+	a := NewAuthenticator(NewKeySet(server.URL, time.Hour), client)
+
+	principal, err := a.Authenticate(context.Background(), issuer.token(t, validClaims()))
+
+	require.NoError(t, err)
+	assert.True(t, principal.IsAdmin())
+	assert.Equal(t, 1, client.calls)
+}
+
+func TestAuthenticator_Remote(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    *pb.ValidateTokenResponse
+		err     error
+		wantErr error
+	}{
+		{name: "Valid", resp: &pb.ValidateTokenResponse{Valid: true, UserId: 1, Role: "user"}},
+		{name: "Invalid", resp: &pb.ValidateTokenResponse{Valid: false}, wantErr: ErrInvalidToken},
+		{name: "Unavailable", err: errors.New("connection refused"), wantErr: ErrAuthUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeAuthClient{
+				validate: func(token string) (*pb.ValidateTokenResponse, error) { return tt.resp, tt.err },
+			}
+			a := NewAuthenticator(nil, client)
+
+			principal, err := a.Authenticate(context.Background(), "token")
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, principal)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), principal.UserID)
+		})
+	}
+}
+
+func TestKeySet_RefreshesForUnknownKeyAtMostOncePerInterval(t *testing.T) {
+	server, hits := newJWKSServer(t, newTestIssuer(t, "k1"))
+	keys := NewKeySet(server.URL, time.Hour)
+	unknown := newTestIssuer(t, "k2")
+
+	for i := 0; i < 5; i++ {
+		_, err := jwt.Parse(unknown.token(t, validClaims()), keys.Keyfunc)
+		assert.ErrorIs(t, err, ErrKeyUnavailable)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(hits))
+
+	now := time.Now().Add(minRefreshInterval)
+	keys.now = func() time.Time { return now }
+	_, _ = jwt.Parse(unknown.token(t, validClaims()), keys.Keyfunc)
+	assert.Equal(t, int32(2), atomic.LoadInt32(hits))
+}
@@ -0,0 +1,90 @@
+package authmw
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// В gRPC токен передаётся в метаданных так же, как в HTTP:
+// "authorization: Bearer <token>".
+const authorizationKey = "authorization"
+
+// UnaryServerInterceptor — аналог Optional для gRPC: вызов без токена
+// проходит анонимно, с действительным токеном — с principal в контексте,
+// с недействительным отклоняется. Методы, которым нужен пользователь,
+// проверяют его сами через RequirePrincipal.
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticateIncoming(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor — то же для потоковых вызовов.
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticateIncoming(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *Authenticator) authenticateIncoming(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return ctx, nil
+	}
+
+	token, ok := parseBearer(values[0])
+	if !ok {
+		return nil, grpcError(ErrMissingToken)
+	}
+	principal, err := a.Authenticate(ctx, token)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return NewContext(ctx, principal), nil
+}
+
+// RequirePrincipal возвращает пользователя вызова или ошибку
+// codes.Unauthenticated, если вызов анонимный.
+func RequirePrincipal(ctx context.Context) (*Principal, error) {
+	principal, ok := FromContext(ctx)
+	if !ok {
+		return nil, grpcError(ErrMissingToken)
+	}
+	return principal, nil
+}
+
+// grpcError — gRPC-статус, соответствующий ошибке аутентификации.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		return status.Error(codes.Unauthenticated, "authorization token is required")
+	case errors.Is(err, ErrAuthUnavailable):
+		return status.Error(codes.Unavailable, "authentication service unavailable")
+	default:
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+}
+
+// authenticatedStream подменяет контекст потока контекстом с principal.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
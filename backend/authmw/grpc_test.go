@@ -0,0 +1,95 @@
+package authmw
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "backend.com/forum/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInterceptors(t *testing.T) {
+	client := &fakeAuthClient{
+		validate: func(token string) (*pb.ValidateTokenResponse, error) {
+			switch token {
+			case "user-token":
+				return &pb.ValidateTokenResponse{Valid: true, UserId: 2, Username: "bob", Role: "user"}, nil
+			case "down":
+				return nil, errors.New("unavailable")
+			default:
+				return &pb.ValidateTokenResponse{Valid: false}, nil
+			}
+		},
+	}
+	a := NewAuthenticator(nil, client)
+
+	tests := []struct {
+		name     string
+		header   string
+		wantCode codes.Code
+		wantUser int64
+	}{
+		{name: "Anonymous", wantCode: codes.OK},
+		{name: "Valid token", header: "Bearer user-token", wantCode: codes.OK, wantUser: 2},
+		{name: "Invalid token", header: "Bearer bad", wantCode: codes.Unauthenticated},
+		{name: "Wrong scheme", header: "Basic user-token", wantCode: codes.Unauthenticated},
+		{name: "Auth service down", header: "Bearer down", wantCode: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.header))
+			}
+			whoami := func(ctx context.Context) int64 {
+				if principal, ok := FromContext(ctx); ok {
+					return principal.UserID
+				}
+				return 0
+			}
+
+			var unaryUser int64
+			_, err := a.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					unaryUser = whoami(ctx)
+					return nil, nil
+				})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantUser, unaryUser)
+
+			var streamUser int64
+			err = a.StreamServerInterceptor()(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{},
+				func(srv interface{}, ss grpc.ServerStream) error {
+					streamUser = whoami(ss.Context())
+					return nil
+				})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantUser, streamUser)
+		})
+	}
+}
+
+func TestRequirePrincipal(t *testing.T) {
+	_, err := RequirePrincipal(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	principal, err := RequirePrincipal(NewContext(context.Background(), &Principal{UserID: 3}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), principal.UserID)
+}
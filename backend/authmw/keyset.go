@@ -0,0 +1,191 @@
+package authmw
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrKeyUnavailable означает, что ключ для проверки токена получить не удалось:
+// JWKS недоступен или в нём нет такого kid. Токен в этом случае проверяется
+// через auth-servise.
+var ErrKeyUnavailable = errors.New("verification key unavailable")
+
+const (
+	defaultRefreshInterval = 5 * time.Minute
+	// minRefreshInterval ограничивает повторные запросы JWKS, когда приходят
+	// токены с неизвестным kid.
+	minRefreshInterval = 30 * time.Second
+	fetchTimeout       = 5 * time.Second
+)
+
+type publicKey struct {
+	alg string
+	key crypto.PublicKey
+}
+
+// KeySet — кэш открытых ключей auth-servise, загружаемых с /.well-known/jwks.json.
+type KeySet struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	fetchMu     sync.Mutex
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewKeySet создаёт кэш ключей. refreshInterval — как часто ключи
+// перечитываются, если их не пришлось обновить раньше из-за нового kid.
+func NewKeySet(url string, refreshInterval time.Duration) *KeySet {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+	return &KeySet{
+		url:             url,
+		client:          &http.Client{Timeout: fetchTimeout},
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+		keys:            make(map[string]publicKey),
+	}
+}
+
+// Keyfunc подходит для jwt.Parse: выбирает ключ по kid из заголовка токена.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid")
+	}
+
+	key, ok, stale := s.lookup(kid)
+	if !ok || stale {
+		s.refresh()
+		key, ok, _ = s.lookup(kid)
+	}
+	if !ok {
+		return nil, ErrKeyUnavailable
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.key, nil
+}
+
+func (s *KeySet) lookup(kid string) (publicKey, bool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+	return key, ok, s.now().Sub(s.fetchedAt) >= s.refreshInterval
+}
+
+// refresh перечитывает JWKS не чаще minRefreshInterval. При ошибке остаются
+// прежние ключи.
+func (s *KeySet) refresh() {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastAttempt) < minRefreshInterval {
+		return
+	}
+	s.lastAttempt = now
+
+	keys, err := s.fetch()
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = now
+	s.mu.Unlock()
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+func (s *KeySet) fetch() (map[string]publicKey, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected JWKS status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Незнакомые ключи пропускаем, чтобы не терять остальные.
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (publicKey, error) {
+	switch {
+	case k.Kty == "RSA" && k.Alg == jwt.SigningMethodRS256.Alg():
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+		return publicKey{
+			alg: k.Alg,
+			key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())},
+		}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519" && k.Alg == jwt.SigningMethodEdDSA.Alg():
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return publicKey{}, errors.New("invalid Ed25519 key size")
+		}
+		return publicKey{alg: k.Alg, key: ed25519.PublicKey(x)}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key %s/%s", k.Kty, k.Alg)
+	}
+}
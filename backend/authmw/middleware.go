@@ -0,0 +1,94 @@
+package authmw
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BearerToken достаёт токен из заголовка "Authorization: Bearer <token>".
+func BearerToken(r *http.Request) (string, bool) {
+	return parseBearer(r.Header.Get("Authorization"))
+}
+
+func parseBearer(header string) (string, bool) {
+	if header == "" {
+		return "", false
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Required пропускает запрос только с действительным токеном и кладёт
+// principal в контекст запроса.
+func (a *Authenticator) Required() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := BearerToken(c.Request)
+		if !ok {
+			Abort(c, ErrMissingToken)
+			return
+		}
+
+		principal, err := a.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			Abort(c, err)
+			return
+		}
+
+		c.Request = c.Request.WithContext(NewContext(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// Optional пропускает анонимные запросы, но отклоняет запрос с
+// недействительным токеном, чтобы клиент узнал, что его сессия закончилась.
+func (a *Authenticator) Optional() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		a.Required()(c)
+	}
+}
+
+// RequireRole пропускает только пользователей с одной из ролей. Должен стоять
+// после Required.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := Current(c)
+		if !ok {
+			Abort(c, ErrMissingToken)
+			return
+		}
+
+		for _, role := range roles {
+			if principal.Role == role {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+	}
+}
+
+// Abort завершает запрос ответом, соответствующим ошибке аутентификации.
+func Abort(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		c.Header("WWW-Authenticate", `Bearer`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+	case errors.Is(err, ErrAuthUnavailable):
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Authentication service unavailable"})
+	default:
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+	}
+}
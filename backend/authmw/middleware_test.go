@@ -0,0 +1,88 @@
+package authmw
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "backend.com/forum/proto"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRouter(a *Authenticator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	whoami := func(c *gin.Context) {
+		principal, ok := Current(c)
+		if !ok {
+			c.JSON(http.StatusOK, gin.H{"user_id": 0})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": principal.UserID, "username": principal.Username})
+	}
+
+	r.GET("/required", a.Required(), whoami)
+	r.GET("/optional", a.Optional(), whoami)
+	r.GET("/admin", a.Required(), RequireRole(RoleAdmin), whoami)
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	client := &fakeAuthClient{
+		validate: func(token string) (*pb.ValidateTokenResponse, error) {
+			switch token {
+			case "user-token":
+				return &pb.ValidateTokenResponse{Valid: true, UserId: 2, Username: "bob", Role: "user"}, nil
+			case "admin-token":
+				return &pb.ValidateTokenResponse{Valid: true, UserId: 1, Username: "root", Role: "admin"}, nil
+			case "down":
+				return nil, errors.New("unavailable")
+			default:
+				return &pb.ValidateTokenResponse{Valid: false}, nil
+			}
+		},
+	}
+	r := newTestRouter(NewAuthenticator(nil, client))
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "Required without header", path: "/required", wantStatus: http.StatusUnauthorized, wantBody: `{"error":"Authorization header is required"}`},
+		{name: "Required with wrong scheme", path: "/required", header: "Basic user-token", wantStatus: http.StatusUnauthorized},
+		{name: "Required with invalid token", path: "/required", header: "Bearer bad", wantStatus: http.StatusUnauthorized, wantBody: `{"error":"Invalid token"}`},
+		{name: "Required with valid token", path: "/required", header: "Bearer user-token", wantStatus: http.StatusOK, wantBody: `{"user_id":2,"username":"bob"}`},
+		{name: "Lowercase scheme", path: "/required", header: "bearer user-token", wantStatus: http.StatusOK},
+		{name: "Auth service down", path: "/required", header: "Bearer down", wantStatus: http.StatusServiceUnavailable},
+		{name: "Optional anonymous", path: "/optional", wantStatus: http.StatusOK, wantBody: `{"user_id":0}`},
+		{name: "Optional with invalid token", path: "/optional", header: "Bearer bad", wantStatus: http.StatusUnauthorized},
+		{name: "Optional with valid token", path: "/optional", header: "Bearer user-token", wantStatus: http.StatusOK, wantBody: `{"user_id":2,"username":"bob"}`},
+		{name: "Admin as user", path: "/admin", header: "Bearer user-token", wantStatus: http.StatusForbidden, wantBody: `{"error":"Permission denied"}`},
+		{name: "Admin as admin", path: "/admin", header: "Bearer admin-token", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
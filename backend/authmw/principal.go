@@ -0,0 +1,38 @@
+// Package authmw проверяет access-токены, выпущенные auth-servise, и кладёт
+// данные пользователя в контекст запроса. Используется forum- и chat-servise.
+package authmw
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RoleAdmin = "admin"
+
+// Principal — пользователь, от имени которого выполняется запрос.
+type Principal struct {
+	UserID   int64
+	Username string
+	Role     string
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p != nil && p.Role == RoleAdmin
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Current возвращает principal, который положил в запрос Required или Optional.
+func Current(c *gin.Context) (*Principal, bool) {
+	return FromContext(c.Request.Context())
+}
@@ -1,18 +1,31 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
 	_ "github.com/Mandarinka0707/newRepoGOODarhit/chat/docs"
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/handler"
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/usecase"
+	myWeb "github.com/Mandarinka0707/newRepoGOODarhit/chat/pkg/websocket"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // @title Chat Microservice API
@@ -42,25 +55,74 @@ func main() {
 	}
 	defer db.Close()
 
+	authConn, err := grpc.Dial(
+		"localhost:50051",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer authConn.Close()
+
+	authClient := pb.NewAuthServiceClient(authConn)
+	authenticator := authmw.NewAuthenticator(
+		authmw.NewKeySet("http://localhost:8080/.well-known/jwks.json", 5*time.Minute),
+		authClient,
+	)
+
+	// Форум нужен только для комнат тем: из него берётся название темы.
+	forumConn, err := grpc.Dial(
+		"localhost:50052",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer forumConn.Close()
+
 	repo := repository.NewMessageRepository(db)
-	uc := usecase.NewMessageUseCase(repo)
-	h := handler.NewMessageHandler(uc)
+	roomRepo := repository.NewRoomRepository(db)
+	uc := usecase.NewMessageUseCase(repo, chatReactions(), authClient)
+	roomUC := usecase.NewRoomUseCase(roomRepo, pb.NewForumServiceClient(forumConn))
+
+	// Фоновые задачи: очистка сообщений и пересылка уведомлений.
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	interval, batchSize := retentionConfig()
+	go usecase.NewRetentionJanitor(roomRepo, repo, interval, batchSize).Run(bgCtx)
 
-	go h.HandleMessages()
+	hub := myWeb.NewHub()
+	go hub.Run()
 
-	r := gin.Default()
-	r.Use(cors.Default())
+	// Уведомления форума приходят через LISTEN/NOTIFY и доставляются по тем
+	// же WebSocket-соединениям, что и сообщения чата.
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("notifications listener: %v", err)
+		}
+	})
+	if err := listener.Listen(usecase.NotificationChannel); err != nil {
+		log.Fatal(err)
+	}
+	defer listener.Close()
+	go usecase.NewNotificationRelay(listener.Notify, hub).Run(bgCtx)
+	origins := allowedOrigins()
+	h := handler.NewMessageHandler(uc, roomUC, hub, authenticator, origins)
+	roomHandler := handler.NewRoomHandler(roomUC, uc)
+	requireAuth := authenticator.Required()
+
+	// gin.Default писал бы в журнал полный URI вместе с ?token=.
+	r := gin.New()
+	r.Use(handler.RequestLogger(), gin.Recovery())
+	r.Use(cors.New(cors.Config{
+		AllowOrigins: origins,
+		AllowMethods: []string{"GET", "POST", "PUT", "OPTIONS"},
+		AllowHeaders: []string{"Authorization", "Content-Type"},
+		MaxAge:       12 * time.Hour,
+	}))
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// WebSocket endpoint
-	// @Summary WebSocket connection
-	// @Description Establishes a WebSocket connection for real-time chat
-	// @Tags chat
-	// @Accept json
-	// @Produce json
-	// @Success 101 {string} string "Switching Protocols"
-	// @Router /ws [get]
 	r.GET("/ws", h.HandleConnections)
 
 	// Get messages endpoint
@@ -72,9 +134,102 @@ func main() {
 	// @Success 200 {array} models.Message
 	// @Router /messages [get]
 	r.GET("/messages", h.GetMessages)
+	r.GET("/reactions", h.ListReactions)
+
+	rooms := r.Group("/rooms", requireAuth)
+	{
+		rooms.GET("", roomHandler.ListRooms)
+		rooms.POST("", roomHandler.CreateRoom)
+		rooms.POST("/:id/members", roomHandler.InviteMember)
+		rooms.PUT("/:id/retention", roomHandler.SetRetention)
+		rooms.GET("/:id/messages", roomHandler.GetRoomMessages)
+	}
+	r.GET("/topics/:id/room", requireAuth, roomHandler.GetTopicRoom)
+
+	server := &http.Server{
+		Addr:    ":8082",
+		Handler: r,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
 
 	log.Println("Listening on :8082...")
-	log.Fatal(r.Run(":8082"))
+
+	// Graceful shutdown: server.Shutdown не ждёт WebSocket-соединения,
+	// их закрывает хаб.
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	stopBackground()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown error: %v", err)
+	}
+	if err := hub.Shutdown(ctx); err != nil {
+		log.Printf("Hub shutdown error: %v", err)
+	}
+
+	log.Println("Server stopped")
+}
+
+// allowedOrigins — origin, с которых разрешено открывать WebSocket и
+// обращаться к HTTP API.
+// Список через запятую в CHAT_ALLOWED_ORIGINS, по умолчанию — фронтенд.
+func allowedOrigins() []string {
+	value := os.Getenv("CHAT_ALLOWED_ORIGINS")
+	if value == "" {
+		return []string{"http://localhost:3000"}
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// chatReactions — эмодзи, которые можно ставить на сообщения: список через
+// запятую в CHAT_REACTIONS, по умолчанию — usecase.DefaultReactions.
+func chatReactions() []string {
+	value := os.Getenv("CHAT_REACTIONS")
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// retentionConfig читает период очистки устаревших сообщений
+// (CHAT_RETENTION_INTERVAL, например "5m") и размер пачки удаления
+// (CHAT_RETENTION_BATCH_SIZE). Некорректные значения заменяются
+// значениями по умолчанию.
+func retentionConfig() (time.Duration, int) {
+	interval := usecase.DefaultRetentionInterval
+	if value := os.Getenv("CHAT_RETENTION_INTERVAL"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			interval = d
+		} else {
+			log.Printf("invalid CHAT_RETENTION_INTERVAL %q, using %s", value, interval)
+		}
+	}
+
+	batchSize := usecase.DefaultRetentionBatchSize
+	if value := os.Getenv("CHAT_RETENTION_BATCH_SIZE"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			batchSize = n
+		} else {
+			log.Printf("invalid CHAT_RETENTION_BATCH_SIZE %q, using %d", value, batchSize)
+		}
+	}
+	return interval, batchSize
 }
 
 // package main
@@ -2,7 +2,14 @@ module github.com/Mandarinka0707/newRepoGOODarhit/chat
 
 go 1.24.0
 
-require github.com/gin-gonic/gin v1.10.0
+require (
+	backend.com/forum/authmw v0.0.0-00010101000000-000000000000
+	backend.com/forum/mention v0.0.0-00010101000000-000000000000
+	backend.com/forum/notify v0.0.0-00010101000000-000000000000
+	backend.com/forum/proto v0.0.0-00010101000000-000000000000
+	github.com/gin-gonic/gin v1.10.0
+	google.golang.org/grpc v1.72.0
+)
 
 require (
 	github.com/DATA-DOG/go-sqlmock v1.5.2 // indirect
@@ -14,6 +21,7 @@ require (
 	github.com/go-openapi/jsonreference v0.19.6 // indirect
 	github.com/go-openapi/spec v0.20.4 // indirect
 	github.com/go-openapi/swag v0.19.15 // indirect
+	github.com/golang-jwt/jwt/v5 v5.2.2 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.6 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
@@ -21,6 +29,7 @@ require (
 	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/swaggo/swag v1.8.12 // indirect
 	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250218202821-56aae31c358a // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
 
@@ -58,3 +67,11 @@ require (
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 
 )
+
+replace backend.com/forum/proto => ../proto
+
+replace backend.com/forum/authmw => ../authmw
+
+replace backend.com/forum/mention => ../mention
+
+replace backend.com/forum/notify => ../notify
@@ -0,0 +1,77 @@
+package entity
+
+import "encoding/json"
+
+// Типы кадров WebSocket-протокола чата.
+const (
+	FrameMessage      = "message"
+	FrameJoin         = "join"
+	FrameLeave        = "leave"
+	FrameJoined       = "joined"
+	FrameLeft         = "left"
+	FrameError        = "error"
+	FrameHistory      = "history"
+	FrameReact        = "react"
+	FrameReaction     = "reaction"
+	FrameNotification = "notification"
+)
+
+// ClientFrame — кадр от клиента. Кадр без type считается сообщением, кадр
+// без room_id относится к общей комнате: так работают старые клиенты.
+// Before, After и Limit используются в запросе истории (type "history") так
+// же, как одноимённые параметры GET /rooms/{id}/messages. MessageID и
+// Emoji задают реакцию (type "react").
+type ClientFrame struct {
+	Type      string `json:"type" example:"message"`
+	RoomID    int64  `json:"room_id" example:"1"`
+	Message   string `json:"message,omitempty" example:"Hello, world!"`
+	Before    string `json:"before,omitempty"`
+	After     string `json:"after,omitempty"`
+	Limit     int    `json:"limit,omitempty" example:"50"`
+	MessageID int    `json:"message_id,omitempty" example:"10"`
+	Emoji     string `json:"emoji,omitempty" example:"👍"`
+}
+
+// ServerFrame — служебный кадр от сервера: подтверждение join/leave или ошибка.
+type ServerFrame struct {
+	Type   string `json:"type" example:"joined"`
+	RoomID int64  `json:"room_id,omitempty" example:"2"`
+	Error  string `json:"error,omitempty" example:"room not found"`
+}
+
+// MessageFrame — сообщение чата, как его получают клиенты.
+type MessageFrame struct {
+	Type string `json:"type" example:"message"`
+	Message
+}
+
+// HistoryFrame — ответ на запрос истории по WebSocket.
+type HistoryFrame struct {
+	Type   string `json:"type" example:"history"`
+	RoomID int64  `json:"room_id" example:"1"`
+	HistoryPage
+}
+
+// ReactionFrame — рассылается подписчикам комнаты, когда пользователь ставит
+// (Added) или снимает реакцию. Count — сколько реакций Emoji на сообщении
+// стало после этого.
+type ReactionFrame struct {
+	Type      string `json:"type" example:"reaction"`
+	RoomID    int64  `json:"room_id" example:"1"`
+	MessageID int    `json:"message_id" example:"10"`
+	UserID    int64  `json:"user_id" example:"42"`
+	Username  string `json:"username" example:"john_doe"`
+	Emoji     string `json:"emoji" example:"👍"`
+	Added     bool   `json:"added" example:"true"`
+	Count     int    `json:"count" example:"3"`
+}
+
+// NotificationFrame — новое уведомление центра уведомлений форума; приходит
+// на все соединения получателя. UnreadCount — сколько у него непрочитанных
+// уведомлений вместе с этим, Notification — строка таблицы notifications
+// (поля те же, что в GET /api/v1/notifications форума).
+type NotificationFrame struct {
+	Type         string          `json:"type" example:"notification"`
+	UnreadCount  int64           `json:"unread_count" example:"3"`
+	Notification json.RawMessage `json:"notification" swaggertype:"object"`
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor указывает на сообщение в истории комнаты. Сообщения упорядочены по
+// (Timestamp, ID): ID различает сообщения с одинаковым временем.
+type Cursor struct {
+	Timestamp time.Time
+	ID        int
+}
+
+// CursorOf возвращает курсор, указывающий на msg.
+func CursorOf(msg Message) Cursor {
+	return Cursor{Timestamp: msg.Timestamp, ID: msg.ID}
+}
+
+// String кодирует курсор в непрозрачную строку для API. Время хранится с
+// точностью до микросекунд, как в PostgreSQL.
+func (c Cursor) String() string {
+	raw := fmt.Sprintf("%d:%d", c.Timestamp.UnixMicro(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor разбирает строку, полученную из Cursor.String.
+func ParseCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var micros int64
+	var id int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &micros, &id); err != nil || id <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{Timestamp: time.UnixMicro(micros).UTC(), ID: id}, nil
+}
+
+// HistoryQuery — запрос страницы истории комнаты. Before и After
+// взаимоисключающие; без курсора возвращаются последние сообщения.
+// ViewerID — кто запрашивает историю: его реакции отмечаются Mine.
+type HistoryQuery struct {
+	RoomID   int64
+	Before   *Cursor
+	After    *Cursor
+	Limit    int
+	ViewerID int64
+}
+
+// HistoryPage — страница истории. Сообщения идут от старых к новым; Before и
+// After — курсоры первого и последнего сообщения страницы для следующих
+// запросов. HasMore — есть ли ещё сообщения в направлении запроса.
+type HistoryPage struct {
+	Messages []Message `json:"messages"`
+	HasMore  bool      `json:"has_more" example:"true"`
+	Before   string    `json:"before,omitempty" example:"MTcwNDExMDQwMDAwMDAwMDox"`
+	After    string    `json:"after,omitempty" example:"MTcwNDExMDQwMDAwMDAwMDo1MA"`
+}
@@ -1,8 +1,26 @@
 // internal/entity/message.go
 package entity
 
+import "time"
+
 type Message struct {
-	ID       int    `json:"id" example:"1"`
-	Username string `json:"username" example:"john_doe"`
-	Message  string `json:"message" example:"Hello, world!"`
-}
\ No newline at end of file
+	ID        int       `json:"id" example:"1"`
+	RoomID    int64     `json:"room_id" example:"1"`
+	UserID    int64     `json:"user_id" example:"42"`
+	Username  string    `json:"username" example:"john_doe"`
+	Message   string    `json:"message" example:"Hello, world!"`
+	Timestamp time.Time `json:"timestamp" example:"2024-01-01T12:00:00Z"`
+	// Reactions заполняется только в истории; у нового сообщения реакций нет.
+	Reactions []Reaction `json:"reactions,omitempty"`
+	// Mentions — упомянутые через @username пользователи. MessageHTML —
+	// текст сообщения в HTML, где упоминания стали ссылками; есть только у
+	// сообщений с упоминаниями.
+	Mentions    []Mention `json:"mentions,omitempty"`
+	MessageHTML string    `json:"message_html,omitempty" example:"hi <a href=\"/users/alice\">@alice</a>"`
+}
+
+// Mention — пользователь, упомянутый в сообщении.
+type Mention struct {
+	UserID   int64  `json:"user_id" example:"42"`
+	Username string `json:"username" example:"alice"`
+}
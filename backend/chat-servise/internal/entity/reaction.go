@@ -0,0 +1,9 @@
+package entity
+
+// Reaction — число реакций одного эмодзи на сообщение. Mine — есть ли среди
+// них реакция пользователя, запросившего историю.
+type Reaction struct {
+	Emoji string `json:"emoji" example:"👍"`
+	Count int    `json:"count" example:"3"`
+	Mine  bool   `json:"mine" example:"true"`
+}
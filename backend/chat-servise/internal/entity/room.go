@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// GeneralRoomID — общая комната, куда попадают все без приглашения.
+const GeneralRoomID int64 = 1
+
+const (
+	RoomRoleOwner  = "owner"
+	RoomRoleMember = "member"
+)
+
+// Режимы хранения сообщений комнаты.
+const (
+	RetentionForever = "forever"
+	RetentionDays    = "days"
+	RetentionCount   = "count"
+)
+
+// RetentionPolicy — сколько хранятся сообщения комнаты. Value — число дней
+// для режима days или число последних сообщений для count. С Archive
+// устаревшие сообщения переносятся в архив, а не удаляются.
+type RetentionPolicy struct {
+	Mode    string `json:"mode" binding:"required" example:"days"`
+	Value   int    `json:"value,omitempty" example:"30"`
+	Archive bool   `json:"archive" example:"false"`
+}
+
+type Room struct {
+	ID        int64           `json:"id" example:"1"`
+	Name      string          `json:"name" example:"general"`
+	IsPrivate bool            `json:"is_private" example:"false"`
+	TopicID   *int64          `json:"topic_id,omitempty" example:"3"`
+	CreatedBy int64           `json:"created_by,omitempty" example:"42"`
+	CreatedAt time.Time       `json:"created_at"`
+	Retention RetentionPolicy `json:"retention"`
+}
+
+type RoomMember struct {
+	RoomID   int64     `json:"room_id" example:"2"`
+	UserID   int64     `json:"user_id" example:"42"`
+	Role     string    `json:"role" example:"member"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type CreateRoomRequest struct {
+	Name      string `json:"name" binding:"required" example:"golang"`
+	IsPrivate bool   `json:"is_private" example:"false"`
+}
+
+type InviteRequest struct {
+	UserID int64 `json:"user_id" binding:"required" example:"42"`
+}
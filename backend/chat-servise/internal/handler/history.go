@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/usecase"
+)
+
+// parseHistoryQuery собирает запрос истории из параметров before, after и
+// limit — из строки запроса или из кадра WebSocket.
+func parseHistoryQuery(roomID int64, before, after, limit string) (entity.HistoryQuery, error) {
+	query := entity.HistoryQuery{RoomID: roomID}
+
+	if before != "" {
+		cursor, err := entity.ParseCursor(before)
+		if err != nil {
+			return query, err
+		}
+		query.Before = &cursor
+	}
+	if after != "" {
+		cursor, err := entity.ParseCursor(after)
+		if err != nil {
+			return query, err
+		}
+		query.After = &cursor
+	}
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return query, usecase.ErrInvalidPageSize
+		}
+		query.Limit = n
+	}
+	return query, nil
+}
+
+// isHistoryQueryError — ошибка в параметрах запроса истории, а не на сервере.
+func isHistoryQueryError(err error) bool {
+	return errors.Is(err, entity.ErrInvalidCursor) ||
+		errors.Is(err, usecase.ErrInvalidPageSize) ||
+		errors.Is(err, usecase.ErrConflictingCursors)
+}
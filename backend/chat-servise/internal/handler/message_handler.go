@@ -3,72 +3,326 @@ package handler
 
 import (
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/usecase"
 	myWeb "github.com/Mandarinka0707/newRepoGOODarhit/chat/pkg/websocket"
 
+	"context"
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"backend.com/forum/authmw"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// sessionCheckInterval — как часто открытое соединение перепроверяет
+	// токен, чтобы заметить отзыв сессии.
+	sessionCheckInterval = time.Minute
+	sessionCheckTimeout  = 5 * time.Second
 )
 
 type MessageHandler struct {
-	Uc usecase.MessageUseCase
+	Uc       usecase.MessageUseCase
+	rooms    usecase.RoomUseCase
+	hub      *myWeb.Hub
+	auth     *authmw.Authenticator
+	upgrader websocket.Upgrader
+
+	sessionCheck time.Duration
 }
 
-func NewMessageHandler(uc usecase.MessageUseCase) *MessageHandler {
-	return &MessageHandler{Uc: uc}
+// NewMessageHandler создаёт обработчик чата. WebSocket-соединения
+// принимаются только с токеном и только с allowedOrigins; сообщения
+// рассылаются через hub, который должен быть запущен (hub.Run).
+func NewMessageHandler(uc usecase.MessageUseCase, rooms usecase.RoomUseCase, hub *myWeb.Hub, auth *authmw.Authenticator, allowedOrigins []string) *MessageHandler {
+	return &MessageHandler{
+		Uc:       uc,
+		rooms:    rooms,
+		hub:      hub,
+		auth:     auth,
+		upgrader: myWeb.NewUpgrader(allowedOrigins),
+
+		sessionCheck: sessionCheckInterval,
+	}
 }
 
+// HandleConnections открывает WebSocket-соединение для чата.
+//
+// Браузер не умеет передавать заголовок Authorization при открытии
+// WebSocket, поэтому токен принимается в параметре token или подпротоколом
+// "Sec-WebSocket-Protocol: bearer, <token>". Автор сообщений берётся из
+// токена, поля user_id и username от клиента игнорируются. Пока соединение
+// открыто, токен периодически перепроверяется; после logout, отзыва сессии
+// или истечения токена соединение закрывается с кодом 1008, и клиент
+// переподключается с новым токеном.
+//
+// Клиент сразу подписан на общую комнату. Кадры протокола описаны в
+// entity.ClientFrame: {"type":"join","room_id":2} подписывает на комнату,
+// "leave" — отписывает, "message" отправляет сообщение в комнату, на которую
+// клиент подписан, "history" возвращает страницу истории комнаты
+// (кадр entity.HistoryFrame) — так клиент подгружает старые сообщения.
+// "react" ставит или снимает реакцию на сообщение комнаты; все подписчики
+// получают кадр entity.ReactionFrame.
+//
+// @Summary WebSocket-соединение чата
+// @Tags chat
+// @Param token query string false "Access-токен"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Router /ws [get]
 func (h *MessageHandler) HandleConnections(c *gin.Context) {
-	ws, err := myWeb.Upgrader.Upgrade(c.Writer, c.Request, nil)
+	if !h.upgrader.CheckOrigin(c.Request) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Origin not allowed"})
+		return
+	}
+
+	token, ok := wsToken(c.Request)
+	if !ok {
+		authmw.Abort(c, authmw.ErrMissingToken)
+		return
+	}
+
+	user, err := h.auth.Authenticate(c.Request.Context(), token)
 	if err != nil {
-		log.Fatal(err)
+		authmw.Abort(c, err)
+		return
 	}
-	defer ws.Close()
 
-	myWeb.Clients[ws] = true
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrader уже ответил клиенту.
+		log.Printf("websocket upgrade failed: %v", err)
+		return
+	}
 
-	for {
-		var msg entity.Message
-		err := ws.ReadJSON(&msg)
-		if err != nil {
-			delete(myWeb.Clients, ws)
-			break
-		}
-		h.Uc.SaveMessage(msg)
-		myWeb.Broadcast <- msg
+	// Подписки соединения. onMessage вызывается из одной горутины чтения,
+	// поэтому блокировка не нужна.
+	joined := map[int64]bool{entity.GeneralRoomID: true}
+	onMessage := func(client *myWeb.Client, data []byte) {
+		h.handleFrame(client, joined, data)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go h.watchSession(ws, token, stop)
+
+	if err := h.hub.Serve(ws, user.UserID, user.Username, []int64{entity.GeneralRoomID}, onMessage); err != nil {
+		log.Printf("websocket connection rejected: %v", err)
 	}
 }
 
-func (h *MessageHandler) HandleMessages() {
+// watchSession закрывает соединение, когда auth-servise перестаёт принимать
+// его токен. Если auth-servise недоступен, соединение остаётся открытым до
+// следующей проверки.
+func (h *MessageHandler) watchSession(ws *websocket.Conn, token string, stop <-chan struct{}) {
+	ticker := time.NewTicker(h.sessionCheck)
+	defer ticker.Stop()
+
 	for {
-		msg := <-myWeb.Broadcast
-		for client := range myWeb.Clients {
-			err := client.WriteJSON(msg)
-			if err != nil {
-				log.Printf("error: %v", err)
-				client.Close()
-				delete(myWeb.Clients, client)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), sessionCheckTimeout)
+			_, err := h.auth.Authenticate(ctx, token)
+			cancel()
+			if !errors.Is(err, authmw.ErrInvalidToken) {
+				continue
 			}
+			// Управляющие кадры можно писать параллельно с горутиной записи хаба.
+			// После Close чтение в Serve завершится и хаб отключит клиента.
+			ws.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session revoked"),
+				time.Now().Add(time.Second))
+			ws.Close()
+			return
+		}
+	}
+}
+
+func (h *MessageHandler) handleFrame(client *myWeb.Client, joined map[int64]bool, data []byte) {
+	var frame entity.ClientFrame
+	if err := json.Unmarshal(data, &frame); err != nil {
+		h.sendError(client, 0, "invalid frame")
+		return
+	}
+	if frame.RoomID == 0 {
+		frame.RoomID = entity.GeneralRoomID
+	}
+
+	switch frame.Type {
+	case "", entity.FrameMessage:
+		if !joined[frame.RoomID] {
+			h.sendError(client, frame.RoomID, "join the room first")
+			return
+		}
+		h.handleMessage(client, frame)
+	case entity.FrameJoin:
+		if err := h.rooms.Join(frame.RoomID, client.UserID); err != nil {
+			h.sendError(client, frame.RoomID, roomErrorText(err))
+			return
+		}
+		joined[frame.RoomID] = true
+		h.hub.Join(client, frame.RoomID)
+		h.hub.Send(client, entity.ServerFrame{Type: entity.FrameJoined, RoomID: frame.RoomID})
+	case entity.FrameHistory:
+		if !joined[frame.RoomID] {
+			h.sendError(client, frame.RoomID, "join the room first")
+			return
+		}
+		h.handleHistory(client, frame)
+	case entity.FrameReact:
+		if !joined[frame.RoomID] {
+			h.sendError(client, frame.RoomID, "join the room first")
+			return
+		}
+		h.handleReaction(client, frame)
+	case entity.FrameLeave:
+		if err := h.rooms.Leave(frame.RoomID, client.UserID); err != nil {
+			h.sendError(client, frame.RoomID, roomErrorText(err))
+			return
+		}
+		delete(joined, frame.RoomID)
+		h.hub.Leave(client, frame.RoomID)
+		h.hub.Send(client, entity.ServerFrame{Type: entity.FrameLeft, RoomID: frame.RoomID})
+	default:
+		h.sendError(client, frame.RoomID, "unknown frame type")
+	}
+}
+
+// handleMessage сохраняет сообщение клиента и рассылает его подписчикам комнаты.
+func (h *MessageHandler) handleMessage(client *myWeb.Client, frame entity.ClientFrame) {
+	msg := entity.Message{
+		RoomID:   frame.RoomID,
+		UserID:   client.UserID,
+		Username: client.Username,
+		Message:  frame.Message,
+	}
+
+	if err := h.Uc.SaveMessage(&msg); err != nil {
+		log.Printf("error saving message: %v", err)
+		h.sendError(client, frame.RoomID, "failed to save message")
+		return
+	}
+	if err := h.hub.BroadcastRoom(msg.RoomID, entity.MessageFrame{Type: entity.FrameMessage, Message: msg}); err != nil {
+		log.Printf("error broadcasting message: %v", err)
+	}
+}
+
+// handleHistory отправляет клиенту страницу истории комнаты.
+func (h *MessageHandler) handleHistory(client *myWeb.Client, frame entity.ClientFrame) {
+	var limit string
+	if frame.Limit != 0 {
+		limit = strconv.Itoa(frame.Limit)
+	}
+
+	query, err := parseHistoryQuery(frame.RoomID, frame.Before, frame.After, limit)
+	if err != nil {
+		h.sendError(client, frame.RoomID, err.Error())
+		return
+	}
+	query.ViewerID = client.UserID
+
+	page, err := h.Uc.GetMessages(query)
+	if err != nil {
+		if isHistoryQueryError(err) {
+			h.sendError(client, frame.RoomID, err.Error())
+			return
+		}
+		log.Printf("error loading history: %v", err)
+		h.sendError(client, frame.RoomID, "failed to load history")
+		return
+	}
+	h.hub.Send(client, entity.HistoryFrame{Type: entity.FrameHistory, RoomID: frame.RoomID, HistoryPage: *page})
+}
+
+// handleReaction переключает реакцию клиента и рассылает событие
+// подписчикам комнаты, включая самого клиента.
+func (h *MessageHandler) handleReaction(client *myWeb.Client, frame entity.ClientFrame) {
+	event, err := h.Uc.ToggleReaction(frame.RoomID, frame.MessageID, client.UserID, client.Username, frame.Emoji)
+	switch {
+	case errors.Is(err, usecase.ErrUnknownReaction), errors.Is(err, repository.ErrMessageNotFound):
+		h.sendError(client, frame.RoomID, err.Error())
+		return
+	case err != nil:
+		log.Printf("error toggling reaction: %v", err)
+		h.sendError(client, frame.RoomID, "failed to toggle reaction")
+		return
+	}
+	if err := h.hub.BroadcastRoom(event.RoomID, event); err != nil {
+		log.Printf("error broadcasting reaction: %v", err)
+	}
+}
+
+func (h *MessageHandler) sendError(client *myWeb.Client, roomID int64, text string) {
+	h.hub.Send(client, entity.ServerFrame{Type: entity.FrameError, RoomID: roomID, Error: text})
+}
+
+// wsToken достаёт токен из параметра token, подпротокола bearer или
+// заголовка Authorization (для клиентов не из браузера).
+func wsToken(r *http.Request) (string, bool) {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token, true
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i := 0; i+1 < len(protocols); i++ {
+		if strings.EqualFold(protocols[i], myWeb.Subprotocol) {
+			return protocols[i+1], true
 		}
 	}
+
+	return authmw.BearerToken(r)
 }
 
-// GetMessages получает список всех сообщений.
+// GetMessages получает сообщения общей комнаты. Ответ — массив, как и
+// раньше; курсоры для следующей страницы есть в GET /rooms/{id}/messages.
 //
 // @Summary Получить сообщения
-// @Description Возвращает все сообщения из чата
+// @Description Возвращает последние сообщения общей комнаты чата или страницу рядом с курсором
 // @Tags messages
 // @Produce json
+// @Param before query string false "Курсор: сообщения старше"
+// @Param after query string false "Курсор: сообщения новее"
+// @Param limit query int false "Размер страницы (по умолчанию 50, не больше 100)"
 // @Success 200 {array} entity.Message
+// @Failure 400 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
 // @Router /messages [get]
 func (h *MessageHandler) GetMessages(c *gin.Context) {
-	messages, err := h.Uc.GetMessages()
+	query, err := parseHistoryQuery(entity.GeneralRoomID, c.Query("before"), c.Query("after"), c.Query("limit"))
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.Uc.GetMessages(query)
+	if err != nil {
+		if isHistoryQueryError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, messages)
+	c.JSON(http.StatusOK, page.Messages)
+}
+
+// ListReactions возвращает эмодзи, которые можно ставить на сообщения.
+//
+// @Summary Разрешённые реакции
+// @Tags messages
+// @Produce json
+// @Success 200 {object} map[string][]string
+// @Router /reactions [get]
+func (h *MessageHandler) ListReactions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"reactions": h.Uc.Reactions()})
 }
@@ -1,44 +1,162 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/usecase"
 	myWeb "github.com/Mandarinka0707/newRepoGOODarhit/chat/pkg/websocket"
 
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 type MockMessageUseCase struct {
 	mock.Mock
 }
 
-func (m *MockMessageUseCase) SaveMessage(msg entity.Message) error {
+func (m *MockMessageUseCase) SaveMessage(msg *entity.Message) error {
 	args := m.Called(msg)
 	return args.Error(0)
 }
 
-func (m *MockMessageUseCase) GetMessages() ([]entity.Message, error) {
+func (m *MockMessageUseCase) GetMessages(query entity.HistoryQuery) (*entity.HistoryPage, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.HistoryPage), args.Error(1)
+}
+
+func (m *MockMessageUseCase) Reactions() []string {
 	args := m.Called()
-	return args.Get(0).([]entity.Message), args.Error(1)
+	return args.Get(0).([]string)
+}
+
+func (m *MockMessageUseCase) ToggleReaction(roomID int64, messageID int, userID int64, username, emoji string) (*entity.ReactionFrame, error) {
+	args := m.Called(roomID, messageID, userID, username, emoji)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.ReactionFrame), args.Error(1)
+}
+
+type MockRoomUseCase struct {
+	mock.Mock
+}
+
+func (m *MockRoomUseCase) CreateRoom(ownerID int64, name string, isPrivate bool) (*entity.Room, error) {
+	args := m.Called(ownerID, name, isPrivate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Room), args.Error(1)
+}
+
+func (m *MockRoomUseCase) ListRooms(userID int64) ([]entity.Room, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Room), args.Error(1)
+}
+
+func (m *MockRoomUseCase) CanAccess(roomID, userID int64) error {
+	return m.Called(roomID, userID).Error(0)
+}
+
+func (m *MockRoomUseCase) Join(roomID, userID int64) error {
+	return m.Called(roomID, userID).Error(0)
+}
+
+func (m *MockRoomUseCase) Leave(roomID, userID int64) error {
+	return m.Called(roomID, userID).Error(0)
+}
+
+func (m *MockRoomUseCase) Invite(roomID, inviterID, userID int64) error {
+	return m.Called(roomID, inviterID, userID).Error(0)
+}
+
+func (m *MockRoomUseCase) SetRetention(roomID int64, user *authmw.Principal, policy entity.RetentionPolicy) (*entity.Room, error) {
+	args := m.Called(roomID, user, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Room), args.Error(1)
+}
+
+func (m *MockRoomUseCase) TopicRoom(topicID int64) (*entity.Room, error) {
+	args := m.Called(topicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Room), args.Error(1)
+}
+
+// fakeAuthClient принимает только токен "valid_token", пока сессия не
+// отозвана.
+type fakeAuthClient struct {
+	pb.AuthServiceClient
+	revoked atomic.Bool
+}
+
+func (f *fakeAuthClient) ValidateToken(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
+	if in.Token != "valid_token" || f.revoked.Load() {
+		return &pb.ValidateTokenResponse{Valid: false}, nil
+	}
+	return &pb.ValidateTokenResponse{Valid: true, UserId: 7, Username: "alice", Role: "user"}, nil
+}
+
+const testOrigin = "http://localhost:3000"
+
+func newTestHandler(t *testing.T, uc *MockMessageUseCase) *MessageHandler {
+	return newTestRoomsHandler(t, uc, new(MockRoomUseCase))
+}
+
+func newTestRoomsHandler(t *testing.T, uc *MockMessageUseCase, rooms *MockRoomUseCase) *MessageHandler {
+	hub := myWeb.NewHub()
+	go hub.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		hub.Shutdown(ctx)
+	})
+	return NewMessageHandler(uc, rooms, hub, authmw.NewAuthenticator(nil, &fakeAuthClient{}), []string{testOrigin})
+}
+
+func newTestServer(t *testing.T, h *MessageHandler) string {
+	router := gin.New()
+	router.GET("/ws", h.HandleConnections)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
 }
 
 func TestMessageHandler_GetMessages(t *testing.T) {
 
 	uc := new(MockMessageUseCase)
 
-	uc.On("GetMessages").Return([]entity.Message{
+	uc.On("GetMessages", entity.HistoryQuery{RoomID: entity.GeneralRoomID}).Return(&entity.HistoryPage{Messages: []entity.Message{
 		{ID: 1, Username: "testuser", Message: "Hello, World!"},
-	}, nil)
+	}}, nil)
 
-	handler := NewMessageHandler(uc)
+	handler := newTestHandler(t, uc)
 
 	router := gin.Default()
 	router.GET("/messages", handler.GetMessages)
@@ -59,58 +177,221 @@ func TestMessageHandler_GetMessages(t *testing.T) {
 }
 
 func TestMessageHandler_HandleConnections(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+	}{
+		{name: "Token in query", path: "?token=valid_token"},
+		{name: "Token in subprotocol", header: http.Header{"Sec-WebSocket-Protocol": {"bearer, valid_token"}}},
+		{name: "Token in Authorization header", header: http.Header{"Authorization": {"Bearer valid_token"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := make(chan entity.Message, 1)
+			uc := new(MockMessageUseCase)
+			uc.On("SaveMessage", mock.Anything).Run(func(args mock.Arguments) {
+				saved <- *args.Get(0).(*entity.Message)
+			}).Return(nil)
+
+			url := newTestServer(t, newTestHandler(t, uc))
+
+			header := http.Header{"Origin": {testOrigin}}
+			for k, v := range tt.header {
+				header[k] = v
+			}
+			ws, resp, err := websocket.DefaultDialer.Dial(url+tt.path, header)
+			require.NoError(t, err)
+			defer ws.Close()
+			if tt.header.Get("Sec-WebSocket-Protocol") != "" {
+				assert.Equal(t, "bearer", resp.Header.Get("Sec-WebSocket-Protocol"))
+			}
+
+			// Клиент пытается выдать себя за другого пользователя.
+			err = ws.WriteJSON(map[string]interface{}{"user_id": 1, "username": "admin", "message": "Hello, World!"})
+			require.NoError(t, err)
+
+			select {
+			case msg := <-saved:
+				assert.Equal(t, int64(7), msg.UserID)
+				assert.Equal(t, "alice", msg.Username)
+				assert.Equal(t, "Hello, World!", msg.Message)
+			case <-time.After(time.Second):
+				t.Fatal("message was not saved")
+			}
+		})
+	}
+}
 
-	uc := new(MockMessageUseCase)
-
-	uc.On("SaveMessage", mock.Anything).Return(nil)
+func TestMessageHandler_HandleConnections_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		origin     string
+		wantStatus int
+	}{
+		{name: "Without token", origin: testOrigin, wantStatus: http.StatusUnauthorized},
+		{name: "Invalid token", path: "?token=bad", origin: testOrigin, wantStatus: http.StatusUnauthorized},
+		{name: "Foreign origin", path: "?token=valid_token", origin: "http://evil.example", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockMessageUseCase)
+			url := newTestServer(t, newTestHandler(t, uc))
+
+			_, resp, err := websocket.DefaultDialer.Dial(url+tt.path, http.Header{"Origin": {tt.origin}})
+
+			require.ErrorIs(t, err, websocket.ErrBadHandshake)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			uc.AssertNotCalled(t, "SaveMessage", mock.Anything)
+		})
+	}
+}
 
-	handler := NewMessageHandler(uc)
+func TestMessageHandler_BroadcastsSavedMessages(t *testing.T) {
+	sentAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc := new(MockMessageUseCase)
+	uc.On("SaveMessage", mock.Anything).Run(func(args mock.Arguments) {
+		msg := args.Get(0).(*entity.Message)
+		msg.ID = 5
+		msg.Timestamp = sentAt
+	}).Return(nil)
+	h := newTestHandler(t, uc)
+	url := newTestServer(t, h)
+
+	header := http.Header{"Origin": {testOrigin}}
+	sender, _, err := websocket.DefaultDialer.Dial(url+"?token=valid_token", header)
+	require.NoError(t, err)
+	defer sender.Close()
+	receiver, _, err := websocket.DefaultDialer.Dial(url+"?token=valid_token", header)
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	require.Eventually(t, func() bool { return h.hub.ClientCount() == 2 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, sender.WriteJSON(entity.Message{Username: "mallory", Message: "Hello, World!"}))
+
+	var frame entity.MessageFrame
+	receiver.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, receiver.ReadJSON(&frame))
+	assert.Equal(t, entity.FrameMessage, frame.Type)
+	assert.Equal(t, entity.Message{ID: 5, RoomID: entity.GeneralRoomID, UserID: 7, Username: "alice", Message: "Hello, World!", Timestamp: sentAt}, frame.Message)
+}
 
-	router := gin.Default()
-	router.GET("/ws", handler.HandleConnections)
+func dialChat(t *testing.T, url string) *websocket.Conn {
+	ws, _, err := websocket.DefaultDialer.Dial(url+"?token=valid_token", http.Header{"Origin": {testOrigin}})
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
 
-	server := httptest.NewServer(router)
-	defer server.Close()
+func readFrame(t *testing.T, ws *websocket.Conn, v interface{}) {
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(t, ws.ReadJSON(v))
+}
 
-	url := "ws" + server.URL[4:] + "/ws"
-	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
-	assert.NoError(t, err)
-	defer ws.Close()
+func TestMessageHandler_ClosesRevokedSession(t *testing.T) {
+	auth := &fakeAuthClient{}
+	h := newTestHandler(t, new(MockMessageUseCase))
+	h.auth = authmw.NewAuthenticator(nil, auth)
+	h.sessionCheck = 10 * time.Millisecond
+	ws := dialChat(t, newTestServer(t, h))
+	require.Eventually(t, func() bool { return h.hub.ClientCount() == 1 }, time.Second, 10*time.Millisecond)
 
-	msg := entity.Message{Username: "testuser", Message: "Hello, World!"}
-	err = ws.WriteJSON(msg)
-	assert.NoError(t, err)
+	auth.revoked.Store(true)
 
-	uc.AssertExpectations(t)
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := ws.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "unexpected error: %v", err)
+	require.Eventually(t, func() bool { return h.hub.ClientCount() == 0 }, time.Second, 10*time.Millisecond)
 }
 
-func TestMessageHandler_HandleMessages(t *testing.T) {
-	// Создаем мок usecase
+func TestMessageHandler_RoomBroadcast(t *testing.T) {
 	uc := new(MockMessageUseCase)
+	uc.On("SaveMessage", mock.Anything).Return(nil)
+	rooms := new(MockRoomUseCase)
+	rooms.On("Join", int64(2), int64(7)).Return(nil)
+	h := newTestRoomsHandler(t, uc, rooms)
+	url := newTestServer(t, h)
+
+	member := dialChat(t, url)
+	outsider := dialChat(t, url)
+	require.Eventually(t, func() bool { return h.hub.ClientCount() == 2 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, member.WriteJSON(entity.ClientFrame{Type: entity.FrameJoin, RoomID: 2}))
+	var ack entity.ServerFrame
+	readFrame(t, member, &ack)
+	assert.Equal(t, entity.ServerFrame{Type: entity.FrameJoined, RoomID: 2}, ack)
+
+	require.NoError(t, member.WriteJSON(entity.ClientFrame{Type: entity.FrameMessage, RoomID: 2, Message: "only for room 2"}))
+	var frame entity.MessageFrame
+	readFrame(t, member, &frame)
+	assert.Equal(t, int64(2), frame.RoomID)
+	assert.Equal(t, "only for room 2", frame.Message.Message)
+
+	// Сообщение в общую комнату доходит до всех, а сообщение комнаты 2 до
+	// outsider дойти не должно: первым он получит именно общее.
+	require.NoError(t, member.WriteJSON(entity.ClientFrame{Message: "for everyone"}))
+	readFrame(t, outsider, &frame)
+	assert.Equal(t, entity.GeneralRoomID, frame.RoomID)
+	assert.Equal(t, "for everyone", frame.Message.Message)
+}
 
-	// Создаем MessageHandler
-	handler := NewMessageHandler(uc)
-
-	// Создаем канал для тестирования
-	broadcast := make(chan entity.Message)
-	myWeb.Broadcast = broadcast
-
-	// Запускаем горутину для обработки сообщений
-	go handler.HandleMessages()
-
-	// Отправляем сообщение в канал
-	msg := entity.Message{Username: "testuser", Message: "Hello, World!"}
-	broadcast <- msg
-
-	// Проверяем, что сообщение было отправлено всем клиентам
-	// (здесь можно добавить проверку для клиентов, если они есть)
+func TestMessageHandler_RoomErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   entity.ClientFrame
+		joinErr error
+		want    entity.ServerFrame
+	}{
+		{
+			name:  "Message to room without join",
+			frame: entity.ClientFrame{Type: entity.FrameMessage, RoomID: 3, Message: "hi"},
+			want:  entity.ServerFrame{Type: entity.FrameError, RoomID: 3, Error: "join the room first"},
+		},
+		{
+			name:    "Join private room",
+			frame:   entity.ClientFrame{Type: entity.FrameJoin, RoomID: 3},
+			joinErr: usecase.ErrRoomAccessDenied,
+			want:    entity.ServerFrame{Type: entity.FrameError, RoomID: 3, Error: usecase.ErrRoomAccessDenied.Error()},
+		},
+		{
+			name:    "Join with database error",
+			frame:   entity.ClientFrame{Type: entity.FrameJoin, RoomID: 3},
+			joinErr: errors.New("database error"),
+			want:    entity.ServerFrame{Type: entity.FrameError, RoomID: 3, Error: "internal server error"},
+		},
+		{
+			name:  "Unknown frame",
+			frame: entity.ClientFrame{Type: "typing", RoomID: 3},
+			want:  entity.ServerFrame{Type: entity.FrameError, RoomID: 3, Error: "unknown frame type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockMessageUseCase)
+			rooms := new(MockRoomUseCase)
+			rooms.On("Join", int64(3), int64(7)).Return(tt.joinErr)
+			url := newTestServer(t, newTestRoomsHandler(t, uc, rooms))
+
+			ws := dialChat(t, url)
+			require.NoError(t, ws.WriteJSON(tt.frame))
+
+			var got entity.ServerFrame
+			readFrame(t, ws, &got)
+			assert.Equal(t, tt.want, got)
+			uc.AssertNotCalled(t, "SaveMessage", mock.Anything)
+		})
+	}
 }
 
 func TestMessageHandler_GetMessages_Error(t *testing.T) {
 	uc := new(MockMessageUseCase)
-	uc.On("GetMessages").Return(nil, errors.New("database error"))
+	uc.On("GetMessages", mock.Anything).Return(nil, errors.New("database error"))
 
-	handler := NewMessageHandler(uc)
+	handler := newTestHandler(t, uc)
 	router := gin.Default()
 	router.GET("/messages", handler.GetMessages)
 
@@ -121,3 +402,149 @@ func TestMessageHandler_GetMessages_Error(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	uc.AssertExpectations(t)
 }
+
+func TestMessageHandler_GetMessages_Query(t *testing.T) {
+	cursor := entity.Cursor{Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), ID: 10}
+
+	tests := []struct {
+		name       string
+		query      string
+		want       entity.HistoryQuery
+		wantStatus int
+	}{
+		{
+			name:       "Before cursor",
+			query:      "?before=" + cursor.String() + "&limit=20",
+			want:       entity.HistoryQuery{RoomID: entity.GeneralRoomID, Before: &cursor, Limit: 20},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "After cursor",
+			query:      "?after=" + cursor.String(),
+			want:       entity.HistoryQuery{RoomID: entity.GeneralRoomID, After: &cursor},
+			wantStatus: http.StatusOK,
+		},
+		{name: "Invalid cursor", query: "?before=garbage!", wantStatus: http.StatusBadRequest},
+		{name: "Invalid limit", query: "?limit=many", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(MockMessageUseCase)
+			uc.On("GetMessages", tt.want).Return(&entity.HistoryPage{Messages: []entity.Message{}}, nil)
+			router := gin.New()
+			router.GET("/messages", newTestHandler(t, uc).GetMessages)
+
+			req, _ := http.NewRequest("GET", "/messages"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.JSONEq(t, `[]`, w.Body.String())
+				uc.AssertExpectations(t)
+			} else {
+				uc.AssertNotCalled(t, "GetMessages", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestMessageHandler_LoadHistory(t *testing.T) {
+	cursor := entity.Cursor{Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), ID: 10}
+	older := entity.Message{ID: 9, RoomID: entity.GeneralRoomID, UserID: 7, Username: "alice", Message: "older", Timestamp: cursor.Timestamp}
+
+	uc := new(MockMessageUseCase)
+	uc.On("GetMessages", entity.HistoryQuery{RoomID: entity.GeneralRoomID, Before: &cursor, Limit: 1, ViewerID: 7}).
+		Return(&entity.HistoryPage{Messages: []entity.Message{older}, HasMore: true, Before: "b", After: "a"}, nil)
+	url := newTestServer(t, newTestHandler(t, uc))
+	ws := dialChat(t, url)
+
+	require.NoError(t, ws.WriteJSON(entity.ClientFrame{Type: entity.FrameHistory, Before: cursor.String(), Limit: 1}))
+	var frame entity.HistoryFrame
+	readFrame(t, ws, &frame)
+	assert.Equal(t, entity.HistoryFrame{
+		Type:        entity.FrameHistory,
+		RoomID:      entity.GeneralRoomID,
+		HistoryPage: entity.HistoryPage{Messages: []entity.Message{older}, HasMore: true, Before: "b", After: "a"},
+	}, frame)
+
+	// История чужой комнаты без join недоступна.
+	require.NoError(t, ws.WriteJSON(entity.ClientFrame{Type: entity.FrameHistory, RoomID: 3}))
+	var errFrame entity.ServerFrame
+	readFrame(t, ws, &errFrame)
+	assert.Equal(t, entity.ServerFrame{Type: entity.FrameError, RoomID: 3, Error: "join the room first"}, errFrame)
+
+	require.NoError(t, ws.WriteJSON(entity.ClientFrame{Type: entity.FrameHistory, Before: "garbage!"}))
+	readFrame(t, ws, &errFrame)
+	assert.Equal(t, entity.ServerFrame{Type: entity.FrameError, RoomID: entity.GeneralRoomID, Error: entity.ErrInvalidCursor.Error()}, errFrame)
+	uc.AssertNumberOfCalls(t, "GetMessages", 1)
+}
+
+func TestMessageHandler_Reactions(t *testing.T) {
+	uc := new(MockMessageUseCase)
+	event := &entity.ReactionFrame{
+		Type: entity.FrameReaction, RoomID: entity.GeneralRoomID, MessageID: 5,
+		UserID: 7, Username: "alice", Emoji: "👍", Added: true, Count: 2,
+	}
+	uc.On("ToggleReaction", entity.GeneralRoomID, 5, int64(7), "alice", "👍").Return(event, nil)
+	uc.On("ToggleReaction", entity.GeneralRoomID, 5, int64(7), "alice", "🤡").Return(nil, usecase.ErrUnknownReaction)
+	uc.On("ToggleReaction", entity.GeneralRoomID, 6, int64(7), "alice", "👍").Return(nil, repository.ErrMessageNotFound)
+	h := newTestHandler(t, uc)
+	url := newTestServer(t, h)
+
+	reactor := dialChat(t, url)
+	watcher := dialChat(t, url)
+	require.Eventually(t, func() bool { return h.hub.ClientCount() == 2 }, time.Second, 10*time.Millisecond)
+
+	// Событие получают все подписчики комнаты, включая автора реакции.
+	require.NoError(t, reactor.WriteJSON(entity.ClientFrame{Type: entity.FrameReact, MessageID: 5, Emoji: "👍"}))
+	for _, ws := range []*websocket.Conn{watcher, reactor} {
+		var frame entity.ReactionFrame
+		readFrame(t, ws, &frame)
+		assert.Equal(t, *event, frame)
+	}
+
+	tests := []struct {
+		name  string
+		frame entity.ClientFrame
+		want  entity.ServerFrame
+	}{
+		{
+			name:  "Unknown reaction",
+			frame: entity.ClientFrame{Type: entity.FrameReact, MessageID: 5, Emoji: "🤡"},
+			want:  entity.ServerFrame{Type: entity.FrameError, RoomID: entity.GeneralRoomID, Error: usecase.ErrUnknownReaction.Error()},
+		},
+		{
+			name:  "Unknown message",
+			frame: entity.ClientFrame{Type: entity.FrameReact, MessageID: 6, Emoji: "👍"},
+			want:  entity.ServerFrame{Type: entity.FrameError, RoomID: entity.GeneralRoomID, Error: repository.ErrMessageNotFound.Error()},
+		},
+		{
+			name:  "Room without join",
+			frame: entity.ClientFrame{Type: entity.FrameReact, RoomID: 3, MessageID: 5, Emoji: "👍"},
+			want:  entity.ServerFrame{Type: entity.FrameError, RoomID: 3, Error: "join the room first"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, reactor.WriteJSON(tt.frame))
+			var frame entity.ServerFrame
+			readFrame(t, reactor, &frame)
+			assert.Equal(t, tt.want, frame)
+		})
+	}
+	uc.AssertNumberOfCalls(t, "ToggleReaction", 3)
+}
+
+func TestMessageHandler_ListReactions(t *testing.T) {
+	uc := new(MockMessageUseCase)
+	uc.On("Reactions").Return([]string{"👍", "🎉"})
+	router := gin.New()
+	router.GET("/reactions", newTestHandler(t, uc).ListReactions)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reactions", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"reactions":["👍","🎉"]}`, w.Body.String())
+}
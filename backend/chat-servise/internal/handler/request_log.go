@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger — журнал запросов как у gin.Logger, но без токена:
+// браузерный WebSocket передает его в параметре token, и в лог он
+// попадать не должен.
+func RequestLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(p gin.LogFormatterParams) string {
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+			p.TimeStamp.Format(time.RFC3339),
+			p.StatusCode,
+			p.Latency,
+			p.ClientIP,
+			p.Method,
+			redactToken(p.Path),
+			p.ErrorMessage,
+		)
+	})
+}
+
+// redactToken заменяет значение параметра token в пути с запросом.
+func redactToken(path string) string {
+	i := strings.IndexByte(path, '?')
+	if i < 0 {
+		return path
+	}
+	query, err := url.ParseQuery(path[i+1:])
+	if err != nil {
+		// Разобрать не удалось — запрос целиком не пишем.
+		return path[:i]
+	}
+	if !query.Has("token") {
+		return path
+	}
+	query.Set("token", "REDACTED")
+	return path[:i] + "?" + query.Encode()
+}
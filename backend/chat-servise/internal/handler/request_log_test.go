@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactToken(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/ws", "/ws"},
+		{"/messages?before=5", "/messages?before=5"},
+		{"/ws?token=secret", "/ws?token=REDACTED"},
+		{"/ws?room=2&token=secret", "/ws?room=2&token=REDACTED"},
+		{"/ws?token=%zz", "/ws"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, redactToken(tt.path), tt.path)
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	defer func(w io.Writer) { gin.DefaultWriter = w }(gin.DefaultWriter)
+	gin.DefaultWriter = &out
+
+	router := gin.New()
+	router.Use(RequestLogger())
+	router.GET("/ws", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ws?token=secret", nil))
+
+	assert.Contains(t, out.String(), `"/ws?token=REDACTED"`)
+	assert.NotContains(t, out.String(), "secret")
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// RoomHandler — HTTP API комнат чата. Все методы требуют authmw.Required.
+type RoomHandler struct {
+	rooms    usecase.RoomUseCase
+	messages usecase.MessageUseCase
+}
+
+func NewRoomHandler(rooms usecase.RoomUseCase, messages usecase.MessageUseCase) *RoomHandler {
+	return &RoomHandler{rooms: rooms, messages: messages}
+}
+
+// ListRooms возвращает комнаты, доступные пользователю.
+//
+// @Summary Список комнат
+// @Description Публичные комнаты и закрытые, в которых состоит пользователь
+// @Tags rooms
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} entity.Room
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /rooms [get]
+func (h *RoomHandler) ListRooms(c *gin.Context) {
+	user, ok := authmw.Current(c)
+	if !ok {
+		authmw.Abort(c, authmw.ErrMissingToken)
+		return
+	}
+
+	rooms, err := h.rooms.ListRooms(user.UserID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rooms)
+}
+
+// CreateRoom создаёт комнату; создатель становится её владельцем.
+//
+// @Summary Создать комнату
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param input body entity.CreateRoomRequest true "Комната"
+// @Success 201 {object} entity.Room
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /rooms [post]
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	user, ok := authmw.Current(c)
+	if !ok {
+		authmw.Abort(c, authmw.ErrMissingToken)
+		return
+	}
+
+	var req entity.CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	room, err := h.rooms.CreateRoom(user.UserID, req.Name, req.IsPrivate)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, room)
+}
+
+// InviteMember приглашает пользователя в комнату.
+//
+// @Summary Пригласить в комнату
+// @Description Приглашать может только владелец комнаты
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID комнаты"
+// @Param input body entity.InviteRequest true "Приглашаемый"
+// @Success 204
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /rooms/{id}/members [post]
+func (h *RoomHandler) InviteMember(c *gin.Context) {
+	user, ok := authmw.Current(c)
+	if !ok {
+		authmw.Abort(c, authmw.ErrMissingToken)
+		return
+	}
+
+	roomID, ok := roomIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req entity.InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.rooms.Invite(roomID, user.UserID, req.UserID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetRetention задаёт срок хранения сообщений комнаты.
+//
+// @Summary Срок хранения сообщений
+// @Description mode: forever — хранить всегда, days — value дней, count — последние value сообщений. С archive устаревшие сообщения переносятся в архив. Менять может владелец комнаты или администратор.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID комнаты"
+// @Param input body entity.RetentionPolicy true "Политика хранения"
+// @Success 200 {object} entity.Room
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /rooms/{id}/retention [put]
+func (h *RoomHandler) SetRetention(c *gin.Context) {
+	user, ok := authmw.Current(c)
+	if !ok {
+		authmw.Abort(c, authmw.ErrMissingToken)
+		return
+	}
+
+	roomID, ok := roomIDParam(c)
+	if !ok {
+		return
+	}
+
+	var policy entity.RetentionPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	room, err := h.rooms.SetRetention(roomID, user, policy)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+// GetRoomMessages возвращает страницу истории комнаты. Без курсора —
+// последние сообщения; before листает назад, after — вперёд.
+//
+// @Summary История комнаты
+// @Tags rooms
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID комнаты"
+// @Param before query string false "Курсор: сообщения старше"
+// @Param after query string false "Курсор: сообщения новее"
+// @Param limit query int false "Размер страницы (по умолчанию 50, не больше 100)"
+// @Success 200 {object} entity.HistoryPage
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /rooms/{id}/messages [get]
+func (h *RoomHandler) GetRoomMessages(c *gin.Context) {
+	user, ok := authmw.Current(c)
+	if !ok {
+		authmw.Abort(c, authmw.ErrMissingToken)
+		return
+	}
+
+	roomID, ok := roomIDParam(c)
+	if !ok {
+		return
+	}
+
+	query, err := parseHistoryQuery(roomID, c.Query("before"), c.Query("after"), c.Query("limit"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	query.ViewerID = user.UserID
+
+	if err := h.rooms.CanAccess(roomID, user.UserID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	page, err := h.messages.GetMessages(query)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetTopicRoom возвращает комнату обсуждения темы форума, создавая её при
+// первом обращении.
+//
+// @Summary Комната темы форума
+// @Tags rooms
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID темы"
+// @Success 200 {object} entity.Room
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 501 {object} entity.ErrorResponse
+// @Router /topics/{id}/room [get]
+func (h *RoomHandler) GetTopicRoom(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || topicID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	room, err := h.rooms.TopicRoom(topicID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+func roomIDParam(c *gin.Context) (int64, bool) {
+	roomID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || roomID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+		return 0, false
+	}
+	return roomID, true
+}
+
+func (h *RoomHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRoomName), errors.Is(err, usecase.ErrInvalidRetention), isHistoryQueryError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrRoomAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrRoomNotFound), errors.Is(err, usecase.ErrTopicNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrTopicRoomsDisabled):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		log.Printf("room request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// roomErrorText — текст ошибки для кадра WebSocket; внутренние ошибки
+// клиенту не показываются.
+func roomErrorText(err error) string {
+	switch {
+	case errors.Is(err, usecase.ErrRoomAccessDenied), errors.Is(err, repository.ErrRoomNotFound):
+		return err.Error()
+	default:
+		log.Printf("room request failed: %v", err)
+		return "internal server error"
+	}
+}
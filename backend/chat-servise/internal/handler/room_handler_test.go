@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/usecase"
+
+	"backend.com/forum/authmw"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newRoomRouter(rooms *MockRoomUseCase, messages *MockMessageUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewRoomHandler(rooms, messages)
+
+	router := gin.New()
+	group := router.Group("/", authmw.NewAuthenticator(nil, &fakeAuthClient{}).Required())
+	group.GET("/rooms", h.ListRooms)
+	group.POST("/rooms", h.CreateRoom)
+	group.POST("/rooms/:id/members", h.InviteMember)
+	group.GET("/rooms/:id/messages", h.GetRoomMessages)
+	group.PUT("/rooms/:id/retention", h.SetRetention)
+	group.GET("/topics/:id/room", h.GetTopicRoom)
+	return router
+}
+
+// alice — пользователь, которого fakeAuthClient узнаёт по valid_token.
+var alice = &authmw.Principal{UserID: 7, Username: "alice", Role: "user"}
+
+func TestRoomHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		anonymous  bool
+		setup      func(rooms *MockRoomUseCase, messages *MockMessageUseCase)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Unauthorized",
+			method:     http.MethodGet,
+			path:       "/rooms",
+			anonymous:  true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "List rooms",
+			method: http.MethodGet,
+			path:   "/rooms",
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("ListRooms", int64(7)).Return([]entity.Room{{ID: 1, Name: "general"}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "Create room",
+			method: http.MethodPost,
+			path:   "/rooms",
+			body:   `{"name":"golang","is_private":true}`,
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("CreateRoom", int64(7), "golang", true).
+					Return(&entity.Room{ID: 2, Name: "golang", IsPrivate: true, CreatedBy: 7}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "Create room without name",
+			method:     http.MethodPost,
+			path:       "/rooms",
+			body:       `{"is_private":true}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Invite by non-owner",
+			method: http.MethodPost,
+			path:   "/rooms/2/members",
+			body:   `{"user_id":8}`,
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("Invite", int64(2), int64(7), int64(8)).Return(usecase.ErrRoomAccessDenied)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Invite",
+			method: http.MethodPost,
+			path:   "/rooms/2/members",
+			body:   `{"user_id":8}`,
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("Invite", int64(2), int64(7), int64(8)).Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Set retention",
+			method: http.MethodPut,
+			path:   "/rooms/2/retention",
+			body:   `{"mode":"days","value":30,"archive":true}`,
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				policy := entity.RetentionPolicy{Mode: entity.RetentionDays, Value: 30, Archive: true}
+				rooms.On("SetRetention", int64(2), alice, policy).
+					Return(&entity.Room{ID: 2, Name: "golang", Retention: policy}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "Set retention by non-owner",
+			method: http.MethodPut,
+			path:   "/rooms/2/retention",
+			body:   `{"mode":"forever"}`,
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("SetRetention", int64(2), alice, entity.RetentionPolicy{Mode: entity.RetentionForever}).
+					Return(nil, usecase.ErrRoomAccessDenied)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Set invalid retention",
+			method: http.MethodPut,
+			path:   "/rooms/2/retention",
+			body:   `{"mode":"weeks","value":2}`,
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("SetRetention", int64(2), alice, entity.RetentionPolicy{Mode: "weeks", Value: 2}).
+					Return(nil, usecase.ErrInvalidRetention)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Set retention without mode",
+			method:     http.MethodPut,
+			path:       "/rooms/2/retention",
+			body:       `{"value":2}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Messages with invalid room ID",
+			method:     http.MethodGet,
+			path:       "/rooms/abc/messages",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Messages of private room",
+			method: http.MethodGet,
+			path:   "/rooms/2/messages",
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("CanAccess", int64(2), int64(7)).Return(usecase.ErrRoomAccessDenied)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Messages of missing room",
+			method: http.MethodGet,
+			path:   "/rooms/9/messages",
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("CanAccess", int64(9), int64(7)).Return(repository.ErrRoomNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Messages of room",
+			method: http.MethodGet,
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("CanAccess", int64(2), int64(7)).Return(nil)
+				messages.On("GetMessages", entity.HistoryQuery{RoomID: 2, Limit: 1, ViewerID: 7}).
+					Return(&entity.HistoryPage{
+						Messages: []entity.Message{{
+							ID: 1, RoomID: 2, UserID: 7, Username: "alice", Message: "hi", Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+							Reactions: []entity.Reaction{{Emoji: "👍", Count: 2, Mine: true}},
+						}},
+						HasMore: true,
+						Before:  "b",
+						After:   "b",
+					}, nil)
+			},
+			path:       "/rooms/2/messages?limit=1",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"messages":[{"id":1,"room_id":2,"user_id":7,"username":"alice","message":"hi","timestamp":"2024-01-01T12:00:00Z","reactions":[{"emoji":"👍","count":2,"mine":true}]}],"has_more":true,"before":"b","after":"b"}`,
+		},
+		{
+			name:       "Messages with invalid cursor",
+			method:     http.MethodGet,
+			path:       "/rooms/2/messages?after=garbage!",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Messages with both cursors",
+			method: http.MethodGet,
+			path:   "/rooms/2/messages",
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("CanAccess", int64(2), int64(7)).Return(nil)
+				messages.On("GetMessages", mock.Anything).Return(nil, usecase.ErrConflictingCursors)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Messages with database error",
+			method: http.MethodGet,
+			path:   "/rooms/2/messages",
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("CanAccess", int64(2), int64(7)).Return(errors.New("database error"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"Internal server error"}`,
+		},
+		{
+			name:   "Room of missing topic",
+			method: http.MethodGet,
+			path:   "/topics/5/room",
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("TopicRoom", int64(5)).Return(nil, usecase.ErrTopicNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Topic rooms disabled",
+			method: http.MethodGet,
+			path:   "/topics/5/room",
+			setup: func(rooms *MockRoomUseCase, messages *MockMessageUseCase) {
+				rooms.On("TopicRoom", int64(5)).Return(nil, usecase.ErrTopicRoomsDisabled)
+			},
+			wantStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := new(MockRoomUseCase)
+			messages := new(MockMessageUseCase)
+			if tt.setup != nil {
+				tt.setup(rooms, messages)
+			}
+			router := newRoomRouter(rooms, messages)
+
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if !tt.anonymous {
+				req.Header.Set("Authorization", "Bearer valid_token")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+			rooms.AssertExpectations(t)
+			messages.AssertExpectations(t)
+		})
+	}
+}
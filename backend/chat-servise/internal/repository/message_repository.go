@@ -2,16 +2,46 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 
+	"backend.com/forum/notify"
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/lib/pq"
 )
 
+var ErrMessageNotFound = errors.New("message not found")
+
 type MessageRepository interface {
-	SaveMessage(msg entity.Message) error
-	GetMessages() ([]entity.Message, error)
+	// SaveMessage сохраняет сообщение и заполняет его ID и Timestamp.
+	SaveMessage(msg *entity.Message) error
+	// GetMessages возвращает до query.Limit сообщений комнаты по порядку от
+	// старых к новым: ближайшие к курсору или последние, если курсора нет.
+	GetMessages(query entity.HistoryQuery) ([]entity.Message, error)
+	// NthNewest возвращает курсор n-го с конца сообщения комнаты или nil,
+	// если сообщений меньше n.
+	NthNewest(roomID int64, n int) (*entity.Cursor, error)
+	// ExpireMessages удаляет до limit самых старых сообщений комнаты раньше
+	// before, с archive — переносит их в chat_messages_archive. Возвращает
+	// число обработанных сообщений.
+	ExpireMessages(roomID int64, before entity.Cursor, limit int, archive bool) (int64, error)
+	// ToggleReaction снимает реакцию emoji пользователя на сообщение комнаты,
+	// если она уже стоит, и ставит её в противном случае. Возвращает, стоит
+	// ли реакция теперь, и сколько реакций emoji на сообщении после этого.
+	ToggleReaction(roomID int64, messageID int, userID int64, emoji string) (bool, int, error)
+	// GetReactions возвращает реакции на сообщения messageIDs с отметкой
+	// реакций viewerID. Сообщений без реакций в ответе нет.
+	GetReactions(viewerID int64, messageIDs []int) (map[int][]entity.Reaction, error)
+	// SaveMentions сохраняет упоминания mentions в сообщении msg и создает
+	// упомянутым уведомления. Пользователи, которые не видят закрытую
+	// комнату, пропускаются. Возвращает сохраненные упоминания.
+	SaveMentions(msg *entity.Message, mentions []entity.Mention) ([]entity.Mention, error)
+	// GetMentions возвращает упоминания в сообщениях messageIDs. Сообщений
+	// без упоминаний в ответе нет.
+	GetMentions(messageIDs []int) (map[int][]entity.Mention, error)
 }
 
 type messageRepository struct {
@@ -22,9 +52,9 @@ func NewMessageRepository(db *sql.DB) MessageRepository {
 	return &messageRepository{db: db}
 }
 
-func (repo *messageRepository) SaveMessage(msg entity.Message) error {
-	query := `INSERT INTO chat_messages (user_id, username, content) VALUES ($1, $2, $3)`
-	_, err := repo.db.Exec(query, 32, msg.Username, msg.Message)
+func (repo *messageRepository) SaveMessage(msg *entity.Message) error {
+	query := `INSERT INTO chat_messages (room_id, user_id, username, content) VALUES ($1, $2, $3, $4) RETURNING id, timestamp`
+	err := repo.db.QueryRow(query, msg.RoomID, msg.UserID, msg.Username, msg.Message).Scan(&msg.ID, &msg.Timestamp)
 	if err != nil {
 		log.Printf("Error saving message: %v", err)
 		return err
@@ -32,26 +62,272 @@ func (repo *messageRepository) SaveMessage(msg entity.Message) error {
 	return nil
 }
 
-func (repo *messageRepository) GetMessages() ([]entity.Message, error) {
-	rows, err := repo.db.Query("SELECT id, username, content FROM chat_messages")
+const messageColumns = `id, room_id, user_id, username, content, timestamp`
+
+func (repo *messageRepository) GetMessages(query entity.HistoryQuery) ([]entity.Message, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	// Страница выбирается по индексу (room_id, timestamp, id). Для before и
+	// последних сообщений строки идут от новых к старым и разворачиваются ниже.
+	switch {
+	case query.After != nil:
+		rows, err = repo.db.Query(`SELECT `+messageColumns+` FROM chat_messages
+			WHERE room_id = $1 AND (timestamp, id) > ($2, $3)
+			ORDER BY timestamp, id LIMIT $4`,
+			query.RoomID, query.After.Timestamp, query.After.ID, query.Limit)
+	case query.Before != nil:
+		rows, err = repo.db.Query(`SELECT `+messageColumns+` FROM chat_messages
+			WHERE room_id = $1 AND (timestamp, id) < ($2, $3)
+			ORDER BY timestamp DESC, id DESC LIMIT $4`,
+			query.RoomID, query.Before.Timestamp, query.Before.ID, query.Limit)
+	default:
+		rows, err = repo.db.Query(`SELECT `+messageColumns+` FROM chat_messages
+			WHERE room_id = $1
+			ORDER BY timestamp DESC, id DESC LIMIT $2`,
+			query.RoomID, query.Limit)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("query error: %w", err)
 	}
 	defer rows.Close()
 
-	var messages []entity.Message
+	messages := []entity.Message{}
 	for rows.Next() {
 		var msg entity.Message
-		// Исправленный маппинг столбцов
-		err := rows.Scan(&msg.ID, &msg.Username, &msg.Message)
+		err := rows.Scan(&msg.ID, &msg.RoomID, &msg.UserID, &msg.Username, &msg.Message, &msg.Timestamp)
 		if err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
 		messages = append(messages, msg)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	if query.After == nil {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
 	return messages, nil
 }
 
+func (repo *messageRepository) NthNewest(roomID int64, n int) (*entity.Cursor, error) {
+	var cursor entity.Cursor
+	err := repo.db.QueryRow(`SELECT timestamp, id FROM chat_messages
+		WHERE room_id = $1
+		ORDER BY timestamp DESC, id DESC OFFSET $2 LIMIT 1`,
+		roomID, n-1,
+	).Scan(&cursor.Timestamp, &cursor.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return &cursor, nil
+}
+
+// expiredMessages выбирает пачку устаревших сообщений по индексу
+// (room_id, timestamp, id), чтобы не блокировать таблицу надолго.
+const expiredMessages = `SELECT id FROM chat_messages
+	WHERE room_id = $1 AND (timestamp, id) < ($2, $3)
+	ORDER BY timestamp, id LIMIT $4`
+
+func (repo *messageRepository) ExpireMessages(roomID int64, before entity.Cursor, limit int, archive bool) (int64, error) {
+	query := `DELETE FROM chat_messages WHERE id IN (` + expiredMessages + `)`
+	if archive {
+		query = `WITH moved AS (
+			DELETE FROM chat_messages WHERE id IN (` + expiredMessages + `)
+			RETURNING ` + messageColumns + `
+		)
+		INSERT INTO chat_messages_archive (` + messageColumns + `)
+		SELECT ` + messageColumns + ` FROM moved`
+	}
+
+	result, err := repo.db.Exec(query, roomID, before.Timestamp, before.ID, limit)
+	if err != nil {
+		return 0, fmt.Errorf("expire messages error: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ToggleReaction — один запрос, поэтому повторное нажатие не оставит двух
+// реакций. Снимок запроса не видит собственных изменений, так что итоговое
+// число считается из прежнего и изменений.
+func (repo *messageRepository) ToggleReaction(roomID int64, messageID int, userID int64, emoji string) (bool, int, error) {
+	var (
+		found, added bool
+		count        int
+	)
+	err := repo.db.QueryRow(`
+		WITH msg AS (
+			SELECT id FROM chat_messages WHERE id = $1 AND room_id = $2
+		), removed AS (
+			DELETE FROM chat_message_reactions
+			WHERE message_id IN (SELECT id FROM msg) AND user_id = $3 AND emoji = $4
+			RETURNING 1
+		), added AS (
+			INSERT INTO chat_message_reactions (message_id, user_id, emoji)
+			SELECT id, $3, $4 FROM msg WHERE NOT EXISTS (SELECT 1 FROM removed)
+			ON CONFLICT DO NOTHING
+			RETURNING 1
+		)
+		SELECT EXISTS (SELECT 1 FROM msg), EXISTS (SELECT 1 FROM added),
+			(SELECT COUNT(*) FROM chat_message_reactions WHERE message_id = $1 AND emoji = $4)
+			+ (SELECT COUNT(*) FROM added) - (SELECT COUNT(*) FROM removed)`,
+		messageID, roomID, userID, emoji,
+	).Scan(&found, &added, &count)
+	if err != nil {
+		return false, 0, fmt.Errorf("toggle reaction error: %w", err)
+	}
+	if !found {
+		return false, 0, ErrMessageNotFound
+	}
+	return added, count, nil
+}
+
+// GetReactions отдаёт эмодзи в порядке первой реакции.
+func (repo *messageRepository) GetReactions(viewerID int64, messageIDs []int) (map[int][]entity.Reaction, error) {
+	reactions := make(map[int][]entity.Reaction)
+	if len(messageIDs) == 0 {
+		return reactions, nil
+	}
+
+	rows, err := repo.db.Query(`SELECT message_id, emoji, COUNT(*), BOOL_OR(user_id = $2)
+		FROM chat_message_reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji
+		ORDER BY message_id, MIN(created_at), emoji`,
+		pq.Array(messageIDs), viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			messageID int
+			reaction  entity.Reaction
+		)
+		if err := rows.Scan(&messageID, &reaction.Emoji, &reaction.Count, &reaction.Mine); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		reactions[messageID] = append(reactions[messageID], reaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return reactions, nil
+}
+
+// SaveMentions сохраняет упоминания и в той же транзакции создает
+// уведомления о них через notify.Create, общий с форумом путь: он же
+// пропускает тех, кто отключил этот тип уведомлений.
+func (repo *messageRepository) SaveMentions(msg *entity.Message, mentions []entity.Mention) ([]entity.Mention, error) {
+	saved := []entity.Mention{}
+	if len(mentions) == 0 {
+		return saved, nil
+	}
+
+	userIDs := make([]int64, len(mentions))
+	usernames := make([]string, len(mentions))
+	for i, mention := range mentions {
+		userIDs[i] = mention.UserID
+		usernames[i] = mention.Username
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin error: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		INSERT INTO mentions (user_id, username, author_id, room_id, message_id)
+		SELECT m.user_id, m.username, $3, r.id, $5
+		FROM unnest($1::int[], $2::text[]) AS m(user_id, username)
+		JOIN chat_rooms r ON r.id = $4
+		WHERE NOT r.is_private OR EXISTS (
+			SELECT 1 FROM chat_room_members rm WHERE rm.room_id = r.id AND rm.user_id = m.user_id
+		)
+		ON CONFLICT DO NOTHING
+		RETURNING user_id, username`,
+		pq.Array(userIDs), pq.Array(usernames), msg.UserID, msg.RoomID, msg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("save mentions error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mention entity.Mention
+		if err := rows.Scan(&mention.UserID, &mention.Username); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		saved = append(saved, mention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("save mentions error: %w", err)
+	}
+	rows.Close()
+
+	if len(saved) > 0 {
+		recipients := make([]int64, len(saved))
+		for i, mention := range saved {
+			recipients[i] = mention.UserID
+		}
+		roomID, messageID := msg.RoomID, int64(msg.ID)
+		_, err = notify.Create(context.Background(), tx, notify.Notification{
+			Type:      notify.TypeMention,
+			ActorID:   msg.UserID,
+			ActorName: msg.Username,
+			RoomID:    &roomID,
+			MessageID: &messageID,
+		}, recipients)
+		if err != nil {
+			return nil, fmt.Errorf("notify mentions error: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit error: %w", err)
+	}
+	return saved, nil
+}
+
+func (repo *messageRepository) GetMentions(messageIDs []int) (map[int][]entity.Mention, error) {
+	mentions := make(map[int][]entity.Mention)
+	if len(messageIDs) == 0 {
+		return mentions, nil
+	}
+
+	rows, err := repo.db.Query(`SELECT message_id, user_id, username FROM mentions
+		WHERE message_id = ANY($1)
+		ORDER BY message_id, id`,
+		pq.Array(messageIDs))
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			messageID int
+			mention   entity.Mention
+		)
+		if err := rows.Scan(&messageID, &mention.UserID, &mention.Username); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		mentions[messageID] = append(mentions[messageID], mention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return mentions, nil
+}
+
 // // internal/repository/message_repository.go
 // package repository
 
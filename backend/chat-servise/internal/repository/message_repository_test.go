@@ -2,12 +2,16 @@
 package repository
 
 import (
+	"database/sql/driver"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
 
+	"backend.com/forum/notify"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,35 +23,49 @@ func TestSaveMessage(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMessageRepository(db)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name    string
 		msg     entity.Message
 		mock    func()
+		want    entity.Message
 		wantErr bool
 	}{
 		{
 			name: "successful message save",
 			msg: entity.Message{
+				RoomID:   1,
+				UserID:   7,
 				Username: "testuser",
 				Message:  "Hello world",
 			},
 			mock: func() {
-				mock.ExpectExec("INSERT INTO chat_messages").
-					WithArgs("testuser", "Hello world").
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO chat_messages (.+) RETURNING id, timestamp").
+					WithArgs(int64(1), int64(7), "testuser", "Hello world").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "timestamp"}).AddRow(5, now))
+			},
+			want: entity.Message{
+				ID:        5,
+				RoomID:    1,
+				UserID:    7,
+				Username:  "testuser",
+				Message:   "Hello world",
+				Timestamp: now,
 			},
 			wantErr: false,
 		},
 		{
 			name: "database error on save",
 			msg: entity.Message{
+				RoomID:   1,
+				UserID:   7,
 				Username: "testuser",
 				Message:  "Hello world",
 			},
 			mock: func() {
-				mock.ExpectExec("INSERT INTO chat_messages").
-					WithArgs("testuser", "Hello world").
+				mock.ExpectQuery("INSERT INTO chat_messages").
+					WithArgs(int64(1), int64(7), "testuser", "Hello world").
 					WillReturnError(errors.New("database error"))
 			},
 			wantErr: true,
@@ -55,14 +73,17 @@ func TestSaveMessage(t *testing.T) {
 		{
 			name: "empty username",
 			msg: entity.Message{
+				RoomID:   1,
+				UserID:   7,
 				Username: "",
 				Message:  "test",
 			},
 			mock: func() {
-				mock.ExpectExec("INSERT INTO chat_messages").
-					WithArgs("", "test").
-					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectQuery("INSERT INTO chat_messages").
+					WithArgs(int64(1), int64(7), "", "test").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "timestamp"}).AddRow(6, now))
 			},
+			want:    entity.Message{ID: 6, RoomID: 1, UserID: 7, Message: "test", Timestamp: now},
 			wantErr: false,
 		},
 	}
@@ -70,8 +91,12 @@ func TestSaveMessage(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
-			err := repo.SaveMessage(tt.msg)
+			msg := tt.msg
+			err := repo.SaveMessage(&msg)
 			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, msg)
+			}
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
@@ -85,44 +110,88 @@ func TestGetMessages(t *testing.T) {
 	defer db.Close()
 
 	repo := NewMessageRepository(db)
+	columns := []string{"id", "room_id", "user_id", "username", "content", "timestamp"}
+	t1 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	cursor := &entity.Cursor{Timestamp: t1, ID: 10}
 
 	tests := []struct {
 		name    string
+		query   entity.HistoryQuery
 		mock    func()
 		want    []entity.Message
 		wantErr bool
 	}{
 		{
-			name: "successful get messages",
+			name:  "latest messages",
+			query: entity.HistoryQuery{RoomID: 2, Limit: 2},
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "username", "content"}).
-					AddRow(1, "user1", "message 1").
-					AddRow(2, "user2", "message 2")
-				mock.ExpectQuery("SELECT id, username, content FROM chat_messages").
+				rows := sqlmock.NewRows(columns).
+					AddRow(2, 2, 12, "user2", "message 2", t2).
+					AddRow(1, 2, 11, "user1", "message 1", t1)
+				mock.ExpectQuery("SELECT (.+) FROM chat_messages WHERE room_id = \\$1 ORDER BY timestamp DESC, id DESC LIMIT \\$2").
+					WithArgs(int64(2), 2).
 					WillReturnRows(rows)
 			},
 			want: []entity.Message{
-				{ID: 1, Username: "user1", Message: "message 1"},
-				{ID: 2, Username: "user2", Message: "message 2"},
+				{ID: 1, RoomID: 2, UserID: 11, Username: "user1", Message: "message 1", Timestamp: t1},
+				{ID: 2, RoomID: 2, UserID: 12, Username: "user2", Message: "message 2", Timestamp: t2},
 			},
 			wantErr: false,
 		},
 		{
-			name: "empty result",
+			name:  "before cursor",
+			query: entity.HistoryQuery{RoomID: 2, Before: cursor, Limit: 2},
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "username", "content"})
-				mock.ExpectQuery("SELECT id, username, content FROM chat_messages").
+				rows := sqlmock.NewRows(columns).
+					AddRow(9, 2, 11, "user1", "message 9", t1).
+					AddRow(8, 2, 11, "user1", "message 8", t1)
+				mock.ExpectQuery("WHERE room_id = \\$1 AND \\(timestamp, id\\) < \\(\\$2, \\$3\\) ORDER BY timestamp DESC, id DESC LIMIT \\$4").
+					WithArgs(int64(2), t1, 10, 2).
 					WillReturnRows(rows)
 			},
+			want: []entity.Message{
+				{ID: 8, RoomID: 2, UserID: 11, Username: "user1", Message: "message 8", Timestamp: t1},
+				{ID: 9, RoomID: 2, UserID: 11, Username: "user1", Message: "message 9", Timestamp: t1},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "after cursor",
+			query: entity.HistoryQuery{RoomID: 2, After: cursor, Limit: 2},
+			mock: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(11, 2, 11, "user1", "message 11", t1).
+					AddRow(12, 2, 12, "user2", "message 12", t2)
+				mock.ExpectQuery("WHERE room_id = \\$1 AND \\(timestamp, id\\) > \\(\\$2, \\$3\\) ORDER BY timestamp, id LIMIT \\$4").
+					WithArgs(int64(2), t1, 10, 2).
+					WillReturnRows(rows)
+			},
+			want: []entity.Message{
+				{ID: 11, RoomID: 2, UserID: 11, Username: "user1", Message: "message 11", Timestamp: t1},
+				{ID: 12, RoomID: 2, UserID: 12, Username: "user2", Message: "message 12", Timestamp: t2},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "empty result",
+			query: entity.HistoryQuery{RoomID: 2, Limit: 50},
+			mock: func() {
+				mock.ExpectQuery("SELECT (.+) FROM chat_messages").
+					WithArgs(int64(2), 50).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
 			want:    []entity.Message{},
 			wantErr: false,
 		},
 		{
-			name: "scan error",
+			name:  "scan error",
+			query: entity.HistoryQuery{RoomID: 2, Limit: 50},
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id", "username"}).
 					AddRow(1, "user1")
-				mock.ExpectQuery("SELECT id, username, content FROM chat_messages").
+				mock.ExpectQuery("SELECT (.+) FROM chat_messages").
+					WithArgs(int64(2), 50).
 					WillReturnRows(rows)
 			},
 			want:    nil,
@@ -133,7 +202,7 @@ func TestGetMessages(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
-			messages, err := repo.GetMessages()
+			messages, err := repo.GetMessages(tt.query)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, messages)
 			assert.NoError(t, mock.ExpectationsWereMet())
@@ -141,6 +210,91 @@ func TestGetMessages(t *testing.T) {
 	}
 }
 
+func TestNthNewest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	query := "SELECT timestamp, id FROM chat_messages WHERE room_id = \\$1 ORDER BY timestamp DESC, id DESC OFFSET \\$2 LIMIT 1"
+	ts := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(query).
+		WithArgs(int64(2), 99).
+		WillReturnRows(sqlmock.NewRows([]string{"timestamp", "id"}).AddRow(ts, 42))
+	cursor, err := repo.NthNewest(2, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.Cursor{Timestamp: ts, ID: 42}, cursor)
+
+	mock.ExpectQuery(query).
+		WithArgs(int64(3), 99).
+		WillReturnRows(sqlmock.NewRows([]string{"timestamp", "id"}))
+	cursor, err = repo.NthNewest(3, 100)
+	assert.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExpireMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	before := entity.Cursor{Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), ID: 42}
+
+	tests := []struct {
+		name    string
+		archive bool
+		query   string
+		result  driver.Result
+		err     error
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:   "delete",
+			query:  "^DELETE FROM chat_messages WHERE id IN \\(SELECT id FROM chat_messages WHERE room_id = \\$1 AND \\(timestamp, id\\) < \\(\\$2, \\$3\\) ORDER BY timestamp, id LIMIT \\$4\\)$",
+			result: sqlmock.NewResult(0, 1000),
+			want:   1000,
+		},
+		{
+			name:    "archive",
+			archive: true,
+			query:   "WITH moved AS \\( DELETE FROM chat_messages (.+) RETURNING (.+) \\) INSERT INTO chat_messages_archive",
+			result:  sqlmock.NewResult(0, 7),
+			want:    7,
+		},
+		{
+			name:    "database error",
+			query:   "DELETE FROM chat_messages",
+			err:     errors.New("database error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := mock.ExpectExec(tt.query).WithArgs(int64(2), before.Timestamp, 42, 1000)
+			if tt.err != nil {
+				exp.WillReturnError(tt.err)
+			} else {
+				exp.WillReturnResult(tt.result)
+			}
+
+			n, err := repo.ExpireMessages(2, before, 1000, tt.archive)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, n)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 // // internal/repository/message_repository_test.go
 // package repository
 
@@ -311,3 +465,115 @@ func TestGetMessages(t *testing.T) {
 // 		})
 // 	}
 // }
+
+func TestToggleReaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	query := `SELECT id FROM chat_messages WHERE id = \$1 AND room_id = \$2.+DELETE FROM chat_message_reactions.+INSERT INTO chat_message_reactions \(message_id, user_id, emoji\)`
+
+	mock.ExpectQuery(query).
+		WithArgs(5, int64(2), int64(7), "👍").
+		WillReturnRows(sqlmock.NewRows([]string{"found", "added", "count"}).AddRow(true, true, 3))
+	added, count, err := repo.ToggleReaction(2, 5, 7, "👍")
+	assert.NoError(t, err)
+	assert.True(t, added)
+	assert.Equal(t, 3, count)
+
+	// Сообщение из другой комнаты не найдено.
+	mock.ExpectQuery(query).
+		WithArgs(5, int64(3), int64(7), "👍").
+		WillReturnRows(sqlmock.NewRows([]string{"found", "added", "count"}).AddRow(false, false, 0))
+	_, _, err = repo.ToggleReaction(3, 5, 7, "👍")
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	mock.ExpectQuery(`SELECT message_id, emoji, COUNT\(\*\), BOOL_OR\(user_id = \$2\)\s+FROM chat_message_reactions\s+WHERE message_id = ANY\(\$1\)`).
+		WithArgs(sqlmock.AnyArg(), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "emoji", "count", "mine"}).
+			AddRow(1, "👍", 2, true).
+			AddRow(1, "🎉", 1, false).
+			AddRow(3, "😂", 1, false))
+
+	reactions, err := repo.GetReactions(7, []int{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int][]entity.Reaction{
+		1: {{Emoji: "👍", Count: 2, Mine: true}, {Emoji: "🎉", Count: 1}},
+		3: {{Emoji: "😂", Count: 1}},
+	}, reactions)
+
+	// Пустая страница обходится без запроса.
+	reactions, err = repo.GetReactions(7, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, reactions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveMentions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	msg := &entity.Message{ID: 10, RoomID: 2, UserID: 7, Username: "carol"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO mentions \(user_id, username, author_id, room_id, message_id\).+JOIN chat_rooms r ON r.id = \$4\s+WHERE NOT r.is_private OR EXISTS.+RETURNING user_id, username`).
+		WithArgs(pq.Array([]int64{5, 6}), pq.Array([]string{"alice", "bob"}), int64(7), int64(2), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username"}).AddRow(5, "alice"))
+	// Уведомление получает только тот, чье упоминание сохранилось.
+	mock.ExpectQuery(`INSERT INTO notifications .+ FROM unnest\(\$1::int\[\]\) AS u\(id\)\s+WHERE NOT EXISTS`).
+		WithArgs(pq.Array([]int64{5}), notify.TypeMention, int64(7), "carol", nil, nil, int64(2), int64(10), "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).AddRow(1, 5, time.Now()))
+	mock.ExpectCommit()
+
+	saved, err := repo.SaveMentions(msg, []entity.Mention{{UserID: 5, Username: "alice"}, {UserID: 6, Username: "bob"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Mention{{UserID: 5, Username: "alice"}}, saved)
+
+	saved, err = repo.SaveMentions(msg, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, saved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMentions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewMessageRepository(db)
+	mock.ExpectQuery(`SELECT message_id, user_id, username FROM mentions\s+WHERE message_id = ANY\(\$1\)`).
+		WithArgs(pq.Array([]int{1, 2})).
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "user_id", "username"}).
+			AddRow(2, 5, "alice").
+			AddRow(2, 6, "bob"))
+
+	mentions, err := repo.GetMentions([]int{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int][]entity.Mention{
+		2: {{UserID: 5, Username: "alice"}, {UserID: 6, Username: "bob"}},
+	}, mentions)
+
+	mentions, err = repo.GetMentions(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, mentions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+)
+
+var ErrRoomNotFound = errors.New("room not found")
+
+type RoomRepository interface {
+	// CreateRoom сохраняет комнату и делает создателя её владельцем.
+	CreateRoom(room *entity.Room) error
+	// CreateTopicRoom создаёт комнату темы или возвращает уже созданную.
+	CreateTopicRoom(topicID int64, name string) (*entity.Room, error)
+	GetRoom(id int64) (*entity.Room, error)
+	GetRoomByTopic(topicID int64) (*entity.Room, error)
+	// ListRooms возвращает публичные комнаты и закрытые, где userID — участник.
+	ListRooms(userID int64) ([]entity.Room, error)
+	AddMember(roomID, userID int64, role string) error
+	RemoveMember(roomID, userID int64) error
+	// GetMemberRole возвращает роль участника или "", если он не в комнате.
+	GetMemberRole(roomID, userID int64) (string, error)
+	SetRetention(roomID int64, policy entity.RetentionPolicy) error
+	// ListRoomsWithRetention возвращает комнаты, сообщения которых хранятся
+	// не вечно.
+	ListRoomsWithRetention() ([]entity.Room, error)
+}
+
+type roomRepository struct {
+	db *sql.DB
+}
+
+func NewRoomRepository(db *sql.DB) RoomRepository {
+	return &roomRepository{db: db}
+}
+
+const roomColumns = `id, name, is_private, topic_id, created_by, created_at, retention_mode, retention_value, retention_archive`
+
+func scanRoom(row interface{ Scan(...interface{}) error }) (*entity.Room, error) {
+	var (
+		room      entity.Room
+		topicID   sql.NullInt64
+		createdBy sql.NullInt64
+	)
+	err := row.Scan(&room.ID, &room.Name, &room.IsPrivate, &topicID, &createdBy, &room.CreatedAt,
+		&room.Retention.Mode, &room.Retention.Value, &room.Retention.Archive)
+	if err != nil {
+		return nil, err
+	}
+	if topicID.Valid {
+		room.TopicID = &topicID.Int64
+	}
+	room.CreatedBy = createdBy.Int64
+	return &room, nil
+}
+
+func (repo *roomRepository) CreateRoom(room *entity.Room) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin error: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO chat_rooms (name, is_private, created_by) VALUES ($1, $2, $3) RETURNING id, created_at`,
+		room.Name, room.IsPrivate, room.CreatedBy,
+	).Scan(&room.ID, &room.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert room error: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO chat_room_members (room_id, user_id, role) VALUES ($1, $2, $3)`,
+		room.ID, room.CreatedBy, entity.RoomRoleOwner,
+	)
+	if err != nil {
+		return fmt.Errorf("insert owner error: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (repo *roomRepository) CreateTopicRoom(topicID int64, name string) (*entity.Room, error) {
+	// Две вкладки могут открыть обсуждение одновременно — уникальный
+	// topic_id оставит только одну комнату.
+	_, err := repo.db.Exec(
+		`INSERT INTO chat_rooms (name, topic_id) VALUES ($1, $2) ON CONFLICT (topic_id) DO NOTHING`,
+		name, topicID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert room error: %w", err)
+	}
+	return repo.GetRoomByTopic(topicID)
+}
+
+func (repo *roomRepository) GetRoom(id int64) (*entity.Room, error) {
+	room, err := scanRoom(repo.db.QueryRow(`SELECT `+roomColumns+` FROM chat_rooms WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return room, nil
+}
+
+func (repo *roomRepository) GetRoomByTopic(topicID int64) (*entity.Room, error) {
+	room, err := scanRoom(repo.db.QueryRow(`SELECT `+roomColumns+` FROM chat_rooms WHERE topic_id = $1`, topicID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return room, nil
+}
+
+func (repo *roomRepository) ListRooms(userID int64) ([]entity.Room, error) {
+	rows, err := repo.db.Query(`
+		SELECT `+roomColumns+` FROM chat_rooms
+		WHERE NOT is_private
+		   OR id IN (SELECT room_id FROM chat_room_members WHERE user_id = $1)
+		ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return scanRooms(rows)
+}
+
+func scanRooms(rows *sql.Rows) ([]entity.Room, error) {
+	defer rows.Close()
+
+	rooms := []entity.Room{}
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		rooms = append(rooms, *room)
+	}
+	return rooms, rows.Err()
+}
+
+func (repo *roomRepository) AddMember(roomID, userID int64, role string) error {
+	_, err := repo.db.Exec(
+		`INSERT INTO chat_room_members (room_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (room_id, user_id) DO NOTHING`,
+		roomID, userID, role,
+	)
+	if err != nil {
+		return fmt.Errorf("insert member error: %w", err)
+	}
+	return nil
+}
+
+func (repo *roomRepository) RemoveMember(roomID, userID int64) error {
+	_, err := repo.db.Exec(`DELETE FROM chat_room_members WHERE room_id = $1 AND user_id = $2`, roomID, userID)
+	if err != nil {
+		return fmt.Errorf("delete member error: %w", err)
+	}
+	return nil
+}
+
+func (repo *roomRepository) GetMemberRole(roomID, userID int64) (string, error) {
+	var role string
+	err := repo.db.QueryRow(
+		`SELECT role FROM chat_room_members WHERE room_id = $1 AND user_id = $2`,
+		roomID, userID,
+	).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("query error: %w", err)
+	}
+	return role, nil
+}
+
+func (repo *roomRepository) SetRetention(roomID int64, policy entity.RetentionPolicy) error {
+	result, err := repo.db.Exec(
+		`UPDATE chat_rooms SET retention_mode = $1, retention_value = $2, retention_archive = $3 WHERE id = $4`,
+		policy.Mode, policy.Value, policy.Archive, roomID,
+	)
+	if err != nil {
+		return fmt.Errorf("update room error: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrRoomNotFound
+	}
+	return nil
+}
+
+func (repo *roomRepository) ListRoomsWithRetention() ([]entity.Room, error) {
+	rows, err := repo.db.Query(`SELECT ` + roomColumns + ` FROM chat_rooms WHERE retention_mode <> 'forever' ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	return scanRooms(rows)
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var roomRowColumns = []string{"id", "name", "is_private", "topic_id", "created_by", "created_at", "retention_mode", "retention_value", "retention_archive"}
+
+func TestCreateRoom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+	now := time.Now()
+
+	t.Run("owner becomes member", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO chat_rooms (name, is_private, created_by) VALUES ($1, $2, $3) RETURNING id, created_at`)).
+			WithArgs("golang", true, int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, now))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO chat_room_members (room_id, user_id, role) VALUES ($1, $2, $3)`)).
+			WithArgs(int64(2), int64(7), entity.RoomRoleOwner).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		room := &entity.Room{Name: "golang", IsPrivate: true, CreatedBy: 7}
+		require.NoError(t, repo.CreateRoom(room))
+		assert.Equal(t, int64(2), room.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rollback on member error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO chat_rooms").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, now))
+		mock.ExpectExec("INSERT INTO chat_room_members").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		err := repo.CreateRoom(&entity.Room{Name: "broken", CreatedBy: 7})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateTopicRoom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO chat_rooms (name, topic_id) VALUES ($1, $2) ON CONFLICT (topic_id) DO NOTHING`)).
+		WithArgs("Go generics", int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM chat_rooms WHERE topic_id = $1`)).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(roomRowColumns).AddRow(4, "Go generics", false, 5, nil, time.Now(), "forever", 0, false))
+
+	room, err := repo.CreateTopicRoom(5, "Go generics")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), room.ID)
+	require.NotNil(t, room.TopicID)
+	assert.Equal(t, int64(5), *room.TopicID)
+	assert.Zero(t, room.CreatedBy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRoom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+
+	t.Run("found", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM chat_rooms WHERE id = $1`)).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows(roomRowColumns).AddRow(1, "general", false, nil, nil, time.Now(), "forever", 0, false))
+
+		room, err := repo.GetRoom(1)
+		require.NoError(t, err)
+		assert.Equal(t, "general", room.Name)
+		assert.Nil(t, room.TopicID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM chat_rooms WHERE id = $1`)).
+			WithArgs(int64(99)).
+			WillReturnRows(sqlmock.NewRows(roomRowColumns))
+
+		_, err := repo.GetRoom(99)
+		assert.ErrorIs(t, err, ErrRoomNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListRooms(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM chat_rooms WHERE NOT is_private OR id IN").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows(roomRowColumns).
+			AddRow(1, "general", false, nil, nil, time.Now(), "forever", 0, false).
+			AddRow(2, "secret", true, nil, 7, time.Now(), "days", 30, true))
+
+	rooms, err := repo.ListRooms(7)
+
+	require.NoError(t, err)
+	require.Len(t, rooms, 2)
+	assert.True(t, rooms[1].IsPrivate)
+	assert.Equal(t, entity.RetentionPolicy{Mode: entity.RetentionDays, Value: 30, Archive: true}, rooms[1].Retention)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMemberRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+	query := regexp.QuoteMeta(`SELECT role FROM chat_room_members WHERE room_id = $1 AND user_id = $2`)
+
+	mock.ExpectQuery(query).
+		WithArgs(int64(2), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(entity.RoomRoleOwner))
+	role, err := repo.GetMemberRole(2, 7)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RoomRoleOwner, role)
+
+	mock.ExpectQuery(query).
+		WithArgs(int64(2), int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+	role, err = repo.GetMemberRole(2, 8)
+	require.NoError(t, err)
+	assert.Empty(t, role)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetRetention(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+	policy := entity.RetentionPolicy{Mode: entity.RetentionCount, Value: 500, Archive: true}
+	query := regexp.QuoteMeta(`UPDATE chat_rooms SET retention_mode = $1, retention_value = $2, retention_archive = $3 WHERE id = $4`)
+
+	mock.ExpectExec(query).
+		WithArgs(entity.RetentionCount, 500, true, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SetRetention(2, policy))
+
+	mock.ExpectExec(query).
+		WithArgs(entity.RetentionCount, 500, true, int64(99)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.SetRetention(99, policy), ErrRoomNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListRoomsWithRetention(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewRoomRepository(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM chat_rooms WHERE retention_mode <> 'forever'").
+		WillReturnRows(sqlmock.NewRows(roomRowColumns).
+			AddRow(3, "news", false, nil, 7, time.Now(), "days", 7, false))
+
+	rooms, err := repo.ListRoomsWithRetention()
+
+	require.NoError(t, err)
+	require.Len(t, rooms, 1)
+	assert.Equal(t, entity.RetentionPolicy{Mode: entity.RetentionDays, Value: 7}, rooms[0].Retention)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
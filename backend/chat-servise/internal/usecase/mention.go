@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+
+	"backend.com/forum/mention"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+)
+
+// mentionHTML экранирует текст и делает ссылками упоминания mentions.
+func mentionHTML(text string, mentions []entity.Mention) string {
+	known := make(map[string]bool, len(mentions))
+	for _, m := range mentions {
+		known[m.Username] = true
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range mention.Find(text) {
+		name := text[loc[2]:loc[3]]
+		if !known[name] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		b.WriteString(`<a href="` + html.EscapeString(mention.URL(name)) + `">@` + html.EscapeString(name) + "</a>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
@@ -2,27 +2,246 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend.com/forum/mention"
+	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
 )
 
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+
+	// mentionLookupTimeout ограничивает поиск упомянутых в auth-сервисе:
+	// сообщение не должно ждать его дольше.
+	mentionLookupTimeout = 2 * time.Second
+)
+
+var (
+	ErrInvalidPageSize    = errors.New("limit must be positive")
+	ErrConflictingCursors = errors.New("before and after cannot be used together")
+	ErrUnknownReaction    = errors.New("reaction is not allowed")
+)
+
+// DefaultReactions — набор эмодзи, если в настройках он не задан.
+var DefaultReactions = []string{"👍", "👎", "❤️", "😂", "😮", "😢", "🎉"}
+
 type MessageUseCase interface {
-	SaveMessage(msg entity.Message) error
-	GetMessages() ([]entity.Message, error)
+	// SaveMessage сохраняет сообщение и заполняет его ID и Timestamp, а
+	// также Mentions и MessageHTML, если в нем упомянуты пользователи.
+	SaveMessage(msg *entity.Message) error
+	// GetMessages возвращает страницу истории комнаты. Limit == 0 означает
+	// DefaultPageSize, больше MaxPageSize не отдаётся.
+	GetMessages(query entity.HistoryQuery) (*entity.HistoryPage, error)
+	// Reactions возвращает разрешённые эмодзи в порядке из настроек.
+	Reactions() []string
+	// ToggleReaction ставит или снимает реакцию пользователя на сообщение
+	// комнаты и возвращает событие для рассылки подписчикам.
+	ToggleReaction(roomID int64, messageID int, userID int64, username, emoji string) (*entity.ReactionFrame, error)
 }
 
 type messageUseCase struct {
-	repo repository.MessageRepository
+	repo       repository.MessageRepository
+	authClient pb.AuthServiceClient
+	reactions  []string
+	allowedSet map[string]bool
 }
 
-func NewMessageUseCase(repo repository.MessageRepository) MessageUseCase {
-	return &messageUseCase{repo: repo}
+// NewMessageUseCase создаёт usecase с набором реакций reactions; пустые
+// значения и повторы отбрасываются, пустой набор заменяется DefaultReactions.
+// Через authClient ищутся упомянутые пользователи; без него упоминания
+// остаются текстом.
+func NewMessageUseCase(repo repository.MessageRepository, reactions []string, authClient pb.AuthServiceClient) MessageUseCase {
+	uc := &messageUseCase{repo: repo, authClient: authClient, allowedSet: make(map[string]bool)}
+	for _, emoji := range reactions {
+		uc.allow(emoji)
+	}
+	if len(uc.reactions) == 0 {
+		for _, emoji := range DefaultReactions {
+			uc.allow(emoji)
+		}
+	}
+	return uc
 }
 
-func (uc *messageUseCase) SaveMessage(msg entity.Message) error {
-	return uc.repo.SaveMessage(msg)
+func (uc *messageUseCase) allow(emoji string) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || uc.allowedSet[emoji] {
+		return
+	}
+	uc.reactions = append(uc.reactions, emoji)
+	uc.allowedSet[emoji] = true
+}
+
+// SaveMessage сохраняет сообщение; без комнаты оно попадает в общую.
+// Ошибки с упоминаниями только пишутся в лог: сообщение уже сохранено.
+func (uc *messageUseCase) SaveMessage(msg *entity.Message) error {
+	if msg.RoomID == 0 {
+		msg.RoomID = entity.GeneralRoomID
+	}
+	mentions := uc.resolveMentions(msg)
+	if err := uc.repo.SaveMessage(msg); err != nil {
+		return err
+	}
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	saved, err := uc.repo.SaveMentions(msg, mentions)
+	if err != nil {
+		log.Printf("Error saving mentions: %v", err)
+		return nil
+	}
+	uc.setMentions(msg, saved)
+	return nil
+}
+
+// resolveMentions ищет в auth-сервисе пользователей, упомянутых в msg.
+// Автор сам себя не упоминает.
+func (uc *messageUseCase) resolveMentions(msg *entity.Message) []entity.Mention {
+	if uc.authClient == nil {
+		return nil
+	}
+	names := mention.Names(msg.Message)
+	if len(names) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mentionLookupTimeout)
+	defer cancel()
+	resp, err := uc.authClient.GetUsersByUsernames(ctx, &pb.GetUsersByUsernamesRequest{Usernames: names})
+	if err != nil || resp == nil {
+		log.Printf("Error resolving mentions: %v", err)
+		return nil
+	}
+
+	found := make(map[string]int64, len(resp.Users))
+	for _, user := range resp.Users {
+		if user != nil {
+			found[user.Username] = user.Id
+		}
+	}
+	var mentions []entity.Mention
+	for _, name := range names {
+		if id, ok := found[name]; ok && id != msg.UserID {
+			mentions = append(mentions, entity.Mention{UserID: id, Username: name})
+		}
+	}
+	return mentions
 }
 
-func (uc *messageUseCase) GetMessages() ([]entity.Message, error) {
-	return uc.repo.GetMessages()
+func (uc *messageUseCase) setMentions(msg *entity.Message, mentions []entity.Mention) {
+	if len(mentions) == 0 {
+		return
+	}
+	msg.Mentions = mentions
+	msg.MessageHTML = mentionHTML(msg.Message, mentions)
+}
+
+func (uc *messageUseCase) GetMessages(query entity.HistoryQuery) (*entity.HistoryPage, error) {
+	if query.Before != nil && query.After != nil {
+		return nil, ErrConflictingCursors
+	}
+	switch {
+	case query.Limit < 0:
+		return nil, ErrInvalidPageSize
+	case query.Limit == 0:
+		query.Limit = DefaultPageSize
+	case query.Limit > MaxPageSize:
+		query.Limit = MaxPageSize
+	}
+	limit := query.Limit
+
+	// Лишнее сообщение показывает, есть ли что-то за пределами страницы.
+	query.Limit++
+	messages, err := uc.repo.GetMessages(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.HistoryPage{Messages: messages, HasMore: len(messages) > limit}
+	if page.HasMore {
+		if query.After != nil {
+			page.Messages = messages[:limit]
+		} else {
+			page.Messages = messages[1:]
+		}
+	}
+	if n := len(page.Messages); n > 0 {
+		page.Before = entity.CursorOf(page.Messages[0]).String()
+		page.After = entity.CursorOf(page.Messages[n-1]).String()
+		if err := uc.fillReactions(query.ViewerID, page.Messages); err != nil {
+			return nil, err
+		}
+		if err := uc.fillMentions(page.Messages); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// fillReactions проставляет сообщениям страницы их реакции.
+func (uc *messageUseCase) fillReactions(viewerID int64, messages []entity.Message) error {
+	ids := make([]int, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+	reactions, err := uc.repo.GetReactions(viewerID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		messages[i].Reactions = reactions[messages[i].ID]
+	}
+	return nil
+}
+
+// fillMentions проставляет сообщениям страницы упоминания.
+func (uc *messageUseCase) fillMentions(messages []entity.Message) error {
+	ids := make([]int, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+	mentions, err := uc.repo.GetMentions(ids)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		uc.setMentions(&messages[i], mentions[messages[i].ID])
+	}
+	return nil
+}
+
+func (uc *messageUseCase) Reactions() []string {
+	return append([]string(nil), uc.reactions...)
+}
+
+func (uc *messageUseCase) ToggleReaction(roomID int64, messageID int, userID int64, username, emoji string) (*entity.ReactionFrame, error) {
+	if !uc.allowedSet[emoji] {
+		return nil, ErrUnknownReaction
+	}
+	if roomID == 0 {
+		roomID = entity.GeneralRoomID
+	}
+
+	added, count, err := uc.repo.ToggleReaction(roomID, messageID, userID, emoji)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ReactionFrame{
+		Type:      entity.FrameReaction,
+		RoomID:    roomID,
+		MessageID: messageID,
+		UserID:    userID,
+		Username:  username,
+		Emoji:     emoji,
+		Added:     added,
+		Count:     count,
+	}, nil
 }
@@ -2,55 +2,332 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 type MockMessageRepository struct {
 	mock.Mock
 }
 
-func (m *MockMessageRepository) SaveMessage(msg entity.Message) error {
+func (m *MockMessageRepository) SaveMessage(msg *entity.Message) error {
 	args := m.Called(msg)
 	return args.Error(0)
 }
 
-func (m *MockMessageRepository) GetMessages() ([]entity.Message, error) {
-	args := m.Called()
+func (m *MockMessageRepository) GetMessages(query entity.HistoryQuery) ([]entity.Message, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]entity.Message), args.Error(1)
 }
+
+func (m *MockMessageRepository) NthNewest(roomID int64, n int) (*entity.Cursor, error) {
+	args := m.Called(roomID, n)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Cursor), args.Error(1)
+}
+
+func (m *MockMessageRepository) ExpireMessages(roomID int64, before entity.Cursor, limit int, archive bool) (int64, error) {
+	args := m.Called(roomID, before, limit, archive)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) ToggleReaction(roomID int64, messageID int, userID int64, emoji string) (bool, int, error) {
+	args := m.Called(roomID, messageID, userID, emoji)
+	return args.Bool(0), args.Int(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) GetReactions(viewerID int64, messageIDs []int) (map[int][]entity.Reaction, error) {
+	args := m.Called(viewerID, messageIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int][]entity.Reaction), args.Error(1)
+}
+
+func (m *MockMessageRepository) SaveMentions(msg *entity.Message, mentions []entity.Mention) ([]entity.Mention, error) {
+	args := m.Called(msg, mentions)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Mention), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetMentions(messageIDs []int) (map[int][]entity.Mention, error) {
+	args := m.Called(messageIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int][]entity.Mention), args.Error(1)
+}
+
+type fakeAuthClient struct {
+	pb.AuthServiceClient
+	users []*pb.User
+	err   error
+	asked []string
+}
+
+func (f *fakeAuthClient) GetUsersByUsernames(ctx context.Context, in *pb.GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+	f.asked = in.Usernames
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &pb.GetUsersResponse{Users: f.users}, nil
+}
+
 func TestMessageUseCase_SaveMessage(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
-	uc := NewMessageUseCase(mockRepo)
+	uc := NewMessageUseCase(mockRepo, nil, nil)
 
-	mockRepo.On("SaveMessage", entity.Message{Username: "test", Message: "hello"}).Return(nil)
-	err := uc.SaveMessage(entity.Message{Username: "test", Message: "hello"})
+	// Сообщение без комнаты попадает в общую.
+	mockRepo.On("SaveMessage", &entity.Message{RoomID: entity.GeneralRoomID, Username: "test", Message: "hello"}).Return(nil)
+	err := uc.SaveMessage(&entity.Message{Username: "test", Message: "hello"})
 	assert.NoError(t, err)
 
-	mockRepo.On("SaveMessage", entity.Message{Username: "error", Message: "fail"}).Return(errors.New("db error"))
-	err = uc.SaveMessage(entity.Message{Username: "error", Message: "fail"})
+	mockRepo.On("SaveMessage", &entity.Message{RoomID: 2, Username: "error", Message: "fail"}).Return(errors.New("db error"))
+	err = uc.SaveMessage(&entity.Message{RoomID: 2, Username: "error", Message: "fail"})
 	assert.Error(t, err)
 }
 
+// history возвращает n сообщений комнаты с ID от first по возрастанию.
+func history(first, n int) []entity.Message {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	messages := make([]entity.Message, n)
+	for i := range messages {
+		id := first + i
+		messages[i] = entity.Message{ID: id, RoomID: 1, Message: "test", Timestamp: start.Add(time.Duration(id) * time.Second)}
+	}
+	return messages
+}
+
 func TestMessageUseCase_GetMessages(t *testing.T) {
+	cursor := &entity.Cursor{Timestamp: time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC), ID: 10}
+
+	tests := []struct {
+		name        string
+		query       entity.HistoryQuery
+		repoQuery   entity.HistoryQuery
+		repoResult  []entity.Message
+		wantIDs     []int
+		wantHasMore bool
+		wantErr     error
+	}{
+		{
+			name:       "Default limit",
+			query:      entity.HistoryQuery{RoomID: 1},
+			repoQuery:  entity.HistoryQuery{RoomID: 1, Limit: DefaultPageSize + 1},
+			repoResult: history(1, 2),
+			wantIDs:    []int{1, 2},
+		},
+		{
+			name:        "Latest page drops oldest extra",
+			query:       entity.HistoryQuery{RoomID: 1, Limit: 2},
+			repoQuery:   entity.HistoryQuery{RoomID: 1, Limit: 3},
+			repoResult:  history(7, 3),
+			wantIDs:     []int{8, 9},
+			wantHasMore: true,
+		},
+		{
+			name:        "Before cursor",
+			query:       entity.HistoryQuery{RoomID: 1, Before: cursor, Limit: 2},
+			repoQuery:   entity.HistoryQuery{RoomID: 1, Before: cursor, Limit: 3},
+			repoResult:  history(7, 3),
+			wantIDs:     []int{8, 9},
+			wantHasMore: true,
+		},
+		{
+			name:        "After cursor drops newest extra",
+			query:       entity.HistoryQuery{RoomID: 1, After: cursor, Limit: 2},
+			repoQuery:   entity.HistoryQuery{RoomID: 1, After: cursor, Limit: 3},
+			repoResult:  history(11, 3),
+			wantIDs:     []int{11, 12},
+			wantHasMore: true,
+		},
+		{
+			name:       "Limit capped",
+			query:      entity.HistoryQuery{RoomID: 1, Limit: 1000},
+			repoQuery:  entity.HistoryQuery{RoomID: 1, Limit: MaxPageSize + 1},
+			repoResult: []entity.Message{},
+			wantIDs:    []int{},
+		},
+		{
+			name:    "Negative limit",
+			query:   entity.HistoryQuery{RoomID: 1, Limit: -1},
+			wantErr: ErrInvalidPageSize,
+		},
+		{
+			name:    "Both cursors",
+			query:   entity.HistoryQuery{RoomID: 1, Before: cursor, After: cursor},
+			wantErr: ErrConflictingCursors,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMessageRepository)
+			mockRepo.On("GetMessages", tt.repoQuery).Return(tt.repoResult, nil)
+			mockRepo.On("GetReactions", int64(0), mock.Anything).Return(map[int][]entity.Reaction{}, nil)
+			mockRepo.On("GetMentions", mock.Anything).Return(map[int][]entity.Mention{}, nil)
+			uc := NewMessageUseCase(mockRepo, nil, nil)
+
+			page, err := uc.GetMessages(tt.query)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "GetMessages", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			ids := []int{}
+			for _, msg := range page.Messages {
+				ids = append(ids, msg.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantHasMore, page.HasMore)
+			if len(page.Messages) == 0 {
+				assert.Empty(t, page.Before)
+				assert.Empty(t, page.After)
+				return
+			}
+
+			before, err := entity.ParseCursor(page.Before)
+			require.NoError(t, err)
+			assert.Equal(t, entity.CursorOf(page.Messages[0]), before)
+			after, err := entity.ParseCursor(page.After)
+			require.NoError(t, err)
+			assert.Equal(t, entity.CursorOf(page.Messages[len(page.Messages)-1]), after)
+		})
+	}
+}
+
+func TestMessageUseCase_GetMessages_Error(t *testing.T) {
 	mockRepo := new(MockMessageRepository)
-	uc := NewMessageUseCase(mockRepo)
+	mockRepo.On("GetMessages", mock.Anything).Return(nil, errors.New("db error"))
+	uc := NewMessageUseCase(mockRepo, nil, nil)
 
-	expected := []entity.Message{{ID: 1, Username: "user", Message: "test"}}
-	mockRepo.On("GetMessages").Return(expected, nil)
-	result, err := uc.GetMessages()
-	assert.NoError(t, err)
-	assert.Equal(t, expected, result)
+	_, err := uc.GetMessages(entity.HistoryQuery{RoomID: 1})
 
-	mockRepo.On("GetMessages").Return([]entity.Message{}, nil)
-	result, err = uc.GetMessages()
-	assert.NoError(t, err)
-	assert.Empty(t, result)
+	assert.Error(t, err)
+}
+
+func TestMessageUseCase_GetMessages_Reactions(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("GetMessages", mock.Anything).Return(history(1, 2), nil)
+	mockRepo.On("GetReactions", int64(7), []int{1, 2}).
+		Return(map[int][]entity.Reaction{2: {{Emoji: "👍", Count: 1, Mine: true}}}, nil)
+	mockRepo.On("GetMentions", []int{1, 2}).Return(map[int][]entity.Mention{}, nil)
+	uc := NewMessageUseCase(mockRepo, nil, nil)
+
+	page, err := uc.GetMessages(entity.HistoryQuery{RoomID: 1, ViewerID: 7})
+	require.NoError(t, err)
+	assert.Nil(t, page.Messages[0].Reactions)
+	assert.Equal(t, []entity.Reaction{{Emoji: "👍", Count: 1, Mine: true}}, page.Messages[1].Reactions)
+}
+
+func TestMessageUseCase_GetMessages_Mentions(t *testing.T) {
+	messages := history(1, 2)
+	messages[1].Message = "hi @alice & @bob"
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("GetMessages", mock.Anything).Return(messages, nil)
+	mockRepo.On("GetReactions", int64(0), []int{1, 2}).Return(map[int][]entity.Reaction{}, nil)
+	mockRepo.On("GetMentions", []int{1, 2}).
+		Return(map[int][]entity.Mention{2: {{UserID: 5, Username: "alice"}}}, nil)
+	uc := NewMessageUseCase(mockRepo, nil, nil)
+
+	page, err := uc.GetMessages(entity.HistoryQuery{RoomID: 1})
+	require.NoError(t, err)
+	assert.Nil(t, page.Messages[0].Mentions)
+	assert.Empty(t, page.Messages[0].MessageHTML)
+	assert.Equal(t, []entity.Mention{{UserID: 5, Username: "alice"}}, page.Messages[1].Mentions)
+	assert.Equal(t, `hi <a href="/users/alice">@alice</a> &amp; @bob`, page.Messages[1].MessageHTML)
+}
+
+func TestMessageUseCase_SaveMessage_Mentions(t *testing.T) {
+	auth := &fakeAuthClient{users: []*pb.User{
+		{Id: 6, Username: "bob"},
+		{Id: 5, Username: "alice"},
+		{Id: 7, Username: "carol"},
+	}}
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("SaveMessage", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*entity.Message).ID = 10
+	})
+	// bob не состоит в закрытой комнате — его упоминание не сохранилось.
+	mockRepo.On("SaveMentions", mock.Anything, []entity.Mention{{UserID: 5, Username: "alice"}, {UserID: 6, Username: "bob"}}).
+		Return([]entity.Mention{{UserID: 5, Username: "alice"}}, nil)
+	uc := NewMessageUseCase(mockRepo, nil, auth)
+
+	msg := &entity.Message{RoomID: 2, UserID: 7, Username: "carol", Message: "@alice @bob @carol mail@alice.io @alice"}
+	require.NoError(t, uc.SaveMessage(msg))
+	assert.Equal(t, []string{"alice", "bob", "carol"}, auth.asked)
+	assert.Equal(t, []entity.Mention{{UserID: 5, Username: "alice"}}, msg.Mentions)
+	assert.Equal(t, `<a href="/users/alice">@alice</a> @bob @carol mail@alice.io <a href="/users/alice">@alice</a>`, msg.MessageHTML)
+
+	t.Run("Auth failure keeps the message", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockRepo.On("SaveMessage", mock.Anything).Return(nil)
+		uc := NewMessageUseCase(mockRepo, nil, &fakeAuthClient{err: errors.New("unavailable")})
+
+		msg := &entity.Message{RoomID: 2, UserID: 7, Message: "@alice"}
+		require.NoError(t, uc.SaveMessage(msg))
+		assert.Nil(t, msg.Mentions)
+		mockRepo.AssertNotCalled(t, "SaveMentions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Mentions save failure keeps the message", func(t *testing.T) {
+		mockRepo := new(MockMessageRepository)
+		mockRepo.On("SaveMessage", mock.Anything).Return(nil)
+		mockRepo.On("SaveMentions", mock.Anything, mock.Anything).Return(nil, errors.New("db error"))
+		uc := NewMessageUseCase(mockRepo, nil, &fakeAuthClient{users: []*pb.User{{Id: 5, Username: "alice"}}})
+
+		msg := &entity.Message{RoomID: 2, UserID: 7, Message: "@alice"}
+		require.NoError(t, uc.SaveMessage(msg))
+		assert.Nil(t, msg.Mentions)
+		assert.Empty(t, msg.MessageHTML)
+	})
+}
+
+func TestMessageUseCase_Reactions(t *testing.T) {
+	assert.Equal(t, DefaultReactions, NewMessageUseCase(new(MockMessageRepository), nil, nil).Reactions())
+	assert.Equal(t, []string{"🔥", "👍"}, NewMessageUseCase(new(MockMessageRepository), []string{" 🔥", "", "👍", "🔥"}, nil).Reactions())
+}
+
+func TestMessageUseCase_ToggleReaction(t *testing.T) {
+	mockRepo := new(MockMessageRepository)
+	mockRepo.On("ToggleReaction", entity.GeneralRoomID, 5, int64(7), "👍").Return(false, 1, nil)
+	mockRepo.On("ToggleReaction", int64(2), 6, int64(7), "👍").Return(false, 0, repository.ErrMessageNotFound)
+	uc := NewMessageUseCase(mockRepo, []string{"👍"}, nil)
+
+	// Реакция без комнаты относится к общей.
+	event, err := uc.ToggleReaction(0, 5, 7, "alice", "👍")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.ReactionFrame{
+		Type: entity.FrameReaction, RoomID: entity.GeneralRoomID, MessageID: 5,
+		UserID: 7, Username: "alice", Emoji: "👍", Added: false, Count: 1,
+	}, event)
+
+	_, err = uc.ToggleReaction(2, 6, 7, "alice", "👍")
+	assert.ErrorIs(t, err, repository.ErrMessageNotFound)
+
+	_, err = uc.ToggleReaction(2, 6, 7, "alice", "🎉")
+	assert.ErrorIs(t, err, ErrUnknownReaction)
+	mockRepo.AssertNumberOfCalls(t, "ToggleReaction", 2)
 }
 
 // func TestMessageUseCase_SaveMessage(t *testing.T) {
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/lib/pq"
+)
+
+// NotificationChannel — канал Postgres, в который триггер на таблице
+// notifications публикует каждое новое уведомление.
+const NotificationChannel = "notifications"
+
+// NotificationSender доставляет кадр всем соединениям пользователя.
+type NotificationSender interface {
+	SendUser(userID int64, v interface{}) error
+}
+
+// NotificationRelay пересылает уведомления форума, приходящие через
+// LISTEN/NOTIFY, получателям по WebSocket. Уведомления пользователей без
+// открытого соединения не теряются: они остаются в таблице и приходят
+// через REST форума.
+type NotificationRelay struct {
+	events <-chan *pq.Notification
+	sender NotificationSender
+}
+
+func NewNotificationRelay(events <-chan *pq.Notification, sender NotificationSender) *NotificationRelay {
+	return &NotificationRelay{events: events, sender: sender}
+}
+
+// notificationEvent — полезная нагрузка NOTIFY, см. notify_notification()
+// в миграциях.
+type notificationEvent struct {
+	UserID       int64           `json:"user_id"`
+	UnreadCount  int64           `json:"unread_count"`
+	Notification json.RawMessage `json:"notification"`
+}
+
+// Run пересылает уведомления до отмены ctx или закрытия events.
+func (r *NotificationRelay) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-r.events:
+			if !ok {
+				return
+			}
+			// nil приходит после переподключения слушателя: пропущенные
+			// за это время уведомления клиенты увидят при следующем запросе.
+			if event != nil {
+				r.Relay(event.Extra)
+			}
+		}
+	}
+}
+
+// Relay отправляет одно уведомление; payload — JSON из NOTIFY.
+func (r *NotificationRelay) Relay(payload string) {
+	var event notificationEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil || event.UserID == 0 {
+		log.Printf("notifications: bad payload %q: %v", payload, err)
+		return
+	}
+
+	frame := entity.NotificationFrame{
+		Type:         entity.FrameNotification,
+		UnreadCount:  event.UnreadCount,
+		Notification: event.Notification,
+	}
+	if err := r.sender.SendUser(event.UserID, frame); err != nil {
+		log.Printf("notifications: failed to deliver to user %d: %v", event.UserID, err)
+	}
+}
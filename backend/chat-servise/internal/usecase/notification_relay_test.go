@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sentFrame struct {
+	userID int64
+	frame  interface{}
+}
+
+type fakeSender struct {
+	sent chan sentFrame
+}
+
+func (s *fakeSender) SendUser(userID int64, v interface{}) error {
+	s.sent <- sentFrame{userID: userID, frame: v}
+	return nil
+}
+
+func TestNotificationRelay_Run(t *testing.T) {
+	events := make(chan *pq.Notification)
+	sender := &fakeSender{sent: make(chan sentFrame, 4)}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewNotificationRelay(events, sender).Run(ctx)
+		close(done)
+	}()
+
+	events <- nil
+	events <- &pq.Notification{Channel: NotificationChannel, Extra: `not json`}
+	events <- &pq.Notification{Channel: NotificationChannel, Extra: `{"unread_count": 1}`}
+	events <- &pq.Notification{
+		Channel: NotificationChannel,
+		Extra:   `{"user_id": 5, "unread_count": 3, "notification": {"id": 10, "type": "reply"}}`,
+	}
+
+	select {
+	case got := <-sender.sent:
+		assert.Equal(t, int64(5), got.userID)
+		assert.Equal(t, entity.NotificationFrame{
+			Type:         entity.FrameNotification,
+			UnreadCount:  3,
+			Notification: json.RawMessage(`{"id": 10, "type": "reply"}`),
+		}, got.frame)
+	case <-time.After(time.Second):
+		t.Fatal("notification was not relayed")
+	}
+	assert.Empty(t, sender.sent, "bad payloads must be dropped")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "relay did not stop")
+	}
+}
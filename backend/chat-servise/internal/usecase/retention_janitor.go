@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
+)
+
+const (
+	DefaultRetentionInterval  = time.Minute
+	DefaultRetentionBatchSize = 1000
+)
+
+// RetentionJanitor соблюдает сроки хранения сообщений комнат: периодически
+// удаляет устаревшие сообщения или переносит их в архив. Удаление идёт
+// пачками по batchSize, чтобы не держать долгих блокировок.
+type RetentionJanitor struct {
+	rooms     repository.RoomRepository
+	messages  repository.MessageRepository
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+func NewRetentionJanitor(rooms repository.RoomRepository, messages repository.MessageRepository, interval time.Duration, batchSize int) *RetentionJanitor {
+	if interval <= 0 {
+		interval = DefaultRetentionInterval
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultRetentionBatchSize
+	}
+	return &RetentionJanitor{
+		rooms:     rooms,
+		messages:  messages,
+		interval:  interval,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run запускает очистку сразу и затем каждые interval до отмены ctx.
+func (j *RetentionJanitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := j.Sweep(ctx)
+		if err != nil {
+			log.Printf("retention sweep failed: %v", err)
+		}
+		if expired > 0 {
+			log.Printf("retention: expired %d messages", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep один раз проходит по комнатам с ограниченным сроком хранения и
+// возвращает число удалённых или перенесённых сообщений. Ошибка в одной
+// комнате не останавливает обработку остальных.
+func (j *RetentionJanitor) Sweep(ctx context.Context) (int64, error) {
+	rooms, err := j.rooms.ListRoomsWithRetention()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		total int64
+		errs  []error
+	)
+	for _, room := range rooms {
+		if ctx.Err() != nil {
+			break
+		}
+		n, err := j.sweepRoom(ctx, room)
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("room %d: %w", room.ID, err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+func (j *RetentionJanitor) sweepRoom(ctx context.Context, room entity.Room) (int64, error) {
+	cutoff, err := j.cutoff(room)
+	if err != nil || cutoff == nil {
+		return 0, err
+	}
+
+	var total int64
+	for ctx.Err() == nil {
+		n, err := j.messages.ExpireMessages(room.ID, *cutoff, j.batchSize, room.Retention.Archive)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(j.batchSize) {
+			break
+		}
+	}
+	return total, nil
+}
+
+// cutoff возвращает курсор, раньше которого сообщения комнаты устарели, или
+// nil, если устаревших нет.
+func (j *RetentionJanitor) cutoff(room entity.Room) (*entity.Cursor, error) {
+	policy := room.Retention
+	switch policy.Mode {
+	case entity.RetentionDays:
+		if policy.Value <= 0 {
+			return nil, nil
+		}
+		// ID сообщений положительные, поэтому (t, 0) отсекает всё раньше t.
+		return &entity.Cursor{Timestamp: j.now().AddDate(0, 0, -policy.Value)}, nil
+	case entity.RetentionCount:
+		if policy.Value <= 0 {
+			return nil, nil
+		}
+		return j.messages.NthNewest(room.ID, policy.Value)
+	default:
+		return nil, nil
+	}
+}
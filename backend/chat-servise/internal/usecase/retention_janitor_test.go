@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJanitor(rooms *MockRoomRepository, messages *MockMessageRepository, batchSize int) *RetentionJanitor {
+	j := NewRetentionJanitor(rooms, messages, time.Hour, batchSize)
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	j.now = func() time.Time { return now }
+	return j
+}
+
+func TestRetentionJanitor_Sweep(t *testing.T) {
+	daysRoom := entity.Room{ID: 2, Retention: entity.RetentionPolicy{Mode: entity.RetentionDays, Value: 30}}
+	countRoom := entity.Room{ID: 3, Retention: entity.RetentionPolicy{Mode: entity.RetentionCount, Value: 100, Archive: true}}
+	smallRoom := entity.Room{ID: 4, Retention: entity.RetentionPolicy{Mode: entity.RetentionCount, Value: 100}}
+
+	rooms := new(MockRoomRepository)
+	rooms.On("ListRoomsWithRetention").Return([]entity.Room{daysRoom, countRoom, smallRoom}, nil)
+
+	messages := new(MockMessageRepository)
+	// 30 дней назад от now; удаление идёт пачками по 10, пока пачка полная.
+	daysCutoff := entity.Cursor{Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	messages.On("ExpireMessages", int64(2), daysCutoff, 10, false).Return(int64(10), nil).Twice()
+	messages.On("ExpireMessages", int64(2), daysCutoff, 10, false).Return(int64(3), nil).Once()
+
+	countCutoff := &entity.Cursor{Timestamp: time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC), ID: 500}
+	messages.On("NthNewest", int64(3), 100).Return(countCutoff, nil)
+	messages.On("ExpireMessages", int64(3), *countCutoff, 10, true).Return(int64(4), nil).Once()
+
+	// В комнате меньше 100 сообщений — удалять нечего.
+	messages.On("NthNewest", int64(4), 100).Return(nil, nil)
+
+	expired, err := newTestJanitor(rooms, messages, 10).Sweep(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(27), expired)
+	messages.AssertExpectations(t)
+	messages.AssertNotCalled(t, "ExpireMessages", int64(4), mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRetentionJanitor_Sweep_ContinuesAfterRoomError(t *testing.T) {
+	rooms := new(MockRoomRepository)
+	rooms.On("ListRoomsWithRetention").Return([]entity.Room{
+		{ID: 2, Retention: entity.RetentionPolicy{Mode: entity.RetentionCount, Value: 10}},
+		{ID: 3, Retention: entity.RetentionPolicy{Mode: entity.RetentionDays, Value: 1}},
+	}, nil)
+
+	messages := new(MockMessageRepository)
+	messages.On("NthNewest", int64(2), 10).Return(nil, errors.New("database error"))
+	messages.On("ExpireMessages", int64(3), mock.Anything, 10, false).Return(int64(2), nil)
+
+	expired, err := newTestJanitor(rooms, messages, 10).Sweep(context.Background())
+
+	assert.ErrorContains(t, err, "room 2")
+	assert.Equal(t, int64(2), expired)
+}
+
+func TestRetentionJanitor_Sweep_StopsOnCancel(t *testing.T) {
+	rooms := new(MockRoomRepository)
+	rooms.On("ListRoomsWithRetention").Return([]entity.Room{
+		{ID: 2, Retention: entity.RetentionPolicy{Mode: entity.RetentionDays, Value: 1}},
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages := new(MockMessageRepository)
+	// Каждая пачка полная; после отмены следующей быть не должно.
+	messages.On("ExpireMessages", int64(2), mock.Anything, 10, false).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(int64(10), nil)
+
+	expired, err := newTestJanitor(rooms, messages, 10).Sweep(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), expired)
+	messages.AssertNumberOfCalls(t, "ExpireMessages", 1)
+}
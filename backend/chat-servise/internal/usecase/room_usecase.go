@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrRoomAccessDenied   = errors.New("access to room denied")
+	ErrInvalidRoomName    = errors.New("room name is required")
+	ErrTopicNotFound      = errors.New("topic not found")
+	ErrTopicRoomsDisabled = errors.New("topic rooms are disabled")
+	ErrInvalidRetention   = errors.New("invalid retention policy")
+)
+
+const (
+	maxRoomNameLength = 255
+	forumTimeout      = 5 * time.Second
+	// maxRetentionDays — около 10 лет; дальше разумнее хранить вечно.
+	maxRetentionDays = 3650
+)
+
+type RoomUseCase interface {
+	CreateRoom(ownerID int64, name string, isPrivate bool) (*entity.Room, error)
+	ListRooms(userID int64) ([]entity.Room, error)
+	// CanAccess проверяет, может ли пользователь читать и писать в комнату:
+	// публичная комната открыта всем, закрытая — только участникам.
+	CanAccess(roomID, userID int64) error
+	// Join добавляет пользователя в публичную комнату. В закрытую можно
+	// войти только после приглашения.
+	Join(roomID, userID int64) error
+	Leave(roomID, userID int64) error
+	// Invite добавляет userID в комнату; приглашать может только владелец.
+	Invite(roomID, inviterID, userID int64) error
+	// TopicRoom возвращает комнату обсуждения темы форума, создавая её при
+	// первом обращении.
+	TopicRoom(topicID int64) (*entity.Room, error)
+	// SetRetention меняет срок хранения сообщений комнаты; менять его может
+	// владелец комнаты или администратор.
+	SetRetention(roomID int64, user *authmw.Principal, policy entity.RetentionPolicy) (*entity.Room, error)
+}
+
+type roomUseCase struct {
+	repo  repository.RoomRepository
+	forum pb.ForumServiceClient
+}
+
+// NewRoomUseCase создаёт usecase комнат. Если forum == nil, комнаты тем
+// форума недоступны.
+func NewRoomUseCase(repo repository.RoomRepository, forum pb.ForumServiceClient) RoomUseCase {
+	return &roomUseCase{repo: repo, forum: forum}
+}
+
+func (uc *roomUseCase) CreateRoom(ownerID int64, name string, isPrivate bool) (*entity.Room, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxRoomNameLength {
+		return nil, ErrInvalidRoomName
+	}
+
+	room := &entity.Room{
+		Name:      name,
+		IsPrivate: isPrivate,
+		CreatedBy: ownerID,
+		Retention: entity.RetentionPolicy{Mode: entity.RetentionForever},
+	}
+	if err := uc.repo.CreateRoom(room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (uc *roomUseCase) ListRooms(userID int64) ([]entity.Room, error) {
+	return uc.repo.ListRooms(userID)
+}
+
+func (uc *roomUseCase) CanAccess(roomID, userID int64) error {
+	room, err := uc.repo.GetRoom(roomID)
+	if err != nil {
+		return err
+	}
+	if !room.IsPrivate {
+		return nil
+	}
+
+	role, err := uc.repo.GetMemberRole(roomID, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrRoomAccessDenied
+	}
+	return nil
+}
+
+func (uc *roomUseCase) Join(roomID, userID int64) error {
+	if err := uc.CanAccess(roomID, userID); err != nil {
+		return err
+	}
+	return uc.repo.AddMember(roomID, userID, entity.RoomRoleMember)
+}
+
+func (uc *roomUseCase) Leave(roomID, userID int64) error {
+	if _, err := uc.repo.GetRoom(roomID); err != nil {
+		return err
+	}
+	return uc.repo.RemoveMember(roomID, userID)
+}
+
+func (uc *roomUseCase) Invite(roomID, inviterID, userID int64) error {
+	if _, err := uc.repo.GetRoom(roomID); err != nil {
+		return err
+	}
+
+	role, err := uc.repo.GetMemberRole(roomID, inviterID)
+	if err != nil {
+		return err
+	}
+	if role != entity.RoomRoleOwner {
+		return ErrRoomAccessDenied
+	}
+	return uc.repo.AddMember(roomID, userID, entity.RoomRoleMember)
+}
+
+func (uc *roomUseCase) TopicRoom(topicID int64) (*entity.Room, error) {
+	room, err := uc.repo.GetRoomByTopic(topicID)
+	if err == nil {
+		return room, nil
+	}
+	if !errors.Is(err, repository.ErrRoomNotFound) {
+		return nil, err
+	}
+
+	if uc.forum == nil {
+		return nil, ErrTopicRoomsDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forumTimeout)
+	defer cancel()
+
+	resp, err := uc.forum.GetTopic(ctx, &pb.GetTopicRequest{Id: topicID})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrTopicNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get topic: %w", err)
+	}
+
+	return uc.repo.CreateTopicRoom(topicID, resp.GetTopic().GetTitle())
+}
+
+func (uc *roomUseCase) SetRetention(roomID int64, user *authmw.Principal, policy entity.RetentionPolicy) (*entity.Room, error) {
+	switch policy.Mode {
+	case entity.RetentionForever:
+		policy.Value = 0
+	case entity.RetentionDays:
+		if policy.Value <= 0 || policy.Value > maxRetentionDays {
+			return nil, ErrInvalidRetention
+		}
+	case entity.RetentionCount:
+		if policy.Value <= 0 {
+			return nil, ErrInvalidRetention
+		}
+	default:
+		return nil, ErrInvalidRetention
+	}
+
+	if _, err := uc.repo.GetRoom(roomID); err != nil {
+		return nil, err
+	}
+	if !user.IsAdmin() {
+		role, err := uc.repo.GetMemberRole(roomID, user.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if role != entity.RoomRoleOwner {
+			return nil, ErrRoomAccessDenied
+		}
+	}
+
+	if err := uc.repo.SetRetention(roomID, policy); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetRoom(roomID)
+}
@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type MockRoomRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoomRepository) CreateRoom(room *entity.Room) error {
+	return m.Called(room).Error(0)
+}
+
+func (m *MockRoomRepository) CreateTopicRoom(topicID int64, name string) (*entity.Room, error) {
+	args := m.Called(topicID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Room), args.Error(1)
+}
+
+func (m *MockRoomRepository) GetRoom(id int64) (*entity.Room, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Room), args.Error(1)
+}
+
+func (m *MockRoomRepository) GetRoomByTopic(topicID int64) (*entity.Room, error) {
+	args := m.Called(topicID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Room), args.Error(1)
+}
+
+func (m *MockRoomRepository) ListRooms(userID int64) ([]entity.Room, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]entity.Room), args.Error(1)
+}
+
+func (m *MockRoomRepository) AddMember(roomID, userID int64, role string) error {
+	return m.Called(roomID, userID, role).Error(0)
+}
+
+func (m *MockRoomRepository) RemoveMember(roomID, userID int64) error {
+	return m.Called(roomID, userID).Error(0)
+}
+
+func (m *MockRoomRepository) GetMemberRole(roomID, userID int64) (string, error) {
+	args := m.Called(roomID, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRoomRepository) SetRetention(roomID int64, policy entity.RetentionPolicy) error {
+	return m.Called(roomID, policy).Error(0)
+}
+
+func (m *MockRoomRepository) ListRoomsWithRetention() ([]entity.Room, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.Room), args.Error(1)
+}
+
+// fakeForumClient отвечает на GetTopic, остальные методы не нужны.
+type fakeForumClient struct {
+	pb.ForumServiceClient
+	getTopic func(id int64) (*pb.GetTopicResponse, error)
+}
+
+func (f *fakeForumClient) GetTopic(ctx context.Context, in *pb.GetTopicRequest, opts ...grpc.CallOption) (*pb.GetTopicResponse, error) {
+	return f.getTopic(in.Id)
+}
+
+var (
+	publicRoom  = &entity.Room{ID: 1, Name: "general"}
+	privateRoom = &entity.Room{ID: 2, Name: "secret", IsPrivate: true, CreatedBy: 7}
+)
+
+func TestRoomUseCase_CreateRoom(t *testing.T) {
+	repo := new(MockRoomRepository)
+	uc := NewRoomUseCase(repo, nil)
+
+	repo.On("CreateRoom", &entity.Room{
+		Name:      "golang",
+		IsPrivate: true,
+		CreatedBy: 7,
+		Retention: entity.RetentionPolicy{Mode: entity.RetentionForever},
+	}).Return(nil)
+	room, err := uc.CreateRoom(7, "  golang ", true)
+	require.NoError(t, err)
+	assert.Equal(t, "golang", room.Name)
+
+	for _, name := range []string{"", "   ", strings.Repeat("a", maxRoomNameLength+1)} {
+		_, err := uc.CreateRoom(7, name, false)
+		assert.ErrorIs(t, err, ErrInvalidRoomName)
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestRoomUseCase_Join(t *testing.T) {
+	tests := []struct {
+		name    string
+		room    *entity.Room
+		role    string
+		wantErr error
+	}{
+		{name: "Public room", room: publicRoom},
+		{name: "Private room as member", room: privateRoom, role: entity.RoomRoleMember},
+		{name: "Private room without invite", room: privateRoom, wantErr: ErrRoomAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRoomRepository)
+			repo.On("GetRoom", tt.room.ID).Return(tt.room, nil)
+			repo.On("GetMemberRole", tt.room.ID, int64(8)).Return(tt.role, nil)
+			repo.On("AddMember", tt.room.ID, int64(8), entity.RoomRoleMember).Return(nil)
+			uc := NewRoomUseCase(repo, nil)
+
+			err := uc.Join(tt.room.ID, 8)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			repo.AssertCalled(t, "AddMember", tt.room.ID, int64(8), entity.RoomRoleMember)
+		})
+	}
+}
+
+func TestRoomUseCase_Join_NotFound(t *testing.T) {
+	repo := new(MockRoomRepository)
+	repo.On("GetRoom", int64(9)).Return(nil, repository.ErrRoomNotFound)
+	uc := NewRoomUseCase(repo, nil)
+
+	assert.ErrorIs(t, uc.Join(9, 8), repository.ErrRoomNotFound)
+}
+
+func TestRoomUseCase_Invite(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		wantErr error
+	}{
+		{name: "Owner", role: entity.RoomRoleOwner},
+		{name: "Member", role: entity.RoomRoleMember, wantErr: ErrRoomAccessDenied},
+		{name: "Stranger", wantErr: ErrRoomAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRoomRepository)
+			repo.On("GetRoom", int64(2)).Return(privateRoom, nil)
+			repo.On("GetMemberRole", int64(2), int64(7)).Return(tt.role, nil)
+			repo.On("AddMember", int64(2), int64(8), entity.RoomRoleMember).Return(nil)
+			uc := NewRoomUseCase(repo, nil)
+
+			err := uc.Invite(2, 7, 8)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "AddMember", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRoomUseCase_TopicRoom(t *testing.T) {
+	topicRoom := &entity.Room{ID: 4, Name: "Go generics"}
+
+	tests := []struct {
+		name     string
+		existing *entity.Room
+		forum    pb.ForumServiceClient
+		want     *entity.Room
+		wantErr  error
+	}{
+		{name: "Existing room", existing: topicRoom, want: topicRoom},
+		{name: "Forum disabled", wantErr: ErrTopicRoomsDisabled},
+		{
+			name: "Topic not found",
+			forum: &fakeForumClient{getTopic: func(id int64) (*pb.GetTopicResponse, error) {
+				return nil, status.Error(codes.NotFound, "topic not found")
+			}},
+			wantErr: ErrTopicNotFound,
+		},
+		{
+			name: "Created on first request",
+			forum: &fakeForumClient{getTopic: func(id int64) (*pb.GetTopicResponse, error) {
+				return &pb.GetTopicResponse{Topic: &pb.Topic{Id: id, Title: "Go generics"}}, nil
+			}},
+			want: topicRoom,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRoomRepository)
+			if tt.existing != nil {
+				repo.On("GetRoomByTopic", int64(5)).Return(tt.existing, nil)
+			} else {
+				repo.On("GetRoomByTopic", int64(5)).Return(nil, repository.ErrRoomNotFound)
+			}
+			repo.On("CreateTopicRoom", int64(5), "Go generics").Return(topicRoom, nil)
+			uc := NewRoomUseCase(repo, tt.forum)
+
+			room, err := uc.TopicRoom(5)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, room)
+		})
+	}
+}
+
+func TestRoomUseCase_TopicRoom_ForumUnavailable(t *testing.T) {
+	repo := new(MockRoomRepository)
+	repo.On("GetRoomByTopic", int64(5)).Return(nil, repository.ErrRoomNotFound)
+	forum := &fakeForumClient{getTopic: func(id int64) (*pb.GetTopicResponse, error) {
+		return nil, errors.New("connection refused")
+	}}
+	uc := NewRoomUseCase(repo, forum)
+
+	_, err := uc.TopicRoom(5)
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrTopicNotFound)
+	repo.AssertNotCalled(t, "CreateTopicRoom", mock.Anything, mock.Anything)
+}
+
+func TestRoomUseCase_SetRetention(t *testing.T) {
+	owner := &authmw.Principal{UserID: 7, Role: "user"}
+	member := &authmw.Principal{UserID: 8, Role: "user"}
+	admin := &authmw.Principal{UserID: 1, Role: authmw.RoleAdmin}
+
+	tests := []struct {
+		name    string
+		user    *authmw.Principal
+		policy  entity.RetentionPolicy
+		saved   entity.RetentionPolicy
+		wantErr error
+	}{
+		{
+			name:   "Owner sets days",
+			user:   owner,
+			policy: entity.RetentionPolicy{Mode: entity.RetentionDays, Value: 30, Archive: true},
+			saved:  entity.RetentionPolicy{Mode: entity.RetentionDays, Value: 30, Archive: true},
+		},
+		{
+			name:   "Forever ignores value",
+			user:   owner,
+			policy: entity.RetentionPolicy{Mode: entity.RetentionForever, Value: 5},
+			saved:  entity.RetentionPolicy{Mode: entity.RetentionForever},
+		},
+		{
+			name:   "Admin without membership",
+			user:   admin,
+			policy: entity.RetentionPolicy{Mode: entity.RetentionCount, Value: 1000},
+			saved:  entity.RetentionPolicy{Mode: entity.RetentionCount, Value: 1000},
+		},
+		{
+			name:    "Member",
+			user:    member,
+			policy:  entity.RetentionPolicy{Mode: entity.RetentionCount, Value: 1000},
+			wantErr: ErrRoomAccessDenied,
+		},
+		{name: "Unknown mode", user: owner, policy: entity.RetentionPolicy{Mode: "weeks", Value: 1}, wantErr: ErrInvalidRetention},
+		{name: "Zero days", user: owner, policy: entity.RetentionPolicy{Mode: entity.RetentionDays}, wantErr: ErrInvalidRetention},
+		{name: "Too many days", user: owner, policy: entity.RetentionPolicy{Mode: entity.RetentionDays, Value: maxRetentionDays + 1}, wantErr: ErrInvalidRetention},
+		{name: "Negative count", user: owner, policy: entity.RetentionPolicy{Mode: entity.RetentionCount, Value: -1}, wantErr: ErrInvalidRetention},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockRoomRepository)
+			repo.On("GetRoom", int64(2)).Return(privateRoom, nil)
+			repo.On("GetMemberRole", int64(2), int64(7)).Return(entity.RoomRoleOwner, nil)
+			repo.On("GetMemberRole", int64(2), int64(8)).Return(entity.RoomRoleMember, nil)
+			repo.On("SetRetention", int64(2), tt.saved).Return(nil)
+			uc := NewRoomUseCase(repo, nil)
+
+			_, err := uc.SetRetention(2, tt.user, tt.policy)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				repo.AssertNotCalled(t, "SetRetention", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			repo.AssertCalled(t, "SetRetention", int64(2), tt.saved)
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package mocks
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -18,7 +19,10 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -26,6 +30,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
 )
 
 type MessageIntegrationTestSuite struct {
@@ -67,8 +72,11 @@ func (suite *MessageIntegrationTestSuite) SetupSuite() {
 	_, err = suite.db.Exec(`
         CREATE TABLE IF NOT EXISTS chat_messages (
             id SERIAL PRIMARY KEY,
+            room_id INT NOT NULL DEFAULT 1,
+            user_id BIGINT,
             username VARCHAR(255) NOT NULL,
-            content TEXT NOT NULL
+            content TEXT NOT NULL,
+            timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
         )
     `)
 	if err != nil {
@@ -76,7 +84,7 @@ func (suite *MessageIntegrationTestSuite) SetupSuite() {
 	}
 
 	suite.repo = repository.NewMessageRepository(suite.db)
-	suite.messageUC = usecase.NewMessageUseCase(suite.repo)
+	suite.messageUC = usecase.NewMessageUseCase(suite.repo, nil, nil)
 }
 
 func (suite *MessageIntegrationTestSuite) TearDownSuite() {
@@ -135,14 +143,15 @@ func (suite *MessageIntegrationTestSuite) TestSaveMessage() {
 			_, err := suite.db.Exec("DELETE FROM chat_messages")
 			assert.NoError(suite.T(), err)
 
-			err = suite.messageUC.SaveMessage(tt.message)
+			msg := tt.message
+			err = suite.messageUC.SaveMessage(&msg)
 			if tt.expectError {
 				assert.Error(suite.T(), err)
 			} else {
 				assert.NoError(suite.T(), err)
 			}
 
-			messages, err := suite.repo.GetMessages()
+			messages, err := suite.repo.GetMessages(entity.HistoryQuery{RoomID: entity.GeneralRoomID, Limit: usecase.DefaultPageSize})
 			assert.NoError(suite.T(), err)
 			assert.Len(suite.T(), messages, 1, "Должно быть ровно одно сообщение в базе")
 			assert.Equal(suite.T(), tt.message.Username, messages[0].Username)
@@ -158,12 +167,13 @@ func (suite *MessageIntegrationTestSuite) TestGetMessages() {
 	}
 
 	for _, msg := range messagesToSave {
-		err := suite.repo.SaveMessage(msg)
+		err := suite.repo.SaveMessage(&msg)
 		assert.NoError(suite.T(), err)
 	}
 
-	messages, err := suite.messageUC.GetMessages()
+	page, err := suite.messageUC.GetMessages(entity.HistoryQuery{RoomID: entity.GeneralRoomID})
 	assert.NoError(suite.T(), err)
+	messages := page.Messages
 	assert.Len(suite.T(), messages, len(messagesToSave))
 
 	for i, msg := range messages {
@@ -180,11 +190,14 @@ func (suite *MessageIntegrationTestSuite) TestMessageFlow() {
 		Message:  "Integration test message",
 	}
 
-	err := suite.messageUC.SaveMessage(testMsg)
+	err := suite.messageUC.SaveMessage(&testMsg)
 	assert.NoError(suite.T(), err)
+	assert.NotZero(suite.T(), testMsg.ID)
+	assert.False(suite.T(), testMsg.Timestamp.IsZero())
 
-	messages, err := suite.messageUC.GetMessages()
+	page, err := suite.messageUC.GetMessages(entity.HistoryQuery{RoomID: entity.GeneralRoomID})
 	assert.NoError(suite.T(), err)
+	messages := page.Messages
 	assert.Len(suite.T(), messages, 1)
 	assert.Equal(suite.T(), testMsg.Username, messages[0].Username)
 	assert.Equal(suite.T(), testMsg.Message, messages[0].Message)
@@ -198,10 +211,11 @@ func TestGetMessages_EdgeCases(t *testing.T) {
 	repo := repository.NewMessageRepository(db)
 
 	t.Run("empty result", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, username, content FROM chat_messages").
-			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "content"}))
+		mock.ExpectQuery("SELECT id, room_id, user_id, username, content, timestamp FROM chat_messages").
+			WithArgs(entity.GeneralRoomID, 50).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "room_id", "user_id", "username", "content", "timestamp"}))
 
-		messages, err := repo.GetMessages()
+		messages, err := repo.GetMessages(entity.HistoryQuery{RoomID: entity.GeneralRoomID, Limit: 50})
 		require.NoError(t, err)
 		require.Empty(t, messages)
 	})
@@ -209,10 +223,10 @@ func TestGetMessages_EdgeCases(t *testing.T) {
 	t.Run("scan error", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{"id", "username"}).
 			AddRow(1, "user1")
-		mock.ExpectQuery("SELECT id, username, content FROM chat_messages").
+		mock.ExpectQuery("SELECT id, room_id, user_id, username, content, timestamp FROM chat_messages").
 			WillReturnRows(rows)
 
-		_, err := repo.GetMessages()
+		_, err := repo.GetMessages(entity.HistoryQuery{RoomID: entity.GeneralRoomID, Limit: 50})
 		require.Error(t, err)
 	})
 }
@@ -220,20 +234,22 @@ func TestGetMessages_EdgeCases(t *testing.T) {
 func TestMessageHandler(t *testing.T) {
 
 	mockUC := &mockMessageUseCase{
-		saveFunc: func(msg entity.Message) error {
+		saveFunc: func(msg *entity.Message) error {
 			return nil
 		},
-		getMessagesFunc: func() ([]entity.Message, error) {
-			return []entity.Message{
+		getMessagesFunc: func(query entity.HistoryQuery) (*entity.HistoryPage, error) {
+			return &entity.HistoryPage{Messages: []entity.Message{
 				{ID: 1, Username: "user1", Message: "Hello"},
 				{ID: 2, Username: "user2", Message: "Hi there"},
-			}, nil
+			}}, nil
 		},
 	}
 
-	h := &handler.MessageHandler{
-		Uc: mockUC,
-	}
+	hub := myWeb.NewHub()
+	go hub.Run()
+	defer hub.Shutdown(context.Background())
+
+	h := handler.NewMessageHandler(mockUC, &mockRoomUseCase{}, hub, authmw.NewAuthenticator(nil, &mockAuthClient{}), nil)
 
 	t.Run("GetMessages success", func(t *testing.T) {
 		router := gin.Default()
@@ -253,12 +269,12 @@ func TestMessageHandler(t *testing.T) {
 
 	t.Run("GetMessages database error", func(t *testing.T) {
 		errorUC := &mockMessageUseCase{
-			getMessagesFunc: func() ([]entity.Message, error) {
+			getMessagesFunc: func(query entity.HistoryQuery) (*entity.HistoryPage, error) {
 				return nil, errors.New("database error")
 			},
 		}
 
-		errorHandler := handler.NewMessageHandler(errorUC)
+		errorHandler := handler.NewMessageHandler(errorUC, &mockRoomUseCase{}, hub, authmw.NewAuthenticator(nil, &mockAuthClient{}), nil)
 
 		router := gin.Default()
 		router.GET("/messages", errorHandler.GetMessages)
@@ -271,9 +287,6 @@ func TestMessageHandler(t *testing.T) {
 	})
 
 	t.Run("HandleConnections websocket upgrade", func(t *testing.T) {
-
-		myWeb.Clients = make(map[*websocket.Conn]bool)
-
 		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 			c, _ := gin.CreateTestContext(w)
@@ -283,24 +296,23 @@ func TestMessageHandler(t *testing.T) {
 		defer s.Close()
 
 		u := "ws" + strings.TrimPrefix(s.URL, "http")
-		ws, _, err := websocket.DefaultDialer.Dial(u+"/ws", nil)
+		ws, _, err := websocket.DefaultDialer.Dial(u+"/ws?token=valid_token", nil)
 		require.NoError(t, err)
 		defer ws.Close()
 
-		assert.Equal(t, 1, len(myWeb.Clients))
+		require.Eventually(t, func() bool { return hub.ClientCount() == 1 }, time.Second, 10*time.Millisecond)
 
 		testMsg := entity.Message{Username: "test", Message: "hello"}
 		err = ws.WriteJSON(testMsg)
 		require.NoError(t, err)
 
+		var echoed entity.Message
+		err = ws.ReadJSON(&echoed)
+		require.NoError(t, err)
 		assert.Equal(t, 1, mockUC.saveCount)
 	})
 
-	t.Run("HandleMessages broadcast", func(t *testing.T) {
-
-		myWeb.Clients = make(map[*websocket.Conn]bool)
-		myWeb.Broadcast = make(chan entity.Message)
-
+	t.Run("Hub broadcast", func(t *testing.T) {
 		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			c, _ := gin.CreateTestContext(w)
 			c.Request = r
@@ -309,18 +321,18 @@ func TestMessageHandler(t *testing.T) {
 		defer s.Close()
 
 		u := "ws" + strings.TrimPrefix(s.URL, "http")
-		ws1, _, err := websocket.DefaultDialer.Dial(u+"/ws", nil)
+		ws1, _, err := websocket.DefaultDialer.Dial(u+"/ws?token=valid_token", nil)
 		require.NoError(t, err)
 		defer ws1.Close()
 
-		ws2, _, err := websocket.DefaultDialer.Dial(u+"/ws", nil)
+		ws2, _, err := websocket.DefaultDialer.Dial(u+"/ws?token=valid_token", nil)
 		require.NoError(t, err)
 		defer ws2.Close()
 
-		go h.HandleMessages()
+		require.Eventually(t, func() bool { return hub.ClientCount() == 2 }, time.Second, 10*time.Millisecond)
 
 		broadcastMsg := entity.Message{Username: "system", Message: "broadcast"}
-		myWeb.Broadcast <- broadcastMsg
+		require.NoError(t, hub.Broadcast(broadcastMsg))
 
 		var msg1, msg2 entity.Message
 		err = ws1.ReadJSON(&msg1)
@@ -333,14 +345,22 @@ func TestMessageHandler(t *testing.T) {
 	})
 }
 
+type mockAuthClient struct {
+	pb.AuthServiceClient
+}
+
+func (m *mockAuthClient) ValidateToken(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
+	return &pb.ValidateTokenResponse{Valid: in.Token == "valid_token", UserId: 1, Username: "test"}, nil
+}
+
 type mockMessageUseCase struct {
 	usecase.MessageUseCase
-	saveFunc        func(entity.Message) error
-	getMessagesFunc func() ([]entity.Message, error)
+	saveFunc        func(*entity.Message) error
+	getMessagesFunc func(entity.HistoryQuery) (*entity.HistoryPage, error)
 	saveCount       int
 }
 
-func (m *mockMessageUseCase) SaveMessage(msg entity.Message) error {
+func (m *mockMessageUseCase) SaveMessage(msg *entity.Message) error {
 	m.saveCount++
 	if m.saveFunc != nil {
 		return m.saveFunc(msg)
@@ -348,11 +368,24 @@ func (m *mockMessageUseCase) SaveMessage(msg entity.Message) error {
 	return nil
 }
 
-func (m *mockMessageUseCase) GetMessages() ([]entity.Message, error) {
+func (m *mockMessageUseCase) GetMessages(query entity.HistoryQuery) (*entity.HistoryPage, error) {
 	if m.getMessagesFunc != nil {
-		return m.getMessagesFunc()
+		return m.getMessagesFunc(query)
 	}
-	return nil, nil
+	return &entity.HistoryPage{Messages: []entity.Message{}}, nil
+}
+
+// mockRoomUseCase пускает в любую комнату.
+type mockRoomUseCase struct {
+	usecase.RoomUseCase
+}
+
+func (m *mockRoomUseCase) Join(roomID, userID int64) error {
+	return nil
+}
+
+func (m *mockRoomUseCase) Leave(roomID, userID int64) error {
+	return nil
 }
 
 // // internal/mocks/message_integration_test.go
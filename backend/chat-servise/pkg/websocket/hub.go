@@ -0,0 +1,400 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrHubClosed возвращается после Shutdown.
+var ErrHubClosed = errors.New("hub is closed")
+
+const (
+	defaultWriteWait  = 10 * time.Second
+	defaultPongWait   = 60 * time.Second
+	defaultSendBuffer = 256
+	maxMessageSize    = 8 * 1024
+)
+
+// Hub хранит подключённых клиентов и рассылает им сообщения — всем,
+// подписчикам комнаты или всем соединениям пользователя. Списки клиентов и подписок меняются только в
+// горутине Run, поэтому обходятся без блокировок.
+//
+// Каждый клиент получает сообщения через свою буферизованную очередь, которую
+// разбирает отдельная горутина записи. Если очередь переполнена, клиент
+// отключается: медленный читатель не должен задерживать остальных.
+type Hub struct {
+	register   chan *Client
+	unregister chan *Client
+	broadcast  chan []byte
+	roomcast   chan roomMessage
+	usercast   chan userMessage
+	direct     chan directMessage
+	subscribe  chan subscription
+	done       chan struct{}
+	stopped    chan struct{}
+	closeOnce  sync.Once
+	writers    sync.WaitGroup
+
+	clients map[*Client]bool
+	rooms   map[int64]map[*Client]bool
+	users   map[int64]map[*Client]bool
+	count   atomic.Int64
+
+	writeWait  time.Duration
+	pongWait   time.Duration
+	pingPeriod time.Duration
+	sendBuffer int
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		broadcast:  make(chan []byte),
+		roomcast:   make(chan roomMessage),
+		usercast:   make(chan userMessage),
+		direct:     make(chan directMessage),
+		subscribe:  make(chan subscription),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+		clients:    make(map[*Client]bool),
+		rooms:      make(map[int64]map[*Client]bool),
+		users:      make(map[int64]map[*Client]bool),
+		writeWait:  defaultWriteWait,
+		pongWait:   defaultPongWait,
+		pingPeriod: defaultPongWait * 9 / 10,
+		sendBuffer: defaultSendBuffer,
+	}
+}
+
+// Client — одно WebSocket-соединение пользователя.
+type Client struct {
+	UserID   int64
+	Username string
+
+	hub  *Hub
+	conn *websocket.Conn
+	send chan []byte
+	// rooms — подписки клиента; меняются только в Run.
+	rooms map[int64]bool
+}
+
+type roomMessage struct {
+	roomID int64
+	data   []byte
+}
+
+type userMessage struct {
+	userID int64
+	data   []byte
+}
+
+type directMessage struct {
+	client *Client
+	data   []byte
+}
+
+type subscription struct {
+	client *Client
+	roomID int64
+	join   bool
+}
+
+// Run обслуживает регистрацию и рассылку до вызова Shutdown.
+func (h *Hub) Run() {
+	defer close(h.stopped)
+
+	for {
+		select {
+		case c := <-h.register:
+			h.clients[c] = true
+			if h.users[c.UserID] == nil {
+				h.users[c.UserID] = make(map[*Client]bool)
+			}
+			h.users[c.UserID][c] = true
+			for roomID := range c.rooms {
+				h.join(c, roomID)
+			}
+			// Add здесь, а не в Serve: после выхода из Run новых Add не будет,
+			// и Shutdown может безопасно ждать writers.
+			h.writers.Add(1)
+		case c := <-h.unregister:
+			h.remove(c)
+		case msg := <-h.broadcast:
+			for c := range h.clients {
+				h.enqueue(c, msg)
+			}
+		case msg := <-h.roomcast:
+			for c := range h.rooms[msg.roomID] {
+				h.enqueue(c, msg.data)
+			}
+		case msg := <-h.usercast:
+			for c := range h.users[msg.userID] {
+				h.enqueue(c, msg.data)
+			}
+		case msg := <-h.direct:
+			if h.clients[msg.client] {
+				h.enqueue(msg.client, msg.data)
+			}
+		case sub := <-h.subscribe:
+			if !h.clients[sub.client] {
+				break
+			}
+			if sub.join {
+				h.join(sub.client, sub.roomID)
+			} else {
+				h.leave(sub.client, sub.roomID)
+			}
+		case <-h.done:
+			for c := range h.clients {
+				h.remove(c)
+			}
+			h.count.Store(0)
+			return
+		}
+		h.count.Store(int64(len(h.clients)))
+	}
+}
+
+// enqueue ставит сообщение в очередь клиента. Если очередь переполнена,
+// клиент отключается.
+func (h *Hub) enqueue(c *Client, msg []byte) {
+	select {
+	case c.send <- msg:
+	default:
+		h.remove(c)
+	}
+}
+
+func (h *Hub) join(c *Client, roomID int64) {
+	c.rooms[roomID] = true
+	if h.rooms[roomID] == nil {
+		h.rooms[roomID] = make(map[*Client]bool)
+	}
+	h.rooms[roomID][c] = true
+}
+
+func (h *Hub) leave(c *Client, roomID int64) {
+	delete(c.rooms, roomID)
+	delete(h.rooms[roomID], c)
+	if len(h.rooms[roomID]) == 0 {
+		delete(h.rooms, roomID)
+	}
+}
+
+// remove закрывает очередь клиента; горутина записи отправит close-фрейм и
+// закроет соединение.
+func (h *Hub) remove(c *Client) {
+	if h.clients[c] {
+		for roomID := range c.rooms {
+			h.leave(c, roomID)
+		}
+		delete(h.users[c.UserID], c)
+		if len(h.users[c.UserID]) == 0 {
+			delete(h.users, c.UserID)
+		}
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+// ClientCount возвращает число подключённых клиентов.
+func (h *Hub) ClientCount() int {
+	return int(h.count.Load())
+}
+
+// Broadcast отправляет v в JSON всем клиентам. Сообщение кодируется один раз.
+func (h *Hub) Broadcast(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case h.broadcast <- data:
+		return nil
+	case <-h.done:
+		return ErrHubClosed
+	}
+}
+
+// BroadcastRoom отправляет v в JSON подписчикам комнаты.
+func (h *Hub) BroadcastRoom(roomID int64, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case h.roomcast <- roomMessage{roomID: roomID, data: data}:
+		return nil
+	case <-h.done:
+		return ErrHubClosed
+	}
+}
+
+// SendUser отправляет v в JSON всем соединениям пользователя. Если
+// пользователь не подключён, сообщение отбрасывается.
+func (h *Hub) SendUser(userID int64, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case h.usercast <- userMessage{userID: userID, data: data}:
+		return nil
+	case <-h.done:
+		return ErrHubClosed
+	}
+}
+
+// Send отправляет v в JSON одному клиенту.
+func (h *Hub) Send(c *Client, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case h.direct <- directMessage{client: c, data: data}:
+		return nil
+	case <-h.done:
+		return ErrHubClosed
+	}
+}
+
+// Join подписывает клиента на сообщения комнаты. Права на комнату должен
+// проверить вызывающий.
+func (h *Hub) Join(c *Client, roomID int64) error {
+	return h.changeSubscription(subscription{client: c, roomID: roomID, join: true})
+}
+
+// Leave отписывает клиента от комнаты.
+func (h *Hub) Leave(c *Client, roomID int64) error {
+	return h.changeSubscription(subscription{client: c, roomID: roomID})
+}
+
+func (h *Hub) changeSubscription(sub subscription) error {
+	select {
+	case h.subscribe <- sub:
+		return nil
+	case <-h.done:
+		return ErrHubClosed
+	}
+}
+
+// Serve регистрирует соединение, подписывает его на rooms и обслуживает до
+// отключения клиента. Запись идёт в отдельной горутине, чтение — в
+// вызывающей; onMessage вызывается для каждого входящего сообщения по очереди.
+func (h *Hub) Serve(conn *websocket.Conn, userID int64, username string, rooms []int64, onMessage func(*Client, []byte)) error {
+	c := &Client{
+		UserID:   userID,
+		Username: username,
+		hub:      h,
+		conn:     conn,
+		send:     make(chan []byte, h.sendBuffer),
+		rooms:    make(map[int64]bool, len(rooms)),
+	}
+	for _, roomID := range rooms {
+		c.rooms[roomID] = true
+	}
+
+	select {
+	case h.register <- c:
+	case <-h.done:
+		conn.Close()
+		return ErrHubClosed
+	}
+
+	go c.writePump()
+
+	c.readPump(onMessage)
+
+	select {
+	case h.unregister <- c:
+	case <-h.stopped:
+	}
+	return nil
+}
+
+// Shutdown отключает всех клиентов и ждёт, пока горутины записи отправят
+// close-фреймы, или пока не истечёт ctx.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.closeOnce.Do(func() { close(h.done) })
+
+	select {
+	case <-h.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) readPump(onMessage func(*Client, []byte)) {
+	defer c.conn.Close()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(c.hub.pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.hub.pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		onMessage(c, data)
+	}
+}
+
+// writePump — единственная горутина, которая пишет в соединение (кроме
+// управляющих фреймов, которые gorilla/websocket отправляет безопасно).
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.hub.pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+		c.hub.writers.Done()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(c.hub.writeWait))
+			if !ok {
+				// Хаб отключил клиента: остановка сервера или переполненная очередь.
+				c.conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.hub.writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer поднимает хаб, который рассылает всем каждое входящее сообщение.
+func newTestServer(t *testing.T, hub *Hub) string {
+	upgrader := NewUpgrader(nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.Serve(conn, 1, "test", nil, func(c *Client, data []byte) {
+			hub.Broadcast(json.RawMessage(data))
+		})
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func startHub(t *testing.T, hub *Hub) {
+	go hub.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		hub.Shutdown(ctx)
+	})
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func waitForClients(t *testing.T, hub *Hub, n int) {
+	require.Eventually(t, func() bool { return hub.ClientCount() == n }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestHub_BroadcastToManyClients(t *testing.T) {
+	const clients = 200
+
+	hub := NewHub()
+	startHub(t, hub)
+	url := newTestServer(t, hub)
+
+	conns := make([]*websocket.Conn, clients)
+	for i := range conns {
+		conns[i] = dial(t, url)
+	}
+	waitForClients(t, hub, clients)
+
+	// Каждый клиент пишет одно сообщение, и каждый должен получить все.
+	var wg sync.WaitGroup
+	received := make([]map[string]bool, clients)
+	for i, conn := range conns {
+		wg.Add(1)
+		go func(i int, conn *websocket.Conn) {
+			defer wg.Done()
+			require.NoError(t, conn.WriteJSON(fmt.Sprintf("from-%d", i)))
+
+			received[i] = make(map[string]bool, clients)
+			conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+			for len(received[i]) < clients {
+				var msg string
+				if err := conn.ReadJSON(&msg); err != nil {
+					t.Errorf("client %d: %v", i, err)
+					return
+				}
+				received[i][msg] = true
+			}
+		}(i, conn)
+	}
+	wg.Wait()
+
+	for i := range received {
+		assert.Len(t, received[i], clients, "client %d", i)
+	}
+}
+
+func TestHub_UnregistersDisconnectedClients(t *testing.T) {
+	hub := NewHub()
+	startHub(t, hub)
+	url := newTestServer(t, hub)
+
+	conns := make([]*websocket.Conn, 50)
+	for i := range conns {
+		conns[i] = dial(t, url)
+	}
+	waitForClients(t, hub, len(conns))
+
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func(conn *websocket.Conn) {
+			defer wg.Done()
+			conn.Close()
+		}(conn)
+	}
+	wg.Wait()
+
+	waitForClients(t, hub, 0)
+}
+
+func TestHub_EvictsSlowConsumer(t *testing.T) {
+	hub := NewHub()
+	hub.sendBuffer = 1
+	startHub(t, hub)
+
+	// Клиент без горутины записи: очередь никто не разбирает.
+	slow := &Client{hub: hub, send: make(chan []byte, 1)}
+	hub.register <- slow
+	waitForClients(t, hub, 1)
+
+	require.NoError(t, hub.Broadcast("first"))
+	require.NoError(t, hub.Broadcast("second"))
+
+	waitForClients(t, hub, 0)
+	<-slow.send
+	_, ok := <-slow.send
+	assert.False(t, ok, "send queue must be closed")
+	// За клиента без writePump отмечаемся сами, иначе Shutdown будет его ждать.
+	hub.writers.Done()
+}
+
+// receive читает очередь клиента без writePump; ok == false, если за timeout
+// ничего не пришло.
+func receive(c *Client, timeout time.Duration) (string, bool) {
+	select {
+	case data := <-c.send:
+		var msg string
+		json.Unmarshal(data, &msg)
+		return msg, true
+	case <-time.After(timeout):
+		return "", false
+	}
+}
+
+func TestHub_BroadcastRoom(t *testing.T) {
+	hub := NewHub()
+	startHub(t, hub)
+
+	member := &Client{hub: hub, send: make(chan []byte, 8), rooms: map[int64]bool{1: true}}
+	outsider := &Client{hub: hub, send: make(chan []byte, 8), rooms: map[int64]bool{1: true}}
+	hub.register <- member
+	hub.register <- outsider
+	waitForClients(t, hub, 2)
+	// За клиентов без writePump отмечаемся сами.
+	defer hub.writers.Add(-2)
+
+	require.NoError(t, hub.Join(member, 2))
+	require.NoError(t, hub.BroadcastRoom(2, "room 2"))
+	require.NoError(t, hub.BroadcastRoom(1, "room 1"))
+
+	msg, ok := receive(member, time.Second)
+	require.True(t, ok)
+	assert.Equal(t, "room 2", msg)
+	msg, _ = receive(member, time.Second)
+	assert.Equal(t, "room 1", msg)
+	msg, _ = receive(outsider, time.Second)
+	assert.Equal(t, "room 1", msg, "outsider must not get room 2 messages")
+
+	require.NoError(t, hub.Leave(member, 2))
+	require.NoError(t, hub.BroadcastRoom(2, "after leave"))
+	_, ok = receive(member, 100*time.Millisecond)
+	assert.False(t, ok)
+
+	require.NoError(t, hub.Send(outsider, "direct"))
+	msg, _ = receive(outsider, time.Second)
+	assert.Equal(t, "direct", msg)
+	_, ok = receive(member, 100*time.Millisecond)
+	assert.False(t, ok)
+}
+
+func TestHub_SendUser(t *testing.T) {
+	hub := NewHub()
+	startHub(t, hub)
+
+	phone := &Client{UserID: 1, hub: hub, send: make(chan []byte, 8), rooms: map[int64]bool{}}
+	laptop := &Client{UserID: 1, hub: hub, send: make(chan []byte, 8), rooms: map[int64]bool{}}
+	other := &Client{UserID: 2, hub: hub, send: make(chan []byte, 8), rooms: map[int64]bool{}}
+	hub.register <- phone
+	hub.register <- laptop
+	hub.register <- other
+	waitForClients(t, hub, 3)
+	// За клиентов без writePump отмечаемся сами.
+	defer hub.writers.Add(-3)
+
+	require.NoError(t, hub.SendUser(1, "for user 1"))
+	for _, c := range []*Client{phone, laptop} {
+		msg, ok := receive(c, time.Second)
+		require.True(t, ok)
+		assert.Equal(t, "for user 1", msg)
+	}
+	_, ok := receive(other, 100*time.Millisecond)
+	assert.False(t, ok)
+
+	// После отключения последнего соединения пользователь забывается.
+	hub.unregister <- phone
+	hub.unregister <- laptop
+	waitForClients(t, hub, 1)
+	require.NoError(t, hub.SendUser(1, "nobody"))
+	require.NoError(t, hub.SendUser(2, "for user 2"))
+	msg, _ := receive(other, time.Second)
+	assert.Equal(t, "for user 2", msg)
+}
+
+func TestHub_ClosesIdleConnectionWithoutPong(t *testing.T) {
+	hub := NewHub()
+	hub.pongWait = 200 * time.Millisecond
+	hub.pingPeriod = time.Hour
+	startHub(t, hub)
+	url := newTestServer(t, hub)
+
+	// Клиент ничего не читает, поэтому и не отвечает на ping.
+	dial(t, url)
+	waitForClients(t, hub, 1)
+
+	waitForClients(t, hub, 0)
+}
+
+func TestHub_PingKeepsConnectionAlive(t *testing.T) {
+	hub := NewHub()
+	hub.pongWait = 300 * time.Millisecond
+	hub.pingPeriod = 50 * time.Millisecond
+	startHub(t, hub)
+	url := newTestServer(t, hub)
+
+	conn := dial(t, url)
+	pings := make(chan struct{}, 100)
+	conn.SetPingHandler(func(data string) error {
+		pings <- struct{}{}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	// Чтение нужно, чтобы обрабатывались управляющие фреймы.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	waitForClients(t, hub, 1)
+
+	time.Sleep(3 * hub.pongWait)
+
+	assert.Equal(t, 1, hub.ClientCount())
+	assert.NotEmpty(t, pings)
+}
+
+func TestHub_Shutdown(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	url := newTestServer(t, hub)
+
+	conns := make([]*websocket.Conn, 20)
+	for i := range conns {
+		conns[i] = dial(t, url)
+	}
+	waitForClients(t, hub, len(conns))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, hub.Shutdown(ctx))
+
+	for _, conn := range conns {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+	}
+
+	assert.ErrorIs(t, hub.Broadcast("late"), ErrHubClosed)
+	assert.Equal(t, 0, hub.ClientCount())
+
+	// Новые соединения после остановки сразу закрываются.
+	late := dial(t, url)
+	late.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := late.ReadMessage()
+	assert.Error(t, err)
+}
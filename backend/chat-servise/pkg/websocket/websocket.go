@@ -3,16 +3,27 @@ package websocket
 import (
 	"net/http"
 
-	"github.com/Mandarinka0707/newRepoGOODarhit/chat/internal/entity"
-
 	"github.com/gorilla/websocket"
 )
 
-var Upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-}
+// Subprotocol — подпротокол, которым клиент передаёт токен:
+// Sec-WebSocket-Protocol: bearer, <token>. Сервер отвечает только "bearer",
+// сам токен обратно не возвращается.
+const Subprotocol = "bearer"
 
-var Clients = make(map[*websocket.Conn]bool)
-var Broadcast = make(chan entity.Message)
+// NewUpgrader создаёт Upgrader, принимающий соединения только с перечисленных
+// origin. Запросы без заголовка Origin (не из браузера) пропускаются.
+func NewUpgrader(allowedOrigins []string) websocket.Upgrader {
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[origin] = true
+	}
+
+	return websocket.Upgrader{
+		Subprotocols: []string{Subprotocol},
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || allowed[origin]
+		},
+	}
+}
@@ -3,18 +3,23 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"backend.com/forum/authmw"
 	pb "backend.com/forum/proto"
 	_ "github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/docs"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/handler"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/storage"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -69,14 +74,69 @@ func main() {
 
 	// Инициализация репозиториев и usecases
 	authClient := pb.NewAuthServiceClient(authConn)
+	// Токены проверяются локально по ключам auth-сервиса, ValidateToken
+	// вызывается, только если нужного ключа нет.
+	authenticator := authmw.NewAuthenticator(
+		authmw.NewKeySet("http://localhost:8080/.well-known/jwks.json", 5*time.Minute),
+		authClient,
+	)
+	requireAuth := authenticator.Required()
+	optionalAuth := authenticator.Optional()
+	requireAdmin := authmw.RequireRole(authmw.RoleAdmin)
 	postRepo := repository.NewPostRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
+	notificationUC := usecase.NewNotificationUsecase(repository.NewNotificationRepository(db), log)
+	mentionUC := usecase.NewMentionUsecase(repository.NewMentionRepository(db), notificationUC, authClient, log)
 	postUsecase := usecase.NewPostUsecase(postRepo, authClient, log)
+	postUsecase.Mentions = mentionUC
+	postUsecase.Notifications = notificationUC
 	commentUC := usecase.NewCommentUseCase(commentRepo, postRepo, authClient)
+	commentUC.MaxDepth = commentMaxDepth(log)
+	commentUC.Mentions = mentionUC
+	commentUC.Notifications = notificationUC
+	categoryRepo := repository.NewCategoryRepository(db)
+	topicRepo := repository.NewTopicRepository(db)
+	messageRepo := repository.NewMessageRepository(db)
+	chatRepo := repository.NewChatMessageRepository(db)
+	categoryUC := usecase.NewCategoryUsecase(categoryRepo)
+	topicUC := usecase.NewTopicUsecase(topicRepo, categoryRepo)
+	messageUC := usecase.NewMessageUsecase(messageRepo, topicRepo)
+	chatUC := usecase.NewChatUsecase(chatRepo, authClient)
+	searchUC := usecase.NewSearchUsecase(repository.NewSearchRepository(db), authClient)
+	grace, purgeInterval := trashConfig(log)
+	trashUC := usecase.NewTrashUsecase(postRepo, commentRepo, grace, purgeInterval, log)
+	trashUC.Notifications = notificationUC
+	revisionUC := usecase.NewRevisionUsecase(postRepo, authClient)
+	revisionUC.Mentions = mentionUC
+	voteUC := usecase.NewVoteUsecase(postRepo, commentRepo)
+	reactionUC := usecase.NewReactionUsecase(postRepo, commentRepo, allowedReactions())
+	tagUC := usecase.NewTagUsecase(repository.NewTagRepository(db))
+	contentUC := usecase.NewContentUsecase(repository.NewContentRepository(db), log)
+	attachmentStore, err := attachmentStorage()
+	if err != nil {
+		log.Error("Failed to init attachment storage", err)
+		os.Exit(1)
+	}
+	attachmentUC := usecase.NewAttachmentUsecase(repository.NewAttachmentRepository(db), postRepo, attachmentStore, attachmentLimits(log), log)
+	trashUC.Attachments = attachmentUC
+
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	go trashUC.Run(purgeCtx)
+	go contentUC.Run(purgeCtx)
 
 	// Регистрация обработчиков
 	postHandler := handler.NewPostHandler(postUsecase, log)
 	commentHandler := handler.NewCommentHandler(commentUC)
+	categoryHandler := handler.NewCategoryHandler(categoryUC, topicUC, log)
+	searchHandler := handler.NewSearchHandler(searchUC, log)
+	trashHandler := handler.NewTrashHandler(trashUC, log)
+	revisionHandler := handler.NewRevisionHandler(revisionUC, log)
+	voteHandler := handler.NewVoteHandler(voteUC, log)
+	reactionHandler := handler.NewReactionHandler(reactionUC, log)
+	tagHandler := handler.NewTagHandler(tagUC, log)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentUC, log)
+	notificationHandler := handler.NewNotificationHandler(notificationUC, log)
 
 	// Группировка роутов
 	api := router.Group("/api/v1")
@@ -84,18 +144,91 @@ func main() {
 		// Роуты для постов
 		posts := api.Group("/posts")
 		{
-			posts.POST("", postHandler.CreatePost)
-			posts.GET("", postHandler.GetPosts)
-			posts.DELETE("/:id", postHandler.DeletePost)
-			posts.PUT("/:id", postHandler.UpdatePost)
+			posts.POST("", requireAuth, postHandler.CreatePost)
+			posts.GET("", optionalAuth, postHandler.GetPosts)
+			posts.DELETE("/:id", requireAuth, postHandler.DeletePost)
+			posts.PUT("/:id", requireAuth, postHandler.UpdatePost)
+			posts.POST("/:id/vote", requireAuth, voteHandler.VotePost)
+			posts.POST("/:id/reactions", requireAuth, reactionHandler.TogglePostReaction)
+			posts.POST("/:id/attachments", requireAuth, attachmentHandler.UploadAttachment)
+			posts.DELETE("/:id/attachments/:attachmentId", requireAuth, attachmentHandler.DeleteAttachment)
 		}
+		api.GET("/attachments/:id", attachmentHandler.GetAttachment)
+		api.GET("/attachments/:id/thumbnail", attachmentHandler.GetThumbnail)
 
 		// Роуты для комментариев
 		comments := api.Group("/posts/:id/comments")
 		{
-			comments.POST("", commentHandler.CreateComment)
-			comments.GET("", commentHandler.GetCommentsByPostID)
+			comments.POST("", requireAuth, commentHandler.CreateComment)
+			comments.GET("", optionalAuth, commentHandler.GetCommentsByPostID)
+			comments.PUT("/:commentId", requireAuth, commentHandler.UpdateComment)
+			comments.DELETE("/:commentId", requireAuth, commentHandler.DeleteComment)
+			comments.POST("/:commentId/vote", requireAuth, voteHandler.VoteComment)
+			comments.POST("/:commentId/reactions", requireAuth, reactionHandler.ToggleCommentReaction)
+		}
+		api.GET("/comments/:id/replies", optionalAuth, commentHandler.GetReplies)
+
+		// История правок постов, откат — только для админов
+		revisions := api.Group("/posts/:id/revisions")
+		{
+			revisions.GET("", revisionHandler.ListRevisions)
+			revisions.GET("/diff", revisionHandler.DiffRevisions)
+			revisions.GET("/:revision", revisionHandler.GetRevision)
+			revisions.POST("/:revision/rollback", requireAuth, requireAdmin, revisionHandler.RollbackPost)
 		}
+
+		// Корзина — только для админов
+		trash := api.Group("/trash", requireAuth, requireAdmin)
+		{
+			trash.GET("/posts", trashHandler.ListPosts)
+			trash.GET("/comments", trashHandler.ListComments)
+			trash.POST("/posts/:id/restore", trashHandler.RestorePost)
+			trash.POST("/comments/:id/restore", trashHandler.RestoreComment)
+		}
+
+		// Роуты для категорий
+		categories := api.Group("/categories")
+		{
+			categories.GET("", categoryHandler.GetCategories)
+			categories.POST("", requireAuth, requireAdmin, categoryHandler.CreateCategory)
+			categories.GET("/:id", categoryHandler.GetCategory)
+			categories.PUT("/:id", requireAuth, requireAdmin, categoryHandler.UpdateCategory)
+			categories.DELETE("/:id", requireAuth, requireAdmin, categoryHandler.DeleteCategory)
+			categories.GET("/:id/topics", categoryHandler.GetTopics)
+			categories.POST("/:id/topics", requireAuth, requireAdmin, categoryHandler.CreateTopic)
+		}
+
+		// Роуты для тем
+		topics := api.Group("/topics")
+		{
+			topics.GET("/:id", categoryHandler.GetTopic)
+			topics.PUT("/:id", requireAuth, requireAdmin, categoryHandler.UpdateTopic)
+			topics.DELETE("/:id", requireAuth, requireAdmin, categoryHandler.DeleteTopic)
+			topics.GET("/:id/posts", optionalAuth, postHandler.GetPostsByTopic)
+		}
+
+		// Теги; переименование и слияние — только для админов
+		tags := api.Group("/tags")
+		{
+			tags.GET("", tagHandler.ListTags)
+			tags.GET("/suggest", tagHandler.SuggestTags)
+			tags.PUT("/:name", requireAuth, requireAdmin, tagHandler.RenameTag)
+			tags.POST("/:name/merge", requireAuth, requireAdmin, tagHandler.MergeTag)
+		}
+
+		// Уведомления текущего пользователя
+		notifications := api.Group("/notifications", requireAuth)
+		{
+			notifications.GET("", notificationHandler.ListNotifications)
+			notifications.GET("/unread-count", notificationHandler.UnreadCount)
+			notifications.POST("/:id/read", notificationHandler.MarkRead)
+			notifications.POST("/read-all", notificationHandler.MarkAllRead)
+			notifications.GET("/preferences", notificationHandler.GetPreferences)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+		}
+
+		api.GET("/search", searchHandler.Search)
+		api.GET("/reactions", reactionHandler.ListReactions)
 	}
 
 	// Запуск сервера
@@ -113,6 +246,30 @@ func main() {
 
 	log.Info("Server started on :8081")
 
+	// gRPC сервер форума для внутренних сервисов
+	forumServer := handler.NewForumGRPCServer(categoryUC, topicUC, messageUC, postUsecase, chatUC, searchUC, log)
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(authenticator.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(authenticator.StreamServerInterceptor()),
+	)
+	pb.RegisterForumServiceServer(grpcServer, forumServer)
+
+	grpcAddr := grpcListenAddr()
+	lis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Error("Failed to listen for gRPC", err)
+		os.Exit(1)
+	}
+
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Error("gRPC server error", err)
+			os.Exit(1)
+		}
+	}()
+
+	log.Info("gRPC server started on " + grpcAddr)
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -124,6 +281,115 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Error("Server shutdown error", err)
 	}
+	grpcServer.GracefulStop()
+	stopPurge()
 
 	log.Info("Server stopped")
 }
+
+// grpcListenAddr — адрес gRPC-сервера из FORUM_GRPC_ADDR. По умолчанию
+// только localhost: API рассчитан на внутренние сервисы.
+func grpcListenAddr() string {
+	if addr := os.Getenv("FORUM_GRPC_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:50052"
+}
+
+// allowedReactions читает набор эмодзи для реакций из FORUM_REACTIONS
+// (через запятую). Без настройки используется usecase.DefaultReactions.
+func allowedReactions() []string {
+	value := os.Getenv("FORUM_REACTIONS")
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// commentMaxDepth читает допустимую вложенность ответов из
+// FORUM_COMMENT_MAX_DEPTH. Некорректное значение заменяется значением
+// по умолчанию.
+func commentMaxDepth(log *logger.Logger) int {
+	depth := usecase.DefaultMaxCommentDepth
+	if value := os.Getenv("FORUM_COMMENT_MAX_DEPTH"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			depth = n
+		} else {
+			log.Warnf("invalid FORUM_COMMENT_MAX_DEPTH %q, using %d", value, depth)
+		}
+	}
+	return depth
+}
+
+// trashConfig читает срок хранения удаленного в корзине
+// (FORUM_TRASH_GRACE_PERIOD, например "720h") и период очистки
+// (FORUM_TRASH_PURGE_INTERVAL). Некорректные значения заменяются
+// значениями по умолчанию.
+func trashConfig(log *logger.Logger) (time.Duration, time.Duration) {
+	grace := usecase.DefaultTrashGracePeriod
+	if value := os.Getenv("FORUM_TRASH_GRACE_PERIOD"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			grace = d
+		} else {
+			log.Warnf("invalid FORUM_TRASH_GRACE_PERIOD %q, using %s", value, grace)
+		}
+	}
+
+	interval := usecase.DefaultTrashPurgeInterval
+	if value := os.Getenv("FORUM_TRASH_PURGE_INTERVAL"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			interval = d
+		} else {
+			log.Warnf("invalid FORUM_TRASH_PURGE_INTERVAL %q, using %s", value, interval)
+		}
+	}
+	return grace, interval
+}
+
+// attachmentStorage выбирает хранилище вложений по FORUM_ATTACHMENT_STORAGE:
+// local (по умолчанию) — каталог FORUM_ATTACHMENT_DIR, s3 — бакет
+// FORUM_S3_BUCKET в FORUM_S3_ENDPOINT (ключи FORUM_S3_ACCESS_KEY и
+// FORUM_S3_SECRET_KEY, регион FORUM_S3_REGION).
+func attachmentStorage() (storage.Storage, error) {
+	switch driver := os.Getenv("FORUM_ATTACHMENT_STORAGE"); driver {
+	case "", "local":
+		dir := os.Getenv("FORUM_ATTACHMENT_DIR")
+		if dir == "" {
+			dir = "./data/attachments"
+		}
+		return storage.NewLocal(dir)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:  os.Getenv("FORUM_S3_ENDPOINT"),
+			Bucket:    os.Getenv("FORUM_S3_BUCKET"),
+			Region:    os.Getenv("FORUM_S3_REGION"),
+			AccessKey: os.Getenv("FORUM_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("FORUM_S3_SECRET_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown FORUM_ATTACHMENT_STORAGE %q", driver)
+	}
+}
+
+// attachmentLimits читает наибольший размер вложения в байтах для
+// пользователей (FORUM_ATTACHMENT_MAX_SIZE_USER) и админов
+// (FORUM_ATTACHMENT_MAX_SIZE_ADMIN). Некорректные значения заменяются
+// значениями по умолчанию.
+func attachmentLimits(log *logger.Logger) usecase.AttachmentLimits {
+	limits := usecase.DefaultAttachmentLimits()
+	for role, name := range map[string]string{
+		"user":           "FORUM_ATTACHMENT_MAX_SIZE_USER",
+		authmw.RoleAdmin: "FORUM_ATTACHMENT_MAX_SIZE_ADMIN",
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n > 0 {
+			limits[role] = n
+		} else {
+			log.Warnf("invalid %s %q, using %d", name, value, limits[role])
+		}
+	}
+	return limits
+}
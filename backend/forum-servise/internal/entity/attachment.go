@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// Attachment — файл, приложенный к посту. Сам файл лежит в хранилище под
+// StorageKey и отдается по URL; у картинок есть уменьшенная копия.
+type Attachment struct {
+	ID           int64     `json:"id" db:"id" example:"7"`
+	PostID       int64     `json:"post_id" db:"post_id" example:"123"`
+	UploaderID   int64     `json:"uploader_id" db:"uploader_id" example:"456"`
+	FileName     string    `json:"file_name" db:"file_name" example:"screenshot.png"`
+	ContentType  string    `json:"content_type" db:"content_type" example:"image/png"` // определен по содержимому
+	Size         int64     `json:"size" db:"size" example:"48213"`
+	StorageKey   string    `json:"-" db:"storage_key"`
+	ThumbnailKey string    `json:"-" db:"thumbnail_key"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at" example:"2023-01-01T00:00:00Z"`
+	URL          string    `json:"url" db:"-" example:"/api/v1/attachments/7"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty" db:"-" example:"/api/v1/attachments/7/thumbnail"`
+}
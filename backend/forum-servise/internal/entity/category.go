@@ -5,8 +5,9 @@ import (
 )
 
 type Category struct {
-	ID          int64     `db:"id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	CreatedAt   time.Time `db:"created_at"`
+	ID          int64     `json:"id" db:"id" example:"1"`
+	Name        string    `json:"name" db:"name" example:"Go"`
+	Description string    `json:"description" db:"description" example:"Всё о языке Go"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// GeneralChatRoomID — общая комната chat-servise, открытая всем.
+const GeneralChatRoomID int64 = 1
+
+// ChatMessage — строка таблицы chat_messages, общей с chat-servise.
+type ChatMessage struct {
+	ID        int64     `json:"id" db:"id"`
+	RoomID    int64     `json:"room_id" db:"room_id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	Content   string    `json:"content" db:"content"`
+	CreatedAt time.Time `json:"created_at" db:"timestamp"`
+}
@@ -3,11 +3,47 @@ package entity
 
 import "time"
 
+// Comment — комментарий к посту. У ответа заполнен ParentID, Depth — уровень
+// вложенности (0 у комментариев к самому посту). Replies содержит только
+// подгруженную часть ответов, их общее число — в ReplyCount. Edited
+// выставляется, если после создания текст хоть раз правили. У удаленного
+// комментария в ветке вместо текста и автора отдается заглушка. MyVote —
+// голос того, кто запросил комментарии (0, если не голосовал или аноним),
+// в Reactions его реакции отмечены Mine. Content — markdown автора,
+// ContentHTML — он же, отрендеренный в очищенный HTML.
 type Comment struct {
-	ID         int64     `json:"id" db:"id" example:"1"`
-	AuthorID   int64     `json:"author_id" db:"author_id" example:"1"`
-	PostID     int64     `json:"post_id" db:"post_id" example:"1"`
-	Content    string    `json:"content" db:"content" example:"текст комментария"`
-	CreatedAt  time.Time `db:"created_at"`
-	AuthorName string    `json:"author_name" db:"author_name"` // Исправлено db:"-"
+	ID          int64      `json:"id" db:"id" example:"1"`
+	AuthorID    int64      `json:"author_id" db:"author_id" example:"1"`
+	PostID      int64      `json:"post_id" db:"post_id" example:"1"`
+	ParentID    *int64     `json:"parent_id,omitempty" db:"parent_id" example:"1"`
+	Depth       int        `json:"depth" db:"depth" example:"0"`
+	Content     string     `json:"content" db:"content" example:"текст комментария"`
+	ContentHTML string     `json:"content_html" db:"content_html" example:"<p>текст комментария</p>"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	Edited      bool       `json:"edited" db:"edited"`
+	AuthorName  string     `json:"author_name" db:"author_name"` // Исправлено db:"-"
+	Deleted     bool       `json:"deleted" db:"deleted"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy   *int64     `json:"deleted_by,omitempty" db:"deleted_by"`
+	Score       int        `json:"score" db:"score" example:"0"`
+	MyVote      int        `json:"my_vote" db:"-" example:"0"`
+	Reactions   []Reaction `json:"reactions" db:"-"`
+	ReplyCount  int        `json:"reply_count" db:"reply_count" example:"0"`
+	Replies     []*Comment `json:"replies,omitempty" db:"-"`
+}
+
+// CommentTreeQuery — страница комментариев одного уровня. Depth — сколько
+// уровней ответов под каждым из них раскрыть в ответе. ViewerID — кто
+// смотрит, для его голосов; 0 у анонима.
+type CommentTreeQuery struct {
+	Depth    int
+	Limit    int
+	Offset   int
+	ViewerID int64
+}
+
+type CommentPage struct {
+	Comments []*Comment
+	Total    int64
 }
@@ -0,0 +1,8 @@
+package entity
+
+// ContentSource — markdown-текст поста или комментария, для которого
+// нужно заново построить content_html.
+type ContentSource struct {
+	ID      int64  `db:"id"`
+	Content string `db:"content"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// Message — сообщение внутри темы форума.
+type Message struct {
+	ID        int64     `json:"id" db:"id"`
+	TopicID   int64     `json:"topic_id" db:"topic_id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Content   string    `json:"content" db:"content"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"time"
+
+	"backend.com/forum/notify"
+)
+
+// Типы уведомлений.
+const (
+	// NotificationComment — новый комментарий к посту пользователя.
+	NotificationComment = "comment"
+	// NotificationReply — ответ на комментарий пользователя.
+	NotificationReply = "reply"
+	// NotificationMention — упоминание @username; тот же тип создает чат.
+	NotificationMention = notify.TypeMention
+	// NotificationModeration — администратор удалил или восстановил пост
+	// или комментарий пользователя; что именно произошло, видно по Action.
+	NotificationModeration = "moderation"
+)
+
+// NotificationTypes — все типы уведомлений. Каждый тип можно отключить.
+var NotificationTypes = []string{NotificationComment, NotificationReply, NotificationMention, NotificationModeration}
+
+// Действия модератора в уведомлениях NotificationModeration.
+const (
+	ModerationPostDeleted     = "post_deleted"
+	ModerationPostRestored    = "post_restored"
+	ModerationCommentDeleted  = "comment_deleted"
+	ModerationCommentRestored = "comment_restored"
+)
+
+// Subject — запись, к которой относится упоминание или уведомление: пост,
+// комментарий (заполнены PostID и CommentID) или сообщение чата (RoomID и
+// MessageID).
+type Subject struct {
+	PostID    *int64 `json:"post_id,omitempty" db:"post_id" example:"123"`
+	CommentID *int64 `json:"comment_id,omitempty" db:"comment_id" example:"45"`
+	RoomID    *int64 `json:"room_id,omitempty" db:"room_id" example:"1"`
+	MessageID *int64 `json:"message_id,omitempty" db:"message_id" example:"10"`
+}
+
+// Notification — уведомление пользователя UserID о действии ActorID.
+type Notification struct {
+	ID        int64  `json:"id" db:"id" example:"1"`
+	UserID    int64  `json:"user_id" db:"user_id" example:"456"`
+	Type      string `json:"type" db:"type" example:"mention"`
+	ActorID   int64  `json:"actor_id" db:"actor_id" example:"42"`
+	ActorName string `json:"actor_name" db:"actor_name" example:"john_doe"`
+	Action    string `json:"action,omitempty" db:"action" example:"post_deleted"`
+	Subject
+	CreatedAt time.Time  `json:"created_at" db:"created_at" example:"2023-01-01T00:00:00Z"`
+	ReadAt    *time.Time `json:"read_at,omitempty" db:"read_at"`
+}
+
+// NotificationQuery — страница уведомлений пользователя.
+type NotificationQuery struct {
+	UserID     int64
+	UnreadOnly bool
+	Limit      int
+	Offset     int
+}
+
+// NotificationPage — страница уведомлений, их общее число и число
+// непрочитанных.
+type NotificationPage struct {
+	Notifications []*Notification
+	Total         int64
+	Unread        int64
+}
+
+// NotificationPreference — включены ли у пользователя уведомления типа Type.
+type NotificationPreference struct {
+	Type    string `json:"type" db:"type" binding:"required" example:"mention"`
+	Enabled bool   `json:"enabled" db:"enabled" example:"false"`
+}
+
+// Mention — пользователь, упомянутый в тексте через @username.
+type Mention struct {
+	UserID   int64  `json:"user_id" db:"user_id" example:"456"`
+	Username string `json:"username" db:"username" example:"jane"`
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PostCursor — позиция в ленте постов, отсортированной по (created_at, id)
+// по убыванию. Клиенту отдается в виде непрозрачной строки.
+type PostCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+func PostCursorOf(post *Post) PostCursor {
+	return PostCursor{CreatedAt: post.CreatedAt, ID: post.ID}
+}
+
+func (c PostCursor) String() string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixMicro(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParsePostCursor(s string) (*PostCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	ts, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	micros, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	postID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || postID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &PostCursor{CreatedAt: time.UnixMicro(micros).UTC(), ID: postID}, nil
+}
+
+// Порядок ленты: new — по времени создания, top — по счету за период
+// с Since, hot — по счету с поправкой на возраст поста.
+const (
+	PostSortNew = "new"
+	PostSortTop = "top"
+	PostSortHot = "hot"
+)
+
+// PostListQuery описывает одну страницу ленты. Если задан Cursor, посты
+// выбираются строго после него, иначе пропускаются первые Offset постов.
+// Курсор есть только у сортировки new. ViewerID — кто смотрит ленту,
+// для его голосов; 0 у анонима. Tags оставляет посты с этими тегами,
+// TagMode — TagMatchAll или TagMatchAny.
+type PostListQuery struct {
+	Cursor   *PostCursor
+	Offset   int
+	Limit    int
+	Sort     string
+	Since    *time.Time
+	ViewerID int64
+	Tags     []string
+	TagMode  string
+}
+
+type PostPage struct {
+	Posts      []*Post
+	Total      int64
+	NextCursor string
+}
@@ -3,9 +3,19 @@ package entity
 import "time"
 
 type Post struct {
-	ID        int64     `json:"id" db:"id" example:"123"`
-	Title     string    `json:"title" db:"title" example:"My Post Title"`
-	Content   string    `json:"content" db:"content" example:"Post content text"`
-	AuthorID  int64     `json:"author_id" db:"author_id" example:"456"`
-	CreatedAt time.Time `json:"created_at" db:"created_at" example:"2023-01-01T00:00:00Z"`
+	ID          int64        `json:"id" db:"id" example:"123"`
+	Title       string       `json:"title" db:"title" example:"My Post Title"`
+	Content     string       `json:"content" db:"content" example:"Post content text"`                  // markdown автора
+	ContentHTML string       `json:"content_html" db:"content_html" example:"<p>Post content text</p>"` // Content, отрендеренный в очищенный HTML
+	AuthorID    int64        `json:"author_id" db:"author_id" example:"456"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at" example:"2023-01-01T00:00:00Z"`
+	TopicID     *int64       `json:"topic_id,omitempty" db:"topic_id" example:"1"`
+	Score       int          `json:"score" db:"score" example:"0"`
+	MyVote      int          `json:"my_vote" db:"-" example:"0"` // голос того, кто запросил ленту: 1, -1 или 0
+	Reactions   []Reaction   `json:"reactions" db:"-"`
+	Tags        []string     `json:"tags" db:"-"`
+	Attachments []Attachment `json:"attachments" db:"-"`
+	// Заполняются только у постов из корзины.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	DeletedBy *int64     `json:"deleted_by,omitempty" db:"deleted_by"`
 }
@@ -0,0 +1,17 @@
+package entity
+
+// Reaction — сколько раз записи поставили эмодзи. Mine — есть ли среди них
+// реакция того, кто запросил список.
+type Reaction struct {
+	Emoji string `json:"emoji" db:"emoji" example:"👍"`
+	Count int    `json:"count" db:"count" example:"3"`
+	Mine  bool   `json:"mine" db:"mine" example:"false"`
+}
+
+// ReactionResult — итог переключения реакции: Added — поставлена она или
+// снята, Reactions — все реакции записи после этого.
+type ReactionResult struct {
+	Emoji     string     `json:"emoji" example:"👍"`
+	Added     bool       `json:"added" example:"true"`
+	Reactions []Reaction `json:"reactions"`
+}
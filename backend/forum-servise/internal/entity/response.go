@@ -8,6 +8,17 @@ type SuccessResponse struct {
 }
 
 type CreatePostRequest struct {
-	Title   string `json:"title" example:"My Post Title"`
-	Content string `json:"content" example:"Post content text"`
+	Title   string   `json:"title" example:"My Post Title"`
+	Content string   `json:"content" example:"Post content text"`
+	TopicID *int64   `json:"topic_id,omitempty" example:"1"`
+	Tags    []string `json:"tags,omitempty" example:"go,grpc"`
+}
+
+type CategoryRequest struct {
+	Name        string `json:"name" example:"Go"`
+	Description string `json:"description" example:"Всё о языке Go"`
+}
+
+type TopicRequest struct {
+	Title string `json:"title" example:"Горутины и каналы"`
 }
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// PostRevision — сохраненная версия поста. Ревизия 1 — текст при создании,
+// дальше по одной на каждую правку или откат.
+type PostRevision struct {
+	PostID     int64     `json:"post_id" db:"post_id"`
+	Revision   int       `json:"revision" db:"revision"`
+	Title      string    `json:"title" db:"title"`
+	Content    string    `json:"content,omitempty" db:"content"`
+	EditorID   int64     `json:"editor_id" db:"editor_id"`
+	EditorName string    `json:"editor_name" db:"-"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// RevisionDiff — unified diff от ревизии From к ревизии To. Заголовок
+// и текст сравниваются отдельно, неизмененная часть в Diff не попадает.
+type RevisionDiff struct {
+	PostID int64  `json:"post_id"`
+	From   int    `json:"from"`
+	To     int    `json:"to"`
+	Diff   string `json:"diff"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+const (
+	SearchResultPost    = "post"
+	SearchResultComment = "comment"
+)
+
+// SearchQuery — поисковый запрос с необязательными фильтрами.
+// Диапазон дат полуоткрытый: [From, To).
+type SearchQuery struct {
+	Text     string
+	AuthorID *int64
+	TopicID  *int64
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
+
+// SearchResult — найденный пост или комментарий. Для комментария Title —
+// заголовок поста, к которому он оставлен. В Snippet совпадения обернуты
+// в <mark>, остальной текст экранирован.
+type SearchResult struct {
+	Type       string    `json:"type" db:"type"`
+	ID         int64     `json:"id" db:"id"`
+	PostID     int64     `json:"post_id" db:"post_id"`
+	Title      string    `json:"title" db:"title"`
+	Snippet    string    `json:"snippet" db:"snippet"`
+	AuthorID   int64     `json:"author_id" db:"author_id"`
+	AuthorName string    `json:"author_name" db:"-"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	Rank       float32   `json:"rank" db:"rank"`
+}
+
+type SearchPage struct {
+	Results []*SearchResult `json:"results"`
+	Total   int64           `json:"total"`
+}
@@ -0,0 +1,14 @@
+package entity
+
+// Tag — тег и число живых постов с ним.
+type Tag struct {
+	Name      string `json:"name" db:"name" example:"go"`
+	PostCount int64  `json:"post_count" db:"post_count" example:"12"`
+}
+
+// Как фильтр ленты сочетает несколько тегов: all — пост должен иметь все
+// теги, any — хотя бы один.
+const (
+	TagMatchAll = "all"
+	TagMatchAny = "any"
+)
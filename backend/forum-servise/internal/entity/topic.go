@@ -5,9 +5,10 @@ import (
 )
 
 type Topic struct {
-	ID         int64     `db:"id"`
-	CategoryID int64     `db:"category_id"`
-	Title      string    `db:"title"`
-	UserID     int64     `db:"user_id"`
-	CreatedAt  time.Time `db:"created_at"`
+	ID         int64     `json:"id" db:"id" example:"1"`
+	CategoryID int64     `json:"category_id" db:"category_id" example:"1"`
+	Title      string    `json:"title" db:"title" example:"Горутины и каналы"`
+	UserID     int64     `json:"user_id" db:"user_id" example:"1"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,7 @@
+package entity
+
+// VoteResult — счет записи после голосования и голос пользователя.
+type VoteResult struct {
+	Score  int `json:"score"`
+	MyVote int `json:"my_vote"`
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead — запас на заголовки multipart сверх размера файла.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	uc     *usecase.AttachmentUsecase
+	logger *logger.Logger
+}
+
+func NewAttachmentHandler(uc *usecase.AttachmentUsecase, logger *logger.Logger) *AttachmentHandler {
+	return &AttachmentHandler{uc: uc, logger: logger}
+}
+
+// UploadAttachment godoc
+// @Summary Attach a file to a post
+// @Description Post author or admin only. Multipart upload in the "file" field. The type is detected from the file content: images (png, jpeg, gif, webp), plain text, pdf, zip and gzip are accepted. Size is limited per role, a post has at most 10 attachments. Images get a thumbnail
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param file formData file true "File"
+// @Success 201 {object} entity.Attachment
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 413 {object} entity.ErrorResponse
+// @Failure 415 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.uc.MaxUploadSize()+multipartOverhead)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondError(c, usecase.ErrAttachmentTooLarge, "")
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": `Multipart field "file" is required`})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.uc.Upload(c.Request.Context(), user, postID, header.Filename, file)
+	if err != nil {
+		h.respondError(c, err, "Failed to upload attachment")
+		return
+	}
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// GetAttachment godoc
+// @Summary Download an attachment
+// @Description File of an attachment of a live post. Images are shown inline, other files are downloaded
+// @Tags attachments
+// @Produce octet-stream
+// @Param id path int true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/attachments/{id} [get]
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	h.serve(c, false)
+}
+
+// GetThumbnail godoc
+// @Summary Download an attachment thumbnail
+// @Description Reduced copy of an image attachment (at most 320px per side). 404 if the attachment has no thumbnail
+// @Tags attachments
+// @Produce image/png
+// @Produce image/jpeg
+// @Param id path int true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/attachments/{id}/thumbnail [get]
+func (h *AttachmentHandler) GetThumbnail(c *gin.Context) {
+	h.serve(c, true)
+}
+
+// DeleteAttachment godoc
+// @Summary Delete an attachment
+// @Description Post author or admin only. Removes the attachment and its files
+// @Tags attachments
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/attachments/{attachmentId} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	attachmentID, err := strconv.ParseInt(c.Param("attachmentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uc.Delete(c.Request.Context(), user, postID, attachmentID); err != nil {
+		h.respondError(c, err, "Failed to delete attachment")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+// serve отдает файл вложения. Файлы пользовательские, поэтому браузеру
+// запрещено угадывать тип и исполнять содержимое, а все, кроме картинок,
+// отдается на скачивание.
+func (h *AttachmentHandler) serve(c *gin.Context, thumbnail bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+
+	file, err := h.uc.Open(c.Request.Context(), id, thumbnail)
+	if err != nil {
+		h.respondError(c, err, "Failed to get attachment")
+		return
+	}
+	defer file.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(file.ContentType, "image/") {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, file.Size, file.ContentType, file, map[string]string{
+		"Content-Disposition":     mime.FormatMediaType(disposition, map[string]string{"filename": file.Attachment.FileName}),
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'; sandbox",
+		"Cache-Control":           "private, max-age=86400",
+	})
+}
+
+func (h *AttachmentHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, usecase.ErrEmptyAttachment), errors.Is(err, usecase.ErrTooManyAttachments):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrUnsupportedAttachment):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	case errors.Is(err, repository.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+	case errors.Is(err, repository.ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission"})
+	default:
+		h.logger.Error(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
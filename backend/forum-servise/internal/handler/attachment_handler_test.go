@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *mockAttachmentRepository) CreateAttachment(ctx context.Context, attachment *entity.Attachment, limit int) error {
+	args := m.Called(ctx, attachment, limit)
+	attachment.ID = 5
+	return args.Error(0)
+}
+
+func (m *mockAttachmentRepository) GetAttachment(ctx context.Context, id int64) (*entity.Attachment, error) {
+	args := m.Called(ctx, id)
+	attachment, _ := args.Get(0).(*entity.Attachment)
+	return attachment, args.Error(1)
+}
+
+func (m *mockAttachmentRepository) CountPostAttachments(ctx context.Context, postID int64) (int, error) {
+	args := m.Called(ctx, postID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockAttachmentRepository) DeleteAttachment(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func newAttachmentRouter(t *testing.T, repo *mockAttachmentRepository) (*gin.Engine, storage.Storage) {
+	posts := new(mockPostRepository)
+	posts.On("GetPostByID", mock.Anything, int64(1)).Return(&entity.Post{ID: 1, AuthorID: 42}, nil)
+	posts.On("GetPostByID", mock.Anything, mock.Anything).Return(nil, repository.ErrPostNotFound)
+
+	store, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	uc := usecase.NewAttachmentUsecase(repo, posts, store, usecase.AttachmentLimits{"user": 64, authmw.RoleAdmin: 128}, newTestLogger())
+	h := NewAttachmentHandler(uc, newTestLogger())
+
+	r := newTestRouter()
+	r.POST("/posts/:id/attachments", r.requireAuth, h.UploadAttachment)
+	r.DELETE("/posts/:id/attachments/:attachmentId", r.requireAuth, h.DeleteAttachment)
+	r.GET("/attachments/:id", h.GetAttachment)
+	r.GET("/attachments/:id/thumbnail", h.GetThumbnail)
+	return r.Engine, store
+}
+
+func uploadRequest(t *testing.T, url, fileName, content, token string) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := testRequest(http.MethodPost, url, body.String(), token)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func TestUploadAttachment(t *testing.T) {
+	repo := new(mockAttachmentRepository)
+	repo.On("CountPostAttachments", mock.Anything, int64(1)).Return(0, nil)
+	repo.On("CreateAttachment", mock.Anything, mock.Anything, usecase.MaxPostAttachments).Return(nil)
+	r, store := newAttachmentRouter(t, repo)
+
+	t.Run("Created", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, uploadRequest(t, "/posts/1/attachments", "notes.txt", "hello", authorToken))
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, "notes.txt", got["file_name"])
+		assert.Equal(t, "text/plain; charset=utf-8", got["content_type"])
+		assert.Equal(t, "/api/v1/attachments/5", got["url"])
+		assert.NotContains(t, got, "storage_key")
+		assert.NotContains(t, got, "thumbnail_url")
+
+		created := repo.Calls[len(repo.Calls)-1].Arguments.Get(1).(*entity.Attachment)
+		file, err := store.Get(context.Background(), created.StorageKey)
+		require.NoError(t, err)
+		data, _ := io.ReadAll(file)
+		file.Close()
+		assert.Equal(t, "hello", string(data))
+	})
+
+	tests := []struct {
+		name    string
+		url     string
+		content string
+		token   string
+		status  int
+	}{
+		{name: "Too large", url: "/posts/1/attachments", content: strings.Repeat("x", 65), token: authorToken, status: http.StatusRequestEntityTooLarge},
+		{name: "Unsupported type", url: "/posts/1/attachments", content: "<!DOCTYPE html><p>x", token: authorToken, status: http.StatusUnsupportedMediaType},
+		{name: "Empty file", url: "/posts/1/attachments", content: "", token: authorToken, status: http.StatusBadRequest},
+		{name: "Not the author", url: "/posts/1/attachments", content: "x", token: userToken, status: http.StatusForbidden},
+		{name: "Post not found", url: "/posts/9/attachments", content: "x", token: authorToken, status: http.StatusNotFound},
+		{name: "Invalid post ID", url: "/posts/abc/attachments", content: "x", token: authorToken, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, uploadRequest(t, tt.url, "a.txt", tt.content, tt.token))
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+
+	t.Run("Missing file field", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, testRequest(http.MethodPost, "/posts/1/attachments", "{}", authorToken))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, uploadRequest(t, "/posts/1/attachments", "a.txt", "x", ""))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestGetAttachment(t *testing.T) {
+	repo := new(mockAttachmentRepository)
+	repo.On("GetAttachment", mock.Anything, int64(3)).Return(&entity.Attachment{
+		ID: 3, PostID: 1, FileName: "отчет.pdf", ContentType: "application/pdf", Size: 8, StorageKey: "posts/1/report",
+	}, nil)
+	repo.On("GetAttachment", mock.Anything, mock.Anything).Return(nil, repository.ErrAttachmentNotFound)
+	r, store := newAttachmentRouter(t, repo)
+	require.NoError(t, store.Put(context.Background(), "posts/1/report", strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/attachments/3", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "%PDF-1.4", w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename*=utf-8''%D0%BE%D1%82%D1%87%D0%B5%D1%82.pdf", w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Contains(t, w.Header().Get("Content-Security-Policy"), "sandbox")
+
+	for _, url := range []string{"/attachments/3/thumbnail", "/attachments/4", "/attachments/abc"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		assert.NotEqual(t, http.StatusOK, w.Code, url)
+	}
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	repo := new(mockAttachmentRepository)
+	repo.On("GetAttachment", mock.Anything, int64(3)).Return(&entity.Attachment{ID: 3, PostID: 1, StorageKey: "posts/1/x"}, nil)
+	repo.On("DeleteAttachment", mock.Anything, int64(3)).Return(nil)
+	r, _ := newAttachmentRouter(t, repo)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodDelete, "/posts/1/attachments/3", "", userToken))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodDelete, "/posts/1/attachments/3", "", authorToken))
+	assert.Equal(t, http.StatusOK, w.Code)
+	repo.AssertCalled(t, "DeleteAttachment", mock.Anything, int64(3))
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"backend.com/forum/authmw"
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser возвращает пользователя, проверенного middleware authmw.
+// Если маршрут по ошибке зарегистрирован без Required, запрос отклоняется.
+func currentUser(c *gin.Context) (*authmw.Principal, bool) {
+	principal, ok := authmw.Current(c)
+	if !ok {
+		authmw.Abort(c, authmw.ErrMissingToken)
+		return nil, false
+	}
+	return principal, true
+}
@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryUC usecase.CategoryUsecaseInterface
+	topicUC    usecase.TopicUsecaseInterface
+	logger     *logger.Logger
+}
+
+func NewCategoryHandler(
+	categoryUC usecase.CategoryUsecaseInterface,
+	topicUC usecase.TopicUsecaseInterface,
+	logger *logger.Logger,
+) *CategoryHandler {
+	return &CategoryHandler{
+		categoryUC: categoryUC,
+		topicUC:    topicUC,
+		logger:     logger,
+	}
+}
+
+// GetCategories godoc
+// @Summary Get all categories
+// @Description Get list of forum categories
+// @Tags categories
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/categories [get]
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	categories, err := h.categoryUC.GetCategories(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get categories", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": categories})
+}
+
+// GetCategory godoc
+// @Summary Get a category
+// @Description Get forum category by ID
+// @Tags categories
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} entity.Category
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /api/v1/categories/{id} [get]
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "Invalid category ID")
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryUC.GetCategory(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Failed to get category")
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// CreateCategory godoc
+// @Summary Create a category
+// @Description Create a forum category (admin only)
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body entity.CategoryRequest true "Category data"
+// @Success 201 {object} entity.Category
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Router /api/v1/categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var request entity.CategoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	category, err := h.categoryUC.CreateCategory(c.Request.Context(), request.Name, request.Description)
+	if err != nil {
+		h.respondError(c, err, "Failed to create category")
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory godoc
+// @Summary Update a category
+// @Description Update a forum category (admin only)
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Category ID"
+// @Param request body entity.CategoryRequest true "Category data"
+// @Success 200 {object} entity.Category
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /api/v1/categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "Invalid category ID")
+	if !ok {
+		return
+	}
+
+	var request entity.CategoryRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	category, err := h.categoryUC.UpdateCategory(c.Request.Context(), id, request.Name, request.Description)
+	if err != nil {
+		h.respondError(c, err, "Failed to update category")
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Delete a forum category with all its topics (admin only)
+// @Tags categories
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Category ID"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /api/v1/categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "Invalid category ID")
+	if !ok {
+		return
+	}
+
+	if err := h.categoryUC.DeleteCategory(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, "Failed to delete category")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+// GetTopics godoc
+// @Summary Get topics of a category
+// @Description Get list of topics in the category
+// @Tags topics
+// @Produce json
+// @Param id path int true "Category ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /api/v1/categories/{id}/topics [get]
+func (h *CategoryHandler) GetTopics(c *gin.Context) {
+	categoryID, ok := parseIDParam(c, "Invalid category ID")
+	if !ok {
+		return
+	}
+
+	topics, err := h.topicUC.GetTopicsByCategory(c.Request.Context(), categoryID)
+	if err != nil {
+		h.respondError(c, err, "Failed to get topics")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": topics})
+}
+
+// CreateTopic godoc
+// @Summary Create a topic
+// @Description Create a topic in the category (admin only)
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Category ID"
+// @Param request body entity.TopicRequest true "Topic data"
+// @Success 201 {object} entity.Topic
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /api/v1/categories/{id}/topics [post]
+func (h *CategoryHandler) CreateTopic(c *gin.Context) {
+	categoryID, ok := parseIDParam(c, "Invalid category ID")
+	if !ok {
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var request entity.TopicRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	topic, err := h.topicUC.CreateTopic(c.Request.Context(), categoryID, user.UserID, request.Title)
+	if err != nil {
+		h.respondError(c, err, "Failed to create topic")
+		return
+	}
+
+	c.JSON(http.StatusCreated, topic)
+}
+
+// GetTopic godoc
+// @Summary Get a topic
+// @Description Get forum topic by ID
+// @Tags topics
+// @Produce json
+// @Param id path int true "Topic ID"
+// @Success 200 {object} entity.Topic
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /api/v1/topics/{id} [get]
+func (h *CategoryHandler) GetTopic(c *gin.Context) {
+	id, ok := parseIDParam(c, "Invalid topic ID")
+	if !ok {
+		return
+	}
+
+	topic, err := h.topicUC.GetTopic(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err, "Failed to get topic")
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
+// UpdateTopic godoc
+// @Summary Update a topic
+// @Description Rename a forum topic (admin only)
+// @Tags topics
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Topic ID"
+// @Param request body entity.TopicRequest true "Topic data"
+// @Success 200 {object} entity.Topic
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /api/v1/topics/{id} [put]
+func (h *CategoryHandler) UpdateTopic(c *gin.Context) {
+	id, ok := parseIDParam(c, "Invalid topic ID")
+	if !ok {
+		return
+	}
+
+	var request entity.TopicRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	topic, err := h.topicUC.UpdateTopic(c.Request.Context(), id, request.Title)
+	if err != nil {
+		h.respondError(c, err, "Failed to update topic")
+		return
+	}
+
+	c.JSON(http.StatusOK, topic)
+}
+
+// DeleteTopic godoc
+// @Summary Delete a topic
+// @Description Delete a forum topic; its posts stay without a topic (admin only)
+// @Tags topics
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Topic ID"
+// @Success 200 {object} entity.SuccessResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Router /api/v1/topics/{id} [delete]
+func (h *CategoryHandler) DeleteTopic(c *gin.Context) {
+	id, ok := parseIDParam(c, "Invalid topic ID")
+	if !ok {
+		return
+	}
+
+	if err := h.topicUC.DeleteTopic(c.Request.Context(), id); err != nil {
+		h.respondError(c, err, "Failed to delete topic")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Topic deleted successfully"})
+}
+
+func (h *CategoryHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	case errors.Is(err, repository.ErrTopicNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Topic not found"})
+	case errors.Is(err, repository.ErrCategoryExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Category already exists"})
+	case errors.Is(err, usecase.ErrEmptyCategoryName),
+		errors.Is(err, usecase.ErrEmptyTopicTitle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func parseIDParam(c *gin.Context, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return id, true
+}
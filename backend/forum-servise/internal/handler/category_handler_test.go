@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newCategoryRouter повторяет маршруты из cmd/main.go: токен проверяется
+// через auth-клиент, админские маршруты закрыты RequireRole.
+func newCategoryRouter(h *CategoryHandler, authClient pb.AuthServiceClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	admin := []gin.HandlerFunc{
+		authmw.NewAuthenticator(nil, authClient).Required(),
+		authmw.RequireRole(authmw.RoleAdmin),
+	}
+	r.GET("/categories", h.GetCategories)
+	r.POST("/categories", append(admin, h.CreateCategory)...)
+	r.GET("/categories/:id", h.GetCategory)
+	r.PUT("/categories/:id", append(admin, h.UpdateCategory)...)
+	r.DELETE("/categories/:id", append(admin, h.DeleteCategory)...)
+	r.GET("/categories/:id/topics", h.GetTopics)
+	r.POST("/categories/:id/topics", append(admin, h.CreateTopic)...)
+	r.DELETE("/topics/:id", append(admin, h.DeleteTopic)...)
+	return r
+}
+
+func TestCategoryHandler_CreateCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		setupMocks func(auth *MockAuthClient, categoryUC *mockCategoryUsecase)
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "No token",
+			body:       `{"name":"Go"}`,
+			setupMocks: func(auth *MockAuthClient, categoryUC *mockCategoryUsecase) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:  "Invalid token",
+			token: "bad-token",
+			body:  `{"name":"Go"}`,
+			setupMocks: func(auth *MockAuthClient, categoryUC *mockCategoryUsecase) {
+				auth.On("ValidateToken", mock.Anything, &pb.ValidateTokenRequest{Token: "bad-token"}, mock.Anything).
+					Return(&pb.ValidateTokenResponse{Valid: false}, nil)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:  "Not admin",
+			token: "user-token",
+			body:  `{"name":"Go"}`,
+			setupMocks: func(auth *MockAuthClient, categoryUC *mockCategoryUsecase) {
+				auth.On("ValidateToken", mock.Anything, &pb.ValidateTokenRequest{Token: "user-token"}, mock.Anything).
+					Return(&pb.ValidateTokenResponse{Valid: true, UserId: 2, Role: "user"}, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:  "Duplicate name",
+			token: "admin-token",
+			body:  `{"name":"Go"}`,
+			setupMocks: func(auth *MockAuthClient, categoryUC *mockCategoryUsecase) {
+				auth.On("ValidateToken", mock.Anything, &pb.ValidateTokenRequest{Token: "admin-token"}, mock.Anything).
+					Return(&pb.ValidateTokenResponse{Valid: true, UserId: 1, Role: "admin"}, nil)
+				categoryUC.On("CreateCategory", mock.Anything, "Go", "").
+					Return(nil, repository.ErrCategoryExists)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:  "Success",
+			token: "admin-token",
+			body:  `{"name":"Go","description":"Всё о Go"}`,
+			setupMocks: func(auth *MockAuthClient, categoryUC *mockCategoryUsecase) {
+				auth.On("ValidateToken", mock.Anything, &pb.ValidateTokenRequest{Token: "admin-token"}, mock.Anything).
+					Return(&pb.ValidateTokenResponse{Valid: true, UserId: 1, Role: "admin"}, nil)
+				categoryUC.On("CreateCategory", mock.Anything, "Go", "Всё о Go").
+					Return(&entity.Category{ID: 1, Name: "Go", Description: "Всё о Go"}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := new(MockAuthClient)
+			categoryUC := new(mockCategoryUsecase)
+			tt.setupMocks(auth, categoryUC)
+
+			r := newCategoryRouter(NewCategoryHandler(categoryUC, nil, newTestLogger()), auth)
+
+			req, _ := http.NewRequest(http.MethodPost, "/categories", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			auth.AssertExpectations(t)
+			categoryUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCategoryHandler_GetCategory_NotFound(t *testing.T) {
+	categoryUC := new(mockCategoryUsecase)
+	categoryUC.On("GetCategory", mock.Anything, int64(9)).Return(nil, repository.ErrCategoryNotFound)
+
+	r := newCategoryRouter(NewCategoryHandler(categoryUC, nil, newTestLogger()), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/categories/9", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	categoryUC.AssertExpectations(t)
+}
+
+func TestCategoryHandler_GetTopics(t *testing.T) {
+	topicUC := new(mockTopicUsecase)
+	topicUC.On("GetTopicsByCategory", mock.Anything, int64(1)).
+		Return([]entity.Topic{{ID: 3, CategoryID: 1, Title: "Горутины"}}, nil)
+
+	r := newCategoryRouter(NewCategoryHandler(nil, topicUC, newTestLogger()), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/categories/1/topics", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Горутины")
+	topicUC.AssertExpectations(t)
+}
+
+func TestCategoryHandler_CreateTopic(t *testing.T) {
+	auth := new(MockAuthClient)
+	topicUC := new(mockTopicUsecase)
+	auth.On("ValidateToken", mock.Anything, &pb.ValidateTokenRequest{Token: "admin-token"}, mock.Anything).
+		Return(&pb.ValidateTokenResponse{Valid: true, UserId: 1, Role: "admin"}, nil)
+	topicUC.On("CreateTopic", mock.Anything, int64(2), int64(1), "Каналы").
+		Return(&entity.Topic{ID: 5, CategoryID: 2, UserID: 1, Title: "Каналы"}, nil)
+
+	r := newCategoryRouter(NewCategoryHandler(nil, topicUC, newTestLogger()), auth)
+
+	req, _ := http.NewRequest(http.MethodPost, "/categories/2/topics", bytes.NewBufferString(`{"title":"Каналы"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer admin-token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	auth.AssertExpectations(t)
+	topicUC.AssertExpectations(t)
+}
+
+func TestCategoryHandler_DeleteTopic_NotFound(t *testing.T) {
+	auth := new(MockAuthClient)
+	topicUC := new(mockTopicUsecase)
+	auth.On("ValidateToken", mock.Anything, &pb.ValidateTokenRequest{Token: "admin-token"}, mock.Anything).
+		Return(&pb.ValidateTokenResponse{Valid: true, UserId: 1, Role: "admin"}, nil)
+	topicUC.On("DeleteTopic", mock.Anything, int64(4)).Return(repository.ErrTopicNotFound)
+
+	r := newCategoryRouter(NewCategoryHandler(nil, topicUC, newTestLogger()), auth)
+
+	req, _ := http.NewRequest(http.MethodDelete, "/topics/4", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	auth.AssertExpectations(t)
+	topicUC.AssertExpectations(t)
+}
@@ -2,13 +2,14 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
 
-	pb "backend.com/forum/proto"
+	"backend.com/forum/authmw"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
@@ -23,7 +24,7 @@ func NewCommentHandler(commentUC *usecase.CommentUseCase) *CommentHandler {
 
 // CreateComment godoc
 // @Summary Create a new comment
-// @Description Create a new comment for a specific post
+// @Description Create a new comment for a specific post. With parent_id the comment is a reply to another comment of the same post. Content is markdown; the sanitized HTML is returned as content_html
 // @Tags comments
 // @Accept json
 // @Produce json
@@ -34,27 +35,90 @@ func NewCommentHandler(commentUC *usecase.CommentUseCase) *CommentHandler {
 // @Success 201 {object} entity.Comment
 // @Failure 400 {object} entity.ErrorResponse
 // @Failure 401 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
 // @Router /api/v1/posts/{id}/comments [post]
 func (h *CommentHandler) CreateComment(c *gin.Context) {
-	authHeader := c.GetHeader("Authorization")
-	if authHeader == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
-	token := strings.TrimPrefix(authHeader, "Bearer ")
 
-	authResponse, err := h.commentUC.AuthClient.ValidateToken(c.Request.Context(), &pb.ValidateTokenRequest{
-		Token: token,
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post id"})
+		return
+	}
+
+	var request struct {
+		Content  string `json:"content" binding:"required"`
+		ParentID *int64 `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	comment := entity.Comment{
+		Content:  request.Content,
+		PostID:   postID,
+		ParentID: request.ParentID,
+	}
+
+	if err := h.commentUC.CreateComment(c.Request.Context(), user, &comment); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrPostNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		case errors.Is(err, usecase.ErrParentNotFound),
+			errors.Is(err, usecase.ErrCommentTooDeep):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("Error creating comment: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":           comment.ID,
+		"content":      comment.Content,
+		"content_html": comment.ContentHTML,
+		"author_id":    comment.AuthorID,
+		"post_id":      comment.PostID,
+		"parent_id":    comment.ParentID,
+		"depth":        comment.Depth,
+		"author_name":  comment.AuthorName,
+		"created_at":   comment.CreatedAt,
+		"edited":       false,
 	})
-	if err != nil || authResponse == nil || !authResponse.Valid {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+}
+
+// UpdateComment godoc
+// @Summary Update a comment
+// @Description Update the text of a comment (only its author or an admin can update). The response carries updated_at and edited=true
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param commentId path int true "Comment ID"
+// @Param request body entity.Comment true "Update data"
+// @Success 200 {object} entity.Comment
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/comments/{commentId} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	postID, commentID, ok := commentPathIDs(c)
+	if !ok {
 		return
 	}
 
-	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post id"})
+	user, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
@@ -66,45 +130,93 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
-	userResponse, err := h.commentUC.AuthClient.GetUser(c.Request.Context(), &pb.GetUserRequest{
-		Id: authResponse.UserId,
+	comment, err := h.commentUC.UpdateComment(c.Request.Context(), user, postID, commentID, request.Content)
+	if err != nil {
+		respondCommentWriteError(c, err, "Failed to update comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment updated successfully",
+		"comment": comment,
 	})
+}
 
-	comment := entity.Comment{
-		Content:    request.Content,
-		AuthorID:   authResponse.UserId,
-		AuthorName: "Unknown",
-		PostID:     postID,
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Description Move a comment to the trash (only its author or an admin can delete). If it has replies, the thread keeps a "[deleted]" placeholder in its place
+// @Tags comments
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param commentId path int true "Comment ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/comments/{commentId} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	postID, commentID, ok := commentPathIDs(c)
+	if !ok {
+		return
 	}
 
-	if err == nil && userResponse != nil && userResponse.User != nil {
-		comment.AuthorName = userResponse.User.Username
+	user, ok := currentUser(c)
+	if !ok {
+		return
 	}
 
-	if err := h.commentUC.CreateComment(c.Request.Context(), &comment); err != nil {
-		log.Printf("Error creating comment: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+	if err := h.commentUC.DeleteComment(c.Request.Context(), user, postID, commentID); err != nil {
+		respondCommentWriteError(c, err, "Failed to delete comment")
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"id":          comment.ID,
-		"content":     comment.Content,
-		"author_id":   comment.AuthorID,
-		"post_id":     comment.PostID,
-		"author_name": comment.AuthorName,
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func commentPathIDs(c *gin.Context) (postID, commentID int64, ok bool) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post id"})
+		return 0, 0, false
+	}
+	commentID, err = strconv.ParseInt(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return 0, 0, false
+	}
+	return postID, commentID, true
+}
+
+func respondCommentWriteError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+	case errors.Is(err, repository.ErrPermissionDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission"})
+	default:
+		log.Printf("%s: %v", message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
 }
 
 // GetCommentsByPostID godoc
 // @Summary Get comments for a post
-// @Description Get all comments for a specific post
+// @Description Get a page of top-level comments of a post, newest first. Each comment carries up to depth levels of replies (the first few per comment, oldest first) and reply_count; the rest are loaded through /comments/{id}/replies. With a token every comment also carries the caller's my_vote
 // @Tags comments
 // @Accept json
 // @Produce json
+// @Param Authorization header string false "Bearer token"
 // @Param id path int true "Post ID"
-// @Success 200 {object} []entity.Comment
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Threads per page" default(20)
+// @Param depth query int false "Reply levels to expand" default(3)
+// @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
 // @Router /api/v1/posts/{id}/comments [get]
 func (h *CommentHandler) GetCommentsByPostID(c *gin.Context) {
@@ -115,17 +227,103 @@ func (h *CommentHandler) GetCommentsByPostID(c *gin.Context) {
 		return
 	}
 
-	comments, err := h.commentUC.GetCommentsByPostID(c.Request.Context(), postID)
+	query, page, err := parseCommentTreeQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.commentUC.GetCommentsByPostID(c.Request.Context(), postID, query)
+	if err != nil {
+		h.respondCommentsError(c, err, "Post not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments": result.Comments,
+		"total":    result.Total,
+		"page":     page,
+		"limit":    query.Limit,
+	})
+}
+
+// GetReplies godoc
+// @Summary Get replies to a comment
+// @Description Get a page of direct replies to a comment, oldest first, each with up to depth levels of nested replies. With a token every comment also carries the caller's my_vote
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param id path int true "Comment ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Replies per page" default(20)
+// @Param depth query int false "Reply levels to expand" default(3)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/comments/{id}/replies [get]
+func (h *CommentHandler) GetReplies(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+
+	query, page, err := parseCommentTreeQuery(c)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.commentUC.GetReplies(c.Request.Context(), commentID, query)
+	if err != nil {
+		h.respondCommentsError(c, err, "Comment not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments": result.Comments,
+		"total":    result.Total,
+		"page":     page,
+		"limit":    query.Limit,
+	})
+}
+
+func (h *CommentHandler) respondCommentsError(c *gin.Context, err error, notFound string) {
+	switch {
+	case isPaginationError(err), errors.Is(err, usecase.ErrInvalidDepth):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPostNotFound),
+		errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+	default:
 		log.Printf("Error getting comments: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "failed to get comments",
 			"details": err.Error(),
 		})
-		return
 	}
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"comments": comments,
-	})
+// parseCommentTreeQuery разбирает page, limit и depth. Глубину больше
+// допустимой usecase сам урежет до MaxDepth. Если запрос с токеном,
+// в ответ попадут голоса пользователя.
+func parseCommentTreeQuery(c *gin.Context) (entity.CommentTreeQuery, int, error) {
+	offset, limit, page, err := parsePage(c.Query("page"), c.Query("limit"), usecase.DefaultCommentsPageSize)
+	if err != nil {
+		return entity.CommentTreeQuery{}, 0, err
+	}
+
+	query := entity.CommentTreeQuery{Depth: usecase.DefaultCommentDepth, Limit: limit, Offset: offset}
+	if value := c.Query("depth"); value != "" {
+		query.Depth, err = strconv.Atoi(value)
+		if err != nil || query.Depth < 0 {
+			return query, 0, usecase.ErrInvalidDepth
+		}
+	}
+	if user, ok := authmw.Current(c); ok {
+		query.ViewerID = user.UserID
+	}
+	return query, page, nil
 }
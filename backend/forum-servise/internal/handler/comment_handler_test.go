@@ -3,12 +3,16 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"backend.com/forum/authmw"
 	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -30,6 +34,16 @@ func (m *MockAuthClient) GetUser(ctx context.Context, in *pb.GetUserRequest, opt
 	return args.Get(0).(*pb.GetUserResponse), args.Error(1)
 }
 
+func (m *MockAuthClient) GetUsers(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+	args := m.Called(ctx, in, opts)
+	return args.Get(0).(*pb.GetUsersResponse), args.Error(1)
+}
+
+func (m *MockAuthClient) GetUsersByUsernames(ctx context.Context, in *pb.GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+	args := m.Called(ctx, in, opts)
+	return args.Get(0).(*pb.GetUsersResponse), args.Error(1)
+}
+
 func (m *MockAuthClient) Login(ctx context.Context, in *pb.LoginRequest, opts ...grpc.CallOption) (*pb.LoginResponse, error) {
 	args := m.Called(ctx, in, opts)
 	return args.Get(0).(*pb.LoginResponse), args.Error(1)
@@ -40,6 +54,26 @@ func (m *MockAuthClient) Register(ctx context.Context, in *pb.RegisterRequest, o
 	return args.Get(0).(*pb.RegisterResponse), args.Error(1)
 }
 
+func (m *MockAuthClient) Refresh(ctx context.Context, in *pb.RefreshRequest, opts ...grpc.CallOption) (*pb.RefreshResponse, error) {
+	args := m.Called(ctx, in, opts)
+	return args.Get(0).(*pb.RefreshResponse), args.Error(1)
+}
+
+func (m *MockAuthClient) Logout(ctx context.Context, in *pb.LogoutRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error) {
+	args := m.Called(ctx, in, opts)
+	return args.Get(0).(*pb.LogoutResponse), args.Error(1)
+}
+
+func (m *MockAuthClient) LogoutAll(ctx context.Context, in *pb.LogoutRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error) {
+	args := m.Called(ctx, in, opts)
+	return args.Get(0).(*pb.LogoutResponse), args.Error(1)
+}
+
+func (m *MockAuthClient) RevokeUserSessions(ctx context.Context, in *pb.RevokeUserSessionsRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error) {
+	args := m.Called(ctx, in, opts)
+	return args.Get(0).(*pb.LogoutResponse), args.Error(1)
+}
+
 type MockCommentRepository struct {
 	mock.Mock
 }
@@ -49,9 +83,99 @@ func (m *MockCommentRepository) CreateComment(ctx context.Context, comment *enti
 	return args.Error(0)
 }
 
-func (m *MockCommentRepository) GetCommentsByPostID(ctx context.Context, postID int64) ([]entity.Comment, error) { // Изменен тип возвращаемого значения
+func (m *MockCommentRepository) GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error) {
+	args := m.Called(ctx, id)
+	comment, _ := args.Get(0).(*entity.Comment)
+	return comment, args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentsByPostID(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+	args := m.Called(ctx, postID, limit, offset)
+	comments, _ := args.Get(0).([]*entity.Comment)
+	return comments, args.Error(1)
+}
+
+func (m *MockCommentRepository) CountComments(ctx context.Context, postID int64) (int64, error) {
 	args := m.Called(ctx, postID)
-	return args.Get(0).([]entity.Comment), args.Error(1)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetReplies(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Comment, error) {
+	args := m.Called(ctx, parentID, limit, offset)
+	comments, _ := args.Get(0).([]*entity.Comment)
+	return comments, args.Error(1)
+}
+
+func (m *MockCommentRepository) GetDescendants(ctx context.Context, parentIDs []int64, depth, perParent int) ([]*entity.Comment, error) {
+	args := m.Called(ctx, parentIDs, depth, perParent)
+	comments, _ := args.Get(0).([]*entity.Comment)
+	return comments, args.Error(1)
+}
+
+func (m *MockCommentRepository) UpdateComment(ctx context.Context, id, postID, authorID int64, role, content, contentHTML string) (*entity.Comment, error) {
+	args := m.Called(ctx, id, postID, authorID, role, content, contentHTML)
+	comment, _ := args.Get(0).(*entity.Comment)
+	return comment, args.Error(1)
+}
+
+func (m *MockCommentRepository) DeleteComment(ctx context.Context, id, postID, authorID int64, role string) error {
+	args := m.Called(ctx, id, postID, authorID, role)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) RestoreComment(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) GetDeletedComments(ctx context.Context, limit, offset int) ([]*entity.Comment, error) {
+	args := m.Called(ctx, limit, offset)
+	comments, _ := args.Get(0).([]*entity.Comment)
+	return comments, args.Error(1)
+}
+
+func (m *MockCommentRepository) CountDeletedComments(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCommentRepository) PurgeDeletedComments(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCommentRepository) VoteComment(ctx context.Context, commentID, userID int64, value int) (int, error) {
+	args := m.Called(ctx, commentID, userID, value)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentVotes(ctx context.Context, userID int64, commentIDs []int64) (map[int64]int, error) {
+	args := m.Called(ctx, userID, commentIDs)
+	votes, _ := args.Get(0).(map[int64]int)
+	return votes, args.Error(1)
+}
+
+func (m *MockCommentRepository) ToggleCommentReaction(ctx context.Context, commentID, userID int64, emoji string) (bool, error) {
+	args := m.Called(ctx, commentID, userID, emoji)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentReactions(ctx context.Context, viewerID int64, commentIDs []int64) (map[int64][]entity.Reaction, error) {
+	args := m.Called(ctx, viewerID, commentIDs)
+	reactions, _ := args.Get(0).(map[int64][]entity.Reaction)
+	return reactions, args.Error(1)
+}
+
+// mockPostRepository реализует только методы, которые нужны тестам хендлеров.
+type mockPostRepository struct {
+	repository.PostRepository
+	mock.Mock
+}
+
+func (m *mockPostRepository) GetPostByID(ctx context.Context, id int64) (*entity.Post, error) {
+	args := m.Called(ctx, id)
+	post, _ := args.Get(0).(*entity.Post)
+	return post, args.Error(1)
 }
 
 func TestCreateComment_Success(t *testing.T) {
@@ -67,13 +191,10 @@ func TestCreateComment_Success(t *testing.T) {
 
 	handler := NewCommentHandler(uc)
 	router := gin.Default()
-	router.POST("/posts/:id/comments", handler.CreateComment)
+	router.POST("/posts/:id/comments", authmw.NewAuthenticator(nil, authClient).Required(), handler.CreateComment)
 
 	authClient.On("ValidateToken", mock.Anything, &pb.ValidateTokenRequest{Token: "valid-token"}, mock.Anything).
-		Return(&pb.ValidateTokenResponse{Valid: true, UserId: 42}, nil)
-
-	authClient.On("GetUser", mock.Anything, &pb.GetUserRequest{Id: 42}, mock.Anything).
-		Return(&pb.GetUserResponse{User: &pb.User{Username: "alice"}}, nil)
+		Return(&pb.ValidateTokenResponse{Valid: true, UserId: 42, Username: "alice"}, nil)
 
 	expectedComment := &entity.Comment{
 		Content:    "test comment",
@@ -95,3 +216,252 @@ func TestCreateComment_Success(t *testing.T) {
 	authClient.AssertExpectations(t)
 	commentRepo.AssertExpectations(t)
 }
+
+func setupRepliesRouter(commentRepo *MockCommentRepository, authClient *MockAuthClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	postRepo := new(mockPostRepository)
+	postRepo.On("GetPostByID", mock.Anything, int64(1)).Return(&entity.Post{ID: 1}, nil)
+	handler := NewCommentHandler(usecase.NewCommentUseCase(commentRepo, postRepo, authClient))
+	router := gin.New()
+	router.GET("/comments/:id/replies", handler.GetReplies)
+	return router
+}
+
+func TestGetReplies_Success(t *testing.T) {
+	authClient := new(MockAuthClient)
+	commentRepo := new(MockCommentRepository)
+	parentID, replyID := int64(1), int64(2)
+
+	commentRepo.On("GetCommentByID", mock.Anything, int64(1)).
+		Return(&entity.Comment{ID: 1, PostID: 1, ReplyCount: 7}, nil)
+	commentRepo.On("GetReplies", mock.Anything, int64(1), 2, 2).
+		Return([]*entity.Comment{{ID: 2, PostID: 1, ParentID: &parentID, Depth: 1, AuthorID: 10, ReplyCount: 1}}, nil)
+	commentRepo.On("GetDescendants", mock.Anything, []int64{2}, 1, 5).
+		Return([]*entity.Comment{{ID: 3, PostID: 1, ParentID: &replyID, Depth: 2, AuthorID: 11}}, nil)
+	commentRepo.On("GetCommentReactions", mock.Anything, int64(0), []int64{2, 3}).
+		Return(map[int64][]entity.Reaction{2: {{Emoji: "👍", Count: 2}}}, nil)
+	authClient.On("GetUsers", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pb.GetUsersResponse{Users: []*pb.User{{Id: 10, Username: "alice"}, {Id: 11, Username: "bob"}}}, nil)
+
+	req, _ := http.NewRequest("GET", "/comments/1/replies?page=2&limit=2&depth=1", nil)
+	w := httptest.NewRecorder()
+	setupRepliesRouter(commentRepo, authClient).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Comments []*entity.Comment `json:"comments"`
+		Total    int64             `json:"total"`
+		Page     int               `json:"page"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(7), response.Total)
+	assert.Equal(t, 2, response.Page)
+	if assert.Len(t, response.Comments, 1) {
+		assert.Equal(t, "alice", response.Comments[0].AuthorName)
+		if assert.Len(t, response.Comments[0].Replies, 1) {
+			assert.Equal(t, "bob", response.Comments[0].Replies[0].AuthorName)
+			assert.Empty(t, response.Comments[0].Replies[0].Reactions)
+		}
+		assert.Equal(t, []entity.Reaction{{Emoji: "👍", Count: 2}}, response.Comments[0].Reactions)
+	}
+	commentRepo.AssertExpectations(t)
+}
+
+func TestGetReplies_NotFound(t *testing.T) {
+	commentRepo := new(MockCommentRepository)
+	commentRepo.On("GetCommentByID", mock.Anything, int64(5)).Return(nil, repository.ErrCommentNotFound)
+
+	req, _ := http.NewRequest("GET", "/comments/5/replies", nil)
+	w := httptest.NewRecorder()
+	setupRepliesRouter(commentRepo, new(MockAuthClient)).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetReplies_InvalidQuery(t *testing.T) {
+	for _, target := range []string{
+		"/comments/abc/replies",
+		"/comments/1/replies?depth=-1",
+		"/comments/1/replies?depth=x",
+		"/comments/1/replies?limit=0",
+		"/comments/1/replies?limit=101",
+	} {
+		req, _ := http.NewRequest("GET", target, nil)
+		w := httptest.NewRecorder()
+		setupRepliesRouter(new(MockCommentRepository), new(MockAuthClient)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
+
+func setupCommentWriteRouter(commentRepo *MockCommentRepository, role string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	authClient := new(MockAuthClient)
+	authClient.On("ValidateToken", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pb.ValidateTokenResponse{Valid: true, UserId: 42, Username: "alice", Role: role}, nil)
+	authClient.On("GetUsers", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pb.GetUsersResponse{}, nil)
+
+	handler := NewCommentHandler(usecase.NewCommentUseCase(commentRepo, nil, authClient))
+	router := gin.New()
+	requireAuth := authmw.NewAuthenticator(nil, authClient).Required()
+	router.PUT("/posts/:id/comments/:commentId", requireAuth, handler.UpdateComment)
+	router.DELETE("/posts/:id/comments/:commentId", requireAuth, handler.DeleteComment)
+	return router
+}
+
+func TestUpdateComment(t *testing.T) {
+	editedAt := time.Now()
+
+	tests := []struct {
+		name       string
+		role       string
+		target     string
+		body       string
+		setup      func(*MockCommentRepository)
+		wantStatus int
+	}{
+		{
+			name:   "Author edits",
+			role:   "user",
+			target: "/posts/1/comments/5",
+			body:   `{"content":"fixed typo"}`,
+			setup: func(repo *MockCommentRepository) {
+				repo.On("GetCommentByID", mock.Anything, int64(5)).
+					Return(&entity.Comment{ID: 5, PostID: 1, AuthorID: 42}, nil)
+				repo.On("UpdateComment", mock.Anything, int64(5), int64(1), int64(42), "user", "fixed typo", "<p>fixed typo</p>\n").
+					Return(&entity.Comment{ID: 5, PostID: 1, AuthorID: 42, AuthorName: "alice",
+						Content: "fixed typo", UpdatedAt: &editedAt, Edited: true}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "Admin edits someone else's comment",
+			role:   "admin",
+			target: "/posts/1/comments/5",
+			body:   `{"content":"moderated"}`,
+			setup: func(repo *MockCommentRepository) {
+				repo.On("GetCommentByID", mock.Anything, int64(5)).
+					Return(&entity.Comment{ID: 5, PostID: 1, AuthorID: 7}, nil)
+				repo.On("UpdateComment", mock.Anything, int64(5), int64(1), int64(42), "admin", "moderated", "<p>moderated</p>\n").
+					Return(&entity.Comment{ID: 5, PostID: 1, AuthorID: 7, AuthorName: "bob",
+						Content: "moderated", UpdatedAt: &editedAt, Edited: true}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "Someone else's comment",
+			role:   "user",
+			target: "/posts/1/comments/5",
+			body:   `{"content":"hijack"}`,
+			setup: func(repo *MockCommentRepository) {
+				repo.On("GetCommentByID", mock.Anything, int64(5)).
+					Return(&entity.Comment{ID: 5, PostID: 1, AuthorID: 7}, nil)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "Comment of another post",
+			role:   "user",
+			target: "/posts/2/comments/5",
+			body:   `{"content":"text"}`,
+			setup: func(repo *MockCommentRepository) {
+				repo.On("GetCommentByID", mock.Anything, int64(5)).
+					Return(&entity.Comment{ID: 5, PostID: 1, AuthorID: 42}, nil)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Empty content",
+			role:       "user",
+			target:     "/posts/1/comments/5",
+			body:       `{"content":""}`,
+			setup:      func(repo *MockCommentRepository) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid comment id",
+			role:       "user",
+			target:     "/posts/1/comments/abc",
+			body:       `{"content":"text"}`,
+			setup:      func(repo *MockCommentRepository) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockCommentRepository)
+			tt.setup(repo)
+
+			req, _ := http.NewRequest("PUT", tt.target, bytes.NewBufferString(tt.body))
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			setupCommentWriteRouter(repo, tt.role).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Comment map[string]interface{} `json:"comment"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, true, response.Comment["edited"])
+				assert.NotEmpty(t, response.Comment["updated_at"])
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	t.Run("Author deletes", func(t *testing.T) {
+		repo := new(MockCommentRepository)
+		repo.On("GetCommentByID", mock.Anything, int64(5)).
+			Return(&entity.Comment{ID: 5, PostID: 1, AuthorID: 42}, nil)
+		repo.On("DeleteComment", mock.Anything, int64(5), int64(1), int64(42), "user").Return(nil)
+
+		req, _ := http.NewRequest("DELETE", "/posts/1/comments/5", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		setupCommentWriteRouter(repo, "user").ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Someone else's comment", func(t *testing.T) {
+		repo := new(MockCommentRepository)
+		repo.On("GetCommentByID", mock.Anything, int64(5)).
+			Return(&entity.Comment{ID: 5, PostID: 1, AuthorID: 7}, nil)
+
+		req, _ := http.NewRequest("DELETE", "/posts/1/comments/5", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		setupCommentWriteRouter(repo, "user").ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		repo.AssertNotCalled(t, "DeleteComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		repo := new(MockCommentRepository)
+		repo.On("GetCommentByID", mock.Anything, int64(5)).Return(nil, repository.ErrCommentNotFound)
+
+		req, _ := http.NewRequest("DELETE", "/posts/1/comments/5", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		setupCommentWriteRouter(repo, "user").ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/posts/1/comments/5", nil)
+		w := httptest.NewRecorder()
+		setupCommentWriteRouter(new(MockCommentRepository), "user").ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
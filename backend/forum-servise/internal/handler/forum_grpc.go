@@ -0,0 +1,432 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// chatPollInterval — как часто StreamChatMessages проверяет таблицу
+// chat_messages. Сообщения туда пишет и chat-servise, поэтому
+// внутрипроцессной рассылки недостаточно.
+const chatPollInterval = time.Second
+
+// ForumGRPCServer — gRPC API форума. Пользователь вызова берется из
+// токена (см. authmw.Authenticator.UnaryServerInterceptor), а не из полей
+// запроса.
+type ForumGRPCServer struct {
+	pb.UnimplementedForumServiceServer
+	categoryUC usecase.CategoryUsecaseInterface
+	topicUC    usecase.TopicUsecaseInterface
+	messageUC  usecase.MessageUsecaseInterface
+	postUC     usecase.PostUsecaseInterface
+	chatUC     usecase.ChatUsecaseInterface
+	searchUC   usecase.SearchUsecaseInterface
+	logger     *logger.Logger
+	pollEvery  time.Duration
+}
+
+func NewForumGRPCServer(
+	categoryUC usecase.CategoryUsecaseInterface,
+	topicUC usecase.TopicUsecaseInterface,
+	messageUC usecase.MessageUsecaseInterface,
+	postUC usecase.PostUsecaseInterface,
+	chatUC usecase.ChatUsecaseInterface,
+	searchUC usecase.SearchUsecaseInterface,
+	logger *logger.Logger,
+) *ForumGRPCServer {
+	return &ForumGRPCServer{
+		categoryUC: categoryUC,
+		topicUC:    topicUC,
+		messageUC:  messageUC,
+		postUC:     postUC,
+		chatUC:     chatUC,
+		searchUC:   searchUC,
+		logger:     logger,
+		pollEvery:  chatPollInterval,
+	}
+}
+
+func (s *ForumGRPCServer) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CreateCategoryResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	if _, err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categoryUC.CreateCategory(ctx, req.Name, req.Description)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to create category")
+	}
+
+	return &pb.CreateCategoryResponse{Id: category.ID}, nil
+}
+
+func (s *ForumGRPCServer) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.GetCategoryResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	category, err := s.categoryUC.GetCategory(ctx, req.Id)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to get category")
+	}
+
+	return &pb.GetCategoryResponse{Category: convertCategoryToProto(category)}, nil
+}
+
+func (s *ForumGRPCServer) CreateTopic(ctx context.Context, req *pb.CreateTopicRequest) (*pb.CreateTopicResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	user, err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err := s.topicUC.CreateTopic(ctx, req.CategoryId, user.UserID, req.Title)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to create topic")
+	}
+
+	return &pb.CreateTopicResponse{Id: topic.ID}, nil
+}
+
+func (s *ForumGRPCServer) GetTopic(ctx context.Context, req *pb.GetTopicRequest) (*pb.GetTopicResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	topic, err := s.topicUC.GetTopic(ctx, req.Id)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to get topic")
+	}
+
+	return &pb.GetTopicResponse{Topic: convertTopicToProto(topic)}, nil
+}
+
+func (s *ForumGRPCServer) CreateMessage(ctx context.Context, req *pb.CreateMessageRequest) (*pb.CreateMessageResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	user, err := authmw.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.messageUC.CreateMessage(ctx, req.TopicId, user.UserID, req.Content)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to create message")
+	}
+
+	return &pb.CreateMessageResponse{Id: message.ID}, nil
+}
+
+func (s *ForumGRPCServer) GetMessage(ctx context.Context, req *pb.GetMessageRequest) (*pb.GetMessageResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	message, err := s.messageUC.GetMessage(ctx, req.Id)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to get message")
+	}
+
+	return &pb.GetMessageResponse{Message: convertMessageToProto(message)}, nil
+}
+
+func (s *ForumGRPCServer) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.CreatePostResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+	if req.Title == "" || req.Content == "" {
+		return nil, status.Error(codes.InvalidArgument, "title and content are required")
+	}
+	author, err := authmw.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var topicID *int64
+	if req.TopicId != 0 {
+		topicID = &req.TopicId
+	}
+
+	post, err := s.postUC.CreatePost(ctx, author, req.Title, req.Content, topicID, nil)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to create post")
+	}
+
+	return &pb.CreatePostResponse{
+		Id:   post.ID,
+		Post: convertPostToProto(post),
+	}, nil
+}
+
+func (s *ForumGRPCServer) GetPosts(ctx context.Context, req *pb.GetPostsRequest) (*pb.GetPostsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	query := entity.PostListQuery{Limit: int(req.Limit), Offset: int(req.Offset)}
+	if req.Cursor != "" {
+		cursor, err := entity.ParsePostCursor(req.Cursor)
+		if err != nil {
+			return nil, s.toGRPCError(err, "Invalid cursor")
+		}
+		query.Cursor = cursor
+	}
+
+	page, _, err := s.postUC.GetPosts(ctx, query)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to get posts")
+	}
+
+	resp := &pb.GetPostsResponse{
+		Posts:      make([]*pb.Post, 0, len(page.Posts)),
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	}
+	for _, post := range page.Posts {
+		resp.Posts = append(resp.Posts, convertPostToProto(post))
+	}
+	return resp, nil
+}
+
+func (s *ForumGRPCServer) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	query := entity.SearchQuery{
+		Text:   req.Query,
+		Limit:  int(req.Limit),
+		Offset: int(req.Offset),
+	}
+	if req.AuthorId != 0 {
+		query.AuthorID = &req.AuthorId
+	}
+	if req.TopicId != 0 {
+		query.TopicID = &req.TopicId
+	}
+	if req.From != nil {
+		from := req.From.AsTime()
+		query.From = &from
+	}
+	if req.To != nil {
+		to := req.To.AsTime()
+		query.To = &to
+	}
+
+	page, err := s.searchUC.Search(ctx, query)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to search")
+	}
+
+	resp := &pb.SearchResponse{
+		Results: make([]*pb.SearchResult, 0, len(page.Results)),
+		Total:   page.Total,
+	}
+	for _, result := range page.Results {
+		resp.Results = append(resp.Results, &pb.SearchResult{
+			Type:       result.Type,
+			Id:         result.ID,
+			PostId:     result.PostID,
+			Title:      result.Title,
+			Snippet:    result.Snippet,
+			AuthorId:   result.AuthorID,
+			AuthorName: result.AuthorName,
+			CreatedAt:  timestamppb.New(result.CreatedAt),
+			Rank:       result.Rank,
+		})
+	}
+	return resp, nil
+}
+
+// CreateChatMessage пишет сообщение в комнату room_id (0 — общая) от имени
+// пользователя из токена. В закрытую комнату пишут только ее участники.
+func (s *ForumGRPCServer) CreateChatMessage(ctx context.Context, req *pb.CreateChatMessageRequest) (*pb.CreateChatMessageResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
+	}
+
+	user, err := authmw.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roomID := req.RoomId
+	if roomID == 0 {
+		roomID = entity.GeneralChatRoomID
+	}
+
+	msg, err := s.chatUC.CreateChatMessage(ctx, roomID, user.UserID, req.Content)
+	if err != nil {
+		return nil, s.toGRPCError(err, "Failed to create chat message")
+	}
+
+	return &pb.CreateChatMessageResponse{Id: msg.ID}, nil
+}
+
+// StreamChatMessages отправляет клиенту сообщения комнаты room_id (0 —
+// общая), появившиеся после открытия стрима, пока клиент не отключится.
+// Закрытую комнату читают только ее участники, как в chat-servise.
+func (s *ForumGRPCServer) StreamChatMessages(req *pb.StreamChatMessagesRequest, stream pb.ForumService_StreamChatMessagesServer) error {
+	ctx := stream.Context()
+
+	user, err := authmw.RequirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	roomID := req.GetRoomId()
+	if roomID == 0 {
+		roomID = entity.GeneralChatRoomID
+	}
+	if err := s.chatUC.CanAccessRoom(ctx, roomID, user.UserID); err != nil {
+		return s.toGRPCError(err, "Failed to open chat stream")
+	}
+
+	lastID, err := s.chatUC.GetLastChatMessageID(ctx)
+	if err != nil {
+		return s.toGRPCError(err, "Failed to get chat messages")
+	}
+
+	ticker := time.NewTicker(s.pollEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		messages, err := s.chatUC.GetChatMessagesAfter(ctx, roomID, lastID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return s.toGRPCError(err, "Failed to poll chat messages")
+		}
+
+		for i := range messages {
+			if err := stream.Send(convertChatMessageToProto(&messages[i])); err != nil {
+				return err
+			}
+			lastID = messages[i].ID
+		}
+	}
+}
+
+// requireAdmin — аналог authmw.RequireRole(authmw.RoleAdmin) для gRPC:
+// категории и темы, как и в REST, создают только админы.
+func requireAdmin(ctx context.Context) (*authmw.Principal, error) {
+	user, err := authmw.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsAdmin() {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return user, nil
+}
+
+// toGRPCError переводит ошибку usecase в gRPC-статус. Неожиданные ошибки
+// пишутся в лог, а клиент получает только message: текст ошибок базы
+// наружу не отдается.
+func (s *ForumGRPCServer) toGRPCError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrPostNotFound),
+		errors.Is(err, repository.ErrCategoryNotFound),
+		errors.Is(err, repository.ErrTopicNotFound),
+		errors.Is(err, repository.ErrMessageNotFound),
+		errors.Is(err, repository.ErrChatRoomNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrPermissionDenied),
+		errors.Is(err, usecase.ErrChatRoomAccessDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, repository.ErrCategoryExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, usecase.ErrEmptyCategoryName),
+		errors.Is(err, usecase.ErrEmptyTopicTitle),
+		errors.Is(err, usecase.ErrEmptyMessage),
+		errors.Is(err, entity.ErrInvalidCursor),
+		errors.Is(err, usecase.ErrInvalidPageSize),
+		errors.Is(err, usecase.ErrInvalidOffset),
+		errors.Is(err, usecase.ErrConflictingPagination),
+		errors.Is(err, usecase.ErrEmptySearchQuery),
+		errors.Is(err, usecase.ErrSearchQueryTooLong),
+		errors.Is(err, usecase.ErrInvalidDateRange):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		s.logger.Error(message, err)
+		return status.Error(codes.Internal, message)
+	}
+}
+
+func convertCategoryToProto(category *entity.Category) *pb.Category {
+	return &pb.Category{
+		Id:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+		CreatedAt:   timestamppb.New(category.CreatedAt),
+	}
+}
+
+func convertTopicToProto(topic *entity.Topic) *pb.Topic {
+	return &pb.Topic{
+		Id:         topic.ID,
+		CategoryId: topic.CategoryID,
+		Title:      topic.Title,
+		UserId:     topic.UserID,
+		CreatedAt:  timestamppb.New(topic.CreatedAt),
+	}
+}
+
+func convertMessageToProto(message *entity.Message) *pb.Message {
+	return &pb.Message{
+		Id:        message.ID,
+		TopicId:   message.TopicID,
+		UserId:    message.UserID,
+		Content:   message.Content,
+		CreatedAt: timestamppb.New(message.CreatedAt),
+	}
+}
+
+func convertPostToProto(post *entity.Post) *pb.Post {
+	pbPost := &pb.Post{
+		Id:        post.ID,
+		Title:     post.Title,
+		Content:   post.Content,
+		AuthorId:  post.AuthorID,
+		CreatedAt: timestamppb.New(post.CreatedAt),
+	}
+	if post.TopicID != nil {
+		pbPost.TopicId = *post.TopicID
+	}
+	return pbPost
+}
+
+func convertChatMessageToProto(msg *entity.ChatMessage) *pb.ChatMessage {
+	return &pb.ChatMessage{
+		Id:        msg.ID,
+		UserId:    msg.UserID,
+		Username:  msg.Username,
+		Content:   msg.Content,
+		CreatedAt: timestamppb.New(msg.CreatedAt),
+	}
+}
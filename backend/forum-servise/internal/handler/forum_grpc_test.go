@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type mockCategoryUsecase struct {
+	mock.Mock
+}
+
+func (m *mockCategoryUsecase) CreateCategory(ctx context.Context, name, description string) (*entity.Category, error) {
+	args := m.Called(ctx, name, description)
+	category, _ := args.Get(0).(*entity.Category)
+	return category, args.Error(1)
+}
+
+func (m *mockCategoryUsecase) GetCategory(ctx context.Context, id int64) (*entity.Category, error) {
+	args := m.Called(ctx, id)
+	category, _ := args.Get(0).(*entity.Category)
+	return category, args.Error(1)
+}
+
+func (m *mockCategoryUsecase) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	args := m.Called(ctx)
+	categories, _ := args.Get(0).([]entity.Category)
+	return categories, args.Error(1)
+}
+
+func (m *mockCategoryUsecase) UpdateCategory(ctx context.Context, id int64, name, description string) (*entity.Category, error) {
+	args := m.Called(ctx, id, name, description)
+	category, _ := args.Get(0).(*entity.Category)
+	return category, args.Error(1)
+}
+
+func (m *mockCategoryUsecase) DeleteCategory(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type mockTopicUsecase struct {
+	mock.Mock
+}
+
+func (m *mockTopicUsecase) CreateTopic(ctx context.Context, categoryID, userID int64, title string) (*entity.Topic, error) {
+	args := m.Called(ctx, categoryID, userID, title)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *mockTopicUsecase) GetTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	args := m.Called(ctx, id)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *mockTopicUsecase) GetTopicsByCategory(ctx context.Context, categoryID int64) ([]entity.Topic, error) {
+	args := m.Called(ctx, categoryID)
+	topics, _ := args.Get(0).([]entity.Topic)
+	return topics, args.Error(1)
+}
+
+func (m *mockTopicUsecase) UpdateTopic(ctx context.Context, id int64, title string) (*entity.Topic, error) {
+	args := m.Called(ctx, id, title)
+	topic, _ := args.Get(0).(*entity.Topic)
+	return topic, args.Error(1)
+}
+
+func (m *mockTopicUsecase) DeleteTopic(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type mockChatUsecase struct {
+	mock.Mock
+}
+
+func (m *mockChatUsecase) CreateChatMessage(ctx context.Context, roomID, userID int64, content string) (*entity.ChatMessage, error) {
+	args := m.Called(ctx, roomID, userID, content)
+	msg, _ := args.Get(0).(*entity.ChatMessage)
+	return msg, args.Error(1)
+}
+
+func (m *mockChatUsecase) GetChatMessagesAfter(ctx context.Context, roomID, afterID int64) ([]entity.ChatMessage, error) {
+	args := m.Called(ctx, roomID, afterID)
+	return args.Get(0).([]entity.ChatMessage), args.Error(1)
+}
+
+func (m *mockChatUsecase) GetLastChatMessageID(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockChatUsecase) CanAccessRoom(ctx context.Context, roomID, userID int64) error {
+	return m.Called(ctx, roomID, userID).Error(0)
+}
+
+func TestForumGRPCServer_Category(t *testing.T) {
+	categoryUC := new(mockCategoryUsecase)
+	server := NewForumGRPCServer(categoryUC, nil, nil, nil, nil, nil, newTestLogger())
+	now := time.Now()
+
+	categoryUC.On("CreateCategory", mock.Anything, "Go", "Всё о Go").
+		Return(&entity.Category{ID: 5, Name: "Go", Description: "Всё о Go", CreatedAt: now}, nil)
+	categoryUC.On("GetCategory", mock.Anything, int64(5)).
+		Return(&entity.Category{ID: 5, Name: "Go", Description: "Всё о Go", CreatedAt: now}, nil)
+	categoryUC.On("GetCategory", mock.Anything, int64(6)).
+		Return(nil, repository.ErrCategoryNotFound)
+
+	admin := authmw.NewContext(context.Background(), &authmw.Principal{UserID: 1, Role: authmw.RoleAdmin})
+	created, err := server.CreateCategory(admin, &pb.CreateCategoryRequest{Name: "Go", Description: "Всё о Go"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), created.Id)
+
+	user := authmw.NewContext(context.Background(), &authmw.Principal{UserID: 2, Role: "user"})
+	_, err = server.CreateCategory(user, &pb.CreateCategoryRequest{Name: "Go"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = server.CreateCategory(context.Background(), &pb.CreateCategoryRequest{Name: "Go"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	got, err := server.GetCategory(context.Background(), &pb.GetCategoryRequest{Id: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "Go", got.Category.Name)
+	assert.True(t, got.Category.CreatedAt.AsTime().Equal(now))
+
+	_, err = server.GetCategory(context.Background(), &pb.GetCategoryRequest{Id: 6})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = server.CreateCategory(context.Background(), nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	categoryUC.AssertExpectations(t)
+}
+
+func TestForumGRPCServer_CreateTopic_CategoryNotFound(t *testing.T) {
+	topicUC := new(mockTopicUsecase)
+	server := NewForumGRPCServer(nil, topicUC, nil, nil, nil, nil, newTestLogger())
+
+	topicUC.On("CreateTopic", mock.Anything, int64(9), int64(1), "Новая тема").
+		Return(nil, repository.ErrCategoryNotFound)
+
+	ctx := authmw.NewContext(context.Background(), &authmw.Principal{UserID: 1, Role: authmw.RoleAdmin})
+	_, err := server.CreateTopic(ctx, &pb.CreateTopicRequest{CategoryId: 9, UserId: 7, Title: "Новая тема"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	ctx = authmw.NewContext(context.Background(), &authmw.Principal{UserID: 2, Role: "user"})
+	_, err = server.CreateTopic(ctx, &pb.CreateTopicRequest{CategoryId: 9, Title: "Новая тема"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	topicUC.AssertExpectations(t)
+}
+
+func TestForumGRPCServer_CreatePost(t *testing.T) {
+	postUC := new(mockPostUsecase)
+	server := NewForumGRPCServer(nil, nil, nil, postUC, nil, nil, newTestLogger())
+	author := &authmw.Principal{UserID: 2, Username: "bob"}
+	topicID := int64(4)
+
+	postUC.On("CreatePost", mock.Anything, author, "title", "text", &topicID, []string(nil)).
+		Return(&entity.Post{ID: 10, Title: "title", AuthorID: 2, TopicID: &topicID}, nil).Once()
+	postUC.On("CreatePost", mock.Anything, author, "title", "broken", (*int64)(nil), []string(nil)).
+		Return((*entity.Post)(nil), errors.New(`pq: relation "posts" does not exist`)).Once()
+
+	// Автор берется из токена, а не из author_id запроса.
+	ctx := authmw.NewContext(context.Background(), author)
+	resp, err := server.CreatePost(ctx, &pb.CreatePostRequest{Title: "title", Content: "text", AuthorId: 1, TopicId: 4})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), resp.Id)
+	assert.Equal(t, int64(2), resp.Post.AuthorId)
+
+	_, err = server.CreatePost(context.Background(), &pb.CreatePostRequest{Title: "title", Content: "text"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Текст ошибки базы клиенту не отдается.
+	_, err = server.CreatePost(ctx, &pb.CreatePostRequest{Title: "title", Content: "broken"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "Failed to create post", status.Convert(err).Message())
+	postUC.AssertExpectations(t)
+}
+
+func TestForumGRPCServer_GetPosts(t *testing.T) {
+	postUC := new(mockPostUsecase)
+	server := NewForumGRPCServer(nil, nil, nil, postUC, nil, nil, newTestLogger())
+
+	page := &entity.PostPage{
+		Posts:      []*entity.Post{{ID: 2, Title: "second"}},
+		Total:      3,
+		NextCursor: "next",
+	}
+	postUC.On("GetPosts", mock.Anything, entity.PostListQuery{Limit: 1, Offset: 1}).
+		Return(page, map[int]string{}, nil)
+
+	resp, err := server.GetPosts(context.Background(), &pb.GetPostsRequest{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.Posts, 1)
+	assert.Equal(t, int64(2), resp.Posts[0].Id)
+	assert.Equal(t, int64(3), resp.Total)
+	assert.Equal(t, "next", resp.NextCursor)
+
+	cursor := entity.PostCursor{CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), ID: 2}
+	postUC.On("GetPosts", mock.Anything, entity.PostListQuery{Cursor: &cursor}).
+		Return(&entity.PostPage{Posts: []*entity.Post{}, Total: 3}, map[int]string{}, nil)
+
+	resp, err = server.GetPosts(context.Background(), &pb.GetPostsRequest{Cursor: cursor.String()})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Posts)
+	assert.Empty(t, resp.NextCursor)
+
+	_, err = server.GetPosts(context.Background(), &pb.GetPostsRequest{Cursor: "!!"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	postUC.On("GetPosts", mock.Anything, entity.PostListQuery{Limit: -1}).
+		Return(nil, nil, usecase.ErrInvalidPageSize)
+
+	_, err = server.GetPosts(context.Background(), &pb.GetPostsRequest{Limit: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	postUC.AssertExpectations(t)
+}
+
+func TestForumGRPCServer_Search(t *testing.T) {
+	searchUC := new(mockSearchUsecase)
+	server := NewForumGRPCServer(nil, nil, nil, nil, nil, searchUC, newTestLogger())
+
+	topicID := int64(3)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	created := from.Add(time.Hour)
+	searchUC.On("Search", mock.Anything, entity.SearchQuery{Text: "go", TopicID: &topicID, From: &from, Limit: 10}).
+		Return(&entity.SearchPage{
+			Results: []*entity.SearchResult{{
+				Type: entity.SearchResultComment, ID: 5, PostID: 2, Title: "Post",
+				Snippet: "<mark>go</mark>", AuthorID: 1, AuthorName: "alice", CreatedAt: created, Rank: 0.5,
+			}},
+			Total: 1,
+		}, nil)
+
+	resp, err := server.Search(context.Background(), &pb.SearchRequest{
+		Query: "go", TopicId: 3, From: timestamppb.New(from), Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.Total)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "comment", resp.Results[0].Type)
+	assert.Equal(t, int64(2), resp.Results[0].PostId)
+	assert.Equal(t, "alice", resp.Results[0].AuthorName)
+	assert.True(t, resp.Results[0].CreatedAt.AsTime().Equal(created))
+
+	searchUC.On("Search", mock.Anything, entity.SearchQuery{}).Return(nil, usecase.ErrEmptySearchQuery)
+
+	_, err = server.Search(context.Background(), &pb.SearchRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	searchUC.AssertExpectations(t)
+}
+
+func TestForumGRPCServer_CreateChatMessage(t *testing.T) {
+	chatUC := new(mockChatUsecase)
+	server := NewForumGRPCServer(nil, nil, nil, nil, chatUC, nil, newTestLogger())
+	ctx := authmw.NewContext(context.Background(), &authmw.Principal{UserID: 7, Username: "bob", Role: "user"})
+
+	chatUC.On("CreateChatMessage", mock.Anything, entity.GeneralChatRoomID, int64(7), "привет").
+		Return(&entity.ChatMessage{ID: 11}, nil)
+	chatUC.On("CreateChatMessage", mock.Anything, int64(5), int64(7), "привет").
+		Return(nil, usecase.ErrChatRoomAccessDenied)
+
+	resp, err := server.CreateChatMessage(ctx, &pb.CreateChatMessageRequest{Content: "привет"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), resp.Id)
+
+	_, err = server.CreateChatMessage(ctx, &pb.CreateChatMessageRequest{RoomId: 5, Content: "привет"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = server.CreateChatMessage(context.Background(), &pb.CreateChatMessageRequest{Content: "привет"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	chatUC.AssertExpectations(t)
+}
+
+// dialForumGRPC поднимает server на bufconn с авторизацией через
+// testTokens и возвращает клиента.
+func dialForumGRPC(t *testing.T, server *ForumGRPCServer) pb.ForumServiceClient {
+	auth := authmw.NewAuthenticator(nil, newTestAuthClient())
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(grpc.StreamInterceptor(auth.StreamServerInterceptor()))
+	pb.RegisterForumServiceServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewForumServiceClient(conn)
+}
+
+func TestForumGRPCServer_StreamChatMessages(t *testing.T) {
+	chatUC := new(mockChatUsecase)
+	server := NewForumGRPCServer(nil, nil, nil, nil, chatUC, nil, newTestLogger())
+	server.pollEvery = 10 * time.Millisecond
+	client := dialForumGRPC(t, server)
+
+	chatUC.On("CanAccessRoom", mock.Anything, entity.GeneralChatRoomID, int64(7)).Return(nil)
+	chatUC.On("GetLastChatMessageID", mock.Anything).Return(int64(10), nil)
+	chatUC.On("GetChatMessagesAfter", mock.Anything, entity.GeneralChatRoomID, int64(10)).
+		Return([]entity.ChatMessage{{ID: 11, RoomID: entity.GeneralChatRoomID, UserID: 1, Username: "alice", Content: "привет"}}, nil).Once()
+	chatUC.On("GetChatMessagesAfter", mock.Anything, entity.GeneralChatRoomID, int64(11)).
+		Return([]entity.ChatMessage{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+userToken)
+
+	stream, err := client.StreamChatMessages(ctx, &pb.StreamChatMessagesRequest{})
+	require.NoError(t, err)
+
+	msg, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), msg.Id)
+	assert.Equal(t, "alice", msg.Username)
+	assert.Equal(t, "привет", msg.Content)
+}
+
+func TestForumGRPCServer_StreamChatMessages_Rejected(t *testing.T) {
+	chatUC := new(mockChatUsecase)
+	server := NewForumGRPCServer(nil, nil, nil, nil, chatUC, nil, newTestLogger())
+	client := dialForumGRPC(t, server)
+
+	chatUC.On("CanAccessRoom", mock.Anything, int64(5), int64(7)).Return(usecase.ErrChatRoomAccessDenied)
+	chatUC.On("CanAccessRoom", mock.Anything, int64(6), int64(7)).Return(repository.ErrChatRoomNotFound)
+
+	tests := []struct {
+		name     string
+		token    string
+		roomID   int64
+		wantCode codes.Code
+	}{
+		{name: "Anonymous", roomID: 5, wantCode: codes.Unauthenticated},
+		{name: "Not a member", token: userToken, roomID: 5, wantCode: codes.PermissionDenied},
+		{name: "Room not found", token: userToken, roomID: 6, wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if tt.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.token)
+			}
+
+			stream, err := client.StreamChatMessages(ctx, &pb.StreamChatMessagesRequest{RoomId: tt.roomID})
+			require.NoError(t, err)
+
+			_, err = stream.Recv()
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+	chatUC.AssertNotCalled(t, "GetChatMessagesAfter", mock.Anything, mock.Anything, mock.Anything)
+}
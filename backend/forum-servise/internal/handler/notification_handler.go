@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultNotificationsPageSize = 20
+
+type NotificationHandler struct {
+	uc     *usecase.NotificationUsecase
+	logger *logger.Logger
+}
+
+func NewNotificationHandler(uc *usecase.NotificationUsecase, logger *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{uc: uc, logger: logger}
+}
+
+// ListNotifications godoc
+// @Summary List notifications
+// @Description Notifications of the current user, newest first: comments on their posts, replies, mentions and moderation actions. New notifications are also pushed over the chat WebSocket as "notification" frames
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Notifications per page" default(20)
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/notifications [get]
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	offset, limit, page, err := parsePage(c.Query("page"), c.Query("limit"), defaultNotificationsPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	unreadOnly := false
+	if value := c.Query("unread"); value != "" {
+		if unreadOnly, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unread must be true or false"})
+			return
+		}
+	}
+
+	result, err := h.uc.List(c.Request.Context(), entity.NotificationQuery{
+		UserID:     user.UserID,
+		UnreadOnly: unreadOnly,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		h.respondError(c, err, "Failed to get notifications")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":         result.Notifications,
+		"total":        result.Total,
+		"page":         page,
+		"limit":        limit,
+		"unread_count": result.Unread,
+	})
+}
+
+// UnreadCount godoc
+// @Summary Count unread notifications
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} map[string]int64
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	unread, err := h.uc.UnreadCount(c.Request.Context(), user.UserID)
+	if err != nil {
+		h.respondError(c, err, "Failed to count notifications")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkRead godoc
+// @Summary Mark a notification as read
+// @Description Marking an already read notification keeps its read_at. Returns the remaining unread count
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Notification ID"
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	unread, err := h.uc.MarkRead(c.Request.Context(), user.UserID, id)
+	if err != nil {
+		h.respondError(c, err, "Failed to mark notification as read")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications as read
+// @Description Returns how many notifications were unread
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} map[string]int64
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	marked, err := h.uc.MarkAllRead(c.Request.Context(), user.UserID)
+	if err != nil {
+		h.respondError(c, err, "Failed to mark notifications as read")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked": marked, "unread_count": 0})
+}
+
+// PreferencesRequest — новые настройки; не перечисленные типы не меняются.
+type PreferencesRequest struct {
+	Preferences []entity.NotificationPreference `json:"preferences" binding:"required,dive"`
+}
+
+// GetPreferences godoc
+// @Summary Get notification preferences
+// @Description Whether each notification type is enabled for the current user. All types are enabled by default
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} entity.NotificationPreference
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.uc.Preferences(c.Request.Context(), user.UserID)
+	if err != nil {
+		h.logger.Error("Failed to get notification preferences", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences godoc
+// @Summary Update notification preferences
+// @Description Enable or disable notification types for the current user. Types not listed keep their settings. Disabled types are not delivered at all
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body PreferencesRequest true "Preferences"
+// @Success 200 {array} entity.NotificationPreference
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prefs, err := h.uc.SetPreferences(c.Request.Context(), user.UserID, req.Preferences)
+	switch {
+	case errors.Is(err, usecase.ErrUnknownNotificationType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		h.logger.Error("Failed to update notification preferences", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+	default:
+		c.JSON(http.StatusOK, prefs)
+	}
+}
+
+func (h *NotificationHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case isPaginationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrNotificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+	default:
+		h.logger.Error(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
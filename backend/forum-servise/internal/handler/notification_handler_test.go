@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *mockNotificationRepository) CreateNotifications(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+	args := m.Called(ctx, n, userIDs)
+	return args.Get(0).([]*entity.Notification), args.Error(1)
+}
+
+func (m *mockNotificationRepository) GetNotifications(ctx context.Context, query entity.NotificationQuery) ([]*entity.Notification, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]*entity.Notification), args.Error(1)
+}
+
+func (m *mockNotificationRepository) CountNotifications(ctx context.Context, userID int64, unreadOnly bool) (int64, error) {
+	args := m.Called(ctx, userID, unreadOnly)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockNotificationRepository) MarkRead(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *mockNotificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockNotificationRepository) GetPreferences(ctx context.Context, userID int64) ([]entity.NotificationPreference, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]entity.NotificationPreference), args.Error(1)
+}
+
+func (m *mockNotificationRepository) SetPreference(ctx context.Context, userID int64, pref entity.NotificationPreference) error {
+	args := m.Called(ctx, userID, pref)
+	return args.Error(0)
+}
+
+func newNotificationRouter(repo *mockNotificationRepository) *gin.Engine {
+	h := NewNotificationHandler(usecase.NewNotificationUsecase(repo, newTestLogger()), newTestLogger())
+
+	r := newTestRouter()
+	r.GET("/notifications", r.requireAuth, h.ListNotifications)
+	r.GET("/notifications/unread-count", r.requireAuth, h.UnreadCount)
+	r.POST("/notifications/:id/read", r.requireAuth, h.MarkRead)
+	r.POST("/notifications/read-all", r.requireAuth, h.MarkAllRead)
+	r.GET("/notifications/preferences", r.requireAuth, h.GetPreferences)
+	r.PUT("/notifications/preferences", r.requireAuth, h.UpdatePreferences)
+	return r.Engine
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	repo := new(mockNotificationRepository)
+	r := newNotificationRouter(repo)
+
+	disabled := entity.NotificationPreference{Type: entity.NotificationMention, Enabled: false}
+	repo.On("SetPreference", mock.Anything, int64(7), disabled).Return(nil)
+	repo.On("GetPreferences", mock.Anything, int64(7)).Return([]entity.NotificationPreference{disabled}, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		token  string
+		want   int
+	}{
+		{name: "Get", method: http.MethodGet, token: userToken, want: http.StatusOK},
+		{name: "Disable mentions", method: http.MethodPut, body: `{"preferences": [{"type": "mention", "enabled": false}]}`, token: userToken, want: http.StatusOK},
+		{name: "Unknown type", method: http.MethodPut, body: `{"preferences": [{"type": "spam", "enabled": false}]}`, token: userToken, want: http.StatusBadRequest},
+		{name: "Missing type", method: http.MethodPut, body: `{"preferences": [{"enabled": false}]}`, token: userToken, want: http.StatusBadRequest},
+		{name: "Missing preferences", method: http.MethodPut, body: `{}`, token: userToken, want: http.StatusBadRequest},
+		{name: "Anonymous", method: http.MethodGet, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(tt.method, "/notifications/preferences", tt.body, tt.token))
+			require.Equal(t, tt.want, w.Code, w.Body.String())
+
+			if tt.want == http.StatusOK {
+				var prefs []entity.NotificationPreference
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prefs))
+				assert.Len(t, prefs, len(entity.NotificationTypes))
+				assert.Contains(t, prefs, disabled)
+				assert.Contains(t, prefs, entity.NotificationPreference{Type: entity.NotificationReply, Enabled: true})
+			}
+		})
+	}
+	repo.AssertNumberOfCalls(t, "SetPreference", 1)
+}
+
+func TestListNotifications(t *testing.T) {
+	repo := new(mockNotificationRepository)
+	r := newNotificationRouter(repo)
+
+	postID := int64(3)
+	notifications := []*entity.Notification{{ID: 9, UserID: 7, Type: entity.NotificationComment, ActorID: 2,
+		ActorName: "alice", Subject: entity.Subject{PostID: &postID}}}
+	repo.On("GetNotifications", mock.Anything, entity.NotificationQuery{UserID: 7, Limit: 20}).Return(notifications, nil)
+	repo.On("GetNotifications", mock.Anything, entity.NotificationQuery{UserID: 7, UnreadOnly: true, Limit: 5, Offset: 5}).
+		Return([]*entity.Notification{}, nil)
+	repo.On("CountNotifications", mock.Anything, int64(7), false).Return(int64(1), nil)
+	repo.On("CountNotifications", mock.Anything, int64(7), true).Return(int64(1), nil)
+
+	tests := []struct {
+		name  string
+		url   string
+		token string
+		want  int
+	}{
+		{name: "First page", url: "/notifications", token: userToken, want: http.StatusOK},
+		{name: "Unread page", url: "/notifications?unread=true&page=2&limit=5", token: userToken, want: http.StatusOK},
+		{name: "Invalid unread", url: "/notifications?unread=maybe", token: userToken, want: http.StatusBadRequest},
+		{name: "Invalid page", url: "/notifications?page=0", token: userToken, want: http.StatusBadRequest},
+		{name: "Limit too large", url: "/notifications?limit=1000", token: userToken, want: http.StatusBadRequest},
+		{name: "Anonymous", url: "/notifications", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(http.MethodGet, tt.url, "", tt.token))
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodGet, "/notifications", "", userToken))
+	var response struct {
+		Data   []*entity.Notification `json:"data"`
+		Total  int64                  `json:"total"`
+		Page   int                    `json:"page"`
+		Unread int64                  `json:"unread_count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, notifications, response.Data)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Equal(t, 1, response.Page)
+	assert.Equal(t, int64(1), response.Unread)
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	repo := new(mockNotificationRepository)
+	r := newNotificationRouter(repo)
+
+	repo.On("MarkRead", mock.Anything, int64(7), int64(9)).Return(nil)
+	repo.On("MarkRead", mock.Anything, int64(7), int64(10)).Return(repository.ErrNotificationNotFound)
+	repo.On("CountNotifications", mock.Anything, int64(7), true).Return(int64(2), nil)
+	repo.On("MarkAllRead", mock.Anything, int64(7)).Return(int64(2), nil)
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		token    string
+		want     int
+		wantBody string
+	}{
+		{name: "Mark read", method: http.MethodPost, url: "/notifications/9/read", token: userToken, want: http.StatusOK, wantBody: `{"unread_count":2}`},
+		{name: "Someone else's notification", method: http.MethodPost, url: "/notifications/10/read", token: userToken, want: http.StatusNotFound},
+		{name: "Invalid ID", method: http.MethodPost, url: "/notifications/abc/read", token: userToken, want: http.StatusBadRequest},
+		{name: "Mark all read", method: http.MethodPost, url: "/notifications/read-all", token: userToken, want: http.StatusOK, wantBody: `{"marked":2,"unread_count":0}`},
+		{name: "Unread count", method: http.MethodGet, url: "/notifications/unread-count", token: userToken, want: http.StatusOK, wantBody: `{"unread_count":2}`},
+		{name: "Anonymous", method: http.MethodPost, url: "/notifications/read-all", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(tt.method, tt.url, "", tt.token))
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+)
+
+var (
+	errInvalidPage   = errors.New("page must be a positive integer")
+	errInvalidWindow = errors.New("window must be one of day, week, month, year, all")
+)
+
+// topWindows — периоды для sort=top; all — за все время.
+var topWindows = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+	"year":  365 * 24 * time.Hour,
+	"all":   0,
+}
+
+// parsePostListQuery разбирает параметры ленты. Номер страницы переводится
+// в смещение, поэтому размер страницы по умолчанию подставляется здесь же.
+func parsePostListQuery(cursor, page, limit string) (entity.PostListQuery, int, error) {
+	if cursor != "" {
+		query := entity.PostListQuery{Limit: usecase.DefaultPostsPageSize}
+		if page != "" {
+			return query, 0, usecase.ErrConflictingPagination
+		}
+		n, err := parseLimit(limit, usecase.DefaultPostsPageSize)
+		if err != nil {
+			return query, 0, err
+		}
+		query.Limit = n
+		query.Cursor, err = entity.ParsePostCursor(cursor)
+		return query, 0, err
+	}
+
+	offset, n, pageNum, err := parsePage(page, limit, usecase.DefaultPostsPageSize)
+	if err != nil {
+		return entity.PostListQuery{}, 0, err
+	}
+	return entity.PostListQuery{Offset: offset, Limit: n}, pageNum, nil
+}
+
+// parsePage переводит page/limit в смещение. Пустые значения — первая
+// страница размера defaultLimit; верхнюю границу limit проверяет usecase.
+func parsePage(page, limit string, defaultLimit int) (offset, n, pageNum int, err error) {
+	n, err = parseLimit(limit, defaultLimit)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	pageNum = 1
+	if page != "" {
+		pageNum, err = strconv.Atoi(page)
+		if err != nil || pageNum < 1 {
+			return 0, 0, 0, errInvalidPage
+		}
+	}
+	return (pageNum - 1) * n, n, pageNum, nil
+}
+
+func parseLimit(limit string, defaultLimit int) (int, error) {
+	if limit == "" {
+		return defaultLimit, nil
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil || n < 1 {
+		return 0, usecase.ErrInvalidPageSize
+	}
+	return n, nil
+}
+
+// parseTopWindow переводит window в начало периода для sort=top.
+// Пустое значение и all — без ограничения.
+func parseTopWindow(window string, now time.Time) (*time.Time, error) {
+	if window == "" {
+		return nil, nil
+	}
+	d, ok := topWindows[window]
+	if !ok {
+		return nil, errInvalidWindow
+	}
+	if d == 0 {
+		return nil, nil
+	}
+	since := now.Add(-d)
+	return &since, nil
+}
+
+func isPaginationError(err error) bool {
+	return errors.Is(err, entity.ErrInvalidCursor) ||
+		errors.Is(err, usecase.ErrInvalidPageSize) ||
+		errors.Is(err, usecase.ErrInvalidOffset) ||
+		errors.Is(err, usecase.ErrConflictingPagination) ||
+		errors.Is(err, usecase.ErrCursorNotSupported)
+}
@@ -4,10 +4,11 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
+	"backend.com/forum/authmw"
 	_ "github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/docs"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
@@ -26,13 +27,13 @@ func NewPostHandler(uc usecase.PostUsecaseInterface, logger *logger.Logger) *Pos
 
 // CreatePost godoc
 // @Summary Создать новый пост
-// @Description Создает новый пост в системе
+// @Description Создает новый пост в системе. Текст принимается в markdown и возвращается еще и очищенным HTML в content_html. Теги приводятся к нижнему регистру, повторы отбрасываются; не больше 5 тегов
 // @Tags Посты
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer токен"
-// @Param request body entity.Post true "Данные поста"
+// @Param request body entity.CreatePostRequest true "Данные поста"
 // @Success 201 {object} entity.Post
 // @Failure 400 {object} entity.ErrorResponse
 // @Failure 401 {object} entity.ErrorResponse
@@ -40,17 +41,16 @@ func NewPostHandler(uc usecase.PostUsecaseInterface, logger *logger.Logger) *Pos
 // @Router /api/v1/posts [post]
 
 func (h *PostHandler) CreatePost(ctx *gin.Context) {
-	authHeader := ctx.GetHeader("Authorization")
-	if authHeader == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
 
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-
 	var request struct {
-		Title   string `json:"title" binding:"required"`
-		Content string `json:"content" binding:"required"`
+		Title   string   `json:"title" binding:"required"`
+		Content string   `json:"content" binding:"required"`
+		TopicID *int64   `json:"topic_id"`
+		Tags    []string `json:"tags"`
 	}
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
@@ -58,8 +58,16 @@ func (h *PostHandler) CreatePost(ctx *gin.Context) {
 		return
 	}
 
-	post, err := h.uc.CreatePost(ctx.Request.Context(), token, request.Title, request.Content)
+	post, err := h.uc.CreatePost(ctx.Request.Context(), user, request.Title, request.Content, request.TopicID, request.Tags)
 	if err != nil {
+		if errors.Is(err, repository.ErrTopicNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Topic not found"})
+			return
+		}
+		if isTagError(err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to create post", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
 		return
@@ -74,18 +82,45 @@ func (h *PostHandler) CreatePost(ctx *gin.Context) {
 
 // GetPosts godoc
 // @Summary Get all posts
-// @Description Get list of all forum posts with pagination
+// @Description Get list of forum posts. sort=new (default) is newest first, top is highest score within window, hot is score decayed by post age. Pages are addressed by page number or, for sort=new only, by the opaque next_cursor from the previous response. tag filters posts by tags (repeat it for several); tag_mode=all (default) keeps posts having every tag, any — at least one. Every post carries its tags and reactions; with a token also the caller's my_vote and own reactions marked mine
 // @Tags posts
 // @Accept json
 // @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param sort query string false "Order: new, top or hot" default(new)
+// @Param window query string false "Period for sort=top: day, week, month, year or all" default(all)
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Posts per page" default(10)
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param tag query []string false "Tag filter" collectionFormat(multi)
+// @Param tag_mode query string false "How several tags combine: all or any" default(all)
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
 // @Failure 500 {object} entity.ErrorResponse
 // @Router /api/v1/posts [get]
 func (h *PostHandler) GetPosts(c *gin.Context) {
-	posts, authorNames, err := h.uc.GetPosts(c.Request.Context())
+	query, page, err := parsePostListQuery(c.Query("cursor"), c.Query("page"), c.Query("limit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.Sort = c.Query("sort")
+	if query.Since, err = parseTopWindow(c.Query("window"), time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if user, ok := authmw.Current(c); ok {
+		query.ViewerID = user.UserID
+	}
+	query.Tags = c.QueryArray("tag")
+	query.TagMode = c.Query("tag_mode")
+
+	result, authorNames, err := h.uc.GetPosts(c.Request.Context(), query)
 	if err != nil {
+		if isPaginationError(err) || errors.Is(err, usecase.ErrInvalidSort) || isTagError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("Failed to get posts", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to get posts",
@@ -94,26 +129,79 @@ func (h *PostHandler) GetPosts(c *gin.Context) {
 		return
 	}
 
+	response := gin.H{
+		"data":        postsResponse(result.Posts, authorNames),
+		"total":       result.Total,
+		"limit":       query.Limit,
+		"next_cursor": result.NextCursor,
+	}
+	if query.Cursor == nil {
+		response["page"] = page
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPostsByTopic godoc
+// @Summary Get posts of a topic
+// @Description Get list of posts linked to the topic. Every post carries its reactions; with a token also the caller's my_vote and own reactions marked mine
+// @Tags posts
+// @Accept json
+// @Produce json
+// @Param Authorization header string false "Bearer token"
+// @Param id path int true "Topic ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/topics/{id}/posts [get]
+func (h *PostHandler) GetPostsByTopic(c *gin.Context) {
+	topicID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	var viewerID int64
+	if user, ok := authmw.Current(c); ok {
+		viewerID = user.UserID
+	}
+
+	posts, authorNames, err := h.uc.GetPostsByTopic(c.Request.Context(), topicID, viewerID)
+	if err != nil {
+		h.logger.Error("Failed to get topic posts", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get posts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": postsResponse(posts, authorNames),
+	})
+}
+
+func postsResponse(posts []*entity.Post, authorNames map[int]string) []gin.H {
 	response := make([]gin.H, 0, len(posts))
 	for _, post := range posts {
 		response = append(response, gin.H{
-			"id":          post.ID,
-			"title":       post.Title,
-			"content":     post.Content,
-			"author_id":   post.AuthorID,
-			"author_name": authorNames[int(post.AuthorID)],
-			"created_at":  post.CreatedAt.Format(time.RFC3339),
+			"id":           post.ID,
+			"title":        post.Title,
+			"content":      post.Content,
+			"content_html": post.ContentHTML,
+			"author_id":    post.AuthorID,
+			"author_name":  authorNames[int(post.AuthorID)],
+			"created_at":   post.CreatedAt.Format(time.RFC3339),
+			"topic_id":     post.TopicID,
+			"score":        post.Score,
+			"my_vote":      post.MyVote,
+			"reactions":    post.Reactions,
+			"tags":         post.Tags,
+			"attachments":  post.Attachments,
 		})
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"data": response,
-	})
+	return response
 }
 
 // DeletePost godoc
 // @Summary Delete a post
-// @Description Delete a forum post by ID (only author or admin can delete)
+// @Description Move a forum post to the trash (only author or admin can delete). An admin can restore it until it is purged
 // @Tags posts
 // @Accept json
 // @Produce json
@@ -134,20 +222,17 @@ func (h *PostHandler) DeletePost(ctx *gin.Context) {
 		return
 	}
 
-	authHeader := ctx.GetHeader("Authorization")
-	if authHeader == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
 
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-
 	h.logger.Debug("Attempting to delete post",
 		zap.Int64("post_id", postID),
-		zap.String("token", token),
+		zap.Int64("user_id", user.UserID),
 	)
 
-	if err := h.uc.DeletePost(ctx.Request.Context(), token, postID); err != nil {
+	if err := h.uc.DeletePost(ctx.Request.Context(), user, postID); err != nil {
 		h.logger.Error("Failed to delete post", err)
 
 		switch {
@@ -166,7 +251,7 @@ func (h *PostHandler) DeletePost(ctx *gin.Context) {
 
 // UpdatePost godoc
 // @Summary Update a post
-// @Description Update an existing forum post (only author can update)
+// @Description Update an existing forum post (author or admin). The previous text stays in the post's revision history. tags replaces the post's tags; without it they stay as they were
 // @Tags posts
 // @Accept json
 // @Produce json
@@ -188,16 +273,15 @@ func (h *PostHandler) UpdatePost(ctx *gin.Context) {
 		return
 	}
 
-	authHeader := ctx.GetHeader("Authorization")
-	if authHeader == "" {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+	user, ok := currentUser(ctx)
+	if !ok {
 		return
 	}
-	token := strings.TrimPrefix(authHeader, "Bearer ")
 
 	var request struct {
-		Title   string `json:"title" binding:"required"`
-		Content string `json:"content" binding:"required"`
+		Title   string   `json:"title" binding:"required"`
+		Content string   `json:"content" binding:"required"`
+		Tags    []string `json:"tags"`
 	}
 
 	if err := ctx.ShouldBindJSON(&request); err != nil {
@@ -205,9 +289,11 @@ func (h *PostHandler) UpdatePost(ctx *gin.Context) {
 		return
 	}
 
-	updatedPost, err := h.uc.UpdatePost(ctx.Request.Context(), token, postID, request.Title, request.Content)
+	updatedPost, err := h.uc.UpdatePost(ctx.Request.Context(), user, postID, request.Title, request.Content, request.Tags)
 	if err != nil {
 		switch {
+		case isTagError(err):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case errors.Is(err, repository.ErrPostNotFound):
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		case errors.Is(err, repository.ErrPermissionDenied):
@@ -224,3 +310,10 @@ func (h *PostHandler) UpdatePost(ctx *gin.Context) {
 		"post":    updatedPost,
 	})
 }
+
+// isTagError — ошибка в тегах из запроса.
+func isTagError(err error) bool {
+	return errors.Is(err, usecase.ErrInvalidTag) ||
+		errors.Is(err, usecase.ErrTooManyTags) ||
+		errors.Is(err, usecase.ErrInvalidTagMode)
+}
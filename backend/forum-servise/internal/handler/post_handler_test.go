@@ -3,14 +3,16 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"backend.com/forum/authmw"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
-	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -20,28 +22,40 @@ type mockPostUsecase struct {
 	mock.Mock
 }
 
-func (m *mockPostUsecase) CreatePost(ctx context.Context, token, title, content string) (*entity.Post, error) {
-	args := m.Called(ctx, token, title, content)
+func (m *mockPostUsecase) CreatePost(ctx context.Context, author *authmw.Principal, title, content string, topicID *int64, tags []string) (*entity.Post, error) {
+	args := m.Called(ctx, author, title, content, topicID, tags)
 	return args.Get(0).(*entity.Post), args.Error(1)
 }
 
-func (m *mockPostUsecase) GetPosts(ctx context.Context) ([]*entity.Post, map[int]string, error) {
-	args := m.Called(ctx)
+func (m *mockPostUsecase) GetPosts(ctx context.Context, query entity.PostListQuery) (*entity.PostPage, map[int]string, error) {
+	args := m.Called(ctx, query)
+	page, _ := args.Get(0).(*entity.PostPage)
+	names, _ := args.Get(1).(map[int]string)
+	return page, names, args.Error(2)
+}
+
+func (m *mockPostUsecase) GetPostsByTopic(ctx context.Context, topicID, viewerID int64) ([]*entity.Post, map[int]string, error) {
+	args := m.Called(ctx, topicID, viewerID)
 	return args.Get(0).([]*entity.Post), args.Get(1).(map[int]string), args.Error(2)
 }
 
-func (m *mockPostUsecase) DeletePost(ctx context.Context, token string, postID int64) error {
-	args := m.Called(ctx, token, postID)
+func (m *mockPostUsecase) DeletePost(ctx context.Context, user *authmw.Principal, postID int64) error {
+	args := m.Called(ctx, user, postID)
 	return args.Error(0)
 }
 
-func (m *mockPostUsecase) UpdatePost(ctx context.Context, token string, postID int64, title, content string) (*entity.Post, error) {
-	args := m.Called(ctx, token, postID, title, content)
+func (m *mockPostUsecase) UpdatePost(ctx context.Context, user *authmw.Principal, postID int64, title, content string, tags []string) (*entity.Post, error) {
+	args := m.Called(ctx, user, postID, title, content, tags)
 	return args.Get(0).(*entity.Post), args.Error(1)
 }
 
-func newTestLogger() *logger.Logger {
-	return &logger.Logger{SugaredLogger: nil}
+var testUser = &authmw.Principal{UserID: 42, Username: "alice", Role: "user"}
+
+// withUser подменяет middleware authmw: кладёт в запрос уже проверенного пользователя.
+func withUser(user *authmw.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(authmw.NewContext(c.Request.Context(), user))
+	}
 }
 
 func TestCreatePost(t *testing.T) {
@@ -51,7 +65,7 @@ func TestCreatePost(t *testing.T) {
 	handler := NewPostHandler(mockUC, newTestLogger())
 
 	r := gin.Default()
-	r.POST("/posts", handler.CreatePost)
+	r.POST("/posts", withUser(testUser), handler.CreatePost)
 
 	post := &entity.Post{
 		ID:        1,
@@ -61,12 +75,11 @@ func TestCreatePost(t *testing.T) {
 		CreatedAt: time.Now(),
 	}
 
-	mockUC.On("CreatePost", mock.Anything, "valid-token", "Test Title", "Test Content").
+	mockUC.On("CreatePost", mock.Anything, testUser, "Test Title", "Test Content", (*int64)(nil), []string(nil)).
 		Return(post, nil)
 
 	body := `{"title":"Test Title", "content":"Test Content"}`
 	req, _ := http.NewRequest(http.MethodPost, "/posts", bytes.NewBufferString(body))
-	req.Header.Set("Authorization", "Bearer valid-token")
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -76,6 +89,26 @@ func TestCreatePost(t *testing.T) {
 	mockUC.AssertExpectations(t)
 }
 
+func TestCreatePost_WithoutUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(mockPostUsecase)
+	handler := NewPostHandler(mockUC, newTestLogger())
+
+	r := gin.Default()
+	r.POST("/posts", handler.CreatePost)
+
+	body := `{"title":"Test Title", "content":"Test Content"}`
+	req, _ := http.NewRequest(http.MethodPost, "/posts", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockUC.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestGetPosts(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -86,18 +119,235 @@ func TestGetPosts(t *testing.T) {
 	r.GET("/posts", handler.GetPosts)
 
 	mockPosts := []*entity.Post{
-		{ID: 1, Title: "Test", Content: "Body", AuthorID: 1, CreatedAt: time.Now()},
+		{ID: 1, Title: "Test", Content: "*Body*", ContentHTML: "<p><em>Body</em></p>\n", AuthorID: 1, CreatedAt: time.Now()},
 	}
 	authors := map[int]string{1: "Alice"}
 
-	mockUC.On("GetPosts", mock.Anything).Return(mockPosts, authors, nil)
+	mockUC.On("GetPosts", mock.Anything, entity.PostListQuery{Limit: 5, Offset: 10}).
+		Return(&entity.PostPage{Posts: mockPosts, Total: 11, NextCursor: "next"}, authors, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/posts?page=3&limit=5", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data       []map[string]interface{} `json:"data"`
+		Total      int64                    `json:"total"`
+		Page       int                      `json:"page"`
+		Limit      int                      `json:"limit"`
+		NextCursor string                   `json:"next_cursor"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, "<p><em>Body</em></p>\n", body.Data[0]["content_html"])
+	assert.Equal(t, int64(11), body.Total)
+	assert.Equal(t, 3, body.Page)
+	assert.Equal(t, 5, body.Limit)
+	assert.Equal(t, "next", body.NextCursor)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetPosts_Cursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(mockPostUsecase)
+	handler := NewPostHandler(mockUC, newTestLogger())
+
+	r := gin.Default()
+	r.GET("/posts", handler.GetPosts)
+
+	cursor := entity.PostCursor{CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), ID: 7}
+	mockUC.On("GetPosts", mock.Anything, entity.PostListQuery{Cursor: &cursor, Limit: usecase.DefaultPostsPageSize}).
+		Return(&entity.PostPage{Posts: []*entity.Post{}, Total: 7}, map[int]string{}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/posts?cursor="+cursor.String(), nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"page"`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetPosts_Tags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(mockPostUsecase)
+	handler := NewPostHandler(mockUC, newTestLogger())
+
+	r := gin.Default()
+	r.GET("/posts", handler.GetPosts)
+
+	query := entity.PostListQuery{Limit: usecase.DefaultPostsPageSize, Tags: []string{"go", "grpc"}, TagMode: entity.TagMatchAny}
+	mockUC.On("GetPosts", mock.Anything, query).
+		Return(&entity.PostPage{Posts: []*entity.Post{{ID: 1, Tags: []string{"go"}}}, Total: 1}, map[int]string{}, nil)
+	mockUC.On("GetPosts", mock.Anything, entity.PostListQuery{Limit: usecase.DefaultPostsPageSize, Tags: []string{"go"}, TagMode: "none"}).
+		Return(nil, nil, usecase.ErrInvalidTagMode)
+
+	req, _ := http.NewRequest(http.MethodGet, "/posts?tag=go&tag=grpc&tag_mode=any", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tags":["go"]`)
+
+	req, _ = http.NewRequest(http.MethodGet, "/posts?tag=go&tag_mode=none", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetPosts_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(mockPostUsecase)
+	handler := NewPostHandler(mockUC, newTestLogger())
+
+	r := gin.Default()
+	r.GET("/posts", handler.GetPosts)
+
+	for _, query := range []string{"page=0", "page=abc", "limit=0", "limit=x", "cursor=%21%21", "cursor=MTox&page=2"} {
+		req, _ := http.NewRequest(http.MethodGet, "/posts?"+query, nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockUC.AssertNotCalled(t, "GetPosts", mock.Anything, mock.Anything)
+}
+
+func TestGetPosts_LimitTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(mockPostUsecase)
+	handler := NewPostHandler(mockUC, newTestLogger())
+
+	r := gin.Default()
+	r.GET("/posts", handler.GetPosts)
+
+	mockUC.On("GetPosts", mock.Anything, entity.PostListQuery{Limit: 500}).
+		Return(nil, nil, usecase.ErrInvalidPageSize)
+
+	req, _ := http.NewRequest(http.MethodGet, "/posts?limit=500", nil)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetPosts_SortAndWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(mockPostUsecase)
+	handler := NewPostHandler(mockUC, newTestLogger())
+
+	r := gin.Default()
+	r.GET("/posts", handler.GetPosts)
+	r.GET("/me/posts", withUser(testUser), handler.GetPosts)
+
+	before := time.Now()
+	mockUC.On("GetPosts", mock.Anything, mock.MatchedBy(func(q entity.PostListQuery) bool {
+		return q.Sort == entity.PostSortTop && q.Since != nil &&
+			!q.Since.After(before.Add(-7*24*time.Hour).Add(time.Minute)) && q.ViewerID == 0
+	})).Return(&entity.PostPage{Posts: []*entity.Post{{ID: 1, Score: 4}}, Total: 1}, map[int]string{}, nil)
+	mockUC.On("GetPosts", mock.Anything, mock.MatchedBy(func(q entity.PostListQuery) bool {
+		return q.Sort == entity.PostSortHot && q.ViewerID == testUser.UserID
+	})).Return(&entity.PostPage{Posts: []*entity.Post{{ID: 2, Score: 1, MyVote: 1}}, Total: 1}, map[int]string{}, nil)
+	mockUC.On("GetPosts", mock.Anything, mock.MatchedBy(func(q entity.PostListQuery) bool {
+		return q.Sort == "random"
+	})).Return(nil, nil, usecase.ErrInvalidSort)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts?sort=top&window=week", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"score":4`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me/posts?sort=hot", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"my_vote":1`)
+
+	for _, query := range []string{"sort=random", "sort=top&window=century"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockUC.AssertExpectations(t)
+}
+
+func TestCreatePost_WithTopic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(mockPostUsecase)
+	handler := NewPostHandler(mockUC, newTestLogger())
+
+	r := gin.Default()
+	r.POST("/posts", withUser(testUser), handler.CreatePost)
 
-	req, _ := http.NewRequest(http.MethodGet, "/posts", nil)
+	topicID := int64(7)
+	mockUC.On("CreatePost", mock.Anything, testUser, "Test Title", "Test Content", &topicID, []string(nil)).
+		Return((*entity.Post)(nil), repository.ErrTopicNotFound)
+
+	body := `{"title":"Test Title", "content":"Test Content", "topic_id": 7}`
+	req, _ := http.NewRequest(http.MethodPost, "/posts", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
 	r.ServeHTTP(w, req)
 
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetPostsByTopic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockUC := new(mockPostUsecase)
+	handler := NewPostHandler(mockUC, newTestLogger())
+
+	r := gin.Default()
+	r.GET("/topics/:id/posts", handler.GetPostsByTopic)
+
+	topicID := int64(3)
+	mockPosts := []*entity.Post{
+		{ID: 1, Title: "Test", Content: "Body", AuthorID: 1, TopicID: &topicID, CreatedAt: time.Now()},
+	}
+	mockUC.On("GetPostsByTopic", mock.Anything, int64(3), int64(0)).Return(mockPosts, map[int]string{1: "Alice"}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, "/topics/3/posts", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"topic_id":3`)
+
+	req, _ = http.NewRequest(http.MethodGet, "/topics/abc/posts", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// С токеном в пост попадает голос читателя.
+	viewer := gin.New()
+	viewer.GET("/topics/:id/posts", withUser(testUser), handler.GetPostsByTopic)
+	voted := []*entity.Post{{ID: 1, AuthorID: 1, TopicID: &topicID, MyVote: 1, CreatedAt: time.Now()}}
+	mockUC.On("GetPostsByTopic", mock.Anything, int64(3), testUser.UserID).Return(voted, map[int]string{}, nil)
+
+	req, _ = http.NewRequest(http.MethodGet, "/topics/3/posts", nil)
+	w = httptest.NewRecorder()
+	viewer.ServeHTTP(w, req)
+
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"my_vote":1`)
 	mockUC.AssertExpectations(t)
 }
 
@@ -108,12 +358,11 @@ func TestDeletePost_Success(t *testing.T) {
 	handler := NewPostHandler(mockUC, newTestLogger())
 
 	r := gin.Default()
-	r.DELETE("/posts/:id", handler.DeletePost)
+	r.DELETE("/posts/:id", withUser(testUser), handler.DeletePost)
 
-	mockUC.On("DeletePost", mock.Anything, "valid-token", int64(1)).Return(nil)
+	mockUC.On("DeletePost", mock.Anything, testUser, int64(1)).Return(nil)
 
 	req, _ := http.NewRequest(http.MethodDelete, "/posts/1", nil)
-	req.Header.Set("Authorization", "Bearer valid-token")
 	w := httptest.NewRecorder()
 
 	r.ServeHTTP(w, req)
@@ -129,12 +378,11 @@ func TestDeletePost_NotFound(t *testing.T) {
 	handler := NewPostHandler(mockUC, newTestLogger())
 
 	r := gin.Default()
-	r.DELETE("/posts/:id", handler.DeletePost)
+	r.DELETE("/posts/:id", withUser(testUser), handler.DeletePost)
 
-	mockUC.On("DeletePost", mock.Anything, "valid-token", int64(2)).Return(repository.ErrPostNotFound)
+	mockUC.On("DeletePost", mock.Anything, testUser, int64(2)).Return(repository.ErrPostNotFound)
 
 	req, _ := http.NewRequest(http.MethodDelete, "/posts/2", nil)
-	req.Header.Set("Authorization", "Bearer valid-token")
 	w := httptest.NewRecorder()
 
 	r.ServeHTTP(w, req)
@@ -149,7 +397,7 @@ func TestUpdatePost(t *testing.T) {
 	handler := NewPostHandler(mockUC, newTestLogger())
 
 	r := gin.Default()
-	r.PUT("/posts/:id", handler.UpdatePost)
+	r.PUT("/posts/:id", withUser(testUser), handler.UpdatePost)
 
 	post := &entity.Post{
 		ID:        1,
@@ -159,12 +407,11 @@ func TestUpdatePost(t *testing.T) {
 		CreatedAt: time.Now(),
 	}
 
-	mockUC.On("UpdatePost", mock.Anything, "valid-token", int64(1), "Updated", "Updated content").
+	mockUC.On("UpdatePost", mock.Anything, testUser, int64(1), "Updated", "Updated content", []string(nil)).
 		Return(post, nil)
 
 	body := `{"title":"Updated", "content":"Updated content"}`
 	req, _ := http.NewRequest(http.MethodPut, "/posts/1", bytes.NewBufferString(body))
-	req.Header.Set("Authorization", "Bearer valid-token")
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type ReactionHandler struct {
+	uc     *usecase.ReactionUsecase
+	logger *logger.Logger
+}
+
+func NewReactionHandler(uc *usecase.ReactionUsecase, logger *logger.Logger) *ReactionHandler {
+	return &ReactionHandler{uc: uc, logger: logger}
+}
+
+// ReactionRequest — эмодзи из разрешенного набора.
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required" example:"👍"`
+}
+
+// ListReactions godoc
+// @Summary Allowed reactions
+// @Description Emoji that can be put on posts and comments, in display order
+// @Tags reactions
+// @Produce json
+// @Success 200 {object} map[string][]string
+// @Router /api/v1/reactions [get]
+func (h *ReactionHandler) ListReactions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"reactions": h.uc.Allowed()})
+}
+
+// TogglePostReaction godoc
+// @Summary Toggle a reaction on a post
+// @Description Puts the emoji on the post or removes it if the caller already reacted with it. Returns all reactions of the post afterwards
+// @Tags reactions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param request body ReactionRequest true "Reaction"
+// @Success 200 {object} entity.ReactionResult
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/reactions [post]
+func (h *ReactionHandler) TogglePostReaction(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	emoji, ok := bindReaction(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.uc.TogglePostReaction(c.Request.Context(), user, postID, emoji)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ToggleCommentReaction godoc
+// @Summary Toggle a reaction on a comment
+// @Description Puts the emoji on the comment or removes it if the caller already reacted with it. Returns all reactions of the comment afterwards
+// @Tags reactions
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param commentId path int true "Comment ID"
+// @Param request body ReactionRequest true "Reaction"
+// @Success 200 {object} entity.ReactionResult
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/comments/{commentId}/reactions [post]
+func (h *ReactionHandler) ToggleCommentReaction(c *gin.Context) {
+	postID, commentID, ok := commentPathIDs(c)
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	emoji, ok := bindReaction(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.uc.ToggleCommentReaction(c.Request.Context(), user, postID, commentID, emoji)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func bindReaction(c *gin.Context) (string, bool) {
+	var request ReactionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return "", false
+	}
+	return request.Emoji, true
+}
+
+func (h *ReactionHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUnknownReaction):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	case errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+	default:
+		h.logger.Error("Failed to toggle reaction", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to toggle reaction"})
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *mockPostRepository) TogglePostReaction(ctx context.Context, postID, userID int64, emoji string) (bool, error) {
+	args := m.Called(ctx, postID, userID, emoji)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockPostRepository) GetPostReactions(ctx context.Context, viewerID int64, postIDs []int64) (map[int64][]entity.Reaction, error) {
+	args := m.Called(ctx, viewerID, postIDs)
+	reactions, _ := args.Get(0).(map[int64][]entity.Reaction)
+	return reactions, args.Error(1)
+}
+
+func newReactionRouter(posts *mockPostRepository, comments *MockCommentRepository) *gin.Engine {
+	uc := usecase.NewReactionUsecase(posts, comments, []string{"👍", "🎉"})
+	h := NewReactionHandler(uc, newTestLogger())
+
+	r := newTestRouter()
+	r.GET("/reactions", h.ListReactions)
+	r.POST("/posts/:id/reactions", r.requireAuth, h.TogglePostReaction)
+	r.POST("/posts/:id/comments/:commentId/reactions", r.requireAuth, h.ToggleCommentReaction)
+	return r.Engine
+}
+
+func TestListReactions(t *testing.T) {
+	r := newReactionRouter(new(mockPostRepository), new(MockCommentRepository))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reactions", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"reactions": ["👍", "🎉"]}`, w.Body.String())
+}
+
+func TestTogglePostReaction(t *testing.T) {
+	posts := new(mockPostRepository)
+	r := newReactionRouter(posts, new(MockCommentRepository))
+
+	posts.On("TogglePostReaction", mock.Anything, int64(1), int64(7), "👍").Return(true, nil)
+	posts.On("GetPostReactions", mock.Anything, int64(7), []int64{1}).
+		Return(map[int64][]entity.Reaction{1: {{Emoji: "👍", Count: 1, Mine: true}}}, nil)
+	posts.On("TogglePostReaction", mock.Anything, int64(2), int64(7), "👍").Return(false, repository.ErrPostNotFound)
+
+	tests := []struct {
+		name  string
+		url   string
+		body  string
+		token string
+		want  int
+	}{
+		{name: "Toggle", url: "/posts/1/reactions", body: `{"emoji": "👍"}`, token: userToken, want: http.StatusOK},
+		{name: "Not allowed emoji", url: "/posts/1/reactions", body: `{"emoji": "🤡"}`, token: userToken, want: http.StatusBadRequest},
+		{name: "Missing emoji", url: "/posts/1/reactions", body: `{}`, token: userToken, want: http.StatusBadRequest},
+		{name: "Unknown post", url: "/posts/2/reactions", body: `{"emoji": "👍"}`, token: userToken, want: http.StatusNotFound},
+		{name: "Anonymous", url: "/posts/1/reactions", body: `{"emoji": "👍"}`, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(http.MethodPost, tt.url, tt.body, tt.token))
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusOK {
+				var result entity.ReactionResult
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+				assert.True(t, result.Added)
+				assert.Equal(t, []entity.Reaction{{Emoji: "👍", Count: 1, Mine: true}}, result.Reactions)
+			}
+		})
+	}
+}
+
+func TestToggleCommentReaction(t *testing.T) {
+	comments := new(MockCommentRepository)
+	r := newReactionRouter(new(mockPostRepository), comments)
+
+	comments.On("GetCommentByID", mock.Anything, int64(3)).Return(&entity.Comment{ID: 3, PostID: 1}, nil)
+	comments.On("ToggleCommentReaction", mock.Anything, int64(3), int64(7), "🎉").Return(false, nil)
+	comments.On("GetCommentReactions", mock.Anything, int64(7), []int64{3}).Return(map[int64][]entity.Reaction{}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodPost, "/posts/1/comments/3/reactions", `{"emoji": "🎉"}`, userToken))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"emoji": "🎉", "added": false, "reactions": []}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodPost, "/posts/2/comments/3/reactions", `{"emoji": "🎉"}`, userToken))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type RevisionHandler struct {
+	uc     *usecase.RevisionUsecase
+	logger *logger.Logger
+}
+
+func NewRevisionHandler(uc *usecase.RevisionUsecase, logger *logger.Logger) *RevisionHandler {
+	return &RevisionHandler{uc: uc, logger: logger}
+}
+
+// ListRevisions godoc
+// @Summary List post revisions
+// @Description Edit history of a post, newest revision first. Revision 1 is the post as created; content is omitted, fetch a single revision to get it
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Revisions per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/revisions [get]
+func (h *RevisionHandler) ListRevisions(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	offset, limit, page, err := parsePage(c.Query("page"), c.Query("limit"), usecase.DefaultRevisionsPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions, total, err := h.uc.ListRevisions(c.Request.Context(), postID, limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to get revisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  revisions,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetRevision godoc
+// @Summary Get a post revision
+// @Description Title and content of the post as of the given revision
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} entity.PostRevision
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/revisions/{revision} [get]
+func (h *RevisionHandler) GetRevision(c *gin.Context) {
+	postID, revision, ok := revisionPathParams(c)
+	if !ok {
+		return
+	}
+
+	rev, err := h.uc.GetRevision(c.Request.Context(), postID, revision)
+	if err != nil {
+		h.respondError(c, err, "Failed to get revision")
+		return
+	}
+	c.JSON(http.StatusOK, rev)
+}
+
+// DiffRevisions godoc
+// @Summary Diff two post revisions
+// @Description Unified diff from revision "from" to revision "to". Title and content are compared as separate files; an unchanged part is left out
+// @Tags revisions
+// @Produce json
+// @Param id path int true "Post ID"
+// @Param from query int true "Base revision"
+// @Param to query int true "Target revision"
+// @Success 200 {object} entity.RevisionDiff
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/revisions/diff [get]
+func (h *RevisionHandler) DiffRevisions(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be revision numbers"})
+		return
+	}
+
+	diff, err := h.uc.Diff(c.Request.Context(), postID, from, to)
+	if err != nil {
+		h.respondError(c, err, "Failed to diff revisions")
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+// RollbackPost godoc
+// @Summary Roll a post back to a revision
+// @Description Admin only. Restores title and content of the given revision. The rollback itself is recorded as a new revision
+// @Tags revisions
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/revisions/{revision}/rollback [post]
+func (h *RevisionHandler) RollbackPost(c *gin.Context) {
+	postID, revision, ok := revisionPathParams(c)
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	post, err := h.uc.Rollback(c.Request.Context(), user.UserID, postID, revision)
+	if err != nil {
+		h.respondError(c, err, "Failed to roll back post")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post rolled back successfully",
+		"post":    post,
+	})
+}
+
+func revisionPathParams(c *gin.Context) (int64, int, bool) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return 0, 0, false
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return 0, 0, false
+	}
+	return postID, revision, true
+}
+
+func (h *RevisionHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case isPaginationError(err), errors.Is(err, usecase.ErrInvalidRevision):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	case errors.Is(err, repository.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+	default:
+		h.logger.Error(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
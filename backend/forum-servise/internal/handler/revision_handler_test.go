@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *mockPostRepository) GetRevisions(ctx context.Context, postID int64, limit, offset int) ([]*entity.PostRevision, error) {
+	args := m.Called(ctx, postID, limit, offset)
+	revisions, _ := args.Get(0).([]*entity.PostRevision)
+	return revisions, args.Error(1)
+}
+
+func (m *mockPostRepository) CountRevisions(ctx context.Context, postID int64) (int64, error) {
+	args := m.Called(ctx, postID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockPostRepository) GetRevision(ctx context.Context, postID int64, revision int) (*entity.PostRevision, error) {
+	args := m.Called(ctx, postID, revision)
+	rev, _ := args.Get(0).(*entity.PostRevision)
+	return rev, args.Error(1)
+}
+
+func (m *mockPostRepository) RollbackPost(ctx context.Context, postID int64, revision int, editorID int64, contentHTML string) (*entity.Post, error) {
+	args := m.Called(ctx, postID, revision, editorID, contentHTML)
+	post, _ := args.Get(0).(*entity.Post)
+	return post, args.Error(1)
+}
+
+func newRevisionRouter(posts *mockPostRepository) *gin.Engine {
+	r := newTestRouter()
+	r.auth.On("GetUsers", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pb.GetUsersResponse{Users: []*pb.User{{Id: 4, Username: "alice"}}}, nil)
+
+	h := NewRevisionHandler(usecase.NewRevisionUsecase(posts, r.auth), newTestLogger())
+
+	revisions := r.Group("/posts/:id/revisions")
+	revisions.GET("", h.ListRevisions)
+	revisions.GET("/diff", h.DiffRevisions)
+	revisions.GET("/:revision", h.GetRevision)
+	revisions.POST("/:revision/rollback", r.admin(h.RollbackPost)...)
+	return r.Engine
+}
+
+func TestListRevisions(t *testing.T) {
+	posts := new(mockPostRepository)
+	r := newRevisionRouter(posts)
+
+	posts.On("GetPostByID", mock.Anything, int64(1)).Return(&entity.Post{ID: 1}, nil)
+	posts.On("GetRevisions", mock.Anything, int64(1), 20, 0).
+		Return([]*entity.PostRevision{{PostID: 1, Revision: 1, Title: "Original", EditorID: 4}}, nil)
+	posts.On("CountRevisions", mock.Anything, int64(1)).Return(int64(1), nil)
+	posts.On("GetPostByID", mock.Anything, int64(2)).Return(nil, repository.ErrPostNotFound)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/1/revisions", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data  []*entity.PostRevision `json:"data"`
+		Total int64                  `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(1), body.Total)
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "alice", body.Data[0].EditorName)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/2/revisions", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDiffRevisions(t *testing.T) {
+	posts := new(mockPostRepository)
+	r := newRevisionRouter(posts)
+
+	posts.On("GetPostByID", mock.Anything, int64(1)).Return(&entity.Post{ID: 1}, nil)
+	posts.On("GetRevision", mock.Anything, int64(1), 1).
+		Return(&entity.PostRevision{PostID: 1, Revision: 1, Title: "Old", Content: "body"}, nil)
+	posts.On("GetRevision", mock.Anything, int64(1), 2).
+		Return(&entity.PostRevision{PostID: 1, Revision: 2, Title: "New", Content: "body"}, nil)
+	posts.On("GetRevision", mock.Anything, int64(1), 3).Return(nil, repository.ErrRevisionNotFound)
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{name: "Success", url: "/posts/1/revisions/diff?from=1&to=2", want: http.StatusOK},
+		{name: "Missing to", url: "/posts/1/revisions/diff?from=1", want: http.StatusBadRequest},
+		{name: "Unknown revision", url: "/posts/1/revisions/diff?from=1&to=3", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusOK {
+				var diff entity.RevisionDiff
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+				assert.Equal(t, "--- revision 1/title\n+++ revision 2/title\n@@ -1 +1 @@\n-Old\n+New\n", diff.Diff)
+			}
+		})
+	}
+}
+
+func TestGetRevision(t *testing.T) {
+	posts := new(mockPostRepository)
+	r := newRevisionRouter(posts)
+
+	posts.On("GetPostByID", mock.Anything, int64(1)).Return(&entity.Post{ID: 1}, nil)
+	posts.On("GetRevision", mock.Anything, int64(1), 1).
+		Return(&entity.PostRevision{PostID: 1, Revision: 1, Title: "Old", Content: "body", EditorID: 4}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/1/revisions/1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"content":"body"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/1/revisions/0", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRollbackPost(t *testing.T) {
+	posts := new(mockPostRepository)
+	r := newRevisionRouter(posts)
+
+	posts.On("GetPostByID", mock.Anything, int64(1)).Return(&entity.Post{ID: 1}, nil)
+	posts.On("GetRevision", mock.Anything, int64(1), 1).
+		Return(&entity.PostRevision{PostID: 1, Revision: 1, Title: "Old", Content: "*old*"}, nil)
+	posts.On("RollbackPost", mock.Anything, int64(1), 1, int64(9), "<p><em>old</em></p>\n").
+		Return(&entity.Post{ID: 1, Title: "Old"}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodPost, "/posts/1/revisions/1/rollback", "", adminToken))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodPost, "/posts/1/revisions/1/rollback", "", userToken))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/posts/1/revisions/1/rollback", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	posts.AssertExpectations(t)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// Токены, которые принимает auth-клиент testRouter.
+const (
+	userToken   = "user-token"   // bob, id 7
+	authorToken = "author-token" // alice, id 42
+	adminToken  = "admin-token"  // admin, id 9
+)
+
+var testTokens = map[string]*pb.ValidateTokenResponse{
+	userToken:   {Valid: true, UserId: 7, Username: "bob", Role: "user"},
+	authorToken: {Valid: true, UserId: 42, Username: "alice", Role: "user"},
+	adminToken:  {Valid: true, UserId: 9, Username: "admin", Role: authmw.RoleAdmin},
+}
+
+// testRouter — роутер для тестов обработчиков с настоящей авторизацией
+// authmw поверх мока auth-сервиса.
+type testRouter struct {
+	*gin.Engine
+	auth        *MockAuthClient
+	requireAuth gin.HandlerFunc
+}
+
+// newTestAuthClient — мок auth-сервиса, который принимает только testTokens.
+func newTestAuthClient() *MockAuthClient {
+	authClient := new(MockAuthClient)
+	for token, resp := range testTokens {
+		authClient.On("ValidateToken", mock.Anything, &pb.ValidateTokenRequest{Token: token}, mock.Anything).
+			Return(resp, nil)
+	}
+	authClient.On("ValidateToken", mock.Anything, mock.Anything, mock.Anything).
+		Return(&pb.ValidateTokenResponse{Valid: false}, nil)
+	return authClient
+}
+
+func newTestRouter() *testRouter {
+	gin.SetMode(gin.TestMode)
+	authClient := newTestAuthClient()
+
+	return &testRouter{
+		Engine:      gin.New(),
+		auth:        authClient,
+		requireAuth: authmw.NewAuthenticator(nil, authClient).Required(),
+	}
+}
+
+// admin ставит перед обработчиком проверку токена и роли администратора.
+func (r *testRouter) admin(h gin.HandlerFunc) []gin.HandlerFunc {
+	return []gin.HandlerFunc{r.requireAuth, authmw.RequireRole(authmw.RoleAdmin), h}
+}
+
+// testRequest собирает запрос с JSON-телом body; пустой token — анонимный запрос.
+func testRequest(method, url, body, token string) *http.Request {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func newTestLogger() *logger.Logger {
+	return &logger.Logger{SugaredLogger: zap.NewNop().Sugar()}
+}
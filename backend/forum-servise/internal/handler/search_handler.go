@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const searchDateLayout = "2006-01-02"
+
+type SearchHandler struct {
+	uc     usecase.SearchUsecaseInterface
+	logger *logger.Logger
+}
+
+func NewSearchHandler(uc usecase.SearchUsecaseInterface, logger *logger.Logger) *SearchHandler {
+	return &SearchHandler{uc: uc, logger: logger}
+}
+
+// Search godoc
+// @Summary Search posts and comments
+// @Description Full-text search over post titles, post content and comments, ordered by relevance. Matches in snippet are wrapped in <mark>, the rest of the snippet is HTML-escaped
+// @Tags search
+// @Produce json
+// @Param q query string true "Search query, supports quoted phrases, OR and -exclusion"
+// @Param author_id query int false "Only results by this author"
+// @Param topic_id query int false "Only results from posts of this topic"
+// @Param from query string false "Created at or after (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Created before (RFC3339, or YYYY-MM-DD inclusive)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Results per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	query, page, err := parseSearchQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.uc.Search(c.Request.Context(), query)
+	if err != nil {
+		if isSearchError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Failed to search", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  result.Results,
+		"total": result.Total,
+		"page":  page,
+		"limit": query.Limit,
+	})
+}
+
+func parseSearchQuery(c *gin.Context) (entity.SearchQuery, int, error) {
+	query := entity.SearchQuery{Text: c.Query("q")}
+
+	offset, limit, page, err := parsePage(c.Query("page"), c.Query("limit"), usecase.DefaultSearchPageSize)
+	if err != nil {
+		return query, 0, err
+	}
+	query.Offset, query.Limit = offset, limit
+
+	if query.AuthorID, err = parseOptionalID(c.Query("author_id"), "author_id"); err != nil {
+		return query, 0, err
+	}
+	if query.TopicID, err = parseOptionalID(c.Query("topic_id"), "topic_id"); err != nil {
+		return query, 0, err
+	}
+	if query.From, err = parseSearchDate(c.Query("from"), "from", false); err != nil {
+		return query, 0, err
+	}
+	if query.To, err = parseSearchDate(c.Query("to"), "to", true); err != nil {
+		return query, 0, err
+	}
+	return query, page, nil
+}
+
+func parseOptionalID(value, name string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, errors.New("invalid " + name)
+	}
+	return &id, nil
+}
+
+// parseSearchDate принимает RFC3339 или просто дату. Дата в верхней границе
+// включается целиком, поэтому для нее граница сдвигается на следующий день.
+func parseSearchDate(value, name string, upper bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(searchDateLayout, value)
+	if err != nil {
+		return nil, errors.New("invalid " + name + " date")
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func isSearchError(err error) bool {
+	return isPaginationError(err) ||
+		errors.Is(err, usecase.ErrEmptySearchQuery) ||
+		errors.Is(err, usecase.ErrSearchQueryTooLong) ||
+		errors.Is(err, usecase.ErrInvalidDateRange)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSearchUsecase struct {
+	mock.Mock
+}
+
+func (m *mockSearchUsecase) Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error) {
+	args := m.Called(ctx, query)
+	page, _ := args.Get(0).(*entity.SearchPage)
+	return page, args.Error(1)
+}
+
+func newSearchRouter(uc usecase.SearchUsecaseInterface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/search", NewSearchHandler(uc, newTestLogger()).Search)
+	return r
+}
+
+func TestSearch(t *testing.T) {
+	uc := new(mockSearchUsecase)
+	r := newSearchRouter(uc)
+
+	authorID, topicID := int64(7), int64(3)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC)
+	uc.On("Search", mock.Anything, entity.SearchQuery{
+		Text:     "go generics",
+		AuthorID: &authorID,
+		TopicID:  &topicID,
+		From:     &from,
+		To:       &to,
+		Limit:    5,
+		Offset:   5,
+	}).Return(&entity.SearchPage{
+		Results: []*entity.SearchResult{{Type: entity.SearchResultPost, ID: 1, Snippet: "<mark>go</mark>"}},
+		Total:   6,
+	}, nil)
+
+	req, _ := http.NewRequest(http.MethodGet,
+		"/search?q=go+generics&author_id=7&topic_id=3&from=2024-05-01&to=2024-05-10&page=2&limit=5", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":6`)
+	assert.Contains(t, w.Body.String(), `"page":2`)
+	uc.AssertExpectations(t)
+}
+
+func TestSearch_BadRequest(t *testing.T) {
+	uc := new(mockSearchUsecase)
+	r := newSearchRouter(uc)
+
+	uc.On("Search", mock.Anything, mock.MatchedBy(func(q entity.SearchQuery) bool { return q.Text == "" })).
+		Return(nil, usecase.ErrEmptySearchQuery)
+
+	for _, query := range []string{"", "q=go&author_id=abc", "q=go&topic_id=-1", "q=go&from=yesterday", "q=go&page=0"} {
+		req, _ := http.NewRequest(http.MethodGet, "/search?"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestSearch_InternalError(t *testing.T) {
+	uc := new(mockSearchUsecase)
+	r := newSearchRouter(uc)
+
+	uc.On("Search", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/search?q=go", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultTagsPageSize = 50
+
+type TagHandler struct {
+	uc     *usecase.TagUsecase
+	logger *logger.Logger
+}
+
+func NewTagHandler(uc *usecase.TagUsecase, logger *logger.Logger) *TagHandler {
+	return &TagHandler{uc: uc, logger: logger}
+}
+
+// RenameTagRequest — новое имя тега.
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required" example:"golang"`
+}
+
+// MergeTagRequest — тег, в который сливается тег из пути.
+type MergeTagRequest struct {
+	Into string `json:"into" binding:"required" example:"go"`
+}
+
+// ListTags godoc
+// @Summary List tags
+// @Description Tags of live posts with the number of such posts, most used first
+// @Tags tags
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Tags per page" default(50)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/tags [get]
+func (h *TagHandler) ListTags(c *gin.Context) {
+	offset, limit, page, err := parsePage(c.Query("page"), c.Query("limit"), defaultTagsPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, total, err := h.uc.ListTags(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to get tags")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":  tags,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// SuggestTags godoc
+// @Summary Autocomplete tags
+// @Description Used tags starting with q, most used first. The prefix is normalized like tags of posts
+// @Tags tags
+// @Produce json
+// @Param q query string false "Tag prefix"
+// @Param limit query int false "Number of suggestions" default(10)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/tags/suggest [get]
+func (h *TagHandler) SuggestTags(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": usecase.ErrInvalidPageSize.Error()})
+			return
+		}
+		limit = n
+	}
+
+	tags, err := h.uc.SuggestTags(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		h.respondError(c, err, "Failed to suggest tags")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tags})
+}
+
+// RenameTag godoc
+// @Summary Rename a tag
+// @Description Admin only. All posts keep the tag under the new name. If the new name is taken, merge the tags instead
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Tag name"
+// @Param request body RenameTagRequest true "New name"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 409 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/tags/{name} [put]
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	var request RenameTagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	name, err := h.uc.RenameTag(c.Request.Context(), c.Param("name"), request.Name)
+	if err != nil {
+		h.respondError(c, err, "Failed to rename tag")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tag renamed successfully", "name": name})
+}
+
+// MergeTag godoc
+// @Summary Merge a tag into another
+// @Description Admin only. Posts of the tag from the path get the target tag, then the tag is deleted
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Tag to merge"
+// @Param request body MergeTagRequest true "Target tag"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/tags/{name}/merge [post]
+func (h *TagHandler) MergeTag(c *gin.Context) {
+	var request MergeTagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	name, err := h.uc.MergeTags(c.Request.Context(), c.Param("name"), request.Into)
+	if err != nil {
+		h.respondError(c, err, "Failed to merge tags")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tags merged successfully", "name": name})
+}
+
+func (h *TagHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case isPaginationError(err), isTagError(err), errors.Is(err, usecase.ErrMergeIntoSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+	case errors.Is(err, repository.ErrTagExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
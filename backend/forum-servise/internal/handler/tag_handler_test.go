@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTagRepository struct {
+	mock.Mock
+}
+
+func (m *mockTagRepository) ListTags(ctx context.Context, limit, offset int) ([]*entity.Tag, error) {
+	args := m.Called(ctx, limit, offset)
+	tags, _ := args.Get(0).([]*entity.Tag)
+	return tags, args.Error(1)
+}
+
+func (m *mockTagRepository) CountTags(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockTagRepository) SuggestTags(ctx context.Context, prefix string, limit int) ([]*entity.Tag, error) {
+	args := m.Called(ctx, prefix, limit)
+	tags, _ := args.Get(0).([]*entity.Tag)
+	return tags, args.Error(1)
+}
+
+func (m *mockTagRepository) RenameTag(ctx context.Context, name, newName string) error {
+	return m.Called(ctx, name, newName).Error(0)
+}
+
+func (m *mockTagRepository) MergeTags(ctx context.Context, source, target string) error {
+	return m.Called(ctx, source, target).Error(0)
+}
+
+func newTagRouter(tags *mockTagRepository) *gin.Engine {
+	h := NewTagHandler(usecase.NewTagUsecase(tags), newTestLogger())
+
+	r := newTestRouter()
+	r.GET("/tags", h.ListTags)
+	r.GET("/tags/suggest", h.SuggestTags)
+	r.PUT("/tags/:name", r.admin(h.RenameTag)...)
+	r.POST("/tags/:name/merge", r.admin(h.MergeTag)...)
+	return r.Engine
+}
+
+func TestTagHandler_ListTags(t *testing.T) {
+	tags := new(mockTagRepository)
+	r := newTagRouter(tags)
+
+	tags.On("ListTags", mock.Anything, 2, 2).
+		Return([]*entity.Tag{{Name: "sql", PostCount: 1}}, nil)
+	tags.On("CountTags", mock.Anything).Return(int64(3), nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodGet, "/tags?page=2&limit=2", "", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data  []entity.Tag `json:"data"`
+		Total int64        `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []entity.Tag{{Name: "sql", PostCount: 1}}, response.Data)
+	assert.Equal(t, int64(3), response.Total)
+}
+
+func TestTagHandler_SuggestTags(t *testing.T) {
+	tags := new(mockTagRepository)
+	r := newTagRouter(tags)
+
+	tags.On("SuggestTags", mock.Anything, "go", usecase.DefaultTagSuggestions).
+		Return([]*entity.Tag{{Name: "go", PostCount: 4}, {Name: "golang", PostCount: 1}}, nil)
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{name: "Success", url: "/tags/suggest?q=Go", want: http.StatusOK},
+		{name: "Invalid prefix", url: "/tags/suggest?q=go!", want: http.StatusBadRequest},
+		{name: "Invalid limit", url: "/tags/suggest?q=go&limit=abc", want: http.StatusBadRequest},
+		{name: "Limit too large", url: "/tags/suggest?q=go&limit=500", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(http.MethodGet, tt.url, "", ""))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestTagHandler_RenameTag(t *testing.T) {
+	tags := new(mockTagRepository)
+	r := newTagRouter(tags)
+
+	tags.On("RenameTag", mock.Anything, "golang", "go-lang").Return(nil)
+	tags.On("RenameTag", mock.Anything, "golang", "go").Return(repository.ErrTagExists)
+	tags.On("RenameTag", mock.Anything, "missing", "go").Return(repository.ErrTagNotFound)
+
+	tests := []struct {
+		name  string
+		url   string
+		body  string
+		token string
+		want  int
+	}{
+		{name: "Success", url: "/tags/golang", body: `{"name": "Go Lang"}`, token: adminToken, want: http.StatusOK},
+		{name: "Name taken", url: "/tags/golang", body: `{"name": "go"}`, token: adminToken, want: http.StatusConflict},
+		{name: "Unknown tag", url: "/tags/missing", body: `{"name": "go"}`, token: adminToken, want: http.StatusNotFound},
+		{name: "Invalid name", url: "/tags/golang", body: `{"name": "go lang!"}`, token: adminToken, want: http.StatusBadRequest},
+		{name: "Missing name", url: "/tags/golang", body: `{}`, token: adminToken, want: http.StatusBadRequest},
+		{name: "Not admin", url: "/tags/golang", body: `{"name": "go"}`, token: userToken, want: http.StatusForbidden},
+		{name: "Anonymous", url: "/tags/golang", body: `{"name": "go"}`, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(http.MethodPut, tt.url, tt.body, tt.token))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestTagHandler_MergeTag(t *testing.T) {
+	tags := new(mockTagRepository)
+	r := newTagRouter(tags)
+
+	tags.On("MergeTags", mock.Anything, "golang", "go").Return(nil)
+	tags.On("MergeTags", mock.Anything, "missing", "go").Return(repository.ErrTagNotFound)
+
+	tests := []struct {
+		name  string
+		url   string
+		body  string
+		token string
+		want  int
+	}{
+		{name: "Success", url: "/tags/golang/merge", body: `{"into": "#Go"}`, token: adminToken, want: http.StatusOK},
+		{name: "Unknown tag", url: "/tags/missing/merge", body: `{"into": "go"}`, token: adminToken, want: http.StatusNotFound},
+		{name: "Into itself", url: "/tags/go/merge", body: `{"into": "GO"}`, token: adminToken, want: http.StatusBadRequest},
+		{name: "Not admin", url: "/tags/golang/merge", body: `{"into": "go"}`, token: userToken, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(http.MethodPost, tt.url, tt.body, tt.token))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+	tags.AssertNumberOfCalls(t, "MergeTags", 2)
+}
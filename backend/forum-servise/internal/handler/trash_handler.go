@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultTrashPageSize = 20
+
+type TrashHandler struct {
+	uc     *usecase.TrashUsecase
+	logger *logger.Logger
+}
+
+func NewTrashHandler(uc *usecase.TrashUsecase, logger *logger.Logger) *TrashHandler {
+	return &TrashHandler{uc: uc, logger: logger}
+}
+
+// ListPosts godoc
+// @Summary List deleted posts
+// @Description Admin only. Deleted posts, most recently deleted first. Items are purged grace_period after deleted_at
+// @Tags trash
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Posts per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/trash/posts [get]
+func (h *TrashHandler) ListPosts(c *gin.Context) {
+	offset, limit, page, err := parsePage(c.Query("page"), c.Query("limit"), defaultTrashPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	posts, total, err := h.uc.ListPosts(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to get deleted posts")
+		return
+	}
+	h.respondPage(c, posts, total, page, limit)
+}
+
+// ListComments godoc
+// @Summary List deleted comments
+// @Description Admin only. Deleted comments, most recently deleted first. Items are purged grace_period after deleted_at
+// @Tags trash
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Comments per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/trash/comments [get]
+func (h *TrashHandler) ListComments(c *gin.Context) {
+	offset, limit, page, err := parsePage(c.Query("page"), c.Query("limit"), defaultTrashPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comments, total, err := h.uc.ListComments(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.respondError(c, err, "Failed to get deleted comments")
+		return
+	}
+	h.respondPage(c, comments, total, page, limit)
+}
+
+// RestorePost godoc
+// @Summary Restore a deleted post
+// @Description Admin only. Takes a post out of the trash together with its comments. The author gets a moderation notification
+// @Tags trash
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/trash/posts/{id}/restore [post]
+func (h *TrashHandler) RestorePost(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	if err := h.uc.RestorePost(c.Request.Context(), user, id); err != nil {
+		h.respondError(c, err, "Failed to restore post")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Post restored successfully"})
+}
+
+// RestoreComment godoc
+// @Summary Restore a deleted comment
+// @Description Admin only. Takes a comment out of the trash. The author gets a moderation notification
+// @Tags trash
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Comment ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 403 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/trash/comments/{id}/restore [post]
+func (h *TrashHandler) RestoreComment(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	if err := h.uc.RestoreComment(c.Request.Context(), user, id); err != nil {
+		h.respondError(c, err, "Failed to restore comment")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment restored successfully"})
+}
+
+func (h *TrashHandler) respondPage(c *gin.Context, data interface{}, total int64, page, limit int) {
+	c.JSON(http.StatusOK, gin.H{
+		"data":         data,
+		"total":        total,
+		"page":         page,
+		"limit":        limit,
+		"grace_period": h.uc.GracePeriod().String(),
+	})
+}
+
+func (h *TrashHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case isPaginationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found in trash"})
+	case errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found in trash"})
+	default:
+		h.logger.Error(message, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
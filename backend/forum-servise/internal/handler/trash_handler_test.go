@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *mockPostRepository) GetDeletedPosts(ctx context.Context, limit, offset int) ([]*entity.Post, error) {
+	args := m.Called(ctx, limit, offset)
+	posts, _ := args.Get(0).([]*entity.Post)
+	return posts, args.Error(1)
+}
+
+func (m *mockPostRepository) CountDeletedPosts(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockPostRepository) RestorePost(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newTrashRouter(posts *mockPostRepository, comments *MockCommentRepository) *gin.Engine {
+	h := NewTrashHandler(
+		usecase.NewTrashUsecase(posts, comments, 48*time.Hour, time.Hour, newTestLogger()),
+		newTestLogger(),
+	)
+
+	r := newTestRouter()
+	r.GET("/trash/posts", r.admin(h.ListPosts)...)
+	r.GET("/trash/comments", r.admin(h.ListComments)...)
+	r.POST("/trash/posts/:id/restore", r.admin(h.RestorePost)...)
+	r.POST("/trash/comments/:id/restore", r.admin(h.RestoreComment)...)
+	return r.Engine
+}
+
+func TestTrashListPosts(t *testing.T) {
+	posts := new(mockPostRepository)
+	r := newTrashRouter(posts, new(MockCommentRepository))
+
+	deletedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	posts.On("GetDeletedPosts", mock.Anything, 10, 10).
+		Return([]*entity.Post{{ID: 3, Title: "Old", DeletedAt: &deletedAt}}, nil)
+	posts.On("CountDeletedPosts", mock.Anything).Return(int64(11), nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodGet, "/trash/posts?page=2&limit=10", "", adminToken))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data        []*entity.Post `json:"data"`
+		Total       int64          `json:"total"`
+		Page        int            `json:"page"`
+		GracePeriod string         `json:"grace_period"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, int64(11), body.Total)
+	assert.Equal(t, 2, body.Page)
+	assert.Equal(t, "48h0m0s", body.GracePeriod)
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, deletedAt, *body.Data[0].DeletedAt)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodGet, "/trash/posts?limit=abc", "", adminToken))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodGet, "/trash/posts", "", userToken))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	posts.AssertExpectations(t)
+}
+
+func TestTrashListComments_Error(t *testing.T) {
+	comments := new(MockCommentRepository)
+	r := newTrashRouter(new(mockPostRepository), comments)
+
+	comments.On("GetDeletedComments", mock.Anything, defaultTrashPageSize, 0).
+		Return(nil, errors.New("db down"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodGet, "/trash/comments", "", adminToken))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	comments.AssertExpectations(t)
+}
+
+func TestTrashRestore(t *testing.T) {
+	posts := new(mockPostRepository)
+	comments := new(MockCommentRepository)
+	r := newTrashRouter(posts, comments)
+
+	posts.On("RestorePost", mock.Anything, int64(1)).Return(nil)
+	posts.On("RestorePost", mock.Anything, int64(2)).Return(repository.ErrPostNotFound)
+	comments.On("RestoreComment", mock.Anything, int64(5)).Return(repository.ErrCommentNotFound)
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{name: "Post restored", url: "/trash/posts/1/restore", want: http.StatusOK},
+		{name: "Post not in trash", url: "/trash/posts/2/restore", want: http.StatusNotFound},
+		{name: "Invalid post ID", url: "/trash/posts/abc/restore", want: http.StatusBadRequest},
+		{name: "Comment not in trash", url: "/trash/comments/5/restore", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(http.MethodPost, tt.url, "", adminToken))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+	posts.AssertExpectations(t)
+	comments.AssertExpectations(t)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type VoteHandler struct {
+	uc     *usecase.VoteUsecase
+	logger *logger.Logger
+}
+
+func NewVoteHandler(uc *usecase.VoteUsecase, logger *logger.Logger) *VoteHandler {
+	return &VoteHandler{uc: uc, logger: logger}
+}
+
+// VoteRequest — голос: 1 за, -1 против, 0 снять голос.
+type VoteRequest struct {
+	Value *int `json:"value" binding:"required" example:"1"`
+}
+
+// VotePost godoc
+// @Summary Vote for a post
+// @Description Upvote (1), downvote (-1) or clear the vote (0). One vote per user, voting again replaces the previous vote
+// @Tags votes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param request body VoteRequest true "Vote"
+// @Success 200 {object} entity.VoteResult
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/vote [post]
+func (h *VoteHandler) VotePost(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	value, ok := bindVote(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.uc.VotePost(c.Request.Context(), user, postID, value)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// VoteComment godoc
+// @Summary Vote for a comment
+// @Description Upvote (1), downvote (-1) or clear the vote (0). One vote per user, voting again replaces the previous vote
+// @Tags votes
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Post ID"
+// @Param commentId path int true "Comment ID"
+// @Param request body VoteRequest true "Vote"
+// @Success 200 {object} entity.VoteResult
+// @Failure 400 {object} entity.ErrorResponse
+// @Failure 401 {object} entity.ErrorResponse
+// @Failure 404 {object} entity.ErrorResponse
+// @Failure 500 {object} entity.ErrorResponse
+// @Router /api/v1/posts/{id}/comments/{commentId}/vote [post]
+func (h *VoteHandler) VoteComment(c *gin.Context) {
+	postID, commentID, ok := commentPathIDs(c)
+	if !ok {
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	value, ok := bindVote(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.uc.VoteComment(c.Request.Context(), user, postID, commentID, value)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func bindVote(c *gin.Context) (int, bool) {
+	var request VoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return 0, false
+	}
+	return *request.Value, true
+}
+
+func (h *VoteHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidVote):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	case errors.Is(err, repository.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+	default:
+		h.logger.Error("Failed to vote", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to vote"})
+	}
+}
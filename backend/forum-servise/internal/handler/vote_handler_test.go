@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func (m *mockPostRepository) VotePost(ctx context.Context, postID, userID int64, value int) (int, error) {
+	args := m.Called(ctx, postID, userID, value)
+	return args.Int(0), args.Error(1)
+}
+
+func newVoteRouter(posts *mockPostRepository, comments *MockCommentRepository) *gin.Engine {
+	h := NewVoteHandler(usecase.NewVoteUsecase(posts, comments), newTestLogger())
+
+	r := newTestRouter()
+	r.POST("/posts/:id/vote", r.requireAuth, h.VotePost)
+	r.POST("/posts/:id/comments/:commentId/vote", r.requireAuth, h.VoteComment)
+	return r.Engine
+}
+
+func TestVotePost(t *testing.T) {
+	posts := new(mockPostRepository)
+	r := newVoteRouter(posts, new(MockCommentRepository))
+
+	posts.On("VotePost", mock.Anything, int64(1), int64(7), 1).Return(6, nil)
+	posts.On("VotePost", mock.Anything, int64(1), int64(7), 0).Return(5, nil)
+	posts.On("VotePost", mock.Anything, int64(2), int64(7), 1).Return(0, repository.ErrPostNotFound)
+
+	tests := []struct {
+		name  string
+		url   string
+		body  string
+		token string
+		want  int
+	}{
+		{name: "Upvote", url: "/posts/1/vote", body: `{"value": 1}`, token: userToken, want: http.StatusOK},
+		{name: "Clear vote", url: "/posts/1/vote", body: `{"value": 0}`, token: userToken, want: http.StatusOK},
+		{name: "Missing value", url: "/posts/1/vote", body: `{}`, token: userToken, want: http.StatusBadRequest},
+		{name: "Invalid value", url: "/posts/1/vote", body: `{"value": 3}`, token: userToken, want: http.StatusBadRequest},
+		{name: "Unknown post", url: "/posts/2/vote", body: `{"value": 1}`, token: userToken, want: http.StatusNotFound},
+		{name: "Anonymous", url: "/posts/1/vote", body: `{"value": 1}`, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, testRequest(http.MethodPost, tt.url, tt.body, tt.token))
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodPost, "/posts/1/vote", `{"value": 1}`, userToken))
+	var result entity.VoteResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, entity.VoteResult{Score: 6, MyVote: 1}, result)
+}
+
+func TestVoteComment(t *testing.T) {
+	comments := new(MockCommentRepository)
+	r := newVoteRouter(new(mockPostRepository), comments)
+
+	comments.On("GetCommentByID", mock.Anything, int64(3)).Return(&entity.Comment{ID: 3, PostID: 1}, nil)
+	comments.On("VoteComment", mock.Anything, int64(3), int64(7), -1).Return(-2, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodPost, "/posts/1/comments/3/vote", `{"value": -1}`, userToken))
+	require.Equal(t, http.StatusOK, w.Code)
+	var result entity.VoteResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, entity.VoteResult{Score: -2, MyVote: -1}, result)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, testRequest(http.MethodPost, "/posts/2/comments/3/vote", `{"value": 1}`, userToken))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
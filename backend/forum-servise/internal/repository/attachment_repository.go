@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentLimit — у поста уже есть limit вложений.
+	ErrAttachmentLimit = errors.New("attachment limit reached")
+)
+
+// AttachmentRepository хранит описания вложений постов; сами файлы лежат
+// в storage. Вложения постов ленты отдает PostRepository.GetPostAttachments.
+type AttachmentRepository interface {
+	// CreateAttachment сохраняет вложение, если у поста меньше limit
+	// вложений, иначе возвращает ErrAttachmentLimit.
+	CreateAttachment(ctx context.Context, attachment *entity.Attachment, limit int) error
+	GetAttachment(ctx context.Context, id int64) (*entity.Attachment, error)
+	CountPostAttachments(ctx context.Context, postID int64) (int, error)
+	DeleteAttachment(ctx context.Context, id int64) error
+}
+
+type attachmentRepository struct {
+	db *sqlx.DB
+}
+
+func NewAttachmentRepository(db *sqlx.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+const attachmentColumns = `a.id, a.post_id, a.uploader_id, a.file_name, a.content_type, a.size,
+	a.storage_key, a.thumbnail_key, a.created_at`
+
+// CreateAttachment сохраняет вложение и проставляет ему ID и CreatedAt.
+// Пост блокируется до подсчета, поэтому параллельные загрузки в один пост
+// идут по очереди и не превышают limit.
+func (r *attachmentRepository) CreateAttachment(ctx context.Context, attachment *entity.Attachment, limit int) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var postID int64
+	err = tx.GetContext(ctx, &postID, `SELECT id FROM posts WHERE id = $1 FOR UPDATE`, attachment.PostID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var count int
+	if err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM attachments WHERE post_id = $1`, postID); err != nil {
+		return err
+	}
+	if count >= limit {
+		return ErrAttachmentLimit
+	}
+
+	query := `
+		INSERT INTO attachments (post_id, uploader_id, file_name, content_type, size, storage_key, thumbnail_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	err = tx.QueryRowContext(ctx, query,
+		attachment.PostID,
+		attachment.UploaderID,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+		attachment.ThumbnailKey,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAttachment возвращает вложение живого поста: вложения постов
+// из корзины не отдаются.
+func (r *attachmentRepository) GetAttachment(ctx context.Context, id int64) (*entity.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		JOIN posts p ON p.id = a.post_id AND p.deleted_at IS NULL
+		WHERE a.id = $1`
+
+	var attachment entity.Attachment
+	if err := r.db.GetContext(ctx, &attachment, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) CountPostAttachments(ctx context.Context, postID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM attachments WHERE post_id = $1`, postID)
+	return count, err
+}
+
+func (r *attachmentRepository) DeleteAttachment(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM attachments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var attachmentRowColumns = []string{"id", "post_id", "uploader_id", "file_name", "content_type", "size",
+	"storage_key", "thumbnail_key", "created_at"}
+
+func TestCreateAttachment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAttachmentRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		attachment := &entity.Attachment{PostID: 1, UploaderID: 2, FileName: "log.txt", ContentType: "text/plain; charset=utf-8",
+			Size: 10, StorageKey: "posts/1/abc"}
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM posts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM attachments WHERE post_id = \$1`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(9))
+		mock.ExpectQuery(`INSERT INTO attachments \(post_id, uploader_id, file_name, content_type, size, storage_key, thumbnail_key\)\s+VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7\)\s+RETURNING id, created_at`).
+			WithArgs(int64(1), int64(2), "log.txt", "text/plain; charset=utf-8", int64(10), "posts/1/abc", "").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, now))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.CreateAttachment(context.Background(), attachment, 10))
+		assert.Equal(t, int64(5), attachment.ID)
+		assert.Equal(t, now, attachment.CreatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Limit reached", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM posts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM attachments WHERE post_id = \$1`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+		mock.ExpectRollback()
+
+		err := repo.CreateAttachment(context.Background(), &entity.Attachment{PostID: 1}, 10)
+		assert.ErrorIs(t, err, ErrAttachmentLimit)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Post not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM posts WHERE id = \$1 FOR UPDATE`).
+			WithArgs(int64(7)).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repo.CreateAttachment(context.Background(), &entity.Attachment{PostID: 7}, 10)
+		assert.ErrorIs(t, err, ErrPostNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAttachment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAttachmentRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT a.id, .+ FROM attachments a\s+JOIN posts p ON p.id = a.post_id AND p.deleted_at IS NULL\s+WHERE a.id = \$1`).
+			WithArgs(int64(5)).
+			WillReturnRows(sqlmock.NewRows(attachmentRowColumns).
+				AddRow(5, 1, 2, "shot.png", "image/png", 100, "posts/1/abc", "posts/1/abc_thumb", now))
+
+		got, err := repo.GetAttachment(context.Background(), 5)
+		require.NoError(t, err)
+		assert.Equal(t, &entity.Attachment{ID: 5, PostID: 1, UploaderID: 2, FileName: "shot.png", ContentType: "image/png",
+			Size: 100, StorageKey: "posts/1/abc", ThumbnailKey: "posts/1/abc_thumb", CreatedAt: now}, got)
+	})
+
+	t.Run("Not found or post deleted", func(t *testing.T) {
+		mock.ExpectQuery(`FROM attachments a`).
+			WithArgs(int64(6)).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetAttachment(context.Background(), 6)
+		assert.ErrorIs(t, err, ErrAttachmentNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountAndDeleteAttachments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewAttachmentRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM attachments WHERE post_id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectExec(`DELETE FROM attachments WHERE id = \$1`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM attachments WHERE id = \$1`).
+		WithArgs(int64(6)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	count, err := repo.CountPostAttachments(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, repo.DeleteAttachment(context.Background(), 5))
+	assert.ErrorIs(t, repo.DeleteAttachment(context.Background(), 6), ErrAttachmentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostAttachments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+	mock.ExpectQuery(`SELECT a.id, .+ FROM attachments a\s+WHERE a.post_id = ANY\(\$1\)\s+ORDER BY a.post_id, a.id`).
+		WithArgs(pq.Array([]int64{1, 2})).
+		WillReturnRows(sqlmock.NewRows(attachmentRowColumns).
+			AddRow(3, 1, 2, "a.png", "image/png", 100, "posts/1/a", "", now).
+			AddRow(4, 1, 2, "b.txt", "text/plain", 10, "posts/1/b", "", now))
+
+	got, err := repo.GetPostAttachments(context.Background(), []int64{1, 2})
+	require.NoError(t, err)
+	require.Len(t, got[1], 2)
+	assert.Equal(t, "a.png", got[1][0].FileName)
+	assert.Equal(t, "b.txt", got[1][1].FileName)
+	assert.Empty(t, got[2])
+
+	got, err = repo.GetPostAttachments(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("category already exists")
+)
+
+// pgUniqueViolation и pgForeignKeyViolation — коды ошибок PostgreSQL.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category *entity.Category) (int64, error)
+	GetCategoryByID(ctx context.Context, id int64) (*entity.Category, error)
+	GetCategories(ctx context.Context) ([]entity.Category, error)
+	UpdateCategory(ctx context.Context, category *entity.Category) error
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+type categoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) CreateCategory(ctx context.Context, category *entity.Category) (int64, error) {
+	query := `
+		INSERT INTO categories (name, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query,
+		category.Name,
+		category.Description,
+		category.CreatedAt,
+	).Scan(&id)
+	if isPgError(err, pgUniqueViolation) {
+		return 0, ErrCategoryExists
+	}
+	return id, err
+}
+
+func (r *categoryRepository) GetCategoryByID(ctx context.Context, id int64) (*entity.Category, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM categories
+		WHERE id = $1`
+
+	var category entity.Category
+	err := r.db.GetContext(ctx, &category, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM categories
+		ORDER BY name`
+
+	categories := []entity.Category{}
+	if err := r.db.SelectContext(ctx, &categories, query); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	query := `
+		UPDATE categories
+		SET name = $1, description = $2, updated_at = $3
+		WHERE id = $4
+		RETURNING created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		category.Name,
+		category.Description,
+		category.UpdatedAt,
+		category.ID,
+	).Scan(&category.CreatedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrCategoryNotFound
+	case isPgError(err, pgUniqueViolation):
+		return ErrCategoryExists
+	}
+	return err
+}
+
+func (r *categoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func isPgError(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
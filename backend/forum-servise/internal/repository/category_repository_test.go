@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		category *entity.Category
+		mock     func()
+		want     int64
+		wantErr  bool
+	}{
+		{
+			name:     "Success",
+			category: &entity.Category{Name: "Go", Description: "Всё о Go", CreatedAt: now},
+			mock: func() {
+				mock.ExpectQuery(`INSERT INTO categories`).
+					WithArgs("Go", "Всё о Go", now).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			},
+			want: 1,
+		},
+		{
+			name:     "Duplicate Name",
+			category: &entity.Category{Name: "Go", CreatedAt: now},
+			mock: func() {
+				mock.ExpectQuery(`INSERT INTO categories`).
+					WithArgs("Go", "", now).
+					WillReturnError(&pq.Error{Code: pgUniqueViolation})
+			},
+			wantErr: true,
+		},
+		{
+			name:     "Database Error",
+			category: &entity.Category{Name: "Go", CreatedAt: now},
+			mock: func() {
+				mock.ExpectQuery(`INSERT INTO categories`).
+					WithArgs("Go", "", now).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			got, err := repo.CreateCategory(context.Background(), tt.category)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateCategory() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetCategoryByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
+			AddRow(1, "Go", "Всё о Go", now, now)
+		mock.ExpectQuery(`SELECT id, name, description, created_at`).WithArgs(int64(1)).WillReturnRows(rows)
+
+		got, err := repo.GetCategoryByID(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.Category{ID: 1, Name: "Go", Description: "Всё о Go", CreatedAt: now, UpdatedAt: now}, got)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, name, description, created_at`).WithArgs(int64(2)).WillReturnError(sql.ErrNoRows)
+
+		got, err := repo.GetCategoryByID(context.Background(), 2)
+		assert.ErrorIs(t, err, ErrCategoryNotFound)
+		assert.Nil(t, got)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		category := &entity.Category{ID: 1, Name: "Golang", UpdatedAt: now}
+		mock.ExpectQuery(`UPDATE categories`).
+			WithArgs("Golang", "", now, int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(now.Add(-time.Hour)))
+
+		assert.NoError(t, repo.UpdateCategory(context.Background(), category))
+		assert.Equal(t, now.Add(-time.Hour), category.CreatedAt)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE categories`).
+			WithArgs("Golang", "", now, int64(2)).
+			WillReturnError(sql.ErrNoRows)
+
+		err := repo.UpdateCategory(context.Background(), &entity.Category{ID: 2, Name: "Golang", UpdatedAt: now})
+		assert.ErrorIs(t, err, ErrCategoryNotFound)
+	})
+
+	t.Run("Duplicate Name", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE categories`).
+			WithArgs("Go", "", now, int64(3)).
+			WillReturnError(&pq.Error{Code: pgUniqueViolation})
+
+		err := repo.UpdateCategory(context.Background(), &entity.Category{ID: 3, Name: "Go", UpdatedAt: now})
+		assert.ErrorIs(t, err, ErrCategoryExists)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewCategoryRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(`DELETE FROM categories`).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteCategory(context.Background(), 1))
+
+	mock.ExpectExec(`DELETE FROM categories`).WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteCategory(context.Background(), 2), ErrCategoryNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
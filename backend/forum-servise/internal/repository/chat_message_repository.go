@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrChatRoomNotFound = errors.New("chat room not found")
+
+// ChatMessageRepository работает с таблицей chat_messages, которую
+// также использует chat-servise.
+type ChatMessageRepository interface {
+	CreateChatMessage(ctx context.Context, msg *entity.ChatMessage) (int64, error)
+	// GetChatMessagesAfter возвращает сообщения комнаты roomID с id больше afterID.
+	GetChatMessagesAfter(ctx context.Context, roomID, afterID int64, limit int) ([]entity.ChatMessage, error)
+	GetLastChatMessageID(ctx context.Context) (int64, error)
+	// GetChatRoomAccess сообщает, закрыта ли комната и состоит ли в ней
+	// пользователь.
+	GetChatRoomAccess(ctx context.Context, roomID, userID int64) (isPrivate, isMember bool, err error)
+}
+
+type chatMessageRepository struct {
+	db *sqlx.DB
+}
+
+func NewChatMessageRepository(db *sqlx.DB) ChatMessageRepository {
+	return &chatMessageRepository{db: db}
+}
+
+func (r *chatMessageRepository) CreateChatMessage(ctx context.Context, msg *entity.ChatMessage) (int64, error) {
+	query := `
+		INSERT INTO chat_messages (room_id, user_id, username, content, timestamp)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query,
+		msg.RoomID,
+		msg.UserID,
+		msg.Username,
+		msg.Content,
+		msg.CreatedAt,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *chatMessageRepository) GetChatMessagesAfter(ctx context.Context, roomID, afterID int64, limit int) ([]entity.ChatMessage, error) {
+	query := `
+		SELECT id, room_id, user_id, username, content, timestamp
+		FROM chat_messages
+		WHERE room_id = $1 AND id > $2
+		ORDER BY id ASC
+		LIMIT $3`
+
+	messages := []entity.ChatMessage{}
+	if err := r.db.SelectContext(ctx, &messages, query, roomID, afterID, limit); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *chatMessageRepository) GetLastChatMessageID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.GetContext(ctx, &id, `SELECT COALESCE(MAX(id), 0) FROM chat_messages`)
+	return id, err
+}
+
+func (r *chatMessageRepository) GetChatRoomAccess(ctx context.Context, roomID, userID int64) (bool, bool, error) {
+	query := `
+		SELECT r.is_private,
+		       EXISTS (SELECT 1 FROM chat_room_members m WHERE m.room_id = r.id AND m.user_id = $2)
+		FROM chat_rooms r
+		WHERE r.id = $1`
+
+	var isPrivate, isMember bool
+	err := r.db.QueryRowContext(ctx, query, roomID, userID).Scan(&isPrivate, &isMember)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, false, ErrChatRoomNotFound
+	}
+	return isPrivate, isMember, err
+}
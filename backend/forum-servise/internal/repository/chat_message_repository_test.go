@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChatMessagesAfter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewChatMessageRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "room_id", "user_id", "username", "content", "timestamp"}).
+		AddRow(11, 2, 1, "alice", "привет", now).
+		AddRow(12, 2, 2, "bob", "hi", now)
+	mock.ExpectQuery(`FROM chat_messages\s+WHERE room_id = \$1 AND id > \$2`).
+		WithArgs(int64(2), int64(10), 100).WillReturnRows(rows)
+
+	got, err := repo.GetChatMessagesAfter(context.Background(), 2, 10, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.ChatMessage{
+		{ID: 11, RoomID: 2, UserID: 1, Username: "alice", Content: "привет", CreatedAt: now},
+		{ID: 12, RoomID: 2, UserID: 2, Username: "bob", Content: "hi", CreatedAt: now},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLastChatMessageID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewChatMessageRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(id\), 0\) FROM chat_messages`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(42))
+
+	id, err := repo.GetLastChatMessageID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+}
+
+func TestCreateChatMessage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewChatMessageRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+	mock.ExpectQuery(`INSERT INTO chat_messages \(room_id, user_id, username, content, timestamp\)`).
+		WithArgs(int64(2), int64(7), "bob", "привет", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(15))
+
+	id, err := repo.CreateChatMessage(context.Background(), &entity.ChatMessage{
+		RoomID: 2, UserID: 7, Username: "bob", Content: "привет", CreatedAt: now,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetChatRoomAccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewChatMessageRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`FROM chat_rooms r\s+WHERE r.id = \$1`).WithArgs(int64(2), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"is_private", "exists"}).AddRow(true, true))
+	mock.ExpectQuery(`FROM chat_rooms r\s+WHERE r.id = \$1`).WithArgs(int64(9), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"is_private", "exists"}))
+
+	isPrivate, isMember, err := repo.GetChatRoomAccess(context.Background(), 2, 7)
+	assert.NoError(t, err)
+	assert.True(t, isPrivate)
+	assert.True(t, isMember)
+
+	_, _, err = repo.GetChatRoomAccess(context.Background(), 9, 7)
+	assert.ErrorIs(t, err, ErrChatRoomNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,14 +4,54 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+var ErrCommentNotFound = errors.New("comment not found")
+
+// visibleCommentOf — условие видимости комментария alias: удаленный
+// комментарий остается в ветке заглушкой, пока ниже есть хоть один живой
+// ответ; ветка из одних удаленных комментариев из выдачи пропадает.
+func visibleCommentOf(alias string) string {
+	return fmt.Sprintf(`(%[1]s.deleted_at IS NULL OR EXISTS (
+                WITH RECURSIVE below AS (
+                    SELECT id, deleted_at FROM comments WHERE parent_id = %[1]s.id
+                    UNION ALL
+                    SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id
+                )
+                SELECT 1 FROM below WHERE deleted_at IS NULL))`, alias)
+}
+
+var visibleComment = visibleCommentOf("c")
+
+var commentColumns = `c.id, c.content, c.content_html, c.author_id, c.post_id, c.author_name,
+            c.created_at, c.updated_at, c.updated_at IS NOT NULL AS edited, c.parent_id, c.depth,
+            c.deleted_at, c.deleted_by, c.deleted_at IS NOT NULL AS deleted, c.score,
+            (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id
+                AND ` + visibleCommentOf("r") + `) AS reply_count`
+
 type CommentRepository interface {
 	CreateComment(ctx context.Context, comment *entity.Comment) error
-	GetCommentsByPostID(ctx context.Context, postID int64) ([]entity.Comment, error)
+	GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error)
+	GetCommentsByPostID(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error)
+	CountComments(ctx context.Context, postID int64) (int64, error)
+	GetReplies(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Comment, error)
+	GetDescendants(ctx context.Context, parentIDs []int64, depth, perParent int) ([]*entity.Comment, error)
+	UpdateComment(ctx context.Context, id, postID, authorID int64, role, content, contentHTML string) (*entity.Comment, error)
+	DeleteComment(ctx context.Context, id, postID, authorID int64, role string) error
+	RestoreComment(ctx context.Context, id int64) error
+	GetDeletedComments(ctx context.Context, limit, offset int) ([]*entity.Comment, error)
+	CountDeletedComments(ctx context.Context) (int64, error)
+	PurgeDeletedComments(ctx context.Context, before time.Time, limit int) (int64, error)
+	VoteComment(ctx context.Context, commentID, userID int64, value int) (int, error)
+	GetCommentVotes(ctx context.Context, userID int64, commentIDs []int64) (map[int64]int, error)
+	ToggleCommentReaction(ctx context.Context, commentID, userID int64, emoji string) (bool, error)
+	GetCommentReactions(ctx context.Context, viewerID int64, commentIDs []int64) (map[int64][]entity.Reaction, error)
 }
 
 type CommentRepo struct {
@@ -23,35 +63,237 @@ func NewCommentRepository(db *sqlx.DB) CommentRepository {
 }
 
 func (r *CommentRepo) CreateComment(ctx context.Context, comment *entity.Comment) error {
-	query := `INSERT INTO comments (content, author_id, post_id, author_name) 
-        VALUES ($1, $2, $3, $4) RETURNING id`
+	query := `INSERT INTO comments (content, author_id, post_id, author_name, parent_id, depth, content_html) 
+        VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
 	return r.db.QueryRowContext(ctx, query,
 		comment.Content,
 		comment.AuthorID,
 		comment.PostID,
 		comment.AuthorName,
-	).Scan(&comment.ID)
+		comment.ParentID,
+		comment.Depth,
+		comment.ContentHTML,
+	).Scan(&comment.ID, &comment.CreatedAt)
+}
+
+func (r *CommentRepo) GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error) {
+	query := `SELECT ` + commentColumns + `
+        FROM comments c
+        WHERE c.id = $1`
+
+	var comment entity.Comment
+	if err := r.db.GetContext(ctx, &comment, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
 }
 
-func (r *CommentRepo) GetCommentsByPostID(ctx context.Context, postID int64) ([]entity.Comment, error) {
+// GetCommentsByPostID возвращает страницу веток поста — комментарии
+// верхнего уровня, новые первыми.
+func (r *CommentRepo) GetCommentsByPostID(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
 	query := `
-        SELECT 
-            id,
-            content,
-            author_id,
-            post_id,
-            author_name
-        FROM comments 
-        WHERE post_id = $1
-        ORDER BY id DESC`
-
-	var comments []entity.Comment
-	err := r.db.SelectContext(ctx, &comments, query, postID)
+        SELECT ` + commentColumns + `
+        FROM comments c
+        WHERE c.post_id = $1 AND c.parent_id IS NULL AND ` + visibleComment + `
+        ORDER BY c.id DESC
+        LIMIT $2 OFFSET $3`
+
+	comments := []*entity.Comment{}
+	if err := r.db.SelectContext(ctx, &comments, query, postID, limit, offset); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// CountComments считает ветки поста, то есть комментарии верхнего уровня.
+func (r *CommentRepo) CountComments(ctx context.Context, postID int64) (int64, error) {
+	var total int64
+	err := r.db.GetContext(ctx, &total, `
+        SELECT COUNT(*) FROM comments c
+        WHERE c.post_id = $1 AND c.parent_id IS NULL AND `+visibleComment, postID)
 	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetReplies возвращает страницу прямых ответов в порядке написания.
+func (r *CommentRepo) GetReplies(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Comment, error) {
+	query := `
+        SELECT ` + commentColumns + `
+        FROM comments c
+        WHERE c.parent_id = $1 AND ` + visibleComment + `
+        ORDER BY c.id
+        LIMIT $2 OFFSET $3`
+
+	comments := []*entity.Comment{}
+	if err := r.db.SelectContext(ctx, &comments, query, parentID, limit, offset); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// GetDescendants возвращает ответы на parentIDs не глубже depth уровней,
+// у каждого комментария — не больше perParent первых ответов. Ответы на
+// отброшенные комментарии тоже попадают в выборку, их отсекает сборка
+// дерева. Строки идут по уровням, поэтому родитель всегда раньше ответа.
+func (r *CommentRepo) GetDescendants(ctx context.Context, parentIDs []int64, depth, perParent int) ([]*entity.Comment, error) {
+	comments := []*entity.Comment{}
+	if len(parentIDs) == 0 || depth < 1 {
+		return comments, nil
+	}
+
+	query := `
+        WITH RECURSIVE tree AS (
+            SELECT c.id, c.parent_id, 1 AS level
+            FROM comments c
+            WHERE c.parent_id = ANY($1) AND ` + visibleComment + `
+            UNION ALL
+            SELECT c.id, c.parent_id, t.level + 1
+            FROM comments c
+            JOIN tree t ON c.parent_id = t.id
+            WHERE t.level < $2 AND ` + visibleComment + `
+        ),
+        ranked AS (
+            SELECT id, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY id) AS rn
+            FROM tree
+        )
+        SELECT ` + commentColumns + `
+        FROM comments c
+        JOIN ranked ON ranked.id = c.id
+        WHERE ranked.rn <= $3
+        ORDER BY c.depth, c.id`
+
+	if err := r.db.SelectContext(ctx, &comments, query, pq.Array(parentIDs), depth, perParent); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// UpdateComment меняет текст комментария автора (или любого — для админа)
+// и отмечает время правки.
+func (r *CommentRepo) UpdateComment(ctx context.Context, id, postID, authorID int64, role, content, contentHTML string) (*entity.Comment, error) {
+	query := `
+        UPDATE comments c
+        SET content = $1, content_html = $6, updated_at = CURRENT_TIMESTAMP
+        WHERE c.id = $2 AND c.post_id = $3 AND c.deleted_at IS NULL
+        AND (c.author_id = $4 OR $5 = 'admin')
+        RETURNING ` + commentColumns
+
+	var comment entity.Comment
+	if err := r.db.GetContext(ctx, &comment, query, content, id, postID, authorID, role, contentHTML); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return []entity.Comment{}, nil
+			return nil, ErrCommentNotFound
 		}
 		return nil, err
 	}
+	return &comment, nil
+}
+
+// DeleteComment переносит комментарий в корзину. Ответы на него остаются.
+func (r *CommentRepo) DeleteComment(ctx context.Context, id, postID, authorID int64, role string) error {
+	query := `
+        UPDATE comments
+        SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $3
+        WHERE id = $1 AND post_id = $2 AND deleted_at IS NULL
+        AND (author_id = $3 OR $4 = 'admin')`
+
+	result, err := r.db.ExecContext(ctx, query, id, postID, authorID, role)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+func (r *CommentRepo) RestoreComment(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE comments
+        SET deleted_at = NULL, deleted_by = NULL
+        WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// GetDeletedComments возвращает страницу корзины, недавно удаленные первыми.
+func (r *CommentRepo) GetDeletedComments(ctx context.Context, limit, offset int) ([]*entity.Comment, error) {
+	query := `
+        SELECT ` + commentColumns + `
+        FROM comments c
+        WHERE c.deleted_at IS NOT NULL
+        ORDER BY c.deleted_at DESC, c.id DESC
+        LIMIT $1 OFFSET $2`
+
+	comments := []*entity.Comment{}
+	if err := r.db.SelectContext(ctx, &comments, query, limit, offset); err != nil {
+		return nil, err
+	}
 	return comments, nil
 }
+
+func (r *CommentRepo) CountDeletedComments(ctx context.Context) (int64, error) {
+	var total int64
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM comments WHERE deleted_at IS NOT NULL`); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// PurgeDeletedComments окончательно удаляет до limit комментариев, удаленных
+// раньше before. Комментарии с ответами не трогаем, иначе каскад унес бы
+// живые ответы: заглушка уйдет, когда очистятся все ответы под ней.
+func (r *CommentRepo) PurgeDeletedComments(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+        DELETE FROM comments
+        WHERE id IN (
+            SELECT c.id FROM comments c
+            WHERE c.deleted_at < $1
+            AND NOT EXISTS (SELECT 1 FROM comments k WHERE k.parent_id = c.id)
+            ORDER BY c.deleted_at
+            LIMIT $2
+        )`, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// VoteComment ставит голос userID за комментарий (0 — снимает) и возвращает
+// новый счет. За удаленный комментарий голосовать нельзя.
+func (r *CommentRepo) VoteComment(ctx context.Context, commentID, userID int64, value int) (int, error) {
+	return castVote(ctx, r.db, commentVotes, commentID, userID, value)
+}
+
+func (r *CommentRepo) GetCommentVotes(ctx context.Context, userID int64, commentIDs []int64) (map[int64]int, error) {
+	return userVotes(ctx, r.db, commentVotes, userID, commentIDs)
+}
+
+// ToggleCommentReaction ставит или снимает реакцию userID на комментарий;
+// true — поставлена. На удаленный комментарий реагировать нельзя.
+func (r *CommentRepo) ToggleCommentReaction(ctx context.Context, commentID, userID int64, emoji string) (bool, error) {
+	return toggleReaction(ctx, r.db, commentReactions, commentID, userID, emoji)
+}
+
+func (r *CommentRepo) GetCommentReactions(ctx context.Context, viewerID int64, commentIDs []int64) (map[int64][]entity.Reaction, error) {
+	return itemReactions(ctx, r.db, commentReactions, viewerID, commentIDs)
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateComment(t *testing.T) {
@@ -31,18 +34,36 @@ func TestCreateComment(t *testing.T) {
 		{
 			name: "Success",
 			comment: &entity.Comment{
-				Content:    "Test comment",
-				AuthorID:   1,
-				PostID:     1,
-				AuthorName: "testuser",
+				Content:     "Test comment",
+				ContentHTML: "<p>Test comment</p>\n",
+				AuthorID:    1,
+				PostID:      1,
+				AuthorName:  "testuser",
 			},
 			mock: func() {
 				mock.ExpectQuery(`INSERT INTO comments`).
-					WithArgs("Test comment", int64(1), int64(1), "testuser").
-					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					WithArgs("Test comment", int64(1), int64(1), "testuser", nil, 0, "<p>Test comment</p>\n").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
 			},
 			wantID: 1,
 		},
+		{
+			name: "Reply",
+			comment: &entity.Comment{
+				Content:    "Reply",
+				AuthorID:   2,
+				PostID:     1,
+				AuthorName: "bob",
+				ParentID:   int64Ptr(1),
+				Depth:      1,
+			},
+			mock: func() {
+				mock.ExpectQuery(`INSERT INTO comments`).
+					WithArgs("Reply", int64(2), int64(1), "bob", int64(1), 1, "").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(2, time.Now()))
+			},
+			wantID: 2,
+		},
 		{
 			name: "Empty Content",
 			comment: &entity.Comment{
@@ -53,7 +74,7 @@ func TestCreateComment(t *testing.T) {
 			},
 			mock: func() {
 				mock.ExpectQuery(`INSERT INTO comments`).
-					WithArgs("", int64(1), int64(1), "testuser").
+					WithArgs("", int64(1), int64(1), "testuser", nil, 0).
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
@@ -77,6 +98,9 @@ func TestCreateComment(t *testing.T) {
 	}
 }
 
+var commentRowColumns = []string{"id", "content", "author_id", "post_id", "author_name",
+	"created_at", "parent_id", "depth", "reply_count"}
+
 func TestGetCommentsByPostID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -86,37 +110,42 @@ func TestGetCommentsByPostID(t *testing.T) {
 
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	repo := NewCommentRepository(sqlxDB)
+	now := time.Now()
 
 	tests := []struct {
 		name    string
 		postID  int64
 		mock    func()
-		want    []entity.Comment
+		want    []*entity.Comment
 		wantErr bool
 	}{
 		{
 			name:   "Success",
 			postID: 1,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "content", "author_id", "post_id", "author_name"}).
-					AddRow(1, "Comment 1", 1, 1, "user1").
-					AddRow(2, "Comment 2", 2, 1, "user2")
-				mock.ExpectQuery(`SELECT`).WithArgs(int64(1)).WillReturnRows(rows)
+				rows := sqlmock.NewRows(commentRowColumns).
+					AddRow(2, "Comment 2", 2, 1, "user2", now, nil, 0, 3).
+					AddRow(1, "Comment 1", 1, 1, "user1", now, nil, 0, 0)
+				mock.ExpectQuery(`SELECT .+ FROM comments c WHERE c.post_id = \$1 AND c.parent_id IS NULL AND \(c.deleted_at IS NULL OR EXISTS .+\) ORDER BY c.id DESC LIMIT \$2 OFFSET \$3`).
+					WithArgs(int64(1), 20, 0).WillReturnRows(rows)
 			},
-			want: []entity.Comment{
-				{
-					ID:         1,
-					Content:    "Comment 1",
-					AuthorID:   1,
-					PostID:     1,
-					AuthorName: "user1",
-				},
+			want: []*entity.Comment{
 				{
 					ID:         2,
 					Content:    "Comment 2",
 					AuthorID:   2,
 					PostID:     1,
 					AuthorName: "user2",
+					CreatedAt:  now,
+					ReplyCount: 3,
+				},
+				{
+					ID:         1,
+					Content:    "Comment 1",
+					AuthorID:   1,
+					PostID:     1,
+					AuthorName: "user1",
+					CreatedAt:  now,
 				},
 			},
 		},
@@ -124,16 +153,16 @@ func TestGetCommentsByPostID(t *testing.T) {
 			name:   "No Comments",
 			postID: 2,
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "content", "author_id", "post_id", "author_name"})
-				mock.ExpectQuery(`SELECT`).WithArgs(int64(2)).WillReturnRows(rows)
+				rows := sqlmock.NewRows(commentRowColumns)
+				mock.ExpectQuery(`SELECT`).WithArgs(int64(2), 20, 0).WillReturnRows(rows)
 			},
-			want: []entity.Comment{},
+			want: []*entity.Comment{},
 		},
 		{
 			name:   "Database Error",
 			postID: 3,
 			mock: func() {
-				mock.ExpectQuery(`SELECT`).WithArgs(int64(3)).WillReturnError(sql.ErrConnDone)
+				mock.ExpectQuery(`SELECT`).WithArgs(int64(3), 20, 0).WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
 		},
@@ -143,7 +172,7 @@ func TestGetCommentsByPostID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetCommentsByPostID(context.Background(), tt.postID)
+			got, err := repo.GetCommentsByPostID(context.Background(), tt.postID, 20, 0)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetCommentsByPostID() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -152,3 +181,245 @@ func TestGetCommentsByPostID(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCommentByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT .+ FROM comments c WHERE c.id = \$1`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows(commentRowColumns).
+			AddRow(5, "Reply", 2, 1, "bob", now, 4, 2, 1))
+
+	got, err := repo.GetCommentByID(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.Comment{
+		ID: 5, Content: "Reply", AuthorID: 2, PostID: 1, AuthorName: "bob",
+		CreatedAt: now, ParentID: int64Ptr(4), Depth: 2, ReplyCount: 1,
+	}, got)
+
+	mock.ExpectQuery(`SELECT .+ FROM comments c WHERE c.id = \$1`).
+		WithArgs(int64(6)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetCommentByID(context.Background(), 6)
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountComments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM comments c WHERE c.post_id = \$1 AND c.parent_id IS NULL AND \(c.deleted_at IS NULL`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	total, err := repo.CountComments(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReplies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	// Заглушка видна, только если ниже по ветке есть живой ответ.
+	mock.ExpectQuery(`SELECT .+ FROM comments c WHERE c.parent_id = \$1 AND \(c.deleted_at IS NULL OR EXISTS \( WITH RECURSIVE below AS \( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL .+ \) SELECT 1 FROM below WHERE deleted_at IS NULL\)\) ORDER BY c.id LIMIT \$2 OFFSET \$3`).
+		WithArgs(int64(1), 10, 10).
+		WillReturnRows(sqlmock.NewRows(commentRowColumns).
+			AddRow(11, "First", 2, 1, "bob", now, 1, 1, 0).
+			AddRow(12, "Second", 3, 1, "carol", now, 1, 1, 2))
+
+	got, err := repo.GetReplies(context.Background(), 1, 10, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, int64(11), got[0].ID)
+	assert.Equal(t, int64Ptr(1), got[1].ParentID)
+	assert.Equal(t, 2, got[1].ReplyCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDescendants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Nothing to expand", func(t *testing.T) {
+		got, err := repo.GetDescendants(context.Background(), nil, 3, 5)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+
+		got, err = repo.GetDescendants(context.Background(), []int64{1}, 0, 5)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(`WITH RECURSIVE tree AS .+ WHERE c.parent_id = ANY\(\$1\) .+ WHERE t.level < \$2 .+ WHERE ranked.rn <= \$3 ORDER BY c.depth, c.id`).
+			WithArgs(pq.Array([]int64{1, 2}), 3, 5).
+			WillReturnRows(sqlmock.NewRows(commentRowColumns).
+				AddRow(3, "Reply", 2, 1, "bob", now, 1, 1, 1).
+				AddRow(4, "Nested", 3, 1, "carol", now, 3, 2, 0))
+
+		got, err := repo.GetDescendants(context.Background(), []int64{1, 2}, 3, 5)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+		assert.Equal(t, int64Ptr(3), got[1].ParentID)
+		assert.Equal(t, 2, got[1].Depth)
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		mock.ExpectQuery(`WITH RECURSIVE`).WillReturnError(sql.ErrConnDone)
+
+		_, err := repo.GetDescendants(context.Background(), []int64{1}, 1, 5)
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+	columns := append(append([]string{}, commentRowColumns...), "updated_at", "edited")
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE comments c SET content = \$1, content_html = \$6, updated_at = CURRENT_TIMESTAMP WHERE c.id = \$2 AND c.post_id = \$3 AND c.deleted_at IS NULL AND \(c.author_id = \$4 OR \$5 = 'admin'\) RETURNING`).
+			WithArgs("edited text", int64(5), int64(1), int64(2), "user", "<p>edited text</p>\n").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(5, "edited text", 2, 1, "bob", now, nil, 0, 0, now, true))
+
+		got, err := repo.UpdateComment(context.Background(), 5, 1, 2, "user", "edited text", "<p>edited text</p>\n")
+		require.NoError(t, err)
+		assert.Equal(t, "edited text", got.Content)
+		assert.True(t, got.Edited)
+		require.NotNil(t, got.UpdatedAt)
+		assert.Equal(t, now, *got.UpdatedAt)
+	})
+
+	t.Run("Not found or not allowed", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE comments`).
+			WithArgs("edited text", int64(5), int64(1), int64(3), "user", "<p>edited text</p>\n").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UpdateComment(context.Background(), 5, 1, 3, "user", "edited text", "<p>edited text</p>\n")
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(`UPDATE comments SET deleted_at = CURRENT_TIMESTAMP, deleted_by = \$3 WHERE id = \$1 AND post_id = \$2 AND deleted_at IS NULL AND \(author_id = \$3 OR \$4 = 'admin'\)`).
+		WithArgs(int64(5), int64(1), int64(2), "user").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteComment(context.Background(), 5, 1, 2, "user"))
+
+	mock.ExpectExec(`UPDATE comments`).
+		WithArgs(int64(5), int64(1), int64(3), "user").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteComment(context.Background(), 5, 1, 3, "user"), ErrCommentNotFound)
+
+	mock.ExpectExec(`UPDATE comments`).
+		WithArgs(int64(6), int64(1), int64(2), "user").
+		WillReturnError(sql.ErrConnDone)
+	assert.Error(t, repo.DeleteComment(context.Background(), 6, 1, 2, "user"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(`UPDATE comments SET deleted_at = NULL, deleted_by = NULL WHERE id = \$1 AND deleted_at IS NOT NULL`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.RestoreComment(context.Background(), 5))
+
+	mock.ExpectExec(`UPDATE comments`).
+		WithArgs(int64(6)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.RestoreComment(context.Background(), 6), ErrCommentNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeletedComments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+	columns := append(append([]string{}, commentRowColumns...), "deleted_at", "deleted_by", "deleted")
+
+	mock.ExpectQuery(`FROM comments c WHERE c.deleted_at IS NOT NULL ORDER BY c.deleted_at DESC, c.id DESC LIMIT \$1 OFFSET \$2`).
+		WithArgs(20, 0).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(5, "Gone", 2, 1, "bob", now, nil, 0, 0, now, 3, true))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM comments WHERE deleted_at IS NOT NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	got, err := repo.GetDeletedComments(context.Background(), 20, 0)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.True(t, got[0].Deleted)
+	assert.Equal(t, int64Ptr(3), got[0].DeletedBy)
+
+	total, err := repo.CountDeletedComments(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeDeletedComments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+	before := time.Now().Add(-time.Hour)
+
+	mock.ExpectExec(`DELETE FROM comments WHERE id IN \( SELECT c.id FROM comments c WHERE c.deleted_at < \$1 AND NOT EXISTS \(SELECT 1 FROM comments k WHERE k.parent_id = c.id\) ORDER BY c.deleted_at LIMIT \$2 \)`).
+		WithArgs(before, 100).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	purged, err := repo.PurgeDeletedComments(context.Background(), before, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+// ContentTable — таблица с markdown-текстом в content и HTML в content_html.
+type ContentTable string
+
+const (
+	PostsContent    ContentTable = "posts"
+	CommentsContent ContentTable = "comments"
+)
+
+// ContentRepository нужен для дорисовки content_html записям, созданным
+// до появления markdown. Обычные создание и правка пишут HTML сами.
+type ContentRepository interface {
+	GetUnrendered(ctx context.Context, table ContentTable, afterID int64, limit int) ([]entity.ContentSource, error)
+	SetContentHTML(ctx context.Context, table ContentTable, id int64, contentHTML string) error
+}
+
+type contentRepository struct {
+	db *sqlx.DB
+}
+
+func NewContentRepository(db *sqlx.DB) ContentRepository {
+	return &contentRepository{db: db}
+}
+
+// GetUnrendered возвращает до limit записей с id больше afterID, у которых
+// есть текст, но нет HTML, по возрастанию id. Удаленные в корзину тоже
+// попадают: их могут восстановить.
+func (r *contentRepository) GetUnrendered(ctx context.Context, table ContentTable, afterID int64, limit int) ([]entity.ContentSource, error) {
+	query := fmt.Sprintf(`
+		SELECT id, content FROM %s
+		WHERE content_html = '' AND content <> '' AND id > $1
+		ORDER BY id
+		LIMIT $2`, table)
+
+	sources := []entity.ContentSource{}
+	if err := r.db.SelectContext(ctx, &sources, query, afterID, limit); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// SetContentHTML записывает HTML, только если его еще нет: если запись
+// успели отредактировать, у нее уже свежий HTML.
+func (r *contentRepository) SetContentHTML(ctx context.Context, table ContentTable, id int64, contentHTML string) error {
+	query := fmt.Sprintf(`UPDATE %s SET content_html = $2 WHERE id = $1 AND content_html = ''`, table)
+	_, err := r.db.ExecContext(ctx, query, id, contentHTML)
+	return err
+}
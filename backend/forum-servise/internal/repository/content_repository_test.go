@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUnrendered(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewContentRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`SELECT id, content FROM comments\s+WHERE content_html = '' AND content <> '' AND id > \$1\s+ORDER BY id\s+LIMIT \$2`).
+		WithArgs(int64(10), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}).AddRow(11, "*a*").AddRow(14, "b"))
+
+	sources, err := repo.GetUnrendered(context.Background(), CommentsContent, 10, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.ContentSource{{ID: 11, Content: "*a*"}, {ID: 14, Content: "b"}}, sources)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetContentHTML(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewContentRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectExec(`UPDATE posts SET content_html = \$2 WHERE id = \$1 AND content_html = ''`).
+		WithArgs(int64(3), "<p>x</p>\n").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.SetContentHTML(context.Background(), PostsContent, 3, "<p>x</p>\n"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// MentionRepository хранит упоминания пользователей в постах и комментариях.
+type MentionRepository interface {
+	// AddMentions сохраняет упоминания mentions в subject от имени authorID
+	// и возвращает тех, кто упомянут в subject впервые.
+	AddMentions(ctx context.Context, authorID int64, subject entity.Subject, mentions []entity.Mention) ([]entity.Mention, error)
+}
+
+type mentionRepository struct {
+	db *sqlx.DB
+}
+
+func NewMentionRepository(db *sqlx.DB) MentionRepository {
+	return &mentionRepository{db: db}
+}
+
+func (r *mentionRepository) AddMentions(ctx context.Context, authorID int64, subject entity.Subject, mentions []entity.Mention) ([]entity.Mention, error) {
+	added := []entity.Mention{}
+	if len(mentions) == 0 {
+		return added, nil
+	}
+
+	userIDs := make([]int64, len(mentions))
+	usernames := make([]string, len(mentions))
+	for i, mention := range mentions {
+		userIDs[i] = mention.UserID
+		usernames[i] = mention.Username
+	}
+
+	// Повторное упоминание отсекают уникальные индексы по записи.
+	query := `
+		INSERT INTO mentions (user_id, username, author_id, post_id, comment_id, room_id, message_id)
+		SELECT m.user_id, m.username, $3, $4, $5, $6, $7
+		FROM unnest($1::int[], $2::text[]) AS m(user_id, username)
+		ON CONFLICT DO NOTHING
+		RETURNING user_id, username`
+	err := r.db.SelectContext(ctx, &added, query, pq.Array(userIDs), pq.Array(usernames), authorID,
+		subject.PostID, subject.CommentID, subject.RoomID, subject.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
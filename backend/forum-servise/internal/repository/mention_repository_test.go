@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddMentions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewMentionRepository(sqlx.NewDb(db, "sqlmock"))
+	postID, commentID := int64(1), int64(3)
+	subject := entity.Subject{PostID: &postID, CommentID: &commentID}
+	mentions := []entity.Mention{{UserID: 5, Username: "alice"}, {UserID: 6, Username: "bob"}}
+
+	// bob уже был упомянут в этом комментарии — строку не вернули.
+	mock.ExpectQuery(`INSERT INTO mentions \(user_id, username, author_id, post_id, comment_id, room_id, message_id\)\s+SELECT m.user_id, m.username, \$3, \$4, \$5, \$6, \$7\s+FROM unnest\(\$1::int\[\], \$2::text\[\]\).+ON CONFLICT DO NOTHING\s+RETURNING user_id, username`).
+		WithArgs(pq.Array([]int64{5, 6}), pq.Array([]string{"alice", "bob"}), int64(2), postID, commentID, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "username"}).AddRow(5, "alice"))
+
+	added, err := repo.AddMentions(context.Background(), 2, subject, mentions)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Mention{{UserID: 5, Username: "alice"}}, added)
+
+	added, err = repo.AddMentions(context.Background(), 2, subject, nil)
+	require.NoError(t, err)
+	assert.Empty(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backend.com/forum/notify"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationRepository хранит уведомления и настройки уведомлений.
+type NotificationRepository interface {
+	// CreateNotifications создает копию n для каждого из userIDs, кроме
+	// отключивших уведомления типа n.Type, и возвращает созданные.
+	CreateNotifications(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error)
+	// GetNotifications возвращает уведомления пользователя, новые первыми.
+	GetNotifications(ctx context.Context, query entity.NotificationQuery) ([]*entity.Notification, error)
+	CountNotifications(ctx context.Context, userID int64, unreadOnly bool) (int64, error)
+	// MarkRead отмечает прочитанным уведомление id пользователя userID.
+	// Уже прочитанное остается с прежним read_at.
+	MarkRead(ctx context.Context, userID, id int64) error
+	// MarkAllRead отмечает прочитанными все уведомления пользователя и
+	// возвращает, сколько их было непрочитано.
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	// GetPreferences возвращает настройки, которые пользователь менял.
+	GetPreferences(ctx context.Context, userID int64) ([]entity.NotificationPreference, error)
+	SetPreference(ctx context.Context, userID int64, pref entity.NotificationPreference) error
+}
+
+type notificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// CreateNotifications создает уведомления через notify.Create — тем же
+// путем их создает чат.
+func (r *notificationRepository) CreateNotifications(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+	rows, err := notify.Create(ctx, r.db, notify.Notification{
+		Type:      n.Type,
+		ActorID:   n.ActorID,
+		ActorName: n.ActorName,
+		Action:    n.Action,
+		PostID:    n.PostID,
+		CommentID: n.CommentID,
+		RoomID:    n.RoomID,
+		MessageID: n.MessageID,
+	}, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*entity.Notification, 0, len(rows))
+	for _, row := range rows {
+		notification := n
+		notification.ID, notification.UserID, notification.CreatedAt = row.ID, row.UserID, row.CreatedAt
+		created = append(created, &notification)
+	}
+	return created, nil
+}
+
+const notificationColumns = `id, user_id, type, actor_id, actor_name, COALESCE(action, '') AS action,
+	post_id, comment_id, room_id, message_id, created_at, read_at`
+
+func (r *notificationRepository) GetNotifications(ctx context.Context, query entity.NotificationQuery) ([]*entity.Notification, error) {
+	notifications := []*entity.Notification{}
+	err := r.db.SelectContext(ctx, &notifications, `
+		SELECT `+notificationColumns+` FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4`,
+		query.UserID, query.UnreadOnly, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) CountNotifications(ctx context.Context, userID int64, unreadOnly bool) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)`,
+		userID, unreadOnly)
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id int64) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = COALESCE(read_at, NOW()) WHERE id = $1 AND user_id = $2`,
+		id, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`,
+		userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *notificationRepository) GetPreferences(ctx context.Context, userID int64) ([]entity.NotificationPreference, error) {
+	prefs := []entity.NotificationPreference{}
+	query := `SELECT type, enabled FROM notification_preferences WHERE user_id = $1 ORDER BY type`
+	if err := r.db.SelectContext(ctx, &prefs, query, userID); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (r *notificationRepository) SetPreference(ctx context.Context, userID int64, pref entity.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, type, enabled) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, type) DO UPDATE SET enabled = EXCLUDED.enabled`
+	_, err := r.db.ExecContext(ctx, query, userID, pref.Type, pref.Enabled)
+	return err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateNotifications(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNotificationRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+	postID := int64(1)
+	n := entity.Notification{Type: entity.NotificationMention, ActorID: 2, ActorName: "alice",
+		Subject: entity.Subject{PostID: &postID}}
+
+	mock.ExpectQuery(`INSERT INTO notifications \(user_id, type, actor_id, actor_name, action, post_id, comment_id, room_id, message_id\)\s+SELECT u.id, \$2, \$3, \$4, NULLIF\(\$9, ''\), \$5, \$6, \$7, \$8\s+FROM unnest\(\$1::int\[\]\) AS u\(id\)\s+WHERE NOT EXISTS \(.+np.type = \$2 AND NOT np.enabled\s+\)\s+RETURNING id, user_id, created_at`).
+		WithArgs(pq.Array([]int64{5, 6}), entity.NotificationMention, int64(2), "alice", postID, nil, nil, nil, "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).AddRow(10, 5, now))
+
+	created, err := repo.CreateNotifications(context.Background(), n, []int64{5, 6})
+	require.NoError(t, err)
+	require.Len(t, created, 1)
+	want := n
+	want.ID, want.UserID, want.CreatedAt = 10, 5, now
+	assert.Equal(t, &want, created[0])
+
+	created, err = repo.CreateNotifications(context.Background(), n, nil)
+	require.NoError(t, err)
+	assert.Empty(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetNotifications(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNotificationRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+	columns := []string{"id", "user_id", "type", "actor_id", "actor_name", "action",
+		"post_id", "comment_id", "room_id", "message_id", "created_at", "read_at"}
+
+	mock.ExpectQuery(`SELECT id, user_id, type, actor_id, actor_name, COALESCE\(action, ''\) AS action,.+FROM notifications\s+WHERE user_id = \$1 AND \(NOT \$2 OR read_at IS NULL\)\s+ORDER BY id DESC\s+LIMIT \$3 OFFSET \$4`).
+		WithArgs(int64(5), true, 20, 0).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(11, 5, entity.NotificationModeration, 1, "root", entity.ModerationPostDeleted, 3, nil, nil, nil, now, nil))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM notifications WHERE user_id = \$1 AND \(NOT \$2 OR read_at IS NULL\)`).
+		WithArgs(int64(5), true).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	notifications, err := repo.GetNotifications(context.Background(),
+		entity.NotificationQuery{UserID: 5, UnreadOnly: true, Limit: 20})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, entity.ModerationPostDeleted, notifications[0].Action)
+	assert.Equal(t, int64(3), *notifications[0].PostID)
+	assert.Nil(t, notifications[0].CommentID)
+	assert.Nil(t, notifications[0].ReadAt)
+
+	count, err := repo.CountNotifications(context.Background(), 5, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNotificationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(`UPDATE notifications SET read_at = COALESCE\(read_at, NOW\(\)\) WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(11), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE notifications SET read_at = COALESCE\(read_at, NOW\(\)\) WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(int64(12), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE notifications SET read_at = NOW\(\) WHERE user_id = \$1 AND read_at IS NULL`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	require.NoError(t, repo.MarkRead(context.Background(), 5, 11))
+	assert.ErrorIs(t, repo.MarkRead(context.Background(), 5, 12), ErrNotificationNotFound)
+	marked, err := repo.MarkAllRead(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), marked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewNotificationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(`INSERT INTO notification_preferences \(user_id, type, enabled\) VALUES \(\$1, \$2, \$3\)\s+ON CONFLICT \(user_id, type\) DO UPDATE SET enabled = EXCLUDED.enabled`).
+		WithArgs(int64(5), entity.NotificationMention, false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT type, enabled FROM notification_preferences WHERE user_id = \$1`).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "enabled"}).AddRow(entity.NotificationMention, false))
+
+	require.NoError(t, repo.SetPreference(context.Background(), 5,
+		entity.NotificationPreference{Type: entity.NotificationMention, Enabled: false}))
+	prefs, err := repo.GetPreferences(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.NotificationPreference{{Type: entity.NotificationMention, Enabled: false}}, prefs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,22 +4,47 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 var (
 	ErrPostNotFound     = errors.New("post not found")
 	ErrPermissionDenied = errors.New("permission denied")
+	ErrRevisionNotFound = errors.New("revision not found")
 )
 
 type PostRepository interface {
 	CreatePost(ctx context.Context, post *entity.Post) (int64, error)
-	GetPosts(ctx context.Context) ([]*entity.Post, error)
+	GetPosts(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error)
+	CountPosts(ctx context.Context, query entity.PostListQuery) (int64, error)
+	GetPostsByTopicID(ctx context.Context, topicID int64) ([]*entity.Post, error)
 	GetPostByID(ctx context.Context, id int64) (*entity.Post, error)
 	DeletePost(ctx context.Context, id, authorID int64, role string) error
-	UpdatePost(ctx context.Context, id, authorID int64, role, title, content string) (*entity.Post, error)
+	// UpdatePost при tags == nil оставляет теги поста как есть.
+	UpdatePost(ctx context.Context, id, authorID int64, role, title, content, contentHTML string, tags []string) (*entity.Post, error)
+	RestorePost(ctx context.Context, id int64) error
+	GetDeletedPosts(ctx context.Context, limit, offset int) ([]*entity.Post, error)
+	CountDeletedPosts(ctx context.Context) (int64, error)
+	// PurgeDeletedPosts возвращает число удаленных постов и ключи файлов
+	// их вложений в хранилище: записи о вложениях удаляются каскадом, а
+	// файлы должен удалить вызывающий.
+	PurgeDeletedPosts(ctx context.Context, before time.Time, limit int) (int64, []string, error)
+	GetRevisions(ctx context.Context, postID int64, limit, offset int) ([]*entity.PostRevision, error)
+	CountRevisions(ctx context.Context, postID int64) (int64, error)
+	GetRevision(ctx context.Context, postID int64, revision int) (*entity.PostRevision, error)
+	RollbackPost(ctx context.Context, postID int64, revision int, editorID int64, contentHTML string) (*entity.Post, error)
+	VotePost(ctx context.Context, postID, userID int64, value int) (int, error)
+	GetPostVotes(ctx context.Context, userID int64, postIDs []int64) (map[int64]int, error)
+	TogglePostReaction(ctx context.Context, postID, userID int64, emoji string) (bool, error)
+	GetPostReactions(ctx context.Context, viewerID int64, postIDs []int64) (map[int64][]entity.Reaction, error)
+	GetPostTags(ctx context.Context, postIDs []int64) (map[int64][]string, error)
+	GetPostAttachments(ctx context.Context, postIDs []int64) (map[int64][]entity.Attachment, error)
 }
 
 type postRepository struct {
@@ -30,40 +55,161 @@ func NewPostRepository(db *sqlx.DB) PostRepository {
 	return &postRepository{db: db}
 }
 
+// upsertTags создает недостающие теги из массива имен и возвращает id всех.
+// DO UPDATE нужен, чтобы RETURNING вернул и уже существующие теги.
+const upsertTags = `
+	INSERT INTO tags (name) SELECT unnest(%s::text[])
+	ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+	RETURNING id`
+
 func (r *postRepository) CreatePost(ctx context.Context, post *entity.Post) (int64, error) {
+	// Исходный текст сразу сохраняется как ревизия 1, теги — тем же запросом.
 	query := `
-		INSERT INTO posts (title, content, author_id, created_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id`
-
-	var id int64
-	err := r.db.QueryRowContext(ctx, query,
+		WITH created AS (
+			INSERT INTO posts (title, content, content_html, author_id, created_at, topic_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, title, content, author_id, created_at
+		), saved AS (
+			INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at)
+			SELECT id, 1, title, content, author_id, created_at FROM created
+		)`
+	args := []interface{}{
 		post.Title,
 		post.Content,
+		post.ContentHTML,
 		post.AuthorID,
 		post.CreatedAt,
-	).Scan(&id)
+		post.TopicID,
+	}
+	if len(post.Tags) > 0 {
+		query += `, tagged AS (` + fmt.Sprintf(upsertTags, "$7") + `
+		), linked AS (
+			INSERT INTO post_tags (post_id, tag_id)
+			SELECT created.id, tagged.id FROM created, tagged
+		)`
+		args = append(args, pq.Array(post.Tags))
+	}
+	query += `
+		SELECT id FROM created`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&id)
+	if isPgError(err, pgForeignKeyViolation) {
+		return 0, ErrTopicNotFound
+	}
 
 	return id, err
 }
 
-func (r *postRepository) GetPosts(ctx context.Context) ([]*entity.Post, error) {
+// GetPosts возвращает не больше query.Limit постов в порядке query.Sort.
+// С курсором выборка идет по ключу (created_at, id), без него — через OFFSET.
+func (r *postRepository) GetPosts(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conds := postFilter(query, arg)
+	if query.Cursor != nil {
+		conds = append(conds, fmt.Sprintf("(created_at, id) < (%s, %s)",
+			arg(query.Cursor.CreatedAt), arg(query.Cursor.ID)))
+	}
+
+	page := "LIMIT " + arg(query.Limit)
+	if query.Cursor == nil {
+		page += " OFFSET " + arg(query.Offset)
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT 
+			id,
+			title,
+			content,
+			content_html,
+			author_id,
+			created_at,
+			topic_id,
+			score
+		FROM posts
+		WHERE %s
+		ORDER BY %s
+		%s`, strings.Join(conds, " AND "), postOrder(query.Sort), page)
+
+	posts := []*entity.Post{}
+	if err := r.db.SelectContext(ctx, &posts, sqlQuery, args...); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// postOrder — ORDER BY для сортировки ленты, совпадает с индексами
+// из миграций.
+func postOrder(sort string) string {
+	switch sort {
+	case entity.PostSortTop:
+		return "score DESC, id DESC"
+	case entity.PostSortHot:
+		return "hot_rank(score, created_at) DESC, id DESC"
+	default:
+		return "created_at DESC, id DESC"
+	}
+}
+
+// postFilter — условия ленты без учета страницы: живые посты, созданные
+// не раньше Since, с тегами Tags. arg добавляет параметр запроса.
+func postFilter(query entity.PostListQuery, arg func(v interface{}) string) []string {
+	conds := []string{"deleted_at IS NULL"}
+	if query.Since != nil {
+		conds = append(conds, "created_at >= "+arg(*query.Since))
+	}
+	if len(query.Tags) > 0 {
+		tagged := `id IN (
+			SELECT pt.post_id FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+			WHERE t.name = ANY(` + arg(pq.Array(query.Tags)) + `)`
+		if query.TagMode == entity.TagMatchAny {
+			tagged += `)`
+		} else {
+			tagged += ` GROUP BY pt.post_id HAVING COUNT(*) = ` + arg(len(query.Tags)) + `)`
+		}
+		conds = append(conds, tagged)
+	}
+	return conds
+}
+
+// CountPosts считает посты ленты query без учета страницы и сортировки.
+func (r *postRepository) CountPosts(ctx context.Context, query entity.PostListQuery) (int64, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	sqlQuery := `SELECT COUNT(*) FROM posts WHERE ` + strings.Join(postFilter(query, arg), " AND ")
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, sqlQuery, args...); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *postRepository) GetPostsByTopicID(ctx context.Context, topicID int64) ([]*entity.Post, error) {
 	query := `
 		SELECT 
 			id,
 			title,
 			content,
+			content_html,
 			author_id,
-			created_at
+			created_at,
+			topic_id,
+			score
 		FROM posts
+		WHERE topic_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC`
 
-	var posts []*entity.Post
-	err := r.db.SelectContext(ctx, &posts, query)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return []*entity.Post{}, nil
-		}
+	posts := []*entity.Post{}
+	if err := r.db.SelectContext(ctx, &posts, query, topicID); err != nil {
 		return nil, err
 	}
 	return posts, nil
@@ -75,10 +221,13 @@ func (r *postRepository) GetPostByID(ctx context.Context, id int64) (*entity.Pos
 			id,
 			title,
 			content,
+			content_html,
 			author_id,
-			created_at
+			created_at,
+			topic_id,
+			score
 		FROM posts
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	var post entity.Post
 	err := r.db.GetContext(ctx, &post, query, id)
@@ -91,10 +240,13 @@ func (r *postRepository) GetPostByID(ctx context.Context, id int64) (*entity.Pos
 	return &post, nil
 }
 
+// DeletePost переносит пост в корзину. Комментарии остаются на месте
+// и возвращаются вместе с постом при восстановлении.
 func (r *postRepository) DeletePost(ctx context.Context, id, authorID int64, role string) error {
 	query := `
-		DELETE FROM posts 
-		WHERE id = $1 
+		UPDATE posts
+		SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
 		AND (author_id = $2 OR $3 = 'admin')`
 
 	result, err := r.db.ExecContext(ctx, query, id, authorID, role)
@@ -114,26 +266,57 @@ func (r *postRepository) DeletePost(ctx context.Context, id, authorID int64, rol
 	return nil
 }
 
-func (r *postRepository) UpdatePost(ctx context.Context, id, authorID int64, role, title, content string) (*entity.Post, error) {
+// UpdatePost меняет пост и тем же запросом сохраняет новую ревизию
+// с authorID в качестве редактора и заменяет теги на tags.
+func (r *postRepository) UpdatePost(ctx context.Context, id, authorID int64, role, title, content, contentHTML string, tags []string) (*entity.Post, error) {
 	query := `
-		UPDATE posts
-		SET title = $1, content = $2
-		WHERE id = $3 AND (author_id = $4 OR $5 = 'admin')
-		RETURNING id, title, content, author_id, created_at`
-
-	var post entity.Post
-	err := r.db.QueryRowContext(ctx, query,
+		WITH updated AS (
+			UPDATE posts
+			SET title = $1, content = $2, content_html = $6, revision = revision + 1
+			WHERE id = $3 AND deleted_at IS NULL AND (author_id = $4 OR $5 = 'admin')
+			RETURNING id, title, content, content_html, author_id, created_at, topic_id, score, revision
+		), saved AS (
+			INSERT INTO post_revisions (post_id, revision, title, content, editor_id)
+			SELECT id, revision, title, content, $4 FROM updated
+		)`
+	args := []interface{}{
 		title,
 		content,
 		id,
 		authorID,
 		role,
-	).Scan(
+		contentHTML,
+	}
+	if tags != nil {
+		// Пустой список снимает все теги.
+		query += `, tagged AS (
+			INSERT INTO tags (name)
+			SELECT name FROM unnest($7::text[]) AS name WHERE EXISTS (SELECT 1 FROM updated)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		), removed AS (
+			DELETE FROM post_tags
+			WHERE post_id IN (SELECT id FROM updated) AND tag_id NOT IN (SELECT id FROM tagged)
+		), linked AS (
+			INSERT INTO post_tags (post_id, tag_id)
+			SELECT updated.id, tagged.id FROM updated, tagged
+			ON CONFLICT DO NOTHING
+		)`
+		args = append(args, pq.Array(tags))
+	}
+	query += `
+		SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM updated`
+
+	var post entity.Post
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 		&post.ID,
 		&post.Title,
 		&post.Content,
+		&post.ContentHTML,
 		&post.AuthorID,
 		&post.CreatedAt,
+		&post.TopicID,
+		&post.Score,
 	)
 
 	if err != nil {
@@ -145,3 +328,239 @@ func (r *postRepository) UpdatePost(ctx context.Context, id, authorID int64, rol
 
 	return &post, nil
 }
+
+func (r *postRepository) RestorePost(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE posts
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+// GetDeletedPosts возвращает страницу корзины, недавно удаленные первыми.
+func (r *postRepository) GetDeletedPosts(ctx context.Context, limit, offset int) ([]*entity.Post, error) {
+	query := `
+		SELECT 
+			id,
+			title,
+			content,
+			content_html,
+			author_id,
+			created_at,
+			topic_id,
+			deleted_at,
+			deleted_by
+		FROM posts
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1 OFFSET $2`
+
+	posts := []*entity.Post{}
+	if err := r.db.SelectContext(ctx, &posts, query, limit, offset); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (r *postRepository) CountDeletedPosts(ctx context.Context) (int64, error) {
+	var total int64
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM posts WHERE deleted_at IS NOT NULL`); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// PurgeDeletedPosts окончательно удаляет до limit постов, удаленных раньше
+// before. Комментарии и вложения удаляются каскадом; SELECT видит вложения
+// еще до каскада, так что ключи файлов читаются тем же запросом.
+func (r *postRepository) PurgeDeletedPosts(ctx context.Context, before time.Time, limit int) (int64, []string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH purged AS (
+			DELETE FROM posts
+			WHERE id IN (
+				SELECT id FROM posts
+				WHERE deleted_at < $1
+				ORDER BY deleted_at
+				LIMIT $2
+			)
+			RETURNING id
+		)
+		SELECT p.id, COALESCE(a.storage_key, ''), COALESCE(a.thumbnail_key, '')
+		FROM purged p
+		LEFT JOIN attachments a ON a.post_id = p.id`, before, limit)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	purged := map[int64]bool{}
+	var keys []string
+	for rows.Next() {
+		var (
+			id                       int64
+			storageKey, thumbnailKey string
+		)
+		if err := rows.Scan(&id, &storageKey, &thumbnailKey); err != nil {
+			return 0, nil, err
+		}
+		purged[id] = true
+		for _, key := range []string{storageKey, thumbnailKey} {
+			if key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return int64(len(purged)), keys, nil
+}
+
+// GetRevisions возвращает страницу истории поста без текстов, новые первыми.
+func (r *postRepository) GetRevisions(ctx context.Context, postID int64, limit, offset int) ([]*entity.PostRevision, error) {
+	query := `
+		SELECT post_id, revision, title, editor_id, created_at
+		FROM post_revisions
+		WHERE post_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3`
+
+	revisions := []*entity.PostRevision{}
+	if err := r.db.SelectContext(ctx, &revisions, query, postID, limit, offset); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *postRepository) CountRevisions(ctx context.Context, postID int64) (int64, error) {
+	var total int64
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM post_revisions WHERE post_id = $1`, postID); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *postRepository) GetRevision(ctx context.Context, postID int64, revision int) (*entity.PostRevision, error) {
+	query := `
+		SELECT post_id, revision, title, content, editor_id, created_at
+		FROM post_revisions
+		WHERE post_id = $1 AND revision = $2`
+
+	var rev entity.PostRevision
+	if err := r.db.GetContext(ctx, &rev, query, postID, revision); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RollbackPost возвращает посту текст ревизии revision, contentHTML —
+// этот текст, уже отрендеренный. История не переписывается: откат
+// сохраняется как новая ревизия от editorID.
+func (r *postRepository) RollbackPost(ctx context.Context, postID int64, revision int, editorID int64, contentHTML string) (*entity.Post, error) {
+	query := `
+		WITH target AS (
+			SELECT title, content FROM post_revisions
+			WHERE post_id = $1 AND revision = $2
+		), updated AS (
+			UPDATE posts p
+			SET title = target.title, content = target.content, content_html = $4, revision = p.revision + 1
+			FROM target
+			WHERE p.id = $1 AND p.deleted_at IS NULL
+			RETURNING p.id, p.title, p.content, p.content_html, p.author_id, p.created_at, p.topic_id, p.score, p.revision
+		), saved AS (
+			INSERT INTO post_revisions (post_id, revision, title, content, editor_id)
+			SELECT id, revision, title, content, $3 FROM updated
+		)
+		SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM updated`
+
+	var post entity.Post
+	if err := r.db.GetContext(ctx, &post, query, postID, revision, editorID, contentHTML); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+// VotePost ставит голос userID за пост (0 — снимает) и возвращает новый счет.
+func (r *postRepository) VotePost(ctx context.Context, postID, userID int64, value int) (int, error) {
+	return castVote(ctx, r.db, postVotes, postID, userID, value)
+}
+
+func (r *postRepository) GetPostVotes(ctx context.Context, userID int64, postIDs []int64) (map[int64]int, error) {
+	return userVotes(ctx, r.db, postVotes, userID, postIDs)
+}
+
+// TogglePostReaction ставит или снимает реакцию userID на пост; true — поставлена.
+func (r *postRepository) TogglePostReaction(ctx context.Context, postID, userID int64, emoji string) (bool, error) {
+	return toggleReaction(ctx, r.db, postReactions, postID, userID, emoji)
+}
+
+func (r *postRepository) GetPostReactions(ctx context.Context, viewerID int64, postIDs []int64) (map[int64][]entity.Reaction, error) {
+	return itemReactions(ctx, r.db, postReactions, viewerID, postIDs)
+}
+
+// GetPostTags возвращает теги постов postIDs по алфавиту. Постов без тегов
+// в ответе нет.
+func (r *postRepository) GetPostTags(ctx context.Context, postIDs []int64) (map[int64][]string, error) {
+	tags := make(map[int64][]string)
+	if len(postIDs) == 0 {
+		return tags, nil
+	}
+
+	var rows []struct {
+		PostID int64  `db:"post_id"`
+		Name   string `db:"name"`
+	}
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT pt.post_id, t.name
+		FROM post_tags pt
+		JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.post_id = ANY($1)
+		ORDER BY pt.post_id, t.name`, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		tags[row.PostID] = append(tags[row.PostID], row.Name)
+	}
+	return tags, nil
+}
+
+// GetPostAttachments возвращает вложения постов postIDs в порядке
+// загрузки. Постов без вложений в ответе нет.
+func (r *postRepository) GetPostAttachments(ctx context.Context, postIDs []int64) (map[int64][]entity.Attachment, error) {
+	attachments := make(map[int64][]entity.Attachment)
+	if len(postIDs) == 0 {
+		return attachments, nil
+	}
+
+	var rows []entity.Attachment
+	err := r.db.SelectContext(ctx, &rows, `
+		SELECT `+attachmentColumns+`
+		FROM attachments a
+		WHERE a.post_id = ANY($1)
+		ORDER BY a.post_id, a.id`, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		attachments[row.PostID] = append(attachments[row.PostID], row)
+	}
+	return attachments, nil
+}
@@ -36,14 +36,15 @@ func TestCreatePost(t *testing.T) {
 		{
 			name: "Success",
 			post: &entity.Post{
-				Title:     "Test Post",
-				Content:   "Test Content",
-				AuthorID:  1,
-				CreatedAt: now,
+				Title:       "Test Post",
+				Content:     "Test Content",
+				ContentHTML: "<p>Test Content</p>\n",
+				AuthorID:    1,
+				CreatedAt:   now,
 			},
 			mock: func() {
-				mock.ExpectQuery(`INSERT INTO posts`).
-					WithArgs("Test Post", "Test Content", int64(1), now).
+				mock.ExpectQuery(`INSERT INTO posts .+ INSERT INTO post_revisions .+ SELECT id, 1, title, content, author_id, created_at FROM created`).
+					WithArgs("Test Post", "Test Content", "<p>Test Content</p>\n", int64(1), now, nil).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 			},
 			want: 1,
@@ -58,7 +59,7 @@ func TestCreatePost(t *testing.T) {
 			},
 			mock: func() {
 				mock.ExpectQuery(`INSERT INTO posts`).
-					WithArgs("", "", int64(1), now).
+					WithArgs("", "", "", int64(1), now, nil).
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: true,
@@ -93,19 +94,25 @@ func TestGetPosts(t *testing.T) {
 
 	now := time.Now()
 
+	cursor := &entity.PostCursor{CreatedAt: now, ID: 5}
+
 	tests := []struct {
 		name    string
+		query   entity.PostListQuery
 		mock    func()
 		want    []*entity.Post
 		wantErr bool
 	}{
 		{
-			name: "Success",
+			name:  "Success",
+			query: entity.PostListQuery{Limit: 10, Offset: 20},
 			mock: func() {
 				rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
 					AddRow(1, "Post 1", "Content 1", 1, now).
 					AddRow(2, "Post 2", "Content 2", 2, now)
-				mock.ExpectQuery(`SELECT`).WillReturnRows(rows)
+				mock.ExpectQuery(`ORDER BY created_at DESC, id DESC\s+LIMIT \$1 OFFSET \$2`).
+					WithArgs(10, 20).
+					WillReturnRows(rows)
 			},
 			want: []*entity.Post{
 				{
@@ -124,6 +131,48 @@ func TestGetPosts(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "After cursor",
+			query: entity.PostListQuery{Cursor: cursor, Limit: 3},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
+					AddRow(4, "Post 4", "Content 4", 1, now)
+				mock.ExpectQuery(`WHERE deleted_at IS NULL AND \(created_at, id\) < \(\$1, \$2\)`).
+					WithArgs(now, int64(5), 3).
+					WillReturnRows(rows)
+			},
+			want: []*entity.Post{
+				{ID: 4, Title: "Post 4", Content: "Content 4", AuthorID: 1, CreatedAt: now},
+			},
+		},
+		{
+			name:  "Top within window",
+			query: entity.PostListQuery{Sort: entity.PostSortTop, Since: &now, Limit: 10},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "score"}).
+					AddRow(7, "Post 7", "Content 7", 1, now, 12)
+				mock.ExpectQuery(`WHERE deleted_at IS NULL AND created_at >= \$1\s+ORDER BY score DESC, id DESC\s+LIMIT \$2 OFFSET \$3`).
+					WithArgs(now, 10, 0).
+					WillReturnRows(rows)
+			},
+			want: []*entity.Post{
+				{ID: 7, Title: "Post 7", Content: "Content 7", AuthorID: 1, CreatedAt: now, Score: 12},
+			},
+		},
+		{
+			name:  "Hot",
+			query: entity.PostListQuery{Sort: entity.PostSortHot, Limit: 10},
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "score"}).
+					AddRow(8, "Post 8", "Content 8", 1, now, 3)
+				mock.ExpectQuery(`ORDER BY hot_rank\(score, created_at\) DESC, id DESC\s+LIMIT \$1 OFFSET \$2`).
+					WithArgs(10, 0).
+					WillReturnRows(rows)
+			},
+			want: []*entity.Post{
+				{ID: 8, Title: "Post 8", Content: "Content 8", AuthorID: 1, CreatedAt: now, Score: 3},
+			},
+		},
 		{
 			name: "No Posts",
 			mock: func() {
@@ -145,7 +194,7 @@ func TestGetPosts(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.GetPosts(context.Background())
+			got, err := repo.GetPosts(context.Background(), tt.query)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetPosts() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -155,6 +204,24 @@ func TestGetPosts(t *testing.T) {
 	}
 }
 
+func TestCountPosts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM posts`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	total, err := repo.CountPosts(context.Background(), entity.PostListQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeletePost(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -179,7 +246,7 @@ func TestDeletePost(t *testing.T) {
 			authorID: 1,
 			role:     "user",
 			mock: func() {
-				mock.ExpectExec(`DELETE FROM posts`).
+				mock.ExpectExec(`UPDATE posts SET deleted_at = CURRENT_TIMESTAMP, deleted_by = \$2`).
 					WithArgs(int64(1), int64(1), "user").
 					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
@@ -190,7 +257,7 @@ func TestDeletePost(t *testing.T) {
 			authorID: 2,
 			role:     "admin",
 			mock: func() {
-				mock.ExpectExec(`DELETE FROM posts`).
+				mock.ExpectExec(`UPDATE posts`).
 					WithArgs(int64(1), int64(2), "admin").
 					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
@@ -201,7 +268,7 @@ func TestDeletePost(t *testing.T) {
 			authorID: 1,
 			role:     "user",
 			mock: func() {
-				mock.ExpectExec(`DELETE FROM posts`).
+				mock.ExpectExec(`UPDATE posts`).
 					WithArgs(int64(2), int64(1), "user").
 					WillReturnResult(sqlmock.NewResult(0, 0))
 			},
@@ -311,6 +378,7 @@ func TestUpdatePost(t *testing.T) {
 		role     string
 		title    string
 		content  string
+		html     string
 		mock     func()
 		want     *entity.Post
 		wantErr  error
@@ -322,19 +390,22 @@ func TestUpdatePost(t *testing.T) {
 			role:     "user",
 			title:    "Updated Title",
 			content:  "Updated Content",
+			html:     "<p>Updated Content</p>\n",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
-					AddRow(1, "Updated Title", "Updated Content", 1, now)
-				mock.ExpectQuery(`UPDATE posts`).
-					WithArgs("Updated Title", "Updated Content", int64(1), int64(1), "user").
+				rows := sqlmock.NewRows([]string{"id", "title", "content", "content_html", "author_id", "created_at", "topic_id", "score"}).
+					AddRow(1, "Updated Title", "Updated Content", "<p>Updated Content</p>\n", 1, now, nil, 3)
+				mock.ExpectQuery(`UPDATE posts SET title = \$1, content = \$2, content_html = \$6, revision = revision \+ 1 .+ INSERT INTO post_revisions`).
+					WithArgs("Updated Title", "Updated Content", int64(1), int64(1), "user", "<p>Updated Content</p>\n").
 					WillReturnRows(rows)
 			},
 			want: &entity.Post{
-				ID:        1,
-				Title:     "Updated Title",
-				Content:   "Updated Content",
-				AuthorID:  1,
-				CreatedAt: now,
+				ID:          1,
+				Title:       "Updated Title",
+				Content:     "Updated Content",
+				ContentHTML: "<p>Updated Content</p>\n",
+				AuthorID:    1,
+				CreatedAt:   now,
+				Score:       3,
 			},
 		},
 		{
@@ -344,19 +415,22 @@ func TestUpdatePost(t *testing.T) {
 			role:     "admin",
 			title:    "Updated Title",
 			content:  "Updated Content",
+			html:     "<p>Updated Content</p>\n",
 			mock: func() {
-				rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
-					AddRow(1, "Updated Title", "Updated Content", 1, now)
+				rows := sqlmock.NewRows([]string{"id", "title", "content", "content_html", "author_id", "created_at", "topic_id", "score"}).
+					AddRow(1, "Updated Title", "Updated Content", "<p>Updated Content</p>\n", 1, now, nil, 3)
 				mock.ExpectQuery(`UPDATE posts`).
-					WithArgs("Updated Title", "Updated Content", int64(1), int64(2), "admin").
+					WithArgs("Updated Title", "Updated Content", int64(1), int64(2), "admin", "<p>Updated Content</p>\n").
 					WillReturnRows(rows)
 			},
 			want: &entity.Post{
-				ID:        1,
-				Title:     "Updated Title",
-				Content:   "Updated Content",
-				AuthorID:  1,
-				CreatedAt: now,
+				ID:          1,
+				Title:       "Updated Title",
+				Content:     "Updated Content",
+				ContentHTML: "<p>Updated Content</p>\n",
+				AuthorID:    1,
+				CreatedAt:   now,
+				Score:       3,
 			},
 		},
 		{
@@ -366,9 +440,10 @@ func TestUpdatePost(t *testing.T) {
 			role:     "user",
 			title:    "Updated Title",
 			content:  "Updated Content",
+			html:     "<p>Updated Content</p>\n",
 			mock: func() {
 				mock.ExpectQuery(`UPDATE posts`).
-					WithArgs("Updated Title", "Updated Content", int64(2), int64(1), "user").
+					WithArgs("Updated Title", "Updated Content", int64(2), int64(1), "user", "<p>Updated Content</p>\n").
 					WillReturnError(sql.ErrNoRows)
 			},
 			wantErr: ErrPostNotFound,
@@ -380,9 +455,10 @@ func TestUpdatePost(t *testing.T) {
 			role:     "user",
 			title:    "Updated Title",
 			content:  "Updated Content",
+			html:     "<p>Updated Content</p>\n",
 			mock: func() {
 				mock.ExpectQuery(`UPDATE posts`).
-					WithArgs("Updated Title", "Updated Content", int64(3), int64(1), "user").
+					WithArgs("Updated Title", "Updated Content", int64(3), int64(1), "user", "<p>Updated Content</p>\n").
 					WillReturnError(sql.ErrConnDone)
 			},
 			wantErr: sql.ErrConnDone,
@@ -393,7 +469,7 @@ func TestUpdatePost(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 
-			got, err := repo.UpdatePost(context.Background(), tt.postID, tt.authorID, tt.role, tt.title, tt.content)
+			got, err := repo.UpdatePost(context.Background(), tt.postID, tt.authorID, tt.role, tt.title, tt.content, tt.html, nil)
 			if err != tt.wantErr {
 				if !errors.Is(err, tt.wantErr) {
 					t.Errorf("UpdatePost() error = %v, wantErr %v", err, tt.wantErr)
@@ -406,3 +482,190 @@ func TestUpdatePost(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPostsByTopicID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "topic_id"}).
+		AddRow(1, "Post 1", "Content 1", 1, now, 5)
+	mock.ExpectQuery(`SELECT (.+) FROM posts WHERE topic_id = \$1`).WithArgs(int64(5)).WillReturnRows(rows)
+
+	posts, err := repo.GetPostsByTopicID(context.Background(), 5)
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 1) && assert.NotNil(t, posts[0].TopicID) {
+		assert.Equal(t, int64(5), *posts[0].TopicID)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestorePost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(`UPDATE posts SET deleted_at = NULL, deleted_by = NULL WHERE id = \$1 AND deleted_at IS NOT NULL`).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.RestorePost(context.Background(), 1))
+
+	mock.ExpectExec(`UPDATE posts`).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.RestorePost(context.Background(), 2), ErrPostNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeletedPosts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "topic_id", "deleted_at", "deleted_by"}).
+		AddRow(3, "Post 3", "Content 3", 1, now, nil, now, 2)
+	mock.ExpectQuery(`FROM posts WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+		WithArgs(20, 0).
+		WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM posts WHERE deleted_at IS NOT NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	posts, err := repo.GetDeletedPosts(context.Background(), 20, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 1) && assert.NotNil(t, posts[0].DeletedBy) {
+		assert.Equal(t, int64(2), *posts[0].DeletedBy)
+		assert.Equal(t, now, *posts[0].DeletedAt)
+	}
+
+	total, err := repo.CountDeletedPosts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeDeletedPosts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	before := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(`WITH purged AS \(\s*DELETE FROM posts\s+WHERE id IN \(\s*SELECT id FROM posts\s+WHERE deleted_at < \$1\s+ORDER BY deleted_at\s+LIMIT \$2\s*\)\s+RETURNING id\s*\)\s+SELECT p.id, COALESCE\(a.storage_key, ''\), COALESCE\(a.thumbnail_key, ''\)\s+FROM purged p\s+LEFT JOIN attachments a ON a.post_id = p.id`).
+		WithArgs(before, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "storage_key", "thumbnail_key"}).
+			AddRow(1, "posts/1/a", "posts/1/a_thumb").
+			AddRow(1, "posts/1/b", "").
+			AddRow(2, "", ""))
+
+	purged, keys, err := repo.PurgeDeletedPosts(context.Background(), before, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	assert.Equal(t, []string{"posts/1/a", "posts/1/a_thumb", "posts/1/b"}, keys)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRevisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT post_id, revision, title, editor_id, created_at FROM post_revisions WHERE post_id = \$1 ORDER BY revision DESC LIMIT \$2 OFFSET \$3`).
+		WithArgs(int64(1), 20, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "revision", "title", "editor_id", "created_at"}).
+			AddRow(1, 2, "Edited", 9, now).
+			AddRow(1, 1, "Original", 4, now))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM post_revisions WHERE post_id = \$1`).
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	revisions, err := repo.GetRevisions(context.Background(), 1, 20, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, revisions, 2) {
+		assert.Equal(t, 2, revisions[0].Revision)
+		assert.Equal(t, int64(9), revisions[0].EditorID)
+	}
+
+	total, err := repo.CountRevisions(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRevision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM post_revisions WHERE post_id = \$1 AND revision = \$2`).
+		WithArgs(int64(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "revision", "title", "content", "editor_id", "created_at"}).
+			AddRow(1, 1, "Original", "Body", 4, now))
+
+	rev, err := repo.GetRevision(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.PostRevision{PostID: 1, Revision: 1, Title: "Original", Content: "Body", EditorID: 4, CreatedAt: now}, rev)
+
+	mock.ExpectQuery(`FROM post_revisions`).
+		WithArgs(int64(1), 7).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetRevision(context.Background(), 1, 7)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRollbackPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectQuery(`WITH target AS .+ WHERE post_id = \$1 AND revision = \$2 .+ UPDATE posts p .+ WHERE p.id = \$1 AND p.deleted_at IS NULL .+ INSERT INTO post_revisions .+ SELECT id, revision, title, content, \$3 FROM updated`).
+		WithArgs(int64(1), 1, int64(9), "<p>Body</p>\n").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "content_html", "author_id", "created_at", "topic_id"}).
+			AddRow(1, "Original", "Body", "<p>Body</p>\n", 4, now, nil))
+
+	post, err := repo.RollbackPost(context.Background(), 1, 1, 9, "<p>Body</p>\n")
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.Post{ID: 1, Title: "Original", Content: "Body", ContentHTML: "<p>Body</p>\n", AuthorID: 4, CreatedAt: now}, post)
+
+	mock.ExpectQuery(`WITH target AS`).
+		WithArgs(int64(2), 1, int64(9), "").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.RollbackPost(context.Background(), 2, 1, 9, "")
+	assert.ErrorIs(t, err, ErrPostNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// reactionTarget описывает, на что ставят реакции: таблицу записей и
+// таблицу реакций, где itemColumn ссылается на запись.
+type reactionTarget struct {
+	items       string
+	reactions   string
+	itemColumn  string
+	errNotFound error
+}
+
+var (
+	postReactions    = reactionTarget{items: "posts", reactions: "post_reactions", itemColumn: "post_id", errNotFound: ErrPostNotFound}
+	commentReactions = reactionTarget{items: "comments", reactions: "comment_reactions", itemColumn: "comment_id", errNotFound: ErrCommentNotFound}
+)
+
+// toggleReaction снимает реакцию emoji пользователя userID, если она уже
+// стоит, и ставит ее в противном случае. Оба шага — один запрос, поэтому
+// повторное нажатие не оставит двух реакций. Возвращает true, если реакция
+// поставлена.
+func toggleReaction(ctx context.Context, db *sqlx.DB, t reactionTarget, itemID, userID int64, emoji string) (bool, error) {
+	var found, added bool
+	err := db.QueryRowxContext(ctx, fmt.Sprintf(`
+		WITH item AS (
+			SELECT id FROM %[1]s WHERE id = $1 AND deleted_at IS NULL
+		), removed AS (
+			DELETE FROM %[2]s
+			WHERE %[3]s IN (SELECT id FROM item) AND user_id = $2 AND emoji = $3
+			RETURNING 1
+		), added AS (
+			INSERT INTO %[2]s (%[3]s, user_id, emoji)
+			SELECT id, $2, $3 FROM item WHERE NOT EXISTS (SELECT 1 FROM removed)
+			ON CONFLICT DO NOTHING
+			RETURNING 1
+		)
+		SELECT EXISTS (SELECT 1 FROM item), EXISTS (SELECT 1 FROM added)`,
+		t.items, t.reactions, t.itemColumn), itemID, userID, emoji).Scan(&found, &added)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, t.errNotFound
+	}
+	return added, nil
+}
+
+// itemReactions возвращает реакции на записи itemIDs с отметкой реакций
+// viewerID. Эмодзи идут в порядке первой реакции. Записей без реакций
+// в ответе нет.
+func itemReactions(ctx context.Context, db *sqlx.DB, t reactionTarget, viewerID int64, itemIDs []int64) (map[int64][]entity.Reaction, error) {
+	reactions := make(map[int64][]entity.Reaction)
+	if len(itemIDs) == 0 {
+		return reactions, nil
+	}
+
+	var rows []struct {
+		ItemID int64 `db:"item_id"`
+		entity.Reaction
+	}
+	err := db.SelectContext(ctx, &rows, fmt.Sprintf(`
+		SELECT %[1]s AS item_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = $2) AS mine
+		FROM %[2]s
+		WHERE %[1]s = ANY($1)
+		GROUP BY %[1]s, emoji
+		ORDER BY %[1]s, MIN(created_at), emoji`,
+		t.itemColumn, t.reactions), pq.Array(itemIDs), viewerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		reactions[row.ItemID] = append(reactions[row.ItemID], row.Reaction)
+	}
+	return reactions, nil
+}
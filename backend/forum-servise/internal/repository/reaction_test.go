@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTogglePostReaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		found     bool
+		added     bool
+		err       error
+		wantAdded bool
+		wantErr   error
+	}{
+		{name: "Added", found: true, added: true, wantAdded: true},
+		{name: "Removed", found: true, added: false, wantAdded: false},
+		{name: "Post not found", found: false, wantErr: ErrPostNotFound},
+		{name: "Database error", err: sql.ErrConnDone, wantErr: sql.ErrConnDone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+			expect := mock.ExpectQuery(`SELECT id FROM posts WHERE id = \$1 AND deleted_at IS NULL.+DELETE FROM post_reactions.+INSERT INTO post_reactions \(post_id, user_id, emoji\)`).
+				WithArgs(int64(1), int64(7), "👍")
+			if tt.err != nil {
+				expect.WillReturnError(tt.err)
+			} else {
+				expect.WillReturnRows(sqlmock.NewRows([]string{"found", "added"}).AddRow(tt.found, tt.added))
+			}
+
+			added, err := repo.TogglePostReaction(context.Background(), 1, 7, "👍")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantAdded, added)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestToggleCommentReaction_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`SELECT id FROM comments WHERE id = \$1 AND deleted_at IS NULL.+comment_reactions`).
+		WithArgs(int64(3), int64(7), "🎉").
+		WillReturnRows(sqlmock.NewRows([]string{"found", "added"}).AddRow(false, false))
+
+	_, err = repo.ToggleCommentReaction(context.Background(), 3, 7, "🎉")
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostReactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`SELECT post_id AS item_id, emoji, COUNT\(\*\) AS count, BOOL_OR\(user_id = \$2\) AS mine\s+FROM post_reactions\s+WHERE post_id = ANY\(\$1\)\s+GROUP BY post_id, emoji`).
+		WithArgs(sqlmock.AnyArg(), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "emoji", "count", "mine"}).
+			AddRow(1, "👍", 3, true).
+			AddRow(1, "🎉", 1, false).
+			AddRow(2, "😂", 2, false))
+
+	reactions, err := repo.GetPostReactions(context.Background(), 7, []int64{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64][]entity.Reaction{
+		1: {{Emoji: "👍", Count: 3, Mine: true}, {Emoji: "🎉", Count: 1}},
+		2: {{Emoji: "😂", Count: 2}},
+	}, reactions)
+
+	// Пустой список обходится без запроса.
+	reactions, err = repo.GetPostReactions(context.Background(), 7, nil)
+	require.NoError(t, err)
+	assert.Empty(t, reactions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+// Границы совпадений в ts_headline. Это управляющие символы, которых нет
+// в обычном тексте: usecase экранирует сниппет и только потом меняет их на <mark>.
+const (
+	HighlightStart = "\x01"
+	HighlightStop  = "\x02"
+)
+
+const headlineOptions = "StartSel=" + HighlightStart + ", StopSel=" + HighlightStop +
+	", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// searchHits объединяет совпадения в постах и комментариях, кроме лежащих
+// в корзине. Фильтры подставляются в общий WHERE, $1 всегда текст запроса.
+const searchHits = `
+		WITH q AS (SELECT websearch_to_tsquery('simple', $1) AS query),
+		hits AS (
+			SELECT 'post' AS type, p.id, p.id AS post_id, p.title, p.content,
+				p.author_id, p.created_at, p.topic_id,
+				ts_rank(p.search_vector, q.query) AS rank
+			FROM posts p, q
+			WHERE p.search_vector @@ q.query AND p.deleted_at IS NULL
+			UNION ALL
+			SELECT 'comment' AS type, c.id, c.post_id, p.title, c.content,
+				c.author_id, c.created_at, p.topic_id,
+				ts_rank(c.search_vector, q.query) AS rank
+			FROM comments c
+			JOIN posts p ON p.id = c.post_id, q
+			WHERE c.search_vector @@ q.query
+				AND c.deleted_at IS NULL AND p.deleted_at IS NULL
+		)`
+
+type SearchRepository interface {
+	Search(ctx context.Context, query entity.SearchQuery) ([]*entity.SearchResult, error)
+	CountSearch(ctx context.Context, query entity.SearchQuery) (int64, error)
+}
+
+type searchRepository struct {
+	db *sqlx.DB
+}
+
+func NewSearchRepository(db *sqlx.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// Search возвращает страницу результатов по убыванию релевантности.
+// Сниппеты строятся только для попавших на страницу строк.
+func (r *searchRepository) Search(ctx context.Context, query entity.SearchQuery) ([]*entity.SearchResult, error) {
+	where, args := searchFilters(query)
+	args = append(args, query.Limit, query.Offset, headlineOptions)
+	n := len(args)
+
+	sqlQuery := searchHits + fmt.Sprintf(`,
+		page AS (
+			SELECT * FROM hits
+			%s
+			ORDER BY rank DESC, created_at DESC, type, id DESC
+			LIMIT $%d OFFSET $%d
+		)
+		SELECT type, id, post_id, title, author_id, created_at, rank,
+			ts_headline('simple', content, (SELECT query FROM q), $%d) AS snippet
+		FROM page
+		ORDER BY rank DESC, created_at DESC, type, id DESC`, where, n-2, n-1, n)
+
+	results := []*entity.SearchResult{}
+	if err := r.db.SelectContext(ctx, &results, sqlQuery, args...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *searchRepository) CountSearch(ctx context.Context, query entity.SearchQuery) (int64, error) {
+	where, args := searchFilters(query)
+
+	var total int64
+	err := r.db.GetContext(ctx, &total, searchHits+`
+		SELECT COUNT(*) FROM hits `+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func searchFilters(query entity.SearchQuery) (string, []interface{}) {
+	args := []interface{}{query.Text}
+	var conds []string
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if query.AuthorID != nil {
+		add("author_id = $%d", *query.AuthorID)
+	}
+	if query.TopicID != nil {
+		add("topic_id = $%d", *query.TopicID)
+	}
+	if query.From != nil {
+		add("created_at >= $%d", *query.From)
+	}
+	if query.To != nil {
+		add("created_at < $%d", *query.To)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
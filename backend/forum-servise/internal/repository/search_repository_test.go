@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewSearchRepository(sqlx.NewDb(db, "sqlmock"))
+
+	now := time.Now()
+	authorID, topicID := int64(7), int64(3)
+	query := entity.SearchQuery{
+		Text:     "golang generics",
+		AuthorID: &authorID,
+		TopicID:  &topicID,
+		From:     &now,
+		Limit:    20,
+		Offset:   40,
+	}
+
+	rows := sqlmock.NewRows([]string{"type", "id", "post_id", "title", "author_id", "created_at", "rank", "snippet"}).
+		AddRow("post", 1, 1, "Generics in Go", 7, now, 0.6, "\x01Generics\x02 in Go").
+		AddRow("comment", 5, 2, "Other post", 7, now, 0.1, "about \x01golang\x02")
+	mock.ExpectQuery(`WHERE author_id = \$2 AND topic_id = \$3 AND created_at >= \$4\s+ORDER BY rank DESC, created_at DESC, type, id DESC\s+LIMIT \$5 OFFSET \$6`).
+		WithArgs("golang generics", authorID, topicID, now, 20, 40, headlineOptions).
+		WillReturnRows(rows)
+
+	results, err := repo.Search(context.Background(), query)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, entity.SearchResultPost, results[0].Type)
+	assert.Equal(t, int64(2), results[1].PostID)
+	assert.Equal(t, "about \x01golang\x02", results[1].Snippet)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountSearch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewSearchRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM hits\s*$`).
+		WithArgs("golang").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	total, err := repo.CountSearch(context.Background(), entity.SearchQuery{Text: "golang"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
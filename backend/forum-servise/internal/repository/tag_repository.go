@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+// TagRepository — справочник тегов. Теги постам ставит PostRepository;
+// здесь — списки с числом постов и админские переименование и слияние.
+type TagRepository interface {
+	ListTags(ctx context.Context, limit, offset int) ([]*entity.Tag, error)
+	CountTags(ctx context.Context) (int64, error)
+	SuggestTags(ctx context.Context, prefix string, limit int) ([]*entity.Tag, error)
+	RenameTag(ctx context.Context, name, newName string) error
+	MergeTags(ctx context.Context, source, target string) error
+}
+
+type tagRepository struct {
+	db *sqlx.DB
+}
+
+func NewTagRepository(db *sqlx.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// usedTags — теги хотя бы одного живого поста с числом таких постов.
+const usedTags = `
+	SELECT t.name, COUNT(*) AS post_count
+	FROM tags t
+	JOIN post_tags pt ON pt.tag_id = t.id
+	JOIN posts p ON p.id = pt.post_id AND p.deleted_at IS NULL`
+
+// ListTags возвращает страницу используемых тегов, популярные первыми.
+func (r *tagRepository) ListTags(ctx context.Context, limit, offset int) ([]*entity.Tag, error) {
+	query := usedTags + `
+		GROUP BY t.name
+		ORDER BY post_count DESC, t.name
+		LIMIT $1 OFFSET $2`
+
+	tags := []*entity.Tag{}
+	if err := r.db.SelectContext(ctx, &tags, query, limit, offset); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) CountTags(ctx context.Context) (int64, error) {
+	query := `
+		SELECT COUNT(DISTINCT pt.tag_id)
+		FROM post_tags pt
+		JOIN posts p ON p.id = pt.post_id AND p.deleted_at IS NULL`
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, query); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// likeEscaper экранирует спецсимволы LIKE; "_" допустим в именах тегов.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SuggestTags возвращает до limit используемых тегов, начинающихся
+// с prefix, популярные первыми.
+func (r *tagRepository) SuggestTags(ctx context.Context, prefix string, limit int) ([]*entity.Tag, error) {
+	query := usedTags + `
+		WHERE t.name LIKE $1
+		GROUP BY t.name
+		ORDER BY post_count DESC, t.name
+		LIMIT $2`
+
+	tags := []*entity.Tag{}
+	if err := r.db.SelectContext(ctx, &tags, query, likeEscaper.Replace(prefix)+"%", limit); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// RenameTag переименовывает тег; занятое имя — ErrTagExists, такие теги
+// нужно сливать через MergeTags.
+func (r *tagRepository) RenameTag(ctx context.Context, name, newName string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE tags SET name = $2 WHERE name = $1`, name, newName)
+	if isPgError(err, pgUniqueViolation) {
+		return ErrTagExists
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// MergeTags переносит посты тега source на target и удаляет source.
+// Посты, у которых уже были оба тега, остаются с одним target.
+func (r *tagRepository) MergeTags(ctx context.Context, source, target string) error {
+	query := `
+		WITH source AS (
+			SELECT id FROM tags WHERE name = $1
+		), target AS (
+			SELECT id FROM tags WHERE name = $2
+		), moved AS (
+			INSERT INTO post_tags (post_id, tag_id)
+			SELECT pt.post_id, target.id FROM post_tags pt, source, target
+			WHERE pt.tag_id = source.id
+			ON CONFLICT DO NOTHING
+		), deleted AS (
+			DELETE FROM tags
+			WHERE id IN (SELECT id FROM source) AND EXISTS (SELECT 1 FROM target)
+		)
+		SELECT EXISTS (SELECT 1 FROM source) AND EXISTS (SELECT 1 FROM target)`
+
+	var found bool
+	if err := r.db.QueryRowContext(ctx, query, source, target).Scan(&found); err != nil {
+		return err
+	}
+	if !found {
+		return ErrTagNotFound
+	}
+	return nil
+}
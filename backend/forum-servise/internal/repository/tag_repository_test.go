@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTagRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`SELECT t.name, COUNT\(\*\) AS post_count FROM tags t.+deleted_at IS NULL\s+GROUP BY t.name\s+ORDER BY post_count DESC, t.name\s+LIMIT \$1 OFFSET \$2`).
+		WithArgs(2, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "post_count"}).AddRow("go", 5).AddRow("grpc", 2))
+	mock.ExpectQuery(`SELECT COUNT\(DISTINCT pt.tag_id\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	tags, err := repo.ListTags(context.Background(), 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Tag{{Name: "go", PostCount: 5}, {Name: "grpc", PostCount: 2}}, tags)
+
+	total, err := repo.CountTags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSuggestTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		pattern string
+	}{
+		{name: "Plain prefix", prefix: "go", pattern: "go%"},
+		{name: "Underscore is escaped", prefix: "c_", pattern: `c\_%`},
+		{name: "Empty prefix", prefix: "", pattern: "%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewTagRepository(sqlx.NewDb(db, "sqlmock"))
+			mock.ExpectQuery(`WHERE t.name LIKE \$1\s+GROUP BY t.name\s+ORDER BY post_count DESC, t.name\s+LIMIT \$2`).
+				WithArgs(tt.pattern, 10).
+				WillReturnRows(sqlmock.NewRows([]string{"name", "post_count"}).AddRow("go", 5))
+
+			tags, err := repo.SuggestTags(context.Background(), tt.prefix, 10)
+			require.NoError(t, err)
+			assert.Len(t, tags, 1)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(expect *sqlmock.ExpectedExec)
+		wantErr error
+	}{
+		{
+			name: "Success",
+			mock: func(expect *sqlmock.ExpectedExec) {
+				expect.WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name: "Not found",
+			mock: func(expect *sqlmock.ExpectedExec) {
+				expect.WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrTagNotFound,
+		},
+		{
+			name: "Name taken",
+			mock: func(expect *sqlmock.ExpectedExec) {
+				expect.WillReturnError(&pq.Error{Code: pgUniqueViolation})
+			},
+			wantErr: ErrTagExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewTagRepository(sqlx.NewDb(db, "sqlmock"))
+			tt.mock(mock.ExpectExec(`UPDATE tags SET name = \$2 WHERE name = \$1`).WithArgs("golang", "go"))
+
+			err = repo.RenameTag(context.Background(), "golang", "go")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	for _, found := range []bool{true, false} {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+
+		repo := NewTagRepository(sqlx.NewDb(db, "sqlmock"))
+		mock.ExpectQuery(`INSERT INTO post_tags \(post_id, tag_id\).+ON CONFLICT DO NOTHING.+DELETE FROM tags`).
+			WithArgs("golang", "go").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(found))
+
+		err = repo.MergeTags(context.Background(), "golang", "go")
+		if found {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrTagNotFound)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
+
+func TestCreatePost_WithTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	post := &entity.Post{Title: "T", Content: "C", AuthorID: 1, Tags: []string{"go", "grpc"}}
+	mock.ExpectQuery(`INSERT INTO tags \(name\) SELECT unnest\(\$7::text\[\]\).+INSERT INTO post_tags \(post_id, tag_id\)\s+SELECT created.id, tagged.id FROM created, tagged`).
+		WithArgs("T", "C", "", int64(1), sqlmock.AnyArg(), nil, pq.Array([]string{"go", "grpc"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+	id, err := repo.CreatePost(context.Background(), post)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPosts_TagFilter(t *testing.T) {
+	tags := pq.Array([]string{"go", "grpc"})
+	tests := []struct {
+		name      string
+		mode      string
+		condition string
+		args      []driver.Value
+	}{
+		{
+			name:      "All tags",
+			mode:      entity.TagMatchAll,
+			condition: `WHERE t.name = ANY\(\$1\) GROUP BY pt.post_id HAVING COUNT\(\*\) = \$2\)`,
+			args:      []driver.Value{tags, 2},
+		},
+		{
+			name:      "Any tag",
+			mode:      entity.TagMatchAny,
+			condition: `WHERE t.name = ANY\(\$1\)\)`,
+			args:      []driver.Value{tags},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+			query := entity.PostListQuery{Tags: []string{"go", "grpc"}, TagMode: tt.mode, Limit: 10}
+
+			mock.ExpectQuery(`FROM posts WHERE deleted_at IS NULL AND id IN \(.+` + tt.condition).
+				WithArgs(append(tt.args, 10, 0)...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
+					AddRow(1, "T", "C", int64(1), time.Now()))
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM posts WHERE deleted_at IS NULL AND id IN \(.+` + tt.condition).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+			posts, err := repo.GetPosts(context.Background(), query)
+			require.NoError(t, err)
+			assert.Len(t, posts, 1)
+
+			total, err := repo.CountPosts(context.Background(), query)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdatePostTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Tags replaced in the same query", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE posts.+INSERT INTO post_revisions.+DELETE FROM post_tags.+INSERT INTO post_tags \(post_id, tag_id\).+SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM updated`).
+			WithArgs("T", "C", int64(1), int64(2), "user", "<p>C</p>\n", pq.Array([]string{"go"})).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "content_html", "author_id", "created_at", "topic_id", "score"}).
+				AddRow(1, "T", "C", "<p>C</p>\n", 2, now, nil, 0))
+
+		post, err := repo.UpdatePost(context.Background(), 1, 2, "user", "T", "C", "<p>C</p>\n", []string{"go"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), post.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Post not found", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE posts.+DELETE FROM post_tags`).
+			WithArgs("T", "C", int64(9), int64(2), "user", "<p>C</p>\n", pq.Array([]string{})).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UpdatePost(context.Background(), 9, 2, "user", "T", "C", "<p>C</p>\n", []string{})
+		assert.ErrorIs(t, err, ErrPostNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetPostTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`SELECT pt.post_id, t.name\s+FROM post_tags pt\s+JOIN tags t ON t.id = pt.tag_id\s+WHERE pt.post_id = ANY\(\$1\)`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "name"}).AddRow(1, "go").AddRow(1, "grpc").AddRow(3, "sql"))
+
+	tags, err := repo.GetPostTags(context.Background(), []int64{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64][]string{1: {"go", "grpc"}, 3: {"sql"}}, tags)
+
+	// Пустой список обходится без запроса.
+	tags, err = repo.GetPostTags(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
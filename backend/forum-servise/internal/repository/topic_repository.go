@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrTopicNotFound = errors.New("topic not found")
+
+type TopicRepository interface {
+	CreateTopic(ctx context.Context, topic *entity.Topic) (int64, error)
+	GetTopicByID(ctx context.Context, id int64) (*entity.Topic, error)
+	GetTopicsByCategoryID(ctx context.Context, categoryID int64) ([]entity.Topic, error)
+	UpdateTopic(ctx context.Context, topic *entity.Topic) error
+	DeleteTopic(ctx context.Context, id int64) error
+}
+
+type topicRepository struct {
+	db *sqlx.DB
+}
+
+func NewTopicRepository(db *sqlx.DB) TopicRepository {
+	return &topicRepository{db: db}
+}
+
+func (r *topicRepository) CreateTopic(ctx context.Context, topic *entity.Topic) (int64, error) {
+	query := `
+		INSERT INTO topics (category_id, title, user_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING id`
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, query,
+		topic.CategoryID,
+		topic.Title,
+		topic.UserID,
+		topic.CreatedAt,
+	).Scan(&id)
+	if isPgError(err, pgForeignKeyViolation) {
+		return 0, ErrCategoryNotFound
+	}
+	return id, err
+}
+
+func (r *topicRepository) GetTopicByID(ctx context.Context, id int64) (*entity.Topic, error) {
+	query := `
+		SELECT id, category_id, title, user_id, created_at, updated_at
+		FROM topics
+		WHERE id = $1`
+
+	var topic entity.Topic
+	err := r.db.GetContext(ctx, &topic, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTopicNotFound
+		}
+		return nil, err
+	}
+	return &topic, nil
+}
+
+func (r *topicRepository) GetTopicsByCategoryID(ctx context.Context, categoryID int64) ([]entity.Topic, error) {
+	query := `
+		SELECT id, category_id, title, user_id, created_at, updated_at
+		FROM topics
+		WHERE category_id = $1
+		ORDER BY created_at DESC`
+
+	topics := []entity.Topic{}
+	if err := r.db.SelectContext(ctx, &topics, query, categoryID); err != nil {
+		return nil, err
+	}
+	return topics, nil
+}
+
+func (r *topicRepository) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
+	query := `
+		UPDATE topics
+		SET title = $1, updated_at = $2
+		WHERE id = $3
+		RETURNING category_id, user_id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		topic.Title,
+		topic.UpdatedAt,
+		topic.ID,
+	).Scan(&topic.CategoryID, &topic.UserID, &topic.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTopicNotFound
+	}
+	return err
+}
+
+func (r *topicRepository) DeleteTopic(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM topics WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTopicNotFound
+	}
+	return nil
+}
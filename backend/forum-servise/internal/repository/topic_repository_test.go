@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTopic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTopicRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO topics`).
+		WithArgs(int64(1), "Горутины", int64(7), now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	id, err := repo.CreateTopic(context.Background(), &entity.Topic{
+		CategoryID: 1,
+		Title:      "Горутины",
+		UserID:     7,
+		CreatedAt:  now,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTopicByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTopicRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "category_id", "title", "user_id", "created_at", "updated_at"}).
+			AddRow(3, 1, "Горутины", 7, now, now)
+		mock.ExpectQuery(`SELECT id, category_id, title, user_id, created_at`).WithArgs(int64(3)).WillReturnRows(rows)
+
+		got, err := repo.GetTopicByID(context.Background(), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.Topic{ID: 3, CategoryID: 1, Title: "Горутины", UserID: 7, CreatedAt: now, UpdatedAt: now}, got)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, category_id, title, user_id, created_at`).WithArgs(int64(4)).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetTopicByID(context.Background(), 4)
+		assert.ErrorIs(t, err, ErrTopicNotFound)
+	})
+}
+
+func TestGetTopicsByCategoryID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTopicRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "category_id", "title", "user_id", "created_at", "updated_at"}).
+			AddRow(4, 1, "Каналы", 7, now, now).
+			AddRow(3, 1, "Горутины", 7, now.Add(-time.Hour), now.Add(-time.Hour))
+		mock.ExpectQuery(`SELECT (.+) FROM topics WHERE category_id = \$1`).WithArgs(int64(1)).WillReturnRows(rows)
+
+		got, err := repo.GetTopicsByCategoryID(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, "Каналы", got[0].Title)
+	})
+
+	t.Run("No Topics", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "category_id", "title", "user_id", "created_at", "updated_at"})
+		mock.ExpectQuery(`SELECT (.+) FROM topics WHERE category_id = \$1`).WithArgs(int64(2)).WillReturnRows(rows)
+
+		got, err := repo.GetTopicsByCategoryID(context.Background(), 2)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTopic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTopicRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		topic := &entity.Topic{ID: 3, Title: "Горутины и каналы", UpdatedAt: now}
+		mock.ExpectQuery(`UPDATE topics`).
+			WithArgs("Горутины и каналы", now, int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"category_id", "user_id", "created_at"}).AddRow(1, 7, now))
+
+		assert.NoError(t, repo.UpdateTopic(context.Background(), topic))
+		assert.Equal(t, int64(1), topic.CategoryID)
+		assert.Equal(t, int64(7), topic.UserID)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE topics`).
+			WithArgs("Тема", now, int64(9)).
+			WillReturnError(sql.ErrNoRows)
+
+		err := repo.UpdateTopic(context.Background(), &entity.Topic{ID: 9, Title: "Тема", UpdatedAt: now})
+		assert.ErrorIs(t, err, ErrTopicNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTopic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewTopicRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(`DELETE FROM topics`).WithArgs(int64(3)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.DeleteTopic(context.Background(), 3))
+
+	mock.ExpectExec(`DELETE FROM topics`).WithArgs(int64(4)).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.DeleteTopic(context.Background(), 4), ErrTopicNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
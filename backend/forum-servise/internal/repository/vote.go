@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// voteTarget описывает, за что голосуют: таблицу записей со счетом
+// и таблицу голосов, где itemColumn ссылается на запись.
+type voteTarget struct {
+	items       string
+	votes       string
+	itemColumn  string
+	errNotFound error
+}
+
+var (
+	postVotes    = voteTarget{items: "posts", votes: "post_votes", itemColumn: "post_id", errNotFound: ErrPostNotFound}
+	commentVotes = voteTarget{items: "comments", votes: "comment_votes", itemColumn: "comment_id", errNotFound: ErrCommentNotFound}
+)
+
+// castVote ставит голос value (0 — снять голос) и в той же транзакции
+// сдвигает счет записи на разницу со старым голосом. Запись блокируется
+// первой, поэтому параллельные голоса за нее идут по очереди. Возвращает
+// новый счет.
+func castVote(ctx context.Context, db *sqlx.DB, t voteTarget, itemID, userID int64, value int) (score int, err error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = tx.GetContext(ctx, &score, fmt.Sprintf(
+		`SELECT score FROM %s WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, t.items), itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, t.errNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var old int
+	err = tx.GetContext(ctx, &old, fmt.Sprintf(
+		`SELECT value FROM %s WHERE %s = $1 AND user_id = $2`, t.votes, t.itemColumn), itemID, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	if value != old {
+		if value == 0 {
+			_, err = tx.ExecContext(ctx, fmt.Sprintf(
+				`DELETE FROM %s WHERE %s = $1 AND user_id = $2`, t.votes, t.itemColumn), itemID, userID)
+		} else {
+			_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+				INSERT INTO %[1]s (%[2]s, user_id, value) VALUES ($1, $2, $3)
+				ON CONFLICT (%[2]s, user_id) DO UPDATE SET value = EXCLUDED.value, created_at = CURRENT_TIMESTAMP`,
+				t.votes, t.itemColumn), itemID, userID, value)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		err = tx.GetContext(ctx, &score, fmt.Sprintf(
+			`UPDATE %s SET score = score + $2 WHERE id = $1 RETURNING score`, t.items), itemID, value-old)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return score, tx.Commit()
+}
+
+// userVotes возвращает голоса userID за записи itemIDs. Записей без голоса
+// в ответе нет.
+func userVotes(ctx context.Context, db *sqlx.DB, t voteTarget, userID int64, itemIDs []int64) (map[int64]int, error) {
+	votes := make(map[int64]int)
+	if userID == 0 || len(itemIDs) == 0 {
+		return votes, nil
+	}
+
+	var rows []struct {
+		ItemID int64 `db:"item_id"`
+		Value  int   `db:"value"`
+	}
+	err := db.SelectContext(ctx, &rows, fmt.Sprintf(
+		`SELECT %s AS item_id, value FROM %s WHERE user_id = $1 AND %s = ANY($2)`,
+		t.itemColumn, t.votes, t.itemColumn), userID, pq.Array(itemIDs))
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		votes[row.ItemID] = row.Value
+	}
+	return votes, nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVotePost(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     int
+		mock      func(mock sqlmock.Sqlmock)
+		wantScore int
+		wantErr   error
+	}{
+		{
+			name:  "New upvote",
+			value: 1,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT score FROM posts WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(4))
+				mock.ExpectQuery(`SELECT value FROM post_votes WHERE post_id = \$1 AND user_id = \$2`).
+					WithArgs(int64(1), int64(7)).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(`INSERT INTO post_votes \(post_id, user_id, value\) VALUES \(\$1, \$2, \$3\)\s+ON CONFLICT \(post_id, user_id\) DO UPDATE`).
+					WithArgs(int64(1), int64(7), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE posts SET score = score \+ \$2 WHERE id = \$1 RETURNING score`).
+					WithArgs(int64(1), 1).
+					WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(5))
+				mock.ExpectCommit()
+			},
+			wantScore: 5,
+		},
+		{
+			name:  "Change upvote to downvote",
+			value: -1,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT score FROM posts`).
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(5))
+				mock.ExpectQuery(`SELECT value FROM post_votes`).
+					WithArgs(int64(1), int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+				mock.ExpectExec(`INSERT INTO post_votes`).
+					WithArgs(int64(1), int64(7), -1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE posts SET score = score \+ \$2`).
+					WithArgs(int64(1), -2).
+					WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(3))
+				mock.ExpectCommit()
+			},
+			wantScore: 3,
+		},
+		{
+			name:  "Clear vote",
+			value: 0,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT score FROM posts`).
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(3))
+				mock.ExpectQuery(`SELECT value FROM post_votes`).
+					WithArgs(int64(1), int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(-1))
+				mock.ExpectExec(`DELETE FROM post_votes WHERE post_id = \$1 AND user_id = \$2`).
+					WithArgs(int64(1), int64(7)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`UPDATE posts SET score = score \+ \$2`).
+					WithArgs(int64(1), 1).
+					WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(4))
+				mock.ExpectCommit()
+			},
+			wantScore: 4,
+		},
+		{
+			name:  "Same vote again",
+			value: 1,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT score FROM posts`).
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(5))
+				mock.ExpectQuery(`SELECT value FROM post_votes`).
+					WithArgs(int64(1), int64(7)).
+					WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+			wantScore: 5,
+		},
+		{
+			name:  "Post not found",
+			value: 1,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT score FROM posts`).
+					WithArgs(int64(1)).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantErr: ErrPostNotFound,
+		},
+		{
+			name:  "Rollback on error",
+			value: 1,
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT score FROM posts`).
+					WithArgs(int64(1)).
+					WillReturnRows(sqlmock.NewRows([]string{"score"}).AddRow(0))
+				mock.ExpectQuery(`SELECT value FROM post_votes`).
+					WithArgs(int64(1), int64(7)).
+					WillReturnError(sql.ErrNoRows)
+				mock.ExpectExec(`INSERT INTO post_votes`).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantErr: sql.ErrConnDone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+			tt.mock(mock)
+
+			score, err := repo.VotePost(context.Background(), 1, 7, tt.value)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantScore, score)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestVoteComment_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewCommentRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT score FROM comments WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(int64(3)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = repo.VoteComment(context.Background(), 3, 7, 1)
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostVotes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPostRepository(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectQuery(`SELECT post_id AS item_id, value FROM post_votes WHERE user_id = \$1 AND post_id = ANY\(\$2\)`).
+		WithArgs(int64(7), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "value"}).AddRow(1, 1).AddRow(3, -1))
+
+	votes, err := repo.GetPostVotes(context.Background(), 7, []int64{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]int{1: 1, 3: -1}, votes)
+
+	// Аноним и пустой список обходятся без запроса.
+	votes, err = repo.GetPostVotes(context.Background(), 0, []int64{1})
+	require.NoError(t, err)
+	assert.Empty(t, votes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
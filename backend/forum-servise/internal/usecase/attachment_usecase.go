@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/storage"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/thumbnail"
+)
+
+const (
+	DefaultUserAttachmentSize  = 5 << 20
+	DefaultAdminAttachmentSize = 20 << 20
+	MaxPostAttachments         = 10
+	maxFileNameLength          = 255
+)
+
+var (
+	ErrEmptyAttachment       = errors.New("file is empty")
+	ErrAttachmentTooLarge    = errors.New("file is too large")
+	ErrUnsupportedAttachment = errors.New("file type is not allowed: images, text, pdf, zip and gzip are accepted")
+	ErrTooManyAttachments    = errors.New("a post can have at most 10 attachments")
+)
+
+// allowedAttachmentTypes — типы, определенные по содержимому файла,
+// которые можно прикладывать: скриншоты, логи и архивы с ними.
+var allowedAttachmentTypes = map[string]bool{
+	"image/png":          true,
+	"image/jpeg":         true,
+	"image/gif":          true,
+	"image/webp":         true,
+	"text/plain":         true,
+	"application/pdf":    true,
+	"application/zip":    true,
+	"application/x-gzip": true,
+}
+
+// AttachmentLimits — наибольший размер файла в байтах по роли. Роли без
+// своего лимита получают лимит роли "user".
+type AttachmentLimits map[string]int64
+
+func DefaultAttachmentLimits() AttachmentLimits {
+	return AttachmentLimits{
+		"user":           DefaultUserAttachmentSize,
+		authmw.RoleAdmin: DefaultAdminAttachmentSize,
+	}
+}
+
+// AttachmentUsecase загружает, отдает и удаляет вложения постов.
+// Прикладывать и удалять файлы может автор поста или админ.
+type AttachmentUsecase struct {
+	repo    repository.AttachmentRepository
+	posts   repository.PostRepository
+	storage storage.Storage
+	limits  AttachmentLimits
+	logger  *logger.Logger
+}
+
+func NewAttachmentUsecase(
+	repo repository.AttachmentRepository,
+	posts repository.PostRepository,
+	store storage.Storage,
+	limits AttachmentLimits,
+	logger *logger.Logger,
+) *AttachmentUsecase {
+	if limits == nil {
+		limits = DefaultAttachmentLimits()
+	}
+	return &AttachmentUsecase{repo: repo, posts: posts, storage: store, limits: limits, logger: logger}
+}
+
+// MaxSize — наибольший размер файла, который может загрузить role.
+func (uc *AttachmentUsecase) MaxSize(role string) int64 {
+	if limit, ok := uc.limits[role]; ok {
+		return limit
+	}
+	return uc.limits["user"]
+}
+
+// MaxUploadSize — наибольший лимит среди всех ролей; больше этого тело
+// запроса не читается вовсе.
+func (uc *AttachmentUsecase) MaxUploadSize() int64 {
+	var largest int64
+	for _, limit := range uc.limits {
+		largest = max(largest, limit)
+	}
+	return largest
+}
+
+// Upload прикладывает файл к посту postID. Тип определяется по первым
+// байтам файла, заголовку клиента не верим. Для картинок сохраняется
+// превью; если его не удалось сделать, файл все равно загружается.
+func (uc *AttachmentUsecase) Upload(ctx context.Context, user *authmw.Principal, postID int64, fileName string, file io.Reader) (*entity.Attachment, error) {
+	if err := uc.checkPostAccess(ctx, user, postID); err != nil {
+		return nil, err
+	}
+	// Ранняя проверка, чтобы не загружать файл зря; окончательно лимит
+	// проверяет CreateAttachment.
+	count, err := uc.repo.CountPostAttachments(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxPostAttachments {
+		return nil, ErrTooManyAttachments
+	}
+
+	limit := uc.MaxSize(user.Role)
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptyAttachment
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrAttachmentTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !allowedAttachmentTypes[mediaType] {
+		return nil, ErrUnsupportedAttachment
+	}
+
+	key, err := attachmentKey(postID)
+	if err != nil {
+		return nil, err
+	}
+	attachment := &entity.Attachment{
+		PostID:      postID,
+		UploaderID:  user.UserID,
+		FileName:    cleanFileName(fileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  key,
+	}
+	if err := uc.storage.Put(ctx, key, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(mediaType, "image/") {
+		attachment.ThumbnailKey = uc.putThumbnail(ctx, key, data)
+	}
+
+	if err := uc.repo.CreateAttachment(ctx, attachment, MaxPostAttachments); err != nil {
+		uc.removeFiles(ctx, attachment)
+		if errors.Is(err, repository.ErrAttachmentLimit) {
+			return nil, ErrTooManyAttachments
+		}
+		return nil, err
+	}
+	setAttachmentURLs(attachment)
+	return attachment, nil
+}
+
+// AttachmentFile — открытый файл вложения или его превью.
+type AttachmentFile struct {
+	io.ReadCloser
+	Attachment  *entity.Attachment
+	ContentType string
+	Size        int64 // -1, если неизвестен
+}
+
+// Open открывает файл вложения или, с thumbnail, его превью. Вызывающий
+// закрывает возвращенный файл.
+func (uc *AttachmentUsecase) Open(ctx context.Context, id int64, thumbnail bool) (*AttachmentFile, error) {
+	attachment, err := uc.repo.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	file := &AttachmentFile{Attachment: attachment, ContentType: attachment.ContentType, Size: attachment.Size}
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, repository.ErrAttachmentNotFound
+		}
+		key = attachment.ThumbnailKey
+		// Превью JPEG остается JPEG, остальные кодируются в PNG.
+		file.ContentType, file.Size = "image/png", -1
+		if attachment.ContentType == "image/jpeg" {
+			file.ContentType = "image/jpeg"
+		}
+	}
+
+	body, err := uc.storage.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, repository.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	file.ReadCloser = body
+	return file, nil
+}
+
+// Delete удаляет вложение attachmentID поста postID вместе с файлами.
+func (uc *AttachmentUsecase) Delete(ctx context.Context, user *authmw.Principal, postID, attachmentID int64) error {
+	attachment, err := uc.repo.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		return err
+	}
+	if attachment.PostID != postID {
+		return repository.ErrAttachmentNotFound
+	}
+	if err := uc.checkPostAccess(ctx, user, postID); err != nil {
+		return err
+	}
+
+	if err := uc.repo.DeleteAttachment(ctx, attachmentID); err != nil {
+		return err
+	}
+	uc.removeFiles(ctx, attachment)
+	return nil
+}
+
+func (uc *AttachmentUsecase) checkPostAccess(ctx context.Context, user *authmw.Principal, postID int64) error {
+	post, err := uc.posts.GetPostByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	if post.AuthorID != user.UserID && !user.IsAdmin() {
+		return repository.ErrPermissionDenied
+	}
+	return nil
+}
+
+// putThumbnail сохраняет превью картинки и возвращает его ключ; пустая
+// строка — превью нет.
+func (uc *AttachmentUsecase) putThumbnail(ctx context.Context, key string, data []byte) string {
+	thumb, contentType, err := thumbnail.Make(data, thumbnail.DefaultMaxSide)
+	if err != nil {
+		return ""
+	}
+	thumbKey := key + "_thumb"
+	if err := uc.storage.Put(ctx, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), contentType); err != nil {
+		uc.logger.Errorf("attachment thumbnail upload failed: %v", err)
+		return ""
+	}
+	return thumbKey
+}
+
+// removeFiles удаляет файлы вложения из хранилища. Ошибка только
+// логируется: запись о вложении уже удалена или не создана.
+func (uc *AttachmentUsecase) removeFiles(ctx context.Context, attachment *entity.Attachment) {
+	uc.DeleteFiles(ctx, attachment.StorageKey, attachment.ThumbnailKey)
+}
+
+// DeleteFiles удаляет из хранилища файлы с ключами keys, пустые ключи
+// пропускаются. Ошибки только логируются. На nil ничего не делает.
+func (uc *AttachmentUsecase) DeleteFiles(ctx context.Context, keys ...string) {
+	if uc == nil {
+		return
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := uc.storage.Delete(ctx, key); err != nil {
+			uc.logger.Errorf("attachment file %s delete failed: %v", key, err)
+		}
+	}
+}
+
+// attachmentKey — ключ нового файла поста: случайное имя исключает
+// перезапись и подбор чужих файлов по имени.
+func attachmentKey(postID int64) (string, error) {
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("posts/%d/%s", postID, hex.EncodeToString(name)), nil
+}
+
+// cleanFileName оставляет от имени файла клиента только последний
+// элемент пути без управляющих символов.
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(name, ""))
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > maxFileNameLength {
+		name = string(runes[:maxFileNameLength])
+	}
+	if name == "" || name == "." || name == "/" {
+		return "file"
+	}
+	return name
+}
+
+func setAttachmentURLs(attachment *entity.Attachment) {
+	attachment.URL = fmt.Sprintf("/api/v1/attachments/%d", attachment.ID)
+	attachment.ThumbnailURL = ""
+	if attachment.ThumbnailKey != "" {
+		attachment.ThumbnailURL = attachment.URL + "/thumbnail"
+	}
+}
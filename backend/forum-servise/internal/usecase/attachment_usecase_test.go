@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStorage — хранилище в памяти.
+type memStorage struct {
+	objects map[string][]byte
+}
+
+func (s *memStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(body)
+	s.objects[key] = data
+	return err
+}
+
+func (s *memStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memStorage) Delete(ctx context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func newAttachmentUsecase(repo *MockAttachmentRepository, limits AttachmentLimits) (*AttachmentUsecase, *memStorage) {
+	posts := &MockPostRepository{
+		GetPostByIDFunc: func(ctx context.Context, id int64) (*entity.Post, error) {
+			if id != 1 {
+				return nil, repository.ErrPostNotFound
+			}
+			return &entity.Post{ID: 1, AuthorID: 42}, nil
+		},
+	}
+	store := &memStorage{objects: map[string][]byte{}}
+	return NewAttachmentUsecase(repo, posts, store, limits, NewMockLogger()), store
+}
+
+func TestAttachmentUsecase_Upload(t *testing.T) {
+	author := &authmw.Principal{UserID: 42, Role: "user"}
+	var created *entity.Attachment
+	repo := &MockAttachmentRepository{
+		CreateAttachmentFunc: func(ctx context.Context, attachment *entity.Attachment, limit int) error {
+			attachment.ID = 7
+			created = attachment
+			return nil
+		},
+	}
+	uc, store := newAttachmentUsecase(repo, nil)
+
+	t.Run("Image gets a thumbnail", func(t *testing.T) {
+		data := pngBytes(t, 640, 480)
+		got, err := uc.Upload(context.Background(), author, 1, `C:\Users\me\shot.png`, bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Same(t, created, got)
+		assert.Equal(t, "shot.png", got.FileName)
+		assert.Equal(t, "image/png", got.ContentType)
+		assert.Equal(t, int64(len(data)), got.Size)
+		assert.Regexp(t, `^posts/1/[0-9a-f]{32}$`, got.StorageKey)
+		assert.Equal(t, got.StorageKey+"_thumb", got.ThumbnailKey)
+		assert.Equal(t, data, store.objects[got.StorageKey])
+		assert.NotEmpty(t, store.objects[got.ThumbnailKey])
+		assert.Equal(t, "/api/v1/attachments/7", got.URL)
+		assert.Equal(t, "/api/v1/attachments/7/thumbnail", got.ThumbnailURL)
+	})
+
+	t.Run("Log file has no thumbnail", func(t *testing.T) {
+		got, err := uc.Upload(context.Background(), author, 1, "app.log", strings.NewReader("panic: boom\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain; charset=utf-8", got.ContentType)
+		assert.Empty(t, got.ThumbnailKey)
+		assert.Empty(t, got.ThumbnailURL)
+	})
+
+	t.Run("Type is detected from content", func(t *testing.T) {
+		_, err := uc.Upload(context.Background(), author, 1, "shot.png", strings.NewReader("<html><script>alert(1)</script>"))
+		assert.ErrorIs(t, err, ErrUnsupportedAttachment)
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		_, err := uc.Upload(context.Background(), author, 1, "empty.txt", strings.NewReader(""))
+		assert.ErrorIs(t, err, ErrEmptyAttachment)
+	})
+
+	t.Run("Someone else's post", func(t *testing.T) {
+		_, err := uc.Upload(context.Background(), &authmw.Principal{UserID: 7, Role: "user"}, 1, "a.txt", strings.NewReader("x"))
+		assert.ErrorIs(t, err, repository.ErrPermissionDenied)
+	})
+
+	t.Run("Admin may attach to any post", func(t *testing.T) {
+		_, err := uc.Upload(context.Background(), &authmw.Principal{UserID: 7, Role: authmw.RoleAdmin}, 1, "a.txt", strings.NewReader("x"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Post not found", func(t *testing.T) {
+		_, err := uc.Upload(context.Background(), author, 2, "a.txt", strings.NewReader("x"))
+		assert.ErrorIs(t, err, repository.ErrPostNotFound)
+	})
+}
+
+func TestAttachmentUsecase_UploadLimits(t *testing.T) {
+	repo := &MockAttachmentRepository{}
+	uc, store := newAttachmentUsecase(repo, AttachmentLimits{"user": 10, authmw.RoleAdmin: 20})
+	data := strings.Repeat("x", 15)
+
+	_, err := uc.Upload(context.Background(), &authmw.Principal{UserID: 42, Role: "user"}, 1, "a.txt", strings.NewReader(data))
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+	_, err = uc.Upload(context.Background(), &authmw.Principal{UserID: 42, Role: "moderator"}, 1, "a.txt", strings.NewReader(data))
+	assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+	_, err = uc.Upload(context.Background(), &authmw.Principal{UserID: 42, Role: authmw.RoleAdmin}, 1, "a.txt", strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), uc.MaxUploadSize())
+	assert.Len(t, store.objects, 1)
+
+	repo.CountPostAttachmentsFunc = func(ctx context.Context, postID int64) (int, error) {
+		return MaxPostAttachments, nil
+	}
+	_, err = uc.Upload(context.Background(), &authmw.Principal{UserID: 42, Role: "user"}, 1, "a.txt", strings.NewReader("x"))
+	assert.ErrorIs(t, err, ErrTooManyAttachments)
+}
+
+func TestAttachmentUsecase_UploadCleansUpOnError(t *testing.T) {
+	dbErr := errors.New("db down")
+	repo := &MockAttachmentRepository{
+		CreateAttachmentFunc: func(ctx context.Context, attachment *entity.Attachment, limit int) error {
+			return dbErr
+		},
+	}
+	uc, store := newAttachmentUsecase(repo, nil)
+
+	_, err := uc.Upload(context.Background(), &authmw.Principal{UserID: 42}, 1, "shot.png", bytes.NewReader(pngBytes(t, 10, 10)))
+	assert.ErrorIs(t, err, dbErr)
+	assert.Empty(t, store.objects)
+}
+
+func TestAttachmentUsecase_UploadLimitRace(t *testing.T) {
+	// Параллельная загрузка успела занять последнее место после подсчета.
+	repo := &MockAttachmentRepository{
+		CreateAttachmentFunc: func(ctx context.Context, attachment *entity.Attachment, limit int) error {
+			assert.Equal(t, MaxPostAttachments, limit)
+			return repository.ErrAttachmentLimit
+		},
+	}
+	uc, store := newAttachmentUsecase(repo, nil)
+
+	_, err := uc.Upload(context.Background(), &authmw.Principal{UserID: 42}, 1, "a.txt", strings.NewReader("x"))
+	assert.ErrorIs(t, err, ErrTooManyAttachments)
+	assert.Empty(t, store.objects)
+}
+
+func TestAttachmentUsecase_OpenAndDelete(t *testing.T) {
+	stored := &entity.Attachment{ID: 7, PostID: 1, FileName: "shot.jpg", ContentType: "image/jpeg", Size: 4,
+		StorageKey: "posts/1/abc", ThumbnailKey: "posts/1/abc_thumb"}
+	deleted := false
+	repo := &MockAttachmentRepository{
+		GetAttachmentFunc: func(ctx context.Context, id int64) (*entity.Attachment, error) {
+			if id != 7 || deleted {
+				return nil, repository.ErrAttachmentNotFound
+			}
+			return stored, nil
+		},
+		DeleteAttachmentFunc: func(ctx context.Context, id int64) error {
+			deleted = true
+			return nil
+		},
+	}
+	uc, store := newAttachmentUsecase(repo, nil)
+	store.objects["posts/1/abc"] = []byte("full")
+	store.objects["posts/1/abc_thumb"] = []byte("th")
+
+	file, err := uc.Open(context.Background(), 7, false)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(file)
+	file.Close()
+	assert.Equal(t, "full", string(data))
+	assert.Equal(t, "image/jpeg", file.ContentType)
+	assert.Equal(t, int64(4), file.Size)
+
+	file, err = uc.Open(context.Background(), 7, true)
+	require.NoError(t, err)
+	data, _ = io.ReadAll(file)
+	file.Close()
+	assert.Equal(t, "th", string(data))
+	assert.Equal(t, int64(-1), file.Size)
+
+	user := &authmw.Principal{UserID: 7, Role: "user"}
+	assert.ErrorIs(t, uc.Delete(context.Background(), user, 1, 7), repository.ErrPermissionDenied)
+	assert.ErrorIs(t, uc.Delete(context.Background(), &authmw.Principal{UserID: 42}, 2, 7), repository.ErrAttachmentNotFound)
+	require.NoError(t, uc.Delete(context.Background(), &authmw.Principal{UserID: 42}, 1, 7))
+	assert.Empty(t, store.objects)
+
+	_, err = uc.Open(context.Background(), 7, false)
+	assert.ErrorIs(t, err, repository.ErrAttachmentNotFound)
+}
+
+func TestAttachmentUsecase_OpenWithoutThumbnail(t *testing.T) {
+	repo := &MockAttachmentRepository{
+		GetAttachmentFunc: func(ctx context.Context, id int64) (*entity.Attachment, error) {
+			return &entity.Attachment{ID: id, PostID: 1, ContentType: "text/plain", StorageKey: "posts/1/log"}, nil
+		},
+	}
+	uc, _ := newAttachmentUsecase(repo, nil)
+
+	_, err := uc.Open(context.Background(), 3, true)
+	assert.ErrorIs(t, err, repository.ErrAttachmentNotFound)
+	// Запись есть, а файла в хранилище нет.
+	_, err = uc.Open(context.Background(), 3, false)
+	assert.ErrorIs(t, err, repository.ErrAttachmentNotFound)
+}
+
+func TestCleanFileName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "report.pdf", want: "report.pdf"},
+		{in: "../../etc/passwd", want: "passwd"},
+		{in: `C:\temp\log.txt`, want: "log.txt"},
+		{in: "bad\x00name\n.txt", want: "badname.txt"},
+		{in: "", want: "file"},
+		{in: "dir/", want: "dir"},
+		{in: "/", want: "file"},
+		{in: strings.Repeat("я", 300), want: strings.Repeat("я", 255)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, cleanFileName(tt.in))
+		})
+	}
+}
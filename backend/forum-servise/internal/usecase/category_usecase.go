@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+)
+
+var ErrEmptyCategoryName = errors.New("category name is required")
+
+type CategoryUsecase struct {
+	categoryRepo repository.CategoryRepository
+}
+
+type CategoryUsecaseInterface interface {
+	CreateCategory(ctx context.Context, name, description string) (*entity.Category, error)
+	GetCategory(ctx context.Context, id int64) (*entity.Category, error)
+	GetCategories(ctx context.Context) ([]entity.Category, error)
+	UpdateCategory(ctx context.Context, id int64, name, description string) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, id int64) error
+}
+
+func NewCategoryUsecase(categoryRepo repository.CategoryRepository) *CategoryUsecase {
+	return &CategoryUsecase{categoryRepo: categoryRepo}
+}
+
+func (uc *CategoryUsecase) CreateCategory(ctx context.Context, name, description string) (*entity.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyCategoryName
+	}
+
+	category := &entity.Category{
+		Name:        name,
+		Description: description,
+		CreatedAt:   time.Now(),
+	}
+
+	id, err := uc.categoryRepo.CreateCategory(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+
+	category.ID = id
+	return category, nil
+}
+
+func (uc *CategoryUsecase) GetCategory(ctx context.Context, id int64) (*entity.Category, error) {
+	return uc.categoryRepo.GetCategoryByID(ctx, id)
+}
+
+func (uc *CategoryUsecase) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	return uc.categoryRepo.GetCategories(ctx)
+}
+
+func (uc *CategoryUsecase) UpdateCategory(ctx context.Context, id int64, name, description string) (*entity.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrEmptyCategoryName
+	}
+
+	category := &entity.Category{
+		ID:          id,
+		Name:        name,
+		Description: description,
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := uc.categoryRepo.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (uc *CategoryUsecase) DeleteCategory(ctx context.Context, id int64) error {
+	return uc.categoryRepo.DeleteCategory(ctx, id)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+)
+
+var ErrChatRoomAccessDenied = errors.New("access to chat room denied")
+
+// chatBatchSize ограничивает количество сообщений, читаемых за один опрос.
+const chatBatchSize = 100
+
+type ChatUsecase struct {
+	chatRepo   repository.ChatMessageRepository
+	authClient pb.AuthServiceClient
+}
+
+type ChatUsecaseInterface interface {
+	CreateChatMessage(ctx context.Context, roomID, userID int64, content string) (*entity.ChatMessage, error)
+	GetChatMessagesAfter(ctx context.Context, roomID, afterID int64) ([]entity.ChatMessage, error)
+	GetLastChatMessageID(ctx context.Context) (int64, error)
+	// CanAccessRoom — та же проверка, что в chat-servise: публичная комната
+	// открыта всем, закрытая — только участникам.
+	CanAccessRoom(ctx context.Context, roomID, userID int64) error
+}
+
+func NewChatUsecase(
+	chatRepo repository.ChatMessageRepository,
+	authClient pb.AuthServiceClient,
+) *ChatUsecase {
+	return &ChatUsecase{
+		chatRepo:   chatRepo,
+		authClient: authClient,
+	}
+}
+
+func (uc *ChatUsecase) CreateChatMessage(ctx context.Context, roomID, userID int64, content string) (*entity.ChatMessage, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, ErrEmptyMessage
+	}
+	if err := uc.CanAccessRoom(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	userResp, err := uc.authClient.GetUser(ctx, &pb.GetUserRequest{Id: userID})
+	if err != nil || userResp == nil || userResp.User == nil {
+		return nil, errors.New("failed to get user info")
+	}
+
+	msg := &entity.ChatMessage{
+		RoomID:    roomID,
+		UserID:    userID,
+		Username:  userResp.User.Username,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}
+
+	id, err := uc.chatRepo.CreateChatMessage(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.ID = id
+	return msg, nil
+}
+
+func (uc *ChatUsecase) GetChatMessagesAfter(ctx context.Context, roomID, afterID int64) ([]entity.ChatMessage, error) {
+	return uc.chatRepo.GetChatMessagesAfter(ctx, roomID, afterID, chatBatchSize)
+}
+
+func (uc *ChatUsecase) GetLastChatMessageID(ctx context.Context) (int64, error) {
+	return uc.chatRepo.GetLastChatMessageID(ctx)
+}
+
+func (uc *ChatUsecase) CanAccessRoom(ctx context.Context, roomID, userID int64) error {
+	isPrivate, isMember, err := uc.chatRepo.GetChatRoomAccess(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if isPrivate && !isMember {
+		return ErrChatRoomAccessDenied
+	}
+	return nil
+}
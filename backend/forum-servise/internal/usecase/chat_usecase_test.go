@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestChatUsecase_CreateChatMessage(t *testing.T) {
+	var saved []*entity.ChatMessage
+	repo := &MockChatMessageRepository{
+		CreateChatMessageFunc: func(ctx context.Context, msg *entity.ChatMessage) (int64, error) {
+			saved = append(saved, msg)
+			return int64(len(saved)), nil
+		},
+		// Комната 2 закрытая, пользователь 7 в ней состоит; комнаты 3 нет.
+		GetChatRoomAccessFunc: func(ctx context.Context, roomID, userID int64) (bool, bool, error) {
+			switch roomID {
+			case 2:
+				return true, userID == 7, nil
+			case 3:
+				return false, false, repository.ErrChatRoomNotFound
+			}
+			return false, false, nil
+		},
+	}
+	authClient := &MockAuthServiceClient{
+		GetUserFunc: func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
+			return &pb.GetUserResponse{User: &pb.User{Id: in.Id, Username: "bob"}}, nil
+		},
+	}
+	uc := NewChatUsecase(repo, authClient)
+
+	msg, err := uc.CreateChatMessage(context.Background(), 2, 7, "привет")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), msg.RoomID)
+	assert.Equal(t, "bob", msg.Username)
+
+	_, err = uc.CreateChatMessage(context.Background(), 2, 8, "привет")
+	assert.ErrorIs(t, err, ErrChatRoomAccessDenied)
+
+	_, err = uc.CreateChatMessage(context.Background(), 3, 7, "привет")
+	assert.ErrorIs(t, err, repository.ErrChatRoomNotFound)
+
+	_, err = uc.CreateChatMessage(context.Background(), 1, 8, "привет")
+	assert.NoError(t, err)
+
+	_, err = uc.CreateChatMessage(context.Background(), 1, 8, "  ")
+	assert.ErrorIs(t, err, ErrEmptyMessage)
+	assert.Len(t, saved, 2)
+}
+
+func TestChatUsecase_GetChatMessagesAfter(t *testing.T) {
+	repo := &MockChatMessageRepository{
+		GetChatMessagesAfterFunc: func(ctx context.Context, roomID, afterID int64, limit int) ([]entity.ChatMessage, error) {
+			assert.Equal(t, int64(2), roomID)
+			assert.Equal(t, int64(10), afterID)
+			assert.Equal(t, chatBatchSize, limit)
+			return []entity.ChatMessage{{ID: 11, RoomID: 2}}, nil
+		},
+	}
+	uc := NewChatUsecase(repo, &MockAuthServiceClient{})
+
+	messages, err := uc.GetChatMessagesAfter(context.Background(), 2, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.ChatMessage{{ID: 11, RoomID: 2}}, messages)
+}
@@ -4,15 +4,46 @@ import (
 	"context"
 	"errors"
 
+	"backend.com/forum/authmw"
 	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/markdown"
+)
+
+const (
+	DefaultCommentsPageSize = 20
+	// Уровни ответов, раскрываемые в ответе, если клиент не задал depth.
+	DefaultCommentDepth = 3
+	// Максимальная вложенность по умолчанию; на более глубокий ответ
+	// ответить уже нельзя.
+	DefaultMaxCommentDepth = 8
+	// Столько первых ответов показываем под каждым комментарием, остальные
+	// догружаются постранично через GetReplies.
+	inlineRepliesPerComment = 5
+	// DeletedPlaceholder заменяет текст и автора удаленного комментария,
+	// который остался в ветке ради ответов на него.
+	DeletedPlaceholder = "[deleted]"
+)
+
+var (
+	ErrParentNotFound = errors.New("parent comment not found in this post")
+	ErrCommentTooDeep = errors.New("comment thread is too deep")
+	ErrInvalidDepth   = errors.New("depth must not be negative")
 )
 
 type CommentUseCase struct {
 	CommentRepo repository.CommentRepository
 	postRepo    repository.PostRepository
 	AuthClient  pb.AuthServiceClient
+	// MaxDepth — наибольший допустимый уровень вложенности ответа.
+	MaxDepth int
+	// Mentions разбирает упоминания в тексте; nil — упоминания остаются текстом.
+	Mentions *MentionUsecase
+	// Notifications уведомляет о комментариях, ответах и удалении
+	// комментариев администратором; nil — без уведомлений.
+	Notifications *NotificationUsecase
+	users         *userDirectory
 }
 
 func NewCommentUseCase(
@@ -24,56 +55,319 @@ func NewCommentUseCase(
 		CommentRepo: commentRepo,
 		postRepo:    postRepo,
 		AuthClient:  authClient,
+		MaxDepth:    DefaultMaxCommentDepth,
+		users:       newUserDirectory(authClient, DefaultUserCacheTTL),
 	}
 }
 
-func (uc *CommentUseCase) CreateComment(ctx context.Context, comment *entity.Comment) error {
+// CreateComment сохраняет комментарий от имени author. Если задан ParentID,
+// это ответ: родитель должен быть в том же посте и не на последнем уровне.
+func (uc *CommentUseCase) CreateComment(ctx context.Context, author *authmw.Principal, comment *entity.Comment) error {
 
-	_, err := uc.postRepo.GetPostByID(ctx, comment.PostID)
+	post, err := uc.postRepo.GetPostByID(ctx, comment.PostID)
 	if err != nil {
 		return err
 	}
 
-	userResp, err := uc.AuthClient.GetUser(ctx, &pb.GetUserRequest{Id: comment.AuthorID})
-	if err != nil || userResp == nil || userResp.User == nil {
-		return errors.New("failed to get user info")
+	comment.Depth = 0
+	var parent *entity.Comment
+	if comment.ParentID != nil {
+		parent, err = uc.CommentRepo.GetCommentByID(ctx, *comment.ParentID)
+		if errors.Is(err, repository.ErrCommentNotFound) {
+			return ErrParentNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if parent.PostID != comment.PostID || parent.Deleted {
+			return ErrParentNotFound
+		}
+		if parent.Depth >= uc.MaxDepth {
+			return ErrCommentTooDeep
+		}
+		comment.Depth = parent.Depth + 1
+	}
+
+	var mentions []entity.Mention
+	comment.ContentHTML, mentions = uc.Mentions.Render(ctx, comment.Content)
+	comment.AuthorID = author.UserID
+	comment.AuthorName = author.Username
+	if comment.AuthorName == "" {
+		userResp, err := uc.AuthClient.GetUser(ctx, &pb.GetUserRequest{Id: author.UserID})
+		if err != nil || userResp == nil || userResp.User == nil {
+			return errors.New("failed to get user info")
+		}
+		comment.AuthorName = userResp.User.Username
+	}
+
+	if err := uc.CommentRepo.CreateComment(ctx, comment); err != nil {
+		return err
 	}
+	uc.notifyComment(ctx, comment, post, parent)
+	uc.Mentions.Record(ctx, author, commentSubject(comment), mentions)
+	return nil
+}
 
-	comment.AuthorName = userResp.User.Username
-	return uc.CommentRepo.CreateComment(ctx, comment)
+// notifyComment уведомляет автора родительского комментария об ответе, а
+// автора поста — о новом комментарии. Если ответили на комментарий автора
+// поста, уведомление одно — об ответе.
+func (uc *CommentUseCase) notifyComment(ctx context.Context, comment *entity.Comment, post *entity.Post, parent *entity.Comment) {
+	n := entity.Notification{
+		ActorID:   comment.AuthorID,
+		ActorName: comment.AuthorName,
+		Subject:   commentSubject(comment),
+	}
+	if parent != nil {
+		n.Type = entity.NotificationReply
+		uc.Notifications.Publish(ctx, n, parent.AuthorID)
+	}
+	if post != nil && (parent == nil || parent.AuthorID != post.AuthorID) {
+		n.Type = entity.NotificationComment
+		uc.Notifications.Publish(ctx, n, post.AuthorID)
+	}
 }
 
-func (uc *CommentUseCase) GetCommentsByPostID(ctx context.Context, postID int64) ([]entity.Comment, error) {
+func commentSubject(comment *entity.Comment) entity.Subject {
+	postID, commentID := comment.PostID, comment.ID
+	return entity.Subject{PostID: &postID, CommentID: &commentID}
+}
+
+// GetCommentsByPostID возвращает страницу веток поста с раскрытыми
+// на query.Depth уровней ответами.
+func (uc *CommentUseCase) GetCommentsByPostID(ctx context.Context, postID int64, query entity.CommentTreeQuery) (*entity.CommentPage, error) {
+	if err := uc.validateTreeQuery(&query); err != nil {
+		return nil, err
+	}
 
 	_, err := uc.postRepo.GetPostByID(ctx, postID)
 	if err != nil {
 		return nil, err
 	}
 
-	return uc.CommentRepo.GetCommentsByPostID(ctx, postID)
+	comments, err := uc.CommentRepo.GetCommentsByPostID(ctx, postID, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+	total, err := uc.CommentRepo.CountComments(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.expandReplies(ctx, comments, query.Depth, query.ViewerID); err != nil {
+		return nil, err
+	}
+	return &entity.CommentPage{Comments: comments, Total: total}, nil
+}
+
+// GetReplies возвращает страницу прямых ответов на комментарий — так
+// догружаются ветки, не поместившиеся в GetCommentsByPostID.
+func (uc *CommentUseCase) GetReplies(ctx context.Context, commentID int64, query entity.CommentTreeQuery) (*entity.CommentPage, error) {
+	if err := uc.validateTreeQuery(&query); err != nil {
+		return nil, err
+	}
+
+	parent, err := uc.CommentRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	// Ветки поста из корзины не показываем.
+	if _, err := uc.postRepo.GetPostByID(ctx, parent.PostID); err != nil {
+		return nil, err
+	}
+
+	replies, err := uc.CommentRepo.GetReplies(ctx, commentID, query.Limit, query.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.expandReplies(ctx, replies, query.Depth, query.ViewerID); err != nil {
+		return nil, err
+	}
+	return &entity.CommentPage{Comments: replies, Total: int64(parent.ReplyCount)}, nil
+}
+
+func (uc *CommentUseCase) validateTreeQuery(query *entity.CommentTreeQuery) error {
+	if query.Limit < 1 || query.Limit > MaxPostsPageSize {
+		return ErrInvalidPageSize
+	}
+	if query.Offset < 0 {
+		return ErrInvalidOffset
+	}
+	if query.Depth < 0 {
+		return ErrInvalidDepth
+	}
+	if query.Depth > uc.MaxDepth {
+		query.Depth = uc.MaxDepth
+	}
+	return nil
+}
+
+// expandReplies подвешивает под comments ответы на depth уровней вниз,
+// прячет удаленные и проставляет имена авторов, голоса viewerID и реакции
+// всего дерева.
+func (uc *CommentUseCase) expandReplies(ctx context.Context, comments []*entity.Comment, depth int, viewerID int64) error {
+	nodes := make(map[int64]*entity.Comment, len(comments))
+	ids := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		nodes[comment.ID] = comment
+		ids = append(ids, comment.ID)
+	}
+
+	descendants, err := uc.CommentRepo.GetDescendants(ctx, ids, depth, inlineRepliesPerComment)
+	if err != nil {
+		return err
+	}
+
+	tree := make([]*entity.Comment, 0, len(comments)+len(descendants))
+	tree = append(tree, comments...)
+	for _, reply := range descendants {
+		if reply.ParentID == nil {
+			continue
+		}
+		parent, ok := nodes[*reply.ParentID]
+		if !ok {
+			continue
+		}
+		parent.Replies = append(parent.Replies, reply)
+		nodes[reply.ID] = reply
+		tree = append(tree, reply)
+	}
+
+	for _, comment := range tree {
+		if comment.Deleted {
+			tombstone(comment)
+		}
+	}
+	uc.fillAuthorNames(ctx, tree)
+	if err := uc.fillMyVotes(ctx, viewerID, tree); err != nil {
+		return err
+	}
+	return uc.fillReactions(ctx, viewerID, tree)
 }
 
-// func (uc *CommentUseCase) DeleteComment(ctx context.Context, id int64) error {
-// 	// Получаем комментарий
-// 	comments, err := uc.CommentRepo.GetCommentsByPostID(ctx, id)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	if len(comments) == 0 {
-// 		return sql.ErrNoRows
-// 	}
-// 	comment := comments[0]
+func (uc *CommentUseCase) fillMyVotes(ctx context.Context, viewerID int64, comments []*entity.Comment) error {
+	if viewerID == 0 || len(comments) == 0 {
+		return nil
+	}
 
-// 	// Проверяем права доступа
-// 	userResp, err := uc.AuthClient.GetUser(ctx, &pb.GetUserRequest{Id: comment.AuthorID})
-// 	if err != nil || userResp == nil || userResp.User == nil {
-// 		return errors.New("failed to verify user")
-// 	}
+	ids := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	votes, err := uc.CommentRepo.GetCommentVotes(ctx, viewerID, ids)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		comment.MyVote = votes[comment.ID]
+	}
+	return nil
+}
 
-// 	// Проверяем, что пользователь является автором
-// 	if userResp.User.Id != comment.AuthorID {
-// 		return errors.New("permission denied")
-// 	}
+func (uc *CommentUseCase) fillReactions(ctx context.Context, viewerID int64, comments []*entity.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
 
-// 	return uc.CommentRepo.DeleteComment(ctx, id)
-// }
+	ids := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	reactions, err := uc.CommentRepo.GetCommentReactions(ctx, viewerID, ids)
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		comment.Reactions = orEmpty(reactions[comment.ID])
+	}
+	return nil
+}
+
+func tombstone(comment *entity.Comment) {
+	comment.Content = DeletedPlaceholder
+	comment.ContentHTML = markdown.Render(DeletedPlaceholder)
+	comment.AuthorName = DeletedPlaceholder
+	comment.AuthorID = 0
+	comment.UpdatedAt = nil
+	comment.Edited = false
+	comment.DeletedBy = nil
+}
+
+// fillAuthorNames берет актуальное имя из auth, сохраненное при создании —
+// запасной вариант.
+func (uc *CommentUseCase) fillAuthorNames(ctx context.Context, comments []*entity.Comment) {
+	authorIDs := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		if !comment.Deleted {
+			authorIDs = append(authorIDs, comment.AuthorID)
+		}
+	}
+	names := uc.users.Usernames(ctx, authorIDs)
+
+	for _, comment := range comments {
+		if comment.Deleted {
+			continue
+		}
+		if name, ok := names[comment.AuthorID]; ok {
+			comment.AuthorName = name
+		} else if comment.AuthorName == "" {
+			comment.AuthorName = "Unknown"
+		}
+	}
+}
+
+// UpdateComment меняет текст комментария. Править может автор или админ.
+func (uc *CommentUseCase) UpdateComment(ctx context.Context, user *authmw.Principal, postID, commentID int64, content string) (*entity.Comment, error) {
+	if _, err := uc.checkCommentAccess(ctx, user, postID, commentID); err != nil {
+		return nil, err
+	}
+
+	contentHTML, mentions := uc.Mentions.Render(ctx, content)
+	comment, err := uc.CommentRepo.UpdateComment(ctx, commentID, postID, user.UserID, user.Role, content, contentHTML)
+	if err != nil {
+		return nil, err
+	}
+	uc.Mentions.Record(ctx, user, commentSubject(comment), mentions)
+
+	uc.fillAuthorNames(ctx, []*entity.Comment{comment})
+	return comment, nil
+}
+
+// DeleteComment переносит комментарий в корзину; если на него есть ответы,
+// в ветке остается заглушка. Удалить может автор или админ; если удалил
+// админ, автор получает уведомление.
+func (uc *CommentUseCase) DeleteComment(ctx context.Context, user *authmw.Principal, postID, commentID int64) error {
+	comment, err := uc.checkCommentAccess(ctx, user, postID, commentID)
+	if err != nil {
+		return err
+	}
+	if err := uc.CommentRepo.DeleteComment(ctx, commentID, postID, user.UserID, user.Role); err != nil {
+		return err
+	}
+	// Автор, удаливший свой комментарий, уведомления не получает: Notify
+	// пропускает автора действия.
+	uc.Notifications.Publish(ctx, entity.Notification{
+		Type:      entity.NotificationModeration,
+		Action:    entity.ModerationCommentDeleted,
+		ActorID:   user.UserID,
+		ActorName: user.Username,
+		Subject:   commentSubject(comment),
+	}, comment.AuthorID)
+	return nil
+}
+
+// checkCommentAccess отличает чужой комментарий от несуществующего, чтобы
+// вернуть 403, а не 404. Сам запрос в репозитории права проверяет еще раз.
+func (uc *CommentUseCase) checkCommentAccess(ctx context.Context, user *authmw.Principal, postID, commentID int64) (*entity.Comment, error) {
+	comment, err := uc.CommentRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.PostID != postID || comment.Deleted {
+		return nil, repository.ErrCommentNotFound
+	}
+	if comment.AuthorID != user.UserID && !user.IsAdmin() {
+		return nil, repository.ErrPermissionDenied
+	}
+	return comment, nil
+}
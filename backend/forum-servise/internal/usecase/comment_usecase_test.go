@@ -4,47 +4,134 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"backend.com/forum/authmw"
 	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/markdown"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 )
 
 type MockCommentRepository struct {
 	CreateCommentFunc       func(ctx context.Context, comment *entity.Comment) error
-	GetCommentsByPostIDFunc func(ctx context.Context, postID int64) ([]entity.Comment, error)
-	DeleteCommentFunc       func(ctx context.Context, id int64) error
+	GetCommentByIDFunc      func(ctx context.Context, id int64) (*entity.Comment, error)
+	GetCommentsByPostIDFunc func(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error)
+	CountCommentsFunc       func(ctx context.Context, postID int64) (int64, error)
+	GetRepliesFunc          func(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Comment, error)
+	GetDescendantsFunc      func(ctx context.Context, parentIDs []int64, depth, perParent int) ([]*entity.Comment, error)
+	UpdateCommentFunc       func(ctx context.Context, id, postID, authorID int64, role, content, contentHTML string) (*entity.Comment, error)
+	DeleteCommentFunc       func(ctx context.Context, id, postID, authorID int64, role string) error
+
+	RestoreCommentFunc       func(ctx context.Context, id int64) error
+	GetDeletedCommentsFunc   func(ctx context.Context, limit, offset int) ([]*entity.Comment, error)
+	CountDeletedCommentsFunc func(ctx context.Context) (int64, error)
+	PurgeDeletedCommentsFunc func(ctx context.Context, before time.Time, limit int) (int64, error)
+
+	VoteCommentFunc     func(ctx context.Context, commentID, userID int64, value int) (int, error)
+	GetCommentVotesFunc func(ctx context.Context, userID int64, commentIDs []int64) (map[int64]int, error)
+
+	ToggleCommentReactionFunc func(ctx context.Context, commentID, userID int64, emoji string) (bool, error)
+	GetCommentReactionsFunc   func(ctx context.Context, viewerID int64, commentIDs []int64) (map[int64][]entity.Reaction, error)
 }
 
 func (m *MockCommentRepository) CreateComment(ctx context.Context, comment *entity.Comment) error {
 	return m.CreateCommentFunc(ctx, comment)
 }
 
-func (m *MockCommentRepository) GetCommentsByPostID(ctx context.Context, postID int64) ([]entity.Comment, error) {
-	return m.GetCommentsByPostIDFunc(ctx, postID)
+func (m *MockCommentRepository) GetCommentByID(ctx context.Context, id int64) (*entity.Comment, error) {
+	return m.GetCommentByIDFunc(ctx, id)
+}
+
+func (m *MockCommentRepository) GetCommentsByPostID(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+	return m.GetCommentsByPostIDFunc(ctx, postID, limit, offset)
+}
+
+func (m *MockCommentRepository) CountComments(ctx context.Context, postID int64) (int64, error) {
+	if m.CountCommentsFunc != nil {
+		return m.CountCommentsFunc(ctx, postID)
+	}
+	return 0, nil
+}
+
+func (m *MockCommentRepository) GetReplies(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Comment, error) {
+	return m.GetRepliesFunc(ctx, parentID, limit, offset)
+}
+
+func (m *MockCommentRepository) GetDescendants(ctx context.Context, parentIDs []int64, depth, perParent int) ([]*entity.Comment, error) {
+	if m.GetDescendantsFunc != nil {
+		return m.GetDescendantsFunc(ctx, parentIDs, depth, perParent)
+	}
+	return []*entity.Comment{}, nil
+}
+
+func (m *MockCommentRepository) UpdateComment(ctx context.Context, id, postID, authorID int64, role, content, contentHTML string) (*entity.Comment, error) {
+	return m.UpdateCommentFunc(ctx, id, postID, authorID, role, content, contentHTML)
+}
+
+func (m *MockCommentRepository) DeleteComment(ctx context.Context, id, postID, authorID int64, role string) error {
+	return m.DeleteCommentFunc(ctx, id, postID, authorID, role)
+}
+
+func (m *MockCommentRepository) RestoreComment(ctx context.Context, id int64) error {
+	return m.RestoreCommentFunc(ctx, id)
+}
+
+func (m *MockCommentRepository) GetDeletedComments(ctx context.Context, limit, offset int) ([]*entity.Comment, error) {
+	return m.GetDeletedCommentsFunc(ctx, limit, offset)
+}
+
+func (m *MockCommentRepository) CountDeletedComments(ctx context.Context) (int64, error) {
+	return m.CountDeletedCommentsFunc(ctx)
+}
+
+func (m *MockCommentRepository) PurgeDeletedComments(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return m.PurgeDeletedCommentsFunc(ctx, before, limit)
+}
+
+func (m *MockCommentRepository) VoteComment(ctx context.Context, commentID, userID int64, value int) (int, error) {
+	return m.VoteCommentFunc(ctx, commentID, userID, value)
+}
+
+func (m *MockCommentRepository) GetCommentVotes(ctx context.Context, userID int64, commentIDs []int64) (map[int64]int, error) {
+	if m.GetCommentVotesFunc != nil {
+		return m.GetCommentVotesFunc(ctx, userID, commentIDs)
+	}
+	return map[int64]int{}, nil
 }
 
-func (m *MockCommentRepository) DeleteComment(ctx context.Context, id int64) error {
-	return m.DeleteCommentFunc(ctx, id)
+func (m *MockCommentRepository) ToggleCommentReaction(ctx context.Context, commentID, userID int64, emoji string) (bool, error) {
+	return m.ToggleCommentReactionFunc(ctx, commentID, userID, emoji)
+}
+
+func (m *MockCommentRepository) GetCommentReactions(ctx context.Context, viewerID int64, commentIDs []int64) (map[int64][]entity.Reaction, error) {
+	if m.GetCommentReactionsFunc != nil {
+		return m.GetCommentReactionsFunc(ctx, viewerID, commentIDs)
+	}
+	return map[int64][]entity.Reaction{}, nil
 }
 
 func TestCommentUseCase_CreateComment(t *testing.T) {
 	tests := []struct {
 		name        string
+		author      *authmw.Principal
 		comment     *entity.Comment
 		mockPost    func() *MockPostRepository
 		mockComment func() *MockCommentRepository
+		mockAuth    *MockAuthServiceClient
+		wantAuthor  string
 		wantErr     bool
 		expectedErr error
 	}{
 		{
-			name: "Success",
+			name:   "Success",
+			author: &authmw.Principal{UserID: 1, Username: "alice"},
 			comment: &entity.Comment{
-				PostID:   1,
-				Content:  "Test comment",
-				AuthorID: 1,
+				PostID:  1,
+				Content: "Test comment",
 			},
 			mockPost: func() *MockPostRepository {
 				return &MockPostRepository{
@@ -60,14 +147,133 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 					},
 				}
 			},
-			wantErr: false,
+			wantAuthor: "alice",
+			wantErr:    false,
+		},
+		{
+			name:   "Username resolved through auth service",
+			author: &authmw.Principal{UserID: 1},
+			comment: &entity.Comment{
+				PostID:  1,
+				Content: "Test comment",
+			},
+			mockPost: func() *MockPostRepository {
+				return &MockPostRepository{
+					GetPostByIDFunc: func(ctx context.Context, id int64) (*entity.Post, error) {
+						return &entity.Post{ID: id}, nil
+					},
+				}
+			},
+			mockComment: func() *MockCommentRepository {
+				return &MockCommentRepository{
+					CreateCommentFunc: func(ctx context.Context, comment *entity.Comment) error {
+						return nil
+					},
+				}
+			},
+			mockAuth: &MockAuthServiceClient{
+				GetUserFunc: func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
+					return &pb.GetUserResponse{User: &pb.User{Id: in.Id, Username: "bob"}}, nil
+				},
+			},
+			wantAuthor: "bob",
+			wantErr:    false,
+		},
+		{
+			name:   "Reply",
+			author: &authmw.Principal{UserID: 1, Username: "alice"},
+			comment: &entity.Comment{
+				PostID:   1,
+				ParentID: int64Ptr(7),
+				Content:  "Reply",
+			},
+			mockPost: func() *MockPostRepository {
+				return &MockPostRepository{}
+			},
+			mockComment: func() *MockCommentRepository {
+				return &MockCommentRepository{
+					GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+						return &entity.Comment{ID: id, PostID: 1, Depth: 2}, nil
+					},
+					CreateCommentFunc: func(ctx context.Context, comment *entity.Comment) error {
+						if comment.Depth != 3 {
+							return errors.New("unexpected depth")
+						}
+						return nil
+					},
+				}
+			},
+			wantAuthor: "alice",
+		},
+		{
+			name:   "Parent from another post",
+			author: &authmw.Principal{UserID: 1, Username: "alice"},
+			comment: &entity.Comment{
+				PostID:   1,
+				ParentID: int64Ptr(7),
+				Content:  "Reply",
+			},
+			mockPost: func() *MockPostRepository {
+				return &MockPostRepository{}
+			},
+			mockComment: func() *MockCommentRepository {
+				return &MockCommentRepository{
+					GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+						return &entity.Comment{ID: id, PostID: 2}, nil
+					},
+				}
+			},
+			wantErr:     true,
+			expectedErr: ErrParentNotFound,
 		},
 		{
-			name: "Post not found",
+			name:   "Parent not found",
+			author: &authmw.Principal{UserID: 1, Username: "alice"},
 			comment: &entity.Comment{
 				PostID:   1,
-				Content:  "Test comment",
-				AuthorID: 1,
+				ParentID: int64Ptr(7),
+				Content:  "Reply",
+			},
+			mockPost: func() *MockPostRepository {
+				return &MockPostRepository{}
+			},
+			mockComment: func() *MockCommentRepository {
+				return &MockCommentRepository{
+					GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+						return nil, repository.ErrCommentNotFound
+					},
+				}
+			},
+			wantErr:     true,
+			expectedErr: ErrParentNotFound,
+		},
+		{
+			name:   "Thread too deep",
+			author: &authmw.Principal{UserID: 1, Username: "alice"},
+			comment: &entity.Comment{
+				PostID:   1,
+				ParentID: int64Ptr(7),
+				Content:  "Reply",
+			},
+			mockPost: func() *MockPostRepository {
+				return &MockPostRepository{}
+			},
+			mockComment: func() *MockCommentRepository {
+				return &MockCommentRepository{
+					GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+						return &entity.Comment{ID: id, PostID: 1, Depth: DefaultMaxCommentDepth}, nil
+					},
+				}
+			},
+			wantErr:     true,
+			expectedErr: ErrCommentTooDeep,
+		},
+		{
+			name:   "Post not found",
+			author: &authmw.Principal{UserID: 1, Username: "alice"},
+			comment: &entity.Comment{
+				PostID:  1,
+				Content: "Test comment",
 			},
 			mockPost: func() *MockPostRepository {
 				return &MockPostRepository{
@@ -88,11 +294,14 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockPost := tt.mockPost()
 			mockComment := tt.mockComment()
-			mockAuth := &MockAuthServiceClient{}
+			mockAuth := tt.mockAuth
+			if mockAuth == nil {
+				mockAuth = &MockAuthServiceClient{}
+			}
 
 			uc := NewCommentUseCase(mockComment, mockPost, mockAuth)
 
-			err := uc.CreateComment(context.Background(), tt.comment)
+			err := uc.CreateComment(context.Background(), tt.author, tt.comment)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateComment() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -100,7 +309,118 @@ func TestCommentUseCase_CreateComment(t *testing.T) {
 
 			if tt.wantErr {
 				assert.Equal(t, tt.expectedErr, err)
+				return
 			}
+			assert.Equal(t, tt.author.UserID, tt.comment.AuthorID)
+			assert.Equal(t, tt.wantAuthor, tt.comment.AuthorName)
+			assert.Equal(t, markdown.Render(tt.comment.Content), tt.comment.ContentHTML)
+		})
+	}
+}
+
+func TestCommentUseCase_CreateComment_Mentions(t *testing.T) {
+	var notifiedIDs []int64
+	var notified entity.Notification
+	authClient := &MockAuthServiceClient{
+		GetUsersByUsernamesFunc: func(ctx context.Context, in *pb.GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+			return &pb.GetUsersResponse{Users: []*pb.User{{Id: 5, Username: "bob"}}}, nil
+		},
+	}
+	uc := NewCommentUseCase(&MockCommentRepository{
+		CreateCommentFunc: func(ctx context.Context, comment *entity.Comment) error {
+			comment.ID = 3
+			return nil
+		},
+	}, &MockPostRepository{}, authClient)
+	uc.Mentions = NewMentionUsecase(&MockMentionRepository{}, NewNotificationUsecase(&MockNotificationRepository{
+		CreateNotificationsFunc: func(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+			notified, notifiedIDs = n, userIDs
+			return []*entity.Notification{}, nil
+		},
+	}, NewMockLogger()), authClient, NewMockLogger())
+
+	comment := &entity.Comment{PostID: 1, Content: "@bob look"}
+	err := uc.CreateComment(context.Background(), &authmw.Principal{UserID: 1, Username: "alice"}, comment)
+	require.NoError(t, err)
+	assert.Contains(t, comment.ContentHTML, `<a href="/users/bob"`)
+	assert.Equal(t, []int64{5}, notifiedIDs)
+	assert.Equal(t, "alice", notified.ActorName)
+	assert.Equal(t, int64(1), *notified.PostID)
+	assert.Equal(t, int64(3), *notified.CommentID)
+}
+
+func TestCommentUseCase_CreateComment_Notifications(t *testing.T) {
+	type sent struct {
+		Type   string
+		UserID int64
+	}
+	var got []sent
+	repo := &MockCommentRepository{
+		GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+			// Комментарий 7 написал автор поста, 8 — другой пользователь.
+			authorID := int64(2)
+			if id == 8 {
+				authorID = 3
+			}
+			return &entity.Comment{ID: id, PostID: 1, AuthorID: authorID}, nil
+		},
+		CreateCommentFunc: func(ctx context.Context, comment *entity.Comment) error {
+			comment.ID = 10
+			return nil
+		},
+	}
+	posts := &MockPostRepository{
+		GetPostByIDFunc: func(ctx context.Context, id int64) (*entity.Post, error) {
+			return &entity.Post{ID: id, AuthorID: 2}, nil
+		},
+	}
+	uc := NewCommentUseCase(repo, posts, &MockAuthServiceClient{})
+	uc.Notifications = NewNotificationUsecase(&MockNotificationRepository{
+		CreateNotificationsFunc: func(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+			for _, id := range userIDs {
+				got = append(got, sent{n.Type, id})
+			}
+			return []*entity.Notification{}, nil
+		},
+	}, NewMockLogger())
+	alice := &authmw.Principal{UserID: 1, Username: "alice"}
+
+	tests := []struct {
+		name     string
+		author   *authmw.Principal
+		parentID *int64
+		want     []sent
+	}{
+		{
+			name:   "Top-level comment notifies the post author",
+			author: alice,
+			want:   []sent{{entity.NotificationComment, 2}},
+		},
+		{
+			name:     "Reply to the post author is a single reply",
+			author:   alice,
+			parentID: int64Ptr(7),
+			want:     []sent{{entity.NotificationReply, 2}},
+		},
+		{
+			name:     "Reply to someone else notifies both",
+			author:   alice,
+			parentID: int64Ptr(8),
+			want:     []sent{{entity.NotificationReply, 3}, {entity.NotificationComment, 2}},
+		},
+		{
+			name:   "Post author commenting on own post",
+			author: &authmw.Principal{UserID: 2, Username: "bob"},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			comment := &entity.Comment{PostID: 1, ParentID: tt.parentID, Content: "hi"}
+			require.NoError(t, uc.CreateComment(context.Background(), tt.author, comment))
+			assert.Equal(t, tt.want, got)
 		})
 	}
 }
@@ -111,7 +431,7 @@ func TestCommentUseCase_GetCommentsByPostID(t *testing.T) {
 		postID      int64
 		mockComment func() *MockCommentRepository
 		mockAuth    func() *MockAuthServiceClient
-		want        []entity.Comment
+		want        []*entity.Comment
 		wantErr     bool
 	}{
 		{
@@ -119,8 +439,8 @@ func TestCommentUseCase_GetCommentsByPostID(t *testing.T) {
 			postID: 1,
 			mockComment: func() *MockCommentRepository {
 				return &MockCommentRepository{
-					GetCommentsByPostIDFunc: func(ctx context.Context, postID int64) ([]entity.Comment, error) {
-						return []entity.Comment{
+					GetCommentsByPostIDFunc: func(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+						return []*entity.Comment{
 							{ID: 1, PostID: postID, AuthorID: 1, Content: "Comment 1"},
 							{ID: 2, PostID: postID, AuthorID: 2, Content: "Comment 2"},
 						}, nil
@@ -129,16 +449,18 @@ func TestCommentUseCase_GetCommentsByPostID(t *testing.T) {
 			},
 			mockAuth: func() *MockAuthServiceClient {
 				return &MockAuthServiceClient{
-					GetUserFunc: func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
-						return &pb.GetUserResponse{
-							User: &pb.User{Username: "user" + string(rune('0'+in.Id))},
-						}, nil
+					GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+						users := make([]*pb.User, 0, len(in.Ids))
+						for _, id := range in.Ids {
+							users = append(users, &pb.User{Id: id, Username: "user" + string(rune('0'+id))})
+						}
+						return &pb.GetUsersResponse{Users: users}, nil
 					},
 				}
 			},
-			want: []entity.Comment{
-				{ID: 1, PostID: 1, AuthorID: 1, Content: "Comment 1", AuthorName: "user1"},
-				{ID: 2, PostID: 1, AuthorID: 2, Content: "Comment 2", AuthorName: "user2"},
+			want: []*entity.Comment{
+				{ID: 1, PostID: 1, AuthorID: 1, Content: "Comment 1", AuthorName: "user1", Reactions: []entity.Reaction{}},
+				{ID: 2, PostID: 1, AuthorID: 2, Content: "Comment 2", AuthorName: "user2", Reactions: []entity.Reaction{}},
 			},
 			wantErr: false,
 		},
@@ -147,8 +469,8 @@ func TestCommentUseCase_GetCommentsByPostID(t *testing.T) {
 			postID: 1,
 			mockComment: func() *MockCommentRepository {
 				return &MockCommentRepository{
-					GetCommentsByPostIDFunc: func(ctx context.Context, postID int64) ([]entity.Comment, error) {
-						return []entity.Comment{
+					GetCommentsByPostIDFunc: func(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+						return []*entity.Comment{
 							{ID: 1, PostID: postID, AuthorID: 1, Content: "Comment 1"},
 						}, nil
 					},
@@ -156,16 +478,80 @@ func TestCommentUseCase_GetCommentsByPostID(t *testing.T) {
 			},
 			mockAuth: func() *MockAuthServiceClient {
 				return &MockAuthServiceClient{
-					GetUserFunc: func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
-						return nil, errors.New("user not found")
+					GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+						return &pb.GetUsersResponse{}, nil
 					},
 				}
 			},
-			want: []entity.Comment{
-				{ID: 1, PostID: 1, AuthorID: 1, Content: "Comment 1", AuthorName: "Unknown"},
+			want: []*entity.Comment{
+				{ID: 1, PostID: 1, AuthorID: 1, Content: "Comment 1", AuthorName: "Unknown", Reactions: []entity.Reaction{}},
 			},
 			wantErr: false,
 		},
+		{
+			name:   "Auth unavailable keeps stored name",
+			postID: 1,
+			mockComment: func() *MockCommentRepository {
+				return &MockCommentRepository{
+					GetCommentsByPostIDFunc: func(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+						return []*entity.Comment{
+							{ID: 1, PostID: postID, AuthorID: 1, Content: "Comment 1", AuthorName: "alice"},
+						}, nil
+					},
+				}
+			},
+			mockAuth: func() *MockAuthServiceClient {
+				return &MockAuthServiceClient{
+					GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+						return nil, errors.New("auth unavailable")
+					},
+				}
+			},
+			want: []*entity.Comment{
+				{ID: 1, PostID: 1, AuthorID: 1, Content: "Comment 1", AuthorName: "alice", Reactions: []entity.Reaction{}},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Replies are nested under their parents",
+			postID: 1,
+			mockComment: func() *MockCommentRepository {
+				return &MockCommentRepository{
+					GetCommentsByPostIDFunc: func(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+						return []*entity.Comment{
+							{ID: 2, PostID: postID, AuthorID: 1, AuthorName: "a", ReplyCount: 1},
+							{ID: 1, PostID: postID, AuthorID: 1, AuthorName: "a", ReplyCount: 1},
+						}, nil
+					},
+					GetDescendantsFunc: func(ctx context.Context, parentIDs []int64, depth, perParent int) ([]*entity.Comment, error) {
+						if depth != DefaultCommentDepth || perParent != inlineRepliesPerComment {
+							return nil, errors.New("unexpected expansion")
+						}
+						return []*entity.Comment{
+							{ID: 3, PostID: 1, ParentID: int64Ptr(1), Depth: 1, AuthorID: 1, AuthorName: "a", ReplyCount: 1},
+							// Родитель не попал в выборку — ответ отбрасывается.
+							{ID: 9, PostID: 1, ParentID: int64Ptr(8), Depth: 2, AuthorID: 1, AuthorName: "a"},
+							{ID: 4, PostID: 1, ParentID: int64Ptr(3), Depth: 2, AuthorID: 1, AuthorName: "a"},
+						}, nil
+					},
+				}
+			},
+			mockAuth: func() *MockAuthServiceClient {
+				return &MockAuthServiceClient{
+					GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+						return nil, errors.New("auth unavailable")
+					},
+				}
+			},
+			want: []*entity.Comment{
+				{ID: 2, PostID: 1, AuthorID: 1, AuthorName: "a", ReplyCount: 1, Reactions: []entity.Reaction{}},
+				{ID: 1, PostID: 1, AuthorID: 1, AuthorName: "a", ReplyCount: 1, Reactions: []entity.Reaction{}, Replies: []*entity.Comment{
+					{ID: 3, PostID: 1, ParentID: int64Ptr(1), Depth: 1, AuthorID: 1, AuthorName: "a", ReplyCount: 1, Reactions: []entity.Reaction{}, Replies: []*entity.Comment{
+						{ID: 4, PostID: 1, ParentID: int64Ptr(3), Depth: 2, AuthorID: 1, AuthorName: "a", Reactions: []entity.Reaction{}},
+					}},
+				}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -176,13 +562,214 @@ func TestCommentUseCase_GetCommentsByPostID(t *testing.T) {
 
 			uc := NewCommentUseCase(mockComment, mockPost, mockAuth)
 
-			got, err := uc.GetCommentsByPostID(context.Background(), tt.postID)
+			got, err := uc.GetCommentsByPostID(context.Background(), tt.postID,
+				entity.CommentTreeQuery{Depth: DefaultCommentDepth, Limit: DefaultCommentsPageSize})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetCommentsByPostID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, got.Comments)
+		})
+	}
+}
+
+func TestCommentUseCase_GetCommentsByPostID_Query(t *testing.T) {
+	var gotLimit, gotOffset, gotDepth int
+	repo := &MockCommentRepository{
+		GetCommentsByPostIDFunc: func(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+			gotLimit, gotOffset = limit, offset
+			return []*entity.Comment{{ID: 1, PostID: postID, AuthorName: "a"}}, nil
+		},
+		CountCommentsFunc: func(ctx context.Context, postID int64) (int64, error) {
+			return 41, nil
+		},
+		GetDescendantsFunc: func(ctx context.Context, parentIDs []int64, depth, perParent int) ([]*entity.Comment, error) {
+			gotDepth = depth
+			return nil, nil
+		},
+	}
+	uc := NewCommentUseCase(repo, &MockPostRepository{}, &MockAuthServiceClient{})
+	uc.MaxDepth = 2
+
+	page, err := uc.GetCommentsByPostID(context.Background(), 1, entity.CommentTreeQuery{Depth: 5, Limit: 10, Offset: 20})
+	require.NoError(t, err)
+	assert.Equal(t, int64(41), page.Total)
+	assert.Equal(t, 10, gotLimit)
+	assert.Equal(t, 20, gotOffset)
+	assert.Equal(t, 2, gotDepth, "depth is capped by MaxDepth")
+
+	for _, query := range []entity.CommentTreeQuery{
+		{Limit: 0},
+		{Limit: MaxPostsPageSize + 1},
+		{Limit: 10, Offset: -1},
+		{Limit: 10, Depth: -1},
+	} {
+		_, err := uc.GetCommentsByPostID(context.Background(), 1, query)
+		assert.Error(t, err, "%+v", query)
+	}
+}
+
+func TestCommentUseCase_GetReplies(t *testing.T) {
+	repo := &MockCommentRepository{
+		GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+			if id != 1 {
+				return nil, repository.ErrCommentNotFound
+			}
+			return &entity.Comment{ID: 1, PostID: 1, ReplyCount: 12}, nil
+		},
+		GetRepliesFunc: func(ctx context.Context, parentID int64, limit, offset int) ([]*entity.Comment, error) {
+			return []*entity.Comment{
+				{ID: 6, PostID: 1, ParentID: int64Ptr(parentID), Depth: 1, AuthorName: "a"},
+			}, nil
+		},
+	}
+	uc := NewCommentUseCase(repo, &MockPostRepository{}, &MockAuthServiceClient{})
+
+	page, err := uc.GetReplies(context.Background(), 1, entity.CommentTreeQuery{Limit: 5, Offset: 5})
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), page.Total)
+	require.Len(t, page.Comments, 1)
+	assert.Equal(t, int64(6), page.Comments[0].ID)
+
+	_, err = uc.GetReplies(context.Background(), 2, entity.CommentTreeQuery{Limit: 5})
+	assert.ErrorIs(t, err, repository.ErrCommentNotFound)
+}
+
+func TestCommentUseCase_GetCommentsByPostID_Tombstone(t *testing.T) {
+	deletedAt := time.Now()
+	repo := &MockCommentRepository{
+		GetCommentsByPostIDFunc: func(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+			return []*entity.Comment{{
+				ID: 1, PostID: postID, AuthorID: 7, AuthorName: "alice", Content: "secret",
+				Deleted: true, DeletedAt: &deletedAt, DeletedBy: int64Ptr(7), ReplyCount: 1,
+			}}, nil
+		},
+		GetDescendantsFunc: func(ctx context.Context, parentIDs []int64, depth, perParent int) ([]*entity.Comment, error) {
+			return []*entity.Comment{{ID: 2, PostID: 1, ParentID: int64Ptr(1), Depth: 1, AuthorID: 8, AuthorName: "bob", Content: "reply"}}, nil
+		},
+	}
+	uc := NewCommentUseCase(repo, &MockPostRepository{}, &MockAuthServiceClient{})
+
+	page, err := uc.GetCommentsByPostID(context.Background(), 1, entity.CommentTreeQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Comments, 1)
+
+	root := page.Comments[0]
+	assert.True(t, root.Deleted)
+	assert.Equal(t, DeletedPlaceholder, root.Content)
+	assert.Equal(t, DeletedPlaceholder, root.AuthorName)
+	assert.Zero(t, root.AuthorID)
+	assert.Nil(t, root.DeletedBy)
+	require.Len(t, root.Replies, 1)
+	assert.Equal(t, "reply", root.Replies[0].Content)
+}
+
+func TestCommentUseCase_UpdateComment(t *testing.T) {
+	stored := &entity.Comment{ID: 5, PostID: 1, AuthorID: 1, AuthorName: "alice"}
+
+	tests := []struct {
+		name    string
+		user    *authmw.Principal
+		postID  int64
+		wantErr error
+	}{
+		{name: "Author", user: &authmw.Principal{UserID: 1, Role: "user"}, postID: 1},
+		{name: "Admin", user: &authmw.Principal{UserID: 9, Role: authmw.RoleAdmin}, postID: 1},
+		{name: "Stranger", user: &authmw.Principal{UserID: 2, Role: "user"}, postID: 1, wantErr: repository.ErrPermissionDenied},
+		{name: "Wrong post", user: &authmw.Principal{UserID: 1, Role: "user"}, postID: 2, wantErr: repository.ErrCommentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := false
+			repo := &MockCommentRepository{
+				GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+					return stored, nil
+				},
+				UpdateCommentFunc: func(ctx context.Context, id, postID, authorID int64, role, content, contentHTML string) (*entity.Comment, error) {
+					updated = true
+					edited := *stored
+					edited.Content, edited.Edited = content, true
+					edited.ContentHTML = contentHTML
+					return &edited, nil
+				},
+			}
+			uc := NewCommentUseCase(repo, &MockPostRepository{}, &MockAuthServiceClient{})
+
+			got, err := uc.UpdateComment(context.Background(), tt.user, tt.postID, 5, "new text")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.False(t, updated)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "new text", got.Content)
+			assert.Equal(t, "<p>new text</p>\n", got.ContentHTML)
+			assert.True(t, got.Edited)
+			assert.Equal(t, "alice", got.AuthorName)
 		})
 	}
 }
+
+func TestCommentUseCase_DeleteComment(t *testing.T) {
+	var deleted []int64
+	repo := &MockCommentRepository{
+		GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+			if id == 404 {
+				return nil, repository.ErrCommentNotFound
+			}
+			return &entity.Comment{ID: id, PostID: 1, AuthorID: 1, Deleted: id == 410}, nil
+		},
+		DeleteCommentFunc: func(ctx context.Context, id, postID, authorID int64, role string) error {
+			deleted = append(deleted, id)
+			return nil
+		},
+	}
+	uc := NewCommentUseCase(repo, &MockPostRepository{}, &MockAuthServiceClient{})
+	author := &authmw.Principal{UserID: 1, Role: "user"}
+
+	require.NoError(t, uc.DeleteComment(context.Background(), author, 1, 5))
+	assert.ErrorIs(t, uc.DeleteComment(context.Background(), &authmw.Principal{UserID: 2, Role: "user"}, 1, 6),
+		repository.ErrPermissionDenied)
+	assert.ErrorIs(t, uc.DeleteComment(context.Background(), author, 1, 404), repository.ErrCommentNotFound)
+	assert.ErrorIs(t, uc.DeleteComment(context.Background(), author, 1, 410), repository.ErrCommentNotFound)
+	assert.Equal(t, []int64{5}, deleted)
+}
+
+func TestCommentUseCase_DeleteComment_Notification(t *testing.T) {
+	var sent []entity.Notification
+	var recipients []int64
+	uc := NewCommentUseCase(&MockCommentRepository{
+		GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+			return &entity.Comment{ID: id, PostID: 1, AuthorID: 1}, nil
+		},
+		DeleteCommentFunc: func(ctx context.Context, id, postID, authorID int64, role string) error {
+			return nil
+		},
+	}, &MockPostRepository{}, &MockAuthServiceClient{})
+	uc.Notifications = NewNotificationUsecase(&MockNotificationRepository{
+		CreateNotificationsFunc: func(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+			sent = append(sent, n)
+			recipients = append(recipients, userIDs...)
+			return []*entity.Notification{}, nil
+		},
+	}, NewMockLogger())
+
+	// Свой комментарий — без уведомления.
+	require.NoError(t, uc.DeleteComment(context.Background(), &authmw.Principal{UserID: 1, Role: "user"}, 1, 5))
+	assert.Empty(t, sent)
+
+	require.NoError(t, uc.DeleteComment(context.Background(),
+		&authmw.Principal{UserID: 9, Username: "root", Role: authmw.RoleAdmin}, 1, 6))
+	require.Len(t, sent, 1)
+	assert.Equal(t, []int64{1}, recipients)
+	assert.Equal(t, entity.NotificationModeration, sent[0].Type)
+	assert.Equal(t, entity.ModerationCommentDeleted, sent[0].Action)
+	assert.Equal(t, "root", sent[0].ActorName)
+	assert.Equal(t, int64(6), *sent[0].CommentID)
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
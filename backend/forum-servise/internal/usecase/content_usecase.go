@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/markdown"
+)
+
+const DefaultRenderBatchSize = 200
+
+// ContentUsecase дорисовывает content_html постам и комментариям,
+// сохраненным до перехода на markdown.
+type ContentUsecase struct {
+	repo      repository.ContentRepository
+	batchSize int
+	logger    *logger.Logger
+}
+
+func NewContentUsecase(repo repository.ContentRepository, logger *logger.Logger) *ContentUsecase {
+	return &ContentUsecase{repo: repo, batchSize: DefaultRenderBatchSize, logger: logger}
+}
+
+// Run один раз дорисовывает недостающий HTML; запускается при старте
+// сервиса в фоне.
+func (uc *ContentUsecase) Run(ctx context.Context) {
+	rendered, err := uc.RenderMissing(ctx)
+	if err != nil {
+		uc.logger.Errorf("content render failed: %v", err)
+	}
+	if rendered > 0 {
+		uc.logger.Debugf("content: rendered html for %d items", rendered)
+	}
+}
+
+// RenderMissing проходит посты, затем комментарии без content_html
+// пачками по batchSize и возвращает число отрисованных записей.
+func (uc *ContentUsecase) RenderMissing(ctx context.Context) (int, error) {
+	total := 0
+	for _, table := range []repository.ContentTable{repository.PostsContent, repository.CommentsContent} {
+		n, err := uc.renderTable(ctx, table)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (uc *ContentUsecase) renderTable(ctx context.Context, table repository.ContentTable) (int, error) {
+	rendered := 0
+	var afterID int64
+	for {
+		sources, err := uc.repo.GetUnrendered(ctx, table, afterID, uc.batchSize)
+		if err != nil {
+			return rendered, err
+		}
+		for _, src := range sources {
+			if err := uc.repo.SetContentHTML(ctx, table, src.ID, markdown.Render(src.Content)); err != nil {
+				return rendered, err
+			}
+			afterID = src.ID
+			rendered++
+		}
+		if len(sources) < uc.batchSize {
+			return rendered, nil
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentUsecase_RenderMissing(t *testing.T) {
+	stored := map[repository.ContentTable][]entity.ContentSource{
+		repository.PostsContent:    {{ID: 1, Content: "*a*"}, {ID: 2, Content: "b"}, {ID: 5, Content: "c"}},
+		repository.CommentsContent: {{ID: 7, Content: "**d**"}},
+	}
+	rendered := map[int64]string{}
+	repo := &MockContentRepository{
+		GetUnrenderedFunc: func(ctx context.Context, table repository.ContentTable, afterID int64, limit int) ([]entity.ContentSource, error) {
+			page := []entity.ContentSource{}
+			for _, src := range stored[table] {
+				if src.ID > afterID && len(page) < limit {
+					page = append(page, src)
+				}
+			}
+			return page, nil
+		},
+		SetContentHTMLFunc: func(ctx context.Context, table repository.ContentTable, id int64, contentHTML string) error {
+			rendered[id] = contentHTML
+			return nil
+		},
+	}
+	uc := NewContentUsecase(repo, NewMockLogger())
+	uc.batchSize = 2
+
+	n, err := uc.RenderMissing(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, map[int64]string{
+		1: "<p><em>a</em></p>\n",
+		2: "<p>b</p>\n",
+		5: "<p>c</p>\n",
+		7: "<p><strong>d</strong></p>\n",
+	}, rendered)
+}
+
+func TestContentUsecase_RenderMissing_Error(t *testing.T) {
+	dbErr := errors.New("db down")
+	repo := &MockContentRepository{
+		GetUnrenderedFunc: func(ctx context.Context, table repository.ContentTable, afterID int64, limit int) ([]entity.ContentSource, error) {
+			if table == repository.CommentsContent {
+				return nil, dbErr
+			}
+			return []entity.ContentSource{{ID: 1, Content: "a"}}, nil
+		},
+	}
+
+	n, err := NewContentUsecase(repo, NewMockLogger()).RenderMissing(context.Background())
+	assert.ErrorIs(t, err, dbErr)
+	assert.Equal(t, 1, n)
+}
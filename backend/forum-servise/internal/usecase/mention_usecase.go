@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+
+	"backend.com/forum/authmw"
+	"backend.com/forum/mention"
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/markdown"
+)
+
+// MentionUsecase разбирает упоминания @username в постах и комментариях,
+// проверяет имена в auth-сервисе и уведомляет упомянутых. Нулевой
+// *MentionUsecase тоже работает: текст рендерится без ссылок, упоминания
+// не сохраняются.
+type MentionUsecase struct {
+	repo          repository.MentionRepository
+	notifications *NotificationUsecase
+	authClient    pb.AuthServiceClient
+	logger        *logger.Logger
+}
+
+func NewMentionUsecase(
+	repo repository.MentionRepository,
+	notifications *NotificationUsecase,
+	authClient pb.AuthServiceClient,
+	logger *logger.Logger,
+) *MentionUsecase {
+	return &MentionUsecase{repo: repo, notifications: notifications, authClient: authClient, logger: logger}
+}
+
+// Render переводит markdown в HTML, делая ссылками упоминания существующих
+// пользователей, и возвращает упомянутых в порядке появления. Если
+// auth-сервис недоступен, упоминания остаются текстом: пост от этого не
+// должен падать.
+func (uc *MentionUsecase) Render(ctx context.Context, content string) (string, []entity.Mention) {
+	if uc == nil {
+		return markdown.Render(content), nil
+	}
+	names := markdown.Mentions(content)
+	if len(names) == 0 {
+		return markdown.Render(content), nil
+	}
+	if len(names) > mention.Max {
+		names = names[:mention.Max]
+	}
+
+	resp, err := uc.authClient.GetUsersByUsernames(ctx, &pb.GetUsersByUsernamesRequest{Usernames: names})
+	if err != nil || resp == nil {
+		uc.logger.Errorf("mentions: failed to resolve usernames: %v", err)
+		return markdown.Render(content), nil
+	}
+	found := make(map[string]int64, len(resp.Users))
+	for _, user := range resp.Users {
+		if user != nil {
+			found[user.Username] = user.Id
+		}
+	}
+
+	var mentions []entity.Mention
+	for _, name := range names {
+		if id, ok := found[name]; ok {
+			mentions = append(mentions, entity.Mention{UserID: id, Username: name})
+		}
+	}
+	contentHTML := markdown.RenderWith(content, markdown.Options{MentionURL: func(username string) (string, bool) {
+		_, ok := found[username]
+		return mention.URL(username), ok
+	}})
+	return contentHTML, mentions
+}
+
+// Record сохраняет упоминания в subject и уведомляет тех, кто упомянут там
+// впервые: после правки текста старые упоминания не повторяются. Сам себя
+// автор не упоминает. Ошибки только пишутся в лог — текст уже сохранен.
+func (uc *MentionUsecase) Record(ctx context.Context, author *authmw.Principal, subject entity.Subject, mentions []entity.Mention) {
+	if uc == nil {
+		return
+	}
+	others := make([]entity.Mention, 0, len(mentions))
+	for _, mention := range mentions {
+		if mention.UserID != author.UserID {
+			others = append(others, mention)
+		}
+	}
+	if len(others) == 0 {
+		return
+	}
+
+	added, err := uc.repo.AddMentions(ctx, author.UserID, subject, others)
+	if err != nil {
+		uc.logger.Errorf("mentions: failed to save: %v", err)
+		return
+	}
+	if len(added) == 0 {
+		return
+	}
+
+	userIDs := make([]int64, len(added))
+	for i, mention := range added {
+		userIDs[i] = mention.UserID
+	}
+	n := entity.Notification{
+		Type:      entity.NotificationMention,
+		ActorID:   author.UserID,
+		ActorName: author.Username,
+		Subject:   subject,
+	}
+	if n.ActorName == "" {
+		resp, err := uc.authClient.GetUser(ctx, &pb.GetUserRequest{Id: author.UserID})
+		if err == nil && resp != nil && resp.User != nil {
+			n.ActorName = resp.User.Username
+		}
+	}
+	uc.notifications.Publish(ctx, n, userIDs...)
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend.com/forum/authmw"
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestMentionUsecase_Render(t *testing.T) {
+	var asked []string
+	authClient := &MockAuthServiceClient{
+		GetUsersByUsernamesFunc: func(ctx context.Context, in *pb.GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+			asked = in.Usernames
+			return &pb.GetUsersResponse{Users: []*pb.User{{Id: 5, Username: "alice"}}}, nil
+		},
+	}
+	uc := NewMentionUsecase(&MockMentionRepository{}, nil, authClient, NewMockLogger())
+
+	html, mentions := uc.Render(context.Background(), "hi @alice and @ghost, `@code`")
+	assert.Equal(t, []string{"alice", "ghost"}, asked)
+	assert.Equal(t, []entity.Mention{{UserID: 5, Username: "alice"}}, mentions)
+	assert.Contains(t, html, `<a href="/users/alice"`)
+	assert.Contains(t, html, "@ghost")
+	assert.NotContains(t, html, `href="/users/ghost"`)
+
+	t.Run("Without mentions auth is not called", func(t *testing.T) {
+		asked = nil
+		html, mentions := uc.Render(context.Background(), "plain text")
+		assert.Nil(t, asked)
+		assert.Nil(t, mentions)
+		assert.Equal(t, "<p>plain text</p>\n", html)
+	})
+
+	t.Run("Auth failure renders plain text", func(t *testing.T) {
+		uc := NewMentionUsecase(&MockMentionRepository{}, nil, &MockAuthServiceClient{
+			GetUsersByUsernamesFunc: func(ctx context.Context, in *pb.GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+				return nil, errors.New("unavailable")
+			},
+		}, NewMockLogger())
+		html, mentions := uc.Render(context.Background(), "hi @alice")
+		assert.Nil(t, mentions)
+		assert.Equal(t, "<p>hi @alice</p>\n", html)
+	})
+
+	t.Run("Nil usecase", func(t *testing.T) {
+		var uc *MentionUsecase
+		html, mentions := uc.Render(context.Background(), "hi @alice")
+		assert.Nil(t, mentions)
+		assert.Equal(t, "<p>hi @alice</p>\n", html)
+	})
+}
+
+func TestMentionUsecase_Record(t *testing.T) {
+	postID := int64(1)
+	subject := entity.Subject{PostID: &postID}
+	var saved []entity.Mention
+	var notified entity.Notification
+	var notifiedIDs []int64
+
+	repo := &MockMentionRepository{
+		AddMentionsFunc: func(ctx context.Context, authorID int64, s entity.Subject, mentions []entity.Mention) ([]entity.Mention, error) {
+			assert.Equal(t, int64(2), authorID)
+			assert.Equal(t, subject, s)
+			saved = mentions
+			// bob уже был упомянут в посте раньше.
+			return mentions[:1], nil
+		},
+	}
+	notifications := NewNotificationUsecase(&MockNotificationRepository{
+		CreateNotificationsFunc: func(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+			notified, notifiedIDs = n, userIDs
+			return []*entity.Notification{}, nil
+		},
+	}, NewMockLogger())
+	authClient := &MockAuthServiceClient{
+		GetUserFunc: func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
+			return &pb.GetUserResponse{User: &pb.User{Id: in.Id, Username: "carol"}}, nil
+		},
+	}
+	uc := NewMentionUsecase(repo, notifications, authClient, NewMockLogger())
+
+	uc.Record(context.Background(), &authmw.Principal{UserID: 2}, subject, []entity.Mention{
+		{UserID: 5, Username: "alice"},
+		{UserID: 2, Username: "carol"},
+		{UserID: 6, Username: "bob"},
+	})
+	assert.Equal(t, []entity.Mention{{UserID: 5, Username: "alice"}, {UserID: 6, Username: "bob"}}, saved)
+	assert.Equal(t, []int64{5}, notifiedIDs)
+	assert.Equal(t, entity.Notification{
+		Type: entity.NotificationMention, ActorID: 2, ActorName: "carol", Subject: subject,
+	}, notified)
+
+	t.Run("Only self mention", func(t *testing.T) {
+		saved = nil
+		uc.Record(context.Background(), &authmw.Principal{UserID: 2, Username: "carol"}, subject,
+			[]entity.Mention{{UserID: 2, Username: "carol"}})
+		assert.Nil(t, saved)
+	})
+}
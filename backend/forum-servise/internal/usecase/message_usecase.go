@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+)
+
+var ErrEmptyMessage = errors.New("message content is required")
+
+type MessageUsecase struct {
+	messageRepo repository.MessageRepository
+	topicRepo   repository.TopicRepository
+}
+
+type MessageUsecaseInterface interface {
+	CreateMessage(ctx context.Context, topicID, userID int64, content string) (*entity.Message, error)
+	GetMessage(ctx context.Context, id int64) (*entity.Message, error)
+}
+
+func NewMessageUsecase(
+	messageRepo repository.MessageRepository,
+	topicRepo repository.TopicRepository,
+) *MessageUsecase {
+	return &MessageUsecase{
+		messageRepo: messageRepo,
+		topicRepo:   topicRepo,
+	}
+}
+
+func (uc *MessageUsecase) CreateMessage(ctx context.Context, topicID, userID int64, content string) (*entity.Message, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, ErrEmptyMessage
+	}
+
+	if _, err := uc.topicRepo.GetTopicByID(ctx, topicID); err != nil {
+		return nil, err
+	}
+
+	message := &entity.Message{
+		TopicID:   topicID,
+		UserID:    userID,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}
+
+	id, err := uc.messageRepo.CreateMessage(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+
+	message.ID = id
+	return message, nil
+}
+
+func (uc *MessageUsecase) GetMessage(ctx context.Context, id int64) (*entity.Message, error) {
+	return uc.messageRepo.GetMessageByID(ctx, id)
+}
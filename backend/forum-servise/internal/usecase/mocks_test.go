@@ -2,18 +2,43 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
 	"google.golang.org/grpc"
 )
 
 type MockPostRepository struct {
 	CreatePostFunc  func(ctx context.Context, post *entity.Post) (int64, error)
-	GetPostsFunc    func(ctx context.Context) ([]*entity.Post, error)
+	GetPostsFunc    func(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error)
+	CountPostsFunc  func(ctx context.Context, query entity.PostListQuery) (int64, error)
 	GetPostByIDFunc func(ctx context.Context, id int64) (*entity.Post, error)
 	DeletePostFunc  func(ctx context.Context, postID, authorID int64, role string) error
-	UpdatePostFunc  func(ctx context.Context, postID, authorID int64, role, title, content string) (*entity.Post, error)
+	UpdatePostFunc  func(ctx context.Context, postID, authorID int64, role, title, content, contentHTML string, tags []string) (*entity.Post, error)
+
+	GetPostsByTopicIDFunc func(ctx context.Context, topicID int64) ([]*entity.Post, error)
+
+	RestorePostFunc       func(ctx context.Context, id int64) error
+	GetDeletedPostsFunc   func(ctx context.Context, limit, offset int) ([]*entity.Post, error)
+	CountDeletedPostsFunc func(ctx context.Context) (int64, error)
+	PurgeDeletedPostsFunc func(ctx context.Context, before time.Time, limit int) (int64, []string, error)
+
+	GetRevisionsFunc   func(ctx context.Context, postID int64, limit, offset int) ([]*entity.PostRevision, error)
+	CountRevisionsFunc func(ctx context.Context, postID int64) (int64, error)
+	GetRevisionFunc    func(ctx context.Context, postID int64, revision int) (*entity.PostRevision, error)
+	RollbackPostFunc   func(ctx context.Context, postID int64, revision int, editorID int64, contentHTML string) (*entity.Post, error)
+
+	VotePostFunc     func(ctx context.Context, postID, userID int64, value int) (int, error)
+	GetPostVotesFunc func(ctx context.Context, userID int64, postIDs []int64) (map[int64]int, error)
+
+	TogglePostReactionFunc func(ctx context.Context, postID, userID int64, emoji string) (bool, error)
+	GetPostReactionsFunc   func(ctx context.Context, viewerID int64, postIDs []int64) (map[int64][]entity.Reaction, error)
+
+	GetPostTagsFunc func(ctx context.Context, postIDs []int64) (map[int64][]string, error)
+
+	GetPostAttachmentsFunc func(ctx context.Context, postIDs []int64) (map[int64][]entity.Attachment, error)
 }
 
 func (m *MockPostRepository) CreatePost(ctx context.Context, post *entity.Post) (int64, error) {
@@ -23,13 +48,20 @@ func (m *MockPostRepository) CreatePost(ctx context.Context, post *entity.Post)
 	return 0, nil
 }
 
-func (m *MockPostRepository) GetPosts(ctx context.Context) ([]*entity.Post, error) {
+func (m *MockPostRepository) GetPosts(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error) {
 	if m.GetPostsFunc != nil {
-		return m.GetPostsFunc(ctx)
+		return m.GetPostsFunc(ctx, query)
 	}
 	return nil, nil
 }
 
+func (m *MockPostRepository) CountPosts(ctx context.Context, query entity.PostListQuery) (int64, error) {
+	if m.CountPostsFunc != nil {
+		return m.CountPostsFunc(ctx, query)
+	}
+	return 0, nil
+}
+
 func (m *MockPostRepository) GetPostByID(ctx context.Context, id int64) (*entity.Post, error) {
 	if m.GetPostByIDFunc != nil {
 		return m.GetPostByIDFunc(ctx, id)
@@ -44,18 +76,129 @@ func (m *MockPostRepository) DeletePost(ctx context.Context, postID, authorID in
 	return nil
 }
 
-func (m *MockPostRepository) UpdatePost(ctx context.Context, postID, authorID int64, role, title, content string) (*entity.Post, error) {
+func (m *MockPostRepository) UpdatePost(ctx context.Context, postID, authorID int64, role, title, content, contentHTML string, tags []string) (*entity.Post, error) {
 	if m.UpdatePostFunc != nil {
-		return m.UpdatePostFunc(ctx, postID, authorID, role, title, content)
+		return m.UpdatePostFunc(ctx, postID, authorID, role, title, content, contentHTML, tags)
+	}
+	return nil, nil
+}
+
+func (m *MockPostRepository) GetPostsByTopicID(ctx context.Context, topicID int64) ([]*entity.Post, error) {
+	if m.GetPostsByTopicIDFunc != nil {
+		return m.GetPostsByTopicIDFunc(ctx, topicID)
+	}
+	return nil, nil
+}
+
+func (m *MockPostRepository) RestorePost(ctx context.Context, id int64) error {
+	if m.RestorePostFunc != nil {
+		return m.RestorePostFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *MockPostRepository) GetDeletedPosts(ctx context.Context, limit, offset int) ([]*entity.Post, error) {
+	if m.GetDeletedPostsFunc != nil {
+		return m.GetDeletedPostsFunc(ctx, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockPostRepository) CountDeletedPosts(ctx context.Context) (int64, error) {
+	if m.CountDeletedPostsFunc != nil {
+		return m.CountDeletedPostsFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *MockPostRepository) PurgeDeletedPosts(ctx context.Context, before time.Time, limit int) (int64, []string, error) {
+	if m.PurgeDeletedPostsFunc != nil {
+		return m.PurgeDeletedPostsFunc(ctx, before, limit)
+	}
+	return 0, nil, nil
+}
+
+func (m *MockPostRepository) GetRevisions(ctx context.Context, postID int64, limit, offset int) ([]*entity.PostRevision, error) {
+	if m.GetRevisionsFunc != nil {
+		return m.GetRevisionsFunc(ctx, postID, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockPostRepository) CountRevisions(ctx context.Context, postID int64) (int64, error) {
+	if m.CountRevisionsFunc != nil {
+		return m.CountRevisionsFunc(ctx, postID)
+	}
+	return 0, nil
+}
+
+func (m *MockPostRepository) GetRevision(ctx context.Context, postID int64, revision int) (*entity.PostRevision, error) {
+	if m.GetRevisionFunc != nil {
+		return m.GetRevisionFunc(ctx, postID, revision)
+	}
+	return nil, nil
+}
+
+func (m *MockPostRepository) RollbackPost(ctx context.Context, postID int64, revision int, editorID int64, contentHTML string) (*entity.Post, error) {
+	if m.RollbackPostFunc != nil {
+		return m.RollbackPostFunc(ctx, postID, revision, editorID, contentHTML)
 	}
 	return nil, nil
 }
 
+func (m *MockPostRepository) VotePost(ctx context.Context, postID, userID int64, value int) (int, error) {
+	if m.VotePostFunc != nil {
+		return m.VotePostFunc(ctx, postID, userID, value)
+	}
+	return 0, nil
+}
+
+func (m *MockPostRepository) GetPostVotes(ctx context.Context, userID int64, postIDs []int64) (map[int64]int, error) {
+	if m.GetPostVotesFunc != nil {
+		return m.GetPostVotesFunc(ctx, userID, postIDs)
+	}
+	return map[int64]int{}, nil
+}
+
+func (m *MockPostRepository) TogglePostReaction(ctx context.Context, postID, userID int64, emoji string) (bool, error) {
+	if m.TogglePostReactionFunc != nil {
+		return m.TogglePostReactionFunc(ctx, postID, userID, emoji)
+	}
+	return false, nil
+}
+
+func (m *MockPostRepository) GetPostReactions(ctx context.Context, viewerID int64, postIDs []int64) (map[int64][]entity.Reaction, error) {
+	if m.GetPostReactionsFunc != nil {
+		return m.GetPostReactionsFunc(ctx, viewerID, postIDs)
+	}
+	return map[int64][]entity.Reaction{}, nil
+}
+
+func (m *MockPostRepository) GetPostTags(ctx context.Context, postIDs []int64) (map[int64][]string, error) {
+	if m.GetPostTagsFunc != nil {
+		return m.GetPostTagsFunc(ctx, postIDs)
+	}
+	return map[int64][]string{}, nil
+}
+
+func (m *MockPostRepository) GetPostAttachments(ctx context.Context, postIDs []int64) (map[int64][]entity.Attachment, error) {
+	if m.GetPostAttachmentsFunc != nil {
+		return m.GetPostAttachmentsFunc(ctx, postIDs)
+	}
+	return map[int64][]entity.Attachment{}, nil
+}
+
 type MockAuthServiceClient struct {
-	ValidateTokenFunc func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error)
-	GetUserFunc       func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error)
-	LoginFunc         func(ctx context.Context, in *pb.LoginRequest, opts ...grpc.CallOption) (*pb.LoginResponse, error)
-	RegisterFunc      func(ctx context.Context, in *pb.RegisterRequest, opts ...grpc.CallOption) (*pb.RegisterResponse, error)
+	ValidateTokenFunc       func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error)
+	GetUserFunc             func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error)
+	GetUsersFunc            func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error)
+	GetUsersByUsernamesFunc func(ctx context.Context, in *pb.GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error)
+	LoginFunc               func(ctx context.Context, in *pb.LoginRequest, opts ...grpc.CallOption) (*pb.LoginResponse, error)
+	RegisterFunc            func(ctx context.Context, in *pb.RegisterRequest, opts ...grpc.CallOption) (*pb.RegisterResponse, error)
+	RefreshFunc             func(ctx context.Context, in *pb.RefreshRequest, opts ...grpc.CallOption) (*pb.RefreshResponse, error)
+	LogoutFunc              func(ctx context.Context, in *pb.LogoutRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error)
+	LogoutAllFunc           func(ctx context.Context, in *pb.LogoutRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error)
+	RevokeUserSessionsFunc  func(ctx context.Context, in *pb.RevokeUserSessionsRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error)
 }
 
 func (m *MockAuthServiceClient) ValidateToken(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
@@ -72,6 +215,20 @@ func (m *MockAuthServiceClient) GetUser(ctx context.Context, in *pb.GetUserReque
 	return nil, nil
 }
 
+func (m *MockAuthServiceClient) GetUsers(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc(ctx, in, opts...)
+	}
+	return nil, nil
+}
+
+func (m *MockAuthServiceClient) GetUsersByUsernames(ctx context.Context, in *pb.GetUsersByUsernamesRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+	if m.GetUsersByUsernamesFunc != nil {
+		return m.GetUsersByUsernamesFunc(ctx, in, opts...)
+	}
+	return nil, nil
+}
+
 func (m *MockAuthServiceClient) Login(ctx context.Context, in *pb.LoginRequest, opts ...grpc.CallOption) (*pb.LoginResponse, error) {
 	if m.LoginFunc != nil {
 		return m.LoginFunc(ctx, in, opts...)
@@ -85,3 +242,335 @@ func (m *MockAuthServiceClient) Register(ctx context.Context, in *pb.RegisterReq
 	}
 	return nil, nil
 }
+
+func (m *MockAuthServiceClient) Refresh(ctx context.Context, in *pb.RefreshRequest, opts ...grpc.CallOption) (*pb.RefreshResponse, error) {
+	if m.RefreshFunc != nil {
+		return m.RefreshFunc(ctx, in, opts...)
+	}
+	return nil, nil
+}
+
+func (m *MockAuthServiceClient) Logout(ctx context.Context, in *pb.LogoutRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error) {
+	if m.LogoutFunc != nil {
+		return m.LogoutFunc(ctx, in, opts...)
+	}
+	return nil, nil
+}
+
+func (m *MockAuthServiceClient) LogoutAll(ctx context.Context, in *pb.LogoutRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error) {
+	if m.LogoutAllFunc != nil {
+		return m.LogoutAllFunc(ctx, in, opts...)
+	}
+	return nil, nil
+}
+
+func (m *MockAuthServiceClient) RevokeUserSessions(ctx context.Context, in *pb.RevokeUserSessionsRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error) {
+	if m.RevokeUserSessionsFunc != nil {
+		return m.RevokeUserSessionsFunc(ctx, in, opts...)
+	}
+	return nil, nil
+}
+
+type MockCategoryRepository struct {
+	CreateCategoryFunc  func(ctx context.Context, category *entity.Category) (int64, error)
+	GetCategoryByIDFunc func(ctx context.Context, id int64) (*entity.Category, error)
+	GetCategoriesFunc   func(ctx context.Context) ([]entity.Category, error)
+	UpdateCategoryFunc  func(ctx context.Context, category *entity.Category) error
+	DeleteCategoryFunc  func(ctx context.Context, id int64) error
+}
+
+func (m *MockCategoryRepository) CreateCategory(ctx context.Context, category *entity.Category) (int64, error) {
+	if m.CreateCategoryFunc != nil {
+		return m.CreateCategoryFunc(ctx, category)
+	}
+	return 0, nil
+}
+
+func (m *MockCategoryRepository) GetCategoryByID(ctx context.Context, id int64) (*entity.Category, error) {
+	if m.GetCategoryByIDFunc != nil {
+		return m.GetCategoryByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockCategoryRepository) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	if m.GetCategoriesFunc != nil {
+		return m.GetCategoriesFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *MockCategoryRepository) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	if m.UpdateCategoryFunc != nil {
+		return m.UpdateCategoryFunc(ctx, category)
+	}
+	return nil
+}
+
+func (m *MockCategoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+	if m.DeleteCategoryFunc != nil {
+		return m.DeleteCategoryFunc(ctx, id)
+	}
+	return nil
+}
+
+type MockTopicRepository struct {
+	CreateTopicFunc  func(ctx context.Context, topic *entity.Topic) (int64, error)
+	GetTopicByIDFunc func(ctx context.Context, id int64) (*entity.Topic, error)
+
+	GetTopicsByCategoryIDFunc func(ctx context.Context, categoryID int64) ([]entity.Topic, error)
+	UpdateTopicFunc           func(ctx context.Context, topic *entity.Topic) error
+	DeleteTopicFunc           func(ctx context.Context, id int64) error
+}
+
+func (m *MockTopicRepository) CreateTopic(ctx context.Context, topic *entity.Topic) (int64, error) {
+	if m.CreateTopicFunc != nil {
+		return m.CreateTopicFunc(ctx, topic)
+	}
+	return 0, nil
+}
+
+func (m *MockTopicRepository) GetTopicByID(ctx context.Context, id int64) (*entity.Topic, error) {
+	if m.GetTopicByIDFunc != nil {
+		return m.GetTopicByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockTopicRepository) GetTopicsByCategoryID(ctx context.Context, categoryID int64) ([]entity.Topic, error) {
+	if m.GetTopicsByCategoryIDFunc != nil {
+		return m.GetTopicsByCategoryIDFunc(ctx, categoryID)
+	}
+	return nil, nil
+}
+
+func (m *MockTopicRepository) UpdateTopic(ctx context.Context, topic *entity.Topic) error {
+	if m.UpdateTopicFunc != nil {
+		return m.UpdateTopicFunc(ctx, topic)
+	}
+	return nil
+}
+
+func (m *MockTopicRepository) DeleteTopic(ctx context.Context, id int64) error {
+	if m.DeleteTopicFunc != nil {
+		return m.DeleteTopicFunc(ctx, id)
+	}
+	return nil
+}
+
+type MockSearchRepository struct {
+	SearchFunc      func(ctx context.Context, query entity.SearchQuery) ([]*entity.SearchResult, error)
+	CountSearchFunc func(ctx context.Context, query entity.SearchQuery) (int64, error)
+}
+
+func (m *MockSearchRepository) Search(ctx context.Context, query entity.SearchQuery) ([]*entity.SearchResult, error) {
+	if m.SearchFunc != nil {
+		return m.SearchFunc(ctx, query)
+	}
+	return nil, nil
+}
+
+func (m *MockSearchRepository) CountSearch(ctx context.Context, query entity.SearchQuery) (int64, error) {
+	if m.CountSearchFunc != nil {
+		return m.CountSearchFunc(ctx, query)
+	}
+	return 0, nil
+}
+
+type MockTagRepository struct {
+	ListTagsFunc    func(ctx context.Context, limit, offset int) ([]*entity.Tag, error)
+	CountTagsFunc   func(ctx context.Context) (int64, error)
+	SuggestTagsFunc func(ctx context.Context, prefix string, limit int) ([]*entity.Tag, error)
+	RenameTagFunc   func(ctx context.Context, name, newName string) error
+	MergeTagsFunc   func(ctx context.Context, source, target string) error
+}
+
+func (m *MockTagRepository) ListTags(ctx context.Context, limit, offset int) ([]*entity.Tag, error) {
+	if m.ListTagsFunc != nil {
+		return m.ListTagsFunc(ctx, limit, offset)
+	}
+	return nil, nil
+}
+
+func (m *MockTagRepository) CountTags(ctx context.Context) (int64, error) {
+	if m.CountTagsFunc != nil {
+		return m.CountTagsFunc(ctx)
+	}
+	return 0, nil
+}
+
+func (m *MockTagRepository) SuggestTags(ctx context.Context, prefix string, limit int) ([]*entity.Tag, error) {
+	if m.SuggestTagsFunc != nil {
+		return m.SuggestTagsFunc(ctx, prefix, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockTagRepository) RenameTag(ctx context.Context, name, newName string) error {
+	if m.RenameTagFunc != nil {
+		return m.RenameTagFunc(ctx, name, newName)
+	}
+	return nil
+}
+
+func (m *MockTagRepository) MergeTags(ctx context.Context, source, target string) error {
+	if m.MergeTagsFunc != nil {
+		return m.MergeTagsFunc(ctx, source, target)
+	}
+	return nil
+}
+
+type MockContentRepository struct {
+	GetUnrenderedFunc  func(ctx context.Context, table repository.ContentTable, afterID int64, limit int) ([]entity.ContentSource, error)
+	SetContentHTMLFunc func(ctx context.Context, table repository.ContentTable, id int64, contentHTML string) error
+}
+
+func (m *MockContentRepository) GetUnrendered(ctx context.Context, table repository.ContentTable, afterID int64, limit int) ([]entity.ContentSource, error) {
+	if m.GetUnrenderedFunc != nil {
+		return m.GetUnrenderedFunc(ctx, table, afterID, limit)
+	}
+	return nil, nil
+}
+
+func (m *MockContentRepository) SetContentHTML(ctx context.Context, table repository.ContentTable, id int64, contentHTML string) error {
+	if m.SetContentHTMLFunc != nil {
+		return m.SetContentHTMLFunc(ctx, table, id, contentHTML)
+	}
+	return nil
+}
+
+type MockAttachmentRepository struct {
+	CreateAttachmentFunc     func(ctx context.Context, attachment *entity.Attachment, limit int) error
+	GetAttachmentFunc        func(ctx context.Context, id int64) (*entity.Attachment, error)
+	CountPostAttachmentsFunc func(ctx context.Context, postID int64) (int, error)
+	DeleteAttachmentFunc     func(ctx context.Context, id int64) error
+}
+
+func (m *MockAttachmentRepository) CreateAttachment(ctx context.Context, attachment *entity.Attachment, limit int) error {
+	if m.CreateAttachmentFunc != nil {
+		return m.CreateAttachmentFunc(ctx, attachment, limit)
+	}
+	return nil
+}
+
+func (m *MockAttachmentRepository) GetAttachment(ctx context.Context, id int64) (*entity.Attachment, error) {
+	if m.GetAttachmentFunc != nil {
+		return m.GetAttachmentFunc(ctx, id)
+	}
+	return nil, repository.ErrAttachmentNotFound
+}
+
+func (m *MockAttachmentRepository) CountPostAttachments(ctx context.Context, postID int64) (int, error) {
+	if m.CountPostAttachmentsFunc != nil {
+		return m.CountPostAttachmentsFunc(ctx, postID)
+	}
+	return 0, nil
+}
+
+func (m *MockAttachmentRepository) DeleteAttachment(ctx context.Context, id int64) error {
+	if m.DeleteAttachmentFunc != nil {
+		return m.DeleteAttachmentFunc(ctx, id)
+	}
+	return nil
+}
+
+type MockMentionRepository struct {
+	AddMentionsFunc func(ctx context.Context, authorID int64, subject entity.Subject, mentions []entity.Mention) ([]entity.Mention, error)
+}
+
+func (m *MockMentionRepository) AddMentions(ctx context.Context, authorID int64, subject entity.Subject, mentions []entity.Mention) ([]entity.Mention, error) {
+	if m.AddMentionsFunc != nil {
+		return m.AddMentionsFunc(ctx, authorID, subject, mentions)
+	}
+	return mentions, nil
+}
+
+type MockNotificationRepository struct {
+	CreateNotificationsFunc func(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error)
+	GetNotificationsFunc    func(ctx context.Context, query entity.NotificationQuery) ([]*entity.Notification, error)
+	CountNotificationsFunc  func(ctx context.Context, userID int64, unreadOnly bool) (int64, error)
+	MarkReadFunc            func(ctx context.Context, userID, id int64) error
+	MarkAllReadFunc         func(ctx context.Context, userID int64) (int64, error)
+	GetPreferencesFunc      func(ctx context.Context, userID int64) ([]entity.NotificationPreference, error)
+	SetPreferenceFunc       func(ctx context.Context, userID int64, pref entity.NotificationPreference) error
+}
+
+func (m *MockNotificationRepository) CreateNotifications(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+	if m.CreateNotificationsFunc != nil {
+		return m.CreateNotificationsFunc(ctx, n, userIDs)
+	}
+	return []*entity.Notification{}, nil
+}
+
+func (m *MockNotificationRepository) GetNotifications(ctx context.Context, query entity.NotificationQuery) ([]*entity.Notification, error) {
+	if m.GetNotificationsFunc != nil {
+		return m.GetNotificationsFunc(ctx, query)
+	}
+	return []*entity.Notification{}, nil
+}
+
+func (m *MockNotificationRepository) CountNotifications(ctx context.Context, userID int64, unreadOnly bool) (int64, error) {
+	if m.CountNotificationsFunc != nil {
+		return m.CountNotificationsFunc(ctx, userID, unreadOnly)
+	}
+	return 0, nil
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, userID, id int64) error {
+	if m.MarkReadFunc != nil {
+		return m.MarkReadFunc(ctx, userID, id)
+	}
+	return nil
+}
+
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	if m.MarkAllReadFunc != nil {
+		return m.MarkAllReadFunc(ctx, userID)
+	}
+	return 0, nil
+}
+
+func (m *MockNotificationRepository) GetPreferences(ctx context.Context, userID int64) ([]entity.NotificationPreference, error) {
+	if m.GetPreferencesFunc != nil {
+		return m.GetPreferencesFunc(ctx, userID)
+	}
+	return []entity.NotificationPreference{}, nil
+}
+
+func (m *MockNotificationRepository) SetPreference(ctx context.Context, userID int64, pref entity.NotificationPreference) error {
+	if m.SetPreferenceFunc != nil {
+		return m.SetPreferenceFunc(ctx, userID, pref)
+	}
+	return nil
+}
+
+type MockChatMessageRepository struct {
+	CreateChatMessageFunc    func(ctx context.Context, msg *entity.ChatMessage) (int64, error)
+	GetChatMessagesAfterFunc func(ctx context.Context, roomID, afterID int64, limit int) ([]entity.ChatMessage, error)
+	GetChatRoomAccessFunc    func(ctx context.Context, roomID, userID int64) (bool, bool, error)
+}
+
+func (m *MockChatMessageRepository) CreateChatMessage(ctx context.Context, msg *entity.ChatMessage) (int64, error) {
+	if m.CreateChatMessageFunc != nil {
+		return m.CreateChatMessageFunc(ctx, msg)
+	}
+	return 0, nil
+}
+
+func (m *MockChatMessageRepository) GetChatMessagesAfter(ctx context.Context, roomID, afterID int64, limit int) ([]entity.ChatMessage, error) {
+	if m.GetChatMessagesAfterFunc != nil {
+		return m.GetChatMessagesAfterFunc(ctx, roomID, afterID, limit)
+	}
+	return []entity.ChatMessage{}, nil
+}
+
+func (m *MockChatMessageRepository) GetLastChatMessageID(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockChatMessageRepository) GetChatRoomAccess(ctx context.Context, roomID, userID int64) (bool, bool, error) {
+	if m.GetChatRoomAccessFunc != nil {
+		return m.GetChatRoomAccessFunc(ctx, roomID, userID)
+	}
+	return false, false, nil
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+)
+
+var ErrUnknownNotificationType = errors.New("unknown notification type")
+
+// NotificationUsecase рассылает уведомления с учетом настроек получателей
+// и отдает их пользователю. Нулевой *NotificationUsecase ничего не
+// рассылает: Publish на нем можно вызывать без проверок.
+type NotificationUsecase struct {
+	repo   repository.NotificationRepository
+	logger *logger.Logger
+}
+
+func NewNotificationUsecase(repo repository.NotificationRepository, logger *logger.Logger) *NotificationUsecase {
+	return &NotificationUsecase{repo: repo, logger: logger}
+}
+
+// Notify создает уведомление n каждому из userIDs. Автор действия о своем
+// действии не уведомляется, повторы отбрасываются, отключившие тип n.Type
+// уведомление не получают.
+func (uc *NotificationUsecase) Notify(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+	recipients := make([]int64, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != n.ActorID && !slices.Contains(recipients, id) {
+			recipients = append(recipients, id)
+		}
+	}
+	if len(recipients) == 0 {
+		return []*entity.Notification{}, nil
+	}
+	return uc.repo.CreateNotifications(ctx, n, recipients)
+}
+
+// Publish — Notify для событий, которые уже произошли: ошибка только
+// пишется в лог.
+func (uc *NotificationUsecase) Publish(ctx context.Context, n entity.Notification, userIDs ...int64) {
+	if uc == nil {
+		return
+	}
+	if _, err := uc.Notify(ctx, n, userIDs); err != nil {
+		uc.logger.Errorf("notifications: failed to send %s: %v", n.Type, err)
+	}
+}
+
+// List возвращает страницу уведомлений пользователя, новые первыми.
+func (uc *NotificationUsecase) List(ctx context.Context, query entity.NotificationQuery) (*entity.NotificationPage, error) {
+	if err := validatePage(query.Limit, query.Offset); err != nil {
+		return nil, err
+	}
+
+	notifications, err := uc.repo.GetNotifications(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	total, err := uc.repo.CountNotifications(ctx, query.UserID, query.UnreadOnly)
+	if err != nil {
+		return nil, err
+	}
+	unread := total
+	if !query.UnreadOnly {
+		if unread, err = uc.UnreadCount(ctx, query.UserID); err != nil {
+			return nil, err
+		}
+	}
+	return &entity.NotificationPage{Notifications: notifications, Total: total, Unread: unread}, nil
+}
+
+func (uc *NotificationUsecase) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	return uc.repo.CountNotifications(ctx, userID, true)
+}
+
+// MarkRead отмечает уведомление прочитанным и возвращает, сколько
+// непрочитанных осталось.
+func (uc *NotificationUsecase) MarkRead(ctx context.Context, userID, id int64) (int64, error) {
+	if err := uc.repo.MarkRead(ctx, userID, id); err != nil {
+		return 0, err
+	}
+	return uc.UnreadCount(ctx, userID)
+}
+
+// MarkAllRead возвращает, сколько уведомлений было отмечено.
+func (uc *NotificationUsecase) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return uc.repo.MarkAllRead(ctx, userID)
+}
+
+// Preferences возвращает настройки пользователя по всем типам уведомлений.
+func (uc *NotificationUsecase) Preferences(ctx context.Context, userID int64) ([]entity.NotificationPreference, error) {
+	stored, err := uc.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make([]entity.NotificationPreference, len(entity.NotificationTypes))
+	for i, notificationType := range entity.NotificationTypes {
+		prefs[i] = entity.NotificationPreference{Type: notificationType, Enabled: true}
+		for _, pref := range stored {
+			if pref.Type == notificationType {
+				prefs[i].Enabled = pref.Enabled
+			}
+		}
+	}
+	return prefs, nil
+}
+
+// SetPreferences меняет настройки перечисленных типов, остальные остаются
+// прежними. Возвращает настройки по всем типам.
+func (uc *NotificationUsecase) SetPreferences(ctx context.Context, userID int64, prefs []entity.NotificationPreference) ([]entity.NotificationPreference, error) {
+	for _, pref := range prefs {
+		if !slices.Contains(entity.NotificationTypes, pref.Type) {
+			return nil, ErrUnknownNotificationType
+		}
+	}
+	for _, pref := range prefs {
+		if err := uc.repo.SetPreference(ctx, userID, pref); err != nil {
+			return nil, err
+		}
+	}
+	return uc.Preferences(ctx, userID)
+}
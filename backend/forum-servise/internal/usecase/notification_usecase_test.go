@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationUsecase_Notify(t *testing.T) {
+	var gotIDs []int64
+	calls := 0
+	uc := NewNotificationUsecase(&MockNotificationRepository{
+		CreateNotificationsFunc: func(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+			calls++
+			gotIDs = userIDs
+			created := make([]*entity.Notification, len(userIDs))
+			for i, id := range userIDs {
+				created[i] = &entity.Notification{UserID: id, Type: n.Type}
+			}
+			return created, nil
+		},
+	}, NewMockLogger())
+	n := entity.Notification{Type: entity.NotificationMention, ActorID: 1}
+
+	created, err := uc.Notify(context.Background(), n, []int64{5, 1, 6, 5})
+	require.NoError(t, err)
+	assert.Len(t, created, 2)
+	assert.Equal(t, []int64{5, 6}, gotIDs)
+
+	// Только автор — в базу не ходим.
+	created, err = uc.Notify(context.Background(), n, []int64{1})
+	require.NoError(t, err)
+	assert.Empty(t, created)
+	assert.Equal(t, 1, calls)
+}
+
+func TestNotificationUsecase_Publish_Nil(t *testing.T) {
+	var uc *NotificationUsecase
+	assert.NotPanics(t, func() {
+		uc.Publish(context.Background(), entity.Notification{Type: entity.NotificationComment}, 5)
+	})
+}
+
+func TestNotificationUsecase_List(t *testing.T) {
+	var counted []bool
+	var gotQuery entity.NotificationQuery
+	uc := NewNotificationUsecase(&MockNotificationRepository{
+		GetNotificationsFunc: func(ctx context.Context, query entity.NotificationQuery) ([]*entity.Notification, error) {
+			gotQuery = query
+			return []*entity.Notification{{ID: 2}, {ID: 1}}, nil
+		},
+		CountNotificationsFunc: func(ctx context.Context, userID int64, unreadOnly bool) (int64, error) {
+			counted = append(counted, unreadOnly)
+			if unreadOnly {
+				return 3, nil
+			}
+			return 12, nil
+		},
+	}, NewMockLogger())
+
+	page, err := uc.List(context.Background(), entity.NotificationQuery{UserID: 5, Limit: 2, Offset: 4})
+	require.NoError(t, err)
+	assert.Len(t, page.Notifications, 2)
+	assert.Equal(t, int64(12), page.Total)
+	assert.Equal(t, int64(3), page.Unread)
+	assert.Equal(t, int64(5), gotQuery.UserID)
+	assert.Equal(t, []bool{false, true}, counted)
+
+	// Только непрочитанные — total и есть unread, второй подсчет не нужен.
+	counted = nil
+	page, err = uc.List(context.Background(), entity.NotificationQuery{UserID: 5, UnreadOnly: true, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, int64(3), page.Unread)
+	assert.Equal(t, []bool{true}, counted)
+
+	_, err = uc.List(context.Background(), entity.NotificationQuery{UserID: 5, Limit: 0})
+	assert.ErrorIs(t, err, ErrInvalidPageSize)
+}
+
+func TestNotificationUsecase_MarkRead(t *testing.T) {
+	unread := int64(2)
+	uc := NewNotificationUsecase(&MockNotificationRepository{
+		MarkReadFunc: func(ctx context.Context, userID, id int64) error {
+			if id == 404 {
+				return repository.ErrNotificationNotFound
+			}
+			unread--
+			return nil
+		},
+		CountNotificationsFunc: func(ctx context.Context, userID int64, unreadOnly bool) (int64, error) {
+			return unread, nil
+		},
+	}, NewMockLogger())
+
+	left, err := uc.MarkRead(context.Background(), 5, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), left)
+
+	_, err = uc.MarkRead(context.Background(), 5, 404)
+	assert.ErrorIs(t, err, repository.ErrNotificationNotFound)
+}
+
+func TestNotificationUsecase_Preferences(t *testing.T) {
+	stored := map[string]bool{}
+	uc := NewNotificationUsecase(&MockNotificationRepository{
+		GetPreferencesFunc: func(ctx context.Context, userID int64) ([]entity.NotificationPreference, error) {
+			prefs := []entity.NotificationPreference{}
+			for notificationType, enabled := range stored {
+				prefs = append(prefs, entity.NotificationPreference{Type: notificationType, Enabled: enabled})
+			}
+			return prefs, nil
+		},
+		SetPreferenceFunc: func(ctx context.Context, userID int64, pref entity.NotificationPreference) error {
+			stored[pref.Type] = pref.Enabled
+			return nil
+		},
+	}, NewMockLogger())
+
+	prefs, err := uc.Preferences(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.NotificationPreference{
+		{Type: entity.NotificationComment, Enabled: true},
+		{Type: entity.NotificationReply, Enabled: true},
+		{Type: entity.NotificationMention, Enabled: true},
+		{Type: entity.NotificationModeration, Enabled: true},
+	}, prefs)
+
+	prefs, err = uc.SetPreferences(context.Background(), 5,
+		[]entity.NotificationPreference{{Type: entity.NotificationMention, Enabled: false}})
+	require.NoError(t, err)
+	assert.Equal(t, []entity.NotificationPreference{
+		{Type: entity.NotificationComment, Enabled: true},
+		{Type: entity.NotificationReply, Enabled: true},
+		{Type: entity.NotificationMention, Enabled: false},
+		{Type: entity.NotificationModeration, Enabled: true},
+	}, prefs)
+
+	_, err = uc.SetPreferences(context.Background(), 5, []entity.NotificationPreference{
+		{Type: entity.NotificationMention, Enabled: true},
+		{Type: "spam", Enabled: true},
+	})
+	assert.ErrorIs(t, err, ErrUnknownNotificationType)
+	assert.False(t, stored[entity.NotificationMention], "nothing is saved when a type is unknown")
+}
@@ -6,22 +6,43 @@ import (
 	"errors"
 	"time"
 
+	"backend.com/forum/authmw"
 	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
 )
 
+const (
+	DefaultPostsPageSize = 10
+	MaxPostsPageSize     = 100
+)
+
+var (
+	ErrInvalidPageSize       = errors.New("limit must be between 1 and 100")
+	ErrInvalidOffset         = errors.New("offset must not be negative")
+	ErrConflictingPagination = errors.New("cursor cannot be combined with page or offset")
+	ErrInvalidSort           = errors.New("sort must be one of new, top, hot")
+	ErrCursorNotSupported    = errors.New("cursor is only supported for sort=new")
+)
+
 type PostUsecase struct {
 	postRepo   repository.PostRepository
 	authClient pb.AuthServiceClient
+	users      *userDirectory
 	logger     *logger.Logger
+	// Mentions разбирает упоминания в тексте; nil — упоминания остаются текстом.
+	Mentions *MentionUsecase
+	// Notifications уведомляет автора, если его пост удалил администратор;
+	// nil — без уведомлений.
+	Notifications *NotificationUsecase
 }
 type PostUsecaseInterface interface {
-	CreatePost(ctx context.Context, token, title, content string) (*entity.Post, error)
-	GetPosts(ctx context.Context) ([]*entity.Post, map[int]string, error)
-	DeletePost(ctx context.Context, token string, postID int64) error
-	UpdatePost(ctx context.Context, token string, postID int64, title, content string) (*entity.Post, error)
+	CreatePost(ctx context.Context, author *authmw.Principal, title, content string, topicID *int64, tags []string) (*entity.Post, error)
+	GetPosts(ctx context.Context, query entity.PostListQuery) (*entity.PostPage, map[int]string, error)
+	GetPostsByTopic(ctx context.Context, topicID, viewerID int64) ([]*entity.Post, map[int]string, error)
+	DeletePost(ctx context.Context, user *authmw.Principal, postID int64) error
+	UpdatePost(ctx context.Context, user *authmw.Principal, postID int64, title, content string, tags []string) (*entity.Post, error)
 }
 
 func NewPostUsecase(
@@ -32,27 +53,33 @@ func NewPostUsecase(
 	return &PostUsecase{
 		postRepo:   postRepo,
 		authClient: authClient,
+		users:      newUserDirectory(authClient, DefaultUserCacheTTL),
 		logger:     logger,
 	}
 }
 
-func (uc *PostUsecase) CreatePost(ctx context.Context, token string, title, content string) (*entity.Post, error) {
-
-	validateResp, err := uc.authClient.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: token})
+// CreatePost создает пост с тегами tags; теги нормализуются, повторы
+// отбрасываются.
+func (uc *PostUsecase) CreatePost(ctx context.Context, author *authmw.Principal, title, content string, topicID *int64, tags []string) (*entity.Post, error) {
+	tags, err := postTags(tags)
 	if err != nil {
 		return nil, err
 	}
-	if !validateResp.Valid {
-		return nil, errors.New("invalid token")
-	}
-	userID := validateResp.UserId
 
+	contentHTML, mentions := uc.Mentions.Render(ctx, content)
 	post := &entity.Post{
-		Title:     title,
-		Content:   content,
-		AuthorID:  userID,
-		CreatedAt: time.Now(),
+		Title:       title,
+		Content:     content,
+		ContentHTML: contentHTML,
+		AuthorID:    author.UserID,
+		CreatedAt:   time.Now(),
+		TopicID:     topicID,
+		Tags:        tags,
 	}
+	if post.Tags == nil {
+		post.Tags = []string{}
+	}
+	post.Attachments = []entity.Attachment{}
 
 	id, err := uc.postRepo.CreatePost(ctx, post)
 	if err != nil {
@@ -60,54 +87,243 @@ func (uc *PostUsecase) CreatePost(ctx context.Context, token string, title, cont
 	}
 
 	post.ID = id
+	uc.Mentions.Record(ctx, author, entity.Subject{PostID: &post.ID}, mentions)
 	return post, nil
 }
 
-func (uc *PostUsecase) GetPosts(ctx context.Context) ([]*entity.Post, map[int]string, error) {
-	posts, err := uc.postRepo.GetPosts(ctx)
+// GetPosts возвращает страницу ленты. Нулевой Limit означает размер по умолчанию,
+// пустой Sort — new. NextCursor заполняется только для new и только если
+// за страницей есть еще посты. Since учитывается только в top. Теги фильтра
+// нормализуются так же, как теги постов; пустой TagMode — all.
+func (uc *PostUsecase) GetPosts(ctx context.Context, query entity.PostListQuery) (*entity.PostPage, map[int]string, error) {
+	if query.Limit == 0 {
+		query.Limit = DefaultPostsPageSize
+	}
+	if query.Limit < 0 || query.Limit > MaxPostsPageSize {
+		return nil, nil, ErrInvalidPageSize
+	}
+	if query.Offset < 0 {
+		return nil, nil, ErrInvalidOffset
+	}
+	if query.Cursor != nil && query.Offset > 0 {
+		return nil, nil, ErrConflictingPagination
+	}
+	switch query.Sort {
+	case "":
+		query.Sort = entity.PostSortNew
+	case entity.PostSortNew, entity.PostSortTop, entity.PostSortHot:
+	default:
+		return nil, nil, ErrInvalidSort
+	}
+	if query.Cursor != nil && query.Sort != entity.PostSortNew {
+		return nil, nil, ErrCursorNotSupported
+	}
+	if query.Sort != entity.PostSortTop {
+		query.Since = nil
+	}
+	switch query.TagMode {
+	case "":
+		query.TagMode = entity.TagMatchAll
+	case entity.TagMatchAll, entity.TagMatchAny:
+	default:
+		return nil, nil, ErrInvalidTagMode
+	}
+	var err error
+	if query.Tags, err = NormalizeTags(query.Tags); err != nil {
+		return nil, nil, err
+	}
+
+	limit := query.Limit
+	query.Limit++
+	posts, err := uc.postRepo.GetPosts(ctx, query)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	authorNames := make(map[int]string)
+	total, err := uc.postRepo.CountPosts(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	authorIDs := make([]int64, 0, len(posts))
+	page := &entity.PostPage{Posts: posts, Total: total}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		if query.Sort == entity.PostSortNew {
+			page.NextCursor = entity.PostCursorOf(page.Posts[limit-1]).String()
+		}
+	}
+
+	if err := uc.fillMyVotes(ctx, query.ViewerID, page.Posts); err != nil {
+		return nil, nil, err
+	}
+	if err := uc.fillReactions(ctx, query.ViewerID, page.Posts); err != nil {
+		return nil, nil, err
+	}
+	if err := uc.fillTags(ctx, page.Posts); err != nil {
+		return nil, nil, err
+	}
+	if err := uc.fillAttachments(ctx, page.Posts); err != nil {
+		return nil, nil, err
+	}
+
+	return page, uc.resolveAuthorNames(ctx, page.Posts), nil
+}
+
+// GetPostsByTopic возвращает посты темы; viewerID == 0 — аноним.
+func (uc *PostUsecase) GetPostsByTopic(ctx context.Context, topicID, viewerID int64) ([]*entity.Post, map[int]string, error) {
+	posts, err := uc.postRepo.GetPostsByTopicID(ctx, topicID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := uc.fillMyVotes(ctx, viewerID, posts); err != nil {
+		return nil, nil, err
+	}
+	if err := uc.fillReactions(ctx, viewerID, posts); err != nil {
+		return nil, nil, err
+	}
+	if err := uc.fillTags(ctx, posts); err != nil {
+		return nil, nil, err
+	}
+	if err := uc.fillAttachments(ctx, posts); err != nil {
+		return nil, nil, err
+	}
+
+	return posts, uc.resolveAuthorNames(ctx, posts), nil
+}
+
+// fillMyVotes проставляет постам голос viewerID. У анонима голосов нет.
+func (uc *PostUsecase) fillMyVotes(ctx context.Context, viewerID int64, posts []*entity.Post) error {
+	if viewerID == 0 || len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, 0, len(posts))
 	for _, post := range posts {
-		authorIDs = append(authorIDs, post.AuthorID)
+		postIDs = append(postIDs, post.ID)
+	}
+	votes, err := uc.postRepo.GetPostVotes(ctx, viewerID, postIDs)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		post.MyVote = votes[post.ID]
 	}
+	return nil
+}
 
-	for _, authorID := range authorIDs {
-		userResponse, err := uc.authClient.GetUser(ctx, &pb.GetUserRequest{
-			Id: authorID,
-		})
+// fillReactions проставляет постам реакции, отмечая реакции viewerID.
+func (uc *PostUsecase) fillReactions(ctx context.Context, viewerID int64, posts []*entity.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
 
-		if err == nil && userResponse.User != nil {
-			authorNames[int(authorID)] = userResponse.User.Username
-		}
+	postIDs := make([]int64, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	reactions, err := uc.postRepo.GetPostReactions(ctx, viewerID, postIDs)
+	if err != nil {
+		return err
 	}
+	for _, post := range posts {
+		post.Reactions = orEmpty(reactions[post.ID])
+	}
+	return nil
+}
 
+// fillTags проставляет постам их теги.
+func (uc *PostUsecase) fillTags(ctx context.Context, posts []*entity.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, 0, len(posts))
 	for _, post := range posts {
-		if _, exists := authorNames[int(post.AuthorID)]; !exists {
-			authorNames[int(post.AuthorID)] = "Unknown"
+		postIDs = append(postIDs, post.ID)
+	}
+	tags, err := uc.postRepo.GetPostTags(ctx, postIDs)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		post.Tags = tags[post.ID]
+		if post.Tags == nil {
+			post.Tags = []string{}
 		}
 	}
-
-	return posts, authorNames, nil
+	return nil
 }
-func (uc *PostUsecase) DeletePost(ctx context.Context, token string, postID int64) error {
-	validateResp, err := uc.authClient.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: token})
+
+// fillAttachments проставляет постам их вложения со ссылками на файлы.
+func (uc *PostUsecase) fillAttachments(ctx context.Context, posts []*entity.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	postIDs := make([]int64, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	attachments, err := uc.postRepo.GetPostAttachments(ctx, postIDs)
 	if err != nil {
 		return err
 	}
-	if !validateResp.Valid {
-		return errors.New("invalid token")
+	for _, post := range posts {
+		post.Attachments = attachments[post.ID]
+		if post.Attachments == nil {
+			post.Attachments = []entity.Attachment{}
+		}
+		for i := range post.Attachments {
+			setAttachmentURLs(&post.Attachments[i])
+		}
 	}
+	return nil
+}
+
+// postTags нормализует теги поста и проверяет их число.
+func postTags(raw []string) ([]string, error) {
+	tags, err := NormalizeTags(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > MaxPostTags {
+		return nil, ErrTooManyTags
+	}
+	return tags, nil
+}
+
+// resolveAuthorNames подписывает посты именами авторов за один вызов GetUsers.
+func (uc *PostUsecase) resolveAuthorNames(ctx context.Context, posts []*entity.Post) map[int]string {
+	authorIDs := make([]int64, 0, len(posts))
+	for _, post := range posts {
+		authorIDs = append(authorIDs, post.AuthorID)
+	}
+
+	found := uc.users.Usernames(ctx, authorIDs)
 
-	err = uc.postRepo.DeletePost(
+	authorNames := make(map[int]string, len(posts))
+	for _, post := range posts {
+		name, ok := found[post.AuthorID]
+		if !ok {
+			name = "Unknown"
+		}
+		authorNames[int(post.AuthorID)] = name
+	}
+	return authorNames
+}
+
+func (uc *PostUsecase) DeletePost(ctx context.Context, user *authmw.Principal, postID int64) error {
+	// Автор нужен только для уведомления о чужом посте, удаленном админом.
+	var post *entity.Post
+	if user.IsAdmin() && uc.Notifications != nil {
+		post, _ = uc.postRepo.GetPostByID(ctx, postID)
+	}
+
+	err := uc.postRepo.DeletePost(
 		ctx,
 		postID,
-		validateResp.UserId,
-		validateResp.Role,
+		user.UserID,
+		user.Role,
 	)
 
 	if err != nil {
@@ -121,32 +337,55 @@ func (uc *PostUsecase) DeletePost(ctx context.Context, token string, postID int6
 		}
 	}
 
+	if post != nil {
+		uc.Notifications.Publish(ctx, entity.Notification{
+			Type:      entity.NotificationModeration,
+			Action:    entity.ModerationPostDeleted,
+			ActorID:   user.UserID,
+			ActorName: user.Username,
+			Subject:   entity.Subject{PostID: &postID},
+		}, post.AuthorID)
+	}
 	return nil
 }
 
+// UpdatePost меняет текст поста, а с tags != nil — и его теги: пустой
+// список снимает все теги, nil оставляет прежние.
 func (uc *PostUsecase) UpdatePost(
 	ctx context.Context,
-	token string,
+	user *authmw.Principal,
 	postID int64,
 	title,
 	content string,
+	tags []string,
 ) (*entity.Post, error) {
-	validateResp, err := uc.authClient.ValidateToken(ctx, &pb.ValidateTokenRequest{Token: token})
+	tags, err := postTags(tags)
 	if err != nil {
 		return nil, err
 	}
-	if !validateResp.Valid {
-		return nil, errors.New("invalid token")
-	}
 
+	contentHTML, mentions := uc.Mentions.Render(ctx, content)
 	updatedPost, err := uc.postRepo.UpdatePost(
 		ctx,
 		postID,
-		validateResp.UserId,
-		validateResp.Role,
+		user.UserID,
+		user.Role,
 		title,
 		content,
+		contentHTML,
+		tags,
 	)
+	if err != nil {
+		return nil, err
+	}
+	uc.Mentions.Record(ctx, user, entity.Subject{PostID: &postID}, mentions)
 
-	return updatedPost, err
+	if tags == nil {
+		if err := uc.fillTags(ctx, []*entity.Post{updatedPost}); err != nil {
+			return nil, err
+		}
+	} else {
+		updatedPost.Tags = tags
+	}
+	return updatedPost, uc.fillAttachments(ctx, []*entity.Post{updatedPost})
 }
@@ -4,14 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
 	"testing"
 	"time"
 
+	"backend.com/forum/authmw"
 	pb "backend.com/forum/proto"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -34,7 +37,7 @@ func TestPostUsecase_UpdatePost(t *testing.T) {
 
 	tests := []struct {
 		name        string
-		token       string
+		user        *authmw.Principal
 		postID      int64
 		title       string
 		content     string
@@ -46,24 +49,16 @@ func TestPostUsecase_UpdatePost(t *testing.T) {
 	}{
 		{
 			name:    "Success - Author Update",
-			token:   "valid_token",
+			user:    &authmw.Principal{UserID: 1, Role: "user"},
 			postID:  1,
 			title:   "Updated Title",
 			content: "Updated Content",
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 1,
-							Role:   "user",
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
-					UpdatePostFunc: func(ctx context.Context, id, authorID int64, role, title, content string) (*entity.Post, error) {
+					UpdatePostFunc: func(ctx context.Context, id, authorID int64, role, title, content, contentHTML string, tags []string) (*entity.Post, error) {
 						return updatedPost, nil
 					},
 				}
@@ -73,24 +68,16 @@ func TestPostUsecase_UpdatePost(t *testing.T) {
 		},
 		{
 			name:    "Success - Admin Update",
-			token:   "valid_token",
+			user:    &authmw.Principal{UserID: 2, Role: "admin"},
 			postID:  1,
 			title:   "Updated Title",
 			content: "Updated Content",
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 2,
-							Role:   "admin",
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
-					UpdatePostFunc: func(ctx context.Context, id, authorID int64, role, title, content string) (*entity.Post, error) {
+					UpdatePostFunc: func(ctx context.Context, id, authorID int64, role, title, content, contentHTML string, tags []string) (*entity.Post, error) {
 						return updatedPost, nil
 					},
 				}
@@ -98,51 +85,18 @@ func TestPostUsecase_UpdatePost(t *testing.T) {
 			want:    updatedPost,
 			wantErr: false,
 		},
-		{
-			name:    "Invalid Token",
-			token:   "invalid_token",
-			postID:  1,
-			title:   "Updated Title",
-			content: "Updated Content",
-			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid: false,
-						}, nil
-					},
-				}
-			},
-			mockRepo: func() *MockPostRepository {
-				return &MockPostRepository{
-					UpdatePostFunc: func(ctx context.Context, id, authorID int64, role, title, content string) (*entity.Post, error) {
-						return nil, nil
-					},
-				}
-			},
-			wantErr:     true,
-			expectedErr: errors.New("invalid token"),
-		},
 		{
 			name:    "Post Not Found",
-			token:   "valid_token",
+			user:    &authmw.Principal{UserID: 1, Role: "user"},
 			postID:  999,
 			title:   "Updated Title",
 			content: "Updated Content",
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 1,
-							Role:   "user",
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
-					UpdatePostFunc: func(ctx context.Context, id, authorID int64, role, title, content string) (*entity.Post, error) {
+					UpdatePostFunc: func(ctx context.Context, id, authorID int64, role, title, content, contentHTML string, tags []string) (*entity.Post, error) {
 						return nil, sql.ErrNoRows
 					},
 				}
@@ -158,13 +112,9 @@ func TestPostUsecase_UpdatePost(t *testing.T) {
 			mockRepo := tt.mockRepo()
 			mockLogger := NewMockLogger()
 
-			uc := &PostUsecase{
-				postRepo:   mockRepo,
-				authClient: mockAuth,
-				logger:     mockLogger,
-			}
+			uc := NewPostUsecase(mockRepo, mockAuth, mockLogger)
 
-			got, err := uc.UpdatePost(context.Background(), tt.token, tt.postID, tt.title, tt.content)
+			got, err := uc.UpdatePost(context.Background(), tt.user, tt.postID, tt.title, tt.content, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("UpdatePost() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -185,7 +135,7 @@ func TestPostUsecase_UpdatePost(t *testing.T) {
 func TestPostUsecase_DeletePost(t *testing.T) {
 	tests := []struct {
 		name        string
-		token       string
+		user        *authmw.Principal
 		postID      int64
 		mockAuth    func() *MockAuthServiceClient
 		mockRepo    func() *MockPostRepository
@@ -194,18 +144,10 @@ func TestPostUsecase_DeletePost(t *testing.T) {
 	}{
 		{
 			name:   "Success - Author Delete",
-			token:  "valid_token",
+			user:   &authmw.Principal{UserID: 1, Role: "user"},
 			postID: 1,
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 1,
-							Role:   "user",
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
@@ -218,18 +160,10 @@ func TestPostUsecase_DeletePost(t *testing.T) {
 		},
 		{
 			name:   "Success - Admin Delete",
-			token:  "valid_token",
+			user:   &authmw.Principal{UserID: 2, Role: "admin"},
 			postID: 1,
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 2,
-							Role:   "admin",
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
@@ -240,43 +174,12 @@ func TestPostUsecase_DeletePost(t *testing.T) {
 			},
 			wantErr: false,
 		},
-		{
-			name:   "Invalid Token",
-			token:  "invalid_token",
-			postID: 1,
-			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid: false,
-						}, nil
-					},
-				}
-			},
-			mockRepo: func() *MockPostRepository {
-				return &MockPostRepository{
-					DeletePostFunc: func(ctx context.Context, id, authorID int64, role string) error {
-						return nil
-					},
-				}
-			},
-			wantErr:     true,
-			expectedErr: errors.New("invalid token"),
-		},
 		{
 			name:   "Post Not Found",
-			token:  "valid_token",
+			user:   &authmw.Principal{UserID: 1, Role: "user"},
 			postID: 999,
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 1,
-							Role:   "user",
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
@@ -290,18 +193,10 @@ func TestPostUsecase_DeletePost(t *testing.T) {
 		},
 		{
 			name:   "Permission Denied",
-			token:  "valid_token",
+			user:   &authmw.Principal{UserID: 2, Role: "user"},
 			postID: 1,
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 2,
-							Role:   "user",
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
@@ -321,13 +216,9 @@ func TestPostUsecase_DeletePost(t *testing.T) {
 			mockRepo := tt.mockRepo()
 			mockLogger := NewMockLogger()
 
-			uc := &PostUsecase{
-				postRepo:   mockRepo,
-				authClient: mockAuth,
-				logger:     mockLogger,
-			}
+			uc := NewPostUsecase(mockRepo, mockAuth, mockLogger)
 
-			err := uc.DeletePost(context.Background(), tt.token, tt.postID)
+			err := uc.DeletePost(context.Background(), tt.user, tt.postID)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DeletePost() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -342,6 +233,42 @@ func TestPostUsecase_DeletePost(t *testing.T) {
 	}
 }
 
+func TestPostUsecase_DeletePost_Notification(t *testing.T) {
+	var sent []entity.Notification
+	var recipients []int64
+	lookups := 0
+	repo := &MockPostRepository{
+		GetPostByIDFunc: func(ctx context.Context, id int64) (*entity.Post, error) {
+			lookups++
+			return &entity.Post{ID: id, AuthorID: 1}, nil
+		},
+		DeletePostFunc: func(ctx context.Context, id, authorID int64, role string) error {
+			return nil
+		},
+	}
+	uc := NewPostUsecase(repo, &MockAuthServiceClient{}, NewMockLogger())
+	uc.Notifications = NewNotificationUsecase(&MockNotificationRepository{
+		CreateNotificationsFunc: func(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+			sent = append(sent, n)
+			recipients = append(recipients, userIDs...)
+			return []*entity.Notification{}, nil
+		},
+	}, NewMockLogger())
+
+	// Автор удаляет свой пост — пост даже не читаем.
+	require.NoError(t, uc.DeletePost(context.Background(), &authmw.Principal{UserID: 1, Role: "user"}, 3))
+	assert.Zero(t, lookups)
+	assert.Empty(t, sent)
+
+	require.NoError(t, uc.DeletePost(context.Background(),
+		&authmw.Principal{UserID: 2, Username: "root", Role: authmw.RoleAdmin}, 3))
+	require.Len(t, sent, 1)
+	assert.Equal(t, []int64{1}, recipients)
+	assert.Equal(t, entity.ModerationPostDeleted, sent[0].Action)
+	assert.Equal(t, int64(3), *sent[0].PostID)
+	assert.Nil(t, sent[0].CommentID)
+}
+
 func TestPostUsecase_GetPosts(t *testing.T) {
 	now := time.Now()
 	posts := []*entity.Post{
@@ -374,19 +301,21 @@ func TestPostUsecase_GetPosts(t *testing.T) {
 			name: "Success with usernames",
 			mockAuth: func() *MockAuthServiceClient {
 				return &MockAuthServiceClient{
-					GetUserFunc: func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
-						return &pb.GetUserResponse{
-							User: &pb.User{
-								Id:       in.Id,
-								Username: "user" + string(rune(in.Id)),
-							},
-						}, nil
+					GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+						users := make([]*pb.User, 0, len(in.Ids))
+						for _, id := range in.Ids {
+							users = append(users, &pb.User{
+								Id:       id,
+								Username: "user" + strconv.FormatInt(id, 10),
+							})
+						}
+						return &pb.GetUsersResponse{Users: users}, nil
 					},
 				}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
-					GetPostsFunc: func(ctx context.Context) ([]*entity.Post, error) {
+					GetPostsFunc: func(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error) {
 						return posts, nil
 					},
 				}
@@ -405,7 +334,7 @@ func TestPostUsecase_GetPosts(t *testing.T) {
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
-					GetPostsFunc: func(ctx context.Context) ([]*entity.Post, error) {
+					GetPostsFunc: func(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error) {
 						return nil, errors.New("database error")
 					},
 				}
@@ -417,19 +346,16 @@ func TestPostUsecase_GetPosts(t *testing.T) {
 			name: "Partial user info",
 			mockAuth: func() *MockAuthServiceClient {
 				return &MockAuthServiceClient{
-					GetUserFunc: func(ctx context.Context, in *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
-						if in.Id == 1 {
-							return &pb.GetUserResponse{
-								User: &pb.User{Username: "user1"},
-							}, nil
-						}
-						return nil, errors.New("user not found")
+					GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+						return &pb.GetUsersResponse{
+							Users: []*pb.User{{Id: 1, Username: "user1"}},
+						}, nil
 					},
 				}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
-					GetPostsFunc: func(ctx context.Context) ([]*entity.Post, error) {
+					GetPostsFunc: func(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error) {
 						return posts, nil
 					},
 				}
@@ -449,13 +375,9 @@ func TestPostUsecase_GetPosts(t *testing.T) {
 			mockRepo := tt.mockRepo()
 			mockLogger := NewMockLogger()
 
-			uc := &PostUsecase{
-				postRepo:   mockRepo,
-				authClient: mockAuth,
-				logger:     mockLogger,
-			}
+			uc := NewPostUsecase(mockRepo, mockAuth, mockLogger)
 
-			gotPosts, gotNames, err := uc.GetPosts(context.Background())
+			gotPage, gotNames, err := uc.GetPosts(context.Background(), entity.PostListQuery{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetPosts() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -468,17 +390,183 @@ func TestPostUsecase_GetPosts(t *testing.T) {
 				return
 			}
 
-			assert.Equal(t, tt.wantPosts, gotPosts)
+			assert.Equal(t, tt.wantPosts, gotPage.Posts)
 			assert.Equal(t, tt.wantNames, gotNames)
 		})
 	}
 }
 
+func TestPostUsecase_GetPostsPagination(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	posts := []*entity.Post{
+		{ID: 3, AuthorID: 1, CreatedAt: now},
+		{ID: 2, AuthorID: 1, CreatedAt: now.Add(-time.Minute)},
+		{ID: 1, AuthorID: 1, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+
+	var gotQuery entity.PostListQuery
+	uc := NewPostUsecase(
+		&MockPostRepository{
+			GetPostsFunc: func(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error) {
+				gotQuery = query
+				return posts[:min(query.Limit, len(posts))], nil
+			},
+			CountPostsFunc: func(ctx context.Context, query entity.PostListQuery) (int64, error) {
+				return 3, nil
+			},
+		},
+		&MockAuthServiceClient{},
+		NewMockLogger(),
+	)
+
+	t.Run("Default limit", func(t *testing.T) {
+		page, _, err := uc.GetPosts(context.Background(), entity.PostListQuery{})
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultPostsPageSize+1, gotQuery.Limit)
+		assert.Len(t, page.Posts, 3)
+		assert.Equal(t, int64(3), page.Total)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("Next cursor points at the last post", func(t *testing.T) {
+		page, _, err := uc.GetPosts(context.Background(), entity.PostListQuery{Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, gotQuery.Limit)
+		assert.Equal(t, posts[:2], page.Posts)
+
+		cursor, err := entity.ParsePostCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), cursor.ID)
+		assert.True(t, cursor.CreatedAt.Equal(posts[1].CreatedAt))
+	})
+
+	invalid := []struct {
+		name  string
+		query entity.PostListQuery
+		want  error
+	}{
+		{"Negative limit", entity.PostListQuery{Limit: -1}, ErrInvalidPageSize},
+		{"Limit too large", entity.PostListQuery{Limit: MaxPostsPageSize + 1}, ErrInvalidPageSize},
+		{"Negative offset", entity.PostListQuery{Offset: -1}, ErrInvalidOffset},
+		{"Cursor with offset", entity.PostListQuery{Cursor: &entity.PostCursor{ID: 1}, Offset: 10}, ErrConflictingPagination},
+		{"Unknown sort", entity.PostListQuery{Sort: "random"}, ErrInvalidSort},
+		{"Cursor with top", entity.PostListQuery{Sort: entity.PostSortTop, Cursor: &entity.PostCursor{ID: 1}}, ErrCursorNotSupported},
+		{"Invalid tag", entity.PostListQuery{Tags: []string{"no spaces?"}}, ErrInvalidTag},
+		{"Unknown tag mode", entity.PostListQuery{Tags: []string{"go"}, TagMode: "none"}, ErrInvalidTagMode},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := uc.GetPosts(context.Background(), tt.query)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestPostUsecase_GetPostsSortAndVotes(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-24 * time.Hour)
+	posts := []*entity.Post{
+		{ID: 3, AuthorID: 1, Score: 9, CreatedAt: now},
+		{ID: 2, AuthorID: 1, Score: 4, CreatedAt: now},
+		{ID: 1, AuthorID: 1, Score: 1, CreatedAt: now},
+	}
+
+	var gotQuery entity.PostListQuery
+	var gotSince *time.Time
+	var votesCalls int
+	uc := NewPostUsecase(
+		&MockPostRepository{
+			GetPostsFunc: func(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error) {
+				gotQuery = query
+				return posts[:min(query.Limit, len(posts))], nil
+			},
+			CountPostsFunc: func(ctx context.Context, query entity.PostListQuery) (int64, error) {
+				gotSince = query.Since
+				return 3, nil
+			},
+			GetPostVotesFunc: func(ctx context.Context, userID int64, postIDs []int64) (map[int64]int, error) {
+				votesCalls++
+				assert.Equal(t, int64(5), userID)
+				assert.Equal(t, []int64{3, 2}, postIDs)
+				return map[int64]int{3: 1}, nil
+			},
+		},
+		&MockAuthServiceClient{},
+		NewMockLogger(),
+	)
+
+	t.Run("Top keeps window and has no cursor", func(t *testing.T) {
+		page, _, err := uc.GetPosts(context.Background(), entity.PostListQuery{Sort: entity.PostSortTop, Since: &since, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, entity.PostSortTop, gotQuery.Sort)
+		assert.Equal(t, &since, gotSince)
+		assert.Len(t, page.Posts, 2)
+		assert.Empty(t, page.NextCursor)
+		assert.Zero(t, votesCalls, "anonymous viewer has no votes")
+	})
+
+	t.Run("Window is ignored outside top", func(t *testing.T) {
+		_, _, err := uc.GetPosts(context.Background(), entity.PostListQuery{Sort: entity.PostSortHot, Since: &since})
+		require.NoError(t, err)
+		assert.Nil(t, gotQuery.Since)
+		assert.Nil(t, gotSince)
+	})
+
+	t.Run("Viewer gets own votes", func(t *testing.T) {
+		page, _, err := uc.GetPosts(context.Background(), entity.PostListQuery{ViewerID: 5, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, entity.PostSortNew, gotQuery.Sort)
+		assert.Equal(t, 1, votesCalls)
+		assert.Equal(t, 1, page.Posts[0].MyVote)
+		assert.Equal(t, 0, page.Posts[1].MyVote)
+	})
+}
+
+func TestPostUsecase_GetPostsByTopic(t *testing.T) {
+	var votesCalls int
+	var reactionsViewer int64 = -1
+	uc := NewPostUsecase(
+		&MockPostRepository{
+			GetPostsByTopicIDFunc: func(ctx context.Context, topicID int64) ([]*entity.Post, error) {
+				assert.Equal(t, int64(3), topicID)
+				return []*entity.Post{{ID: 2, AuthorID: 1}, {ID: 1, AuthorID: 1}}, nil
+			},
+			GetPostVotesFunc: func(ctx context.Context, userID int64, postIDs []int64) (map[int64]int, error) {
+				votesCalls++
+				assert.Equal(t, int64(5), userID)
+				assert.Equal(t, []int64{2, 1}, postIDs)
+				return map[int64]int{1: -1}, nil
+			},
+			GetPostReactionsFunc: func(ctx context.Context, viewerID int64, postIDs []int64) (map[int64][]entity.Reaction, error) {
+				reactionsViewer = viewerID
+				return map[int64][]entity.Reaction{2: {{Emoji: "👍", Count: 2, Mine: viewerID == 5}}}, nil
+			},
+		},
+		&MockAuthServiceClient{},
+		NewMockLogger(),
+	)
+
+	posts, _, err := uc.GetPostsByTopic(context.Background(), 3, 0)
+	require.NoError(t, err)
+	assert.Zero(t, votesCalls, "anonymous viewer has no votes")
+	assert.Zero(t, posts[1].MyVote)
+	assert.Zero(t, reactionsViewer)
+	assert.Equal(t, []entity.Reaction{{Emoji: "👍", Count: 2}}, posts[0].Reactions)
+	assert.Equal(t, []entity.Reaction{}, posts[1].Reactions, "no reactions is an empty list, not null")
+
+	posts, _, err = uc.GetPostsByTopic(context.Background(), 3, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, votesCalls)
+	assert.Equal(t, 0, posts[0].MyVote)
+	assert.Equal(t, -1, posts[1].MyVote)
+	assert.True(t, posts[0].Reactions[0].Mine)
+}
+
 func TestPostUsecase_CreatePost(t *testing.T) {
 	now := time.Now()
 	tests := []struct {
 		name        string
-		token       string
+		user        *authmw.Principal
 		title       string
 		content     string
 		mockAuth    func() *MockAuthServiceClient
@@ -489,18 +577,11 @@ func TestPostUsecase_CreatePost(t *testing.T) {
 	}{
 		{
 			name:    "Success",
-			token:   "valid_token",
+			user:    &authmw.Principal{UserID: 1, Role: "user"},
 			title:   "Test Title",
 			content: "Test Content",
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 1,
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
@@ -518,40 +599,13 @@ func TestPostUsecase_CreatePost(t *testing.T) {
 			},
 			wantErr: false,
 		},
-		{
-			name:    "Invalid token",
-			token:   "invalid_token",
-			title:   "Test Title",
-			content: "Test Content",
-			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid: false,
-						}, nil
-					},
-				}
-			},
-			mockRepo: func() *MockPostRepository {
-				return &MockPostRepository{}
-			},
-			wantErr:     true,
-			expectedErr: errors.New("invalid token"),
-		},
 		{
 			name:    "Create post error",
-			token:   "valid_token",
+			user:    &authmw.Principal{UserID: 1, Role: "user"},
 			title:   "Test Title",
 			content: "Test Content",
 			mockAuth: func() *MockAuthServiceClient {
-				return &MockAuthServiceClient{
-					ValidateTokenFunc: func(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-						return &pb.ValidateTokenResponse{
-							Valid:  true,
-							UserId: 1,
-						}, nil
-					},
-				}
+				return &MockAuthServiceClient{}
 			},
 			mockRepo: func() *MockPostRepository {
 				return &MockPostRepository{
@@ -571,13 +625,9 @@ func TestPostUsecase_CreatePost(t *testing.T) {
 			mockRepo := tt.mockRepo()
 			mockLogger := NewMockLogger()
 
-			uc := &PostUsecase{
-				postRepo:   mockRepo,
-				authClient: mockAuth,
-				logger:     mockLogger,
-			}
+			uc := NewPostUsecase(mockRepo, mockAuth, mockLogger)
 
-			got, err := uc.CreatePost(context.Background(), tt.token, tt.title, tt.content)
+			got, err := uc.CreatePost(context.Background(), tt.user, tt.title, tt.content, nil, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreatePost() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+)
+
+var ErrUnknownReaction = errors.New("reaction is not allowed")
+
+// DefaultReactions — набор эмодзи, если в настройках он не задан.
+var DefaultReactions = []string{"👍", "👎", "❤️", "😂", "😮", "😢", "🎉"}
+
+// ReactionUsecase переключает реакции на посты и комментарии. Поставить
+// можно только эмодзи из разрешенного набора; повторная такая же реакция
+// пользователя снимает ее.
+type ReactionUsecase struct {
+	postRepo    repository.PostRepository
+	commentRepo repository.CommentRepository
+	allowed     []string
+	allowedSet  map[string]bool
+}
+
+// NewReactionUsecase создает usecase с набором allowed; пустые значения и
+// повторы отбрасываются, пустой набор заменяется DefaultReactions.
+func NewReactionUsecase(postRepo repository.PostRepository, commentRepo repository.CommentRepository, allowed []string) *ReactionUsecase {
+	uc := &ReactionUsecase{postRepo: postRepo, commentRepo: commentRepo, allowedSet: make(map[string]bool)}
+	for _, emoji := range allowed {
+		uc.allow(emoji)
+	}
+	if len(uc.allowed) == 0 {
+		for _, emoji := range DefaultReactions {
+			uc.allow(emoji)
+		}
+	}
+	return uc
+}
+
+func (uc *ReactionUsecase) allow(emoji string) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || uc.allowedSet[emoji] {
+		return
+	}
+	uc.allowed = append(uc.allowed, emoji)
+	uc.allowedSet[emoji] = true
+}
+
+// Allowed возвращает разрешенные эмодзи в порядке из настроек.
+func (uc *ReactionUsecase) Allowed() []string {
+	return append([]string(nil), uc.allowed...)
+}
+
+func (uc *ReactionUsecase) TogglePostReaction(ctx context.Context, user *authmw.Principal, postID int64, emoji string) (*entity.ReactionResult, error) {
+	if !uc.allowedSet[emoji] {
+		return nil, ErrUnknownReaction
+	}
+	added, err := uc.postRepo.TogglePostReaction(ctx, postID, user.UserID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := uc.postRepo.GetPostReactions(ctx, user.UserID, []int64{postID})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ReactionResult{Emoji: emoji, Added: added, Reactions: orEmpty(reactions[postID])}, nil
+}
+
+// ToggleCommentReaction переключает реакцию на комментарий postID/commentID;
+// комментарий из другого поста считается ненайденным.
+func (uc *ReactionUsecase) ToggleCommentReaction(ctx context.Context, user *authmw.Principal, postID, commentID int64, emoji string) (*entity.ReactionResult, error) {
+	if !uc.allowedSet[emoji] {
+		return nil, ErrUnknownReaction
+	}
+	comment, err := uc.commentRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.PostID != postID || comment.Deleted {
+		return nil, repository.ErrCommentNotFound
+	}
+
+	added, err := uc.commentRepo.ToggleCommentReaction(ctx, commentID, user.UserID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := uc.commentRepo.GetCommentReactions(ctx, user.UserID, []int64{commentID})
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ReactionResult{Emoji: emoji, Added: added, Reactions: orEmpty(reactions[commentID])}, nil
+}
+
+// orEmpty заменяет nil пустым списком, чтобы в JSON был [], а не null.
+func orEmpty(reactions []entity.Reaction) []entity.Reaction {
+	if reactions == nil {
+		return []entity.Reaction{}
+	}
+	return reactions
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReactionUsecase_Allowed(t *testing.T) {
+	uc := NewReactionUsecase(&MockPostRepository{}, &MockCommentRepository{}, nil)
+	assert.Equal(t, DefaultReactions, uc.Allowed())
+
+	uc = NewReactionUsecase(&MockPostRepository{}, &MockCommentRepository{}, []string{" 🔥", "👍", "", "🔥"})
+	assert.Equal(t, []string{"🔥", "👍"}, uc.Allowed())
+}
+
+func TestReactionUsecase_TogglePostReaction(t *testing.T) {
+	user := &authmw.Principal{UserID: 7}
+	toggled := false
+	uc := NewReactionUsecase(&MockPostRepository{
+		TogglePostReactionFunc: func(ctx context.Context, postID, userID int64, emoji string) (bool, error) {
+			toggled = true
+			assert.Equal(t, int64(7), userID)
+			if postID == 2 {
+				return false, repository.ErrPostNotFound
+			}
+			return true, nil
+		},
+		GetPostReactionsFunc: func(ctx context.Context, viewerID int64, postIDs []int64) (map[int64][]entity.Reaction, error) {
+			assert.Equal(t, int64(7), viewerID)
+			return map[int64][]entity.Reaction{1: {{Emoji: "👍", Count: 2, Mine: true}}}, nil
+		},
+	}, &MockCommentRepository{}, []string{"👍"})
+
+	result, err := uc.TogglePostReaction(context.Background(), user, 1, "👍")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.ReactionResult{
+		Emoji:     "👍",
+		Added:     true,
+		Reactions: []entity.Reaction{{Emoji: "👍", Count: 2, Mine: true}},
+	}, result)
+
+	_, err = uc.TogglePostReaction(context.Background(), user, 2, "👍")
+	assert.ErrorIs(t, err, repository.ErrPostNotFound)
+
+	toggled = false
+	_, err = uc.TogglePostReaction(context.Background(), user, 1, "🤡")
+	assert.ErrorIs(t, err, ErrUnknownReaction)
+	assert.False(t, toggled)
+}
+
+func TestReactionUsecase_ToggleCommentReaction(t *testing.T) {
+	user := &authmw.Principal{UserID: 7}
+	comments := map[int64]*entity.Comment{
+		1: {ID: 1, PostID: 10},
+		2: {ID: 2, PostID: 11},
+		3: {ID: 3, PostID: 10, Deleted: true},
+	}
+	uc := NewReactionUsecase(&MockPostRepository{}, &MockCommentRepository{
+		GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+			if comment, ok := comments[id]; ok {
+				return comment, nil
+			}
+			return nil, repository.ErrCommentNotFound
+		},
+		ToggleCommentReactionFunc: func(ctx context.Context, commentID, userID int64, emoji string) (bool, error) {
+			return false, nil
+		},
+	}, nil)
+
+	result, err := uc.ToggleCommentReaction(context.Background(), user, 10, 1, "🎉")
+	require.NoError(t, err)
+	assert.False(t, result.Added)
+	assert.NotNil(t, result.Reactions, "no reactions left is an empty list")
+
+	for _, commentID := range []int64{2, 3, 4} {
+		_, err := uc.ToggleCommentReaction(context.Background(), user, 10, commentID, "🎉")
+		assert.ErrorIs(t, err, repository.ErrCommentNotFound, "comment %d", commentID)
+	}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	DefaultRevisionsPageSize = 20
+	// Строк контекста вокруг каждого изменения в diff.
+	diffContextLines = 3
+)
+
+var ErrInvalidRevision = errors.New("revision must be a positive integer")
+
+// RevisionUsecase отдает историю правок поста, diff между ревизиями
+// и откатывает пост к одной из них.
+type RevisionUsecase struct {
+	postRepo repository.PostRepository
+	users    *userDirectory
+	// Mentions делает ссылками упоминания в восстановленном тексте.
+	Mentions *MentionUsecase
+}
+
+func NewRevisionUsecase(postRepo repository.PostRepository, authClient pb.AuthServiceClient) *RevisionUsecase {
+	return &RevisionUsecase{
+		postRepo: postRepo,
+		users:    newUserDirectory(authClient, DefaultUserCacheTTL),
+	}
+}
+
+// ListRevisions возвращает страницу истории поста, новые ревизии первыми.
+// История поста из корзины недоступна, как и сам пост.
+func (uc *RevisionUsecase) ListRevisions(ctx context.Context, postID int64, limit, offset int) ([]*entity.PostRevision, int64, error) {
+	if err := validatePage(limit, offset); err != nil {
+		return nil, 0, err
+	}
+	if _, err := uc.postRepo.GetPostByID(ctx, postID); err != nil {
+		return nil, 0, err
+	}
+
+	revisions, err := uc.postRepo.GetRevisions(ctx, postID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := uc.postRepo.CountRevisions(ctx, postID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	uc.fillEditorNames(ctx, revisions)
+	return revisions, total, nil
+}
+
+func (uc *RevisionUsecase) GetRevision(ctx context.Context, postID int64, revision int) (*entity.PostRevision, error) {
+	rev, err := uc.getRevision(ctx, postID, revision)
+	if err != nil {
+		return nil, err
+	}
+	uc.fillEditorNames(ctx, []*entity.PostRevision{rev})
+	return rev, nil
+}
+
+// Diff строит unified diff от ревизии from к ревизии to. Порядок любой:
+// from может быть и новее to.
+func (uc *RevisionUsecase) Diff(ctx context.Context, postID int64, from, to int) (*entity.RevisionDiff, error) {
+	if from < 1 || to < 1 {
+		return nil, ErrInvalidRevision
+	}
+	a, err := uc.getRevision(ctx, postID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := uc.postRepo.GetRevision(ctx, postID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := revisionDiff(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.RevisionDiff{PostID: postID, From: from, To: to, Diff: diff}, nil
+}
+
+// Rollback возвращает посту текст ревизии revision. Откат записывается
+// в историю новой ревизией от editorID, так что его тоже можно откатить.
+func (uc *RevisionUsecase) Rollback(ctx context.Context, editorID, postID int64, revision int) (*entity.Post, error) {
+	rev, err := uc.getRevision(ctx, postID, revision)
+	if err != nil {
+		return nil, err
+	}
+	// Упоминания этой версии уже сохранялись, повторно о них не уведомляем.
+	contentHTML, _ := uc.Mentions.Render(ctx, rev.Content)
+	return uc.postRepo.RollbackPost(ctx, postID, revision, editorID, contentHTML)
+}
+
+func (uc *RevisionUsecase) getRevision(ctx context.Context, postID int64, revision int) (*entity.PostRevision, error) {
+	if revision < 1 {
+		return nil, ErrInvalidRevision
+	}
+	if _, err := uc.postRepo.GetPostByID(ctx, postID); err != nil {
+		return nil, err
+	}
+	return uc.postRepo.GetRevision(ctx, postID, revision)
+}
+
+func (uc *RevisionUsecase) fillEditorNames(ctx context.Context, revisions []*entity.PostRevision) {
+	editorIDs := make([]int64, 0, len(revisions))
+	for _, rev := range revisions {
+		editorIDs = append(editorIDs, rev.EditorID)
+	}
+	names := uc.users.Usernames(ctx, editorIDs)
+
+	for _, rev := range revisions {
+		if name, ok := names[rev.EditorID]; ok {
+			rev.EditorName = name
+		} else {
+			rev.EditorName = "Unknown"
+		}
+	}
+}
+
+// revisionDiff сравнивает заголовок и текст как два отдельных файла,
+// в духе git diff. Секция без изменений пропускается.
+func revisionDiff(from, to *entity.PostRevision) (string, error) {
+	var out strings.Builder
+	for _, part := range []struct{ name, a, b string }{
+		{"title", from.Title, to.Title},
+		{"content", from.Content, to.Content},
+	} {
+		if part.a == part.b {
+			continue
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(part.a),
+			B:        difflib.SplitLines(part.b),
+			FromFile: fmt.Sprintf("revision %d/%s", from.Revision, part.name),
+			ToFile:   fmt.Sprintf("revision %d/%s", to.Revision, part.name),
+			Context:  diffContextLines,
+		})
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(diff)
+	}
+	return out.String(), nil
+}
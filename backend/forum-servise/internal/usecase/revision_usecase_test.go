@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func newRevisionRepo(revisions map[int]*entity.PostRevision) *MockPostRepository {
+	return &MockPostRepository{
+		GetPostByIDFunc: func(ctx context.Context, id int64) (*entity.Post, error) {
+			if id != 1 {
+				return nil, repository.ErrPostNotFound
+			}
+			return &entity.Post{ID: 1}, nil
+		},
+		GetRevisionFunc: func(ctx context.Context, postID int64, revision int) (*entity.PostRevision, error) {
+			rev, ok := revisions[revision]
+			if !ok {
+				return nil, repository.ErrRevisionNotFound
+			}
+			return rev, nil
+		},
+	}
+}
+
+func TestRevisionUsecase_Diff(t *testing.T) {
+	repo := newRevisionRepo(map[int]*entity.PostRevision{
+		1: {PostID: 1, Revision: 1, Title: "Hello", Content: "one\ntwo\nthree"},
+		2: {PostID: 1, Revision: 2, Title: "Hello", Content: "one\n2\nthree"},
+		3: {PostID: 1, Revision: 3, Title: "Hi", Content: "one\n2\nthree"},
+	})
+	uc := NewRevisionUsecase(repo, &MockAuthServiceClient{})
+
+	diff, err := uc.Diff(context.Background(), 1, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "--- revision 1/content\n"+
+		"+++ revision 2/content\n"+
+		"@@ -1,3 +1,3 @@\n"+
+		" one\n"+
+		"-two\n"+
+		"+2\n"+
+		" three\n", diff.Diff)
+
+	diff, err = uc.Diff(context.Background(), 1, 3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, diff.From)
+	assert.Equal(t, "--- revision 3/title\n+++ revision 2/title\n@@ -1 +1 @@\n-Hi\n+Hello\n", diff.Diff)
+
+	diff, err = uc.Diff(context.Background(), 1, 2, 2)
+	require.NoError(t, err)
+	assert.Empty(t, diff.Diff)
+
+	_, err = uc.Diff(context.Background(), 1, 1, 9)
+	assert.ErrorIs(t, err, repository.ErrRevisionNotFound)
+	_, err = uc.Diff(context.Background(), 1, 0, 2)
+	assert.ErrorIs(t, err, ErrInvalidRevision)
+	_, err = uc.Diff(context.Background(), 2, 1, 2)
+	assert.ErrorIs(t, err, repository.ErrPostNotFound)
+}
+
+func TestRevisionUsecase_ListRevisions(t *testing.T) {
+	repo := newRevisionRepo(nil)
+	repo.GetRevisionsFunc = func(ctx context.Context, postID int64, limit, offset int) ([]*entity.PostRevision, error) {
+		return []*entity.PostRevision{
+			{PostID: postID, Revision: 2, EditorID: 9},
+			{PostID: postID, Revision: 1, EditorID: 4},
+		}, nil
+	}
+	repo.CountRevisionsFunc = func(ctx context.Context, postID int64) (int64, error) {
+		return 2, nil
+	}
+	auth := &MockAuthServiceClient{
+		GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+			return &pb.GetUsersResponse{Users: []*pb.User{{Id: 4, Username: "alice"}}}, nil
+		},
+	}
+	uc := NewRevisionUsecase(repo, auth)
+
+	revisions, total, err := uc.ListRevisions(context.Background(), 1, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "Unknown", revisions[0].EditorName)
+	assert.Equal(t, "alice", revisions[1].EditorName)
+
+	_, _, err = uc.ListRevisions(context.Background(), 2, 20, 0)
+	assert.ErrorIs(t, err, repository.ErrPostNotFound)
+	_, _, err = uc.ListRevisions(context.Background(), 1, 0, 0)
+	assert.ErrorIs(t, err, ErrInvalidPageSize)
+}
+
+func TestRevisionUsecase_Rollback(t *testing.T) {
+	repo := newRevisionRepo(map[int]*entity.PostRevision{
+		1: {PostID: 1, Revision: 1, Title: "Original", Content: "**bold**"},
+	})
+	var rolledBack []int
+	repo.RollbackPostFunc = func(ctx context.Context, postID int64, revision int, editorID int64, contentHTML string) (*entity.Post, error) {
+		rolledBack = append(rolledBack, revision)
+		assert.Equal(t, int64(9), editorID)
+		assert.Equal(t, "<p><strong>bold</strong></p>\n", contentHTML)
+		return &entity.Post{ID: postID, Title: "Original"}, nil
+	}
+	uc := NewRevisionUsecase(repo, &MockAuthServiceClient{})
+
+	post, err := uc.Rollback(context.Background(), 9, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Original", post.Title)
+
+	_, err = uc.Rollback(context.Background(), 9, 1, 5)
+	assert.ErrorIs(t, err, repository.ErrRevisionNotFound)
+	assert.Equal(t, []int{1}, rolledBack)
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+)
+
+const (
+	DefaultSearchPageSize = 20
+	maxSearchQueryLength  = 256
+)
+
+var (
+	ErrEmptySearchQuery   = errors.New("search query is required")
+	ErrSearchQueryTooLong = errors.New("search query is too long")
+	ErrInvalidDateRange   = errors.New("date range start must be before its end")
+)
+
+type SearchUsecase struct {
+	searchRepo repository.SearchRepository
+	users      *userDirectory
+}
+
+type SearchUsecaseInterface interface {
+	Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error)
+}
+
+func NewSearchUsecase(searchRepo repository.SearchRepository, authClient pb.AuthServiceClient) *SearchUsecase {
+	return &SearchUsecase{
+		searchRepo: searchRepo,
+		users:      newUserDirectory(authClient, DefaultUserCacheTTL),
+	}
+}
+
+// Search ищет по постам и комментариям. Нулевой Limit означает размер
+// страницы по умолчанию, ограничения те же, что у ленты постов.
+func (uc *SearchUsecase) Search(ctx context.Context, query entity.SearchQuery) (*entity.SearchPage, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	if utf8.RuneCountInString(query.Text) > maxSearchQueryLength {
+		return nil, ErrSearchQueryTooLong
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultSearchPageSize
+	}
+	if query.Limit < 0 || query.Limit > MaxPostsPageSize {
+		return nil, ErrInvalidPageSize
+	}
+	if query.Offset < 0 {
+		return nil, ErrInvalidOffset
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, ErrInvalidDateRange
+	}
+
+	results, err := uc.searchRepo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	total, err := uc.searchRepo.CountSearch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	authorIDs := make([]int64, 0, len(results))
+	for _, result := range results {
+		authorIDs = append(authorIDs, result.AuthorID)
+	}
+	names := uc.users.Usernames(ctx, authorIDs)
+
+	for _, result := range results {
+		result.Snippet = highlightSnippet(result.Snippet)
+		result.AuthorName = names[result.AuthorID]
+		if result.AuthorName == "" {
+			result.AuthorName = "Unknown"
+		}
+	}
+
+	return &entity.SearchPage{Results: results, Total: total}, nil
+}
+
+// highlightSnippet экранирует текст сниппета и заменяет служебные границы
+// совпадений из ts_headline на <mark>, чтобы клиент мог вывести его как HTML.
+func highlightSnippet(raw string) string {
+	escaped := html.EscapeString(raw)
+	escaped = strings.ReplaceAll(escaped, repository.HighlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, repository.HighlightStop, "</mark>")
+}
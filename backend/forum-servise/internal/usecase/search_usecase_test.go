@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pb "backend.com/forum/proto"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestSearchUsecase_Search(t *testing.T) {
+	var gotQuery entity.SearchQuery
+	repo := &MockSearchRepository{
+		SearchFunc: func(ctx context.Context, query entity.SearchQuery) ([]*entity.SearchResult, error) {
+			gotQuery = query
+			return []*entity.SearchResult{
+				{Type: entity.SearchResultPost, ID: 1, AuthorID: 1, Snippet: "<b>\x01go\x02</b> & more"},
+				{Type: entity.SearchResultComment, ID: 2, AuthorID: 2, Snippet: "plain"},
+			}, nil
+		},
+		CountSearchFunc: func(ctx context.Context, query entity.SearchQuery) (int64, error) {
+			return 42, nil
+		},
+	}
+	auth := &MockAuthServiceClient{
+		GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+			return &pb.GetUsersResponse{Users: []*pb.User{{Id: 1, Username: "alice"}}}, nil
+		},
+	}
+	uc := NewSearchUsecase(repo, auth)
+
+	page, err := uc.Search(context.Background(), entity.SearchQuery{Text: "  go  "})
+	assert.NoError(t, err)
+	assert.Equal(t, "go", gotQuery.Text)
+	assert.Equal(t, DefaultSearchPageSize, gotQuery.Limit)
+	assert.Equal(t, int64(42), page.Total)
+	assert.Equal(t, "&lt;b&gt;<mark>go</mark>&lt;/b&gt; &amp; more", page.Results[0].Snippet)
+	assert.Equal(t, "alice", page.Results[0].AuthorName)
+	assert.Equal(t, "Unknown", page.Results[1].AuthorName)
+}
+
+func TestSearchUsecase_SearchValidation(t *testing.T) {
+	uc := NewSearchUsecase(&MockSearchRepository{}, &MockAuthServiceClient{})
+
+	from := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		query entity.SearchQuery
+		want  error
+	}{
+		{"Empty query", entity.SearchQuery{Text: "   "}, ErrEmptySearchQuery},
+		{"Too long", entity.SearchQuery{Text: strings.Repeat("я", maxSearchQueryLength+1)}, ErrSearchQueryTooLong},
+		{"Limit too large", entity.SearchQuery{Text: "go", Limit: MaxPostsPageSize + 1}, ErrInvalidPageSize},
+		{"Negative offset", entity.SearchQuery{Text: "go", Offset: -1}, ErrInvalidOffset},
+		{"Reversed range", entity.SearchQuery{Text: "go", From: &from, To: &to}, ErrInvalidDateRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Search(context.Background(), tt.query)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestSearchUsecase_SearchRepoError(t *testing.T) {
+	repo := &MockSearchRepository{
+		SearchFunc: func(ctx context.Context, query entity.SearchQuery) ([]*entity.SearchResult, error) {
+			return nil, errors.New("database error")
+		},
+	}
+	uc := NewSearchUsecase(repo, &MockAuthServiceClient{})
+
+	_, err := uc.Search(context.Background(), entity.SearchQuery{Text: "go"})
+	assert.EqualError(t, err, "database error")
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+)
+
+const (
+	MaxTagLength          = 32
+	MaxPostTags           = 5
+	DefaultTagSuggestions = 10
+	MaxTagSuggestions     = 50
+)
+
+var (
+	ErrInvalidTag     = errors.New("tag must be 1-32 letters, digits or - _ . + #")
+	ErrTooManyTags    = errors.New("a post can have at most 5 tags")
+	ErrInvalidTagMode = errors.New("tag_mode must be one of all, any")
+	ErrMergeIntoSelf  = errors.New("cannot merge a tag into itself")
+)
+
+// NormalizeTag приводит тег к виду, в котором он хранится: нижний регистр,
+// без "#" в начале, пробелы внутри заменены на "-". "Go Lang" и "#go-lang" —
+// один и тот же тег.
+func NormalizeTag(raw string) (string, error) {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw), "#"))
+	name = strings.Join(strings.Fields(name), "-")
+	if name == "" || utf8.RuneCountInString(name) > MaxTagLength {
+		return "", ErrInvalidTag
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.+#", r) {
+			return "", ErrInvalidTag
+		}
+	}
+	return name, nil
+}
+
+// NormalizeTags нормализует теги и убирает повторы, сохраняя порядок.
+// nil остается nil: для UpdatePost это значит «теги не менять».
+func NormalizeTags(raw []string) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	tags := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, r := range raw {
+		name, err := NormalizeTag(r)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			tags = append(tags, name)
+		}
+	}
+	return tags, nil
+}
+
+// TagUsecase — списки тегов и их обслуживание администратором.
+type TagUsecase struct {
+	tagRepo repository.TagRepository
+}
+
+func NewTagUsecase(tagRepo repository.TagRepository) *TagUsecase {
+	return &TagUsecase{tagRepo: tagRepo}
+}
+
+func (uc *TagUsecase) ListTags(ctx context.Context, limit, offset int) ([]*entity.Tag, int64, error) {
+	if err := validatePage(limit, offset); err != nil {
+		return nil, 0, err
+	}
+	tags, err := uc.tagRepo.ListTags(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := uc.tagRepo.CountTags(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tags, total, nil
+}
+
+// SuggestTags дополняет начало тега prefix. Нулевой limit — размер по
+// умолчанию; пустой prefix — просто самые популярные теги.
+func (uc *TagUsecase) SuggestTags(ctx context.Context, prefix string, limit int) ([]*entity.Tag, error) {
+	switch {
+	case limit == 0:
+		limit = DefaultTagSuggestions
+	case limit < 0 || limit > MaxTagSuggestions:
+		return nil, ErrInvalidPageSize
+	}
+
+	if prefix = strings.TrimSpace(prefix); prefix != "" {
+		var err error
+		if prefix, err = NormalizeTag(prefix); err != nil {
+			return nil, err
+		}
+	}
+	return uc.tagRepo.SuggestTags(ctx, prefix, limit)
+}
+
+// RenameTag переименовывает тег; оба имени нормализуются.
+func (uc *TagUsecase) RenameTag(ctx context.Context, name, newName string) (string, error) {
+	name, err := existingTag(name)
+	if err != nil {
+		return "", err
+	}
+	newName, err = NormalizeTag(newName)
+	if err != nil {
+		return "", err
+	}
+	if newName == name {
+		return newName, nil
+	}
+	if err := uc.tagRepo.RenameTag(ctx, name, newName); err != nil {
+		return "", err
+	}
+	return newName, nil
+}
+
+// MergeTags сливает тег source в target: посты source получают target,
+// а сам source удаляется.
+func (uc *TagUsecase) MergeTags(ctx context.Context, source, target string) (string, error) {
+	source, err := existingTag(source)
+	if err != nil {
+		return "", err
+	}
+	target, err = NormalizeTag(target)
+	if err != nil {
+		return "", err
+	}
+	if target == source {
+		return "", ErrMergeIntoSelf
+	}
+	if err := uc.tagRepo.MergeTags(ctx, source, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// existingTag нормализует имя уже существующего тега; с невалидным именем
+// тега быть не может.
+func existingTag(name string) (string, error) {
+	name, err := NormalizeTag(name)
+	if err != nil {
+		return "", repository.ErrTagNotFound
+	}
+	return name, nil
+}
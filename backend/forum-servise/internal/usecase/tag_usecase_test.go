@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "Go", want: "go"},
+		{raw: "  #GoLang ", want: "golang"},
+		{raw: "Machine   Learning", want: "machine-learning"},
+		{raw: "c++", want: "c++"},
+		{raw: "c#", want: "c#"},
+		{raw: "Горутины", want: "горутины"},
+		{raw: "", wantErr: true},
+		{raw: "#", wantErr: true},
+		{raw: "drop;table", wantErr: true},
+		{raw: strings.Repeat("a", MaxTagLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := NormalizeTag(tt.raw)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTag)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{"Go", "gRPC", "#go", "grpc"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "grpc"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	require.NoError(t, err)
+	assert.Nil(t, tags)
+
+	tags, err = NormalizeTags([]string{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, tags)
+}
+
+func TestTagUsecase_SuggestTags(t *testing.T) {
+	var gotPrefix string
+	var gotLimit int
+	uc := NewTagUsecase(&MockTagRepository{
+		SuggestTagsFunc: func(ctx context.Context, prefix string, limit int) ([]*entity.Tag, error) {
+			gotPrefix, gotLimit = prefix, limit
+			return []*entity.Tag{{Name: "golang", PostCount: 3}}, nil
+		},
+	})
+
+	tags, err := uc.SuggestTags(context.Background(), " #Go", 0)
+	require.NoError(t, err)
+	assert.Len(t, tags, 1)
+	assert.Equal(t, "go", gotPrefix)
+	assert.Equal(t, DefaultTagSuggestions, gotLimit)
+
+	_, err = uc.SuggestTags(context.Background(), "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "", gotPrefix)
+
+	_, err = uc.SuggestTags(context.Background(), "go", MaxTagSuggestions+1)
+	assert.ErrorIs(t, err, ErrInvalidPageSize)
+}
+
+func TestTagUsecase_RenameTag(t *testing.T) {
+	renamed := false
+	uc := NewTagUsecase(&MockTagRepository{
+		RenameTagFunc: func(ctx context.Context, name, newName string) error {
+			renamed = true
+			assert.Equal(t, "golang", name)
+			assert.Equal(t, "go-lang", newName)
+			return nil
+		},
+	})
+
+	name, err := uc.RenameTag(context.Background(), "GoLang", "Go Lang")
+	require.NoError(t, err)
+	assert.Equal(t, "go-lang", name)
+	assert.True(t, renamed)
+
+	_, err = uc.RenameTag(context.Background(), "golang", "bad tag!")
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	_, err = uc.RenameTag(context.Background(), "bad tag!", "go")
+	assert.ErrorIs(t, err, repository.ErrTagNotFound)
+}
+
+func TestTagUsecase_MergeTags(t *testing.T) {
+	merged := false
+	uc := NewTagUsecase(&MockTagRepository{
+		MergeTagsFunc: func(ctx context.Context, source, target string) error {
+			merged = true
+			assert.Equal(t, "golang", source)
+			assert.Equal(t, "go", target)
+			return nil
+		},
+	})
+
+	name, err := uc.MergeTags(context.Background(), "golang", "#Go")
+	require.NoError(t, err)
+	assert.Equal(t, "go", name)
+	assert.True(t, merged)
+
+	merged = false
+	_, err = uc.MergeTags(context.Background(), "go", "GO")
+	assert.ErrorIs(t, err, ErrMergeIntoSelf)
+	assert.False(t, merged)
+}
+
+func TestPostUsecase_PostTags(t *testing.T) {
+	user := &authmw.Principal{UserID: 1}
+
+	t.Run("Create normalizes tags", func(t *testing.T) {
+		var gotTags []string
+		uc := NewPostUsecase(&MockPostRepository{
+			CreatePostFunc: func(ctx context.Context, post *entity.Post) (int64, error) {
+				gotTags = post.Tags
+				return 1, nil
+			},
+		}, &MockAuthServiceClient{}, NewMockLogger())
+
+		post, err := uc.CreatePost(context.Background(), user, "T", "C", nil, []string{"Go", "#go", "gRPC"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "grpc"}, gotTags)
+		assert.Equal(t, []string{"go", "grpc"}, post.Tags)
+
+		_, err = uc.CreatePost(context.Background(), user, "T", "C", nil, []string{"a", "b", "c", "d", "e", "f"})
+		assert.ErrorIs(t, err, ErrTooManyTags)
+	})
+
+	t.Run("Update replaces tags", func(t *testing.T) {
+		var gotTags []string
+		uc := NewPostUsecase(&MockPostRepository{
+			UpdatePostFunc: func(ctx context.Context, id, authorID int64, role, title, content, contentHTML string, tags []string) (*entity.Post, error) {
+				gotTags = tags
+				return &entity.Post{ID: id, Title: title, Content: content}, nil
+			},
+			GetPostTagsFunc: func(ctx context.Context, postIDs []int64) (map[int64][]string, error) {
+				return map[int64][]string{1: {"sql"}}, nil
+			},
+		}, &MockAuthServiceClient{}, NewMockLogger())
+
+		post, err := uc.UpdatePost(context.Background(), user, 1, "T", "C", []string{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, gotTags)
+		assert.Equal(t, []string{}, post.Tags)
+
+		// Без тегов в запросе старые теги остаются.
+		gotTags = nil
+		post, err = uc.UpdatePost(context.Background(), user, 1, "T", "C", nil)
+		require.NoError(t, err)
+		assert.Nil(t, gotTags)
+		assert.Equal(t, []string{"sql"}, post.Tags)
+	})
+
+	t.Run("Filter normalizes tags", func(t *testing.T) {
+		var gotQuery entity.PostListQuery
+		uc := NewPostUsecase(&MockPostRepository{
+			GetPostsFunc: func(ctx context.Context, query entity.PostListQuery) ([]*entity.Post, error) {
+				gotQuery = query
+				return []*entity.Post{}, nil
+			},
+		}, &MockAuthServiceClient{}, NewMockLogger())
+
+		_, _, err := uc.GetPosts(context.Background(), entity.PostListQuery{Tags: []string{"Go", "#GRPC", "go"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "grpc"}, gotQuery.Tags)
+		assert.Equal(t, entity.TagMatchAll, gotQuery.TagMode)
+	})
+}
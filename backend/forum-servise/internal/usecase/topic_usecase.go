@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+)
+
+var ErrEmptyTopicTitle = errors.New("topic title is required")
+
+type TopicUsecase struct {
+	topicRepo    repository.TopicRepository
+	categoryRepo repository.CategoryRepository
+}
+
+type TopicUsecaseInterface interface {
+	CreateTopic(ctx context.Context, categoryID, userID int64, title string) (*entity.Topic, error)
+	GetTopic(ctx context.Context, id int64) (*entity.Topic, error)
+	GetTopicsByCategory(ctx context.Context, categoryID int64) ([]entity.Topic, error)
+	UpdateTopic(ctx context.Context, id int64, title string) (*entity.Topic, error)
+	DeleteTopic(ctx context.Context, id int64) error
+}
+
+func NewTopicUsecase(
+	topicRepo repository.TopicRepository,
+	categoryRepo repository.CategoryRepository,
+) *TopicUsecase {
+	return &TopicUsecase{
+		topicRepo:    topicRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (uc *TopicUsecase) CreateTopic(ctx context.Context, categoryID, userID int64, title string) (*entity.Topic, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrEmptyTopicTitle
+	}
+
+	if _, err := uc.categoryRepo.GetCategoryByID(ctx, categoryID); err != nil {
+		return nil, err
+	}
+
+	topic := &entity.Topic{
+		CategoryID: categoryID,
+		Title:      title,
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+	}
+
+	id, err := uc.topicRepo.CreateTopic(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	topic.ID = id
+	return topic, nil
+}
+
+func (uc *TopicUsecase) GetTopic(ctx context.Context, id int64) (*entity.Topic, error) {
+	return uc.topicRepo.GetTopicByID(ctx, id)
+}
+
+func (uc *TopicUsecase) GetTopicsByCategory(ctx context.Context, categoryID int64) ([]entity.Topic, error) {
+	if _, err := uc.categoryRepo.GetCategoryByID(ctx, categoryID); err != nil {
+		return nil, err
+	}
+	return uc.topicRepo.GetTopicsByCategoryID(ctx, categoryID)
+}
+
+func (uc *TopicUsecase) UpdateTopic(ctx context.Context, id int64, title string) (*entity.Topic, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrEmptyTopicTitle
+	}
+
+	topic := &entity.Topic{
+		ID:        id,
+		Title:     title,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := uc.topicRepo.UpdateTopic(ctx, topic); err != nil {
+		return nil, err
+	}
+	return topic, nil
+}
+
+func (uc *TopicUsecase) DeleteTopic(ctx context.Context, id int64) error {
+	return uc.topicRepo.DeleteTopic(ctx, id)
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicUsecase_CreateTopic(t *testing.T) {
+	tests := []struct {
+		name         string
+		categoryID   int64
+		title        string
+		categoryRepo *MockCategoryRepository
+		topicRepo    *MockTopicRepository
+		wantID       int64
+		wantErr      error
+	}{
+		{
+			name:       "Success",
+			categoryID: 1,
+			title:      "  Горутины  ",
+			categoryRepo: &MockCategoryRepository{
+				GetCategoryByIDFunc: func(ctx context.Context, id int64) (*entity.Category, error) {
+					return &entity.Category{ID: id}, nil
+				},
+			},
+			topicRepo: &MockTopicRepository{
+				CreateTopicFunc: func(ctx context.Context, topic *entity.Topic) (int64, error) {
+					assert.Equal(t, "Горутины", topic.Title)
+					return 10, nil
+				},
+			},
+			wantID: 10,
+		},
+		{
+			name:         "Empty Title",
+			categoryID:   1,
+			title:        "   ",
+			categoryRepo: &MockCategoryRepository{},
+			topicRepo:    &MockTopicRepository{},
+			wantErr:      ErrEmptyTopicTitle,
+		},
+		{
+			name:       "Category Not Found",
+			categoryID: 2,
+			title:      "Тема",
+			categoryRepo: &MockCategoryRepository{
+				GetCategoryByIDFunc: func(ctx context.Context, id int64) (*entity.Category, error) {
+					return nil, repository.ErrCategoryNotFound
+				},
+			},
+			topicRepo: &MockTopicRepository{},
+			wantErr:   repository.ErrCategoryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewTopicUsecase(tt.topicRepo, tt.categoryRepo)
+			topic, err := uc.CreateTopic(context.Background(), tt.categoryID, 1, tt.title)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantID, topic.ID)
+		})
+	}
+}
+
+func TestCategoryUsecase_CreateCategory_EmptyName(t *testing.T) {
+	uc := NewCategoryUsecase(&MockCategoryRepository{})
+	_, err := uc.CreateCategory(context.Background(), " ", "описание")
+	assert.ErrorIs(t, err, ErrEmptyCategoryName)
+}
+
+func TestTopicUsecase_GetTopicsByCategory_CategoryNotFound(t *testing.T) {
+	uc := NewTopicUsecase(&MockTopicRepository{
+		GetTopicsByCategoryIDFunc: func(ctx context.Context, categoryID int64) ([]entity.Topic, error) {
+			t.Fatal("topics must not be queried for a missing category")
+			return nil, nil
+		},
+	}, &MockCategoryRepository{
+		GetCategoryByIDFunc: func(ctx context.Context, id int64) (*entity.Category, error) {
+			return nil, repository.ErrCategoryNotFound
+		},
+	})
+
+	_, err := uc.GetTopicsByCategory(context.Background(), 3)
+	assert.ErrorIs(t, err, repository.ErrCategoryNotFound)
+}
+
+func TestTopicUsecase_UpdateTopic(t *testing.T) {
+	uc := NewTopicUsecase(&MockTopicRepository{
+		UpdateTopicFunc: func(ctx context.Context, topic *entity.Topic) error {
+			assert.Equal(t, int64(4), topic.ID)
+			assert.Equal(t, "Каналы", topic.Title)
+			topic.CategoryID = 1
+			return nil
+		},
+	}, &MockCategoryRepository{})
+
+	topic, err := uc.UpdateTopic(context.Background(), 4, " Каналы ")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), topic.CategoryID)
+
+	_, err = uc.UpdateTopic(context.Background(), 4, "")
+	assert.ErrorIs(t, err, ErrEmptyTopicTitle)
+}
+
+func TestCategoryUsecase_UpdateCategory_NotFound(t *testing.T) {
+	uc := NewCategoryUsecase(&MockCategoryRepository{
+		UpdateCategoryFunc: func(ctx context.Context, category *entity.Category) error {
+			return repository.ErrCategoryNotFound
+		},
+	})
+
+	_, err := uc.UpdateCategory(context.Background(), 8, "Go", "")
+	assert.ErrorIs(t, err, repository.ErrCategoryNotFound)
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/pkg/logger"
+)
+
+const (
+	DefaultTrashGracePeriod   = 30 * 24 * time.Hour
+	DefaultTrashPurgeInterval = time.Hour
+	DefaultPurgeBatchSize     = 500
+)
+
+// TrashUsecase обслуживает корзину: админ просматривает и восстанавливает
+// удаленные посты и комментарии, а фоновая очистка окончательно удаляет
+// те, что пролежали в корзине дольше grace.
+type TrashUsecase struct {
+	posts     repository.PostRepository
+	comments  repository.CommentRepository
+	grace     time.Duration
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+	logger    *logger.Logger
+	// Notifications уведомляет авторов о восстановлении из корзины; nil —
+	// без уведомлений.
+	Notifications *NotificationUsecase
+	// Attachments удаляет из хранилища файлы вложений очищенных постов.
+	Attachments *AttachmentUsecase
+}
+
+func NewTrashUsecase(
+	posts repository.PostRepository,
+	comments repository.CommentRepository,
+	grace, interval time.Duration,
+	logger *logger.Logger,
+) *TrashUsecase {
+	if grace <= 0 {
+		grace = DefaultTrashGracePeriod
+	}
+	if interval <= 0 {
+		interval = DefaultTrashPurgeInterval
+	}
+	return &TrashUsecase{
+		posts:     posts,
+		comments:  comments,
+		grace:     grace,
+		interval:  interval,
+		batchSize: DefaultPurgeBatchSize,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// GracePeriod — сколько удаленное хранится в корзине до очистки.
+func (uc *TrashUsecase) GracePeriod() time.Duration {
+	return uc.grace
+}
+
+func (uc *TrashUsecase) ListPosts(ctx context.Context, limit, offset int) ([]*entity.Post, int64, error) {
+	if err := validatePage(limit, offset); err != nil {
+		return nil, 0, err
+	}
+	posts, err := uc.posts.GetDeletedPosts(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := uc.posts.CountDeletedPosts(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return posts, total, nil
+}
+
+func (uc *TrashUsecase) ListComments(ctx context.Context, limit, offset int) ([]*entity.Comment, int64, error) {
+	if err := validatePage(limit, offset); err != nil {
+		return nil, 0, err
+	}
+	comments, err := uc.comments.GetDeletedComments(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := uc.comments.CountDeletedComments(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// RestorePost восстанавливает пост от имени администратора user.
+func (uc *TrashUsecase) RestorePost(ctx context.Context, user *authmw.Principal, id int64) error {
+	if err := uc.posts.RestorePost(ctx, id); err != nil {
+		return err
+	}
+	if uc.Notifications == nil {
+		return nil
+	}
+
+	post, err := uc.posts.GetPostByID(ctx, id)
+	if err != nil {
+		uc.logger.Errorf("trash: failed to get restored post %d: %v", id, err)
+		return nil
+	}
+	uc.notifyRestored(ctx, user, post.AuthorID, entity.ModerationPostRestored, entity.Subject{PostID: &id})
+	return nil
+}
+
+// RestoreComment восстанавливает комментарий от имени администратора user.
+func (uc *TrashUsecase) RestoreComment(ctx context.Context, user *authmw.Principal, id int64) error {
+	if err := uc.comments.RestoreComment(ctx, id); err != nil {
+		return err
+	}
+	if uc.Notifications == nil {
+		return nil
+	}
+
+	comment, err := uc.comments.GetCommentByID(ctx, id)
+	if err != nil {
+		uc.logger.Errorf("trash: failed to get restored comment %d: %v", id, err)
+		return nil
+	}
+	uc.notifyRestored(ctx, user, comment.AuthorID, entity.ModerationCommentRestored, commentSubject(comment))
+	return nil
+}
+
+// notifyRestored уведомляет автора; свое восстановление админ не получает.
+func (uc *TrashUsecase) notifyRestored(ctx context.Context, user *authmw.Principal, authorID int64, action string, subject entity.Subject) {
+	uc.Notifications.Publish(ctx, entity.Notification{
+		Type:      entity.NotificationModeration,
+		Action:    action,
+		ActorID:   user.UserID,
+		ActorName: user.Username,
+		Subject:   subject,
+	}, authorID)
+}
+
+// Run запускает очистку сразу и затем каждые interval до отмены ctx.
+func (uc *TrashUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := uc.Purge(ctx)
+		if err != nil {
+			uc.logger.Errorf("trash purge failed: %v", err)
+		}
+		if purged > 0 {
+			uc.logger.Infof("trash: purged %d items", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge окончательно удаляет комментарии и посты, пролежавшие в корзине
+// дольше grace, пачками по batchSize, вместе с файлами вложений постов.
+// Возвращает число удаленных строк (комментарии, ушедшие каскадом вместе
+// с постом, не считаются).
+func (uc *TrashUsecase) Purge(ctx context.Context) (int64, error) {
+	before := uc.now().Add(-uc.grace)
+
+	comments, errComments := uc.purgeBatches(ctx, func(limit int) (int64, error) {
+		return uc.comments.PurgeDeletedComments(ctx, before, limit)
+	})
+	posts, errPosts := uc.purgeBatches(ctx, func(limit int) (int64, error) {
+		n, keys, err := uc.posts.PurgeDeletedPosts(ctx, before, limit)
+		uc.Attachments.DeleteFiles(ctx, keys...)
+		return n, err
+	})
+	return comments + posts, errors.Join(errComments, errPosts)
+}
+
+func (uc *TrashUsecase) purgeBatches(ctx context.Context, purge func(limit int) (int64, error)) (int64, error) {
+	var total int64
+	for ctx.Err() == nil {
+		n, err := purge(uc.batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(uc.batchSize) {
+			break
+		}
+	}
+	return total, nil
+}
+
+func validatePage(limit, offset int) error {
+	if limit < 1 || limit > MaxPostsPageSize {
+		return ErrInvalidPageSize
+	}
+	if offset < 0 {
+		return ErrInvalidOffset
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTrashUsecase_Defaults(t *testing.T) {
+	uc := NewTrashUsecase(&MockPostRepository{}, &MockCommentRepository{}, 0, 0, NewMockLogger())
+	assert.Equal(t, DefaultTrashGracePeriod, uc.GracePeriod())
+	assert.Equal(t, DefaultTrashPurgeInterval, uc.interval)
+}
+
+func TestTrashUsecase_Purge(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	grace := 24 * time.Hour
+
+	var commentCalls, postCalls []time.Time
+	comments := &MockCommentRepository{
+		PurgeDeletedCommentsFunc: func(ctx context.Context, before time.Time, limit int) (int64, error) {
+			commentCalls = append(commentCalls, before)
+			if len(commentCalls) == 1 {
+				return int64(limit), nil
+			}
+			return 1, nil
+		},
+	}
+	posts := &MockPostRepository{
+		PurgeDeletedPostsFunc: func(ctx context.Context, before time.Time, limit int) (int64, []string, error) {
+			postCalls = append(postCalls, before)
+			return 0, nil, nil
+		},
+	}
+
+	uc := NewTrashUsecase(posts, comments, grace, time.Hour, NewMockLogger())
+	uc.now = func() time.Time { return now }
+	uc.batchSize = 2
+
+	purged, err := uc.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	assert.Len(t, commentCalls, 2, "full batch triggers another round")
+	require.Len(t, postCalls, 1)
+	assert.Equal(t, now.Add(-grace), postCalls[0])
+}
+
+func TestTrashUsecase_Purge_AttachmentFiles(t *testing.T) {
+	store := &memStorage{objects: map[string][]byte{
+		"posts/1/a": {1}, "posts/1/a_thumb": {2}, "posts/2/b": {3},
+	}}
+	posts := &MockPostRepository{
+		PurgeDeletedPostsFunc: func(ctx context.Context, before time.Time, limit int) (int64, []string, error) {
+			return 1, []string{"posts/1/a", "posts/1/a_thumb"}, nil
+		},
+	}
+	comments := &MockCommentRepository{
+		PurgeDeletedCommentsFunc: func(ctx context.Context, before time.Time, limit int) (int64, error) {
+			return 0, nil
+		},
+	}
+	uc := NewTrashUsecase(posts, comments, time.Hour, time.Hour, NewMockLogger())
+	uc.Attachments = NewAttachmentUsecase(&MockAttachmentRepository{}, posts, store, nil, NewMockLogger())
+
+	purged, err := uc.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.Equal(t, map[string][]byte{"posts/2/b": {3}}, store.objects)
+}
+
+func TestTrashUsecase_Purge_Errors(t *testing.T) {
+	commentErr := errors.New("comments down")
+	postsPurged := false
+	comments := &MockCommentRepository{
+		PurgeDeletedCommentsFunc: func(ctx context.Context, before time.Time, limit int) (int64, error) {
+			return 0, commentErr
+		},
+	}
+	posts := &MockPostRepository{
+		PurgeDeletedPostsFunc: func(ctx context.Context, before time.Time, limit int) (int64, []string, error) {
+			postsPurged = true
+			return 2, nil, nil
+		},
+	}
+
+	uc := NewTrashUsecase(posts, comments, time.Hour, time.Hour, NewMockLogger())
+	purged, err := uc.Purge(context.Background())
+	assert.ErrorIs(t, err, commentErr)
+	assert.True(t, postsPurged, "posts are purged even if comments fail")
+	assert.Equal(t, int64(2), purged)
+}
+
+func TestTrashUsecase_List(t *testing.T) {
+	posts := &MockPostRepository{
+		GetDeletedPostsFunc: func(ctx context.Context, limit, offset int) ([]*entity.Post, error) {
+			return []*entity.Post{{ID: 1}}, nil
+		},
+		CountDeletedPostsFunc: func(ctx context.Context) (int64, error) {
+			return 5, nil
+		},
+	}
+	comments := &MockCommentRepository{
+		GetDeletedCommentsFunc: func(ctx context.Context, limit, offset int) ([]*entity.Comment, error) {
+			return []*entity.Comment{{ID: 2}}, nil
+		},
+		CountDeletedCommentsFunc: func(ctx context.Context) (int64, error) {
+			return 3, nil
+		},
+	}
+	uc := NewTrashUsecase(posts, comments, time.Hour, time.Hour, NewMockLogger())
+
+	gotPosts, total, err := uc.ListPosts(context.Background(), 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, gotPosts, 1)
+	assert.Equal(t, int64(5), total)
+
+	gotComments, total, err := uc.ListComments(context.Background(), 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, gotComments, 1)
+	assert.Equal(t, int64(3), total)
+
+	_, _, err = uc.ListPosts(context.Background(), 0, 0)
+	assert.ErrorIs(t, err, ErrInvalidPageSize)
+	_, _, err = uc.ListComments(context.Background(), 10, -1)
+	assert.ErrorIs(t, err, ErrInvalidOffset)
+}
+
+func TestTrashUsecase_Restore(t *testing.T) {
+	posts := &MockPostRepository{
+		RestorePostFunc: func(ctx context.Context, id int64) error {
+			if id != 1 {
+				return repository.ErrPostNotFound
+			}
+			return nil
+		},
+	}
+	comments := &MockCommentRepository{
+		RestoreCommentFunc: func(ctx context.Context, id int64) error {
+			return repository.ErrCommentNotFound
+		},
+	}
+	uc := NewTrashUsecase(posts, comments, time.Hour, time.Hour, NewMockLogger())
+	admin := &authmw.Principal{UserID: 1, Role: authmw.RoleAdmin}
+
+	assert.NoError(t, uc.RestorePost(context.Background(), admin, 1))
+	assert.ErrorIs(t, uc.RestorePost(context.Background(), admin, 2), repository.ErrPostNotFound)
+	assert.ErrorIs(t, uc.RestoreComment(context.Background(), admin, 3), repository.ErrCommentNotFound)
+}
+
+func TestTrashUsecase_Restore_Notifications(t *testing.T) {
+	var sent []entity.Notification
+	var recipients []int64
+	uc := NewTrashUsecase(&MockPostRepository{
+		GetPostByIDFunc: func(ctx context.Context, id int64) (*entity.Post, error) {
+			return &entity.Post{ID: id, AuthorID: 5}, nil
+		},
+	}, &MockCommentRepository{
+		GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+			return &entity.Comment{ID: id, PostID: 2, AuthorID: 6}, nil
+		},
+		RestoreCommentFunc: func(ctx context.Context, id int64) error { return nil },
+	}, time.Hour, time.Hour, NewMockLogger())
+	uc.Notifications = NewNotificationUsecase(&MockNotificationRepository{
+		CreateNotificationsFunc: func(ctx context.Context, n entity.Notification, userIDs []int64) ([]*entity.Notification, error) {
+			sent = append(sent, n)
+			recipients = append(recipients, userIDs...)
+			return []*entity.Notification{}, nil
+		},
+	}, NewMockLogger())
+	admin := &authmw.Principal{UserID: 1, Username: "root", Role: authmw.RoleAdmin}
+
+	require.NoError(t, uc.RestorePost(context.Background(), admin, 2))
+	require.NoError(t, uc.RestoreComment(context.Background(), admin, 3))
+	require.Len(t, sent, 2)
+	assert.Equal(t, []int64{5, 6}, recipients)
+	assert.Equal(t, entity.ModerationPostRestored, sent[0].Action)
+	assert.Equal(t, int64(2), *sent[0].PostID)
+	assert.Equal(t, entity.ModerationCommentRestored, sent[1].Action)
+	assert.Equal(t, int64(3), *sent[1].CommentID)
+	for _, n := range sent {
+		assert.Equal(t, entity.NotificationModeration, n.Type)
+		assert.Equal(t, "root", n.ActorName)
+	}
+}
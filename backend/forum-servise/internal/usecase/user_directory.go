@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "backend.com/forum/proto"
+)
+
+const (
+	DefaultUserCacheTTL     = 30 * time.Second
+	userCachePruneThreshold = 10000
+	// Столько id auth-сервис принимает в одном GetUsers.
+	maxUsersPerCall = 500
+)
+
+// userDirectory разрешает имена авторов одним вызовом GetUsers и недолго
+// помнит ответ, чтобы соседние запросы ленты не ходили в auth-сервис заново.
+// Отсутствующих пользователей тоже запоминаем: id не переиспользуются.
+type userDirectory struct {
+	client pb.AuthServiceClient
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[int64]userCacheEntry
+}
+
+type userCacheEntry struct {
+	username  string
+	found     bool
+	fetchedAt time.Time
+}
+
+func newUserDirectory(client pb.AuthServiceClient, ttl time.Duration) *userDirectory {
+	return &userDirectory{
+		client:  client,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[int64]userCacheEntry),
+	}
+}
+
+// Usernames возвращает имена найденных пользователей. Ошибка auth-сервиса
+// не ломает ленту: вернутся только имена из кэша, а сбой не запоминается.
+func (d *userDirectory) Usernames(ctx context.Context, ids []int64) map[int64]string {
+	names := make(map[int64]string, len(ids))
+	now := d.now()
+
+	missing := d.lookup(ids, names, now)
+	for len(missing) > 0 {
+		batch := missing[:min(len(missing), maxUsersPerCall)]
+		missing = missing[len(batch):]
+
+		resp, err := d.client.GetUsers(ctx, &pb.GetUsersRequest{Ids: batch})
+		if err == nil && resp != nil {
+			d.store(batch, resp.Users, names, now)
+		}
+	}
+	return names
+}
+
+func (d *userDirectory) lookup(ids []int64, names map[int64]string, now time.Time) []int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var missing []int64
+	seen := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		entry, ok := d.entries[id]
+		if !ok || now.Sub(entry.fetchedAt) >= d.ttl {
+			missing = append(missing, id)
+			continue
+		}
+		if entry.found {
+			names[id] = entry.username
+		}
+	}
+	return missing
+}
+
+func (d *userDirectory) store(requested []int64, users []*pb.User, names map[int64]string, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Устаревшие записи вычищаем по ходу, чтобы кэш не рос бесконечно.
+	if len(d.entries) >= userCachePruneThreshold {
+		for id, entry := range d.entries {
+			if now.Sub(entry.fetchedAt) >= d.ttl {
+				delete(d.entries, id)
+			}
+		}
+	}
+
+	for _, id := range requested {
+		d.entries[id] = userCacheEntry{fetchedAt: now}
+	}
+	for _, user := range users {
+		if user == nil {
+			continue
+		}
+		d.entries[user.Id] = userCacheEntry{username: user.Username, found: true, fetchedAt: now}
+		names[user.Id] = user.Username
+	}
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "backend.com/forum/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestUserDirectory_Usernames(t *testing.T) {
+	var calls [][]int64
+	client := &MockAuthServiceClient{
+		GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+			calls = append(calls, in.Ids)
+			return &pb.GetUsersResponse{Users: []*pb.User{{Id: 1, Username: "alice"}}}, nil
+		},
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	dir := newUserDirectory(client, time.Minute)
+	dir.now = func() time.Time { return now }
+
+	names := dir.Usernames(context.Background(), []int64{1, 2, 1})
+	assert.Equal(t, map[int64]string{1: "alice"}, names)
+	assert.Equal(t, [][]int64{{1, 2}}, calls)
+
+	// Найденные и ненайденные id берутся из кэша, пока не истек ttl.
+	names = dir.Usernames(context.Background(), []int64{2, 1})
+	assert.Equal(t, map[int64]string{1: "alice"}, names)
+	assert.Len(t, calls, 1)
+
+	now = now.Add(time.Minute)
+	dir.Usernames(context.Background(), []int64{1})
+	assert.Equal(t, [][]int64{{1, 2}, {1}}, calls)
+}
+
+func TestUserDirectory_AuthErrorNotCached(t *testing.T) {
+	fail := true
+	client := &MockAuthServiceClient{
+		GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+			if fail {
+				return nil, errors.New("auth unavailable")
+			}
+			return &pb.GetUsersResponse{Users: []*pb.User{{Id: 1, Username: "alice"}}}, nil
+		},
+	}
+	dir := newUserDirectory(client, time.Minute)
+
+	assert.Empty(t, dir.Usernames(context.Background(), []int64{1}))
+
+	fail = false
+	assert.Equal(t, map[int64]string{1: "alice"}, dir.Usernames(context.Background(), []int64{1}))
+}
+
+func TestUserDirectory_SplitsLargeRequests(t *testing.T) {
+	var sizes []int
+	client := &MockAuthServiceClient{
+		GetUsersFunc: func(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+			sizes = append(sizes, len(in.Ids))
+			return &pb.GetUsersResponse{}, nil
+		},
+	}
+	dir := newUserDirectory(client, time.Minute)
+
+	ids := make([]int64, maxUsersPerCall+1)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	dir.Usernames(context.Background(), ids)
+
+	assert.Equal(t, []int{maxUsersPerCall, 1}, sizes)
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+)
+
+var ErrInvalidVote = errors.New("vote must be 1, -1 or 0")
+
+// VoteUsecase принимает голоса за посты и комментарии. У пользователя
+// один голос на запись: повторный голос заменяет прежний, 0 — снимает его.
+type VoteUsecase struct {
+	postRepo    repository.PostRepository
+	commentRepo repository.CommentRepository
+}
+
+func NewVoteUsecase(postRepo repository.PostRepository, commentRepo repository.CommentRepository) *VoteUsecase {
+	return &VoteUsecase{postRepo: postRepo, commentRepo: commentRepo}
+}
+
+func (uc *VoteUsecase) VotePost(ctx context.Context, user *authmw.Principal, postID int64, value int) (*entity.VoteResult, error) {
+	if !validVote(value) {
+		return nil, ErrInvalidVote
+	}
+	score, err := uc.postRepo.VotePost(ctx, postID, user.UserID, value)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.VoteResult{Score: score, MyVote: value}, nil
+}
+
+// VoteComment голосует за комментарий postID/commentID; комментарий
+// из другого поста считается ненайденным.
+func (uc *VoteUsecase) VoteComment(ctx context.Context, user *authmw.Principal, postID, commentID int64, value int) (*entity.VoteResult, error) {
+	if !validVote(value) {
+		return nil, ErrInvalidVote
+	}
+	comment, err := uc.commentRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.PostID != postID || comment.Deleted {
+		return nil, repository.ErrCommentNotFound
+	}
+
+	score, err := uc.commentRepo.VoteComment(ctx, commentID, user.UserID, value)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.VoteResult{Score: score, MyVote: value}, nil
+}
+
+func validVote(value int) bool {
+	return value >= -1 && value <= 1
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"backend.com/forum/authmw"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
+	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteUsecase_VotePost(t *testing.T) {
+	user := &authmw.Principal{UserID: 7}
+	var gotValue int
+	uc := NewVoteUsecase(&MockPostRepository{
+		VotePostFunc: func(ctx context.Context, postID, userID int64, value int) (int, error) {
+			assert.Equal(t, int64(1), postID)
+			assert.Equal(t, int64(7), userID)
+			gotValue = value
+			return 5, nil
+		},
+	}, &MockCommentRepository{})
+
+	result, err := uc.VotePost(context.Background(), user, 1, -1)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.VoteResult{Score: 5, MyVote: -1}, result)
+	assert.Equal(t, -1, gotValue)
+
+	_, err = uc.VotePost(context.Background(), user, 1, 2)
+	assert.ErrorIs(t, err, ErrInvalidVote)
+}
+
+func TestVoteUsecase_VoteComment(t *testing.T) {
+	user := &authmw.Principal{UserID: 7}
+	comments := map[int64]*entity.Comment{
+		1: {ID: 1, PostID: 10},
+		2: {ID: 2, PostID: 11},
+		3: {ID: 3, PostID: 10, Deleted: true},
+	}
+	voted := false
+	uc := NewVoteUsecase(&MockPostRepository{}, &MockCommentRepository{
+		GetCommentByIDFunc: func(ctx context.Context, id int64) (*entity.Comment, error) {
+			if comment, ok := comments[id]; ok {
+				return comment, nil
+			}
+			return nil, repository.ErrCommentNotFound
+		},
+		VoteCommentFunc: func(ctx context.Context, commentID, userID int64, value int) (int, error) {
+			voted = true
+			return 1, nil
+		},
+	})
+
+	tests := []struct {
+		name      string
+		commentID int64
+		value     int
+		want      *entity.VoteResult
+		wantErr   error
+	}{
+		{name: "Success", commentID: 1, value: 1, want: &entity.VoteResult{Score: 1, MyVote: 1}},
+		{name: "Invalid value", commentID: 1, value: 5, wantErr: ErrInvalidVote},
+		{name: "Comment of another post", commentID: 2, value: 1, wantErr: repository.ErrCommentNotFound},
+		{name: "Deleted comment", commentID: 3, value: 1, wantErr: repository.ErrCommentNotFound},
+		{name: "Missing comment", commentID: 4, value: 1, wantErr: repository.ErrCommentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voted = false
+			result, err := uc.VoteComment(context.Background(), user, 10, tt.commentID, tt.value)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.False(t, voted)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+			assert.True(t, voted)
+		})
+	}
+}
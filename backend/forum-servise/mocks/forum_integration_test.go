@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"backend.com/forum/authmw"
 	pb "backend.com/forum/proto"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/Mandarinka0707/newRepoGOODarhit/forum-servise/internal/entity"
@@ -29,6 +30,7 @@ type mockAuthClient struct {
 	pb.AuthServiceClient
 	validateFunc func(context.Context, *pb.ValidateTokenRequest, ...grpc.CallOption) (*pb.ValidateTokenResponse, error)
 	getUserFunc  func(context.Context, *pb.GetUserRequest, ...grpc.CallOption) (*pb.GetUserResponse, error)
+	getUsersFunc func(context.Context, *pb.GetUsersRequest, ...grpc.CallOption) (*pb.GetUsersResponse, error)
 }
 
 func (m *mockAuthClient) ValidateToken(ctx context.Context, in *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
@@ -39,6 +41,30 @@ func (m *mockAuthClient) GetUser(ctx context.Context, in *pb.GetUserRequest, opt
 	return m.getUserFunc(ctx, in, opts...)
 }
 
+func (m *mockAuthClient) GetUsers(ctx context.Context, in *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+	return m.getUsersFunc(ctx, in, opts...)
+}
+
+var testUser = &authmw.Principal{UserID: 1, Username: "testuser", Role: "user"}
+
+// requireAuth пропускает только запросы с "Bearer valid_token".
+func requireAuth() gin.HandlerFunc {
+	authClient := &mockAuthClient{
+		validateFunc: func(ctx context.Context, req *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
+			if req.Token != "valid_token" {
+				return &pb.ValidateTokenResponse{Valid: false}, nil
+			}
+			return &pb.ValidateTokenResponse{
+				Valid:    true,
+				UserId:   testUser.UserID,
+				Username: testUser.Username,
+				Role:     testUser.Role,
+			}, nil
+		},
+	}
+	return authmw.NewAuthenticator(nil, authClient).Required()
+}
+
 type testDependencies struct {
 	db          *sql.DB
 	mock        sqlmock.Sqlmock
@@ -70,6 +96,13 @@ func setupTest(t *testing.T) *testDependencies {
 				},
 			}, nil
 		},
+		getUsersFunc: func(ctx context.Context, req *pb.GetUsersRequest, opts ...grpc.CallOption) (*pb.GetUsersResponse, error) {
+			users := make([]*pb.User, 0, len(req.Ids))
+			for _, id := range req.Ids {
+				users = append(users, &pb.User{Id: id, Username: "testuser"})
+			}
+			return &pb.GetUsersResponse{Users: users}, nil
+		},
 	}
 
 	postRepo := repository.NewPostRepository(sqlxDB)
@@ -95,14 +128,14 @@ func TestForumService_Integrated(t *testing.T) {
 
 		t.Run("Create and get post", func(t *testing.T) {
 			now := time.Now()
-			createQuery := `INSERT INTO posts (title, content, author_id, created_at) VALUES ($1, $2, $3, $4) RETURNING id`
-			getQuery := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
+			createQuery := `WITH created AS ( INSERT INTO posts (title, content, content_html, author_id, created_at, topic_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, title, content, author_id, created_at ), saved AS ( INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at) SELECT id, 1, title, content, author_id, created_at FROM created ) SELECT id FROM created`
+			getQuery := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
 
 			deps.mock.ExpectQuery(createQuery).
-				WithArgs("Test Post", "Test Content", int64(1), sqlmock.AnyArg()).
+				WithArgs("Test Post", "Test Content", "<p>Test Content</p>\n", int64(1), sqlmock.AnyArg(), nil).
 				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
-			post, err := deps.postUC.CreatePost(context.Background(), "valid_token", "Test Post", "Test Content")
+			post, err := deps.postUC.CreatePost(context.Background(), testUser, "Test Post", "Test Content", nil, nil)
 			require.NoError(t, err)
 			assert.Equal(t, int64(1), post.ID)
 
@@ -117,23 +150,46 @@ func TestForumService_Integrated(t *testing.T) {
 		})
 
 		t.Run("Get posts list", func(t *testing.T) {
-			query := `SELECT id, title, content, author_id, created_at FROM posts ORDER BY created_at DESC`
+			query := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`
 			now := time.Now()
 
 			deps.mock.ExpectQuery(query).
+				WithArgs(usecase.DefaultPostsPageSize+1, 0).
 				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
 					AddRow(1, "First Post", "First Content", int64(1), now).
 					AddRow(2, "Second Post", "Second Content", int64(2), now.Add(-time.Hour)))
-
-			posts, authorNames, err := deps.postUC.GetPosts(context.Background())
+			deps.mock.ExpectQuery(`SELECT COUNT(*) FROM posts WHERE deleted_at IS NULL`).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+			deps.mock.ExpectQuery(`SELECT post_id AS item_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = $2) AS mine FROM post_reactions WHERE post_id = ANY($1) GROUP BY post_id, emoji ORDER BY post_id, MIN(created_at), emoji`).
+				WithArgs(sqlmock.AnyArg(), int64(0)).
+				WillReturnRows(sqlmock.NewRows([]string{"item_id", "emoji", "count", "mine"}).AddRow(1, "👍", 3, false))
+			deps.mock.ExpectQuery(`SELECT pt.post_id, t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = ANY($1) ORDER BY pt.post_id, t.name`).
+				WithArgs(sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"post_id", "name"}).AddRow(2, "go"))
+			deps.mock.ExpectQuery(`SELECT a.id, a.post_id, a.uploader_id, a.file_name, a.content_type, a.size, a.storage_key, a.thumbnail_key, a.created_at FROM attachments a WHERE a.post_id = ANY($1) ORDER BY a.post_id, a.id`).
+				WithArgs(sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "uploader_id", "file_name", "content_type", "size", "storage_key", "thumbnail_key", "created_at"}).
+					AddRow(3, 1, 1, "shot.png", "image/png", 1024, "posts/1/abc", "posts/1/abc_thumb", now))
+
+			page, authorNames, err := deps.postUC.GetPosts(context.Background(), entity.PostListQuery{})
 			require.NoError(t, err)
-			assert.Len(t, posts, 2)
+			assert.Len(t, page.Posts, 2)
+			assert.Equal(t, int64(2), page.Total)
+			assert.Empty(t, page.NextCursor)
 			assert.Equal(t, "testuser", authorNames[1])
+			assert.Equal(t, []entity.Reaction{{Emoji: "👍", Count: 3}}, page.Posts[0].Reactions)
+			assert.Empty(t, page.Posts[1].Reactions)
+			assert.Equal(t, []string{}, page.Posts[0].Tags)
+			assert.Equal(t, []string{"go"}, page.Posts[1].Tags)
+			require.Len(t, page.Posts[0].Attachments, 1)
+			assert.Equal(t, "/api/v1/attachments/3", page.Posts[0].Attachments[0].URL)
+			assert.Equal(t, "/api/v1/attachments/3/thumbnail", page.Posts[0].Attachments[0].ThumbnailURL)
+			assert.Equal(t, []entity.Attachment{}, page.Posts[1].Attachments)
 		})
 
 		t.Run("Create comment", func(t *testing.T) {
-			postQuery := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
-			commentQuery := `INSERT INTO comments (content, author_id, post_id, author_name) VALUES ($1, $2, $3, $4) RETURNING id`
+			postQuery := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
+			commentQuery := `INSERT INTO comments (content, author_id, post_id, author_name, parent_id, depth, content_html) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
 
 			deps.mock.ExpectQuery(postQuery).
 				WithArgs(int64(1)).
@@ -141,8 +197,8 @@ func TestForumService_Integrated(t *testing.T) {
 					AddRow(1, "Test Post", "Test Content", int64(1), time.Now()))
 
 			deps.mock.ExpectQuery(commentQuery).
-				WithArgs("Test Comment", int64(1), int64(1), "testuser").
-				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				WithArgs("Test Comment", int64(1), int64(1), "testuser", nil, 0, "<p>Test Comment</p>\n").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
 
 			comment := &entity.Comment{
 				Content:  "Test Comment",
@@ -150,14 +206,14 @@ func TestForumService_Integrated(t *testing.T) {
 				PostID:   1,
 			}
 
-			err := deps.commentUC.CreateComment(context.Background(), comment)
+			err := deps.commentUC.CreateComment(context.Background(), testUser, comment)
 			require.NoError(t, err)
 			assert.Equal(t, int64(1), comment.ID)
 		})
 
 		t.Run("Get comments", func(t *testing.T) {
-			postQuery := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
-			commentQuery := `SELECT id, content, author_id, post_id, author_name FROM comments WHERE post_id = $1 ORDER BY id DESC`
+			postQuery := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
+			commentQuery := `SELECT c.id, c.content, c.content_html, c.author_id, c.post_id, c.author_name, c.created_at, c.updated_at, c.updated_at IS NOT NULL AS edited, c.parent_id, c.depth, c.deleted_at, c.deleted_by, c.deleted_at IS NOT NULL AS deleted, c.score, (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND (r.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = r.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL))) AS reply_count FROM comments c WHERE c.post_id = $1 AND c.parent_id IS NULL AND (c.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL)) ORDER BY c.id DESC LIMIT $2 OFFSET $3`
 
 			deps.mock.ExpectQuery(postQuery).
 				WithArgs(int64(1)).
@@ -165,36 +221,49 @@ func TestForumService_Integrated(t *testing.T) {
 					AddRow(1, "Test Post", "Test Content", int64(1), time.Now()))
 
 			deps.mock.ExpectQuery(commentQuery).
-				WithArgs(int64(1)).
+				WithArgs(int64(1), 20, 0).
 				WillReturnRows(sqlmock.NewRows([]string{"id", "content", "author_id", "post_id", "author_name"}).
 					AddRow(1, "First Comment", int64(1), int64(1), "testuser"))
+			deps.mock.ExpectQuery(`SELECT COUNT(*) FROM comments c WHERE c.post_id = $1 AND c.parent_id IS NULL AND (c.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL))`).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			deps.mock.ExpectQuery(`SELECT comment_id AS item_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = $2) AS mine FROM comment_reactions WHERE comment_id = ANY($1) GROUP BY comment_id, emoji ORDER BY comment_id, MIN(created_at), emoji`).
+				WithArgs(sqlmock.AnyArg(), int64(0)).
+				WillReturnRows(sqlmock.NewRows([]string{"item_id", "emoji", "count", "mine"}))
 
-			comments, err := deps.commentUC.GetCommentsByPostID(context.Background(), 1)
+			page, err := deps.commentUC.GetCommentsByPostID(context.Background(), 1, entity.CommentTreeQuery{Limit: 20})
 			require.NoError(t, err)
-			assert.Len(t, comments, 1)
+			assert.Len(t, page.Comments, 1)
+			assert.Equal(t, int64(1), page.Total)
 		})
 
 		t.Run("Update post", func(t *testing.T) {
-			query := `UPDATE posts SET title = $1, content = $2 WHERE id = $3 AND (author_id = $4 OR $5 = 'admin') RETURNING id, title, content, author_id, created_at`
+			query := `WITH updated AS ( UPDATE posts SET title = $1, content = $2, content_html = $6, revision = revision + 1 WHERE id = $3 AND deleted_at IS NULL AND (author_id = $4 OR $5 = 'admin') RETURNING id, title, content, content_html, author_id, created_at, topic_id, score, revision ), saved AS ( INSERT INTO post_revisions (post_id, revision, title, content, editor_id) SELECT id, revision, title, content, $4 FROM updated ) SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM updated`
 
 			deps.mock.ExpectQuery(query).
-				WithArgs("Updated Title", "Updated Content", int64(1), int64(1), "user").
-				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
-					AddRow(1, "Updated Title", "Updated Content", int64(1), time.Now()))
-
-			post, err := deps.postUC.UpdatePost(context.Background(), "valid_token", 1, "Updated Title", "Updated Content")
+				WithArgs("Updated Title", "Updated Content", int64(1), int64(1), "user", "<p>Updated Content</p>\n").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "content_html", "author_id", "created_at", "topic_id", "score"}).
+					AddRow(1, "Updated Title", "Updated Content", "<p>Updated Content</p>\n", int64(1), time.Now(), nil, 0))
+			deps.mock.ExpectQuery(`SELECT pt.post_id, t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = ANY($1) ORDER BY pt.post_id, t.name`).
+				WithArgs(sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"post_id", "name"}))
+			deps.mock.ExpectQuery(`SELECT a.id, a.post_id, a.uploader_id, a.file_name, a.content_type, a.size, a.storage_key, a.thumbnail_key, a.created_at FROM attachments a WHERE a.post_id = ANY($1) ORDER BY a.post_id, a.id`).
+				WithArgs(sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "uploader_id", "file_name", "content_type", "size", "storage_key", "thumbnail_key", "created_at"}))
+
+			post, err := deps.postUC.UpdatePost(context.Background(), testUser, 1, "Updated Title", "Updated Content", nil)
 			require.NoError(t, err)
 			assert.Equal(t, "Updated Title", post.Title)
 		})
 
 		t.Run("Delete post", func(t *testing.T) {
-			query := `DELETE FROM posts WHERE id = $1 AND (author_id = $2 OR $3 = 'admin')`
+			query := `UPDATE posts SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL AND (author_id = $2 OR $3 = 'admin')`
 
 			deps.mock.ExpectExec(query).
 				WithArgs(int64(1), int64(1), "user").
 				WillReturnResult(sqlmock.NewResult(0, 1))
 
-			err := deps.postUC.DeletePost(context.Background(), "valid_token", 1)
+			err := deps.postUC.DeletePost(context.Background(), testUser, 1)
 			require.NoError(t, err)
 		})
 
@@ -206,28 +275,29 @@ func TestForumService_Integrated(t *testing.T) {
 		defer deps.db.Close()
 
 		t.Run("Create post database error", func(t *testing.T) {
-			query := `INSERT INTO posts (title, content, author_id, created_at) VALUES ($1, $2, $3, $4) RETURNING id`
+			query := `WITH created AS ( INSERT INTO posts (title, content, content_html, author_id, created_at, topic_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, title, content, author_id, created_at ), saved AS ( INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at) SELECT id, 1, title, content, author_id, created_at FROM created ) SELECT id FROM created`
 
 			deps.mock.ExpectQuery(query).
-				WithArgs("Bad Post", "Bad Content", int64(1), sqlmock.AnyArg()).
+				WithArgs("Bad Post", "Bad Content", "<p>Bad Content</p>\n", int64(1), sqlmock.AnyArg(), nil).
 				WillReturnError(errors.New("database error"))
 
-			_, err := deps.postUC.CreatePost(context.Background(), "valid_token", "Bad Post", "Bad Content")
+			_, err := deps.postUC.CreatePost(context.Background(), testUser, "Bad Post", "Bad Content", nil, nil)
 			require.Error(t, err)
 		})
 
 		t.Run("Get posts list error", func(t *testing.T) {
-			query := `SELECT id, title, content, author_id, created_at FROM posts ORDER BY created_at DESC`
+			query := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`
 
 			deps.mock.ExpectQuery(query).
+				WithArgs(usecase.DefaultPostsPageSize+1, 0).
 				WillReturnError(errors.New("database error"))
 
-			_, _, err := deps.postUC.GetPosts(context.Background())
+			_, _, err := deps.postUC.GetPosts(context.Background(), entity.PostListQuery{})
 			require.Error(t, err)
 		})
 
 		t.Run("Create comment for non-existent post", func(t *testing.T) {
-			query := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
+			query := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
 
 			deps.mock.ExpectQuery(query).
 				WithArgs(int64(999)).
@@ -239,48 +309,35 @@ func TestForumService_Integrated(t *testing.T) {
 				PostID:   999,
 			}
 
-			err := deps.commentUC.CreateComment(context.Background(), comment)
+			err := deps.commentUC.CreateComment(context.Background(), testUser, comment)
 			require.Error(t, err)
 			assert.True(t, errors.Is(err, repository.ErrPostNotFound))
 		})
 
 		t.Run("Update non-existent post", func(t *testing.T) {
-			query := `UPDATE posts SET title = $1, content = $2 WHERE id = $3 AND (author_id = $4 OR $5 = 'admin') RETURNING id, title, content, author_id, created_at`
+			query := `WITH updated AS ( UPDATE posts SET title = $1, content = $2, content_html = $6, revision = revision + 1 WHERE id = $3 AND deleted_at IS NULL AND (author_id = $4 OR $5 = 'admin') RETURNING id, title, content, content_html, author_id, created_at, topic_id, score, revision ), saved AS ( INSERT INTO post_revisions (post_id, revision, title, content, editor_id) SELECT id, revision, title, content, $4 FROM updated ) SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM updated`
 
 			deps.mock.ExpectQuery(query).
-				WithArgs("New Title", "New Content", int64(999), int64(1), "user").
+				WithArgs("New Title", "New Content", int64(999), int64(1), "user", "<p>New Content</p>\n").
 				WillReturnError(sql.ErrNoRows)
 
-			_, err := deps.postUC.UpdatePost(context.Background(), "valid_token", 999, "New Title", "New Content")
+			_, err := deps.postUC.UpdatePost(context.Background(), testUser, 999, "New Title", "New Content", nil)
 			require.Error(t, err)
 			assert.True(t, errors.Is(err, repository.ErrPostNotFound))
 		})
 
 		t.Run("Delete non-existent post", func(t *testing.T) {
-			query := `DELETE FROM posts WHERE id = $1 AND (author_id = $2 OR $3 = 'admin')`
+			query := `UPDATE posts SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL AND (author_id = $2 OR $3 = 'admin')`
 
 			deps.mock.ExpectExec(query).
 				WithArgs(int64(999), int64(1), "user").
 				WillReturnResult(sqlmock.NewResult(0, 0))
 
-			err := deps.postUC.DeletePost(context.Background(), "valid_token", 999)
+			err := deps.postUC.DeletePost(context.Background(), testUser, 999)
 			require.Error(t, err)
 			assert.True(t, errors.Is(err, repository.ErrPostNotFound))
 		})
 
-		t.Run("Authentication error", func(t *testing.T) {
-			errorAuthClient := &mockAuthClient{
-				validateFunc: func(ctx context.Context, req *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-					return nil, errors.New("auth service error")
-				},
-			}
-
-			errorPostUC := usecase.NewPostUsecase(deps.postRepo, errorAuthClient, nil)
-
-			_, err := errorPostUC.CreatePost(context.Background(), "invalid_token", "Test", "Content")
-			require.Error(t, err)
-		})
-
 		require.NoError(t, deps.mock.ExpectationsWereMet())
 	})
 
@@ -289,20 +346,23 @@ func TestForumService_Integrated(t *testing.T) {
 		defer deps.db.Close()
 
 		t.Run("Empty posts list", func(t *testing.T) {
-			query := `SELECT id, title, content, author_id, created_at FROM posts ORDER BY created_at DESC`
+			query := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`
 
 			deps.mock.ExpectQuery(query).
+				WithArgs(usecase.DefaultPostsPageSize+1, 0).
 				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}))
+			deps.mock.ExpectQuery(`SELECT COUNT(*) FROM posts WHERE deleted_at IS NULL`).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
-			posts, authorNames, err := deps.postUC.GetPosts(context.Background())
+			page, authorNames, err := deps.postUC.GetPosts(context.Background(), entity.PostListQuery{})
 			require.NoError(t, err)
-			assert.Empty(t, posts)
+			assert.Empty(t, page.Posts)
 			assert.Empty(t, authorNames)
 		})
 
 		t.Run("Create comment database error", func(t *testing.T) {
-			postQuery := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
-			commentQuery := `INSERT INTO comments (content, author_id, post_id, author_name) VALUES ($1, $2, $3, $4) RETURNING id`
+			postQuery := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
+			commentQuery := `INSERT INTO comments (content, author_id, post_id, author_name, parent_id, depth, content_html) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
 
 			deps.mock.ExpectQuery(postQuery).
 				WithArgs(int64(1)).
@@ -310,7 +370,7 @@ func TestForumService_Integrated(t *testing.T) {
 					AddRow(1, "Test Post", "Test Content", int64(1), time.Now()))
 
 			deps.mock.ExpectQuery(commentQuery).
-				WithArgs("Bad Comment", int64(1), int64(1), "testuser").
+				WithArgs("Bad Comment", int64(1), int64(1), "testuser", nil, 0, "<p>Bad Comment</p>\n").
 				WillReturnError(errors.New("database error"))
 
 			comment := &entity.Comment{
@@ -319,44 +379,32 @@ func TestForumService_Integrated(t *testing.T) {
 				PostID:   1,
 			}
 
-			err := deps.commentUC.CreateComment(context.Background(), comment)
+			err := deps.commentUC.CreateComment(context.Background(), testUser, comment)
 			require.Error(t, err)
 		})
 
 		t.Run("Update post as admin", func(t *testing.T) {
+			admin := &authmw.Principal{UserID: 2, Username: "admin", Role: "admin"}
 
-			authClient := &mockAuthClient{
-				validateFunc: func(ctx context.Context, req *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-					return &pb.ValidateTokenResponse{
-						Valid:  true,
-						UserId: 2,
-						Role:   "admin",
-					}, nil
-				},
-			}
-
-			postUC := usecase.NewPostUsecase(deps.postRepo, authClient, nil)
-
-			query := `UPDATE posts SET title = $1, content = $2 WHERE id = $3 AND (author_id = $4 OR $5 = 'admin') RETURNING id, title, content, author_id, created_at`
+			query := `WITH updated AS ( UPDATE posts SET title = $1, content = $2, content_html = $6, revision = revision + 1 WHERE id = $3 AND deleted_at IS NULL AND (author_id = $4 OR $5 = 'admin') RETURNING id, title, content, content_html, author_id, created_at, topic_id, score, revision ), saved AS ( INSERT INTO post_revisions (post_id, revision, title, content, editor_id) SELECT id, revision, title, content, $4 FROM updated ) SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM updated`
 
 			deps.mock.ExpectQuery(query).
-				WithArgs("Admin Updated", "Admin Content", int64(1), int64(2), "admin").
-				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
-					AddRow(1, "Admin Updated", "Admin Content", int64(1), time.Now()))
-
-			_, err := postUC.UpdatePost(context.Background(), "admin_token", 1, "Admin Updated", "Admin Content")
+				WithArgs("Admin Updated", "Admin Content", int64(1), int64(2), "admin", "<p>Admin Content</p>\n").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "content_html", "author_id", "created_at", "topic_id", "score"}).
+					AddRow(1, "Admin Updated", "Admin Content", "<p>Admin Content</p>\n", int64(1), time.Now(), nil, 0))
+			deps.mock.ExpectQuery(`SELECT pt.post_id, t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = ANY($1) ORDER BY pt.post_id, t.name`).
+				WithArgs(sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"post_id", "name"}))
+			deps.mock.ExpectQuery(`SELECT a.id, a.post_id, a.uploader_id, a.file_name, a.content_type, a.size, a.storage_key, a.thumbnail_key, a.created_at FROM attachments a WHERE a.post_id = ANY($1) ORDER BY a.post_id, a.id`).
+				WithArgs(sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "uploader_id", "file_name", "content_type", "size", "storage_key", "thumbnail_key", "created_at"}))
+
+			_, err := deps.postUC.UpdatePost(context.Background(), admin, 1, "Admin Updated", "Admin Content", nil)
 			require.NoError(t, err)
 		})
 
 		t.Run("Get user error", func(t *testing.T) {
 			authClient := &mockAuthClient{
-				validateFunc: func(ctx context.Context, req *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-					return &pb.ValidateTokenResponse{
-						Valid:  true,
-						UserId: 1,
-						Role:   "user",
-					}, nil
-				},
 				getUserFunc: func(ctx context.Context, req *pb.GetUserRequest, opts ...grpc.CallOption) (*pb.GetUserResponse, error) {
 					return nil, errors.New("user service error")
 				},
@@ -364,7 +412,7 @@ func TestForumService_Integrated(t *testing.T) {
 
 			commentUC := usecase.NewCommentUseCase(deps.commentRepo, deps.postRepo, authClient)
 
-			deps.mock.ExpectQuery(`SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`).
+			deps.mock.ExpectQuery(`SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`).
 				WithArgs(int64(1)).
 				WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at"}).
 					AddRow(1, "Test Post", "Test Content", int64(1), time.Now()))
@@ -375,53 +423,26 @@ func TestForumService_Integrated(t *testing.T) {
 				PostID:   1,
 			}
 
-			err := commentUC.CreateComment(context.Background(), comment)
+			err := commentUC.CreateComment(context.Background(), &authmw.Principal{UserID: 1, Role: "user"}, comment)
 			require.Error(t, err)
 		})
 
-		t.Run("Invalid token", func(t *testing.T) {
-			authClient := &mockAuthClient{
-				validateFunc: func(ctx context.Context, req *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-					return &pb.ValidateTokenResponse{
-						Valid: false,
-					}, nil
-				},
-			}
-
-			postUC := usecase.NewPostUsecase(deps.postRepo, authClient, nil)
-
-			_, err := postUC.CreatePost(context.Background(), "invalid_token", "Test", "Content")
-			require.Error(t, err)
-			assert.Contains(t, err.Error(), "invalid token")
-		})
-
 		t.Run("Update post without permission", func(t *testing.T) {
+			stranger := &authmw.Principal{UserID: 2, Username: "stranger", Role: "user"}
 
-			authClient := &mockAuthClient{
-				validateFunc: func(ctx context.Context, req *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-					return &pb.ValidateTokenResponse{
-						Valid:  true,
-						UserId: 2,
-						Role:   "user",
-					}, nil
-				},
-			}
-
-			postUC := usecase.NewPostUsecase(deps.postRepo, authClient, nil)
-
-			query := `UPDATE posts SET title = $1, content = $2 WHERE id = $3 AND (author_id = $4 OR $5 = 'admin') RETURNING id, title, content, author_id, created_at`
+			query := `WITH updated AS ( UPDATE posts SET title = $1, content = $2, content_html = $6, revision = revision + 1 WHERE id = $3 AND deleted_at IS NULL AND (author_id = $4 OR $5 = 'admin') RETURNING id, title, content, content_html, author_id, created_at, topic_id, score, revision ), saved AS ( INSERT INTO post_revisions (post_id, revision, title, content, editor_id) SELECT id, revision, title, content, $4 FROM updated ) SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM updated`
 
 			deps.mock.ExpectQuery(query).
-				WithArgs("New Title", "New Content", int64(1), int64(2), "user").
+				WithArgs("New Title", "New Content", int64(1), int64(2), "user", "<p>New Content</p>\n").
 				WillReturnError(repository.ErrPermissionDenied)
 
-			_, err := postUC.UpdatePost(context.Background(), "valid_token", 1, "New Title", "New Content")
+			_, err := deps.postUC.UpdatePost(context.Background(), stranger, 1, "New Title", "New Content", nil)
 			require.Error(t, err)
 			assert.True(t, errors.Is(err, repository.ErrPermissionDenied))
 		})
 		t.Run("Get comments database error", func(t *testing.T) {
-			postQuery := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
-			commentQuery := `SELECT id, content, author_id, post_id, author_name FROM comments WHERE post_id = $1 ORDER BY id DESC`
+			postQuery := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
+			commentQuery := `SELECT c.id, c.content, c.content_html, c.author_id, c.post_id, c.author_name, c.created_at, c.updated_at, c.updated_at IS NOT NULL AS edited, c.parent_id, c.depth, c.deleted_at, c.deleted_by, c.deleted_at IS NOT NULL AS deleted, c.score, (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND (r.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = r.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL))) AS reply_count FROM comments c WHERE c.post_id = $1 AND c.parent_id IS NULL AND (c.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL)) ORDER BY c.id DESC LIMIT $2 OFFSET $3`
 
 			deps.mock.ExpectQuery(postQuery).
 				WithArgs(int64(1)).
@@ -429,16 +450,16 @@ func TestForumService_Integrated(t *testing.T) {
 					AddRow(1, "Test Post", "Test Content", int64(1), time.Now()))
 
 			deps.mock.ExpectQuery(commentQuery).
-				WithArgs(int64(1)).
+				WithArgs(int64(1), 20, 0).
 				WillReturnError(errors.New("database error"))
 
-			_, err := deps.commentUC.GetCommentsByPostID(context.Background(), 1)
+			_, err := deps.commentUC.GetCommentsByPostID(context.Background(), 1, entity.CommentTreeQuery{Limit: 20})
 			require.Error(t, err)
 		})
 
 		t.Run("Empty comments list", func(t *testing.T) {
-			postQuery := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
-			commentQuery := `SELECT id, content, author_id, post_id, author_name FROM comments WHERE post_id = $1 ORDER BY id DESC`
+			postQuery := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
+			commentQuery := `SELECT c.id, c.content, c.content_html, c.author_id, c.post_id, c.author_name, c.created_at, c.updated_at, c.updated_at IS NOT NULL AS edited, c.parent_id, c.depth, c.deleted_at, c.deleted_by, c.deleted_at IS NOT NULL AS deleted, c.score, (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND (r.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = r.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL))) AS reply_count FROM comments c WHERE c.post_id = $1 AND c.parent_id IS NULL AND (c.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL)) ORDER BY c.id DESC LIMIT $2 OFFSET $3`
 
 			deps.mock.ExpectQuery(postQuery).
 				WithArgs(int64(1)).
@@ -446,12 +467,15 @@ func TestForumService_Integrated(t *testing.T) {
 					AddRow(1, "Test Post", "Test Content", int64(1), time.Now()))
 
 			deps.mock.ExpectQuery(commentQuery).
-				WithArgs(int64(1)).
+				WithArgs(int64(1), 20, 0).
 				WillReturnRows(sqlmock.NewRows([]string{"id", "content", "author_id", "post_id", "author_name"}))
+			deps.mock.ExpectQuery(`SELECT COUNT(*) FROM comments c WHERE c.post_id = $1 AND c.parent_id IS NULL AND (c.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL))`).
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
-			comments, err := deps.commentUC.GetCommentsByPostID(context.Background(), 1)
+			page, err := deps.commentUC.GetCommentsByPostID(context.Background(), 1, entity.CommentTreeQuery{Limit: 20})
 			require.NoError(t, err)
-			assert.Empty(t, comments)
+			assert.Empty(t, page.Comments)
 		})
 
 		require.NoError(t, deps.mock.ExpectationsWereMet())
@@ -464,7 +488,7 @@ func TestPostHandler(t *testing.T) {
 
 	t.Run("CreatePost success", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			createFunc: func(ctx context.Context, token, title, content string) (*entity.Post, error) {
+			createFunc: func(ctx context.Context, author *authmw.Principal, title, content string, topicID *int64, tags []string) (*entity.Post, error) {
 				return &entity.Post{
 					ID:        1,
 					Title:     title,
@@ -478,7 +502,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(mockUC, mockLogger)
 
 		router := gin.Default()
-		router.POST("/posts", handler.CreatePost)
+		router.POST("/posts", requireAuth(), handler.CreatePost)
 
 		requestBody := `{"title": "Test Post", "content": "Test Content"}`
 		req, _ := http.NewRequest("POST", "/posts", bytes.NewBufferString(requestBody))
@@ -491,7 +515,7 @@ func TestPostHandler(t *testing.T) {
 	})
 	t.Run("DeletePost success", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			deleteFunc: func(ctx context.Context, token string, postID int64) error {
+			deleteFunc: func(ctx context.Context, user *authmw.Principal, postID int64) error {
 				return nil
 			},
 		}
@@ -499,7 +523,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(mockUC, mockLogger)
 
 		router := gin.Default()
-		router.DELETE("/posts/:id", handler.DeletePost)
+		router.DELETE("/posts/:id", requireAuth(), handler.DeletePost)
 
 		req, _ := http.NewRequest("DELETE", "/posts/1", nil)
 		req.Header.Set("Authorization", "Bearer valid_token")
@@ -512,7 +536,7 @@ func TestPostHandler(t *testing.T) {
 
 	t.Run("DeletePost not found", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			deleteFunc: func(ctx context.Context, token string, postID int64) error {
+			deleteFunc: func(ctx context.Context, user *authmw.Principal, postID int64) error {
 				return repository.ErrPostNotFound
 			},
 		}
@@ -520,7 +544,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(mockUC, mockLogger)
 
 		router := gin.Default()
-		router.DELETE("/posts/:id", handler.DeletePost)
+		router.DELETE("/posts/:id", requireAuth(), handler.DeletePost)
 
 		req, _ := http.NewRequest("DELETE", "/posts/999", nil)
 		req.Header.Set("Authorization", "Bearer valid_token")
@@ -533,7 +557,7 @@ func TestPostHandler(t *testing.T) {
 
 	t.Run("UpdatePost success", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			updateFunc: func(ctx context.Context, token string, postID int64, title, content string) (*entity.Post, error) {
+			updateFunc: func(ctx context.Context, user *authmw.Principal, postID int64, title, content string, tags []string) (*entity.Post, error) {
 				return &entity.Post{
 					ID:        postID,
 					Title:     title,
@@ -547,7 +571,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(mockUC, mockLogger)
 
 		router := gin.Default()
-		router.PUT("/posts/:id", handler.UpdatePost)
+		router.PUT("/posts/:id", requireAuth(), handler.UpdatePost)
 
 		requestBody := `{"title": "Updated Title", "content": "Updated Content"}`
 		req, _ := http.NewRequest("PUT", "/posts/1", bytes.NewBufferString(requestBody))
@@ -562,7 +586,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(nil, mockLogger)
 
 		router := gin.Default()
-		router.POST("/posts", handler.CreatePost)
+		router.POST("/posts", requireAuth(), handler.CreatePost)
 
 		requestBody := `{"title": "Test Post", "content": "Test Content"}`
 		req, _ := http.NewRequest("POST", "/posts", bytes.NewBufferString(requestBody))
@@ -577,7 +601,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(nil, mockLogger)
 
 		router := gin.Default()
-		router.POST("/posts", handler.CreatePost)
+		router.POST("/posts", requireAuth(), handler.CreatePost)
 
 		requestBody := `{"invalid": "data"}`
 		req, _ := http.NewRequest("POST", "/posts", bytes.NewBufferString(requestBody))
@@ -591,7 +615,7 @@ func TestPostHandler(t *testing.T) {
 
 	t.Run("GetPosts database error", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			getPostsFunc: func(ctx context.Context) ([]*entity.Post, map[int]string, error) {
+			getPostsFunc: func(ctx context.Context, query entity.PostListQuery) (*entity.PostPage, map[int]string, error) {
 				return nil, nil, errors.New("database error")
 			},
 		}
@@ -609,7 +633,7 @@ func TestPostHandler(t *testing.T) {
 	})
 	t.Run("DeletePost permission denied", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			deleteFunc: func(ctx context.Context, token string, postID int64) error {
+			deleteFunc: func(ctx context.Context, user *authmw.Principal, postID int64) error {
 				return repository.ErrPermissionDenied
 			},
 		}
@@ -617,7 +641,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(mockUC, mockLogger)
 
 		router := gin.Default()
-		router.DELETE("/posts/:id", handler.DeletePost)
+		router.DELETE("/posts/:id", requireAuth(), handler.DeletePost)
 
 		req, _ := http.NewRequest("DELETE", "/posts/1", nil)
 		req.Header.Set("Authorization", "Bearer valid_token")
@@ -630,7 +654,7 @@ func TestPostHandler(t *testing.T) {
 
 	t.Run("DeletePost database error", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			deleteFunc: func(ctx context.Context, token string, postID int64) error {
+			deleteFunc: func(ctx context.Context, user *authmw.Principal, postID int64) error {
 				return errors.New("database error")
 			},
 		}
@@ -638,7 +662,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(mockUC, mockLogger)
 
 		router := gin.Default()
-		router.DELETE("/posts/:id", handler.DeletePost)
+		router.DELETE("/posts/:id", requireAuth(), handler.DeletePost)
 
 		req, _ := http.NewRequest("DELETE", "/posts/1", nil)
 		req.Header.Set("Authorization", "Bearer valid_token")
@@ -651,7 +675,7 @@ func TestPostHandler(t *testing.T) {
 
 	t.Run("UpdatePost permission denied", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			updateFunc: func(ctx context.Context, token string, postID int64, title, content string) (*entity.Post, error) {
+			updateFunc: func(ctx context.Context, user *authmw.Principal, postID int64, title, content string, tags []string) (*entity.Post, error) {
 				return nil, repository.ErrPermissionDenied
 			},
 		}
@@ -659,7 +683,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(mockUC, mockLogger)
 
 		router := gin.Default()
-		router.PUT("/posts/:id", handler.UpdatePost)
+		router.PUT("/posts/:id", requireAuth(), handler.UpdatePost)
 
 		requestBody := `{"title": "Updated Title", "content": "Updated Content"}`
 		req, _ := http.NewRequest("PUT", "/posts/1", bytes.NewBufferString(requestBody))
@@ -673,7 +697,7 @@ func TestPostHandler(t *testing.T) {
 
 	t.Run("UpdatePost database error", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			updateFunc: func(ctx context.Context, token string, postID int64, title, content string) (*entity.Post, error) {
+			updateFunc: func(ctx context.Context, user *authmw.Principal, postID int64, title, content string, tags []string) (*entity.Post, error) {
 				return nil, errors.New("database error")
 			},
 		}
@@ -681,7 +705,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(mockUC, mockLogger)
 
 		router := gin.Default()
-		router.PUT("/posts/:id", handler.UpdatePost)
+		router.PUT("/posts/:id", requireAuth(), handler.UpdatePost)
 
 		requestBody := `{"title": "Updated Title", "content": "Updated Content"}`
 		req, _ := http.NewRequest("PUT", "/posts/1", bytes.NewBufferString(requestBody))
@@ -693,19 +717,10 @@ func TestPostHandler(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 	t.Run("CreatePost invalid token", func(t *testing.T) {
-		mockAuth := &mockAuthClient{
-			validateFunc: func(ctx context.Context, req *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-				return &pb.ValidateTokenResponse{
-					Valid: false,
-				}, nil
-			},
-		}
-
-		postUC := usecase.NewPostUsecase(nil, mockAuth, nil)
-		handler := handler.NewPostHandler(postUC, mockLogger)
+		handler := handler.NewPostHandler(nil, mockLogger)
 
 		router := gin.Default()
-		router.POST("/posts", handler.CreatePost)
+		router.POST("/posts", requireAuth(), handler.CreatePost)
 
 		requestBody := `{"title": "Test Post", "content": "Test Content"}`
 		req, _ := http.NewRequest("POST", "/posts", bytes.NewBufferString(requestBody))
@@ -721,7 +736,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(nil, mockLogger)
 
 		router := gin.Default()
-		router.PUT("/posts/:id", handler.UpdatePost)
+		router.PUT("/posts/:id", requireAuth(), handler.UpdatePost)
 
 		requestBody := `{"invalid": "data"}`
 		req, _ := http.NewRequest("PUT", "/posts/1", bytes.NewBufferString(requestBody))
@@ -734,19 +749,10 @@ func TestPostHandler(t *testing.T) {
 	})
 
 	t.Run("DeletePost invalid token", func(t *testing.T) {
-		mockAuth := &mockAuthClient{
-			validateFunc: func(ctx context.Context, req *pb.ValidateTokenRequest, opts ...grpc.CallOption) (*pb.ValidateTokenResponse, error) {
-				return &pb.ValidateTokenResponse{
-					Valid: false,
-				}, nil
-			},
-		}
-
-		postUC := usecase.NewPostUsecase(nil, mockAuth, nil)
-		handler := handler.NewPostHandler(postUC, mockLogger)
+		handler := handler.NewPostHandler(nil, mockLogger)
 
 		router := gin.Default()
-		router.DELETE("/posts/:id", handler.DeletePost)
+		router.DELETE("/posts/:id", requireAuth(), handler.DeletePost)
 
 		req, _ := http.NewRequest("DELETE", "/posts/1", nil)
 		req.Header.Set("Authorization", "Bearer invalid_token")
@@ -760,7 +766,7 @@ func TestPostHandler(t *testing.T) {
 		handler := handler.NewPostHandler(nil, mockLogger)
 
 		router := gin.Default()
-		router.PUT("/posts/:id", handler.UpdatePost)
+		router.PUT("/posts/:id", requireAuth(), handler.UpdatePost)
 
 		requestBody := `{"title": "Updated Title", "content": "Updated Content"}`
 		req, _ := http.NewRequest("PUT", "/posts/invalid", bytes.NewBufferString(requestBody))
@@ -773,8 +779,8 @@ func TestPostHandler(t *testing.T) {
 	})
 	t.Run("GetPosts success", func(t *testing.T) {
 		mockUC := &mockPostUseCase{
-			getPostsFunc: func(ctx context.Context) ([]*entity.Post, map[int]string, error) {
-				return []*entity.Post{
+			getPostsFunc: func(ctx context.Context, query entity.PostListQuery) (*entity.PostPage, map[int]string, error) {
+				return &entity.PostPage{Posts: []*entity.Post{
 					{
 						ID:        1,
 						Title:     "Test Post",
@@ -782,7 +788,7 @@ func TestPostHandler(t *testing.T) {
 						AuthorID:  1,
 						CreatedAt: time.Now(),
 					},
-				}, map[int]string{1: "testuser"}, nil
+				}, Total: 1}, map[int]string{1: "testuser"}, nil
 			},
 		}
 
@@ -821,7 +827,7 @@ func TestCommentHandler(t *testing.T) {
 		handler := handler.NewCommentHandler(commentUC)
 
 		router := gin.Default()
-		router.POST("/posts/:id/comments", handler.CreateComment)
+		router.POST("/posts/:id/comments", requireAuth(), handler.CreateComment)
 
 		requestBody := `{"content": "Test Comment"}`
 		req, _ := http.NewRequest("POST", "/posts/1/comments", bytes.NewBufferString(requestBody))
@@ -841,7 +847,7 @@ func TestCommentHandler(t *testing.T) {
 		handler := handler.NewCommentHandler(nil)
 
 		router := gin.Default()
-		router.POST("/posts/:id/comments", handler.CreateComment)
+		router.POST("/posts/:id/comments", requireAuth(), handler.CreateComment)
 
 		requestBody := `{"content": "Test Comment"}`
 		req, _ := http.NewRequest("POST", "/posts/invalid/comments", bytes.NewBufferString(requestBody))
@@ -857,7 +863,7 @@ func TestCommentHandler(t *testing.T) {
 		handler := handler.NewCommentHandler(nil)
 
 		router := gin.Default()
-		router.POST("/posts/:id/comments", handler.CreateComment)
+		router.POST("/posts/:id/comments", requireAuth(), handler.CreateComment)
 
 		requestBody := `{"invalid": "data"}`
 		req, _ := http.NewRequest("POST", "/posts/1/comments", bytes.NewBufferString(requestBody))
@@ -909,7 +915,7 @@ func TestCommentHandler(t *testing.T) {
 		handler := handler.NewCommentHandler(commentUC)
 
 		router := gin.Default()
-		router.POST("/posts/:id/comments", handler.CreateComment)
+		router.POST("/posts/:id/comments", requireAuth(), handler.CreateComment)
 
 		requestBody := `{"content": "Test Comment"}`
 		req, _ := http.NewRequest("POST", "/posts/1/comments", bytes.NewBufferString(requestBody))
@@ -926,8 +932,8 @@ func TestCommentHandler(t *testing.T) {
 		deps := setupTest(t)
 		defer deps.db.Close()
 
-		postQuery := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
-		commentQuery := `SELECT id, content, author_id, post_id, author_name FROM comments WHERE post_id = $1 ORDER BY id DESC`
+		postQuery := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
+		commentQuery := `SELECT c.id, c.content, c.content_html, c.author_id, c.post_id, c.author_name, c.created_at, c.updated_at, c.updated_at IS NOT NULL AS edited, c.parent_id, c.depth, c.deleted_at, c.deleted_by, c.deleted_at IS NOT NULL AS deleted, c.score, (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND (r.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = r.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL))) AS reply_count FROM comments c WHERE c.post_id = $1 AND c.parent_id IS NULL AND (c.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL)) ORDER BY c.id DESC LIMIT $2 OFFSET $3`
 
 		deps.mock.ExpectQuery(postQuery).
 			WithArgs(int64(1)).
@@ -935,7 +941,7 @@ func TestCommentHandler(t *testing.T) {
 				AddRow(1, "Test Post", "Test Content", int64(1), time.Now()))
 
 		deps.mock.ExpectQuery(commentQuery).
-			WithArgs(int64(1)).
+			WithArgs(int64(1), 20, 0).
 			WillReturnError(errors.New("database error"))
 
 		handler := handler.NewCommentHandler(deps.commentUC)
@@ -955,8 +961,8 @@ func TestCommentHandler(t *testing.T) {
 		deps := setupTest(t)
 		defer deps.db.Close()
 
-		postQuery := `SELECT id, title, content, author_id, created_at FROM posts WHERE id = $1`
-		commentQuery := `SELECT id, content, author_id, post_id, author_name FROM comments WHERE post_id = $1 ORDER BY id DESC`
+		postQuery := `SELECT id, title, content, content_html, author_id, created_at, topic_id, score FROM posts WHERE id = $1 AND deleted_at IS NULL`
+		commentQuery := `SELECT c.id, c.content, c.content_html, c.author_id, c.post_id, c.author_name, c.created_at, c.updated_at, c.updated_at IS NOT NULL AS edited, c.parent_id, c.depth, c.deleted_at, c.deleted_by, c.deleted_at IS NOT NULL AS deleted, c.score, (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND (r.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = r.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL))) AS reply_count FROM comments c WHERE c.post_id = $1 AND c.parent_id IS NULL AND (c.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL)) ORDER BY c.id DESC LIMIT $2 OFFSET $3`
 
 		deps.mock.ExpectQuery(postQuery).
 			WithArgs(int64(1)).
@@ -964,16 +970,22 @@ func TestCommentHandler(t *testing.T) {
 				AddRow(1, "Test Post", "Test Content", int64(1), time.Now()))
 
 		deps.mock.ExpectQuery(commentQuery).
-			WithArgs(int64(1)).
+			WithArgs(int64(1), 20, 0).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "content", "author_id", "post_id", "author_name"}).
 				AddRow(1, "Test Comment", int64(1), int64(1), "testuser"))
+		deps.mock.ExpectQuery(`SELECT COUNT(*) FROM comments c WHERE c.post_id = $1 AND c.parent_id IS NULL AND (c.deleted_at IS NULL OR EXISTS ( WITH RECURSIVE below AS ( SELECT id, deleted_at FROM comments WHERE parent_id = c.id UNION ALL SELECT k.id, k.deleted_at FROM comments k JOIN below b ON k.parent_id = b.id ) SELECT 1 FROM below WHERE deleted_at IS NULL))`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		deps.mock.ExpectQuery(`SELECT comment_id AS item_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = $2) AS mine FROM comment_reactions WHERE comment_id = ANY($1) GROUP BY comment_id, emoji ORDER BY comment_id, MIN(created_at), emoji`).
+			WithArgs(sqlmock.AnyArg(), int64(0)).
+			WillReturnRows(sqlmock.NewRows([]string{"item_id", "emoji", "count", "mine"}))
 
 		handler := handler.NewCommentHandler(deps.commentUC)
 
 		router := gin.Default()
 		router.GET("/posts/:id/comments", handler.GetCommentsByPostID)
 
-		req, _ := http.NewRequest("GET", "/posts/1/comments", nil)
+		req, _ := http.NewRequest("GET", "/posts/1/comments?depth=0", nil)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 
@@ -989,36 +1001,36 @@ func TestCommentHandler(t *testing.T) {
 
 type mockPostUseCase struct {
 	usecase.PostUsecaseInterface
-	createFunc   func(context.Context, string, string, string) (*entity.Post, error)
-	getPostsFunc func(context.Context) ([]*entity.Post, map[int]string, error)
-	deleteFunc   func(context.Context, string, int64) error
-	updateFunc   func(context.Context, string, int64, string, string) (*entity.Post, error)
+	createFunc   func(context.Context, *authmw.Principal, string, string, *int64, []string) (*entity.Post, error)
+	getPostsFunc func(context.Context, entity.PostListQuery) (*entity.PostPage, map[int]string, error)
+	deleteFunc   func(context.Context, *authmw.Principal, int64) error
+	updateFunc   func(context.Context, *authmw.Principal, int64, string, string, []string) (*entity.Post, error)
 }
 
-func (m *mockPostUseCase) CreatePost(ctx context.Context, token, title, content string) (*entity.Post, error) {
-	return m.createFunc(ctx, token, title, content)
+func (m *mockPostUseCase) CreatePost(ctx context.Context, author *authmw.Principal, title, content string, topicID *int64, tags []string) (*entity.Post, error) {
+	return m.createFunc(ctx, author, title, content, topicID, tags)
 }
 
-func (m *mockPostUseCase) GetPosts(ctx context.Context) ([]*entity.Post, map[int]string, error) {
-	return m.getPostsFunc(ctx)
+func (m *mockPostUseCase) GetPosts(ctx context.Context, query entity.PostListQuery) (*entity.PostPage, map[int]string, error) {
+	return m.getPostsFunc(ctx, query)
 }
 
-func (m *mockPostUseCase) DeletePost(ctx context.Context, token string, postID int64) error {
-	return m.deleteFunc(ctx, token, postID)
+func (m *mockPostUseCase) DeletePost(ctx context.Context, user *authmw.Principal, postID int64) error {
+	return m.deleteFunc(ctx, user, postID)
 }
 
-func (m *mockPostUseCase) UpdatePost(ctx context.Context, token string, postID int64, title, content string) (*entity.Post, error) {
-	return m.updateFunc(ctx, token, postID, title, content)
+func (m *mockPostUseCase) UpdatePost(ctx context.Context, user *authmw.Principal, postID int64, title, content string, tags []string) (*entity.Post, error) {
+	return m.updateFunc(ctx, user, postID, title, content, tags)
 }
 
 type mockCommentUseCase struct {
-	usecase.CommentUseCase
+	repository.CommentRepository
 	createFunc      func(context.Context, *entity.Comment) error
-	getCommentsFunc func(context.Context, int64) ([]entity.Comment, error)
+	getCommentsFunc func(context.Context, int64, int, int) ([]*entity.Comment, error)
 	deleteFunc      func(context.Context, int64) error // Add this line
 }
 
-func (m *mockCommentUseCase) DeleteComment(ctx context.Context, commentID int64) error {
+func (m *mockCommentUseCase) DeleteComment(ctx context.Context, commentID, postID, authorID int64, role string) error {
 	if m.deleteFunc != nil {
 		return m.deleteFunc(ctx, commentID)
 	}
@@ -1029,6 +1041,6 @@ func (m *mockCommentUseCase) CreateComment(ctx context.Context, comment *entity.
 	return m.createFunc(ctx, comment)
 }
 
-func (m *mockCommentUseCase) GetCommentsByPostID(ctx context.Context, postID int64) ([]entity.Comment, error) {
-	return m.getCommentsFunc(ctx, postID)
+func (m *mockCommentUseCase) GetCommentsByPostID(ctx context.Context, postID int64, limit, offset int) ([]*entity.Comment, error) {
+	return m.getCommentsFunc(ctx, postID, limit, offset)
 }
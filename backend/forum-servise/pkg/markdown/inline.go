@@ -0,0 +1,496 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"backend.com/forum/mention"
+)
+
+var (
+	entityRef    = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	uriAutolink  = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.\-]{1,31}:[^<>\x00-\x20]*)>`)
+	mailAutolink = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+	bareURL      = regexp.MustCompile(`^(?i:https?://)[^\s<]+`)
+)
+
+// node — кусок строки: готовый HTML или серия разделителей выделения
+// (* _ ~), которые превращаются в теги после разбора всей строки.
+type node struct {
+	html string
+
+	delim    byte
+	count    int // сколько символов разделителя еще не использовано
+	length   int // исходная длина серии
+	canOpen  bool
+	canClose bool
+	open     string // открывающие теги, добавленные при разборе выделения
+	close    string // закрывающие теги
+}
+
+type inlineParser struct {
+	src     string
+	nodes   []*node
+	noLinks bool // внутри текста ссылки вложенные ссылки не разбираются
+	mention mentionFunc
+}
+
+// renderInline переводит текст абзаца или заголовка в HTML.
+func renderInline(text string, mention mentionFunc) string {
+	return (&inlineParser{src: strings.TrimRight(text, " "), mention: mention}).render()
+}
+
+func (p *inlineParser) render() string {
+	p.parse()
+	p.processEmphasis()
+
+	var b strings.Builder
+	for _, n := range p.nodes {
+		if n.delim == 0 {
+			b.WriteString(n.html)
+			continue
+		}
+		b.WriteString(n.close)
+		b.WriteString(strings.Repeat(string(n.delim), n.count))
+		b.WriteString(n.open)
+	}
+	return b.String()
+}
+
+func (p *inlineParser) text(s string) {
+	p.raw(html.EscapeString(s))
+}
+
+func (p *inlineParser) raw(s string) {
+	if last := len(p.nodes) - 1; last >= 0 && p.nodes[last].delim == 0 {
+		p.nodes[last].html += s
+		return
+	}
+	p.nodes = append(p.nodes, &node{html: s})
+}
+
+func (p *inlineParser) parse() {
+	src := p.src
+	for i := 0; i < len(src); {
+		switch c := src[i]; c {
+		case '\\':
+			switch {
+			case i+1 < len(src) && src[i+1] == '\n':
+				p.raw("<br>\n")
+				i = skipLeadingSpaces(src, i+2)
+			case i+1 < len(src) && isASCIIPunct(src[i+1]):
+				p.text(src[i+1 : i+2])
+				i += 2
+			default:
+				p.text(`\`)
+				i++
+			}
+		case '`':
+			i = p.codeSpan(i)
+		case '*', '_', '~':
+			i = p.delimiterRun(i)
+		case '!':
+			if i+1 < len(src) && src[i+1] == '[' {
+				if end, ok := p.link(i+1, true); ok {
+					i = end
+					continue
+				}
+			}
+			p.text("!")
+			i++
+		case '[':
+			if end, ok := p.link(i, false); ok {
+				i = end
+				continue
+			}
+			p.text("[")
+			i++
+		case '<':
+			if end, ok := p.autolink(i); ok {
+				i = end
+				continue
+			}
+			p.text("<")
+			i++
+		case '&':
+			if match := entityRef.FindString(src[i:]); match != "" {
+				p.text(html.UnescapeString(match))
+				i += len(match)
+				continue
+			}
+			p.text("&")
+			i++
+		case '\n':
+			p.lineBreak()
+			i = skipLeadingSpaces(src, i+1)
+		default:
+			if (c == 'h' || c == 'H') && !p.noLinks && (i == 0 || !isWordByte(src[i-1])) {
+				if end, ok := p.bareURL(i); ok {
+					i = end
+					continue
+				}
+			}
+			if c == '@' && p.mention != nil && !p.noLinks && mention.CanStart(src, i) {
+				if end, ok := p.mentionLink(i); ok {
+					i = end
+					continue
+				}
+			}
+			_, size := utf8.DecodeRuneInString(src[i:])
+			p.text(src[i : i+size])
+			i += size
+		}
+	}
+}
+
+// lineBreak превращает перевод строки в <br>, если перед ним два пробела,
+// иначе оставляет мягкий перенос.
+func (p *inlineParser) lineBreak() {
+	hard := false
+	if last := len(p.nodes) - 1; last >= 0 && p.nodes[last].delim == 0 {
+		trimmed := strings.TrimRight(p.nodes[last].html, " ")
+		hard = len(p.nodes[last].html)-len(trimmed) >= 2
+		p.nodes[last].html = trimmed
+	}
+	if hard {
+		p.raw("<br>\n")
+		return
+	}
+	p.raw("\n")
+}
+
+func (p *inlineParser) codeSpan(i int) int {
+	n := runLength(p.src, i, '`')
+	for j := i + n; j < len(p.src); {
+		if p.src[j] != '`' {
+			j++
+			continue
+		}
+		m := runLength(p.src, j, '`')
+		if m == n {
+			code := strings.ReplaceAll(p.src[i+n:j], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			p.raw("<code>" + html.EscapeString(code) + "</code>")
+			return j + m
+		}
+		j += m
+	}
+	p.text(p.src[i : i+n])
+	return i + n
+}
+
+func (p *inlineParser) delimiterRun(i int) int {
+	c := p.src[i]
+	n := runLength(p.src, i, c)
+	if c == '~' && n != 2 {
+		p.text(p.src[i : i+n])
+		return i + n
+	}
+
+	before, after := ' ', ' '
+	if i > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.src[:i])
+	}
+	if i+n < len(p.src) {
+		after, _ = utf8.DecodeRuneInString(p.src[i+n:])
+	}
+	leftFlanking := !unicode.IsSpace(after) &&
+		(!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	rightFlanking := !unicode.IsSpace(before) &&
+		(!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	d := &node{delim: c, count: n, length: n, canOpen: leftFlanking, canClose: rightFlanking}
+	if c == '_' {
+		d.canOpen = leftFlanking && (!rightFlanking || isPunct(before))
+		d.canClose = rightFlanking && (!leftFlanking || isPunct(after))
+	}
+	p.nodes = append(p.nodes, d)
+	return i + n
+}
+
+// processEmphasis сопоставляет разделители, как в алгоритме CommonMark:
+// для каждого закрывающего ищется ближайший подходящий открывающий.
+func (p *inlineParser) processEmphasis() {
+	for ci, closer := range p.nodes {
+		if closer.delim == 0 || !closer.canClose {
+			continue
+		}
+		for closer.count > 0 {
+			oi := p.findOpener(ci)
+			if oi < 0 {
+				break
+			}
+			opener := p.nodes[oi]
+
+			use := 1
+			if opener.count >= 2 && closer.count >= 2 {
+				use = 2
+			}
+			tag := "em"
+			switch {
+			case closer.delim == '~':
+				tag = "del"
+			case use == 2:
+				tag = "strong"
+			}
+			opener.count -= use
+			closer.count -= use
+			opener.open = "<" + tag + ">" + opener.open
+			closer.close += "</" + tag + ">"
+
+			// Разделители между парой остаются просто текстом.
+			for _, n := range p.nodes[oi+1 : ci] {
+				n.canOpen, n.canClose = false, false
+			}
+		}
+	}
+}
+
+func (p *inlineParser) findOpener(ci int) int {
+	closer := p.nodes[ci]
+	for oi := ci - 1; oi >= 0; oi-- {
+		opener := p.nodes[oi]
+		if opener.delim != closer.delim || !opener.canOpen || opener.count == 0 {
+			continue
+		}
+		if closer.delim == '~' && (opener.count != 2 || closer.count != 2) {
+			continue
+		}
+		// «Правило трех» CommonMark для разделителей, которые могут быть
+		// и открывающими, и закрывающими.
+		if (opener.canClose || closer.canOpen) && (opener.length+closer.length)%3 == 0 &&
+			!(opener.length%3 == 0 && closer.length%3 == 0) {
+			continue
+		}
+		return oi
+	}
+	return -1
+}
+
+// link разбирает [текст](адрес "заголовок") или картинку с "!" перед ней;
+// i указывает на "[".
+func (p *inlineParser) link(i int, image bool) (int, bool) {
+	if p.noLinks && !image {
+		return 0, false
+	}
+	closing := matchingBracket(p.src, i)
+	if closing < 0 || closing+1 >= len(p.src) || p.src[closing+1] != '(' {
+		return 0, false
+	}
+	dest, title, end, ok := linkTail(p.src, closing+2)
+	if !ok {
+		return 0, false
+	}
+
+	label := p.src[i+1 : closing]
+	if image {
+		alt := html.UnescapeString(unescapeBackslashes(label))
+		tag := `<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(alt) + `"`
+		if title != "" {
+			tag += ` title="` + html.EscapeString(title) + `"`
+		}
+		p.raw(tag + ">")
+		return end, true
+	}
+
+	inner := (&inlineParser{src: label, noLinks: true}).render()
+	tag := `<a href="` + html.EscapeString(dest) + `"`
+	if title != "" {
+		tag += ` title="` + html.EscapeString(title) + `"`
+	}
+	p.raw(tag + ">" + inner + "</a>")
+	return end, true
+}
+
+func (p *inlineParser) autolink(i int) (int, bool) {
+	if p.noLinks {
+		return 0, false
+	}
+	if match := uriAutolink.FindStringSubmatch(p.src[i:]); match != nil {
+		p.raw(`<a href="` + html.EscapeString(match[1]) + `">` + html.EscapeString(match[1]) + "</a>")
+		return i + len(match[0]), true
+	}
+	if match := mailAutolink.FindStringSubmatch(p.src[i:]); match != nil {
+		p.raw(`<a href="mailto:` + html.EscapeString(match[1]) + `">` + html.EscapeString(match[1]) + "</a>")
+		return i + len(match[0]), true
+	}
+	return 0, false
+}
+
+// bareURL делает ссылкой голый http(s)-адрес в тексте. Завершающая
+// пунктуация и непарные скобки в адрес не входят.
+func (p *inlineParser) bareURL(i int) (int, bool) {
+	url := bareURL.FindString(p.src[i:])
+	if url == "" {
+		return 0, false
+	}
+	for {
+		trimmed := strings.TrimRight(url, "?!.,:;*_~'\"")
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")") {
+			trimmed = trimmed[:len(trimmed)-1]
+		}
+		if trimmed == url {
+			break
+		}
+		url = trimmed
+	}
+	if strings.HasSuffix(url, "://") {
+		return 0, false
+	}
+	p.raw(`<a href="` + html.EscapeString(url) + `">` + html.EscapeString(url) + "</a>")
+	return i + len(url), true
+}
+
+// matchingBracket возвращает позицию "]", закрывающей "[" на позиции i.
+func matchingBracket(src string, i int) int {
+	depth := 0
+	for j := i; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '`':
+			// Скобки внутри кода в строке не считаются.
+			n := runLength(src, j, '`')
+			if end := strings.Index(src[j+n:], strings.Repeat("`", n)); end >= 0 {
+				j += n + end + n - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// linkTail разбирает часть ссылки после "(": адрес, необязательный
+// заголовок и ")". Возвращает позицию за ")".
+func linkTail(src string, i int) (dest, title string, end int, ok bool) {
+	i = skipSpace(src, i)
+	if i >= len(src) {
+		return "", "", 0, false
+	}
+
+	if src[i] == '<' {
+		j := i + 1
+		for ; j < len(src) && src[j] != '>'; j++ {
+			if src[j] == '\n' || src[j] == '<' {
+				return "", "", 0, false
+			}
+			if src[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(src) {
+			return "", "", 0, false
+		}
+		dest = src[i+1 : j]
+		i = j + 1
+	} else {
+		j, depth := i, 0
+	loop:
+		for ; j < len(src); j++ {
+			switch c := src[j]; {
+			case c == '\\':
+				j++
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case c <= ' ':
+				break loop
+			}
+		}
+		if j > len(src) {
+			j = len(src)
+		}
+		dest = src[i:j]
+		i = j
+	}
+
+	if next := skipSpace(src, i); next < len(src) && next > i && strings.ContainsRune(`"'(`, rune(src[next])) {
+		closing := src[next]
+		if closing == '(' {
+			closing = ')'
+		}
+		j := next + 1
+		for ; j < len(src) && src[j] != closing; j++ {
+			if src[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(src) {
+			return "", "", 0, false
+		}
+		title = html.UnescapeString(unescapeBackslashes(src[next+1 : j]))
+		i = j + 1
+	}
+
+	i = skipSpace(src, i)
+	if i >= len(src) || src[i] != ')' {
+		return "", "", 0, false
+	}
+	return html.UnescapeString(unescapeBackslashes(dest)), title, i + 1, true
+}
+
+func unescapeBackslashes(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func runLength(src string, i int, c byte) int {
+	n := 0
+	for i+n < len(src) && src[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpace(src string, i int) int {
+	for i < len(src) && (src[i] == ' ' || src[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func skipLeadingSpaces(src string, i int) int {
+	for i < len(src) && src[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func isWordByte(c byte) bool {
+	return c >= utf8.RuneSelf || c == '_' || c == '/' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
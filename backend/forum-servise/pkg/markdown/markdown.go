@@ -0,0 +1,491 @@
+// Package markdown переводит текст постов и комментариев из CommonMark
+// в безопасный HTML.
+//
+// Поддерживается основное подмножество CommonMark: заголовки (# и
+// подчеркивание), абзацы, жесткие переносы, цитаты, вложенные списки,
+// блоки кода (отступом и ``` / ~~~), горизонтальные линии, ссылки,
+// картинки, автоссылки, выделение, код в строке, экранирование и
+// HTML-сущности. Из GFM взяты зачеркивание ~~ и ссылки из голых URL.
+// Ссылки по ссылкам-определениям ([текст][метка]) не поддерживаются.
+// Упоминания @username становятся ссылками, если задан Options.MentionURL.
+//
+// Сырой HTML в тексте не интерпретируется и выводится как текст. Результат
+// рендера дополнительно проходит Sanitize.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Render переводит CommonMark-текст в HTML, пропущенный через Sanitize.
+func Render(source string) string {
+	return RenderWith(source, Options{})
+}
+
+// Options — необязательные настройки рендера.
+type Options struct {
+	// MentionURL возвращает адрес ссылки для @username. Если false или
+	// функция не задана, упоминание остается текстом.
+	MentionURL func(username string) (string, bool)
+}
+
+// RenderWith — Render с настройками opts.
+func RenderWith(source string, opts Options) string {
+	var b strings.Builder
+	renderBlocks(&b, parseBlocks(splitLines(source)), false, opts.MentionURL)
+	return Sanitize(b.String())
+}
+
+type blockKind int
+
+const (
+	paragraphBlock blockKind = iota
+	headingBlock
+	codeBlock
+	quoteBlock
+	listBlock
+	ruleBlock
+)
+
+type block struct {
+	kind     blockKind
+	level    int        // уровень заголовка
+	text     string     // текст абзаца и заголовка, содержимое блока кода
+	lang     string     // язык блока кода
+	children []*block   // содержимое цитаты
+	items    [][]*block // пункты списка
+	ordered  bool
+	start    int
+	tight    bool
+}
+
+var (
+	atxHeading   = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?[ \t]*$`)
+	atxClosing   = regexp.MustCompile(`(^|[ \t]+)#+$`)
+	thematic     = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	setextH1     = regexp.MustCompile(`^=+[ \t]*$`)
+	setextH2     = regexp.MustCompile(`^-+[ \t]*$`)
+	orderedStart = regexp.MustCompile(`^([0-9]{1,9})([.)])`)
+)
+
+// splitLines разбивает текст на строки с единым переводом строки и
+// раскрытыми табуляциями — дальше отступы считаются только пробелами.
+func splitLines(source string) []string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\x00", "\uFFFD")
+
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	return lines
+}
+
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	column := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := 4 - column%4
+			b.WriteString(strings.Repeat(" ", n))
+			column += n
+			continue
+		}
+		b.WriteRune(r)
+		column++
+	}
+	return b.String()
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent убирает до n пробелов в начале строки.
+func trimIndent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
+
+// fence — открывающая граница блока кода.
+type fence struct {
+	char   byte
+	length int
+	indent int
+}
+
+func openFence(line string) (fence, string, bool) {
+	indent := leadingSpaces(line)
+	if indent > 3 {
+		return fence{}, "", false
+	}
+	rest := line[indent:]
+	if rest == "" || (rest[0] != '`' && rest[0] != '~') {
+		return fence{}, "", false
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+	if n < 3 {
+		return fence{}, "", false
+	}
+	info := strings.TrimSpace(rest[n:])
+	if rest[0] == '`' && strings.Contains(info, "`") {
+		return fence{}, "", false
+	}
+	return fence{char: rest[0], length: n, indent: indent}, info, true
+}
+
+func (f fence) closes(line string) bool {
+	if leadingSpaces(line) > 3 {
+		return false
+	}
+	rest := strings.TrimLeft(line, " ")
+	n := len(rest) - len(strings.TrimLeft(rest, string(f.char)))
+	return n >= f.length && isBlank(rest[n:])
+}
+
+// listMarker — маркер пункта списка в начале строки.
+type listMarker struct {
+	ordered bool
+	delim   byte // символ маркера: - + * или . ) у нумерованного
+	start   int
+	indent  int // отступ содержимого пункта
+	empty   bool
+}
+
+func parseListMarker(line string) (listMarker, bool) {
+	indent := leadingSpaces(line)
+	if indent > 3 {
+		return listMarker{}, false
+	}
+	rest := line[indent:]
+
+	var m listMarker
+	width := 0
+	switch {
+	case rest != "" && strings.ContainsRune("-+*", rune(rest[0])):
+		m.delim = rest[0]
+		width = 1
+	default:
+		match := orderedStart.FindStringSubmatch(rest)
+		if match == nil {
+			return listMarker{}, false
+		}
+		m.ordered = true
+		m.start, _ = strconv.Atoi(match[1])
+		m.delim = match[2][0]
+		width = len(match[0])
+	}
+
+	after := rest[width:]
+	if after != "" && after[0] != ' ' {
+		return listMarker{}, false
+	}
+	spaces := leadingSpaces(after)
+	switch {
+	case isBlank(after):
+		m.empty = true
+		spaces = 1
+	case spaces > 4:
+		// Код с отступом внутри пункта: маркер отделяет один пробел.
+		spaces = 1
+	}
+	m.indent = indent + width + spaces
+	return m, true
+}
+
+// startsBlock сообщает, начинает ли строка новый блок, а не продолжает
+// абзац (ленивое продолжение в цитатах и списках).
+func startsBlock(line string) bool {
+	indent := leadingSpaces(line)
+	if indent > 3 {
+		return false
+	}
+	rest := line[indent:]
+	if _, _, ok := openFence(line); ok {
+		return true
+	}
+	if _, ok := parseListMarker(line); ok {
+		return true
+	}
+	return strings.HasPrefix(rest, ">") || atxHeading.MatchString(rest) || thematic.MatchString(rest)
+}
+
+func parseBlocks(lines []string) []*block {
+	var blocks []*block
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, &block{kind: paragraphBlock, text: strings.Join(para, "\n")})
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			flush()
+			i++
+			continue
+		}
+
+		indent := leadingSpaces(line)
+		if indent >= 4 {
+			if len(para) > 0 {
+				para = append(para, strings.TrimLeft(line, " "))
+				i++
+				continue
+			}
+			i = parseIndentedCode(lines, i, &blocks)
+			continue
+		}
+
+		rest := line[indent:]
+		if len(para) > 0 {
+			level := 0
+			switch {
+			case setextH1.MatchString(rest):
+				level = 1
+			case setextH2.MatchString(rest):
+				level = 2
+			}
+			if level > 0 {
+				blocks = append(blocks, &block{kind: headingBlock, level: level, text: strings.Join(para, "\n")})
+				para = nil
+				i++
+				continue
+			}
+		}
+
+		if f, info, ok := openFence(line); ok {
+			flush()
+			i = parseFencedCode(lines, i, f, info, &blocks)
+			continue
+		}
+		if match := atxHeading.FindStringSubmatch(rest); match != nil {
+			flush()
+			text := atxClosing.ReplaceAllString(match[2], "")
+			blocks = append(blocks, &block{kind: headingBlock, level: len(match[1]), text: strings.TrimSpace(text)})
+			i++
+			continue
+		}
+		if thematic.MatchString(rest) {
+			flush()
+			blocks = append(blocks, &block{kind: ruleBlock})
+			i++
+			continue
+		}
+		if strings.HasPrefix(rest, ">") {
+			flush()
+			i = parseQuote(lines, i, &blocks)
+			continue
+		}
+		if m, ok := parseListMarker(line); ok {
+			// Абзац прерывает только непустой пункт, нумерованный — с 1.
+			if len(para) == 0 || (!m.empty && (!m.ordered || m.start == 1)) {
+				flush()
+				i = parseList(lines, i, m, &blocks)
+				continue
+			}
+		}
+
+		para = append(para, rest)
+		i++
+	}
+	flush()
+	return blocks
+}
+
+func parseIndentedCode(lines []string, i int, blocks *[]*block) int {
+	var code []string
+	for ; i < len(lines); i++ {
+		if !isBlank(lines[i]) && leadingSpaces(lines[i]) < 4 {
+			break
+		}
+		code = append(code, trimIndent(lines[i], 4))
+	}
+	for len(code) > 0 && isBlank(code[len(code)-1]) {
+		code = code[:len(code)-1]
+	}
+	*blocks = append(*blocks, &block{kind: codeBlock, text: strings.Join(code, "\n") + "\n"})
+	return i
+}
+
+func parseFencedCode(lines []string, i int, f fence, info string, blocks *[]*block) int {
+	var code []string
+	for i++; i < len(lines); i++ {
+		if f.closes(lines[i]) {
+			i++
+			break
+		}
+		code = append(code, trimIndent(lines[i], f.indent))
+	}
+
+	text := strings.Join(code, "\n")
+	if len(code) > 0 {
+		text += "\n"
+	}
+	lang := ""
+	if fields := strings.Fields(info); len(fields) > 0 {
+		lang = html.UnescapeString(unescapeBackslashes(fields[0]))
+	}
+	*blocks = append(*blocks, &block{kind: codeBlock, text: text, lang: lang})
+	return i
+}
+
+func parseQuote(lines []string, i int, blocks *[]*block) int {
+	var inner []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		indent := leadingSpaces(line)
+		if indent < 4 && strings.HasPrefix(line[indent:], ">") {
+			rest := line[indent+1:]
+			if strings.HasPrefix(rest, " ") {
+				rest = rest[1:]
+			}
+			inner = append(inner, rest)
+			continue
+		}
+		// Ленивое продолжение абзаца цитаты без ">".
+		if !isBlank(line) && len(inner) > 0 && !isBlank(inner[len(inner)-1]) && !startsBlock(line) {
+			inner = append(inner, line)
+			continue
+		}
+		break
+	}
+	*blocks = append(*blocks, &block{kind: quoteBlock, children: parseBlocks(inner)})
+	return i
+}
+
+func parseList(lines []string, i int, first listMarker, blocks *[]*block) int {
+	list := &block{kind: listBlock, ordered: first.ordered, start: first.start, tight: true}
+
+	for i < len(lines) {
+		m, ok := parseListMarker(lines[i])
+		if !ok || m.ordered != first.ordered || m.delim != first.delim || thematic.MatchString(strings.TrimLeft(lines[i], " ")) {
+			break
+		}
+
+		item := []string{""}
+		if !m.empty {
+			item[0] = lines[i][m.indent:]
+		}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				item = append(item, "")
+				continue
+			case leadingSpaces(line) >= m.indent:
+				item = append(item, line[m.indent:])
+				continue
+			case !isBlank(item[len(item)-1]) && !startsBlock(line):
+				item = append(item, strings.TrimLeft(line, " "))
+				continue
+			}
+			break
+		}
+
+		trailing := 0
+		for len(item) > 1 && isBlank(item[len(item)-1]) {
+			item = item[:len(item)-1]
+			trailing++
+		}
+		children := parseBlocks(item)
+		if len(children) > 1 && containsBlank(item) {
+			list.tight = false
+		}
+		list.items = append(list.items, children)
+
+		if trailing > 0 {
+			next, ok := nextListMarker(lines, i)
+			if !ok || next.ordered != first.ordered || next.delim != first.delim {
+				break
+			}
+			list.tight = false
+		}
+	}
+
+	*blocks = append(*blocks, list)
+	return i
+}
+
+func nextListMarker(lines []string, i int) (listMarker, bool) {
+	if i >= len(lines) {
+		return listMarker{}, false
+	}
+	return parseListMarker(lines[i])
+}
+
+func containsBlank(lines []string) bool {
+	for _, line := range lines {
+		if isBlank(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderBlocks(b *strings.Builder, blocks []*block, tight bool, mention mentionFunc) {
+	for i, bl := range blocks {
+		switch bl.kind {
+		case paragraphBlock:
+			// В тесном списке текст пункта идет без <p>.
+			if tight {
+				b.WriteString(renderInline(bl.text, mention))
+				if i < len(blocks)-1 {
+					b.WriteString("\n")
+				}
+				continue
+			}
+			b.WriteString("<p>" + renderInline(bl.text, mention) + "</p>\n")
+		case headingBlock:
+			level := strconv.Itoa(bl.level)
+			b.WriteString("<h" + level + ">" + renderInline(bl.text, mention) + "</h" + level + ">\n")
+		case codeBlock:
+			b.WriteString("<pre><code")
+			if bl.lang != "" {
+				b.WriteString(` class="language-` + html.EscapeString(bl.lang) + `"`)
+			}
+			b.WriteString(">" + html.EscapeString(bl.text) + "</code></pre>\n")
+		case quoteBlock:
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, bl.children, false, mention)
+			b.WriteString("</blockquote>\n")
+		case listBlock:
+			tag := "ul"
+			if bl.ordered {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag)
+			if bl.ordered && bl.start != 1 {
+				b.WriteString(` start="` + strconv.Itoa(bl.start) + `"`)
+			}
+			b.WriteString(">\n")
+			for _, item := range bl.items {
+				b.WriteString("<li>")
+				if len(item) > 0 && (!bl.tight || item[0].kind != paragraphBlock) {
+					b.WriteString("\n")
+				}
+				renderBlocks(b, item, bl.tight, mention)
+				b.WriteString("</li>\n")
+			}
+			b.WriteString("</" + tag + ">\n")
+		case ruleBlock:
+			b.WriteString("<hr>\n")
+		}
+	}
+}
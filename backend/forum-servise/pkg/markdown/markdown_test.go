@@ -0,0 +1,212 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const rel = ` rel="nofollow noopener noreferrer"`
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "Paragraphs",
+			in:   "first line\nsecond line\n\nnext paragraph",
+			want: "<p>first line\nsecond line</p>\n<p>next paragraph</p>\n",
+		},
+		{
+			name: "Headings",
+			in:   "# Title #\n## Sub\nSetext\n---",
+			want: "<h1>Title</h1>\n<h2>Sub</h2>\n<h2>Setext</h2>\n",
+		},
+		{
+			name: "Emphasis",
+			in:   "*em* **strong** ***both*** _u_ __uu__ ~~del~~ snake_case_word",
+			want: "<p><em>em</em> <strong>strong</strong> <em><strong>both</strong></em> <em>u</em> <strong>uu</strong> <del>del</del> snake_case_word</p>\n",
+		},
+		{
+			name: "Hard breaks",
+			in:   "a  \nb\\\nc",
+			want: "<p>a<br>\nb<br>\nc</p>\n",
+		},
+		{
+			name: "Escapes and entities",
+			in:   `\*not em\* &copy; &amp; 1 < 2`,
+			want: "<p>*not em* © &amp; 1 &lt; 2</p>\n",
+		},
+		{
+			name: "Inline code",
+			in:   "use `a <b> && c` here",
+			want: "<p>use <code>a &lt;b&gt; &amp;&amp; c</code> here</p>\n",
+		},
+		{
+			name: "Fenced code",
+			in:   "```go\nfmt.Println(\"<hi>\")\n```",
+			want: "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)\n</code></pre>\n",
+		},
+		{
+			name: "Indented code",
+			in:   "    x := 1\n    y := 2",
+			want: "<pre><code>x := 1\ny := 2\n</code></pre>\n",
+		},
+		{
+			name: "Tight list",
+			in:   "- a\n- b\n  - nested\n\n3. three\n4. four",
+			want: "<ul>\n<li>a</li>\n<li>b\n<ul>\n<li>nested</li>\n</ul>\n</li>\n</ul>\n<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n",
+		},
+		{
+			name: "Loose list",
+			in:   "- a\n\n- b",
+			want: "<ul>\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ul>\n",
+		},
+		{
+			name: "Blockquote",
+			in:   "> quote\nlazy\n> > nested",
+			want: "<blockquote>\n<p>quote\nlazy</p>\n<blockquote>\n<p>nested</p>\n</blockquote>\n</blockquote>\n",
+		},
+		{
+			name: "Thematic break",
+			in:   "a\n\n***\n\nb",
+			want: "<p>a</p>\n<hr>\n<p>b</p>\n",
+		},
+		{
+			name: "Links",
+			in:   `[go *site*](https://go.dev "Go") [rel](/posts/1)`,
+			want: `<p><a href="https://go.dev" title="Go"` + rel + `>go <em>site</em></a> <a href="/posts/1"` + rel + ">rel</a></p>\n",
+		},
+		{
+			name: "Autolinks",
+			in:   "<https://a.io/x> <me@ex.com> see https://go.dev/doc).",
+			want: `<p><a href="https://a.io/x"` + rel + `>https://a.io/x</a> <a href="mailto:me@ex.com"` + rel + `>me@ex.com</a> see <a href="https://go.dev/doc"` + rel + ">https://go.dev/doc</a>).</p>\n",
+		},
+		{
+			name: "Image",
+			in:   `![a cat](https://img.io/cat.png "Cat")`,
+			want: "<p><img src=\"https://img.io/cat.png\" alt=\"a cat\" title=\"Cat\"></p>\n",
+		},
+		{
+			name: "Not a link",
+			in:   "[no](",
+			want: "<p>[no](</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.in))
+		})
+	}
+}
+
+func TestRender_XSS(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "Raw HTML is text",
+			in:   `<script>alert(1)</script><img src=x onerror=alert(1)>`,
+			want: "<p>&lt;script&gt;alert(1)&lt;/script&gt;&lt;img src=x onerror=alert(1)&gt;</p>\n",
+		},
+		{
+			name: "Script link",
+			in:   `[x](javascript:alert(1)) [y](JaVaScRiPt:alert(1))`,
+			want: `<p><a` + rel + `>x</a> <a` + rel + ">y</a></p>\n",
+		},
+		{
+			name: "Script autolink",
+			in:   `<javascript:alert(1)>`,
+			want: `<p><a` + rel + ">javascript:alert(1)</a></p>\n",
+		},
+		{
+			name: "Data image",
+			in:   `![x](data:image/svg+xml;base64,PHN2Zz4=)`,
+			want: "<p></p>\n",
+		},
+		{
+			name: "Quote in title",
+			in:   `[x](/a "\" onmouseover=\"alert(1)")`,
+			want: `<p><a href="/a" title="&#34; onmouseover=&#34;alert(1)"` + rel + ">x</a></p>\n",
+		},
+		{
+			name: "Code language",
+			in:   "```\"><script>\nx\n```",
+			want: "<pre><code>x\n</code></pre>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.in))
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "Allowed tags keep allowed attributes",
+			in:   `<p class="x" onclick="y">a <strong style="s">b</strong></p>`,
+			want: `<p>a <strong>b</strong></p>`,
+		},
+		{
+			name: "Unknown tags are unwrapped",
+			in:   `<div><b>bold</b> <span>text</span></div>`,
+			want: `bold text`,
+		},
+		{
+			name: "Dangerous tags are dropped with content",
+			in:   `a<script>alert(1)</script><style>p{}</style><iframe src="x"><p>in</p></iframe>b`,
+			want: `ab`,
+		},
+		{
+			name: "Links get rel and lose unsafe href",
+			in:   `<a href="https://ok.io" rel="opener" target="_blank">ok</a><a href=" javascript:alert(1)">bad</a><a href="vbscript:x">vb</a>`,
+			want: `<a href="https://ok.io"` + rel + `>ok</a><a` + rel + `>bad</a><a` + rel + `>vb</a>`,
+		},
+		{
+			name: "Images need a safe src",
+			in:   `<img src="https://i.io/a.png" onerror="x"><img src="javascript:x"><img>`,
+			want: `<img src="https://i.io/a.png">`,
+		},
+		{
+			name: "Code class is checked",
+			in:   `<code class="language-go">a</code><code class="x onload">b</code>`,
+			want: `<code class="language-go">a</code><code>b</code>`,
+		},
+		{
+			name: "Unbalanced tags",
+			in:   `<em>a<strong>b</em></p>c`,
+			want: `<em>a<strong>b</strong></em>c`,
+		},
+		{
+			name: "Comments are dropped",
+			in:   `a<!-- <script>x</script> -->b`,
+			want: `ab`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.in))
+		})
+	}
+}
+
+func TestRender_Idempotent(t *testing.T) {
+	// Повторная очистка готового HTML его не меняет.
+	out := Render("# T\n\n- *a*\n- [b](https://b.io)\n\n```\n<x>\n```")
+	assert.Equal(t, out, Sanitize(out))
+	assert.False(t, strings.Contains(out, "<x>"))
+}
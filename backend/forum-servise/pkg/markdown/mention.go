@@ -0,0 +1,40 @@
+package markdown
+
+import (
+	"html"
+
+	"backend.com/forum/mention"
+)
+
+type mentionFunc func(username string) (string, bool)
+
+// Mentions возвращает имена, упомянутые в тексте через @username, в
+// порядке появления и без повторов. Упоминания в коде, в тексте ссылок и
+// внутри слов (адреса почты) не считаются.
+func Mentions(source string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	RenderWith(source, Options{MentionURL: func(username string) (string, bool) {
+		if !seen[username] {
+			seen[username] = true
+			names = append(names, username)
+		}
+		return "", false
+	}})
+	return names
+}
+
+// mentionLink делает ссылкой @username на позиции i, если p.mention дает
+// для него адрес.
+func (p *inlineParser) mentionLink(i int) (int, bool) {
+	username, n := mention.At(p.src[i:])
+	if n == 0 {
+		return 0, false
+	}
+	href, ok := p.mention(username)
+	if !ok {
+		return 0, false
+	}
+	p.raw(`<a href="` + html.EscapeString(href) + `">@` + html.EscapeString(username) + "</a>")
+	return i + n, true
+}
@@ -0,0 +1,43 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "Plain", in: "hi @alice and @bob_2, see @alice", want: []string{"alice", "bob_2"}},
+		{name: "Trailing punctuation", in: "thanks @al.ice. (@carol-) @dave!", want: []string{"al.ice", "carol", "dave"}},
+		{name: "Unicode", in: "привет, @Даша", want: []string{"Даша"}},
+		{name: "Email is not a mention", in: "mail me@ex.com", want: nil},
+		{name: "Code is skipped", in: "`@alice`\n\n```\n@bob\n```\n\n    @carol", want: nil},
+		{name: "Link text is skipped", in: "[@alice](https://a.io) **@bob**", want: []string{"bob"}},
+		{name: "Escaped", in: `\@alice @`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Mentions(tt.in))
+		})
+	}
+}
+
+func TestRenderWith_Mentions(t *testing.T) {
+	opts := Options{MentionURL: func(username string) (string, bool) {
+		if username == "ghost" {
+			return "", false
+		}
+		return "/users/" + username, true
+	}}
+
+	out := RenderWith("hi @alice, @ghost and *@bob*", opts)
+	assert.Equal(t, `<p>hi <a href="/users/alice"`+rel+`>@alice</a>, @ghost and <em><a href="/users/bob"`+rel+">@bob</a></em></p>\n", out)
+	assert.Equal(t, out, Sanitize(out))
+	assert.Equal(t, "<p>hi @alice</p>\n", Render("hi @alice"))
+}
@@ -0,0 +1,168 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// LinkRel ставится на все ссылки: пользовательский контент не должен
+// передавать вес ссылкам и доступ к window.opener.
+const LinkRel = "nofollow noopener noreferrer"
+
+// allowedTags — разрешенные теги и их атрибуты. Все, что не в списке,
+// выбрасывается, текст внутри остается.
+var allowedTags = map[string][]string{
+	"p": nil, "br": nil, "hr": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"em": nil, "strong": nil, "del": nil,
+	"blockquote": nil, "pre": nil, "code": {"class"},
+	"ul": nil, "ol": {"start"}, "li": nil,
+	"a":   {"href", "title"},
+	"img": {"src", "alt", "title"},
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// droppedTags выбрасываются вместе с содержимым.
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "textarea": true, "title": true, "svg": true, "math": true,
+}
+
+var (
+	codeClass = regexp.MustCompile(`^language-[A-Za-z0-9_+#.\-]{1,32}$`)
+	digits    = regexp.MustCompile(`^[0-9]{1,9}$`)
+)
+
+// Sanitize оставляет в HTML только теги и атрибуты из белого списка.
+// Ссылки допускаются на http, https, mailto и относительные адреса и
+// получают rel=LinkRel, картинки — только http, https и относительные.
+// Незакрытые теги закрываются, лишние закрывающие отбрасываются.
+func Sanitize(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	var open []string
+	skip, skipDepth := "", 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		if skip != "" {
+			switch {
+			case tt == html.StartTagToken && tok.Data == skip:
+				skipDepth++
+			case tt == html.EndTagToken && tok.Data == skip:
+				skipDepth--
+				if skipDepth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.WriteString(html.EscapeString(tok.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[tok.Data] {
+				if tt == html.StartTagToken {
+					skip, skipDepth = tok.Data, 1
+				}
+				continue
+			}
+			attrs, ok := allowedAttrs(tok)
+			if !ok {
+				continue
+			}
+			b.WriteString("<" + tok.Data + attrs + ">")
+			if !voidTags[tok.Data] {
+				open = append(open, tok.Data)
+			}
+		case html.EndTagToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.Data {
+					continue
+				}
+				for len(open) > i {
+					b.WriteString("</" + open[len(open)-1] + ">")
+					open = open[:len(open)-1]
+				}
+				break
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// allowedAttrs собирает разрешенные атрибуты тега. false — тег целиком
+// выбрасывается.
+func allowedAttrs(tok html.Token) (string, bool) {
+	allowed, ok := allowedTags[tok.Data]
+	if !ok {
+		return "", false
+	}
+
+	var b strings.Builder
+	write := func(key, value string) {
+		b.WriteString(" " + key + `="` + html.EscapeString(value) + `"`)
+	}
+	hasSrc := false
+	for _, attr := range tok.Attr {
+		if attr.Namespace != "" || !contains(allowed, attr.Key) {
+			continue
+		}
+		switch {
+		case attr.Key == "href" && !safeURL(attr.Val, "http", "https", "mailto"):
+			continue
+		case attr.Key == "src":
+			if !safeURL(attr.Val, "http", "https") {
+				continue
+			}
+			hasSrc = true
+		case attr.Key == "class" && !codeClass.MatchString(attr.Val):
+			continue
+		case attr.Key == "start" && !digits.MatchString(attr.Val):
+			continue
+		}
+		write(attr.Key, attr.Val)
+	}
+
+	switch tok.Data {
+	case "a":
+		write("rel", LinkRel)
+	case "img":
+		if !hasSrc {
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// safeURL разрешает относительные адреса и адреса с одной из schemes.
+func safeURL(raw string, schemes ...string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || contains(schemes, strings.ToLower(u.Scheme))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocal(root)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "posts/1/abc", strings.NewReader("hello"), 5, "text/plain"))
+	require.NoError(t, s.Put(ctx, "posts/1/abc", strings.NewReader("hello again"), 11, "text/plain"))
+
+	r, err := s.Get(ctx, "posts/1/abc")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hello again", string(data))
+
+	entries, err := os.ReadDir(filepath.Join(root, "posts", "1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files must not be left behind")
+
+	require.NoError(t, s.Delete(ctx, "posts/1/abc"))
+	require.NoError(t, s.Delete(ctx, "posts/1/abc"))
+	_, err = s.Get(ctx, "posts/1/abc")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocal_InvalidKey(t *testing.T) {
+	s, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../etc/passwd", "/abs", "posts/../../x", "posts//x", `posts\x`, "posts/x?y"} {
+		t.Run(key, func(t *testing.T) {
+			assert.ErrorIs(t, s.Put(context.Background(), key, strings.NewReader("x"), 1, ""), ErrInvalidKey)
+			_, err := s.Get(context.Background(), key)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+		})
+	}
+}
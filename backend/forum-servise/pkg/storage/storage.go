@@ -0,0 +1,37 @@
+// Package storage хранит файлы вложений. Драйверы взаимозаменяемы:
+// локальный диск (по умолчанию) и S3-совместимое хранилище.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage — хранилище объектов по ключу вида "posts/12/3f9a...".
+type Storage interface {
+	// Put сохраняет объект, перезаписывая существующий с тем же ключом.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get открывает объект; если его нет — ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; удаление отсутствующего не ошибка.
+	Delete(ctx context.Context, key string) error
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9_\-]+(/[A-Za-z0-9_\-.]+)*$`)
+
+// checkKey пропускает только ключи из букв, цифр и "_-." без ".." —
+// такие одинаково безопасны и как путь на диске, и как путь в URL.
+func checkKey(key string) error {
+	if len(key) > 512 || !keyPattern.MatchString(key) || strings.Contains(key, "..") {
+		return ErrInvalidKey
+	}
+	return nil
+}
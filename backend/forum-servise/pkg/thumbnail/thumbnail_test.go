@@ -0,0 +1,84 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(t *testing.T, format string, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantType string
+		wantW    int
+		wantH    int
+	}{
+		{name: "Wide PNG", data: encode(t, "png", 800, 400), wantType: "image/png", wantW: 320, wantH: 160},
+		{name: "Tall JPEG", data: encode(t, "jpeg", 300, 900), wantType: "image/jpeg", wantW: 106, wantH: 320},
+		{name: "GIF becomes PNG", data: encode(t, "gif", 640, 640), wantType: "image/png", wantW: 320, wantH: 320},
+		{name: "Small image is not enlarged", data: encode(t, "png", 40, 30), wantType: "image/png", wantW: 40, wantH: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, contentType, err := Make(tt.data, DefaultMaxSide)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, contentType)
+
+			img, _, err := image.Decode(bytes.NewReader(data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantW, img.Bounds().Dx())
+			assert.Equal(t, tt.wantH, img.Bounds().Dy())
+
+			r, g, b, _ := img.At(tt.wantW/2, tt.wantH/2).RGBA()
+			// GIF сводит цвета к палитре, поэтому допуск шире точности JPEG.
+			assert.InDelta(t, 200, r>>8, 16)
+			assert.InDelta(t, 100, g>>8, 16)
+			assert.InDelta(t, 50, b>>8, 16)
+		})
+	}
+}
+
+func TestMake_Errors(t *testing.T) {
+	_, _, err := Make([]byte("not an image"), DefaultMaxSide)
+	assert.ErrorIs(t, err, ErrUnsupported)
+
+	// PNG, объявляющий полотно 100000×100000.
+	header := encode(t, "png", 1, 1)
+	huge := append([]byte{}, header...)
+	copy(huge[16:24], []byte{0, 1, 0x86, 0xa0, 0, 1, 0x86, 0xa0})
+	binary.BigEndian.PutUint32(huge[29:33], crc32.ChecksumIEEE(huge[12:29]))
+	_, _, err = Make(huge, DefaultMaxSide)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
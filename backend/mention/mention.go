@@ -0,0 +1,79 @@
+// Package mention разбирает упоминания @username одинаково для форума и
+// чата: правила имени, адрес ссылки и лимит имен на один текст.
+package mention
+
+import (
+	"net/url"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Max — сколько разных имен из одного текста ищется в auth; остальные
+// упоминания остаются текстом.
+const Max = 20
+
+// pattern — @username: буквы, цифры и "_", внутри имени еще "." и "-".
+const pattern = `@([\p{L}\p{N}_](?:[\p{L}\p{N}_.\-]{0,62}[\p{L}\p{N}_])?)`
+
+var (
+	ref   = regexp.MustCompile(pattern)
+	refAt = regexp.MustCompile(`^` + pattern)
+)
+
+// URL — адрес, на который ведет ссылка упоминания.
+func URL(username string) string {
+	return "/users/" + url.PathEscape(username)
+}
+
+// At разбирает упоминание в начале s и возвращает имя и длину упоминания
+// вместе с @; n == 0, если s с упоминания не начинается.
+func At(s string) (username string, n int) {
+	match := refAt.FindStringSubmatch(s)
+	if match == nil {
+		return "", 0
+	}
+	return match[1], len(match[0])
+}
+
+// Find возвращает позиции упоминаний в тексте: [начало, конец, начало
+// имени, конец имени]. @ внутри слова (адрес почты) не считается.
+func Find(text string) [][]int {
+	var found [][]int
+	for _, loc := range ref.FindAllStringSubmatchIndex(text, -1) {
+		if !CanStart(text, loc[0]) {
+			continue
+		}
+		found = append(found, loc)
+	}
+	return found
+}
+
+// CanStart сообщает, может ли @ на позиции i начинать упоминание: перед
+// ним не должно быть буквы, цифры, "_" или "/".
+func CanStart(text string, i int) bool {
+	return i == 0 || !isWordByte(text[i-1])
+}
+
+// Names возвращает до Max упомянутых в тексте имен в порядке появления и
+// без повторов.
+func Names(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, loc := range Find(text) {
+		name := text[loc[2]:loc[3]]
+		if seen[name] {
+			continue
+		}
+		if len(names) == Max {
+			break
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func isWordByte(c byte) bool {
+	return c >= utf8.RuneSelf || c == '_' || c == '/' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
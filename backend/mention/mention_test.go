@@ -0,0 +1,54 @@
+package mention
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "Plain", in: "hi @alice and @bob_2, see @alice", want: []string{"alice", "bob_2"}},
+		{name: "Trailing punctuation", in: "thanks @al.ice. (@carol-) @dave!", want: []string{"al.ice", "carol", "dave"}},
+		{name: "Unicode", in: "привет, @Даша", want: []string{"Даша"}},
+		{name: "Email is not a mention", in: "mail me@ex.com", want: nil},
+		{name: "Path is not a mention", in: "see /users/@alice", want: nil},
+		{name: "Bare at", in: "@ @", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Names(tt.in))
+		})
+	}
+}
+
+func TestNames_Limit(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < Max+5; i++ {
+		fmt.Fprintf(&b, "@user%d ", i)
+	}
+	names := Names(b.String())
+	assert.Len(t, names, Max)
+	assert.Equal(t, "user0", names[0])
+}
+
+func TestAt(t *testing.T) {
+	name, n := At("@bob. hi")
+	assert.Equal(t, "bob", name)
+	assert.Equal(t, 4, n)
+
+	_, n = At("hi @bob")
+	assert.Zero(t, n)
+}
+
+func TestURL(t *testing.T) {
+	assert.Equal(t, "/users/alice", URL("alice"))
+	assert.Equal(t, "/users/%D0%94%D0%B0%D1%88%D0%B0", URL("Даша"))
+}
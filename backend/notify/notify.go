@@ -0,0 +1,73 @@
+// Package notify создает уведомления в общей таблице notifications. Форум
+// и чат создают их только через Create, поэтому настройки получателей
+// проверяются в одном месте.
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TypeMention — уведомление об упоминании @username.
+const TypeMention = "mention"
+
+// Querier — *sql.DB, *sql.Tx или *sqlx.DB.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Notification — уведомление о действии ActorID. Незаданные Action и
+// ссылки на пост, комментарий, комнату и сообщение остаются NULL.
+type Notification struct {
+	Type      string
+	ActorID   int64
+	ActorName string
+	Action    string
+	PostID    *int64
+	CommentID *int64
+	RoomID    *int64
+	MessageID *int64
+}
+
+// Created — уведомление, созданное получателю UserID.
+type Created struct {
+	ID        int64
+	UserID    int64
+	CreatedAt time.Time
+}
+
+// Create создает копию n для каждого из userIDs, кроме отключивших
+// уведомления типа n.Type, и возвращает созданные.
+func Create(ctx context.Context, q Querier, n Notification, userIDs []int64) ([]Created, error) {
+	created := []Created{}
+	if len(userIDs) == 0 {
+		return created, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		INSERT INTO notifications (user_id, type, actor_id, actor_name, action, post_id, comment_id, room_id, message_id)
+		SELECT u.id, $2, $3, $4, NULLIF($9, ''), $5, $6, $7, $8
+		FROM unnest($1::int[]) AS u(id)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM notification_preferences np
+			WHERE np.user_id = u.id AND np.type = $2 AND NOT np.enabled
+		)
+		RETURNING id, user_id, created_at`,
+		pq.Array(userIDs), n.Type, n.ActorID, n.ActorName, n.PostID, n.CommentID, n.RoomID, n.MessageID, n.Action)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Created
+		if err := rows.Scan(&c.ID, &c.UserID, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		created = append(created, c)
+	}
+	return created, rows.Err()
+}
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	roomID, messageID := int64(3), int64(7)
+	n := Notification{Type: TypeMention, ActorID: 2, ActorName: "alice", RoomID: &roomID, MessageID: &messageID}
+
+	mock.ExpectQuery(`INSERT INTO notifications \(user_id, type, actor_id, actor_name, action, post_id, comment_id, room_id, message_id\)\s+SELECT u.id, \$2, \$3, \$4, NULLIF\(\$9, ''\), \$5, \$6, \$7, \$8\s+FROM unnest\(\$1::int\[\]\) AS u\(id\)\s+WHERE NOT EXISTS \(.+np.type = \$2 AND NOT np.enabled\s+\)\s+RETURNING id, user_id, created_at`).
+		WithArgs(pq.Array([]int64{5, 6}), TypeMention, int64(2), "alice", nil, nil, roomID, messageID, "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).AddRow(10, 5, now))
+
+	created, err := Create(context.Background(), db, n, []int64{5, 6})
+	require.NoError(t, err)
+	assert.Equal(t, []Created{{ID: 10, UserID: 5, CreatedAt: now}}, created)
+
+	created, err = Create(context.Background(), db, n, nil)
+	require.NoError(t, err)
+	assert.Empty(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -294,6 +294,7 @@ type Post struct {
 	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
 	AuthorId      int64                  `protobuf:"varint,4,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
 	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	TopicId       int64                  `protobuf:"varint,6,opt,name=topic_id,json=topicId,proto3" json:"topic_id,omitempty"` // 0, если пост не привязан к теме
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -363,11 +364,18 @@ func (x *Post) GetCreatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Post) GetTopicId() int64 {
+	if x != nil {
+		return x.TopicId
+	}
+	return 0
+}
+
 type ChatMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"` // Добавьте это поле
+	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
 	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
 	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
@@ -629,7 +637,7 @@ type CreateTopicRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	CategoryId    int64                  `protobuf:"varint,1,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
 	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // не используется: автор берется из токена
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -821,7 +829,7 @@ func (x *GetTopicResponse) GetTopic() *Topic {
 type CreateMessageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TopicId       int64                  `protobuf:"varint,1,opt,name=topic_id,json=topicId,proto3" json:"topic_id,omitempty"`
-	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // не используется: автор берется из токена
 	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -1015,7 +1023,8 @@ type CreatePostRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
 	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
-	AuthorId      int64                  `protobuf:"varint,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	AuthorId      int64                  `protobuf:"varint,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"` // не используется: автор берется из токена
+	TopicId       int64                  `protobuf:"varint,4,opt,name=topic_id,json=topicId,proto3" json:"topic_id,omitempty"`    // необязательно
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1071,6 +1080,13 @@ func (x *CreatePostRequest) GetAuthorId() int64 {
 	return 0
 }
 
+func (x *CreatePostRequest) GetTopicId() int64 {
+	if x != nil {
+		return x.TopicId
+	}
+	return 0
+}
+
 type CreatePostResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -1125,9 +1141,11 @@ func (x *CreatePostResponse) GetPost() *Post {
 
 type GetPostsRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Можно добавить параметры пагинации или фильтрации
-	Limit         int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset        int32 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// 0 — размер страницы по умолчанию
+	Limit  int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset int32 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// next_cursor предыдущей страницы; нельзя сочетать с offset
+	Cursor        string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1176,9 +1194,19 @@ func (x *GetPostsRequest) GetOffset() int32 {
 	return 0
 }
 
+func (x *GetPostsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
 type GetPostsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Posts []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	Total int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// пусто, если это последняя страница
+	NextCursor    string `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1220,18 +1248,286 @@ func (x *GetPostsResponse) GetPosts() []*Post {
 	return nil
 }
 
+func (x *GetPostsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GetPostsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// Запросы и ответы для поиска
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	AuthorId      int64                  `protobuf:"varint,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"` // 0 — без фильтра
+	TopicId       int64                  `protobuf:"varint,3,opt,name=topic_id,json=topicId,proto3" json:"topic_id,omitempty"`    // 0 — без фильтра
+	From          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`                          // включительно
+	To            *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`                              // не включительно
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`                       // 0 — размер страницы по умолчанию
+	Offset        int32                  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_forum_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forum_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_forum_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetTopicId() int64 {
+	if x != nil {
+		return x.TopicId
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // "post" или "comment"
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	PostId        int64                  `protobuf:"varint,3,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Snippet       string                 `protobuf:"bytes,5,opt,name=snippet,proto3" json:"snippet,omitempty"` // совпадения обернуты в <mark>, текст экранирован
+	AuthorId      int64                  `protobuf:"varint,6,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	AuthorName    string                 `protobuf:"bytes,7,opt,name=author_name,json=authorName,proto3" json:"author_name,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Rank          float32                `protobuf:"fixed32,9,opt,name=rank,proto3" json:"rank,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_forum_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_forum_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_forum_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SearchResult) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SearchResult) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SearchResult) GetPostId() int64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
+
+func (x *SearchResult) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *SearchResult) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *SearchResult) GetAuthorName() string {
+	if x != nil {
+		return x.AuthorName
+	}
+	return ""
+}
+
+func (x *SearchResult) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *SearchResult) GetRank() float32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_forum_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forum_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_forum_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SearchResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
 // Запросы и ответы для чата
 type CreateChatMessageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // не используется: автор берется из токена
 	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	RoomId        int64                  `protobuf:"varint,3,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"` // 0 — общая комната
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateChatMessageRequest) Reset() {
 	*x = CreateChatMessageRequest{}
-	mi := &file_forum_proto_msgTypes[22]
+	mi := &file_forum_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1243,7 +1539,7 @@ func (x *CreateChatMessageRequest) String() string {
 func (*CreateChatMessageRequest) ProtoMessage() {}
 
 func (x *CreateChatMessageRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_forum_proto_msgTypes[22]
+	mi := &file_forum_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1256,7 +1552,7 @@ func (x *CreateChatMessageRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateChatMessageRequest.ProtoReflect.Descriptor instead.
 func (*CreateChatMessageRequest) Descriptor() ([]byte, []int) {
-	return file_forum_proto_rawDescGZIP(), []int{22}
+	return file_forum_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *CreateChatMessageRequest) GetUserId() int64 {
@@ -1273,6 +1569,13 @@ func (x *CreateChatMessageRequest) GetContent() string {
 	return ""
 }
 
+func (x *CreateChatMessageRequest) GetRoomId() int64 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
+}
+
 type CreateChatMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -1282,7 +1585,7 @@ type CreateChatMessageResponse struct {
 
 func (x *CreateChatMessageResponse) Reset() {
 	*x = CreateChatMessageResponse{}
-	mi := &file_forum_proto_msgTypes[23]
+	mi := &file_forum_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1294,7 +1597,7 @@ func (x *CreateChatMessageResponse) String() string {
 func (*CreateChatMessageResponse) ProtoMessage() {}
 
 func (x *CreateChatMessageResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_forum_proto_msgTypes[23]
+	mi := &file_forum_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1307,7 +1610,7 @@ func (x *CreateChatMessageResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateChatMessageResponse.ProtoReflect.Descriptor instead.
 func (*CreateChatMessageResponse) Descriptor() ([]byte, []int) {
-	return file_forum_proto_rawDescGZIP(), []int{23}
+	return file_forum_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *CreateChatMessageResponse) GetId() int64 {
@@ -1319,13 +1622,14 @@ func (x *CreateChatMessageResponse) GetId() int64 {
 
 type StreamChatMessagesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomId        int64                  `protobuf:"varint,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"` // 0 — общая комната
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StreamChatMessagesRequest) Reset() {
 	*x = StreamChatMessagesRequest{}
-	mi := &file_forum_proto_msgTypes[24]
+	mi := &file_forum_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1337,7 +1641,7 @@ func (x *StreamChatMessagesRequest) String() string {
 func (*StreamChatMessagesRequest) ProtoMessage() {}
 
 func (x *StreamChatMessagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_forum_proto_msgTypes[24]
+	mi := &file_forum_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1350,7 +1654,14 @@ func (x *StreamChatMessagesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamChatMessagesRequest.ProtoReflect.Descriptor instead.
 func (*StreamChatMessagesRequest) Descriptor() ([]byte, []int) {
-	return file_forum_proto_rawDescGZIP(), []int{24}
+	return file_forum_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *StreamChatMessagesRequest) GetRoomId() int64 {
+	if x != nil {
+		return x.RoomId
+	}
+	return 0
 }
 
 var File_forum_proto protoreflect.FileDescriptor
@@ -1380,14 +1691,15 @@ const file_forum_proto_rawDesc = "" +
 	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12\x18\n" +
 	"\acontent\x18\x04 \x01(\tR\acontent\x129\n" +
 	"\n" +
-	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x9e\x01\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xb9\x01\n" +
 	"\x04Post\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12\x1b\n" +
 	"\tauthor_id\x18\x04 \x01(\x03R\bauthorId\x129\n" +
 	"\n" +
-	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xa7\x01\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x19\n" +
+	"\btopic_id\x18\x06 \x01(\x03R\atopicId\"\xa7\x01\n" +
 	"\vChatMessage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x1a\n" +
@@ -1424,25 +1736,55 @@ const file_forum_proto_rawDesc = "" +
 	"\x11GetMessageRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\">\n" +
 	"\x12GetMessageResponse\x12(\n" +
-	"\amessage\x18\x01 \x01(\v2\x0e.forum.MessageR\amessage\"`\n" +
+	"\amessage\x18\x01 \x01(\v2\x0e.forum.MessageR\amessage\"{\n" +
 	"\x11CreatePostRequest\x12\x14\n" +
 	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
 	"\acontent\x18\x02 \x01(\tR\acontent\x12\x1b\n" +
-	"\tauthor_id\x18\x03 \x01(\x03R\bauthorId\"E\n" +
+	"\tauthor_id\x18\x03 \x01(\x03R\bauthorId\x12\x19\n" +
+	"\btopic_id\x18\x04 \x01(\x03R\atopicId\"E\n" +
 	"\x12CreatePostResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1f\n" +
-	"\x04post\x18\x02 \x01(\v2\v.forum.PostR\x04post\"?\n" +
+	"\x04post\x18\x02 \x01(\v2\v.forum.PostR\x04post\"W\n" +
 	"\x0fGetPostsRequest\x12\x14\n" +
 	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06offset\x18\x02 \x01(\x05R\x06offset\"5\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"l\n" +
 	"\x10GetPostsResponse\x12!\n" +
-	"\x05posts\x18\x01 \x03(\v2\v.forum.PostR\x05posts\"M\n" +
+	"\x05posts\x18\x01 \x03(\v2\v.forum.PostR\x05posts\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"\xe7\x01\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\x03R\bauthorId\x12\x19\n" +
+	"\btopic_id\x18\x03 \x01(\x03R\atopicId\x12.\n" +
+	"\x04from\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x05R\x06offset\"\x88\x02\n" +
+	"\fSearchResult\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x17\n" +
+	"\apost_id\x18\x03 \x01(\x03R\x06postId\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12\x18\n" +
+	"\asnippet\x18\x05 \x01(\tR\asnippet\x12\x1b\n" +
+	"\tauthor_id\x18\x06 \x01(\x03R\bauthorId\x12\x1f\n" +
+	"\vauthor_name\x18\a \x01(\tR\n" +
+	"authorName\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x12\n" +
+	"\x04rank\x18\t \x01(\x02R\x04rank\"U\n" +
+	"\x0eSearchResponse\x12-\n" +
+	"\aresults\x18\x01 \x03(\v2\x13.forum.SearchResultR\aresults\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\"f\n" +
 	"\x18CreateChatMessageRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\tR\acontent\"+\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x17\n" +
+	"\aroom_id\x18\x03 \x01(\x03R\x06roomId\"+\n" +
 	"\x19CreateChatMessageResponse\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\x1b\n" +
-	"\x19StreamChatMessagesRequest2\xdb\x05\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"4\n" +
+	"\x19StreamChatMessagesRequest\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\x03R\x06roomId2\x92\x06\n" +
 	"\fForumService\x12M\n" +
 	"\x0eCreateCategory\x12\x1c.forum.CreateCategoryRequest\x1a\x1d.forum.CreateCategoryResponse\x12D\n" +
 	"\vGetCategory\x12\x19.forum.GetCategoryRequest\x1a\x1a.forum.GetCategoryResponse\x12D\n" +
@@ -1453,7 +1795,8 @@ const file_forum_proto_rawDesc = "" +
 	"GetMessage\x12\x18.forum.GetMessageRequest\x1a\x19.forum.GetMessageResponse\x12A\n" +
 	"\n" +
 	"CreatePost\x12\x18.forum.CreatePostRequest\x1a\x19.forum.CreatePostResponse\x12;\n" +
-	"\bGetPosts\x12\x16.forum.GetPostsRequest\x1a\x17.forum.GetPostsResponse\x12V\n" +
+	"\bGetPosts\x12\x16.forum.GetPostsRequest\x1a\x17.forum.GetPostsResponse\x125\n" +
+	"\x06Search\x12\x14.forum.SearchRequest\x1a\x15.forum.SearchResponse\x12V\n" +
 	"\x11CreateChatMessage\x12\x1f.forum.CreateChatMessageRequest\x1a .forum.CreateChatMessageResponse\x12L\n" +
 	"\x12StreamChatMessages\x12 .forum.StreamChatMessagesRequest\x1a\x12.forum.ChatMessage0\x01B\x19Z\x17backend.com/forum/protob\x06proto3"
 
@@ -1469,7 +1812,7 @@ func file_forum_proto_rawDescGZIP() []byte {
 	return file_forum_proto_rawDescData
 }
 
-var file_forum_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_forum_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_forum_proto_goTypes = []any{
 	(*Category)(nil),                  // 0: forum.Category
 	(*Topic)(nil),                     // 1: forum.Topic
@@ -1493,47 +1836,56 @@ var file_forum_proto_goTypes = []any{
 	(*CreatePostResponse)(nil),        // 19: forum.CreatePostResponse
 	(*GetPostsRequest)(nil),           // 20: forum.GetPostsRequest
 	(*GetPostsResponse)(nil),          // 21: forum.GetPostsResponse
-	(*CreateChatMessageRequest)(nil),  // 22: forum.CreateChatMessageRequest
-	(*CreateChatMessageResponse)(nil), // 23: forum.CreateChatMessageResponse
-	(*StreamChatMessagesRequest)(nil), // 24: forum.StreamChatMessagesRequest
-	(*timestamppb.Timestamp)(nil),     // 25: google.protobuf.Timestamp
+	(*SearchRequest)(nil),             // 22: forum.SearchRequest
+	(*SearchResult)(nil),              // 23: forum.SearchResult
+	(*SearchResponse)(nil),            // 24: forum.SearchResponse
+	(*CreateChatMessageRequest)(nil),  // 25: forum.CreateChatMessageRequest
+	(*CreateChatMessageResponse)(nil), // 26: forum.CreateChatMessageResponse
+	(*StreamChatMessagesRequest)(nil), // 27: forum.StreamChatMessagesRequest
+	(*timestamppb.Timestamp)(nil),     // 28: google.protobuf.Timestamp
 }
 var file_forum_proto_depIdxs = []int32{
-	25, // 0: forum.Category.created_at:type_name -> google.protobuf.Timestamp
-	25, // 1: forum.Topic.created_at:type_name -> google.protobuf.Timestamp
-	25, // 2: forum.Message.created_at:type_name -> google.protobuf.Timestamp
-	25, // 3: forum.Post.created_at:type_name -> google.protobuf.Timestamp
-	25, // 4: forum.ChatMessage.created_at:type_name -> google.protobuf.Timestamp
+	28, // 0: forum.Category.created_at:type_name -> google.protobuf.Timestamp
+	28, // 1: forum.Topic.created_at:type_name -> google.protobuf.Timestamp
+	28, // 2: forum.Message.created_at:type_name -> google.protobuf.Timestamp
+	28, // 3: forum.Post.created_at:type_name -> google.protobuf.Timestamp
+	28, // 4: forum.ChatMessage.created_at:type_name -> google.protobuf.Timestamp
 	0,  // 5: forum.GetCategoryResponse.category:type_name -> forum.Category
 	1,  // 6: forum.GetTopicResponse.topic:type_name -> forum.Topic
 	3,  // 7: forum.GetMessageResponse.message:type_name -> forum.Message
 	4,  // 8: forum.CreatePostResponse.post:type_name -> forum.Post
 	4,  // 9: forum.GetPostsResponse.posts:type_name -> forum.Post
-	6,  // 10: forum.ForumService.CreateCategory:input_type -> forum.CreateCategoryRequest
-	8,  // 11: forum.ForumService.GetCategory:input_type -> forum.GetCategoryRequest
-	10, // 12: forum.ForumService.CreateTopic:input_type -> forum.CreateTopicRequest
-	12, // 13: forum.ForumService.GetTopic:input_type -> forum.GetTopicRequest
-	14, // 14: forum.ForumService.CreateMessage:input_type -> forum.CreateMessageRequest
-	16, // 15: forum.ForumService.GetMessage:input_type -> forum.GetMessageRequest
-	18, // 16: forum.ForumService.CreatePost:input_type -> forum.CreatePostRequest
-	20, // 17: forum.ForumService.GetPosts:input_type -> forum.GetPostsRequest
-	22, // 18: forum.ForumService.CreateChatMessage:input_type -> forum.CreateChatMessageRequest
-	24, // 19: forum.ForumService.StreamChatMessages:input_type -> forum.StreamChatMessagesRequest
-	7,  // 20: forum.ForumService.CreateCategory:output_type -> forum.CreateCategoryResponse
-	9,  // 21: forum.ForumService.GetCategory:output_type -> forum.GetCategoryResponse
-	11, // 22: forum.ForumService.CreateTopic:output_type -> forum.CreateTopicResponse
-	13, // 23: forum.ForumService.GetTopic:output_type -> forum.GetTopicResponse
-	15, // 24: forum.ForumService.CreateMessage:output_type -> forum.CreateMessageResponse
-	17, // 25: forum.ForumService.GetMessage:output_type -> forum.GetMessageResponse
-	19, // 26: forum.ForumService.CreatePost:output_type -> forum.CreatePostResponse
-	21, // 27: forum.ForumService.GetPosts:output_type -> forum.GetPostsResponse
-	23, // 28: forum.ForumService.CreateChatMessage:output_type -> forum.CreateChatMessageResponse
-	5,  // 29: forum.ForumService.StreamChatMessages:output_type -> forum.ChatMessage
-	20, // [20:30] is the sub-list for method output_type
-	10, // [10:20] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	28, // 10: forum.SearchRequest.from:type_name -> google.protobuf.Timestamp
+	28, // 11: forum.SearchRequest.to:type_name -> google.protobuf.Timestamp
+	28, // 12: forum.SearchResult.created_at:type_name -> google.protobuf.Timestamp
+	23, // 13: forum.SearchResponse.results:type_name -> forum.SearchResult
+	6,  // 14: forum.ForumService.CreateCategory:input_type -> forum.CreateCategoryRequest
+	8,  // 15: forum.ForumService.GetCategory:input_type -> forum.GetCategoryRequest
+	10, // 16: forum.ForumService.CreateTopic:input_type -> forum.CreateTopicRequest
+	12, // 17: forum.ForumService.GetTopic:input_type -> forum.GetTopicRequest
+	14, // 18: forum.ForumService.CreateMessage:input_type -> forum.CreateMessageRequest
+	16, // 19: forum.ForumService.GetMessage:input_type -> forum.GetMessageRequest
+	18, // 20: forum.ForumService.CreatePost:input_type -> forum.CreatePostRequest
+	20, // 21: forum.ForumService.GetPosts:input_type -> forum.GetPostsRequest
+	22, // 22: forum.ForumService.Search:input_type -> forum.SearchRequest
+	25, // 23: forum.ForumService.CreateChatMessage:input_type -> forum.CreateChatMessageRequest
+	27, // 24: forum.ForumService.StreamChatMessages:input_type -> forum.StreamChatMessagesRequest
+	7,  // 25: forum.ForumService.CreateCategory:output_type -> forum.CreateCategoryResponse
+	9,  // 26: forum.ForumService.GetCategory:output_type -> forum.GetCategoryResponse
+	11, // 27: forum.ForumService.CreateTopic:output_type -> forum.CreateTopicResponse
+	13, // 28: forum.ForumService.GetTopic:output_type -> forum.GetTopicResponse
+	15, // 29: forum.ForumService.CreateMessage:output_type -> forum.CreateMessageResponse
+	17, // 30: forum.ForumService.GetMessage:output_type -> forum.GetMessageResponse
+	19, // 31: forum.ForumService.CreatePost:output_type -> forum.CreatePostResponse
+	21, // 32: forum.ForumService.GetPosts:output_type -> forum.GetPostsResponse
+	24, // 33: forum.ForumService.Search:output_type -> forum.SearchResponse
+	26, // 34: forum.ForumService.CreateChatMessage:output_type -> forum.CreateChatMessageResponse
+	5,  // 35: forum.ForumService.StreamChatMessages:output_type -> forum.ChatMessage
+	25, // [25:36] is the sub-list for method output_type
+	14, // [14:25] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_forum_proto_init() }
@@ -1547,7 +1899,7 @@ func file_forum_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_forum_proto_rawDesc), len(file_forum_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   25,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
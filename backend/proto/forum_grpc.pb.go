@@ -27,6 +27,7 @@ const (
 	ForumService_GetMessage_FullMethodName         = "/forum.ForumService/GetMessage"
 	ForumService_CreatePost_FullMethodName         = "/forum.ForumService/CreatePost"
 	ForumService_GetPosts_FullMethodName           = "/forum.ForumService/GetPosts"
+	ForumService_Search_FullMethodName             = "/forum.ForumService/Search"
 	ForumService_CreateChatMessage_FullMethodName  = "/forum.ForumService/CreateChatMessage"
 	ForumService_StreamChatMessages_FullMethodName = "/forum.ForumService/StreamChatMessages"
 )
@@ -49,6 +50,8 @@ type ForumServiceClient interface {
 	// Посты
 	CreatePost(ctx context.Context, in *CreatePostRequest, opts ...grpc.CallOption) (*CreatePostResponse, error)
 	GetPosts(ctx context.Context, in *GetPostsRequest, opts ...grpc.CallOption) (*GetPostsResponse, error)
+	// Поиск
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
 	// Чат
 	CreateChatMessage(ctx context.Context, in *CreateChatMessageRequest, opts ...grpc.CallOption) (*CreateChatMessageResponse, error)
 	StreamChatMessages(ctx context.Context, in *StreamChatMessagesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChatMessage], error)
@@ -142,6 +145,16 @@ func (c *forumServiceClient) GetPosts(ctx context.Context, in *GetPostsRequest,
 	return out, nil
 }
 
+func (c *forumServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, ForumService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *forumServiceClient) CreateChatMessage(ctx context.Context, in *CreateChatMessageRequest, opts ...grpc.CallOption) (*CreateChatMessageResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateChatMessageResponse)
@@ -189,6 +202,8 @@ type ForumServiceServer interface {
 	// Посты
 	CreatePost(context.Context, *CreatePostRequest) (*CreatePostResponse, error)
 	GetPosts(context.Context, *GetPostsRequest) (*GetPostsResponse, error)
+	// Поиск
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
 	// Чат
 	CreateChatMessage(context.Context, *CreateChatMessageRequest) (*CreateChatMessageResponse, error)
 	StreamChatMessages(*StreamChatMessagesRequest, grpc.ServerStreamingServer[ChatMessage]) error
@@ -226,6 +241,9 @@ func (UnimplementedForumServiceServer) CreatePost(context.Context, *CreatePostRe
 func (UnimplementedForumServiceServer) GetPosts(context.Context, *GetPostsRequest) (*GetPostsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPosts not implemented")
 }
+func (UnimplementedForumServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
 func (UnimplementedForumServiceServer) CreateChatMessage(context.Context, *CreateChatMessageRequest) (*CreateChatMessageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateChatMessage not implemented")
 }
@@ -397,6 +415,24 @@ func _ForumService_GetPosts_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ForumService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForumServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForumService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForumServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ForumService_CreateChatMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateChatMessageRequest)
 	if err := dec(in); err != nil {
@@ -465,6 +501,10 @@ var ForumService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPosts",
 			Handler:    _ForumService_GetPosts_Handler,
 		},
+		{
+			MethodName: "Search",
+			Handler:    _ForumService_Search_Handler,
+		},
 		{
 			MethodName: "CreateChatMessage",
 			Handler:    _ForumService_CreateChatMessage_Handler,
@@ -15,6 +15,7 @@ const Login = () => {
             console.log('Login response:', response.data);
             const token = response.data.token;
             localStorage.setItem('token', token);
+            localStorage.setItem('refreshToken', response.data.refresh_token);
 
             // Распарсить user_id из токена
             const base64Url = token.split('.')[1];
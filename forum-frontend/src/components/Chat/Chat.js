@@ -10,8 +10,13 @@ const Chat = () => {
     const [connectionStatus, setConnectionStatus] = useState('connecting');
     const [error, setError] = useState(null);
     const [isLoading, setIsLoading] = useState(true);
+    const [historyCursor, setHistoryCursor] = useState(null);
+    const [hasMore, setHasMore] = useState(false);
+    const [unreadCount, setUnreadCount] = useState(0);
     const navigate = useNavigate();
     const messagesEndRef = useRef(null);
+    // Запрошены более старые сообщения: их страницу нужно добавить в начало
+    const loadingOlder = useRef(false);
 
     const token = localStorage.getItem('token');
     const username = localStorage.getItem('username');
@@ -20,10 +25,15 @@ const Chat = () => {
     const { sendMessage, lastMessage } = useWebSocket(
         'ws://localhost:8082/ws',
         {
-            onOpen: () => {
+            // Токен передаётся подпротоколом: заголовок Authorization
+            // браузер при открытии WebSocket не отправляет.
+            protocols: ['bearer', token],
+            onOpen: (event) => {
                 console.log('WebSocket connection established');
                 setConnectionStatus('connected');
                 setError(null);
+                // Последняя страница истории вместе с курсором для подгрузки
+                event.target.send(JSON.stringify({ type: 'history' }));
             },
             onClose: () => {
                 console.log('WebSocket connection closed');
@@ -38,7 +48,8 @@ const Chat = () => {
             shouldReconnect: () => true,
             reconnectAttempts: 10,
             reconnectInterval: 3000,
-        }
+        },
+        isAuthenticated
     );
 
     useEffect(() => {
@@ -62,10 +73,42 @@ const Chat = () => {
         fetchMessages();
     }, []);
 
+    useEffect(() => {
+        if (!isAuthenticated) return;
+        // Дальше счётчик обновляют кадры notification из WebSocket
+        axios.get('http://localhost:8081/api/v1/notifications/unread-count', {
+            headers: { Authorization: `Bearer ${token}` }
+        })
+            .then(response => setUnreadCount(response.data.unread_count || 0))
+            .catch(err => console.error('Error fetching notifications:', err));
+    }, [isAuthenticated, token]);
+
     useEffect(() => {
         if (lastMessage !== null) {
             try {
                 const newMessage = JSON.parse(lastMessage.data);
+                if (newMessage.type === 'history') {
+                    const page = newMessage.messages || [];
+                    if (loadingOlder.current) {
+                        setMessages(prev => [...page, ...prev]);
+                    } else {
+                        setMessages(page);
+                    }
+                    setHistoryCursor(newMessage.before || null);
+                    setHasMore(newMessage.has_more);
+                    return;
+                }
+                if (newMessage.type === 'notification') {
+                    setUnreadCount(newMessage.unread_count);
+                    return;
+                }
+                // Служебные кадры (joined, left, error) в ленту не попадают
+                if (newMessage.type && newMessage.type !== 'message') {
+                    if (newMessage.type === 'error') {
+                        console.error('Chat error:', newMessage.error);
+                    }
+                    return;
+                }
                 // Добавляем timestamp, если его нет
                 if (!newMessage.timestamp) {
                     newMessage.timestamp = new Date().toISOString();
@@ -78,9 +121,20 @@ const Chat = () => {
     }, [lastMessage]);
 
     useEffect(() => {
+        // После подгрузки старых сообщений остаёмся на месте
+        if (loadingOlder.current) {
+            loadingOlder.current = false;
+            return;
+        }
         messagesEndRef.current?.scrollIntoView({ behavior: 'smooth' });
     }, [messages]);
 
+    const handleLoadOlder = () => {
+        if (!historyCursor) return;
+        loadingOlder.current = true;
+        sendMessage(JSON.stringify({ type: 'history', before: historyCursor }));
+    };
+
     const handleSendMessage = () => {
         if (!isAuthenticated) {
             navigate('/login');
@@ -89,10 +143,9 @@ const Chat = () => {
 
         if (!message.trim()) return;
 
+        // Автора и время сервер проставляет сам.
         const msg = {
-            username: username || 'Anonymous',
-            message: message.trim(),
-            timestamp: new Date().toISOString() // Всегда добавляем timestamp при отправке
+            message: message.trim()
         };
 
         sendMessage(JSON.stringify(msg));
@@ -126,6 +179,11 @@ const Chat = () => {
             <div className="chat-container">
                 <div className="chat-header">
                     <h2>Community Chat</h2>
+                    {unreadCount > 0 && (
+                        <div className="notification-badge" title="Unread notifications">
+                            {unreadCount}
+                        </div>
+                    )}
                     <div className={`connection-status ${connectionStatus}`}>
                         {connectionStatus.toUpperCase()}
                     </div>
@@ -134,6 +192,15 @@ const Chat = () => {
                 {error && <div className="error-message">{error}</div>}
 
                 <div className="messages-window">
+                    {hasMore && (
+                        <button
+                            className="load-more-button"
+                            onClick={handleLoadOlder}
+                            disabled={connectionStatus !== 'connected'}
+                        >
+                            Load earlier messages
+                        </button>
+                    )}
                     {messages.length > 0 ? (
                         messages.map((msg, index) => (
                             <div key={index} className={`message ${msg.username === username ? 'own-message' : ''}`}>
@@ -143,7 +210,15 @@ const Chat = () => {
                                         {formatMessageTime(msg.timestamp)}
                                     </span>
                                 </div>
-                                <div className="message-content">{msg.message}</div>
+                                {msg.message_html ? (
+                                    // message_html — экранированный на сервере текст со ссылками упоминаний
+                                    <div
+                                        className="message-content"
+                                        dangerouslySetInnerHTML={{ __html: msg.message_html }}
+                                    />
+                                ) : (
+                                    <div className="message-content">{msg.message}</div>
+                                )}
                             </div>
                         ))
                     ) : (
@@ -1,13 +1,23 @@
 import React from 'react';
  import '../MainLayout.css'; // Импортируйте CSS здесь
 import { Link, useNavigate } from 'react-router-dom';
+import axios from 'axios';
 
 const Navbar = () => {
     const navigate = useNavigate();
     const isLoggedIn = localStorage.getItem('token') !== null;
 
-    const handleLogout = () => {
+    const handleLogout = async () => {
+        const token = localStorage.getItem('token');
+        try {
+            await axios.post('http://localhost:8080/api/v1/auth/logout', null, {
+                headers: { Authorization: `Bearer ${token}` },
+            });
+        } catch (error) {
+            console.error('Logout error:', error);
+        }
         localStorage.removeItem('token');
+        localStorage.removeItem('refreshToken');
         navigate('/login');
     };
 
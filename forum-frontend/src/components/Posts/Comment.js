@@ -3,8 +3,224 @@ import axios from 'axios';
 import { useNavigate } from 'react-router-dom';
 import '/Users/darinautalieva/Desktop/GOProject/forum-frontend/src/components/MainLayout.css';
 
+const API_URL = 'http://localhost:8081/api/v1';
+const THREADS_PAGE_SIZE = 20;
+const REPLIES_PAGE_SIZE = 100;
+
+const normalizeComment = (comment) => ({
+    id: parseInt(comment.id, 10),
+    author_id: parseInt(comment.author_id, 10),
+    post_id: parseInt(comment.post_id, 10),
+    parent_id: comment.parent_id ? parseInt(comment.parent_id, 10) : null,
+    depth: comment.depth || 0,
+    content: comment.content || '',
+    content_html: comment.content_html || '',
+    author_name: comment.author_name || `User #${comment.author_id}`,
+    created_at: comment.created_at || new Date().toISOString(),
+    updated_at: comment.updated_at || null,
+    edited: !!comment.edited,
+    deleted: !!comment.deleted,
+    reply_count: comment.reply_count || 0,
+    replies: (comment.replies || []).map(normalizeComment)
+});
+
+// Применяет fn к комментарию с данным id, где бы в дереве он ни был
+const updateComment = (comments, id, fn) => comments.map(comment => {
+    if (comment.id === id) {
+        return fn(comment);
+    }
+    if (comment.replies.length === 0) {
+        return comment;
+    }
+    return { ...comment, replies: updateComment(comment.replies, id, fn) };
+});
+
+// Убирает комментарий из дерева и уменьшает счетчик ответов у его родителя
+const removeComment = (comments, id) => comments
+    .filter(comment => comment.id !== id)
+    .map(comment => {
+        if (comment.replies.length === 0) {
+            return comment;
+        }
+        const replies = removeComment(comment.replies, id);
+        const removed = comment.replies.some(reply => reply.id === id);
+        return {
+            ...comment,
+            replies,
+            reply_count: removed ? comment.reply_count - 1 : comment.reply_count
+        };
+    });
+
+const DELETED_PLACEHOLDER = '[deleted]';
+
+// Комментарий с ответами после удаления остается в ветке заглушкой
+const markDeleted = (comment) => ({
+    ...comment,
+    content: DELETED_PLACEHOLDER,
+    author_name: DELETED_PLACEHOLDER,
+    author_id: 0,
+    edited: false,
+    deleted: true
+});
+
+const formatDate = (value) => new Date(value).toLocaleDateString('en-US', {
+    hour: '2-digit',
+    minute: '2-digit',
+    day: 'numeric',
+    month: 'short',
+    year: 'numeric'
+});
+
+const CommentItem = ({ comment, canReply, canModify, onReply, onEdit, onDelete, onLoadReplies }) => {
+    const [replying, setReplying] = useState(false);
+    const [replyText, setReplyText] = useState('');
+    const [editing, setEditing] = useState(false);
+    const [editText, setEditText] = useState('');
+    const [submitting, setSubmitting] = useState(false);
+    const hiddenReplies = comment.reply_count - comment.replies.length;
+
+    const handleReply = async (e) => {
+        e.preventDefault();
+        if (!replyText.trim() || submitting) return;
+
+        setSubmitting(true);
+        const ok = await onReply(comment, replyText);
+        setSubmitting(false);
+        if (ok) {
+            setReplyText('');
+            setReplying(false);
+        }
+    };
+
+    const startEditing = () => {
+        setEditText(comment.content);
+        setEditing(true);
+    };
+
+    const handleEdit = async (e) => {
+        e.preventDefault();
+        if (!editText.trim() || submitting) return;
+
+        setSubmitting(true);
+        const ok = await onEdit(comment, editText);
+        setSubmitting(false);
+        if (ok) {
+            setEditing(false);
+        }
+    };
+
+    return (
+        <div className="comment-item" style={{ marginLeft: comment.depth > 0 ? 20 : 0 }}>
+            <div className="comment-header">
+                <span className="comment-author">
+                    {comment.author_name}
+                </span>
+                <span className="comment-timestamp">
+                    {formatDate(comment.created_at)}
+                </span>
+                {comment.edited && !comment.deleted && (
+                    <span
+                        className="comment-edited"
+                        title={comment.updated_at ? `Edited ${formatDate(comment.updated_at)}` : undefined}
+                    >
+                        (edited)
+                    </span>
+                )}
+                {!comment.deleted && canModify(comment) && !editing && (
+                    <div className="comment-actions">
+                        <button onClick={startEditing} className="edit-button" title="Edit comment">
+                            ✎
+                        </button>
+                        <button onClick={() => onDelete(comment)} className="delete-button" title="Delete comment">
+                            ✕
+                        </button>
+                    </div>
+                )}
+            </div>
+            {editing ? (
+                <form onSubmit={handleEdit} className="comment-form">
+                    <textarea
+                        value={editText}
+                        onChange={(e) => setEditText(e.target.value)}
+                        rows="3"
+                        disabled={submitting}
+                        required
+                    />
+                    <button
+                        type="submit"
+                        className="submit-comment-btn"
+                        disabled={submitting || !editText.trim()}
+                    >
+                        {submitting ? 'Saving...' : 'Save'}
+                    </button>
+                    <button type="button" onClick={() => setEditing(false)} disabled={submitting}>
+                        Cancel
+                    </button>
+                </form>
+            ) : (
+                comment.content_html ? (
+                    // content_html уже очищен на сервере по белому списку тегов
+                    <div
+                        className={comment.deleted ? 'comment-content comment-deleted' : 'comment-content'}
+                        dangerouslySetInnerHTML={{ __html: comment.content_html }}
+                    />
+                ) : (
+                    <div className={comment.deleted ? 'comment-content comment-deleted' : 'comment-content'}>
+                        {(comment.content || '').split('\n').map((line, index) => (
+                            <p key={index}>{line}</p>
+                        ))}
+                    </div>
+                )
+            )}
+            {canReply && !comment.deleted && (
+                <button className="reply-comment-btn" onClick={() => setReplying(!replying)}>
+                    {replying ? 'Cancel' : 'Reply'}
+                </button>
+            )}
+            {replying && (
+                <form onSubmit={handleReply} className="comment-form">
+                    <textarea
+                        value={replyText}
+                        onChange={(e) => setReplyText(e.target.value)}
+                        placeholder={`Reply to ${comment.author_name}...`}
+                        rows="2"
+                        disabled={submitting}
+                        required
+                    />
+                    <button
+                        type="submit"
+                        className="submit-comment-btn"
+                        disabled={submitting || !replyText.trim()}
+                    >
+                        {submitting ? 'Posting...' : 'Post Reply'}
+                    </button>
+                </form>
+            )}
+            {comment.replies.map(reply => (
+                <CommentItem
+                    key={reply.id}
+                    comment={reply}
+                    canReply={canReply}
+                    canModify={canModify}
+                    onReply={onReply}
+                    onEdit={onEdit}
+                    onDelete={onDelete}
+                    onLoadReplies={onLoadReplies}
+                />
+            ))}
+            {hiddenReplies > 0 && (
+                <button className="load-replies-btn" onClick={() => onLoadReplies(comment)}>
+                    Show {hiddenReplies} more {hiddenReplies === 1 ? 'reply' : 'replies'}
+                </button>
+            )}
+        </div>
+    );
+};
+
 const Comments = ({ postId }) => {
     const [comments, setComments] = useState([]);
+    const [total, setTotal] = useState(0);
+    const [page, setPage] = useState(1);
     const [newComment, setNewComment] = useState('');
     const [loading, setLoading] = useState(false);
     const [error, setError] = useState(null);
@@ -14,6 +230,7 @@ const Comments = ({ postId }) => {
     const token = localStorage.getItem('token');
     const userId = localStorage.getItem('userId');
     const username = localStorage.getItem('username');
+    const userRole = localStorage.getItem('userRole');
     
     // Формируем объект пользователя
     const currentUser = userId ? {
@@ -24,43 +241,25 @@ const Comments = ({ postId }) => {
     const isAuthenticated = !!token && !!currentUser;
     const navigate = useNavigate();
 
-    const fetchComments = useCallback(async () => {
+    const fetchComments = useCallback(async (pageToLoad = 1) => {
         try {
             setLoading(true);
             setError(null);
 
-            const config = token ? {
-                headers: { 
-                    'Authorization': `Bearer ${token}`
-                }
-            } : {};
+            const config = {
+                params: { page: pageToLoad, limit: THREADS_PAGE_SIZE },
+                ...(token ? { headers: { 'Authorization': `Bearer ${token}` } } : {})
+            };
 
-            const response = await axios.get(
-                `http://localhost:8081/api/v1/posts/${postId}/comments`,
-                config
-            );
+            const response = await axios.get(`${API_URL}/posts/${postId}/comments`, config);
 
             const rawData = response.data;
-            let commentsArray = [];
-            
-            if (Array.isArray(rawData)) {
-                commentsArray = rawData;
-            } else if (rawData && Array.isArray(rawData.data)) {
-                commentsArray = rawData.data;
-            } else if (rawData && Array.isArray(rawData.comments)) {
-                commentsArray = rawData.comments;
-            }
-
-            const processedComments = commentsArray.map(comment => ({
-                id: parseInt(comment.id, 10),
-                author_id: parseInt(comment.author_id, 10),
-                post_id: parseInt(comment.post_id, 10),
-                content: comment.content || '',
-                author_name: comment.author_name || `User #${comment.author_id}`,
-                created_at: comment.created_at || new Date().toISOString()
-            }));
+            const commentsArray = Array.isArray(rawData?.comments) ? rawData.comments : [];
+            const processedComments = commentsArray.map(normalizeComment);
 
-            setComments(processedComments);
+            setComments(prev => pageToLoad === 1 ? processedComments : [...prev, ...processedComments]);
+            setTotal(rawData?.total || 0);
+            setPage(pageToLoad);
         } catch (err) {
             setError(err.response?.data?.error || err.message);
         } finally {
@@ -68,6 +267,31 @@ const Comments = ({ postId }) => {
         }
     }, [postId, token]);
 
+    const postComment = async (content, parentId) => {
+        const response = await axios.post(
+            `${API_URL}/posts/${postId}/comments`,
+            {
+                content,
+                ...(parentId ? { parent_id: parentId } : {})
+            },
+            {
+                headers: {
+                    Authorization: `Bearer ${token}`,
+                    'Content-Type': 'application/json'
+                }
+            }
+        );
+
+        return normalizeComment({
+            ...response.data,
+            author_name: currentUser.username,
+            created_at: new Date().toISOString(),
+            content,
+            author_id: currentUser.id,
+            post_id: postId
+        });
+    };
+
     const handleSubmitComment = async (e) => {
         e.preventDefault();
         
@@ -80,30 +304,9 @@ const Comments = ({ postId }) => {
 
         try {
             setSubmitting(true);
-            const response = await axios.post(
-                `http://localhost:8081/api/v1/posts/${postId}/comments`,
-                { 
-                    content: newComment,
-                    author_id: currentUser.id
-                },
-                { 
-                    headers: { 
-                        Authorization: `Bearer ${token}`,
-                        'Content-Type': 'application/json' 
-                    }
-                }
-            );
-
-            const newCommentWithAuthor = {
-                ...response.data,
-                author_name: currentUser.username,
-                created_at: new Date().toISOString(),
-                content: newComment,
-                author_id: currentUser.id,
-                post_id: postId
-            };
-
-            setComments(prev => [newCommentWithAuthor, ...prev]);
+            const created = await postComment(newComment, null);
+            setComments(prev => [created, ...prev]);
+            setTotal(prev => prev + 1);
             setNewComment('');
             setError(null);
         } catch (err) {
@@ -113,9 +316,101 @@ const Comments = ({ postId }) => {
         }
     };
 
+    const handleReply = async (parent, content) => {
+        if (!isAuthenticated) {
+            navigate('/login');
+            return false;
+        }
+
+        try {
+            const created = await postComment(content, parent.id);
+            setComments(prev => updateComment(prev, parent.id, comment => ({
+                ...comment,
+                reply_count: comment.reply_count + 1,
+                replies: [...comment.replies, created]
+            })));
+            setError(null);
+            return true;
+        } catch (err) {
+            setError(err.response?.data?.error || err.message || 'Failed to post reply');
+            return false;
+        }
+    };
+
+    const canModify = (comment) =>
+        isAuthenticated && (comment.author_id === currentUser.id || userRole === 'admin');
+
+    const authHeaders = () => ({
+        headers: {
+            Authorization: `Bearer ${token}`,
+            'Content-Type': 'application/json'
+        }
+    });
+
+    const handleEdit = async (target, content) => {
+        try {
+            const response = await axios.put(
+                `${API_URL}/posts/${postId}/comments/${target.id}`,
+                { content },
+                authHeaders()
+            );
+            const updated = response.data?.comment || {};
+            setComments(prev => updateComment(prev, target.id, comment => ({
+                ...comment,
+                content: updated.content ?? content,
+                content_html: updated.content_html ?? '',
+                updated_at: updated.updated_at || new Date().toISOString(),
+                edited: true
+            })));
+            setError(null);
+            return true;
+        } catch (err) {
+            setError(err.response?.data?.error || err.message || 'Failed to update comment');
+            return false;
+        }
+    };
+
+    const handleDelete = async (target) => {
+        const message = target.reply_count > 0
+            ? 'Delete this comment? Replies to it will stay in the thread.'
+            : 'Are you sure you want to delete this comment?';
+        if (!window.confirm(message)) return;
+
+        try {
+            await axios.delete(`${API_URL}/posts/${postId}/comments/${target.id}`, authHeaders());
+            if (target.reply_count > 0) {
+                setComments(prev => updateComment(prev, target.id, markDeleted));
+            } else {
+                setComments(prev => removeComment(prev, target.id));
+                if (!target.parent_id) {
+                    setTotal(prev => prev - 1);
+                }
+            }
+            setError(null);
+        } catch (err) {
+            setError(err.response?.data?.error || err.message || 'Failed to delete comment');
+        }
+    };
+
+    const handleLoadReplies = async (parent) => {
+        try {
+            const response = await axios.get(`${API_URL}/comments/${parent.id}/replies`, {
+                params: { limit: REPLIES_PAGE_SIZE }
+            });
+            const replies = (response.data?.comments || []).map(normalizeComment);
+            setComments(prev => updateComment(prev, parent.id, comment => ({
+                ...comment,
+                reply_count: response.data?.total ?? comment.reply_count,
+                replies
+            })));
+        } catch (err) {
+            setError(err.response?.data?.error || err.message);
+        }
+    };
+
     useEffect(() => {
         if (postId) {
-            fetchComments();
+            fetchComments(1);
         }
     }, [postId, fetchComments]);
 
@@ -132,7 +427,7 @@ const Comments = ({ postId }) => {
 
     return (
         <div className="comments-section">
-            <h4>Comments ({comments.length})</h4>
+            <h4>Comments ({total})</h4>
             
             {isAuthenticated ? (
                 <form onSubmit={handleSubmitComment} className="comment-form">
@@ -163,29 +458,24 @@ const Comments = ({ postId }) => {
 
             <div className="comments-list">
                 {comments.map(comment => (
-                    <div key={comment.id} className="comment-item">
-                        <div className="comment-header">
-                            <span className="comment-author">
-                                {comment.author_name}
-                            </span>
-                            <span className="comment-timestamp">
-                                {new Date(comment.created_at).toLocaleDateString('en-US', {
-                                    hour: '2-digit',
-                                    minute: '2-digit',
-                                    day: 'numeric',
-                                    month: 'short',
-                                    year: 'numeric'
-                                })}
-                            </span>
-                        </div>
-                        <div className="comment-content">
-                            {(comment.content || '').split('\n').map((line, index) => (
-                                <p key={index}>{line}</p>
-                            ))}
-                        </div>
-                    </div>
+                    <CommentItem
+                        key={comment.id}
+                        comment={comment}
+                        canReply={isAuthenticated}
+                        canModify={canModify}
+                        onReply={handleReply}
+                        onEdit={handleEdit}
+                        onDelete={handleDelete}
+                        onLoadReplies={handleLoadReplies}
+                    />
                 ))}
             </div>
+
+            {comments.length < total && !loading && (
+                <button className="load-more-btn" onClick={() => fetchComments(page + 1)}>
+                    Load more comments
+                </button>
+            )}
         </div>
     );
 };
@@ -8,6 +8,7 @@ const PostList = ({ refreshTrigger }) => {
     const [posts, setPosts] = useState([]);
     const [loading, setLoading] = useState(true);
     const [error, setError] = useState(null);
+    const [nextCursor, setNextCursor] = useState('');
     const [editingPostId, setEditingPostId] = useState(null);
     const [editFormData, setEditFormData] = useState({
         title: '',
@@ -20,13 +21,14 @@ const PostList = ({ refreshTrigger }) => {
     const isAuthenticated = !!token;
     const navigate = useNavigate();
 
-    const fetchPosts = useCallback(async () => {
+    const fetchPosts = useCallback(async (cursor = '') => {
         try {
             setLoading(true);
             setError(null);
             setEditingPostId(null);
 
             const response = await axios.get('http://localhost:8081/api/v1/posts', {
+                params: cursor ? { cursor } : {},
                 headers: { 
                     'Accept': 'application/json',
                     ...(token && { 'Authorization': `Bearer ${token}` })
@@ -46,7 +48,8 @@ const PostList = ({ refreshTrigger }) => {
                 created_at: new Date(post.created_at).toISOString()
             }));
 
-            setPosts(processedPosts);
+            setPosts(prev => cursor ? [...prev, ...processedPosts] : processedPosts);
+            setNextCursor(response.data.next_cursor || '');
         } catch (err) {
             setError(err.message || 'Failed to load posts');
         } finally {
@@ -138,6 +141,43 @@ const PostList = ({ refreshTrigger }) => {
         }
     };
 
+    const handleUploadAttachment = async (postId, file) => {
+        if (!file) return;
+        const formData = new FormData();
+        formData.append('file', file);
+
+        try {
+            const response = await axios.post(
+                `http://localhost:8081/api/v1/posts/${postId}/attachments`,
+                formData,
+                { headers: { 'Authorization': `Bearer ${token}` } }
+            );
+            setPosts(prev => prev.map(post => post.id === postId
+                ? { ...post, attachments: [...(post.attachments || []), response.data] }
+                : post));
+        } catch (error) {
+            console.error('Upload attachment error:', error);
+            alert(error.response?.data?.error || 'Failed to upload attachment');
+        }
+    };
+
+    const handleDeleteAttachment = async (postId, attachmentId) => {
+        if (!window.confirm("Delete this attachment?")) return;
+
+        try {
+            await axios.delete(
+                `http://localhost:8081/api/v1/posts/${postId}/attachments/${attachmentId}`,
+                { headers: { 'Authorization': `Bearer ${token}` } }
+            );
+            setPosts(prev => prev.map(post => post.id === postId
+                ? { ...post, attachments: post.attachments.filter(a => a.id !== attachmentId) }
+                : post));
+        } catch (error) {
+            console.error('Delete attachment error:', error);
+            alert(error.response?.data?.error || 'Failed to delete attachment');
+        }
+    };
+
     return (
         <div className="post-list-container">
             {loading && <div className="loading-indicator">Loading posts...</div>}
@@ -201,11 +241,60 @@ const PostList = ({ refreshTrigger }) => {
                                     </div>
                                 )}
                             </div>
-                            <div className="post-content">
-                                {post.content.split('\n').map((p, i) => (
-                                    <p key={i}>{p}</p>
-                                ))}
-                            </div>
+                            {post.content_html ? (
+                                // content_html уже очищен на сервере по белому списку тегов
+                                <div
+                                    className="post-content"
+                                    dangerouslySetInnerHTML={{ __html: post.content_html }}
+                                />
+                            ) : (
+                                <div className="post-content">
+                                    {post.content.split('\n').map((p, i) => (
+                                        <p key={i}>{p}</p>
+                                    ))}
+                                </div>
+                            )}
+                            {(post.attachments?.length > 0 || (isAuthenticated && (currentUserId === post.author_id || currentUserRole === 'admin'))) && (
+                                <div className="post-attachments">
+                                    {(post.attachments || []).map(attachment => (
+                                        <div key={attachment.id} className="attachment-item">
+                                            <a
+                                                href={`http://localhost:8081${attachment.url}`}
+                                                target="_blank"
+                                                rel="noopener noreferrer"
+                                            >
+                                                {attachment.thumbnail_url ? (
+                                                    <img
+                                                        src={`http://localhost:8081${attachment.thumbnail_url}`}
+                                                        alt={attachment.file_name}
+                                                        className="attachment-thumbnail"
+                                                    />
+                                                ) : (
+                                                    <span>📎 {attachment.file_name}</span>
+                                                )}
+                                            </a>
+                                            {isAuthenticated && (currentUserId === post.author_id || currentUserRole === 'admin') && (
+                                                <button
+                                                    onClick={() => handleDeleteAttachment(post.id, attachment.id)}
+                                                    className="delete-button"
+                                                    title="Delete attachment"
+                                                >
+                                                    ✕
+                                                </button>
+                                            )}
+                                        </div>
+                                    ))}
+                                    {isAuthenticated && (currentUserId === post.author_id || currentUserRole === 'admin') && (
+                                        <input
+                                            type="file"
+                                            onChange={(e) => {
+                                                handleUploadAttachment(post.id, e.target.files[0]);
+                                                e.target.value = '';
+                                            }}
+                                        />
+                                    )}
+                                </div>
+                            )}
                         </>
                     )}
                     
@@ -229,6 +318,12 @@ const PostList = ({ refreshTrigger }) => {
                     <Comments postId={post.id} />
                 </div>
             ))}
+
+            {nextCursor && !loading && (
+                <button onClick={() => fetchPosts(nextCursor)} className="load-more-button">
+                    Load more posts
+                </button>
+            )}
         </div>
     );
 };